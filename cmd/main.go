@@ -1,9 +1,48 @@
 package main
 
 import (
+	"log"
+	"os"
+	"strings"
+
+	"github.com/MMII0220/MiniBank/config"
 	"github.com/MMII0220/MiniBank/internal/app"
 )
 
 func main() {
-	app.AppRun()
+	// Без команды запускается HTTP сервер, иначе - служебная команда со своими аргументами
+	// (migrate down 2); флаги конфигурации идут после них
+	var command string
+	var params []string
+	args := os.Args[1:]
+	for len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		if command == "" {
+			command = args[0]
+		} else {
+			params = append(params, args[0])
+		}
+		args = args[1:]
+	}
+	switch command {
+	case "", "audit-verify", "migrate":
+	default:
+		log.Fatalf("unknown command %q (available: audit-verify, migrate)", command)
+	}
+	if command == "audit-verify" && len(params) > 0 {
+		log.Fatalf("audit-verify takes no arguments, got %q", params)
+	}
+
+	cfg, err := config.Load(args)
+	if err != nil {
+		log.Printf("invalid configuration:\n%v", err)
+		os.Exit(2)
+	}
+
+	switch command {
+	case "audit-verify":
+		os.Exit(app.AuditVerify(cfg))
+	case "migrate":
+		os.Exit(app.Migrate(cfg, params))
+	}
+	app.AppRun(cfg)
 }
@@ -1,36 +1,226 @@
 package config
 
 import (
-	"fmt"
-	"github.com/jmoiron/sqlx"
-	"github.com/joho/godotenv"
-	_ "github.com/lib/pq"
-	"log"
-	"os"
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/screening"
+)
+
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
 )
 
-var db *sqlx.DB
+// Config - вся конфигурация приложения. Значения берутся по порядку: Default(), YAML файл (-config или CONFIG_FILE),
+// переменные окружения и .env, флаги командной строки. Тег env - имя переменной окружения.
+type Config struct {
+	Env        string `yaml:"env" env:"APP_ENV"`
+	NotifyFile string `yaml:"notify_file" env:"NOTIFY_FILE"` // без SMS/email шлюза уведомления пишутся в файл
 
-// Connection to Database
-func InitDB() (*sqlx.DB, error) {
-	var err error
-	err = godotenv.Load(".env")
-	if err != nil {
-		return nil, fmt.Errorf("no env file found: %v", err)
-	}
+	Server    Server    `yaml:"server"`
+	Database  Database  `yaml:"database"`
+	Redis     Redis     `yaml:"redis"`
+	JWT       JWT       `yaml:"jwt"`
+	Storage   Storage   `yaml:"storage"`
+	Sanctions Sanctions `yaml:"sanctions"`
+	Bootstrap Bootstrap `yaml:"bootstrap_admin"`
+	Jobs      Jobs      `yaml:"jobs"`
+	Service   Service   `yaml:"service"`
+}
 
-	dbc := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable", os.Getenv("DB_HOST"), os.Getenv("DB_PORT"), os.Getenv("DB_USER"), os.Getenv("DB_PASSWORD"), os.Getenv("DB_NAME"))
+// Server - HTTP сервер и время на завершение начатых запросов при остановке
+type Server struct {
+	Port              string        `yaml:"port" env:"ROUTER_RUN"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"HTTP_READ_HEADER_TIMEOUT"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"HTTP_READ_TIMEOUT"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" env:"HTTP_WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+}
 
-	db, err = sqlx.Connect("postgres", dbc)
-	if err != nil {
-		log.Fatal("Error connecting to database", err)
-	}
+// Database - подключение к PostgreSQL; пароль можно передать файлом (DB_PASSWORD_FILE)
+type Database struct {
+	Host         string `yaml:"host" env:"DB_HOST"`
+	Port         string `yaml:"port" env:"DB_PORT"`
+	User         string `yaml:"user" env:"DB_USER"`
+	Password     string `yaml:"password" env:"DB_PASSWORD"`
+	PasswordFile string `yaml:"password_file" env:"DB_PASSWORD_FILE"`
+	Name         string `yaml:"name" env:"DB_NAME"`
+	SSLMode      string `yaml:"sslmode" env:"DB_SSLMODE"`
+	// MigrateOnStart - применять встроенные миграции при старте сервера; без него сервер только проверяет версию схемы
+	MigrateOnStart bool `yaml:"migrate_on_start" env:"DB_MIGRATE_ON_START"`
+}
+
+// Redis - кеш, статусы сессий и счетчики входа; пароль можно передать файлом (REDIS_PASSWORD_FILE)
+type Redis struct {
+	Host         string        `yaml:"host" env:"REDIS_HOST"`
+	Port         string        `yaml:"port" env:"REDIS_PORT"`
+	Password     string        `yaml:"password" env:"REDIS_PASSWORD"`
+	PasswordFile string        `yaml:"password_file" env:"REDIS_PASSWORD_FILE"`
+	DB           int           `yaml:"db" env:"REDIS_DB"`
+	AccountsTTL  time.Duration `yaml:"accounts_ttl" env:"REDIS_ACCOUNTS_TTL"`
+}
+
+// JWT - каталог ключей подписи (*.pem, kid = имя файла) и kid ключа, которым подписываются новые токены
+type JWT struct {
+	KeysDir    string `yaml:"keys_dir" env:"JWT_KEYS_DIR"`
+	SigningKID string `yaml:"signing_kid" env:"JWT_SIGNING_KID"`
+}
+
+// Storage - хранилище документов KYC: disk или s3
+type Storage struct {
+	Backend    string `yaml:"backend" env:"KYC_STORAGE"`
+	Dir        string `yaml:"dir" env:"KYC_STORAGE_DIR"`
+	S3Bucket   string `yaml:"s3_bucket" env:"KYC_S3_BUCKET"`
+	S3LocalDir string `yaml:"s3_local_dir" env:"KYC_S3_LOCAL_DIR"`
+}
+
+// Sanctions - файлы санкционных списков (CSV/XML) и порог схожести имени
+type Sanctions struct {
+	Lists          []string `yaml:"lists" env:"SANCTIONS_LISTS"`
+	MatchThreshold float64  `yaml:"match_threshold" env:"SANCTIONS_MATCH_THRESHOLD"`
+}
 
-	return db, nil
+// Bootstrap - первый админ при пустой системе; пароль можно передать файлом (BOOTSTRAP_ADMIN_PASSWORD_FILE)
+type Bootstrap struct {
+	Email        string `yaml:"email" env:"BOOTSTRAP_ADMIN_EMAIL"`
+	Name         string `yaml:"name" env:"BOOTSTRAP_ADMIN_NAME"`
+	Phone        string `yaml:"phone" env:"BOOTSTRAP_ADMIN_PHONE"`
+	Password     string `yaml:"password" env:"BOOTSTRAP_ADMIN_PASSWORD"`
+	PasswordFile string `yaml:"password_file" env:"BOOTSTRAP_ADMIN_PASSWORD_FILE"`
 }
 
-func CloseDB() {
-	if db != nil {
-		db.Close()
+// Jobs - фоновые задания
+type Jobs struct {
+	ExpiryInterval       time.Duration `yaml:"expiry_interval" env:"EXPIRY_JOB_INTERVAL"`
+	DormancyScanInterval time.Duration `yaml:"dormancy_scan_interval" env:"DORMANCY_SCAN_INTERVAL"`
+}
+
+// Service - настройки бизнес-логики, передаются в service.Service через SetConfig
+type Service struct {
+	// Токены
+	JWTIssuer          string        `yaml:"jwt_issuer" env:"JWT_ISSUER"`
+	JWTAudience        string        `yaml:"jwt_audience" env:"JWT_AUDIENCE"`
+	RefreshTokenTTL    time.Duration `yaml:"refresh_token_ttl" env:"REFRESH_TOKEN_TTL"`
+	NewDeviceStepUp    bool          `yaml:"new_device_step_up" env:"LOGIN_NEW_DEVICE_STEP_UP"`
+	StepUpCodeTTL      time.Duration `yaml:"step_up_code_ttl" env:"LOGIN_STEP_UP_CODE_TTL"`
+	MFAIssuer          string        `yaml:"mfa_issuer" env:"MFA_ISSUER"`
+	APIClientRateLimit int           `yaml:"api_client_rate_limit" env:"API_CLIENT_RATE_LIMIT"`
+	APIClientTokenTTL  time.Duration `yaml:"api_client_token_ttl" env:"API_CLIENT_TOKEN_TTL"`
+
+	// Вход и пароли
+	LoginMaxFailedAttempts   int           `yaml:"login_max_failed_attempts" env:"LOGIN_MAX_FAILED_ATTEMPTS"`
+	LoginIPMaxFailedAttempts int           `yaml:"login_ip_max_failed_attempts" env:"LOGIN_IP_MAX_FAILED_ATTEMPTS"`
+	LoginFailureWindow       time.Duration `yaml:"login_failure_window" env:"LOGIN_FAILURE_WINDOW"`
+	LoginLockoutDuration     time.Duration `yaml:"login_lockout_duration" env:"LOGIN_LOCKOUT_DURATION"`
+	LoginBackoffBase         time.Duration `yaml:"login_backoff_base" env:"LOGIN_BACKOFF_BASE"`
+	PasswordMinLength        int           `yaml:"password_min_length" env:"PASSWORD_MIN_LENGTH"`
+	PasswordResetTTL         time.Duration `yaml:"password_reset_ttl" env:"PASSWORD_RESET_TTL"`
+	PasswordResetMaxPerEmail int           `yaml:"password_reset_max_per_email" env:"PASSWORD_RESET_MAX_PER_EMAIL"`
+	PasswordResetMaxPerIP    int           `yaml:"password_reset_max_per_ip" env:"PASSWORD_RESET_MAX_PER_IP"`
+	PasswordResetWindow      time.Duration `yaml:"password_reset_window" env:"PASSWORD_RESET_WINDOW"`
+	StaffInviteTTL           time.Duration `yaml:"staff_invite_ttl" env:"STAFF_INVITE_TTL"`
+
+	// Контакты
+	PhoneDefaultCountryCode        string        `yaml:"phone_default_country_code" env:"PHONE_DEFAULT_COUNTRY_CODE"`
+	PhoneNationalLength            int           `yaml:"phone_national_length" env:"PHONE_NATIONAL_LENGTH"`
+	ContactVerificationTTL         time.Duration `yaml:"contact_verification_ttl" env:"CONTACT_VERIFICATION_TTL"`
+	ContactVerificationMaxAttempts int           `yaml:"contact_verification_max_attempts" env:"CONTACT_VERIFICATION_MAX_ATTEMPTS"`
+
+	// Переводы и админские действия (суммы в TJS)
+	TransferOTPThreshold      float64       `yaml:"transfer_otp_threshold" env:"TRANSFER_OTP_THRESHOLD"`
+	TransferOTPTTL            time.Duration `yaml:"transfer_otp_ttl" env:"TRANSFER_OTP_TTL"`
+	TransferOTPMaxAttempts    int           `yaml:"transfer_otp_max_attempts" env:"TRANSFER_OTP_MAX_ATTEMPTS"`
+	TransferApprovalThreshold float64       `yaml:"transfer_approval_threshold" env:"TRANSFER_APPROVAL_THRESHOLD"`
+	TransferApprovalTTL       time.Duration `yaml:"transfer_approval_ttl" env:"TRANSFER_APPROVAL_TTL"`
+	AdminActionTTL            time.Duration `yaml:"admin_action_ttl" env:"ADMIN_ACTION_TTL"`
+	AdjustmentDailyCeiling    float64       `yaml:"adjustment_daily_ceiling" env:"ADJUSTMENT_DAILY_CEILING"`
+	AdjustmentNoApprovalLimit float64       `yaml:"adjustment_no_approval_limit" env:"ADJUSTMENT_NO_APPROVAL_LIMIT"`
+
+	// Прочее
+	StatsCacheTTL    time.Duration `yaml:"stats_cache_ttl" env:"STATS_CACHE_TTL"`
+	DormancyMonths   int           `yaml:"dormancy_months" env:"DORMANCY_MONTHS"`
+	KYCMaxDocumentMB int           `yaml:"kyc_max_document_mb" env:"KYC_MAX_DOCUMENT_MB"`
+}
+
+// Default - значения по умолчанию, с которыми приложение запускается локально
+func Default() *Config {
+	return &Config{
+		Env: EnvDevelopment,
+		Server: Server{
+			Port:              "7999",
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       30 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			ShutdownTimeout:   30 * time.Second,
+		},
+		Database: Database{
+			Host:    "localhost",
+			Port:    "5432",
+			User:    "postgres",
+			Name:    "mini_bank",
+			SSLMode: "disable",
+		},
+		Redis: Redis{
+			Host:        "127.0.0.1",
+			Port:        "6379",
+			AccountsTTL: 15 * time.Minute,
+		},
+		Storage: Storage{
+			Backend:    "disk",
+			Dir:        "./storage/kyc",
+			S3Bucket:   "minibank-kyc",
+			S3LocalDir: "./storage/s3",
+		},
+		Sanctions: Sanctions{MatchThreshold: screening.DefaultThreshold},
+		Jobs: Jobs{
+			ExpiryInterval:       time.Minute,
+			DormancyScanInterval: 24 * time.Hour,
+		},
+		Service: DefaultService(),
+	}
+}
+
+// DefaultService - настройки бизнес-логики по умолчанию
+func DefaultService() Service {
+	return Service{
+		JWTIssuer:          "minibank",
+		JWTAudience:        "minibank-api",
+		RefreshTokenTTL:    7 * 24 * time.Hour,
+		StepUpCodeTTL:      10 * time.Minute,
+		MFAIssuer:          "MiniBank",
+		APIClientRateLimit: 60,
+		APIClientTokenTTL:  15 * time.Minute,
+
+		LoginMaxFailedAttempts:   5,
+		LoginIPMaxFailedAttempts: 20,
+		LoginFailureWindow:       15 * time.Minute,
+		LoginLockoutDuration:     15 * time.Minute,
+		LoginBackoffBase:         time.Second,
+		PasswordMinLength:        8,
+		PasswordResetTTL:         30 * time.Minute,
+		PasswordResetMaxPerEmail: 3,
+		PasswordResetMaxPerIP:    20,
+		PasswordResetWindow:      time.Hour,
+		StaffInviteTTL:           72 * time.Hour,
+
+		PhoneDefaultCountryCode:        "992",
+		PhoneNationalLength:            9,
+		ContactVerificationTTL:         15 * time.Minute,
+		ContactVerificationMaxAttempts: 5,
+
+		TransferOTPThreshold:      3000,
+		TransferOTPTTL:            5 * time.Minute,
+		TransferOTPMaxAttempts:    3,
+		TransferApprovalThreshold: 10000,
+		TransferApprovalTTL:       24 * time.Hour,
+		AdminActionTTL:            24 * time.Hour,
+		AdjustmentDailyCeiling:    5000,
+		AdjustmentNoApprovalLimit: 500,
+
+		StatsCacheTTL:    time.Minute,
+		DormancyMonths:   12,
+		KYCMaxDocumentMB: 10,
 	}
 }
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestDefault_IsValid(t *testing.T) {
+	if err := Default().Validate(); err != nil {
+		t.Fatalf("defaults must be valid: %v", err)
+	}
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	file := writeFile(t, "minibank.yaml", `
+server:
+  port: "8000"
+  read_timeout: 10s
+redis:
+  accounts_ttl: 5m
+service:
+  dormancy_months: 6
+  transfer_otp_threshold: 1500
+sanctions:
+  lists: [ofac.csv, un.xml]
+`)
+	// Окружение перекрывает файл, флаг - окружение
+	t.Setenv("HTTP_READ_TIMEOUT", "20s")
+	t.Setenv("ROUTER_RUN", "8100")
+	t.Setenv("LOGIN_NEW_DEVICE_STEP_UP", "true")
+
+	cfg, err := Load([]string{"-config", file, "-port", "8200"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Server.Port != "8200" {
+		t.Fatalf("flag must win, got port %s", cfg.Server.Port)
+	}
+	if cfg.Server.ReadTimeout != 20*time.Second || cfg.Redis.AccountsTTL != 5*time.Minute {
+		t.Fatalf("unexpected durations: %v %v", cfg.Server.ReadTimeout, cfg.Redis.AccountsTTL)
+	}
+	if cfg.Service.DormancyMonths != 6 || cfg.Service.TransferOTPThreshold != 1500 || !cfg.Service.NewDeviceStepUp {
+		t.Fatalf("unexpected service config: %+v", cfg.Service)
+	}
+	if len(cfg.Sanctions.Lists) != 2 || cfg.Sanctions.Lists[1] != "un.xml" {
+		t.Fatalf("unexpected sanctions lists: %v", cfg.Sanctions.Lists)
+	}
+	// Не заданное нигде остается по умолчанию
+	if cfg.Server.WriteTimeout != 30*time.Second || cfg.Service.PasswordMinLength != 8 {
+		t.Fatalf("defaults lost: %v %d", cfg.Server.WriteTimeout, cfg.Service.PasswordMinLength)
+	}
+}
+
+func TestLoad_EnvList(t *testing.T) {
+	t.Setenv("SANCTIONS_LISTS", " ofac.csv, ,un.xml ")
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if strings.Join(cfg.Sanctions.Lists, "|") != "ofac.csv|un.xml" {
+		t.Fatalf("unexpected lists: %q", cfg.Sanctions.Lists)
+	}
+}
+
+func TestLoad_InvalidValuesReportedTogether(t *testing.T) {
+	t.Setenv("HTTP_WRITE_TIMEOUT", "30")
+	t.Setenv("PASSWORD_MIN_LENGTH", "eight")
+
+	_, err := Load(nil)
+	if err == nil {
+		t.Fatal("expected error for invalid values")
+	}
+	for _, want := range []string{`HTTP_WRITE_TIMEOUT: invalid duration "30"`, `PASSWORD_MIN_LENGTH: invalid integer "eight"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("error %q does not mention %q", err, want)
+		}
+	}
+}
+
+func TestLoad_UnknownYAMLKey(t *testing.T) {
+	file := writeFile(t, "minibank.yaml", "server:\n  prot: \"8000\"\n")
+	if _, err := Load([]string{"-config", file}); err == nil || !strings.Contains(err.Error(), "prot") {
+		t.Fatalf("expected unknown field error, got %v", err)
+	}
+}
+
+func TestLoad_SecretFromFile(t *testing.T) {
+	secret := writeFile(t, "db_password", "s3cr3t\n")
+	t.Setenv("DB_PASSWORD_FILE", secret)
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Database.Password != "s3cr3t" {
+		t.Fatalf("expected password from file without newline, got %q", cfg.Database.Password)
+	}
+
+	// Значение и файл одновременно - неоднозначно
+	t.Setenv("DB_PASSWORD", "other")
+	if _, err := Load(nil); err == nil || !strings.Contains(err.Error(), "DB_PASSWORD and DB_PASSWORD_FILE") {
+		t.Fatalf("expected conflict error, got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cases := map[string]struct {
+		change func(c *Config)
+		want   string
+	}{
+		"production without jwt keys": {func(c *Config) {
+			c.Env = EnvProduction
+			c.Database.Password = "pw"
+		}, "JWT_KEYS_DIR is required in production"},
+		"keys dir without kid":    {func(c *Config) { c.JWT.KeysDir = "/etc/minibank/jwt" }, "JWT_SIGNING_KID is required"},
+		"empty audience":          {func(c *Config) { c.Service.JWTAudience = "" }, "JWT_AUDIENCE"},
+		"zero timeout":            {func(c *Config) { c.Server.ShutdownTimeout = 0 }, "SHUTDOWN_TIMEOUT must be a positive duration"},
+		"bad port":                {func(c *Config) { c.Server.Port = "http" }, "ROUTER_RUN must be a port number"},
+		"unknown env":             {func(c *Config) { c.Env = "prod" }, "APP_ENV must be"},
+		"bootstrap without pass":  {func(c *Config) { c.Bootstrap.Email = "admin@bank.tj" }, "BOOTSTRAP_ADMIN_PASSWORD"},
+		"unknown storage backend": {func(c *Config) { c.Storage.Backend = "ftp" }, "KYC_STORAGE must be disk or s3"},
+		"threshold out of range":  {func(c *Config) { c.Sanctions.MatchThreshold = 1.5 }, "SANCTIONS_MATCH_THRESHOLD"},
+		"negative ceiling":        {func(c *Config) { c.Service.AdjustmentDailyCeiling = -1 }, "ADJUSTMENT_DAILY_CEILING must be positive"},
+	}
+	for name, tc := range cases {
+		cfg := Default()
+		tc.change(cfg)
+		err := cfg.Validate()
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%s: expected %q, got %v", name, tc.want, err)
+		}
+	}
+}
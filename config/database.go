@@ -0,0 +1,30 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+var db *sqlx.DB
+
+// Connection to Database
+func InitDB(cfg Database) (*sqlx.DB, error) {
+	dbc := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name, cfg.SSLMode)
+
+	var err error
+	db, err = sqlx.Connect("postgres", dbc)
+	if err != nil {
+		return nil, fmt.Errorf("connect to database %s at %s:%s: %w", cfg.Name, cfg.Host, cfg.Port, err)
+	}
+
+	return db, nil
+}
+
+func CloseDB() {
+	if db != nil {
+		db.Close()
+	}
+}
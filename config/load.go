@@ -0,0 +1,181 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/joho/godotenv"
+)
+
+// Load собирает конфигурацию для команды с аргументами args (без имени программы и команды):
+// Default(), затем YAML файл, затем .env и переменные окружения, затем флаги.
+// После этого читаются секреты из *_FILE и проверяется результат; все найденные ошибки возвращаются разом.
+func Load(args []string) (*Config, error) {
+	flags := flag.NewFlagSet("minibank", flag.ContinueOnError)
+	envFile := flags.String("env-file", ".env", "dotenv file, variables already set in the environment win")
+	configFile := flags.String("config", "", "YAML config file (default $CONFIG_FILE)")
+	port := flags.String("port", "", "HTTP port, overrides ROUTER_RUN")
+	appEnv := flags.String("env", "", "development or production, overrides APP_ENV")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+
+	// .env не обязателен: в контейнере все приходит через окружение
+	if err := godotenv.Load(*envFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("load %s: %w", *envFile, err)
+	}
+
+	cfg := Default()
+	if *configFile == "" {
+		*configFile = os.Getenv("CONFIG_FILE")
+	}
+	if *configFile != "" {
+		if err := cfg.loadFile(*configFile); err != nil {
+			return nil, err
+		}
+	}
+	if err := errors.Join(applyEnv(reflect.ValueOf(cfg).Elem())...); err != nil {
+		return nil, err
+	}
+	if *port != "" {
+		cfg.Server.Port = *port
+	}
+	if *appEnv != "" {
+		cfg.Env = *appEnv
+	}
+
+	if err := cfg.loadSecrets(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// loadFile накладывает YAML файл на текущие значения; неизвестные ключи - ошибка, чтобы опечатка не терялась молча
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+	if err := yaml.UnmarshalWithOptions(data, c, yaml.Strict()); err != nil {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// applyEnv переносит в структуру заданные переменные окружения по тегам env.
+// Пустая переменная считается незаданной и не затирает значение из файла или по умолчанию.
+func applyEnv(v reflect.Value) []error {
+	var problems []error
+	for i := 0; i < v.NumField(); i++ {
+		field, spec := v.Field(i), v.Type().Field(i)
+		if field.Kind() == reflect.Struct {
+			problems = append(problems, applyEnv(field)...)
+			continue
+		}
+		name := spec.Tag.Get("env")
+		if name == "" {
+			continue
+		}
+		raw := strings.TrimSpace(os.Getenv(name))
+		if raw == "" {
+			continue
+		}
+		if err := setField(field, raw); err != nil {
+			problems = append(problems, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return problems
+}
+
+func setField(field reflect.Value, raw string) error {
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", raw)
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		field.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		field.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		field.SetBool(b)
+	case reflect.Slice:
+		// Списки задаются через запятую
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported config field type %s", field.Type())
+	}
+	return nil
+}
+
+// loadSecrets читает пароли из файлов *_FILE (Docker/Kubernetes secrets).
+// Значение и файл одновременно - ошибка: непонятно, какой из паролей имелся в виду.
+func (c *Config) loadSecrets() error {
+	secrets := []struct {
+		name  string
+		value *string
+		file  string
+	}{
+		{"DB_PASSWORD", &c.Database.Password, c.Database.PasswordFile},
+		{"REDIS_PASSWORD", &c.Redis.Password, c.Redis.PasswordFile},
+		{"BOOTSTRAP_ADMIN_PASSWORD", &c.Bootstrap.Password, c.Bootstrap.PasswordFile},
+	}
+
+	var problems []error
+	for _, secret := range secrets {
+		if secret.file == "" {
+			continue
+		}
+		if *secret.value != "" {
+			problems = append(problems, fmt.Errorf("%s and %s_FILE are both set, use one of them", secret.name, secret.name))
+			continue
+		}
+		data, err := os.ReadFile(secret.file)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("%s_FILE: %w", secret.name, err))
+			continue
+		}
+		*secret.value = strings.TrimRight(string(data), "\r\n")
+	}
+	return errors.Join(problems...)
+}
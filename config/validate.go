@@ -0,0 +1,121 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Validate проверяет конфигурацию при старте, чтобы ошибка настройки не всплыла посреди операции.
+// Возвращает все найденные проблемы сразу.
+func (c *Config) Validate() error {
+	var problems []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Errorf(format, args...))
+		}
+	}
+	production := c.Env == EnvProduction
+
+	check(c.Env == EnvDevelopment || production, "APP_ENV must be %s or %s, got %q", EnvDevelopment, EnvProduction, c.Env)
+	port, err := strconv.Atoi(c.Server.Port)
+	check(err == nil && port > 0 && port <= 65535, "ROUTER_RUN must be a port number, got %q", c.Server.Port)
+
+	check(c.Database.Host != "", "DB_HOST is required")
+	check(c.Database.Port != "", "DB_PORT is required")
+	check(c.Database.User != "", "DB_USER is required")
+	check(c.Database.Name != "", "DB_NAME is required")
+	check(!production || c.Database.Password != "", "DB_PASSWORD (or DB_PASSWORD_FILE) is required in production")
+	check(c.Redis.Host != "" && c.Redis.Port != "", "REDIS_HOST and REDIS_PORT are required")
+	check(c.Redis.DB >= 0, "REDIS_DB must not be negative")
+
+	// Без ключей токены подписываются временным ключом и перестают действовать после перезапуска
+	check(c.JWT.KeysDir != "" || !production, "JWT_KEYS_DIR is required in production")
+	check(c.JWT.KeysDir == "" || c.JWT.SigningKID != "", "JWT_SIGNING_KID is required when JWT_KEYS_DIR is set")
+	check(c.Service.JWTIssuer != "" && c.Service.JWTAudience != "", "JWT_ISSUER and JWT_AUDIENCE must not be empty")
+
+	switch c.Storage.Backend {
+	case "disk":
+		check(c.Storage.Dir != "", "KYC_STORAGE_DIR is required for disk storage")
+	case "s3":
+		check(c.Storage.S3Bucket != "", "KYC_S3_BUCKET is required for s3 storage")
+	default:
+		check(false, "KYC_STORAGE must be disk or s3, got %q", c.Storage.Backend)
+	}
+	check(c.Sanctions.MatchThreshold > 0 && c.Sanctions.MatchThreshold <= 1,
+		"SANCTIONS_MATCH_THRESHOLD must be in (0, 1], got %v", c.Sanctions.MatchThreshold)
+	check(c.Bootstrap.Email == "" || c.Bootstrap.Password != "",
+		"BOOTSTRAP_ADMIN_PASSWORD (or BOOTSTRAP_ADMIN_PASSWORD_FILE) is required with BOOTSTRAP_ADMIN_EMAIL")
+
+	durations := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"HTTP_READ_HEADER_TIMEOUT", c.Server.ReadHeaderTimeout},
+		{"HTTP_READ_TIMEOUT", c.Server.ReadTimeout},
+		{"HTTP_WRITE_TIMEOUT", c.Server.WriteTimeout},
+		{"HTTP_IDLE_TIMEOUT", c.Server.IdleTimeout},
+		{"SHUTDOWN_TIMEOUT", c.Server.ShutdownTimeout},
+		{"REDIS_ACCOUNTS_TTL", c.Redis.AccountsTTL},
+		{"EXPIRY_JOB_INTERVAL", c.Jobs.ExpiryInterval},
+		{"DORMANCY_SCAN_INTERVAL", c.Jobs.DormancyScanInterval},
+		{"REFRESH_TOKEN_TTL", c.Service.RefreshTokenTTL},
+		{"API_CLIENT_TOKEN_TTL", c.Service.APIClientTokenTTL},
+		{"LOGIN_STEP_UP_CODE_TTL", c.Service.StepUpCodeTTL},
+		{"LOGIN_FAILURE_WINDOW", c.Service.LoginFailureWindow},
+		{"LOGIN_LOCKOUT_DURATION", c.Service.LoginLockoutDuration},
+		{"LOGIN_BACKOFF_BASE", c.Service.LoginBackoffBase},
+		{"PASSWORD_RESET_TTL", c.Service.PasswordResetTTL},
+		{"PASSWORD_RESET_WINDOW", c.Service.PasswordResetWindow},
+		{"STAFF_INVITE_TTL", c.Service.StaffInviteTTL},
+		{"CONTACT_VERIFICATION_TTL", c.Service.ContactVerificationTTL},
+		{"TRANSFER_OTP_TTL", c.Service.TransferOTPTTL},
+		{"TRANSFER_APPROVAL_TTL", c.Service.TransferApprovalTTL},
+		{"ADMIN_ACTION_TTL", c.Service.AdminActionTTL},
+		{"STATS_CACHE_TTL", c.Service.StatsCacheTTL},
+	}
+	for _, d := range durations {
+		check(d.value > 0, "%s must be a positive duration, got %s", d.name, d.value)
+	}
+	check(c.Service.LoginBackoffBase <= c.Service.LoginLockoutDuration, "LOGIN_BACKOFF_BASE must not exceed LOGIN_LOCKOUT_DURATION")
+
+	counts := []struct {
+		name  string
+		value int
+	}{
+		{"API_CLIENT_RATE_LIMIT", c.Service.APIClientRateLimit},
+		{"LOGIN_MAX_FAILED_ATTEMPTS", c.Service.LoginMaxFailedAttempts},
+		{"LOGIN_IP_MAX_FAILED_ATTEMPTS", c.Service.LoginIPMaxFailedAttempts},
+		{"PASSWORD_MIN_LENGTH", c.Service.PasswordMinLength},
+		{"PASSWORD_RESET_MAX_PER_EMAIL", c.Service.PasswordResetMaxPerEmail},
+		{"PASSWORD_RESET_MAX_PER_IP", c.Service.PasswordResetMaxPerIP},
+		{"PHONE_NATIONAL_LENGTH", c.Service.PhoneNationalLength},
+		{"CONTACT_VERIFICATION_MAX_ATTEMPTS", c.Service.ContactVerificationMaxAttempts},
+		{"TRANSFER_OTP_MAX_ATTEMPTS", c.Service.TransferOTPMaxAttempts},
+		{"DORMANCY_MONTHS", c.Service.DormancyMonths},
+		{"KYC_MAX_DOCUMENT_MB", c.Service.KYCMaxDocumentMB},
+	}
+	for _, n := range counts {
+		check(n.value > 0, "%s must be positive, got %d", n.name, n.value)
+	}
+
+	amounts := []struct {
+		name  string
+		value float64
+	}{
+		{"TRANSFER_OTP_THRESHOLD", c.Service.TransferOTPThreshold},
+		{"TRANSFER_APPROVAL_THRESHOLD", c.Service.TransferApprovalThreshold},
+		{"ADJUSTMENT_DAILY_CEILING", c.Service.AdjustmentDailyCeiling},
+		{"ADJUSTMENT_NO_APPROVAL_LIMIT", c.Service.AdjustmentNoApprovalLimit},
+	}
+	for _, a := range amounts {
+		check(a.value > 0, "%s must be positive, got %v", a.name, a.value)
+	}
+	check(c.Service.AdjustmentNoApprovalLimit <= c.Service.AdjustmentDailyCeiling,
+		"ADJUSTMENT_NO_APPROVAL_LIMIT must not exceed ADJUSTMENT_DAILY_CEILING")
+	_, err = strconv.ParseUint(c.Service.PhoneDefaultCountryCode, 10, 16)
+	check(err == nil, "PHONE_DEFAULT_COUNTRY_CODE must be digits, got %q", c.Service.PhoneDefaultCountryCode)
+
+	return errors.Join(problems...)
+}
@@ -1,35 +1,253 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/MMII0220/MiniBank/config"
 	"github.com/MMII0220/MiniBank/internal/controller"
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/domain/contracts"
+	"github.com/MMII0220/MiniBank/internal/jwtkeys"
+	"github.com/MMII0220/MiniBank/internal/notify"
 	"github.com/MMII0220/MiniBank/internal/redis"
 	"github.com/MMII0220/MiniBank/internal/repository"
+	"github.com/MMII0220/MiniBank/internal/screening"
 	"github.com/MMII0220/MiniBank/internal/service"
+	"github.com/MMII0220/MiniBank/internal/storage"
 )
 
-// AppRun starts the application in main.go
-func AppRun() {
-	dbConn, err := config.InitDB()
+// AppRun starts the application in main.go.
+// SIGINT/SIGTERM останавливает прием запросов, дожидается завершения начатых операций
+// (не дольше SHUTDOWN_TIMEOUT) и фоновых задач, затем закрывает Redis и БД.
+func AppRun(cfg *config.Config) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	dbConn, err := config.InitDB(cfg.Database)
 	if err != nil {
 		log.Fatal("failed to initialize database: ", err)
 	}
 	defer config.CloseDB()
 
-	if err := redis.InitRedisConnection(); err != nil {
+	if err := checkSchema(ctx, dbConn, cfg.Database.MigrateOnStart); err != nil {
+		log.Fatal("refusing to start: ", err)
+	}
+
+	if err := redis.InitRedisConnection(ctx, cfg.Redis); err != nil {
 		log.Printf("WARNING: Cannot connect to Redis: %v", err)
 		log.Printf("Application will continue without Redis caching")
 	} else {
 		log.Println("Redis connected successfully")
 	}
+	defer redis.Close()
 
 	// redisClient := redis.GetRedisClient()
 
 	rep := repository.NewRepository(dbConn)
 	svc := service.NewService(rep)
+	svc.SetConfig(cfg.Service)
+
+	if keys := loadJWTKeys(cfg.JWT); keys != nil {
+		svc.SetKeyring(keys)
+	}
+
+	if store := loadWatchlists(cfg.Sanctions); store != nil {
+		svc.SetScreener(store)
+	}
+
+	// Без реального SMS/email шлюза уведомления можно складывать в файл (NOTIFY_FILE)
+	if cfg.NotifyFile != "" {
+		svc.SetNotifier(notify.NewFileNotifier(cfg.NotifyFile))
+	}
+
+	svc.SetDocumentStore(loadDocumentStore(cfg.Storage))
+
+	bootstrapAdmin(ctx, svc, cfg.Bootstrap)
+
+	var jobs sync.WaitGroup
+	jobs.Add(2)
+	go func() {
+		defer jobs.Done()
+		runExpiryJobs(ctx, svc, cfg.Jobs.ExpiryInterval)
+	}()
+	go func() {
+		defer jobs.Done()
+		runDormancyJob(ctx, svc, cfg.Jobs.DormancyScanInterval)
+	}()
+
 	ctr := controller.NewController(svc)
+	srv := newHTTPServer(cfg.Server, ctr.SetupRoutes())
+
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Printf("HTTP server listening on %s", srv.Addr)
+		serverErr <- srv.ListenAndServe()
+	}()
 
-	ctr.SetupRoutes()
+	select {
+	case err := <-serverErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("HTTP server failed: %v", err)
+		}
+	case <-ctx.Done():
+		log.Printf("Shutdown signal received, draining in-flight requests")
+	}
+	stop()
+
+	timeout := cfg.Server.ShutdownTimeout
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		// Запросы, не успевшие за SHUTDOWN_TIMEOUT, обрываются: их транзакции откатываются, а не коммитятся наполовину
+		log.Printf("WARNING: in-flight requests did not finish within %s, closing connections: %v", timeout, err)
+		srv.Close()
+	}
+
+	jobs.Wait()
+	log.Println("Shutdown complete")
+}
+
+// newHTTPServer - HTTP сервер на порту ROUTER_RUN с таймаутами HTTP_*_TIMEOUT.
+// WriteTimeout должен покрывать самую долгую операцию, включая загрузку документа KYC.
+func newHTTPServer(cfg config.Server, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              ":" + cfg.Port,
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+}
+
+// loadJWTKeys загружает ключи подписи токенов из JWT_KEYS_DIR, подписывает ключ JWT_SIGNING_KID.
+// Для ротации в каталог кладется новый ключ и меняется JWT_SIGNING_KID, старый остается до истечения его токенов.
+func loadJWTKeys(cfg config.JWT) *jwtkeys.Keyring {
+	if cfg.KeysDir == "" {
+		log.Printf("WARNING: JWT_KEYS_DIR is not set, tokens are signed with a temporary key and expire on restart")
+		return nil
+	}
+
+	keys, err := jwtkeys.LoadDir(cfg.KeysDir, cfg.SigningKID)
+	if err != nil {
+		log.Fatal("failed to load jwt keys: ", err)
+	}
+	log.Printf("Loaded jwt keys from %s, signing with %s", cfg.KeysDir, keys.SigningKeyID())
+	return keys
+}
+
+// loadWatchlists загружает санкционные списки из файлов SANCTIONS_LISTS (через запятую)
+func loadWatchlists(cfg config.Sanctions) *screening.Store {
+	if len(cfg.Lists) == 0 {
+		log.Printf("WARNING: SANCTIONS_LISTS is not set, sanctions screening is disabled")
+		return nil
+	}
+
+	store := screening.NewStore(cfg.MatchThreshold)
+	for _, path := range cfg.Lists {
+		entries, err := screening.LoadFile(path)
+		if err != nil {
+			log.Fatal("failed to load sanctions list: ", err)
+		}
+		store.Add(entries...)
+		log.Printf("Loaded %d sanctions entries from %s", len(entries), path)
+	}
+	return store
+}
+
+// loadDocumentStore - хранилище документов KYC: KYC_STORAGE=disk (каталог KYC_STORAGE_DIR)
+// или s3 (бакет KYC_S3_BUCKET). Клиент S3 подключается через storage.S3Client,
+// без него используется локальная замена LocalS3 в KYC_S3_LOCAL_DIR.
+func loadDocumentStore(cfg config.Storage) contracts.DocumentStoreI {
+	switch cfg.Backend {
+	case "s3":
+		log.Printf("KYC documents are stored in bucket %s of the local S3 stand-in at %s", cfg.S3Bucket, cfg.S3LocalDir)
+		return storage.NewBucketStore(storage.NewLocalS3(cfg.S3LocalDir), cfg.S3Bucket)
+	default:
+		store, err := storage.NewDiskStore(cfg.Dir)
+		if err != nil {
+			log.Fatal("failed to initialize kyc document storage: ", err)
+		}
+		log.Printf("KYC documents are stored on disk in %s", cfg.Dir)
+		return store
+	}
+}
+
+// bootstrapAdmin создает первого админа из BOOTSTRAP_ADMIN_* при пустой системе.
+// Остальные сотрудники появляются только по приглашению админа.
+func bootstrapAdmin(ctx context.Context, svc *service.Service, cfg config.Bootstrap) {
+	if cfg.Email == "" {
+		return
+	}
+
+	created, err := svc.BootstrapAdmin(ctx, domain.ReqRegister{
+		FullName: cfg.Name,
+		Phone:    cfg.Phone,
+		Email:    cfg.Email,
+		Password: cfg.Password,
+	})
+	if err != nil {
+		log.Fatal("failed to bootstrap admin: ", err)
+	}
+	if created {
+		log.Printf("Bootstrap admin %s created, enroll two-factor authentication before using /admin", cfg.Email)
+	}
+}
+
+// runExpiryJobs раз в EXPIRY_JOB_INTERVAL (по умолчанию минута) закрывает просроченные крупные переводы (со снятием удержаний)
+// и админские действия, не дождавшиеся второго админа. Останавливается с отменой ctx;
+// начатый проход доводится до конца, чтобы не оборвать снятие удержаний.
+func runExpiryJobs(ctx context.Context, svc *service.Service, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		runCtx := context.WithoutCancel(ctx)
+		if count, err := svc.ExpirePendingTransfers(runCtx); err != nil {
+			log.Printf("WARNING: failed to expire pending transfers: %v", err)
+		} else if count > 0 {
+			log.Printf("Expired %d pending transfers", count)
+		}
+
+		if count, err := svc.ExpirePendingActions(runCtx); err != nil {
+			log.Printf("WARNING: failed to expire pending admin actions: %v", err)
+		} else if count > 0 {
+			log.Printf("Expired %d pending admin actions", count)
+		}
+	}
+}
+
+// runDormancyJob при старте и затем раз в DORMANCY_SCAN_INTERVAL (по умолчанию сутки)
+// помечает спящими счета без активности клиента; останавливается с отменой ctx
+func runDormancyJob(ctx context.Context, svc *service.Service, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if count, err := svc.MarkDormantAccounts(context.WithoutCancel(ctx)); err != nil {
+			log.Printf("WARNING: failed to mark dormant accounts: %v", err)
+		} else if count > 0 {
+			log.Printf("Marked %d accounts dormant", count)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
 }
@@ -0,0 +1,45 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/MMII0220/MiniBank/config"
+	"github.com/MMII0220/MiniBank/internal/repository"
+	"github.com/MMII0220/MiniBank/internal/service"
+)
+
+// AuditVerify - команда `minibank audit-verify`: проверяет hash цепочку audit_events.
+// Код выхода 0 - цепочка цела, 1 - найдено удаление или правка записи, 2 - проверку выполнить не удалось.
+func AuditVerify(cfg *config.Config) int {
+	dbConn, err := config.InitDB(cfg.Database)
+	if err != nil {
+		log.Printf("failed to initialize database: %v", err)
+		return 2
+	}
+	defer config.CloseDB()
+
+	// Проверку большой цепочки можно прервать Ctrl+C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	svc := service.NewService(repository.NewRepository(dbConn))
+	svc.SetConfig(cfg.Service)
+	report, err := svc.VerifyAuditChain(ctx)
+	if err != nil {
+		log.Printf("audit chain verification failed: %v", err)
+		return 2
+	}
+
+	if !report.Valid() {
+		fmt.Printf("audit chain BROKEN at event #%d (%d events verified before it): %s\n", report.BrokenAt, report.Checked, report.Problem)
+		return 1
+	}
+	// Hash последней записи стоит сохранять вне БД: удаление хвоста цепочки видно только по нему
+	fmt.Printf("audit chain OK: %d events, head #%d %s\n", report.Checked, report.HeadID, report.HeadHash)
+	return 0
+}
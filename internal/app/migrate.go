@@ -0,0 +1,119 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/MMII0220/MiniBank/config"
+	"github.com/MMII0220/MiniBank/internal/migrator"
+	"github.com/MMII0220/MiniBank/migration"
+	"github.com/jmoiron/sqlx"
+)
+
+const migrateUsage = "usage: minibank migrate up | down [N|all] | status | force VERSION"
+
+// Migrate - команда `minibank migrate`: встроенные миграции схемы под advisory lock.
+// Код выхода 0 - успешно, 1 - ошибка миграции, 2 - неверные аргументы или нет подключения к БД.
+func Migrate(cfg *config.Config, args []string) int {
+	if len(args) == 0 {
+		log.Print(migrateUsage)
+		return 2
+	}
+
+	dbConn, err := config.InitDB(cfg.Database)
+	if err != nil {
+		log.Printf("failed to initialize database: %v", err)
+		return 2
+	}
+	defer config.CloseDB()
+
+	m, err := migrator.New(dbConn, migration.FS)
+	if err != nil {
+		log.Printf("failed to load embedded migrations: %v", err)
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch {
+	case args[0] == "up" && len(args) == 1:
+		applied, err := m.Up(ctx)
+		for _, migration := range applied {
+			fmt.Printf("applied %s\n", migration)
+		}
+		if err != nil {
+			log.Printf("migrate up failed: %v", err)
+			return 1
+		}
+		fmt.Printf("schema is at version %d\n", m.Latest())
+	case args[0] == "down" && len(args) <= 2:
+		steps := 1
+		if len(args) == 2 && args[1] == "all" {
+			steps = math.MaxInt
+		} else if len(args) == 2 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps <= 0 {
+				log.Print(migrateUsage)
+				return 2
+			}
+		}
+		reverted, err := m.Down(ctx, steps)
+		for _, migration := range reverted {
+			fmt.Printf("reverted %s\n", migration)
+		}
+		if err != nil {
+			log.Printf("migrate down failed: %v", err)
+			return 1
+		}
+	case args[0] == "status" && len(args) == 1:
+		status, err := m.Status(ctx)
+		if err != nil {
+			log.Printf("migrate status failed: %v", err)
+			return 1
+		}
+		fmt.Printf("version %d (latest %d), dirty: %t\n", status.Version, status.Latest, status.Dirty)
+		for _, migration := range status.Pending {
+			fmt.Printf("pending %s\n", migration)
+		}
+	case args[0] == "force" && len(args) == 2:
+		version, err := strconv.ParseUint(args[1], 10, 32)
+		if err != nil {
+			log.Print(migrateUsage)
+			return 2
+		}
+		if err := m.Force(ctx, uint(version)); err != nil {
+			log.Printf("migrate force failed: %v", err)
+			return 1
+		}
+		fmt.Printf("schema version forced to %d\n", version)
+	default:
+		log.Print(migrateUsage)
+		return 2
+	}
+	return 0
+}
+
+// checkSchema перед запуском сервера: при DB_MIGRATE_ON_START сначала применяет миграции
+// (инстансы, стартующие вместе, ждут друг друга на advisory lock), затем отказывается работать со схемой старее бинарника
+func checkSchema(ctx context.Context, db *sqlx.DB, migrateOnStart bool) error {
+	m, err := migrator.New(db, migration.FS)
+	if err != nil {
+		return err
+	}
+	if migrateOnStart {
+		applied, err := m.Up(ctx)
+		for _, migration := range applied {
+			log.Printf("Applied migration %s", migration)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return m.Check(ctx)
+}
@@ -4,16 +4,42 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/MMII0220/MiniBank/internal/controller/dto"
 	"github.com/MMII0220/MiniBank/internal/domain"
 	"github.com/gin-gonic/gin"
 )
 
+func restrictionJSON(r domain.AccountRestriction) gin.H {
+	res := gin.H{
+		"id":         r.ID,
+		"account_id": r.AccountID,
+		"mode":       r.Mode,
+		"category":   r.Category,
+		"reason":     r.Reason,
+		"created_by": r.CreatedBy,
+		"created_at": r.CreatedAt,
+		"active":     r.Active(time.Now()),
+	}
+	if r.Mode == domain.RestrictionLegalHold {
+		res["amount"] = r.Amount
+	}
+	if !r.ExpiresAt.IsZero() {
+		res["expires_at"] = r.ExpiresAt
+	}
+	if !r.LiftedAt.IsZero() {
+		res["lifted_at"] = r.LiftedAt
+		res["lifted_by"] = r.LiftedBy
+	}
+	return res
+}
+
+// Установка и снятие ограничений счета: debit_freeze, credit_freeze, full_block (по умолчанию), legal_hold
 func (ctr *Controller) blockUnblockAccountHandler(c *gin.Context) {
 	currentUser := c.MustGet("currentUser").(domain.User)
-	if !currentUser.IsAdmin() {
-		c.JSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+	if !currentUser.Can(domain.PermAccountsBlock) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
 		return
 	}
 
@@ -29,22 +55,148 @@ func (ctr *Controller) blockUnblockAccountHandler(c *gin.Context) {
 		return
 	}
 
-	block := req.Block
+	action := req.ToDomain()
+
+	if !action.Block {
+		// Снятие любого ограничения требует одобрения вторым админом
+		if !currentUser.Can(domain.ActionUnblock.ProposePermission()) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
+			return
+		}
+		pending, err := ctr.svc(c).ProposeAdminAction(c.Request.Context(), domain.PendingAction{
+			Type:            domain.ActionUnblock,
+			AccountID:       accountID,
+			RestrictionMode: action.Mode,
+			Reason:          action.Reason,
+		}, currentUser.ID)
+		if err != nil {
+			ctr.translateError(c, err)
+			return
+		}
+		c.JSON(http.StatusAccepted, gin.H{
+			"message":   fmt.Sprintf("lift of %s from account %d is pending approval", action.Mode, accountID),
+			"action_id": pending.ID,
+		})
+		return
+	}
 
 	// Controller передает только HTTP параметры в Service
-	if err := ctr.service.BlockUnblockAccount(accountID, block, currentUser.ID, req.Reason); err != nil {
+	restriction, err := ctr.svc(c).RestrictAccount(c.Request.Context(), currentUser.ID, accountID, action)
+	if err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	message := fmt.Sprintf("account %d blocked", accountID)
+	if action.Mode != domain.RestrictionFullBlock {
+		message = fmt.Sprintf("%s set on account %d", action.Mode, accountID)
+	}
+	c.JSON(http.StatusOK, gin.H{"message": message, "restriction": restrictionJSON(restriction)})
+}
+
+// История ограничений счета, включая снятые и истекшие
+func (ctr *Controller) getAccountRestrictionsHandler(c *gin.Context) {
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || accountID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	restrictions, err := ctr.svc(c).AccountRestrictions(c.Request.Context(), accountID)
+	if err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	result := make([]gin.H, 0, len(restrictions))
+	for _, r := range restrictions {
+		result = append(result, restrictionJSON(r))
+	}
+	c.JSON(http.StatusOK, gin.H{"restrictions": result})
+}
+
+// Действующие блокировки входа после серии неудачных попыток
+func (ctr *Controller) getLoginLocksHandler(c *gin.Context) {
+	locks, err := ctr.svc(c).LoginLocks(c.Request.Context())
+	if err != nil {
 		ctr.translateError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("account %d %s", accountID, map[bool]string{true: "blocked", false: "unblocked"}[block])})
+	result := make([]gin.H, 0, len(locks))
+	for _, l := range locks {
+		result = append(result, gin.H{
+			"email":        l.Email,
+			"user_id":      l.UserID,
+			"failed_count": l.FailedCount,
+			"locked_until": l.LockedUntil,
+			"created_at":   l.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"locks":       result,
+		"total_count": len(result),
+	})
 }
 
-func (ctr *Controller) getAuditLogsHandler(c *gin.Context) {
-	logs, err := ctr.service.AuditLogs()
+// Досрочное снятие блокировки входа пользователя
+func (ctr *Controller) unlockLoginHandler(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(domain.User)
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || userID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var req dto.ReqUnlockLoginHTTP
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason is required"})
+		return
+	}
+
+	if err := ctr.svc(c).UnlockLogin(c.Request.Context(), userID, currentUser.ID, req.Reason); err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("login for user %d unlocked", userID)})
+}
+
+// Ручная корректировка баланса: зачисление или списание с кодом причины, в пределах дневного потолка админа
+func (ctr *Controller) adjustBalanceHandler(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(domain.User)
+
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || accountID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	var req dto.ReqBalanceAdjustmentHTTP
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	adj, err := ctr.svc(c).AdjustBalance(c.Request.Context(), currentUser.ID, accountID, req.ToDomain())
 	if err != nil {
 		ctr.translateError(c, err)
 		return
 	}
-	c.JSON(200, logs)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"id":             adj.ID,
+		"account_id":     adj.AccountID,
+		"transaction_id": adj.TransactionID,
+		"direction":      adj.Direction,
+		"amount":         adj.Amount,
+		"currency":       adj.Currency,
+		"reason_code":    adj.ReasonCode,
+		"note":           adj.Note,
+		"balance_before": adj.BalanceBefore,
+		"balance_after":  adj.BalanceAfter,
+		"created_at":     adj.CreatedAt,
+	})
 }
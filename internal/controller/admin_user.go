@@ -0,0 +1,160 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/MMII0220/MiniBank/internal/controller/dto"
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+func adminUserJSON(user domain.User) gin.H {
+	item := profileUserJSON(user)
+	item["disabled"] = user.Disabled
+	return item
+}
+
+// userIDParam - id пользователя из пути, при ошибке ответ уже отправлен
+func userIDParam(c *gin.Context) (int, bool) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || userID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return 0, false
+	}
+	return userID, true
+}
+
+// Поиск клиентов по имени, email, телефону или номеру карты
+func (ctr *Controller) searchUsersHandler(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(domain.User)
+
+	var req dto.ReqUserSearchHTTP
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	users, err := ctr.svc(c).SearchUsers(c.Request.Context(), currentUser.ID, req.ToDomain())
+	if err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	result := make([]gin.H, 0, len(users))
+	for _, u := range users {
+		result = append(result, adminUserJSON(u))
+	}
+	c.JSON(http.StatusOK, gin.H{"users": result, "total_count": len(result)})
+}
+
+// Карточка клиента: счета, карты, лимит, последние операции и активные сессии
+func (ctr *Controller) getUserDetailHandler(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(domain.User)
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	detail, err := ctr.svc(c).UserDetail(c.Request.Context(), currentUser.ID, userID)
+	if err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	accounts := make([]gin.H, 0, len(detail.Accounts))
+	for _, a := range detail.Accounts {
+		accounts = append(accounts, gin.H{
+			"id":          a.ID,
+			"currency":    a.Currency,
+			"balance":     a.Balance,
+			"hold_amount": a.HoldAmount,
+			"blocked":     a.Blocked,
+			"type":        a.Type,
+		})
+	}
+	cards := make([]gin.H, 0, len(detail.Cards))
+	for _, card := range detail.Cards {
+		cards = append(cards, gin.H{
+			"id":          card.ID,
+			"account_id":  card.AccountID,
+			"card_number": maskCardNumber(card.CardNumber),
+			"holder_name": card.CardHolderName,
+			"expiry":      card.ExpiryDate.Format("01/06"),
+		})
+	}
+	sessions := make([]gin.H, 0, len(detail.Sessions))
+	for _, s := range detail.Sessions {
+		sessions = append(sessions, gin.H{
+			"id":           s.ID,
+			"device_id":    s.DeviceID,
+			"user_agent":   s.UserAgent,
+			"ip":           s.IP,
+			"created_at":   s.CreatedAt,
+			"last_used_at": s.LastUsedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user":     adminUserJSON(detail.User),
+		"accounts": accounts,
+		"cards":    cards,
+		"limit": gin.H{
+			"daily_amount": detail.Limit.DailyAmount,
+			"used_today":   detail.TodayUsage,
+		},
+		"recent_transactions": detail.RecentTransactions,
+		"sessions":            sessions,
+	})
+}
+
+// История операций клиента
+func (ctr *Controller) getUserHistoryHandler(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(domain.User)
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	transactions, err := ctr.svc(c).UserHistory(c.Request.Context(), currentUser.ID, userID)
+	if err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"history_logs": transactions})
+}
+
+func (ctr *Controller) disableUserHandler(c *gin.Context) {
+	ctr.setUserDisabled(c, true)
+}
+
+func (ctr *Controller) enableUserHandler(c *gin.Context) {
+	ctr.setUserDisabled(c, false)
+}
+
+// setUserDisabled - отключение отзывает все сессии пользователя, включение возвращает вход
+func (ctr *Controller) setUserDisabled(c *gin.Context, disable bool) {
+	currentUser := c.MustGet("currentUser").(domain.User)
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req dto.ReqUserStatusHTTP
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason is required"})
+		return
+	}
+
+	if err := ctr.svc(c).SetUserDisabled(c.Request.Context(), currentUser.ID, userID, disable, req.Reason); err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	status := "enabled"
+	if disable {
+		status = "disabled"
+	}
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("user %d %s", userID, status)})
+}
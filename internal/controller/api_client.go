@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/MMII0220/MiniBank/internal/controller/dto"
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/gin-gonic/gin"
+)
+
+// clientRouteScopes - маршруты, доступные API клиентам, и scope для каждого.
+// Маршрута нет в списке - API клиенту он закрыт, даже если права владельца позволяют.
+var clientRouteScopes = map[string]domain.Scope{
+	"GET /api/accounts":             domain.ScopeAccountsRead,
+	"GET /api/history":              domain.ScopeAccountsRead,
+	"POST /api/transfer":            domain.ScopeTransfersInitiate,
+	"POST /api/transfer/confirm":    domain.ScopeTransfersInitiate,
+	"GET /admin/getAuditLogs":       domain.ScopeAuditRead,
+	"GET /admin/audit-logs/export":  domain.ScopeAuditRead,
+	"GET /admin/accounts/:id/audit": domain.ScopeAuditRead,
+}
+
+// clientRouteScope - scope маршрута текущего запроса, пусто - маршрут клиентам закрыт
+func clientRouteScope(c *gin.Context) domain.Scope {
+	return clientRouteScopes[c.Request.Method+" "+c.FullPath()]
+}
+
+// Создание API клиента; client_secret показывается только в этом ответе
+func (ctr *Controller) createAPIClientHandler(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(domain.User)
+
+	var req dto.ReqCreateAPIClientHTTP
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	client, secret, err := ctr.svc(c).CreateAPIClient(c.Request.Context(), req.ToDomain(), currentUser.ID)
+	if err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"id":                    client.ID,
+		"client_id":             client.ClientID,
+		"client_secret":         secret,
+		"owner_user_id":         client.OwnerUserID,
+		"scopes":                client.Scopes,
+		"rate_limit_per_minute": client.RateLimitPerMinute,
+	})
+}
+
+func (ctr *Controller) getAPIClientsHandler(c *gin.Context) {
+	clients, err := ctr.svc(c).APIClients(c.Request.Context())
+	if err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	result := make([]gin.H, 0, len(clients))
+	for _, client := range clients {
+		item := gin.H{
+			"id":                    client.ID,
+			"client_id":             client.ClientID,
+			"name":                  client.Name,
+			"owner_user_id":         client.OwnerUserID,
+			"scopes":                client.Scopes,
+			"rate_limit_per_minute": client.RateLimitPerMinute,
+			"created_by":            client.CreatedBy,
+			"created_at":            client.CreatedAt,
+		}
+		if !client.LastUsedAt.IsZero() {
+			item["last_used_at"] = client.LastUsedAt
+		}
+		if client.Revoked() {
+			item["revoked_at"] = client.RevokedAt
+		}
+		result = append(result, item)
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (ctr *Controller) revokeAPIClientHandler(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(domain.User)
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid api client id"})
+		return
+	}
+
+	if err := ctr.svc(c).RevokeAPIClient(c.Request.Context(), id, currentUser.ID); err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "API client revoked"})
+}
+
+// OAuth2 token endpoint (client credentials), ошибки в формате RFC 6749.
+// Учетные данные принимаются в теле запроса или в заголовке Authorization: Basic.
+func (ctr *Controller) clientTokenHandler(c *gin.Context) {
+	var req dto.ReqClientTokenHTTP
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request"})
+		return
+	}
+	if id, secret, ok := c.Request.BasicAuth(); ok {
+		req.ClientID, req.ClientSecret = id, secret
+	}
+
+	c.Header("Cache-Control", "no-store")
+	token, err := ctr.svc(c).IssueClientToken(c.Request.Context(), req.ToDomain())
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, token)
+	case errors.Is(err, errs.ErrInvalidClient):
+		c.Header("WWW-Authenticate", `Basic realm="minibank"`)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid_client"})
+	case errors.Is(err, errs.ErrUnsupportedGrantType):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported_grant_type"})
+	case errors.Is(err, errs.ErrInvalidScope):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_scope"})
+	default:
+		ctr.translateError(c, err)
+	}
+}
@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/MMII0220/MiniBank/internal/controller/dto"
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// Очередь крупных переводов для админа, ?status=pending|approved|rejected|expired
+func (ctr *Controller) getApprovalsHandler(c *gin.Context) {
+	status := domain.PendingTransferStatus(c.Query("status"))
+	switch status {
+	case "", domain.PendingTransferPending, domain.PendingTransferApproved,
+		domain.PendingTransferRejected, domain.PendingTransferExpired:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+		return
+	}
+
+	transfers, err := ctr.svc(c).PendingTransfers(c.Request.Context(), status)
+	if err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"pending_transfers": transfers,
+		"total_count":       len(transfers),
+	})
+}
+
+// Переводы с бизнес-счетов, которые ждут подписи текущего пользователя
+func (ctr *Controller) getSignatoryApprovalsHandler(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(domain.User)
+
+	transfers, err := ctr.svc(c).PendingTransfersForApprover(c.Request.Context(), currentUser.ID)
+	if err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"pending_transfers": transfers,
+		"total_count":       len(transfers),
+	})
+}
+
+func (ctr *Controller) approveTransferHandler(c *gin.Context) {
+	ctr.decideTransfer(c, true)
+}
+
+func (ctr *Controller) rejectTransferHandler(c *gin.Context) {
+	ctr.decideTransfer(c, false)
+}
+
+// Общая часть approve/reject, права проверяются в сервисе
+func (ctr *Controller) decideTransfer(c *gin.Context, approve bool) {
+	currentUser := c.MustGet("currentUser").(domain.User)
+
+	pendingID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || pendingID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid pending transfer id"})
+		return
+	}
+
+	var req dto.ReqApprovalDecisionHTTP
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+			return
+		}
+	}
+	if !approve && req.Note == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "note is required to reject a transfer"})
+		return
+	}
+
+	if err := ctr.svc(c).DecidePendingTransfer(c.Request.Context(), pendingID, currentUser, approve, req.Note); err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	message := "transfer approved"
+	if !approve {
+		message = "transfer rejected"
+	}
+	c.JSON(http.StatusOK, gin.H{"message": message})
+}
+
+// Добавление второго подписанта, счет становится бизнес-счетом
+func (ctr *Controller) addAccountSignatoryHandler(c *gin.Context) {
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || accountID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	var req dto.ReqAccountSignatoryHTTP
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	if err := ctr.svc(c).AddAccountSignatory(c.Request.Context(), accountID, req.UserID); err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "signatory added"})
+}
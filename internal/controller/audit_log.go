@@ -0,0 +1,203 @@
+package controller
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/controller/dto"
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// auditLogRecord - запись account_audit в ответах и выгрузке
+type auditLogRecord struct {
+	ID          int       `json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	AccountID   int       `json:"account_id"`
+	AdminID     int       `json:"admin_id"`
+	APIClientID int       `json:"api_client_id,omitempty"`
+	Action      string    `json:"action"`
+	Reason      string    `json:"reason"`
+}
+
+func newAuditLogRecord(l domain.AdminAuditLog) auditLogRecord {
+	return auditLogRecord{
+		ID:          l.ID,
+		CreatedAt:   l.CreatedAt,
+		AccountID:   l.AccountID,
+		AdminID:     l.AdminID,
+		APIClientID: l.APIClientID,
+		Action:      l.Action,
+		Reason:      l.Reason,
+	}
+}
+
+func auditLogPageJSON(page domain.AuditLogPage) gin.H {
+	records := make([]auditLogRecord, 0, len(page.Logs))
+	for _, l := range page.Logs {
+		records = append(records, newAuditLogRecord(l))
+	}
+	return gin.H{"audit_logs": records, "next_cursor": page.NextCursor}
+}
+
+// bindAuditLogFilter - фильтры из query, при ошибке ответ уже отправлен
+func bindAuditLogFilter(c *gin.Context) (domain.AuditLogFilter, bool) {
+	var req dto.ReqAuditLogQueryHTTP
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return domain.AuditLogFilter{}, false
+	}
+	filter, err := req.ToDomain()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return filter, false
+	}
+	return filter, true
+}
+
+// Журнал админских действий с фильтрами и курсорной пагинацией
+func (ctr *Controller) getAuditLogsHandler(c *gin.Context) {
+	filter, ok := bindAuditLogFilter(c)
+	if !ok {
+		return
+	}
+
+	page, err := ctr.svc(c).AuditLogs(c.Request.Context(), filter)
+	if err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, auditLogPageJSON(page))
+}
+
+// Хронология событий по одному счету
+func (ctr *Controller) getAccountAuditTimelineHandler(c *gin.Context) {
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || accountID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+	filter, ok := bindAuditLogFilter(c)
+	if !ok {
+		return
+	}
+
+	page, err := ctr.svc(c).AccountAuditTimeline(c.Request.Context(), accountID, filter)
+	if err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+	response := auditLogPageJSON(page)
+	response["account_id"] = accountID
+	c.JSON(http.StatusOK, response)
+}
+
+// auditLogWriter - формат выгрузки журнала
+type auditLogWriter interface {
+	Write(record auditLogRecord) error
+	Flush() error
+}
+
+type csvAuditLogWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (cw *csvAuditLogWriter) Write(record auditLogRecord) error {
+	if !cw.headerWritten {
+		cw.headerWritten = true
+		if err := cw.w.Write([]string{"id", "created_at", "account_id", "admin_id", "api_client_id", "action", "reason"}); err != nil {
+			return err
+		}
+	}
+	return cw.w.Write([]string{
+		strconv.Itoa(record.ID),
+		record.CreatedAt.UTC().Format(time.RFC3339),
+		strconv.Itoa(record.AccountID),
+		strconv.Itoa(record.AdminID),
+		strconv.Itoa(record.APIClientID),
+		record.Action,
+		record.Reason,
+	})
+}
+
+func (cw *csvAuditLogWriter) Flush() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// jsonlAuditLogWriter - одна JSON запись на строку (JSON Lines)
+type jsonlAuditLogWriter struct {
+	enc *json.Encoder
+}
+
+func (jw *jsonlAuditLogWriter) Write(record auditLogRecord) error {
+	return jw.enc.Encode(record)
+}
+
+func (jw *jsonlAuditLogWriter) Flush() error {
+	return nil
+}
+
+func newAuditLogWriter(format string, w io.Writer) (auditLogWriter, string, bool) {
+	switch format {
+	case "csv":
+		return &csvAuditLogWriter{w: csv.NewWriter(w)}, "text/csv; charset=utf-8", true
+	case "jsonl":
+		return &jsonlAuditLogWriter{enc: json.NewEncoder(w)}, "application/x-ndjson", true
+	default:
+		return nil, "", false
+	}
+}
+
+// Выгрузка журнала для регулятора: ?format=csv|jsonl и те же фильтры, что у списка.
+// Ответ пишется потоком, поэтому ошибка после первой записи обрывает файл, а не меняет статус.
+func (ctr *Controller) exportAuditLogsHandler(c *gin.Context) {
+	filter, ok := bindAuditLogFilter(c)
+	if !ok {
+		return
+	}
+	format := c.DefaultQuery("format", "csv")
+	writer, contentType, ok := newAuditLogWriter(format, c.Writer)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or jsonl"})
+		return
+	}
+
+	started := false
+	start := func() {
+		if started {
+			return
+		}
+		started = true
+		c.Header("Content-Type", contentType)
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="audit-log-%s.%s"`, time.Now().UTC().Format("20060102-150405"), format))
+		c.Status(http.StatusOK)
+	}
+
+	err := ctr.svc(c).ExportAuditLogs(c.Request.Context(), filter, func(l domain.AdminAuditLog) error {
+		start()
+		return writer.Write(newAuditLogRecord(l))
+	})
+	if err != nil {
+		if !started {
+			ctr.translateError(c, err)
+			return
+		}
+		log := logger.GetLogger()
+		log.Error().Err(err).Msg("Audit log export interrupted")
+		c.Abort()
+		return
+	}
+	start()
+	if err := writer.Flush(); err != nil {
+		log := logger.GetLogger()
+		log.Error().Err(err).Msg("Failed to flush audit log export")
+	}
+}
@@ -9,6 +9,35 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	deviceHeader = "X-Device-ID"
+	deviceCookie = "minibank_device"
+	// deviceTokenMaxLen - токены устройств, выданные сервером, короче; длинное значение отклоняется до сервиса
+	deviceTokenMaxLen  = 128
+	deviceCookieMaxAge = 365 * 24 * 60 * 60
+)
+
+// deviceToken - токен устройства из тела запроса, заголовка X-Device-ID или cookie; false - значение слишком длинное
+func deviceToken(c *gin.Context, fromBody string) (string, bool) {
+	token := fromBody
+	if token == "" {
+		token = c.GetHeader(deviceHeader)
+	}
+	if token == "" {
+		token, _ = c.Cookie(deviceCookie)
+	}
+	return token, len(token) <= deviceTokenMaxLen
+}
+
+// setDeviceCookie запоминает выданный сервером токен устройства в браузере; API клиенты берут device_token из ответа
+func setDeviceCookie(c *gin.Context, token string) {
+	if token == "" {
+		return
+	}
+	c.SetSameSite(http.SameSiteStrictMode)
+	c.SetCookie(deviceCookie, token, deviceCookieMaxAge, "/", "", c.Request.TLS != nil, true)
+}
+
 func (ctr *Controller) registerHandler(c *gin.Context) {
 	log := logger.GetLogger()
 	log.Info().Str("endpoint", "register").Msg("Registration request received")
@@ -20,15 +49,9 @@ func (ctr *Controller) registerHandler(c *gin.Context) {
 		return
 	}
 
-	var role domain.Role
-	if req.Role != "" && (req.Role == "admin" || req.Role == "user") {
-		role = domain.Role(req.Role)
-	} else {
-		role = domain.RoleUser
-	}
-
+	// Открытая регистрация всегда создает клиента, сотрудники приходят только по приглашению
 	domainReq := req.ToDomain()
-	user, err := ctr.service.Register(domainReq, role)
+	user, err := ctr.svc(c).Register(c.Request.Context(), domainReq, domain.RoleUser)
 	if err != nil {
 		ctr.translateError(c, err)
 		return
@@ -44,13 +67,23 @@ func (ctr *Controller) loginHandler(c *gin.Context) {
 		return
 	}
 
+	// Данные устройства для списка сессий и обнаружения входа с нового устройства
 	domainReq := req.ToDomain()
-	tokenResponse, err := ctr.service.Login(domainReq)
+	var ok bool
+	if domainReq.DeviceID, ok = deviceToken(c, domainReq.DeviceID); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "device id is too long"})
+		return
+	}
+	domainReq.UserAgent = c.Request.UserAgent()
+	domainReq.IP = c.ClientIP()
+
+	tokenResponse, err := ctr.svc(c).Login(c.Request.Context(), domainReq)
 	if err != nil {
 		ctr.translateError(c, err)
 		return
 	}
 
+	setDeviceCookie(c, tokenResponse.DeviceToken)
 	c.JSON(http.StatusOK, tokenResponse)
 }
 
@@ -62,7 +95,7 @@ func (ctr *Controller) refreshTokenHandler(c *gin.Context) {
 	}
 
 	domainReq := req.ToDomain()
-	tokenResponse, err := ctr.service.RefreshToken(domainReq)
+	tokenResponse, err := ctr.svc(c).RefreshToken(c.Request.Context(), domainReq)
 	if err != nil {
 		ctr.translateError(c, err)
 		return
@@ -70,3 +103,33 @@ func (ctr *Controller) refreshTokenHandler(c *gin.Context) {
 
 	c.JSON(http.StatusOK, tokenResponse)
 }
+
+// Выход из текущей сессии: refresh токены сессии отзываются, access токен перестает работать сразу
+func (ctr *Controller) logoutHandler(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(domain.User)
+
+	if err := ctr.svc(c).Logout(c.Request.Context(), currentUser.ID, currentUser.SessionID); err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "logged out"})
+}
+
+// Выход со всех устройств
+func (ctr *Controller) logoutAllHandler(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(domain.User)
+
+	if err := ctr.svc(c).LogoutAll(c.Request.Context(), currentUser.ID); err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "logged out from all sessions"})
+}
+
+// Открытые ключи подписи токенов, чтобы другие сервисы проверяли их без общего секрета
+func (ctr *Controller) jwksHandler(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, ctr.svc(c).JWKS())
+}
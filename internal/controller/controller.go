@@ -2,6 +2,7 @@ package controller
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 
@@ -37,6 +38,10 @@ func (ctr *Controller) translateError(c *gin.Context, err error) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
 	case errors.Is(err, errs.ErrAccountBlocked):
 		c.JSON(http.StatusForbidden, gin.H{"error": "Account is blocked"})
+	case errors.Is(err, errs.ErrAccountDormant):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account is dormant, reactivate it to make outgoing operations", "dormant": true})
+	case errors.Is(err, errs.ErrAccountRestricted):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Operation not allowed by account restriction"})
 	case errors.Is(err, errs.ErrInsufficientFunds):
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Insufficient funds"})
 	case errors.Is(err, errs.ErrInvalidAmount):
@@ -49,15 +54,104 @@ func (ctr *Controller) translateError(c *gin.Context, err error) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Token expired"})
 	case errors.Is(err, errs.ErrRefreshTokenExpired):
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token expired"})
+	case errors.Is(err, errs.ErrRefreshTokenRevoked):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token revoked"})
+	case errors.Is(err, errs.ErrInvalidRefreshToken):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+	case errors.Is(err, errs.ErrSessionExpired):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has expired"})
+	case errors.Is(err, errs.ErrStepUpRequired):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Additional verification required for this session", "step_up_required": true})
+	case errors.Is(err, errs.ErrInvalidMFACode):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid two-factor code"})
+	case errors.Is(err, errs.ErrMFARequired):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Two-factor authentication required"})
+	case errors.Is(err, errs.ErrTokenMalformed), errors.Is(err, errs.ErrInvalidTokenType), errors.Is(err, errs.ErrInvalidTokenClaims):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+	case errors.Is(err, errs.ErrTransferConfirmationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transfer confirmation not found"})
+	case errors.Is(err, errs.ErrOTPExpired):
+		c.JSON(http.StatusGone, gin.H{"error": "Confirmation code has expired"})
+	case errors.Is(err, errs.ErrInvalidOTP):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid confirmation code"})
+	case errors.Is(err, errs.ErrInvalidEmail):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email address"})
+	case errors.Is(err, errs.ErrInvalidPhone):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid phone number"})
+	case errors.Is(err, errs.ErrVerificationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "No pending verification code, request a new one"})
+	case errors.Is(err, errs.ErrPhoneNotVerified):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Recipient phone number is not verified"})
+	case errors.Is(err, errs.ErrWeakPassword):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Password is too weak"})
+	case errors.Is(err, errs.ErrInvalidResetToken):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired password reset token"})
+	case errors.Is(err, errs.ErrInviteNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invite not found"})
+	case errors.Is(err, errs.ErrInviteExpired):
+		c.JSON(http.StatusGone, gin.H{"error": "Invite has expired"})
+	case errors.Is(err, errs.ErrUserDisabled):
+		c.JSON(http.StatusForbidden, gin.H{"error": "User is disabled"})
+	case errors.Is(err, errs.ErrAPIClientNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "API client not found"})
+	case errors.Is(err, errs.ErrInvalidScope):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid scope"})
+	case errors.Is(err, errs.ErrRateLimited):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Rate limit exceeded"})
+	case errors.Is(err, errs.ErrTooManyAttempts):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many failed attempts, try again later"})
+	case errors.Is(err, errs.ErrAccountLocked):
+		c.JSON(http.StatusLocked, gin.H{"error": "Account is temporarily locked"})
 	case errors.Is(err, errs.ErrOperationNotAllowed):
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Operation not allowed"})
+	case errors.Is(err, errs.ErrScreeningPending):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Operation is pending compliance review"})
+	case errors.Is(err, errs.ErrSanctionsMatch):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Operation blocked by sanctions screening"})
+	case errors.Is(err, errs.ErrScreeningReviewNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Screening review not found"})
+	case errors.Is(err, errs.ErrKYCDocumentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "KYC document not found"})
+	case errors.Is(err, errs.ErrKYCDocumentInvalid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported or too large document: JPEG, PNG or PDF expected"})
+	case errors.Is(err, errs.ErrKYCLimitExceeded):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Daily limit for your verification level exceeded, submit KYC documents to raise it"})
+	case errors.Is(err, errs.ErrPendingTransferNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pending transfer not found"})
+	case errors.Is(err, errs.ErrPendingTransferExpired):
+		c.JSON(http.StatusGone, gin.H{"error": "Pending transfer has expired"})
+	case errors.Is(err, errs.ErrSelfApproval):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Initiator cannot approve own operation"})
+	case errors.Is(err, errs.ErrPendingActionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pending action not found"})
+	case errors.Is(err, errs.ErrPendingActionExpired):
+		c.JSON(http.StatusGone, gin.H{"error": "Pending action has expired"})
+	case errors.Is(err, errs.ErrApprovalRequired):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Operation requires approval by another admin"})
+	case errors.Is(err, errs.ErrAdjustmentCeiling):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Adjustment ceiling exceeded, propose a manual_adjustment action for approval"})
+	case errors.Is(err, errs.ErrInvalidLimit):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit amount"})
+	case errors.Is(err, errs.ErrLimitNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Daily limit not found"})
+	case errors.Is(err, errs.ErrInvalidOperation):
+		c.JSON(http.StatusConflict, gin.H{"error": "Invalid operation"})
+	case errors.Is(err, errs.ErrInvalidData):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid data provided"})
+	case errors.Is(err, errs.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	case errors.Is(err, errs.ErrAccountNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
 	default:
 		// Неизвестная ошибка - возвращаем 500 и логируем
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 	}
 }
 
-func (ctr *Controller) AuthMiddleware(requiredRole domain.Role) gin.HandlerFunc {
+// AuthMiddleware проверяет токен и право из матрицы ролей; пустое право - любой вошедший пользователь.
+// Принимает access токены пользователей и токены API клиентов: клиенту доступны только маршруты
+// из clientRouteScopes с выданным ему scope, а права проверяются по роли владельца клиента.
+func (ctr *Controller) AuthMiddleware(required domain.Permission) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -72,20 +166,49 @@ func (ctr *Controller) AuthMiddleware(requiredRole domain.Role) gin.HandlerFunc
 		}
 
 		tokenStr := parts[1]
-		user, err := ctr.service.ParseToken(tokenStr)
+		user, err := ctr.svc(c).ParseToken(c.Request.Context(), tokenStr)
+		if errors.Is(err, errs.ErrRateLimited) {
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+			return
+		}
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
 			return
 		}
 
-		// Проверяем роль если указана
-		if requiredRole != "" && user.Role != requiredRole {
+		// API клиент: маршрут должен быть открыт клиентам и входить в его scopes
+		var scope domain.Scope
+		if user.IsAPIClient() {
+			scope = clientRouteScope(c)
+			if scope == "" || !domain.HasScope(user.Scopes, scope) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "insufficient scope"})
+				return
+			}
+		}
+
+		// Проверяем право если указано
+		if required != "" && !user.Can(required) {
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
 			return
 		}
 
+		// Служебные права доступны только из сессии, подтвержденной вторым фактором.
+		// API клиент входит по секрету, выданному админом, второго фактора у него нет
+		if required != "" && required != domain.PermBankingUse && !user.MFAVerified && !user.IsAPIClient() {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":        "two-factor authentication required",
+				"mfa_required": true,
+			})
+			return
+		}
+
 		// Сохраняем пользователя в контексте
 		c.Set("currentUser", user)
 		c.Next()
+
+		// Каждый запрос API клиента попадает в аудит с id клиента
+		if user.IsAPIClient() {
+			ctr.svc(c).RecordClientRequest(c.Request.Context(), user, scope, fmt.Sprintf("%s %s -> %d", c.Request.Method, c.Request.URL.Path, c.Writer.Status()))
+		}
 	}
 }
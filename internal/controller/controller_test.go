@@ -1,7 +1,11 @@
 package controller
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -9,96 +13,377 @@ import (
 	"time"
 
 	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/domain/contracts"
 	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/jwtkeys"
 	"github.com/gin-gonic/gin"
 )
 
 type mockService struct {
-	blockUnblockFn   func(accountID int, block bool, adminID int, reason string) error
-	parseTokenFn     func(tokenStr string) (domain.User, error)
-	getAllAccountsFn func(userID int) ([]domain.Account, error)
-	depositFn        func(currentUserID int, req domain.ReqTransaction) error
-	withdrawFn       func(currentUserID int, req domain.ReqTransaction) error
-	transferFn       func(currentUserID int, req domain.ReqTransfer) error
-	historyFn        func(idUser int) ([]domain.Transaction, error)
-	registerFn       func(req domain.ReqRegister, role domain.Role) (domain.User, error)
-	loginFn          func(req domain.ReqLogin) (domain.TokenResponse, error)
-	refreshFn        func(req domain.ReqRefreshToken) (domain.TokenResponse, error)
+	restrictAccountFn  func(adminID, accountID int, req domain.ReqAdminAccountAction) (domain.AccountRestriction, error)
+	reactivateFn       func(userID, accountID int, req domain.ReqAccountReactivation) error
+	dormantBalancesFn  func() ([]domain.DormantBalance, error)
+	submitKYCFn        func(userID int, req domain.ReqKYCDocument) (domain.KYCDocument, error)
+	reviewKYCFn        func(docID, adminID int, approve bool, note string) (domain.KYCTier, error)
+	parseTokenFn       func(tokenStr string) (domain.User, error)
+	getAllAccountsFn   func(userID int) ([]domain.Account, error)
+	depositFn          func(currentUserID int, req domain.ReqTransaction) error
+	withdrawFn         func(currentUserID int, req domain.ReqTransaction) error
+	transferFn         func(currentUserID int, req domain.ReqTransfer) (domain.TransferResult, error)
+	historyFn          func(idUser int) ([]domain.Transaction, error)
+	registerFn         func(req domain.ReqRegister, role domain.Role) (domain.User, error)
+	loginFn            func(req domain.ReqLogin) (domain.TokenResponse, error)
+	refreshFn          func(req domain.ReqRefreshToken) (domain.TokenResponse, error)
+	resolveReviewFn    func(reviewID int, adminID int, clear bool, note string) error
+	decideTransferFn   func(pendingID int, approver domain.User, approve bool, note string) error
+	proposeActionFn    func(action domain.PendingAction, proposerID int) (domain.PendingAction, error)
+	logoutFn           func(userID int, sessionID string) error
+	listSessionsFn     func(userID int) ([]domain.Session, error)
+	completeStepUpFn   func(userID int, sessionID string, code string) error
+	unlockLoginFn      func(userID int, adminID int, reason string) error
+	verifyMFAFn        func(req domain.ReqMFAVerify) (domain.TokenResponse, error)
+	confirmTransferFn  func(userID int, confirmationID int, code string, sessionID string) (domain.TransferResult, error)
+	inviteStaffFn      func(email string, role domain.Role, adminID int) (domain.StaffInvite, error)
+	acceptInviteFn     func(req domain.ReqAcceptInvite) (domain.User, error)
+	changePasswordFn   func(userID int, req domain.ReqPasswordChange) error
+	issueClientTokenFn func(req domain.ReqClientToken) (domain.ClientTokenResponse, error)
+	recordedRequests   []string
+	updateProfileFn    func(userID int, req domain.ReqUpdateProfile) (domain.User, error)
+	setUserDisabledFn  func(adminID int, userID int, disable bool, reason string) error
+	// lastMeta - метаданные последнего запроса, переданные в WithRequest
+	lastMeta          domain.RequestMeta
+	exportAuditLogsFn func(filter domain.AuditLogFilter, write func(domain.AdminAuditLog) error) error
+	adminStatsFn      func(from, to time.Time) (domain.AdminStats, error)
+	adjustBalanceFn   func(adminID, accountID int, req domain.ReqBalanceAdjustment) (domain.BalanceAdjustment, error)
 	// other methods not used in these tests
 }
 
-func (m *mockService) BlockUnblockAccount(accountID int, block bool, adminID int, reason string) error {
-	if m.blockUnblockFn != nil {
-		return m.blockUnblockFn(accountID, block, adminID, reason)
+func (m *mockService) RestrictAccount(ctx context.Context, adminID, accountID int, req domain.ReqAdminAccountAction) (domain.AccountRestriction, error) {
+	if m.restrictAccountFn != nil {
+		return m.restrictAccountFn(adminID, accountID, req)
+	}
+	return domain.AccountRestriction{}, nil
+}
+func (m *mockService) AccountRestrictions(ctx context.Context, accountID int) ([]domain.AccountRestriction, error) {
+	return nil, nil
+}
+func (m *mockService) MarkDormantAccounts(ctx context.Context) (int, error) {
+	return 0, nil
+}
+func (m *mockService) ReactivateAccount(ctx context.Context, userID, accountID int, req domain.ReqAccountReactivation) error {
+	if m.reactivateFn != nil {
+		return m.reactivateFn(userID, accountID, req)
+	}
+	return nil
+}
+func (m *mockService) SubmitKYCDocument(ctx context.Context, userID int, req domain.ReqKYCDocument) (domain.KYCDocument, error) {
+	if m.submitKYCFn != nil {
+		return m.submitKYCFn(userID, req)
+	}
+	return domain.KYCDocument{}, nil
+}
+func (m *mockService) KYCStatus(ctx context.Context, userID int) (domain.KYCStatus, error) {
+	return domain.KYCStatus{}, nil
+}
+func (m *mockService) KYCReviewQueue(ctx context.Context, status domain.KYCDocumentStatus) ([]domain.KYCDocument, error) {
+	return nil, nil
+}
+func (m *mockService) OpenKYCDocument(ctx context.Context, docID int) (domain.KYCDocument, io.ReadCloser, error) {
+	return domain.KYCDocument{}, nil, errs.ErrKYCDocumentNotFound
+}
+func (m *mockService) ReviewKYCDocument(ctx context.Context, docID, adminID int, approve bool, note string) (domain.KYCTier, error) {
+	if m.reviewKYCFn != nil {
+		return m.reviewKYCFn(docID, adminID, approve, note)
+	}
+	return domain.KYCUnverified, nil
+}
+func (m *mockService) DormantBalances(ctx context.Context) ([]domain.DormantBalance, error) {
+	if m.dormantBalancesFn != nil {
+		return m.dormantBalancesFn()
+	}
+	return nil, nil
+}
+func (m *mockService) AuditLogs(ctx context.Context, filter domain.AuditLogFilter) (domain.AuditLogPage, error) {
+	return domain.AuditLogPage{}, nil
+}
+func (m *mockService) ExportAuditLogs(ctx context.Context, filter domain.AuditLogFilter, write func(domain.AdminAuditLog) error) error {
+	if m.exportAuditLogsFn != nil {
+		return m.exportAuditLogsFn(filter, write)
 	}
 	return nil
 }
-func (m *mockService) AuditLogs() ([]domain.AdminAuditLog, error) { return nil, nil }
-func (m *mockService) Register(req domain.ReqRegister, role domain.Role) (domain.User, error) {
+func (m *mockService) AdjustBalance(ctx context.Context, adminID, accountID int, req domain.ReqBalanceAdjustment) (domain.BalanceAdjustment, error) {
+	if m.adjustBalanceFn != nil {
+		return m.adjustBalanceFn(adminID, accountID, req)
+	}
+	return domain.BalanceAdjustment{}, nil
+}
+func (m *mockService) AdminStats(ctx context.Context, from, to time.Time) (domain.AdminStats, error) {
+	if m.adminStatsFn != nil {
+		return m.adminStatsFn(from, to)
+	}
+	return domain.AdminStats{}, nil
+}
+func (m *mockService) AccountAuditTimeline(ctx context.Context, accountID int, filter domain.AuditLogFilter) (domain.AuditLogPage, error) {
+	return domain.AuditLogPage{}, nil
+}
+func (m *mockService) Register(ctx context.Context, req domain.ReqRegister, role domain.Role) (domain.User, error) {
 	if m.registerFn != nil {
 		return m.registerFn(req, role)
 	}
 	return domain.User{}, nil
 }
-func (m *mockService) Login(req domain.ReqLogin) (domain.TokenResponse, error) {
+func (m *mockService) Login(ctx context.Context, req domain.ReqLogin) (domain.TokenResponse, error) {
 	if m.loginFn != nil {
 		return m.loginFn(req)
 	}
 	return domain.TokenResponse{}, nil
 }
-func (m *mockService) RefreshToken(req domain.ReqRefreshToken) (domain.TokenResponse, error) {
+func (m *mockService) RefreshToken(ctx context.Context, req domain.ReqRefreshToken) (domain.TokenResponse, error) {
 	if m.refreshFn != nil {
 		return m.refreshFn(req)
 	}
 	return domain.TokenResponse{}, nil
 }
-func (m *mockService) ParseToken(tokenStr string) (domain.User, error) {
+func (m *mockService) ParseToken(ctx context.Context, tokenStr string) (domain.User, error) {
 	if m.parseTokenFn != nil {
 		return m.parseTokenFn(tokenStr)
 	}
 	return domain.User{}, nil
 }
-func (m *mockService) CreateCardForAccount(accountID int, holderName string) (*domain.Card, error) {
+func (m *mockService) CreateCardForAccount(ctx context.Context, accountID int, holderName string) (*domain.Card, error) {
 	return nil, nil
 }
 func (m *mockService) ConvertToBaseCurrency(amount float64, currency string) (float64, error) {
 	return 0, nil
 }
-func (m *mockService) CheckLimitAndCalculateFee(userID int, amount float64, currency string) (float64, error) {
+func (m *mockService) CheckLimitAndCalculateFee(ctx context.Context, userID int, amount float64, currency string) (float64, error) {
 	return 0, nil
 }
 func (m *mockService) CalculateOverlimitFee(amount float64) float64 { return 0 }
 func (m *mockService) IsNewDay(lastReset time.Time) bool            { return false }
-func (m *mockService) Deposit(currentUserID int, req domain.ReqTransaction) error {
+func (m *mockService) Deposit(ctx context.Context, currentUserID int, req domain.ReqTransaction) error {
 	if m.depositFn != nil {
 		return m.depositFn(currentUserID, req)
 	}
 	return nil
 }
-func (m *mockService) Withdraw(currentUserID int, req domain.ReqTransaction) error {
+func (m *mockService) Withdraw(ctx context.Context, currentUserID int, req domain.ReqTransaction) error {
 	if m.withdrawFn != nil {
 		return m.withdrawFn(currentUserID, req)
 	}
 	return nil
 }
-func (m *mockService) Transfer(currentUserID int, req domain.ReqTransfer) error {
+func (m *mockService) Transfer(ctx context.Context, currentUserID int, req domain.ReqTransfer) (domain.TransferResult, error) {
 	if m.transferFn != nil {
 		return m.transferFn(currentUserID, req)
 	}
+	return domain.TransferResult{Status: domain.TransferCompleted}, nil
+}
+func (m *mockService) InviteStaff(ctx context.Context, email string, role domain.Role, adminID int) (domain.StaffInvite, error) {
+	if m.inviteStaffFn != nil {
+		return m.inviteStaffFn(email, role, adminID)
+	}
+	return domain.StaffInvite{}, nil
+}
+func (m *mockService) AcceptInvite(ctx context.Context, req domain.ReqAcceptInvite) (domain.User, error) {
+	if m.acceptInviteFn != nil {
+		return m.acceptInviteFn(req)
+	}
+	return domain.User{}, nil
+}
+func (m *mockService) BootstrapAdmin(ctx context.Context, req domain.ReqRegister) (bool, error) {
+	return false, nil
+}
+func (m *mockService) ForgotPassword(ctx context.Context, email string) error {
+	return nil
+}
+func (m *mockService) ResetPassword(ctx context.Context, req domain.ReqPasswordReset) error {
+	return nil
+}
+func (m *mockService) ChangePassword(ctx context.Context, userID int, req domain.ReqPasswordChange) error {
+	if m.changePasswordFn != nil {
+		return m.changePasswordFn(userID, req)
+	}
+	return nil
+}
+func (m *mockService) SendVerificationCode(ctx context.Context, userID int, channel domain.VerificationChannel) error {
+	return nil
+}
+func (m *mockService) VerifyContact(ctx context.Context, userID int, channel domain.VerificationChannel, code string) error {
+	if code != "123456" {
+		return errs.ErrInvalidOTP
+	}
+	return nil
+}
+func (m *mockService) CreateAPIClient(ctx context.Context, req domain.ReqCreateAPIClient, adminID int) (domain.APIClient, string, error) {
+	return domain.APIClient{}, "", nil
+}
+func (m *mockService) APIClients(ctx context.Context) ([]domain.APIClient, error)     { return nil, nil }
+func (m *mockService) RevokeAPIClient(ctx context.Context, id int, adminID int) error { return nil }
+func (m *mockService) IssueClientToken(ctx context.Context, req domain.ReqClientToken) (domain.ClientTokenResponse, error) {
+	if m.issueClientTokenFn != nil {
+		return m.issueClientTokenFn(req)
+	}
+	return domain.ClientTokenResponse{}, nil
+}
+func (m *mockService) RecordClientRequest(ctx context.Context, user domain.User, scope domain.Scope, request string) {
+	m.recordedRequests = append(m.recordedRequests, string(scope)+" "+request)
+}
+func (m *mockService) Profile(ctx context.Context, userID int) (domain.Profile, error) {
+	return domain.Profile{
+		User:  domain.User{ID: userID, FullName: "Ali"},
+		Cards: []domain.Card{{ID: 1, AccountID: 2, CardNumber: "4000123412344242", CVV: "123", ExpiryDate: time.Date(2029, 5, 31, 0, 0, 0, 0, time.UTC)}},
+	}, nil
+}
+func (m *mockService) UpdateProfile(ctx context.Context, userID int, req domain.ReqUpdateProfile) (domain.User, error) {
+	if m.updateProfileFn != nil {
+		return m.updateProfileFn(userID, req)
+	}
+	return domain.User{ID: userID}, nil
+}
+func (m *mockService) ProfileChanges(ctx context.Context, userID int) ([]domain.ProfileChange, error) {
+	return nil, nil
+}
+func (m *mockService) SearchUsers(ctx context.Context, adminID int, search domain.UserSearch) ([]domain.User, error) {
+	return []domain.User{{ID: 5, Email: "ali@bank.tj", Disabled: true}}, nil
+}
+func (m *mockService) UserDetail(ctx context.Context, adminID int, userID int) (domain.UserDetail, error) {
+	return domain.UserDetail{User: domain.User{ID: userID}}, nil
+}
+func (m *mockService) UserHistory(ctx context.Context, adminID int, userID int) ([]domain.Transaction, error) {
+	return nil, nil
+}
+func (m *mockService) SetUserDisabled(ctx context.Context, adminID int, userID int, disable bool, reason string) error {
+	if m.setUserDisabledFn != nil {
+		return m.setUserDisabledFn(adminID, userID, disable, reason)
+	}
 	return nil
 }
-func (m *mockService) HistoryLogs(idUser int) ([]domain.Transaction, error) {
+func (m *mockService) WithRequest(meta domain.RequestMeta) contracts.ServiceI {
+	m.lastMeta = meta
+	return m
+}
+func (m *mockService) VerifyAuditChain(ctx context.Context) (domain.AuditChainReport, error) {
+	return domain.AuditChainReport{}, nil
+}
+func (m *mockService) JWKS() jwtkeys.JWKS {
+	return jwtkeys.JWKS{Keys: []jwtkeys.JWK{{Kty: "OKP", Kid: "k1", Alg: "EdDSA"}}}
+}
+func (m *mockService) HistoryLogs(ctx context.Context, idUser int) ([]domain.Transaction, error) {
 	if m.historyFn != nil {
 		return m.historyFn(idUser)
 	}
 	return nil, nil
 }
-func (m *mockService) GetAllAccounts(userID int) ([]domain.Account, error) {
+func (m *mockService) GetAllAccounts(ctx context.Context, userID int) ([]domain.Account, error) {
 	if m.getAllAccountsFn != nil {
 		return m.getAllAccountsFn(userID)
 	}
 	return []domain.Account{}, nil
 }
+func (m *mockService) ScreeningReviews(ctx context.Context, status domain.ScreeningStatus) ([]domain.ScreeningReview, error) {
+	return []domain.ScreeningReview{}, nil
+}
+func (m *mockService) ResolveScreeningReview(ctx context.Context, reviewID int, adminID int, clear bool, note string) error {
+	if m.resolveReviewFn != nil {
+		return m.resolveReviewFn(reviewID, adminID, clear, note)
+	}
+	return nil
+}
+func (m *mockService) PendingTransfers(ctx context.Context, status domain.PendingTransferStatus) ([]domain.PendingTransfer, error) {
+	return []domain.PendingTransfer{}, nil
+}
+func (m *mockService) PendingTransfersForApprover(ctx context.Context, userID int) ([]domain.PendingTransfer, error) {
+	return []domain.PendingTransfer{}, nil
+}
+func (m *mockService) DecidePendingTransfer(ctx context.Context, pendingID int, approver domain.User, approve bool, note string) error {
+	if m.decideTransferFn != nil {
+		return m.decideTransferFn(pendingID, approver, approve, note)
+	}
+	return nil
+}
+func (m *mockService) ExpirePendingTransfers(ctx context.Context) (int, error) {
+	return 0, nil
+}
+func (m *mockService) AddAccountSignatory(ctx context.Context, accountID, userID int) error {
+	return nil
+}
+func (m *mockService) ProposeAdminAction(ctx context.Context, action domain.PendingAction, proposerID int) (domain.PendingAction, error) {
+	if m.proposeActionFn != nil {
+		return m.proposeActionFn(action, proposerID)
+	}
+	return action, nil
+}
+func (m *mockService) PendingActions(ctx context.Context, status domain.PendingActionStatus) ([]domain.PendingAction, error) {
+	return []domain.PendingAction{}, nil
+}
+func (m *mockService) DecidePendingAction(ctx context.Context, actionID int, approverID int, approve bool, note string) error {
+	return nil
+}
+func (m *mockService) ExpirePendingActions(ctx context.Context) (int, error) {
+	return 0, nil
+}
+func (m *mockService) Logout(ctx context.Context, userID int, sessionID string) error {
+	if m.logoutFn != nil {
+		return m.logoutFn(userID, sessionID)
+	}
+	return nil
+}
+func (m *mockService) LogoutAll(ctx context.Context, userID int) error {
+	return nil
+}
+func (m *mockService) ListSessions(ctx context.Context, userID int) ([]domain.Session, error) {
+	if m.listSessionsFn != nil {
+		return m.listSessionsFn(userID)
+	}
+	return []domain.Session{}, nil
+}
+func (m *mockService) TerminateSession(ctx context.Context, userID int, sessionID string) error {
+	return nil
+}
+func (m *mockService) SendStepUpCode(ctx context.Context, userID int, sessionID string) error {
+	return nil
+}
+func (m *mockService) CompleteStepUp(ctx context.Context, userID int, sessionID string, code string, ip string) error {
+	if m.completeStepUpFn != nil {
+		return m.completeStepUpFn(userID, sessionID, code)
+	}
+	return nil
+}
+func (m *mockService) LoginLocks(ctx context.Context) ([]domain.LoginLock, error) {
+	return []domain.LoginLock{}, nil
+}
+func (m *mockService) UnlockLogin(ctx context.Context, userID int, adminID int, reason string) error {
+	if m.unlockLoginFn != nil {
+		return m.unlockLoginFn(userID, adminID, reason)
+	}
+	return nil
+}
+func (m *mockService) VerifyMFA(ctx context.Context, req domain.ReqMFAVerify) (domain.TokenResponse, error) {
+	if m.verifyMFAFn != nil {
+		return m.verifyMFAFn(req)
+	}
+	return domain.TokenResponse{}, nil
+}
+func (m *mockService) EnrollMFA(ctx context.Context, userID int) (domain.MFAEnrollment, error) {
+	return domain.MFAEnrollment{}, nil
+}
+func (m *mockService) ConfirmMFA(ctx context.Context, userID int, sessionID string, code string) ([]string, error) {
+	return nil, nil
+}
+func (m *mockService) RegenerateRecoveryCodes(ctx context.Context, userID int, code string) ([]string, error) {
+	return nil, nil
+}
+func (m *mockService) DisableMFA(ctx context.Context, userID int, code string) error {
+	return nil
+}
+func (m *mockService) ConfirmTransfer(ctx context.Context, userID int, confirmationID int, code string, sessionID string) (domain.TransferResult, error) {
+	if m.confirmTransferFn != nil {
+		return m.confirmTransferFn(userID, confirmationID, code, sessionID)
+	}
+	return domain.TransferResult{Status: domain.TransferCompleted}, nil
+}
 
 func TestGetAllAccountsHandler_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
@@ -205,7 +490,9 @@ func TestDepositWithdrawTransfer_Success(t *testing.T) {
 	ctr := NewController(&mockService{
 		depositFn:  func(id int, req domain.ReqTransaction) error { return nil },
 		withdrawFn: func(id int, req domain.ReqTransaction) error { return nil },
-		transferFn: func(id int, req domain.ReqTransfer) error { return nil },
+		transferFn: func(id int, req domain.ReqTransfer) (domain.TransferResult, error) {
+			return domain.TransferResult{Status: domain.TransferCompleted}, nil
+		},
 	})
 
 	// deposit
@@ -272,7 +559,7 @@ func TestAuthMiddleware_HeaderIssues(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	ctr := NewController(&mockService{})
 	r := gin.New()
-	r.GET("/p", ctr.AuthMiddleware(domain.RoleUser), func(c *gin.Context) { c.String(200, "ok") })
+	r.GET("/p", ctr.AuthMiddleware(domain.PermBankingUse), func(c *gin.Context) { c.String(200, "ok") })
 	// Missing header
 	w := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodGet, "/p", nil)
@@ -327,7 +614,7 @@ func TestAuthMiddleware_InvalidToken(t *testing.T) {
 	ctr := NewController(&mockService{parseTokenFn: func(tokenStr string) (domain.User, error) { return domain.User{}, errs.ErrInvalidToken }})
 
 	r := gin.New()
-	r.GET("/protected", ctr.AuthMiddleware(domain.RoleUser), func(c *gin.Context) { c.String(200, "ok") })
+	r.GET("/protected", ctr.AuthMiddleware(domain.PermBankingUse), func(c *gin.Context) { c.String(200, "ok") })
 
 	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
 	req.Header.Set("Authorization", "Bearer badtoken")
@@ -344,7 +631,7 @@ func TestAuthMiddleware_RoleMismatch(t *testing.T) {
 	ctr := NewController(&mockService{parseTokenFn: func(tokenStr string) (domain.User, error) { return domain.User{ID: 1, Role: domain.RoleUser}, nil }})
 
 	r := gin.New()
-	r.GET("/admin", ctr.AuthMiddleware(domain.RoleAdmin), func(c *gin.Context) { c.String(200, "ok") })
+	r.GET("/admin", ctr.AuthMiddleware(domain.PermAuditRead), func(c *gin.Context) { c.String(200, "ok") })
 
 	req := httptest.NewRequest(http.MethodGet, "/admin", nil)
 	req.Header.Set("Authorization", "Bearer good")
@@ -356,12 +643,31 @@ func TestAuthMiddleware_RoleMismatch(t *testing.T) {
 	}
 }
 
-func TestAuthMiddleware_Success(t *testing.T) {
+func TestAuthMiddleware_AdminWithoutMFA(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	ctr := NewController(&mockService{parseTokenFn: func(tokenStr string) (domain.User, error) { return domain.User{ID: 1, Role: domain.RoleAdmin}, nil }})
 
 	r := gin.New()
-	r.GET("/admin", ctr.AuthMiddleware(domain.RoleAdmin), func(c *gin.Context) { c.String(200, "ok") })
+	r.GET("/admin", ctr.AuthMiddleware(domain.PermAuditRead), func(c *gin.Context) { c.String(200, "ok") })
+
+	req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+	req.Header.Set("Authorization", "Bearer good")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), "mfa_required") {
+		t.Fatalf("expected 403 mfa_required, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestAuthMiddleware_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctr := NewController(&mockService{parseTokenFn: func(tokenStr string) (domain.User, error) {
+		return domain.User{ID: 1, Role: domain.RoleAdmin, MFAVerified: true}, nil
+	}})
+
+	r := gin.New()
+	r.GET("/admin", ctr.AuthMiddleware(domain.PermAuditRead), func(c *gin.Context) { c.String(200, "ok") })
 
 	req := httptest.NewRequest(http.MethodGet, "/admin", nil)
 	req.Header.Set("Authorization", "Bearer good")
@@ -416,7 +722,16 @@ func TestTranslateError_Mapping(t *testing.T) {
 		{errs.ErrInvalidToken, http.StatusUnauthorized, "Invalid token"},
 		{errs.ErrTokenExpired, http.StatusUnauthorized, "Token expired"},
 		{errs.ErrRefreshTokenExpired, http.StatusUnauthorized, "Refresh token expired"},
+		{errs.ErrRefreshTokenRevoked, http.StatusUnauthorized, "Refresh token revoked"},
+		{errs.ErrSessionExpired, http.StatusUnauthorized, "Session has expired"},
 		{errs.ErrOperationNotAllowed, http.StatusBadRequest, "Operation not allowed"},
+		{errs.ErrScreeningPending, http.StatusForbidden, "pending compliance review"},
+		{errs.ErrSanctionsMatch, http.StatusForbidden, "sanctions screening"},
+		{errs.ErrPendingTransferNotFound, http.StatusNotFound, "Pending transfer not found"},
+		{errs.ErrPendingTransferExpired, http.StatusGone, "expired"},
+		{errs.ErrSelfApproval, http.StatusForbidden, "own operation"},
+		{errs.ErrPendingActionNotFound, http.StatusNotFound, "Pending action not found"},
+		{errs.ErrApprovalRequired, http.StatusForbidden, "another admin"},
 		{errors.New("unknown"), http.StatusInternalServerError, "Internal server error"},
 	}
 
@@ -500,20 +815,20 @@ func TestRefreshTokenHandler_Success(t *testing.T) {
 	}
 }
 
-func TestRegisterHandler_DefaultRole(t *testing.T) {
+func TestRegisterHandler_IgnoresRequestedRole(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	called := false
 	svc := &mockService{registerFn: func(req domain.ReqRegister, role domain.Role) (domain.User, error) {
 		called = true
 		if role != domain.RoleUser {
-			t.Fatalf("expected default role user, got %s", role)
+			t.Fatalf("self-registration must create a user, got %s", role)
 		}
 		return domain.User{ID: 1}, nil
 	}}
 	ctr := NewController(svc)
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
-	body := `{"full_name":"John","phone":"1","email":"a@b.c","password":"password123","role":"unknown"}`
+	body := `{"full_name":"John","phone":"1","email":"a@b.c","password":"password123","role":"admin"}`
 	c.Request = httptest.NewRequest(http.MethodPost, "/auth/register", strings.NewReader(body))
 	c.Request.Header.Set("Content-Type", "application/json")
 	ctr.registerHandler(c)
@@ -525,12 +840,13 @@ func TestRegisterHandler_DefaultRole(t *testing.T) {
 func TestBlockUnblockAccountHandler_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	called := false
-	ctr := NewController(&mockService{blockUnblockFn: func(accountID int, block bool, adminID int, reason string) error {
+	ctr := NewController(&mockService{restrictAccountFn: func(adminID, accountID int, req domain.ReqAdminAccountAction) (domain.AccountRestriction, error) {
 		called = true
-		if accountID != 10 || !block || adminID != 1 || reason != "abuse" {
-			t.Fatalf("wrong args: id=%d block=%v admin=%d reason=%s", accountID, block, adminID, reason)
+		if accountID != 10 || !req.Block || adminID != 1 || req.Reason != "abuse" ||
+			req.Mode != domain.RestrictionFullBlock || req.Category != domain.RestrictionOther {
+			t.Fatalf("wrong args: id=%d admin=%d req=%+v", accountID, adminID, req)
 		}
-		return nil
+		return domain.AccountRestriction{ID: 1, AccountID: accountID, Mode: req.Mode}, nil
 	}})
 
 	w := httptest.NewRecorder()
@@ -576,3 +892,979 @@ func TestGetAuditLogsHandler_Success(t *testing.T) {
 		t.Fatalf("expected 200 got %d", w.Code)
 	}
 }
+
+func TestResolveScreeningReviewHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	called := false
+	ctr := NewController(&mockService{resolveReviewFn: func(reviewID int, adminID int, clear bool, note string) error {
+		called = true
+		if reviewID != 3 || adminID != 1 || !clear || note != "false positive" {
+			t.Fatalf("wrong args: id=%d admin=%d clear=%v note=%s", reviewID, adminID, clear, note)
+		}
+		return nil
+	}})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "3"})
+	c.Request = httptest.NewRequest(http.MethodPost, "/admin/screening/reviews/3/resolve", strings.NewReader(`{"clear":true,"note":"false positive"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("currentUser", domain.User{ID: 1, Role: domain.RoleAdmin})
+
+	ctr.resolveScreeningReviewHandler(c)
+	if w.Code != http.StatusOK || !called {
+		t.Fatalf("expected 200 and called, got %d called=%v", w.Code, called)
+	}
+
+	// неверный статус в фильтре очереди
+	w2 := httptest.NewRecorder()
+	c2, _ := gin.CreateTestContext(w2)
+	c2.Request = httptest.NewRequest(http.MethodGet, "/admin/screening/reviews?status=bogus", nil)
+	ctr.getScreeningReviewsHandler(c2)
+	if w2.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 got %d", w2.Code)
+	}
+}
+
+func TestTransferHandler_PendingApproval(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctr := NewController(&mockService{transferFn: func(id int, req domain.ReqTransfer) (domain.TransferResult, error) {
+		return domain.TransferResult{Status: domain.TransferPendingApproval, PendingTransferID: 42}, nil
+	}})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/transfer", strings.NewReader(`{"from_card_number":"4000","to_card_number":"5000","amount":20000,"currency":"TJS"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("currentUser", domain.User{ID: 5, Role: domain.RoleUser})
+	ctr.transferHandler(c)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202 got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "\"pending_transfer_id\":42") {
+		t.Fatalf("unexpected: %s", w.Body.String())
+	}
+}
+
+func TestDecideTransferHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var gotApprove bool
+	ctr := NewController(&mockService{decideTransferFn: func(pendingID int, approver domain.User, approve bool, note string) error {
+		gotApprove = approve
+		if pendingID != 7 || approver.ID != 1 {
+			t.Fatalf("wrong args: id=%d approver=%d", pendingID, approver.ID)
+		}
+		return nil
+	}})
+
+	// approve без тела
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "7"})
+	c.Request = httptest.NewRequest(http.MethodPost, "/admin/approvals/7/approve", nil)
+	c.Set("currentUser", domain.User{ID: 1, Role: domain.RoleAdmin})
+	ctr.approveTransferHandler(c)
+	if w.Code != http.StatusOK || !gotApprove {
+		t.Fatalf("expected 200 approve, got %d", w.Code)
+	}
+
+	// reject без причины
+	w2 := httptest.NewRecorder()
+	c2, _ := gin.CreateTestContext(w2)
+	c2.Params = append(c2.Params, gin.Param{Key: "id", Value: "7"})
+	c2.Request = httptest.NewRequest(http.MethodPost, "/admin/approvals/7/reject", strings.NewReader(`{}`))
+	c2.Request.Header.Set("Content-Type", "application/json")
+	c2.Set("currentUser", domain.User{ID: 1, Role: domain.RoleAdmin})
+	ctr.rejectTransferHandler(c2)
+	if w2.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 got %d", w2.Code)
+	}
+}
+
+func TestBlockUnblockAccountHandler_UnblockProposed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctr := NewController(&mockService{
+		proposeActionFn: func(action domain.PendingAction, proposerID int) (domain.PendingAction, error) {
+			if action.Type != domain.ActionUnblock || action.AccountID != 10 || action.RestrictionMode != domain.RestrictionFullBlock || proposerID != 1 {
+				t.Fatalf("unexpected proposal: %+v by %d", action, proposerID)
+			}
+			action.ID = 5
+			return action, nil
+		},
+	})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "10"})
+	c.Request = httptest.NewRequest(http.MethodPost, "/admin/blockUnblock/10", strings.NewReader(`{"block":false,"reason":"verified"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("currentUser", domain.User{ID: 1, Role: domain.RoleAdmin})
+
+	ctr.blockUnblockAccountHandler(c)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202 got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "\"action_id\":5") {
+		t.Fatalf("unexpected: %s", w.Body.String())
+	}
+}
+
+func TestLogoutHandler_UsesCurrentSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	called := false
+	ctr := NewController(&mockService{logoutFn: func(userID int, sessionID string) error {
+		called = true
+		if userID != 5 || sessionID != "sid-1" {
+			t.Fatalf("wrong args: %d %s", userID, sessionID)
+		}
+		return nil
+	}})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/auth/logout", nil)
+	c.Set("currentUser", domain.User{ID: 5, Role: domain.RoleUser, SessionID: "sid-1"})
+	ctr.logoutHandler(c)
+
+	if w.Code != http.StatusOK || !called {
+		t.Fatalf("expected 200 and called, got %d called=%v", w.Code, called)
+	}
+}
+
+func TestGetSessionsHandler_MarksCurrent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctr := NewController(&mockService{listSessionsFn: func(userID int) ([]domain.Session, error) {
+		return []domain.Session{
+			{ID: "sid-1", UserID: userID, DeviceID: "laptop"},
+			{ID: "sid-2", UserID: userID, DeviceID: "phone", StepUpRequired: true},
+		}, nil
+	}})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/sessions", nil)
+	c.Set("currentUser", domain.User{ID: 5, Role: domain.RoleUser, SessionID: "sid-2"})
+	ctr.getSessionsHandler(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d", w.Code)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "\"total_count\":2") || !strings.Contains(body, "\"current\":true,\"device_id\":\"phone\"") {
+		t.Fatalf("unexpected: %s", body)
+	}
+}
+
+func TestTransferHandler_StepUpRequired(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctr := NewController(&mockService{transferFn: func(currentUserID int, req domain.ReqTransfer) (domain.TransferResult, error) {
+		if req.SessionID != "sid-2" {
+			t.Fatalf("session id not passed: %q", req.SessionID)
+		}
+		return domain.TransferResult{}, errs.ErrStepUpRequired
+	}})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/transfer", strings.NewReader(`{"from_card_number":"4000","to_card_number":"5000","amount":10,"currency":"TJS"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("currentUser", domain.User{ID: 5, Role: domain.RoleUser, SessionID: "sid-2"})
+	ctr.transferHandler(c)
+
+	if w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), "step_up_required") {
+		t.Fatalf("expected 403 step-up, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestLoginHandler_LockedAndThrottled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cases := map[error]int{
+		errs.ErrAccountLocked:   http.StatusLocked,
+		errs.ErrTooManyAttempts: http.StatusTooManyRequests,
+	}
+	for loginErr, want := range cases {
+		ctr := NewController(&mockService{loginFn: func(req domain.ReqLogin) (domain.TokenResponse, error) {
+			return domain.TokenResponse{}, loginErr
+		}})
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(`{"email":"a@b.c","password":"x"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+		ctr.loginHandler(c)
+
+		if w.Code != want {
+			t.Fatalf("%v: expected %d got %d", loginErr, want, w.Code)
+		}
+	}
+}
+
+func TestLoginHandler_DeviceToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var got string
+	ctr := NewController(&mockService{loginFn: func(req domain.ReqLogin) (domain.TokenResponse, error) {
+		got = req.DeviceID
+		return domain.TokenResponse{AccessToken: "a", DeviceToken: "issued-token"}, nil
+	}})
+
+	// Токен устройства из cookie, выданный сервер запоминает в cookie
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(`{"email":"a@b.c","password":"x"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.AddCookie(&http.Cookie{Name: deviceCookie, Value: "cookie-token"})
+	ctr.loginHandler(c)
+
+	if w.Code != http.StatusOK || got != "cookie-token" {
+		t.Fatalf("expected 200 with cookie device token, got %d %q", w.Code, got)
+	}
+	if cookie := w.Header().Get("Set-Cookie"); !strings.Contains(cookie, deviceCookie+"=issued-token") || !strings.Contains(cookie, "HttpOnly") {
+		t.Fatalf("expected device cookie, got %q", cookie)
+	}
+
+	// Слишком длинный X-Device-ID отклоняется до сервиса
+	got = ""
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(`{"email":"a@b.c","password":"x"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Header.Set(deviceHeader, strings.Repeat("x", 129))
+	ctr.loginHandler(c)
+
+	if w.Code != http.StatusBadRequest || got != "" {
+		t.Fatalf("expected 400 for oversized device id, got %d", w.Code)
+	}
+}
+
+func TestUnlockLoginHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	called := false
+	ctr := NewController(&mockService{unlockLoginFn: func(userID int, adminID int, reason string) error {
+		called = true
+		if userID != 7 || adminID != 1 || reason != "ok" {
+			t.Fatalf("wrong args: %d %d %s", userID, adminID, reason)
+		}
+		return nil
+	}})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/admin/users/7/unlock", strings.NewReader(`{"reason":"ok"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "id", Value: "7"}}
+	c.Set("currentUser", domain.User{ID: 1, Role: domain.RoleAdmin})
+	ctr.unlockLoginHandler(c)
+
+	if w.Code != http.StatusOK || !called {
+		t.Fatalf("expected 200 and called, got %d called=%v", w.Code, called)
+	}
+}
+
+func TestVerifyMFAHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctr := NewController(&mockService{verifyMFAFn: func(req domain.ReqMFAVerify) (domain.TokenResponse, error) {
+		if req.MFAToken != "challenge" || req.DeviceID != "phone" {
+			t.Fatalf("unexpected request: %+v", req)
+		}
+		if req.Code != "123456" {
+			return domain.TokenResponse{}, errs.ErrInvalidMFACode
+		}
+		return domain.TokenResponse{AccessToken: "at", TokenType: "Bearer"}, nil
+	}})
+
+	for code, want := range map[string]int{"123456": http.StatusOK, "654321": http.StatusUnauthorized} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/auth/mfa/verify", strings.NewReader(`{"mfa_token":"challenge","code":"`+code+`"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Request.Header.Set("X-Device-ID", "phone")
+		ctr.verifyMFAHandler(c)
+
+		if w.Code != want {
+			t.Fatalf("code %s: expected %d got %d", code, want, w.Code)
+		}
+	}
+}
+
+func TestTransferHandler_PendingConfirmationAndConfirm(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctr := NewController(&mockService{
+		transferFn: func(currentUserID int, req domain.ReqTransfer) (domain.TransferResult, error) {
+			return domain.TransferResult{Status: domain.TransferPendingConfirmation, ConfirmationID: 9}, nil
+		},
+		confirmTransferFn: func(userID int, confirmationID int, code string, sessionID string) (domain.TransferResult, error) {
+			if confirmationID != 9 || code != "123456" {
+				return domain.TransferResult{}, errs.ErrInvalidOTP
+			}
+			return domain.TransferResult{Status: domain.TransferCompleted}, nil
+		},
+	})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/transfer", strings.NewReader(`{"from_card_number":"4000","to_card_number":"5000","amount":10}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("currentUser", domain.User{ID: 5, Role: domain.RoleUser})
+	ctr.transferHandler(c)
+	if w.Code != http.StatusAccepted || !strings.Contains(w.Body.String(), "\"confirmation_id\":9") {
+		t.Fatalf("expected 202 with confirmation id, got %d %s", w.Code, w.Body.String())
+	}
+
+	for code, want := range map[string]int{"123456": http.StatusOK, "111111": http.StatusBadRequest} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/transfer/confirm", strings.NewReader(`{"confirmation_id":9,"code":"`+code+`"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Set("currentUser", domain.User{ID: 5, Role: domain.RoleUser})
+		ctr.confirmTransferHandler(c)
+		if w.Code != want {
+			t.Fatalf("code %s: expected %d got %d", code, want, w.Code)
+		}
+	}
+}
+
+func TestAuthMiddleware_Permissions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var current domain.User
+	ctr := NewController(&mockService{parseTokenFn: func(tokenStr string) (domain.User, error) { return current, nil }})
+
+	r := gin.New()
+	r.GET("/api", ctr.AuthMiddleware(domain.PermBankingUse), func(c *gin.Context) { c.String(200, "ok") })
+	r.GET("/block", ctr.AuthMiddleware(domain.PermAccountsBlock), func(c *gin.Context) { c.String(200, "ok") })
+
+	cases := []struct {
+		user domain.User
+		path string
+		want int
+	}{
+		{domain.User{ID: 1, Role: domain.RoleAdmin}, "/api", http.StatusOK}, // админ больше не отсекается от /api
+		{domain.User{ID: 2, Role: domain.RoleAuditor, MFAVerified: true}, "/api", http.StatusForbidden},
+		{domain.User{ID: 2, Role: domain.RoleAuditor, MFAVerified: true}, "/block", http.StatusForbidden},
+		{domain.User{ID: 3, Role: domain.RoleTeller, MFAVerified: true}, "/block", http.StatusOK},
+		{domain.User{ID: 3, Role: domain.RoleTeller}, "/block", http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		current = tc.user
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		req.Header.Set("Authorization", "Bearer good")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Fatalf("%s on %s: expected %d, got %d", tc.user.Role, tc.path, tc.want, w.Code)
+		}
+	}
+}
+
+func TestProposeActionHandler_RoleChangeRequiresAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctr := NewController(&mockService{proposeActionFn: func(action domain.PendingAction, proposerID int) (domain.PendingAction, error) {
+		t.Fatalf("support must not propose role changes")
+		return action, nil
+	}})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/admin/actions", strings.NewReader(`{"type":"role_change","user_id":5,"role":"admin","reason":"promo"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("currentUser", domain.User{ID: 2, Role: domain.RoleSupport, MFAVerified: true})
+
+	ctr.proposeActionHandler(c)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestInviteHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctr := NewController(&mockService{
+		inviteStaffFn: func(email string, role domain.Role, adminID int) (domain.StaffInvite, error) {
+			if email != "ops@bank.tj" || role != domain.RoleSupport || adminID != 1 {
+				t.Fatalf("unexpected invite: %s %s %d", email, role, adminID)
+			}
+			return domain.StaffInvite{ID: 7, Email: email, Role: role}, nil
+		},
+		acceptInviteFn: func(req domain.ReqAcceptInvite) (domain.User, error) {
+			if req.Token != "expired" {
+				t.Fatalf("unexpected token: %s", req.Token)
+			}
+			return domain.User{}, errs.ErrInviteExpired
+		},
+	})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/admin/invites", strings.NewReader(`{"email":"ops@bank.tj","role":"support"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("currentUser", domain.User{ID: 1, Role: domain.RoleAdmin, MFAVerified: true})
+	ctr.inviteStaffHandler(c)
+	if w.Code != http.StatusCreated || !strings.Contains(w.Body.String(), "\"invite_id\":7") {
+		t.Fatalf("expected 201 with invite id, got %d %s", w.Code, w.Body.String())
+	}
+
+	w2 := httptest.NewRecorder()
+	c2, _ := gin.CreateTestContext(w2)
+	c2.Request = httptest.NewRequest(http.MethodPost, "/admin/invites", strings.NewReader(`{"email":"x@bank.tj","role":"user"}`))
+	c2.Request.Header.Set("Content-Type", "application/json")
+	c2.Set("currentUser", domain.User{ID: 1, Role: domain.RoleAdmin, MFAVerified: true})
+	ctr.inviteStaffHandler(c2)
+	if w2.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for user role invite, got %d", w2.Code)
+	}
+
+	w3 := httptest.NewRecorder()
+	c3, _ := gin.CreateTestContext(w3)
+	c3.Request = httptest.NewRequest(http.MethodPost, "/auth/invites/accept", strings.NewReader(`{"token":"expired","full_name":"A","phone":"1","password":"password123"}`))
+	c3.Request.Header.Set("Content-Type", "application/json")
+	ctr.acceptInviteHandler(c3)
+	if w3.Code != http.StatusGone {
+		t.Fatalf("expected 410 got %d", w3.Code)
+	}
+}
+
+func TestJWKSHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctr := NewController(&mockService{})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	ctr.jwksHandler(c)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"kid":"k1"`) {
+		t.Fatalf("unexpected: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestChangePasswordHandler_WeakPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctr := NewController(&mockService{changePasswordFn: func(userID int, req domain.ReqPasswordChange) error {
+		if userID != 5 || req.CurrentPassword != "old" || req.NewPassword != "123" {
+			t.Fatalf("unexpected args: %d %+v", userID, req)
+		}
+		return errs.ErrWeakPassword
+	}})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/me/password", strings.NewReader(`{"current_password":"old","new_password":"123"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("currentUser", domain.User{ID: 5, Role: domain.RoleUser})
+	ctr.changePasswordHandler(c)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "too weak") {
+		t.Fatalf("expected 400 weak password, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestForgotPasswordHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctr := NewController(&mockService{})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/auth/password/forgot", strings.NewReader(`{"email":"a@b.c"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	ctr.forgotPasswordHandler(c)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202 got %d", w.Code)
+	}
+}
+
+func TestVerifyContactHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctr := NewController(&mockService{})
+
+	for _, tc := range []struct {
+		body string
+		code int
+	}{
+		{`{"channel":"fax","code":"123456"}`, http.StatusBadRequest},
+		{`{"channel":"phone","code":"000000"}`, http.StatusBadRequest},
+		{`{"channel":"phone","code":"123456"}`, http.StatusOK},
+	} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/me/verification/confirm", strings.NewReader(tc.body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Set("currentUser", domain.User{ID: 5, Role: domain.RoleUser})
+		ctr.verifyContactHandler(c)
+		if w.Code != tc.code {
+			t.Fatalf("%s: expected %d got %d %s", tc.body, tc.code, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestAuthMiddleware_APIClientScopes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &mockService{parseTokenFn: func(tokenStr string) (domain.User, error) {
+		if tokenStr == "limited" {
+			return domain.User{}, errs.ErrRateLimited
+		}
+		return domain.User{ID: 7, Role: domain.RoleAuditor, APIClientID: 3, Scopes: []domain.Scope{domain.ScopeAuditRead}}, nil
+	}}
+	ctr := NewController(svc)
+	r := gin.New()
+	ok := func(c *gin.Context) { c.String(200, "ok") }
+	r.GET("/admin/getAuditLogs", ctr.AuthMiddleware(domain.PermAuditRead), ok)
+	r.GET("/admin/screening/reviews", ctr.AuthMiddleware(domain.PermScreeningRead), ok)
+	r.GET("/api/accounts", ctr.AuthMiddleware(domain.PermBankingUse), ok)
+
+	for _, tc := range []struct {
+		path, token string
+		code        int
+	}{
+		{"/admin/getAuditLogs", "client", http.StatusOK},             // scope есть, 2FA клиенту не нужна
+		{"/admin/screening/reviews", "client", http.StatusForbidden}, // маршрут закрыт для клиентов
+		{"/api/accounts", "client", http.StatusForbidden},            // нет scope accounts:read
+		{"/admin/getAuditLogs", "limited", http.StatusTooManyRequests},
+	} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		req.Header.Set("Authorization", "Bearer "+tc.token)
+		r.ServeHTTP(w, req)
+		if w.Code != tc.code {
+			t.Fatalf("%s: expected %d got %d %s", tc.path, tc.code, w.Code, w.Body.String())
+		}
+	}
+
+	if len(svc.recordedRequests) != 1 || svc.recordedRequests[0] != "audit:read GET /admin/getAuditLogs -> 200" {
+		t.Fatalf("expected audited client request, got %v", svc.recordedRequests)
+	}
+}
+
+func TestClientTokenHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctr := NewController(&mockService{issueClientTokenFn: func(req domain.ReqClientToken) (domain.ClientTokenResponse, error) {
+		if req.GrantType != "client_credentials" {
+			return domain.ClientTokenResponse{}, errs.ErrUnsupportedGrantType
+		}
+		if req.ClientID != "mbc_1" || req.ClientSecret != "s3cret" {
+			return domain.ClientTokenResponse{}, errs.ErrInvalidClient
+		}
+		return domain.ClientTokenResponse{AccessToken: "tok", TokenType: "Bearer", ExpiresIn: 900, Scope: "audit:read"}, nil
+	}})
+
+	for _, tc := range []struct {
+		body  string
+		basic bool
+		code  int
+		want  string
+	}{
+		{"grant_type=password", false, http.StatusBadRequest, "unsupported_grant_type"},
+		{"grant_type=client_credentials&client_id=mbc_1&client_secret=bad", false, http.StatusUnauthorized, "invalid_client"},
+		{"grant_type=client_credentials&client_id=mbc_1&client_secret=s3cret", false, http.StatusOK, `"access_token":"tok"`},
+		{"grant_type=client_credentials", true, http.StatusOK, `"scope":"audit:read"`},
+	} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/auth/token", strings.NewReader(tc.body))
+		c.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if tc.basic {
+			c.Request.SetBasicAuth("mbc_1", "s3cret")
+		}
+		ctr.clientTokenHandler(c)
+		if w.Code != tc.code || !strings.Contains(w.Body.String(), tc.want) {
+			t.Fatalf("%s: expected %d %s, got %d %s", tc.body, tc.code, tc.want, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestProfileHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctr := NewController(&mockService{updateProfileFn: func(userID int, req domain.ReqUpdateProfile) (domain.User, error) {
+		if req.FullName != nil || req.Email == nil || *req.Email != "new@bank.tj" || req.CurrentPassword != "pw" {
+			t.Fatalf("unexpected request: %+v", req)
+		}
+		return domain.User{ID: userID, Email: *req.Email}, nil
+	}})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/me", nil)
+	c.Set("currentUser", domain.User{ID: 5, Role: domain.RoleUser})
+	ctr.getProfileHandler(c)
+	body := w.Body.String()
+	if w.Code != http.StatusOK || !strings.Contains(body, `"card_number":"**** 4242"`) || !strings.Contains(body, `"expiry":"05/29"`) {
+		t.Fatalf("unexpected profile: %d %s", w.Code, body)
+	}
+	if strings.Contains(body, "4000123412344242") || strings.Contains(body, "123\"") {
+		t.Fatalf("profile leaks card data: %s", body)
+	}
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPatch, "/api/me", strings.NewReader(`{"email":"new@bank.tj","current_password":"pw"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("currentUser", domain.User{ID: 5, Role: domain.RoleUser})
+	ctr.updateProfileHandler(c)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"email":"new@bank.tj"`) {
+		t.Fatalf("unexpected update: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestDisableUserHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctr := NewController(&mockService{setUserDisabledFn: func(adminID int, userID int, disable bool, reason string) error {
+		if adminID == userID {
+			return errs.ErrOperationNotAllowed
+		}
+		return nil
+	}})
+
+	for _, tc := range []struct {
+		id   string
+		body string
+		code int
+	}{
+		{"abc", `{"reason":"fraud"}`, http.StatusBadRequest},
+		{"5", `{}`, http.StatusBadRequest},
+		{"1", `{"reason":"fraud"}`, http.StatusBadRequest},
+		{"5", `{"reason":"fraud"}`, http.StatusOK},
+	} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/admin/users/"+tc.id+"/disable", strings.NewReader(tc.body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "id", Value: tc.id}}
+		c.Set("currentUser", domain.User{ID: 1, Role: domain.RoleAdmin})
+		ctr.disableUserHandler(c)
+		if w.Code != tc.code {
+			t.Fatalf("%s %s: expected %d got %d %s", tc.id, tc.body, tc.code, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestRequestIDMiddleware_PassesMetaToService(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &mockService{}
+	ctr := NewController(svc)
+	r := gin.New()
+	r.Use(requestIDMiddleware())
+	r.POST("/admin/users/:id/disable", func(c *gin.Context) {
+		c.Set("currentUser", domain.User{ID: 1, Role: domain.RoleAdmin})
+		ctr.disableUserHandler(c)
+	})
+
+	for _, tc := range []struct {
+		header string
+		keep   bool
+	}{
+		{"proxy-req-42", true},
+		{"bad id with spaces", false},
+	} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/admin/users/5/disable", strings.NewReader(`{"reason":"fraud"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "ops-console")
+		req.Header.Set(requestIDHeader, tc.header)
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d %s", w.Code, w.Body.String())
+		}
+		got := w.Header().Get(requestIDHeader)
+		if (got == tc.header) != tc.keep || got == "" {
+			t.Fatalf("%q: unexpected response request id %q", tc.header, got)
+		}
+		meta := svc.lastMeta
+		if meta.RequestID != got || meta.ActorID != 1 || meta.ActorType != domain.ActorUser || meta.UserAgent != "ops-console" {
+			t.Fatalf("unexpected request meta: %+v", meta)
+		}
+	}
+}
+
+func TestExportAuditLogsHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	created := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	ctr := NewController(&mockService{exportAuditLogsFn: func(filter domain.AuditLogFilter, write func(domain.AdminAuditLog) error) error {
+		if filter.AccountID != 10 || !filter.To.Equal(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)) {
+			t.Fatalf("unexpected filter: %+v", filter)
+		}
+		return write(domain.AdminAuditLog{ID: 1, AccountID: 10, AdminID: 2, Action: "block", Reason: "fraud, confirmed", CreatedAt: created})
+	}})
+
+	for _, tc := range []struct {
+		format string
+		code   int
+		body   string
+	}{
+		{"csv", http.StatusOK, "id,created_at,account_id,admin_id,api_client_id,action,reason\n1,2026-03-01T10:00:00Z,10,2,0,block,\"fraud, confirmed\"\n"},
+		{"jsonl", http.StatusOK, `{"id":1,"created_at":"2026-03-01T10:00:00Z","account_id":10,"admin_id":2,"action":"block","reason":"fraud, confirmed"}` + "\n"},
+		{"xml", http.StatusBadRequest, ""},
+	} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/admin/audit-logs/export?account_id=10&to=2026-03-01&format="+tc.format, nil)
+		ctr.exportAuditLogsHandler(c)
+		if w.Code != tc.code {
+			t.Fatalf("%s: expected %d got %d %s", tc.format, tc.code, w.Code, w.Body.String())
+		}
+		if tc.body != "" && w.Body.String() != tc.body {
+			t.Fatalf("%s: unexpected body %q", tc.format, w.Body.String())
+		}
+	}
+}
+
+func TestGetStatsHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	ctr := NewController(&mockService{adminStatsFn: func(from, to time.Time) (domain.AdminStats, error) {
+		if !from.Equal(day) || !to.Equal(day.AddDate(0, 0, 1)) {
+			t.Fatalf("unexpected period: %v - %v", from, to)
+		}
+		return domain.AdminStats{
+			From: from, To: to, NewUsers: 3, ActiveAccounts: 7, BlockedAccounts: 1,
+			Volumes:   []domain.DailyVolume{{Day: day, Currency: "TJS", Type: domain.Transfer, Count: 2, Volume: 150, Fees: 1.5}},
+			FeeIncome: map[string]float64{"TJS": 1.5},
+		}, nil
+	}})
+
+	for _, tc := range []struct {
+		query string
+		code  int
+		body  string
+	}{
+		{"format=csv", http.StatusOK, "day,currency,type,count,volume,fees\n2026-03-01,TJS,transfer,2,150.00,1.50\n"},
+		{"format=csv&report=summary", http.StatusOK, "metric,value\nfrom,2026-03-01T00:00:00Z\nto,2026-03-02T00:00:00Z\nnew_users,3\nactive_accounts,7\nblocked_accounts,1\nfee_income_TJS,1.50\n"},
+		{"format=xml", http.StatusBadRequest, ""},
+		{"format=csv&report=everything", http.StatusBadRequest, ""},
+	} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/admin/stats?from=2026-03-01&to=2026-03-01&"+tc.query, nil)
+		ctr.getStatsHandler(c)
+		if w.Code != tc.code {
+			t.Fatalf("%s: expected %d got %d %s", tc.query, tc.code, w.Code, w.Body.String())
+		}
+		if tc.body != "" && w.Body.String() != tc.body {
+			t.Fatalf("%s: unexpected body %q", tc.query, w.Body.String())
+		}
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/admin/stats?from=2026-03-01&to=2026-03-01", nil)
+	ctr.getStatsHandler(c)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"fee_income":{"TJS":1.5}`) {
+		t.Fatalf("unexpected json response %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestAdjustBalanceHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctr := NewController(&mockService{adjustBalanceFn: func(adminID, accountID int, req domain.ReqBalanceAdjustment) (domain.BalanceAdjustment, error) {
+		if adminID != 1 || accountID != 10 || req.Direction != domain.AdjustmentCredit || req.ReasonCode != domain.AdjustmentGoodwill {
+			t.Fatalf("unexpected args: %d %d %+v", adminID, accountID, req)
+		}
+		if req.Amount > 1000 {
+			return domain.BalanceAdjustment{}, errs.ErrAdjustmentCeiling
+		}
+		return domain.BalanceAdjustment{ID: 3, AccountID: accountID, Direction: req.Direction, Amount: req.Amount, BalanceBefore: 10, BalanceAfter: 60}, nil
+	}})
+
+	for _, tc := range []struct {
+		body string
+		code int
+	}{
+		{`{"direction":"credit","amount":50,"reason_code":"goodwill","note":"outage"}`, http.StatusCreated},
+		{`{"direction":"credit","amount":5000,"reason_code":"goodwill","note":"outage"}`, http.StatusForbidden},
+		{`{"direction":"credit","amount":50,"reason_code":"bonus","note":"outage"}`, http.StatusBadRequest},
+		{`{"direction":"credit","amount":50,"reason_code":"goodwill"}`, http.StatusBadRequest},
+		{`{"direction":"credit","amount":-5,"reason_code":"goodwill","note":"outage"}`, http.StatusBadRequest},
+	} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/admin/accounts/10/adjust", strings.NewReader(tc.body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "id", Value: "10"}}
+		c.Set("currentUser", domain.User{ID: 1, Role: domain.RoleAdmin})
+		ctr.adjustBalanceHandler(c)
+		if w.Code != tc.code {
+			t.Fatalf("%s: expected %d got %d %s", tc.body, tc.code, w.Code, w.Body.String())
+		}
+		if tc.code == http.StatusCreated && !strings.Contains(w.Body.String(), `"balance_after":60`) {
+			t.Fatalf("unexpected body %s", w.Body.String())
+		}
+	}
+}
+
+func TestBlockUnblockAccountHandler_RestrictionModes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var restricted domain.ReqAdminAccountAction
+	var liftedMode domain.RestrictionMode
+	ctr := NewController(&mockService{
+		restrictAccountFn: func(adminID, accountID int, req domain.ReqAdminAccountAction) (domain.AccountRestriction, error) {
+			restricted = req
+			return domain.AccountRestriction{ID: 2, AccountID: accountID, Mode: req.Mode, Amount: req.Amount, ExpiresAt: req.ExpiresAt}, nil
+		},
+		proposeActionFn: func(action domain.PendingAction, proposerID int) (domain.PendingAction, error) {
+			if action.Type != domain.ActionUnblock {
+				t.Fatalf("restriction lift must be proposed as unblock: %+v", action)
+			}
+			liftedMode = action.RestrictionMode
+			action.ID = 5
+			return action, nil
+		},
+	})
+
+	for _, tc := range []struct {
+		body string
+		code int
+	}{
+		{`{"block":true,"mode":"legal_hold","amount":250,"category":"court_order","reason":"case 12/26","expires_at":"2030-01-01T00:00:00Z"}`, http.StatusOK},
+		{`{"block":false,"mode":"debit_freeze","reason":"resolved"}`, http.StatusAccepted},
+		{`{"block":true,"mode":"soft_block","reason":"x"}`, http.StatusBadRequest},
+		{`{"block":true,"category":"whim","reason":"x"}`, http.StatusBadRequest},
+	} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "id", Value: "10"}}
+		c.Request = httptest.NewRequest(http.MethodPost, "/admin/blockUnblock/10", strings.NewReader(tc.body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Set("currentUser", domain.User{ID: 1, Role: domain.RoleAdmin})
+		ctr.blockUnblockAccountHandler(c)
+		if w.Code != tc.code {
+			t.Fatalf("%s: expected %d got %d %s", tc.body, tc.code, w.Code, w.Body.String())
+		}
+	}
+
+	if restricted.Mode != domain.RestrictionLegalHold || restricted.Amount != 250 || restricted.Category != domain.RestrictionCourtOrder ||
+		!restricted.ExpiresAt.Equal(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected restriction request %+v", restricted)
+	}
+	if liftedMode != domain.RestrictionDebitFreeze {
+		t.Fatalf("unexpected lifted mode %q", liftedMode)
+	}
+}
+
+func TestReactivateAccountHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctr := NewController(&mockService{reactivateFn: func(userID, accountID int, req domain.ReqAccountReactivation) error {
+		if userID != 5 || accountID != 10 || req.Password != "secret" {
+			t.Fatalf("wrong args: user=%d account=%d req=%+v", userID, accountID, req)
+		}
+		if req.MFACode != "123456" {
+			return errs.ErrMFARequired
+		}
+		return nil
+	}})
+
+	for _, tc := range []struct {
+		body string
+		code int
+	}{
+		{`{"password":"secret","mfa_code":"123456"}`, http.StatusOK},
+		{`{"password":"secret"}`, http.StatusForbidden},
+		{`{}`, http.StatusBadRequest},
+	} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = append(c.Params, gin.Param{Key: "id", Value: "10"})
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/accounts/10/reactivate", strings.NewReader(tc.body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Set("currentUser", domain.User{ID: 5, Role: domain.RoleUser})
+
+		ctr.reactivateAccountHandler(c)
+		if w.Code != tc.code {
+			t.Fatalf("%s: expected %d got %d %s", tc.body, tc.code, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestGetDormantBalancesHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctr := NewController(&mockService{dormantBalancesFn: func() ([]domain.DormantBalance, error) {
+		return []domain.DormantBalance{{Currency: "TJS", Accounts: 3, Balance: 1250.5}, {Currency: "USD", Accounts: 1, Balance: 40}}, nil
+	}})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/admin/reports/dormant-balances?format=csv", nil)
+	ctr.getDormantBalancesHandler(c)
+	if w.Code != http.StatusOK || w.Body.String() != "currency,accounts,balance\nTJS,3,1250.50\nUSD,1,40.00\n" {
+		t.Fatalf("unexpected csv: %d %q", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/admin/reports/dormant-balances", nil)
+	ctr.getDormantBalancesHandler(c)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `{"accounts":3,"balance":1250.5,"currency":"TJS"}`) {
+		t.Fatalf("unexpected json: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestSubmitKYCDocumentHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctr := NewController(&mockService{submitKYCFn: func(userID int, req domain.ReqKYCDocument) (domain.KYCDocument, error) {
+		data, _ := io.ReadAll(req.Body)
+		if userID != 5 || req.Type != domain.KYCPassport || req.FileName != "passport.png" || string(data) != "scan" {
+			t.Fatalf("wrong args: user=%d req=%+v data=%q", userID, req, data)
+		}
+		return domain.KYCDocument{ID: 3, UserID: userID, Type: req.Type, Status: domain.KYCDocumentPending, StorageKey: "kyc/5/x"}, nil
+	}})
+
+	upload := func(docType string, withFile bool) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		_ = mw.WriteField("doc_type", docType)
+		if withFile {
+			fw, _ := mw.CreateFormFile("file", "passport.png")
+			_, _ = fw.Write([]byte("scan"))
+		}
+		_ = mw.Close()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/me/kyc/documents", &body)
+		c.Request.Header.Set("Content-Type", mw.FormDataContentType())
+		c.Set("currentUser", domain.User{ID: 5, Role: domain.RoleUser})
+		ctr.submitKYCDocumentHandler(c)
+		return w
+	}
+
+	w := upload("passport", true)
+	if w.Code != http.StatusCreated || !strings.Contains(w.Body.String(), `"status":"pending"`) {
+		t.Fatalf("expected 201 got %d %s", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "kyc/5/x") {
+		t.Fatalf("storage key must not be exposed: %s", w.Body.String())
+	}
+	if w := upload("selfie", true); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown doc_type, got %d", w.Code)
+	}
+	if w := upload("passport", false); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without file, got %d", w.Code)
+	}
+}
+
+func TestReviewKYCDocumentHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctr := NewController(&mockService{reviewKYCFn: func(docID, adminID int, approve bool, note string) (domain.KYCTier, error) {
+		if docID != 3 || adminID != 1 || note != "ok" {
+			t.Fatalf("wrong args: doc=%d admin=%d note=%q", docID, adminID, note)
+		}
+		if !approve {
+			return domain.KYCUnverified, nil
+		}
+		return domain.KYCBasic, nil
+	}})
+
+	review := func(handler gin.HandlerFunc, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = append(c.Params, gin.Param{Key: "id", Value: "3"})
+		c.Request = httptest.NewRequest(http.MethodPost, "/admin/kyc/documents/3/approve", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Set("currentUser", domain.User{ID: 1, Role: domain.RoleAdmin})
+		handler(c)
+		return w
+	}
+
+	if w := review(ctr.approveKYCDocumentHandler, `{"note":"ok"}`); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"kyc_tier":"basic"`) {
+		t.Fatalf("approve: expected 200 with basic tier, got %d %s", w.Code, w.Body.String())
+	}
+	if w := review(ctr.rejectKYCDocumentHandler, `{"note":"ok"}`); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"status":"rejected"`) {
+		t.Fatalf("reject: expected 200, got %d %s", w.Code, w.Body.String())
+	}
+	if w := review(ctr.rejectKYCDocumentHandler, `{}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without note, got %d", w.Code)
+	}
+}
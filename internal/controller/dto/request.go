@@ -1,6 +1,11 @@
 package dto
 
-import "github.com/MMII0220/MiniBank/internal/domain"
+import (
+	"fmt"
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+)
 
 // HTTP Request DTOs с JSON тегами
 
@@ -45,7 +50,6 @@ type ReqRegisterHTTP struct {
 	Phone    string `json:"phone" binding:"required"`
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=6"`
-	Role     string `json:"role,omitempty"`
 }
 
 func (r *ReqRegisterHTTP) ToDomain() domain.ReqRegister {
@@ -54,22 +58,112 @@ func (r *ReqRegisterHTTP) ToDomain() domain.ReqRegister {
 		Phone:    r.Phone,
 		Email:    r.Email,
 		Password: r.Password,
-		Role:     r.Role,
+	}
+}
+
+type ReqStaffInviteHTTP struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required,oneof=admin support auditor teller"`
+}
+
+type ReqAcceptInviteHTTP struct {
+	Token    string `json:"token" binding:"required"`
+	FullName string `json:"full_name" binding:"required"`
+	Phone    string `json:"phone" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+func (r *ReqAcceptInviteHTTP) ToDomain() domain.ReqAcceptInvite {
+	return domain.ReqAcceptInvite{
+		Token:    r.Token,
+		FullName: r.FullName,
+		Phone:    r.Phone,
+		Password: r.Password,
 	}
 }
 
 type ReqLoginHTTP struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required"`
+	DeviceID string `json:"device_id,omitempty" binding:"omitempty,max=128"`
 }
 
 func (r *ReqLoginHTTP) ToDomain() domain.ReqLogin {
 	return domain.ReqLogin{
 		Email:    r.Email,
 		Password: r.Password,
+		DeviceID: r.DeviceID,
+	}
+}
+
+type ReqTransferConfirmHTTP struct {
+	ConfirmationID int    `json:"confirmation_id" binding:"required,gt=0"`
+	Code           string `json:"code" binding:"required"`
+}
+
+type ReqMFAVerifyHTTP struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+	DeviceID string `json:"device_id,omitempty" binding:"omitempty,max=128"`
+}
+
+func (r *ReqMFAVerifyHTTP) ToDomain() domain.ReqMFAVerify {
+	return domain.ReqMFAVerify{
+		MFAToken: r.MFAToken,
+		Code:     r.Code,
+		DeviceID: r.DeviceID,
+	}
+}
+
+type ReqMFACodeHTTP struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type ReqUnlockLoginHTTP struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+type ReqStepUpHTTP struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type ReqForgotPasswordHTTP struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ReqResetPasswordHTTP struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+func (r *ReqResetPasswordHTTP) ToDomain() domain.ReqPasswordReset {
+	return domain.ReqPasswordReset{
+		Token:       r.Token,
+		NewPassword: r.NewPassword,
 	}
 }
 
+type ReqChangePasswordHTTP struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+func (r *ReqChangePasswordHTTP) ToDomain() domain.ReqPasswordChange {
+	return domain.ReqPasswordChange{
+		CurrentPassword: r.CurrentPassword,
+		NewPassword:     r.NewPassword,
+	}
+}
+
+type ReqSendVerificationHTTP struct {
+	Channel string `json:"channel" binding:"required,oneof=email phone"`
+}
+
+type ReqVerifyContactHTTP struct {
+	Channel string `json:"channel" binding:"required,oneof=email phone"`
+	Code    string `json:"code" binding:"required"`
+}
+
 type ReqRefreshTokenHTTP struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
 }
@@ -81,13 +175,242 @@ func (r *ReqRefreshTokenHTTP) ToDomain() domain.ReqRefreshToken {
 }
 
 type ReqAdminAccountActionHTTP struct {
-	Block  bool   `json:"block"`
-	Reason string `json:"reason" binding:"required"`
+	Block     bool       `json:"block"`
+	Mode      string     `json:"mode" binding:"omitempty,oneof=debit_freeze credit_freeze full_block legal_hold dormant"`
+	Amount    float64    `json:"amount" binding:"omitempty,gt=0"`
+	Category  string     `json:"category" binding:"omitempty,oneof=fraud aml court_order customer_request operational dormancy other"`
+	Reason    string     `json:"reason" binding:"required"`
+	ExpiresAt *time.Time `json:"expires_at"`
 }
 
 func (r *ReqAdminAccountActionHTTP) ToDomain() domain.ReqAdminAccountAction {
-	return domain.ReqAdminAccountAction{
-		Block:  r.Block,
-		Reason: r.Reason,
+	req := domain.ReqAdminAccountAction{
+		Block:    r.Block,
+		Mode:     domain.RestrictionMode(r.Mode),
+		Amount:   r.Amount,
+		Category: domain.RestrictionCategory(r.Category),
+		Reason:   r.Reason,
+	}
+	if req.Mode == "" {
+		req.Mode = domain.RestrictionFullBlock
+	}
+	if req.Category == "" {
+		req.Category = domain.RestrictionOther
+	}
+	if r.ExpiresAt != nil {
+		req.ExpiresAt = *r.ExpiresAt
+	}
+	return req
+}
+
+// ReqBalanceAdjustmentHTTP - POST /admin/accounts/:id/adjust
+type ReqBalanceAdjustmentHTTP struct {
+	Direction  string  `json:"direction" binding:"required,oneof=credit debit"`
+	Amount     float64 `json:"amount" binding:"required,gt=0"`
+	ReasonCode string  `json:"reason_code" binding:"required,oneof=goodwill error_correction fee_refund chargeback other"`
+	Note       string  `json:"note" binding:"required"`
+}
+
+func (r *ReqBalanceAdjustmentHTTP) ToDomain() domain.ReqBalanceAdjustment {
+	return domain.ReqBalanceAdjustment{
+		Direction:  domain.AdjustmentDirection(r.Direction),
+		Amount:     r.Amount,
+		ReasonCode: domain.AdjustmentReason(r.ReasonCode),
+		Note:       r.Note,
+	}
+}
+
+// ReqAccountReactivationHTTP - POST /api/accounts/:id/reactivate
+type ReqAccountReactivationHTTP struct {
+	Password string `json:"password" binding:"required"`
+	MFACode  string `json:"mfa_code"`
+}
+
+func (r *ReqAccountReactivationHTTP) ToDomain() domain.ReqAccountReactivation {
+	return domain.ReqAccountReactivation{
+		Password: r.Password,
+		MFACode:  r.MFACode,
+	}
+}
+
+// ReqKYCReviewHTTP - POST /admin/kyc/documents/:id/approve и /reject
+type ReqKYCReviewHTTP struct {
+	Note string `json:"note" binding:"required"`
+}
+
+type ReqScreeningDecisionHTTP struct {
+	Clear bool   `json:"clear"`
+	Note  string `json:"note" binding:"required"`
+}
+
+type ReqApprovalDecisionHTTP struct {
+	Note string `json:"note"`
+}
+
+type ReqAccountSignatoryHTTP struct {
+	UserID int `json:"user_id" binding:"required,gt=0"`
+}
+
+type ReqAdminActionHTTP struct {
+	Type       string  `json:"type" binding:"required,oneof=unblock limit_raise manual_adjustment role_change"`
+	AccountID  int     `json:"account_id"`
+	UserID     int     `json:"user_id"`
+	Amount     float64 `json:"amount"`
+	Role       string  `json:"role"`
+	ReasonCode string  `json:"reason_code" binding:"omitempty,oneof=goodwill error_correction fee_refund chargeback other"` // для manual_adjustment
+	Mode       string  `json:"mode" binding:"omitempty,oneof=debit_freeze credit_freeze full_block legal_hold dormant"`     // для unblock
+	Reason     string  `json:"reason" binding:"required"`
+}
+
+func (r *ReqAdminActionHTTP) ToDomain() domain.PendingAction {
+	return domain.PendingAction{
+		Type:            domain.PendingActionType(r.Type),
+		AccountID:       r.AccountID,
+		UserID:          r.UserID,
+		Amount:          r.Amount,
+		NewRole:         domain.Role(r.Role),
+		ReasonCode:      domain.AdjustmentReason(r.ReasonCode),
+		RestrictionMode: domain.RestrictionMode(r.Mode),
+		Reason:          r.Reason,
+	}
+}
+
+type ReqCreateAPIClientHTTP struct {
+	Name               string   `json:"name" binding:"required"`
+	OwnerUserID        int      `json:"owner_user_id" binding:"required,gt=0"`
+	Scopes             []string `json:"scopes" binding:"required,min=1"`
+	RateLimitPerMinute int      `json:"rate_limit_per_minute" binding:"gte=0"`
+}
+
+func (r *ReqCreateAPIClientHTTP) ToDomain() domain.ReqCreateAPIClient {
+	scopes := make([]domain.Scope, len(r.Scopes))
+	for i, s := range r.Scopes {
+		scopes[i] = domain.Scope(s)
+	}
+	return domain.ReqCreateAPIClient{
+		Name:               r.Name,
+		OwnerUserID:        r.OwnerUserID,
+		Scopes:             scopes,
+		RateLimitPerMinute: r.RateLimitPerMinute,
+	}
+}
+
+// ReqClientTokenHTTP - OAuth2 token endpoint принимает form-urlencoded (по RFC 6749) и JSON
+type ReqClientTokenHTTP struct {
+	GrantType    string `form:"grant_type" json:"grant_type"`
+	ClientID     string `form:"client_id" json:"client_id"`
+	ClientSecret string `form:"client_secret" json:"client_secret"`
+	Scope        string `form:"scope" json:"scope"`
+}
+
+func (r *ReqClientTokenHTTP) ToDomain() domain.ReqClientToken {
+	return domain.ReqClientToken{
+		GrantType:    r.GrantType,
+		ClientID:     r.ClientID,
+		ClientSecret: r.ClientSecret,
+		Scope:        r.Scope,
+	}
+}
+
+// ReqUpdateProfileHTTP - PATCH /api/me, отсутствующее поле не меняется
+type ReqUpdateProfileHTTP struct {
+	FullName        *string `json:"full_name"`
+	Email           *string `json:"email"`
+	Phone           *string `json:"phone"`
+	CurrentPassword string  `json:"current_password"`
+	ReissueCards    bool    `json:"reissue_cards"`
+}
+
+func (r *ReqUpdateProfileHTTP) ToDomain() domain.ReqUpdateProfile {
+	return domain.ReqUpdateProfile{
+		FullName:        r.FullName,
+		Email:           r.Email,
+		Phone:           r.Phone,
+		CurrentPassword: r.CurrentPassword,
+		ReissueCards:    r.ReissueCards,
+	}
+}
+
+// ReqUserSearchHTTP - GET /admin/users?q=...&limit=...&offset=...
+type ReqUserSearchHTTP struct {
+	Query  string `form:"q"`
+	Limit  int    `form:"limit" binding:"gte=0,lte=100"`
+	Offset int    `form:"offset" binding:"gte=0"`
+}
+
+func (r *ReqUserSearchHTTP) ToDomain() domain.UserSearch {
+	return domain.UserSearch{Query: r.Query, Limit: r.Limit, Offset: r.Offset}
+}
+
+type ReqUserStatusHTTP struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// ReqAuditLogQueryHTTP - фильтры журнала account_audit: GET /admin/getAuditLogs?admin_id=&account_id=&action=&from=&to=&cursor=&limit=
+// from и to - RFC3339 или дата YYYY-MM-DD (дата в to включается целиком)
+type ReqAuditLogQueryHTTP struct {
+	AdminID   int    `form:"admin_id" binding:"gte=0"`
+	AccountID int    `form:"account_id" binding:"gte=0"`
+	Action    string `form:"action"`
+	From      string `form:"from"`
+	To        string `form:"to"`
+	Cursor    int    `form:"cursor" binding:"gte=0"`
+	Limit     int    `form:"limit" binding:"gte=0"`
+}
+
+func (r *ReqAuditLogQueryHTTP) ToDomain() (domain.AuditLogFilter, error) {
+	filter := domain.AuditLogFilter{
+		AdminID:   r.AdminID,
+		AccountID: r.AccountID,
+		Action:    r.Action,
+		Cursor:    r.Cursor,
+		Limit:     r.Limit,
+	}
+	var err error
+	if filter.From, err = parseQueryTime(r.From, false); err != nil {
+		return filter, fmt.Errorf("invalid from: %w", err)
+	}
+	if filter.To, err = parseQueryTime(r.To, true); err != nil {
+		return filter, fmt.Errorf("invalid to: %w", err)
+	}
+	return filter, nil
+}
+
+// parseQueryTime - RFC3339 или дата; для конца периода дата означает начало следующего дня
+func parseQueryTime(value string, endOfPeriod bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	day, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected RFC3339 or YYYY-MM-DD, got %q", value)
+	}
+	if endOfPeriod {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day, nil
+}
+
+// ReqStatsHTTP - GET /admin/stats?from=&to=&format=json|csv&report=summary|volumes|breaches
+type ReqStatsHTTP struct {
+	From   string `form:"from"`
+	To     string `form:"to"`
+	Format string `form:"format" binding:"omitempty,oneof=json csv"`
+	Report string `form:"report" binding:"omitempty,oneof=summary volumes breaches"`
+}
+
+// Period - границы отчета; дата в to включается целиком
+func (r *ReqStatsHTTP) Period() (time.Time, time.Time, error) {
+	from, err := parseQueryTime(r.From, false)
+	if err != nil {
+		return from, time.Time{}, fmt.Errorf("invalid from: %w", err)
+	}
+	to, err := parseQueryTime(r.To, true)
+	if err != nil {
+		return from, to, fmt.Errorf("invalid to: %w", err)
 	}
+	return from, to, nil
 }
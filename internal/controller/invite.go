@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/MMII0220/MiniBank/internal/controller/dto"
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// Приглашение сотрудника, токен уходит на email приглашенного и в ответе не возвращается
+func (ctr *Controller) inviteStaffHandler(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(domain.User)
+
+	var req dto.ReqStaffInviteHTTP
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	invite, err := ctr.svc(c).InviteStaff(c.Request.Context(), req.Email, domain.Role(req.Role), currentUser.ID)
+	if err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"invite_id":  invite.ID,
+		"email":      invite.Email,
+		"role":       invite.Role,
+		"expires_at": invite.ExpiresAt,
+	})
+}
+
+// Регистрация сотрудника по токену из приглашения
+func (ctr *Controller) acceptInviteHandler(c *gin.Context) {
+	var req dto.ReqAcceptInviteHTTP
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := ctr.svc(c).AcceptInvite(c.Request.Context(), req.ToDomain())
+	if err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user_id": user.ID, "role": user.Role})
+}
@@ -0,0 +1,181 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/MMII0220/MiniBank/internal/controller/dto"
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// kycDocumentJSON - документ без ключа хранилища: файл отдается только через /admin/kyc/documents/:id/file
+func kycDocumentJSON(d domain.KYCDocument) gin.H {
+	result := gin.H{
+		"id":           d.ID,
+		"user_id":      d.UserID,
+		"doc_type":     d.Type,
+		"file_name":    d.FileName,
+		"content_type": d.ContentType,
+		"size":         d.Size,
+		"sha256":       d.SHA256,
+		"status":       d.Status,
+		"created_at":   d.CreatedAt,
+	}
+	if d.Status != domain.KYCDocumentPending {
+		result["reviewed_by"] = d.ReviewedBy
+		result["review_note"] = d.ReviewNote
+		result["reviewed_at"] = d.ReviewedAt
+	}
+	return result
+}
+
+func kycDocumentsJSON(docs []domain.KYCDocument) []gin.H {
+	result := make([]gin.H, 0, len(docs))
+	for _, d := range docs {
+		result = append(result, kycDocumentJSON(d))
+	}
+	return result
+}
+
+// Загрузка документа KYC: multipart/form-data с полями doc_type и file
+func (ctr *Controller) submitKYCDocumentHandler(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(domain.User)
+
+	docType := domain.KYCDocumentType(c.PostForm("doc_type"))
+	if !docType.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "doc_type must be passport, national_id or proof_of_address"})
+		return
+	}
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot read file"})
+		return
+	}
+	defer file.Close()
+
+	doc, err := ctr.svc(c).SubmitKYCDocument(c.Request.Context(), currentUser.ID, domain.ReqKYCDocument{
+		Type:     docType,
+		FileName: header.Filename,
+		Body:     file,
+	})
+	if err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, kycDocumentJSON(doc))
+}
+
+// Уровень KYC текущего пользователя, его лимиты и загруженные документы
+func (ctr *Controller) getKYCStatusHandler(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(domain.User)
+
+	status, err := ctr.svc(c).KYCStatus(c.Request.Context(), currentUser.ID)
+	if err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"kyc_tier":    status.Tier,
+		"daily_limit": status.Policy.DailyAmount,
+		"max_daily":   status.Policy.MaxDaily,
+		"documents":   kycDocumentsJSON(status.Documents),
+		"total_count": len(status.Documents),
+	})
+}
+
+// Очередь проверки документов, ?status=pending (по умолчанию)|approved|rejected|all
+func (ctr *Controller) getKYCReviewsHandler(c *gin.Context) {
+	status := domain.KYCDocumentStatus(c.DefaultQuery("status", string(domain.KYCDocumentPending)))
+	switch status {
+	case domain.KYCDocumentPending, domain.KYCDocumentApproved, domain.KYCDocumentRejected:
+	case "all":
+		status = ""
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+		return
+	}
+
+	docs, err := ctr.svc(c).KYCReviewQueue(c.Request.Context(), status)
+	if err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"documents":   kycDocumentsJSON(docs),
+		"total_count": len(docs),
+	})
+}
+
+// Файл документа для проверяющего; просмотр пишется в аудит
+func (ctr *Controller) getKYCDocumentFileHandler(c *gin.Context) {
+	docID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || docID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid document id"})
+		return
+	}
+
+	doc, body, err := ctr.svc(c).OpenKYCDocument(c.Request.Context(), docID)
+	if err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+	defer func() {
+		if err := body.Close(); err != nil {
+			log := logger.GetLogger()
+			log.Warn().Err(err).Int("document_id", docID).Msg("Failed to close KYC document")
+		}
+	}()
+
+	c.DataFromReader(http.StatusOK, doc.Size, doc.ContentType, body, map[string]string{
+		"Content-Disposition": fmt.Sprintf(`inline; filename=%q`, doc.FileName),
+		"Cache-Control":       "no-store",
+	})
+}
+
+func (ctr *Controller) approveKYCDocumentHandler(c *gin.Context) {
+	ctr.reviewKYCDocument(c, true)
+}
+
+func (ctr *Controller) rejectKYCDocumentHandler(c *gin.Context) {
+	ctr.reviewKYCDocument(c, false)
+}
+
+// reviewKYCDocument - решение по документу; в ответе уровень клиента после решения
+func (ctr *Controller) reviewKYCDocument(c *gin.Context, approve bool) {
+	currentUser := c.MustGet("currentUser").(domain.User)
+
+	docID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || docID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid document id"})
+		return
+	}
+
+	var req dto.ReqKYCReviewHTTP
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tier, err := ctr.svc(c).ReviewKYCDocument(c.Request.Context(), docID, currentUser.ID, approve, req.Note)
+	if err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	status := domain.KYCDocumentRejected
+	if approve {
+		status = domain.KYCDocumentApproved
+	}
+	c.JSON(http.StatusOK, gin.H{"document_id": docID, "status": status, "kyc_tier": tier})
+}
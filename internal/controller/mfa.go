@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/MMII0220/MiniBank/internal/controller/dto"
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// Второй шаг входа: challenge токен из /auth/login + код из приложения или код восстановления
+func (ctr *Controller) verifyMFAHandler(c *gin.Context) {
+	var req dto.ReqMFAVerifyHTTP
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	domainReq := req.ToDomain()
+	var ok bool
+	if domainReq.DeviceID, ok = deviceToken(c, domainReq.DeviceID); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "device id is too long"})
+		return
+	}
+	domainReq.UserAgent = c.Request.UserAgent()
+	domainReq.IP = c.ClientIP()
+
+	tokenResponse, err := ctr.svc(c).VerifyMFA(c.Request.Context(), domainReq)
+	if err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	setDeviceCookie(c, tokenResponse.DeviceToken)
+	c.JSON(http.StatusOK, tokenResponse)
+}
+
+// Начало подключения 2FA: секрет и otpauth:// URI для QR кода
+func (ctr *Controller) enrollMFAHandler(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(domain.User)
+
+	enrollment, err := ctr.svc(c).EnrollMFA(c.Request.Context(), currentUser.ID)
+	if err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"secret":           enrollment.Secret,
+		"provisioning_uri": enrollment.ProvisioningURI,
+	})
+}
+
+// Подтверждение подключения первым кодом, в ответе - коды восстановления (показываются один раз)
+func (ctr *Controller) confirmMFAHandler(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(domain.User)
+
+	var req dto.ReqMFACodeHTTP
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	codes, err := ctr.svc(c).ConfirmMFA(c.Request.Context(), currentUser.ID, currentUser.SessionID, req.Code)
+	if err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "two-factor authentication enabled",
+		"recovery_codes": codes,
+	})
+}
+
+// Новый набор кодов восстановления
+func (ctr *Controller) regenerateRecoveryCodesHandler(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(domain.User)
+
+	var req dto.ReqMFACodeHTTP
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	codes, err := ctr.svc(c).RegenerateRecoveryCodes(c.Request.Context(), currentUser.ID, req.Code)
+	if err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// Отключение 2FA (для админов запрещено)
+func (ctr *Controller) disableMFAHandler(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(domain.User)
+
+	var req dto.ReqMFACodeHTTP
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := ctr.svc(c).DisableMFA(c.Request.Context(), currentUser.ID, req.Code); err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "two-factor authentication disabled"})
+}
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/MMII0220/MiniBank/internal/controller/dto"
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// Запрос на восстановление пароля; ответ одинаковый для известных и неизвестных email
+func (ctr *Controller) forgotPasswordHandler(c *gin.Context) {
+	var req dto.ReqForgotPasswordHTTP
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := ctr.svc(c).ForgotPassword(c.Request.Context(), req.Email); err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "if the email is registered, a reset token has been sent"})
+}
+
+// Новый пароль по токену из письма, все сессии пользователя завершаются
+func (ctr *Controller) resetPasswordHandler(c *gin.Context) {
+	var req dto.ReqResetPasswordHTTP
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := ctr.svc(c).ResetPassword(c.Request.Context(), req.ToDomain()); err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "password has been reset, sign in again"})
+}
+
+// Смена пароля с подтверждением текущего, после нее все сессии (включая текущую) завершаются
+func (ctr *Controller) changePasswordHandler(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(domain.User)
+
+	var req dto.ReqChangePasswordHTTP
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := ctr.svc(c).ChangePassword(c.Request.Context(), currentUser.ID, req.ToDomain()); err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "password changed, sign in again"})
+}
@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/MMII0220/MiniBank/internal/controller/dto"
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// Очередь админских действий, ?status=pending|approved|rejected|expired
+func (ctr *Controller) getPendingActionsHandler(c *gin.Context) {
+	status := domain.PendingActionStatus(c.Query("status"))
+	switch status {
+	case "", domain.PendingActionPending, domain.PendingActionApproved,
+		domain.PendingActionRejected, domain.PendingActionExpired:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+		return
+	}
+
+	actions, err := ctr.svc(c).PendingActions(c.Request.Context(), status)
+	if err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"actions":     actions,
+		"total_count": len(actions),
+	})
+}
+
+// Предложение действия: unblock, limit_raise, manual_adjustment, role_change
+func (ctr *Controller) proposeActionHandler(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(domain.User)
+
+	var req dto.ReqAdminActionHTTP
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	proposed := req.ToDomain()
+	if !currentUser.Can(proposed.Type.ProposePermission()) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
+		return
+	}
+
+	action, err := ctr.svc(c).ProposeAdminAction(c.Request.Context(), proposed, currentUser.ID)
+	if err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":    "action is pending approval",
+		"action_id":  action.ID,
+		"expires_at": action.ExpiresAt,
+	})
+}
+
+func (ctr *Controller) approveActionHandler(c *gin.Context) {
+	ctr.decideAction(c, true)
+}
+
+func (ctr *Controller) rejectActionHandler(c *gin.Context) {
+	ctr.decideAction(c, false)
+}
+
+// Общая часть approve/reject, запрет самоодобрения проверяется в сервисе
+func (ctr *Controller) decideAction(c *gin.Context, approve bool) {
+	currentUser := c.MustGet("currentUser").(domain.User)
+
+	actionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || actionID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid action id"})
+		return
+	}
+
+	var req dto.ReqApprovalDecisionHTTP
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+			return
+		}
+	}
+	if !approve && req.Note == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "note is required to reject an action"})
+		return
+	}
+
+	if err := ctr.svc(c).DecidePendingAction(c.Request.Context(), actionID, currentUser.ID, approve, req.Note); err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	message := "action approved and executed"
+	if !approve {
+		message = "action rejected"
+	}
+	c.JSON(http.StatusOK, gin.H{"message": message})
+}
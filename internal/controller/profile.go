@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/MMII0220/MiniBank/internal/controller/dto"
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// maskCardNumber - в профиле видны только последние 4 цифры карты
+func maskCardNumber(number string) string {
+	if len(number) <= 4 {
+		return number
+	}
+	return "**** " + number[len(number)-4:]
+}
+
+func profileUserJSON(user domain.User) gin.H {
+	return gin.H{
+		"id":             user.ID,
+		"full_name":      user.FullName,
+		"email":          user.Email,
+		"email_verified": user.EmailVerified,
+		"phone":          user.Phone,
+		"phone_verified": user.PhoneVerified,
+		"role":           user.Role,
+		"created_at":     user.CreatedAt,
+		"updated_at":     user.UpdatedAt,
+	}
+}
+
+// Профиль пользователя со сводкой по счетам и картам (без полного номера и CVV)
+func (ctr *Controller) getProfileHandler(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(domain.User)
+
+	profile, err := ctr.svc(c).Profile(c.Request.Context(), currentUser.ID)
+	if err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	accounts := make([]gin.H, 0, len(profile.Accounts))
+	for _, a := range profile.Accounts {
+		accounts = append(accounts, gin.H{
+			"id":       a.ID,
+			"currency": a.Currency,
+			"balance":  a.Balance,
+			"blocked":  a.Blocked,
+			"type":     a.Type,
+		})
+	}
+	cards := make([]gin.H, 0, len(profile.Cards))
+	for _, card := range profile.Cards {
+		cards = append(cards, gin.H{
+			"id":          card.ID,
+			"account_id":  card.AccountID,
+			"card_number": maskCardNumber(card.CardNumber),
+			"holder_name": card.CardHolderName,
+			"expiry":      card.ExpiryDate.Format("01/06"),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user":     profileUserJSON(profile.User),
+		"accounts": accounts,
+		"cards":    cards,
+	})
+}
+
+// Изменение имени, email и телефона; новый email или телефон нужно подтвердить через /api/me/verification
+func (ctr *Controller) updateProfileHandler(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(domain.User)
+
+	var req dto.ReqUpdateProfileHTTP
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := ctr.svc(c).UpdateProfile(c.Request.Context(), currentUser.ID, req.ToDomain())
+	if err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user": profileUserJSON(user)})
+}
+
+// История изменений профиля
+func (ctr *Controller) getProfileChangesHandler(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(domain.User)
+
+	changes, err := ctr.svc(c).ProfileChanges(c.Request.Context(), currentUser.ID)
+	if err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	result := make([]gin.H, 0, len(changes))
+	for _, ch := range changes {
+		result = append(result, gin.H{
+			"field":      ch.Field,
+			"old_value":  ch.OldValue,
+			"new_value":  ch.NewValue,
+			"changed_by": ch.ChangedBy,
+			"created_at": ch.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, result)
+}
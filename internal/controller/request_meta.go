@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/domain/contracts"
+	"github.com/gin-gonic/gin"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// requestIDMiddleware - id запроса из X-Request-ID (если его поставил прокси) или новый.
+// Id возвращается в ответе и попадает в события аудита.
+func requestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(requestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+		c.Set("requestID", requestID)
+		c.Header(requestIDHeader, requestID)
+		c.Next()
+	}
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// svc - сервис в рамках текущего запроса: события аудита получают id запроса, IP, User-Agent
+// и пользователя из токена
+func (ctr *Controller) svc(c *gin.Context) contracts.ServiceI {
+	var meta domain.RequestMeta
+	if user, ok := c.Get("currentUser"); ok {
+		meta = domain.RequestMetaFor(user.(domain.User))
+	}
+	meta.RequestID = c.GetString("requestID")
+	meta.IP = c.ClientIP()
+	meta.UserAgent = c.Request.UserAgent()
+	return ctr.service.WithRequest(meta)
+}
@@ -1,41 +1,107 @@
 package controller
 
 import (
-	"os"
-
 	"github.com/MMII0220/MiniBank/internal/domain"
 	"github.com/gin-gonic/gin"
 )
 
-func (ctr *Controller) SetupRoutes() {
+// SetupRoutes собирает роутер; HTTP сервер с таймаутами и остановкой запускается в app
+func (ctr *Controller) SetupRoutes() *gin.Engine {
 	r := gin.Default()
+	r.Use(requestIDMiddleware())
 
 	r.GET("/ping", ctr.healthCheck)
 	r.GET("/health/redis", ctr.redisHealth)
+	r.GET("/.well-known/jwks.json", ctr.jwksHandler)
 
 	auth := r.Group("/auth")
 	{
 		auth.POST("/register", ctr.registerHandler)
 		auth.POST("/login", ctr.loginHandler)
 		auth.POST("/refresh", ctr.refreshTokenHandler)
+		auth.POST("/token", ctr.clientTokenHandler) // OAuth2 client credentials для API клиентов
+		auth.POST("/logout", ctr.AuthMiddleware(""), ctr.logoutHandler)
+		auth.POST("/logout-all", ctr.AuthMiddleware(""), ctr.logoutAllHandler)
+		auth.POST("/mfa/verify", ctr.verifyMFAHandler)
+		auth.POST("/invites/accept", ctr.acceptInviteHandler)
+		auth.POST("/password/forgot", ctr.forgotPasswordHandler)
+		auth.POST("/password/reset", ctr.resetPasswordHandler)
+		auth.POST("/mfa/enroll", ctr.AuthMiddleware(""), ctr.enrollMFAHandler)
+		auth.POST("/mfa/confirm", ctr.AuthMiddleware(""), ctr.confirmMFAHandler)
+		auth.POST("/mfa/recovery-codes", ctr.AuthMiddleware(""), ctr.regenerateRecoveryCodesHandler)
+		auth.POST("/mfa/disable", ctr.AuthMiddleware(""), ctr.disableMFAHandler)
 	}
 
 	admin := r.Group("/admin")
-	admin.Use(ctr.AuthMiddleware(domain.RoleAdmin))
 	{
-		admin.POST("/blockUnblock/:id", ctr.blockUnblockAccountHandler)
-		admin.GET("/getAuditLogs", ctr.getAuditLogsHandler)
+		// Доступ к служебным маршрутам определяется матрицей прав ролей (domain/permission.go)
+		admin.POST("/blockUnblock/:id", ctr.AuthMiddleware(domain.PermAccountsBlock), ctr.blockUnblockAccountHandler)
+		admin.GET("/accounts/:id/restrictions", ctr.AuthMiddleware(domain.PermAccountsBlock), ctr.getAccountRestrictionsHandler)
+		admin.GET("/getAuditLogs", ctr.AuthMiddleware(domain.PermAuditRead), ctr.getAuditLogsHandler)
+		admin.GET("/audit-logs/export", ctr.AuthMiddleware(domain.PermAuditRead), ctr.exportAuditLogsHandler)
+		admin.GET("/accounts/:id/audit", ctr.AuthMiddleware(domain.PermAuditRead), ctr.getAccountAuditTimelineHandler)
+		admin.GET("/stats", ctr.AuthMiddleware(domain.PermStatsRead), ctr.getStatsHandler)
+		admin.GET("/reports/dormant-balances", ctr.AuthMiddleware(domain.PermStatsRead), ctr.getDormantBalancesHandler)
+		admin.GET("/screening/reviews", ctr.AuthMiddleware(domain.PermScreeningRead), ctr.getScreeningReviewsHandler)
+		admin.POST("/screening/reviews/:id/resolve", ctr.AuthMiddleware(domain.PermScreeningReview), ctr.resolveScreeningReviewHandler)
+		admin.GET("/kyc/documents", ctr.AuthMiddleware(domain.PermKYCRead), ctr.getKYCReviewsHandler)
+		admin.GET("/kyc/documents/:id/file", ctr.AuthMiddleware(domain.PermKYCRead), ctr.getKYCDocumentFileHandler)
+		admin.POST("/kyc/documents/:id/approve", ctr.AuthMiddleware(domain.PermKYCReview), ctr.approveKYCDocumentHandler)
+		admin.POST("/kyc/documents/:id/reject", ctr.AuthMiddleware(domain.PermKYCReview), ctr.rejectKYCDocumentHandler)
+		admin.GET("/approvals", ctr.AuthMiddleware(domain.PermApprovalsRead), ctr.getApprovalsHandler)
+		admin.POST("/approvals/:id/approve", ctr.AuthMiddleware(domain.PermApprovalsDecide), ctr.approveTransferHandler)
+		admin.POST("/approvals/:id/reject", ctr.AuthMiddleware(domain.PermApprovalsDecide), ctr.rejectTransferHandler)
+		admin.POST("/accounts/:id/signatories", ctr.AuthMiddleware(domain.PermAccountsManage), ctr.addAccountSignatoryHandler)
+		admin.POST("/accounts/:id/adjust", ctr.AuthMiddleware(domain.PermAccountsManage), ctr.adjustBalanceHandler)
+		admin.GET("/actions", ctr.AuthMiddleware(domain.PermAdminActionsRead), ctr.getPendingActionsHandler)
+		admin.POST("/actions", ctr.AuthMiddleware(domain.PermAdminActionsPropose), ctr.proposeActionHandler)
+		admin.POST("/actions/:id/approve", ctr.AuthMiddleware(domain.PermAdminActionsDecide), ctr.approveActionHandler)
+		admin.POST("/actions/:id/reject", ctr.AuthMiddleware(domain.PermAdminActionsDecide), ctr.rejectActionHandler)
+		admin.GET("/users", ctr.AuthMiddleware(domain.PermUsersRead), ctr.searchUsersHandler)
+		admin.GET("/users/:id", ctr.AuthMiddleware(domain.PermUsersRead), ctr.getUserDetailHandler)
+		admin.GET("/users/:id/history", ctr.AuthMiddleware(domain.PermUsersRead), ctr.getUserHistoryHandler)
+		admin.POST("/users/:id/disable", ctr.AuthMiddleware(domain.PermUsersDisable), ctr.disableUserHandler)
+		admin.POST("/users/:id/enable", ctr.AuthMiddleware(domain.PermUsersDisable), ctr.enableUserHandler)
+		admin.GET("/login-locks", ctr.AuthMiddleware(domain.PermUsersUnlock), ctr.getLoginLocksHandler)
+		admin.POST("/users/:id/unlock", ctr.AuthMiddleware(domain.PermUsersUnlock), ctr.unlockLoginHandler)
+		admin.POST("/invites", ctr.AuthMiddleware(domain.PermUsersInvite), ctr.inviteStaffHandler)
+		admin.GET("/api-clients", ctr.AuthMiddleware(domain.PermAPIClientsManage), ctr.getAPIClientsHandler)
+		admin.POST("/api-clients", ctr.AuthMiddleware(domain.PermAPIClientsManage), ctr.createAPIClientHandler)
+		admin.POST("/api-clients/:id/revoke", ctr.AuthMiddleware(domain.PermAPIClientsManage), ctr.revokeAPIClientHandler)
+	}
+
+	// Профиль доступен любому вошедшему пользователю, включая сотрудников без banking:use
+	me := r.Group("/api/me")
+	me.Use(ctr.AuthMiddleware(""))
+	{
+		me.GET("", ctr.getProfileHandler)
+		me.PATCH("", ctr.updateProfileHandler)
+		me.GET("/changes", ctr.getProfileChangesHandler)
+		me.POST("/password", ctr.changePasswordHandler)
+		me.POST("/verification", ctr.sendVerificationHandler)
+		me.POST("/verification/confirm", ctr.verifyContactHandler)
+		me.GET("/kyc", ctr.getKYCStatusHandler)
+		me.POST("/kyc/documents", ctr.submitKYCDocumentHandler)
 	}
 
 	api := r.Group("/api")
-	api.Use(ctr.AuthMiddleware(domain.RoleUser))
+	api.Use(ctr.AuthMiddleware(domain.PermBankingUse))
 	{
 		api.POST("/deposit", ctr.depositHandler)
 		api.POST("/withdraw", ctr.withdrawHandler)
 		api.POST("/transfer", ctr.transferHandler)
+		api.POST("/transfer/confirm", ctr.confirmTransferHandler)
 		api.GET("/history", ctr.historyLogs)
 		api.GET("/accounts", ctr.getAllAccountsHandler)
+		api.POST("/accounts/:id/reactivate", ctr.reactivateAccountHandler)
+		api.GET("/sessions", ctr.getSessionsHandler)
+		api.DELETE("/sessions/:id", ctr.terminateSessionHandler)
+		api.POST("/sessions/step-up/code", ctr.stepUpCodeHandler)
+		api.POST("/sessions/step-up", ctr.stepUpHandler)
+		api.GET("/approvals", ctr.getSignatoryApprovalsHandler)
+		api.POST("/approvals/:id/approve", ctr.approveTransferHandler)
+		api.POST("/approvals/:id/reject", ctr.rejectTransferHandler)
 	}
 
-	r.Run(":" + os.Getenv("ROUTER_RUN"))
+	return r
 }
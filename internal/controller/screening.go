@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/MMII0220/MiniBank/internal/controller/dto"
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// Очередь проверок по санкционным спискам, ?status=pending|cleared|confirmed
+func (ctr *Controller) getScreeningReviewsHandler(c *gin.Context) {
+	status := domain.ScreeningStatus(c.Query("status"))
+	switch status {
+	case "", domain.ScreeningPending, domain.ScreeningCleared, domain.ScreeningConfirmed:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+		return
+	}
+
+	reviews, err := ctr.svc(c).ScreeningReviews(c.Request.Context(), status)
+	if err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"reviews":     reviews,
+		"total_count": len(reviews),
+	})
+}
+
+// Решение по совпадению: clear=true снимает блокировку, clear=false подтверждает совпадение
+func (ctr *Controller) resolveScreeningReviewHandler(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(domain.User)
+
+	reviewID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || reviewID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid review id"})
+		return
+	}
+
+	var req dto.ReqScreeningDecisionHTTP
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	if err := ctr.svc(c).ResolveScreeningReview(c.Request.Context(), reviewID, currentUser.ID, req.Clear, req.Note); err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "screening review resolved"})
+}
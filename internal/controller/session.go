@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/MMII0220/MiniBank/internal/controller/dto"
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// Активные сессии текущего пользователя: устройство, user agent, IP, время входа и последнего использования
+func (ctr *Controller) getSessionsHandler(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(domain.User)
+
+	sessions, err := ctr.svc(c).ListSessions(c.Request.Context(), currentUser.ID)
+	if err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	result := make([]gin.H, 0, len(sessions))
+	for _, s := range sessions {
+		result = append(result, gin.H{
+			"id":               s.ID,
+			"device_id":        s.DeviceID,
+			"user_agent":       s.UserAgent,
+			"ip":               s.IP,
+			"created_at":       s.CreatedAt,
+			"last_used_at":     s.LastUsedAt,
+			"step_up_required": s.StepUpRequired,
+			"current":          s.ID == currentUser.SessionID,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"sessions":    result,
+		"total_count": len(result),
+	})
+}
+
+// Завершение одной из своих сессий
+func (ctr *Controller) terminateSessionHandler(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(domain.User)
+
+	sessionID := c.Param("id")
+	if sessionID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session id"})
+		return
+	}
+
+	if err := ctr.svc(c).TerminateSession(c.Request.Context(), currentUser.ID, sessionID); err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "session terminated"})
+}
+
+// Повторная отправка кода подтверждения сессии после входа с нового устройства
+func (ctr *Controller) stepUpCodeHandler(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(domain.User)
+
+	if err := ctr.svc(c).SendStepUpCode(c.Request.Context(), currentUser.ID, currentUser.SessionID); err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "confirmation code sent"})
+}
+
+// Подтверждение текущей сессии кодом из SMS или email после входа с нового устройства
+func (ctr *Controller) stepUpHandler(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(domain.User)
+
+	var req dto.ReqStepUpHTTP
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := ctr.svc(c).CompleteStepUp(c.Request.Context(), currentUser.ID, currentUser.SessionID, req.Code, c.ClientIP()); err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "session verified"})
+}
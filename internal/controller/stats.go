@@ -0,0 +1,169 @@
+package controller
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/controller/dto"
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/logger"
+	"github.com/gin-gonic/gin"
+)
+
+func formatAmount(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+func statsJSON(stats domain.AdminStats) gin.H {
+	volumes := make([]gin.H, 0, len(stats.Volumes))
+	for _, v := range stats.Volumes {
+		volumes = append(volumes, gin.H{
+			"day":      v.Day.Format("2006-01-02"),
+			"currency": v.Currency,
+			"type":     v.Type,
+			"count":    v.Count,
+			"volume":   v.Volume,
+			"fees":     v.Fees,
+		})
+	}
+	breaches := make([]gin.H, 0, len(stats.TopLimitBreaches))
+	for _, b := range stats.TopLimitBreaches {
+		breaches = append(breaches, gin.H{
+			"user_id":        b.UserID,
+			"full_name":      b.FullName,
+			"email":          b.Email,
+			"breaches":       b.Breaches,
+			"last_breach_at": b.LastBreachAt,
+		})
+	}
+	feeIncome := stats.FeeIncome
+	if feeIncome == nil {
+		feeIncome = map[string]float64{}
+	}
+
+	return gin.H{
+		"from":               stats.From,
+		"to":                 stats.To,
+		"generated_at":       stats.GeneratedAt,
+		"new_users":          stats.NewUsers,
+		"active_accounts":    stats.ActiveAccounts,
+		"blocked_accounts":   stats.BlockedAccounts,
+		"volumes":            volumes,
+		"fee_income":         feeIncome,
+		"top_limit_breaches": breaches,
+	}
+}
+
+// statsCSV - одна таблица отчета: summary (показатель, значение), volumes (обороты по дням) или breaches
+func statsCSV(stats domain.AdminStats, report string) [][]string {
+	switch report {
+	case "summary":
+		rows := [][]string{
+			{"metric", "value"},
+			{"from", stats.From.Format(time.RFC3339)},
+			{"to", stats.To.Format(time.RFC3339)},
+			{"new_users", strconv.Itoa(stats.NewUsers)},
+			{"active_accounts", strconv.Itoa(stats.ActiveAccounts)},
+			{"blocked_accounts", strconv.Itoa(stats.BlockedAccounts)},
+		}
+		currencies := make([]string, 0, len(stats.FeeIncome))
+		for currency := range stats.FeeIncome {
+			currencies = append(currencies, currency)
+		}
+		sort.Strings(currencies)
+		for _, currency := range currencies {
+			rows = append(rows, []string{"fee_income_" + currency, formatAmount(stats.FeeIncome[currency])})
+		}
+		return rows
+	case "breaches":
+		rows := [][]string{{"user_id", "full_name", "email", "breaches", "last_breach_at"}}
+		for _, b := range stats.TopLimitBreaches {
+			rows = append(rows, []string{
+				strconv.Itoa(b.UserID), b.FullName, b.Email, strconv.Itoa(b.Breaches), b.LastBreachAt.UTC().Format(time.RFC3339),
+			})
+		}
+		return rows
+	default:
+		rows := [][]string{{"day", "currency", "type", "count", "volume", "fees"}}
+		for _, v := range stats.Volumes {
+			rows = append(rows, []string{
+				v.Day.Format("2006-01-02"), v.Currency, string(v.Type), strconv.Itoa(v.Count), formatAmount(v.Volume), formatAmount(v.Fees),
+			})
+		}
+		return rows
+	}
+}
+
+// Операционная панель: новые клиенты, счета, обороты по дням и валютам, комиссии, превышения лимита
+func (ctr *Controller) getStatsHandler(c *gin.Context) {
+	var req dto.ReqStatsHTTP
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	from, to, err := req.Period()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	stats, err := ctr.svc(c).AdminStats(c.Request.Context(), from, to)
+	if err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	if req.Format != "csv" {
+		c.JSON(http.StatusOK, statsJSON(stats))
+		return
+	}
+
+	report := req.Report
+	if report == "" {
+		report = "volumes"
+	}
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="stats-%s-%s-%s.csv"`,
+		report, stats.From.Format("20060102"), stats.To.Format("20060102")))
+	c.Status(http.StatusOK)
+	w := csv.NewWriter(c.Writer)
+	if err := w.WriteAll(statsCSV(stats, report)); err != nil {
+		log := logger.GetLogger()
+		log.Error().Err(err).Msg("Failed to write stats csv")
+	}
+}
+
+// Остатки на спящих счетах по валютам; format=csv отдает ту же таблицу файлом
+func (ctr *Controller) getDormantBalancesHandler(c *gin.Context) {
+	balances, err := ctr.svc(c).DormantBalances(c.Request.Context())
+	if err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	if c.Query("format") != "csv" {
+		result := make([]gin.H, 0, len(balances))
+		for _, b := range balances {
+			result = append(result, gin.H{"currency": b.Currency, "accounts": b.Accounts, "balance": b.Balance})
+		}
+		c.JSON(http.StatusOK, gin.H{"generated_at": time.Now().UTC(), "balances": result})
+		return
+	}
+
+	rows := [][]string{{"currency", "accounts", "balance"}}
+	for _, b := range balances {
+		rows = append(rows, []string{b.Currency, strconv.Itoa(b.Accounts), formatAmount(b.Balance)})
+	}
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="dormant-balances-%s.csv"`, time.Now().UTC().Format("20060102")))
+	c.Status(http.StatusOK)
+	w := csv.NewWriter(c.Writer)
+	if err := w.WriteAll(rows); err != nil {
+		log := logger.GetLogger()
+		log.Error().Err(err).Msg("Failed to write dormant balances csv")
+	}
+}
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/MMII0220/MiniBank/internal/controller/dto"
 	"github.com/MMII0220/MiniBank/internal/domain"
@@ -19,7 +20,7 @@ func (ctr *Controller) healthCheck(c *gin.Context) {
 
 // Redis health check
 func (ctr *Controller) redisHealth(c *gin.Context) {
-	if err := appredis.Ping(); err != nil {
+	if err := appredis.Ping(c.Request.Context()); err != nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{
 			"redis": "down",
 			"error": err.Error(),
@@ -55,7 +56,7 @@ func (ctr *Controller) depositHandler(c *gin.Context) {
 	}
 
 	domainReq := req.ToDomain()
-	err = ctr.service.Deposit(int(currentUser.ID), domainReq)
+	err = ctr.svc(c).Deposit(c.Request.Context(), int(currentUser.ID), domainReq)
 	if err != nil {
 		ctr.translateError(c, err)
 		return
@@ -81,7 +82,7 @@ func (ctr *Controller) withdrawHandler(c *gin.Context) {
 	}
 
 	domainReq := req.ToDomain()
-	err = ctr.service.Withdraw(int(currentUser.ID), domainReq)
+	err = ctr.svc(c).Withdraw(c.Request.Context(), int(currentUser.ID), domainReq)
 	if err != nil {
 		ctr.translateError(c, err)
 		return
@@ -111,19 +112,68 @@ func (ctr *Controller) transferHandler(c *gin.Context) {
 	}
 
 	domainReq := req.ToDomain()
-	err := ctr.service.Transfer(int(currentUser.ID), domainReq)
+	domainReq.SessionID = currentUser.SessionID
+	result, err := ctr.svc(c).Transfer(c.Request.Context(), int(currentUser.ID), domainReq)
 	if err != nil {
 		ctr.translateError(c, err)
 		return
 	}
 
+	// Крупный перевод не выполнен, а ждет одобрения - средства удержаны
+	if result.Status == domain.TransferPendingApproval {
+		c.JSON(http.StatusAccepted, gin.H{
+			"message":             "Transfer is pending approval",
+			"status":              result.Status,
+			"pending_transfer_id": result.PendingTransferID,
+		})
+		return
+	}
+
+	// Нужен код из SMS - перевод исполнится после /api/transfer/confirm
+	if result.Status == domain.TransferPendingConfirmation {
+		c.JSON(http.StatusAccepted, gin.H{
+			"message":         "Confirmation code sent",
+			"status":          result.Status,
+			"confirmation_id": result.ConfirmationID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Transfer successful"})
+}
+
+// Подтверждение перевода кодом из SMS
+func (ctr *Controller) confirmTransferHandler(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(domain.User)
+
+	var req dto.ReqTransferConfirmHTTP
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := ctr.svc(c).ConfirmTransfer(c.Request.Context(), currentUser.ID, req.ConfirmationID, req.Code, currentUser.SessionID)
+	if err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	if result.Status == domain.TransferPendingApproval {
+		c.JSON(http.StatusAccepted, gin.H{
+			"message":             "Transfer is pending approval",
+			"status":              result.Status,
+			"pending_transfer_id": result.PendingTransferID,
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "Transfer successful"})
 }
 
 func (ctr *Controller) historyLogs(c *gin.Context) {
 	currentUser := c.MustGet("currentUser").(domain.User)
 
-	transactions, err := ctr.service.HistoryLogs(currentUser.ID)
+	transactions, err := ctr.svc(c).HistoryLogs(c.Request.Context(), currentUser.ID)
 	if err != nil {
 		ctr.translateError(c, err)
 		return
@@ -135,7 +185,7 @@ func (ctr *Controller) historyLogs(c *gin.Context) {
 func (ctr *Controller) getAllAccountsHandler(c *gin.Context) {
 	currentUser := c.MustGet("currentUser").(domain.User)
 
-	accounts, err := ctr.service.GetAllAccounts(currentUser.ID)
+	accounts, err := ctr.svc(c).GetAllAccounts(c.Request.Context(), currentUser.ID)
 	if err != nil {
 		ctr.translateError(c, err)
 		return
@@ -147,3 +197,27 @@ func (ctr *Controller) getAllAccountsHandler(c *gin.Context) {
 		"total_count": len(accounts),
 	})
 }
+
+// Реактивация спящего счета владельцем: повторный ввод пароля и кода 2FA, если она включена
+func (ctr *Controller) reactivateAccountHandler(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(domain.User)
+
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || accountID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	var req dto.ReqAccountReactivationHTTP
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := ctr.svc(c).ReactivateAccount(c.Request.Context(), currentUser.ID, accountID, req.ToDomain()); err != nil {
+		ctr.translateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "account reactivated"})
+}
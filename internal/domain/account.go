@@ -2,15 +2,24 @@ package domain
 
 import "time"
 
+type AccountType string
+
+const (
+	AccountPersonal AccountType = "personal"
+	AccountBusiness AccountType = "business"
+)
+
 // Чистая доменная модель аккаунта
 type Account struct {
-	ID        int
-	UserID    int
-	Currency  string
-	Balance   string
-	Blocked   bool
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID         int
+	UserID     int
+	Currency   string
+	Balance    string
+	HoldAmount string // сумма, удержанная под переводы на одобрении
+	Blocked    bool
+	Type       AccountType
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
 }
 
 // ddd стурктура, прочитать
@@ -0,0 +1,66 @@
+package domain
+
+import "time"
+
+type AdjustmentDirection string
+
+const (
+	AdjustmentCredit AdjustmentDirection = "credit"
+	AdjustmentDebit  AdjustmentDirection = "debit"
+)
+
+func (d AdjustmentDirection) Valid() bool {
+	return d == AdjustmentCredit || d == AdjustmentDebit
+}
+
+// AdjustmentReason - обязательный код причины ручной корректировки
+type AdjustmentReason string
+
+const (
+	AdjustmentGoodwill        AdjustmentReason = "goodwill"
+	AdjustmentErrorCorrection AdjustmentReason = "error_correction"
+	AdjustmentFeeRefund       AdjustmentReason = "fee_refund"
+	AdjustmentChargeback      AdjustmentReason = "chargeback"
+	AdjustmentOther           AdjustmentReason = "other"
+)
+
+func (r AdjustmentReason) Valid() bool {
+	switch r {
+	case AdjustmentGoodwill, AdjustmentErrorCorrection, AdjustmentFeeRefund, AdjustmentChargeback, AdjustmentOther:
+		return true
+	}
+	return false
+}
+
+// ReqBalanceAdjustment - зачисление или списание админом, Amount всегда положительная
+type ReqBalanceAdjustment struct {
+	Direction  AdjustmentDirection
+	Amount     float64
+	ReasonCode AdjustmentReason
+	Note       string
+}
+
+// BalanceAdjustment - проведенная корректировка: транзакция adjustment и балансы до и после
+type BalanceAdjustment struct {
+	ID            int
+	AccountID     int
+	TransactionID int
+	AdminID       int
+	Direction     AdjustmentDirection
+	Amount        float64
+	Currency      string
+	AmountTJS     float64
+	ReasonCode    AdjustmentReason
+	Note          string
+	BalanceBefore float64
+	BalanceAfter  float64
+	CreatedAt     time.Time
+}
+
+// SignedAmount - сумма со знаком, как она проводится по счету
+func (a BalanceAdjustment) SignedAmount() float64 {
+	if a.Direction == AdjustmentDebit {
+		return -a.Amount
+	}
+	return a.Amount
+}
@@ -7,7 +7,27 @@ type AdminAuditLog struct {
 	ID        int
 	AccountID int
 	AdminID   int
+	// APIClientID - действие выполнил API клиент от имени AdminID, 0 - сотрудник лично
+	APIClientID int
+	Action      string
+	Reason      string
+	CreatedAt   time.Time
+}
+
+// AuditLogFilter - выборка из account_audit. Пустые поля не фильтруют.
+// Cursor - id записи, после которой продолжается выборка (в порядке сортировки), 0 - с начала.
+type AuditLogFilter struct {
+	AdminID   int
+	AccountID int
 	Action    string
-	Reason    string
-	CreatedAt time.Time
+	From      time.Time // включительно
+	To        time.Time // не включительно
+	Cursor    int
+	Limit     int
+	Ascending bool // по умолчанию сначала новые; хронология счета - от старых к новым
+}
+
+type AuditLogPage struct {
+	Logs       []AdminAuditLog
+	NextCursor int // 0 - записей больше нет
 }
@@ -0,0 +1,29 @@
+package domain
+
+// Поиск пользователей в админке: по части имени или email, по телефону или номеру карты
+type UserSearch struct {
+	Query  string
+	Limit  int
+	Offset int
+}
+
+// UserSearchFilter - подготовленные сервисом условия поиска, пустое условие не применяется
+type UserSearchFilter struct {
+	NamePattern  string // LIKE по LOWER(full_name)
+	EmailPattern string // LIKE по email
+	Phone        string // E.164
+	CardNumber   string
+	Limit        int
+	Offset       int
+}
+
+// Карточка клиента для сотрудника
+type UserDetail struct {
+	User               User
+	Accounts           []Account
+	Cards              []Card
+	Limit              Limit
+	TodayUsage         float64
+	RecentTransactions []Transaction
+	Sessions           []Session
+}
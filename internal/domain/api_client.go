@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Scope - что разрешено API клиенту; клиент действует от имени владельца и не больше его прав
+type Scope string
+
+const (
+	ScopeAccountsRead      Scope = "accounts:read"      // счета и история владельца
+	ScopeTransfersInitiate Scope = "transfers:initiate" // переводы со счетов владельца
+	ScopeAuditRead         Scope = "audit:read"         // журнал аудита
+)
+
+// scopePermissions - право владельца, без которого scope выдать нельзя
+var scopePermissions = map[Scope]Permission{
+	ScopeAccountsRead:      PermBankingUse,
+	ScopeTransfersInitiate: PermBankingUse,
+	ScopeAuditRead:         PermAuditRead,
+}
+
+// Valid - scope известен системе
+func (s Scope) Valid() bool {
+	_, ok := scopePermissions[s]
+	return ok
+}
+
+// Permission - право, которое scope требует от владельца клиента
+func (s Scope) Permission() Permission {
+	return scopePermissions[s]
+}
+
+// ParseScopes разбирает строку scope в формате OAuth2 ("a b c"), неизвестные значения возвращаются как есть
+func ParseScopes(s string) []Scope {
+	fields := strings.Fields(s)
+	scopes := make([]Scope, 0, len(fields))
+	for _, f := range fields {
+		scopes = append(scopes, Scope(f))
+	}
+	return scopes
+}
+
+// FormatScopes - обратное к ParseScopes
+func FormatScopes(scopes []Scope) string {
+	parts := make([]string, len(scopes))
+	for i, s := range scopes {
+		parts[i] = string(s)
+	}
+	return strings.Join(parts, " ")
+}
+
+// HasScope - есть ли scope в списке
+func HasScope(scopes []Scope, s Scope) bool {
+	for _, granted := range scopes {
+		if granted == s {
+			return true
+		}
+	}
+	return false
+}
+
+// APIClient - учетная запись сервисного скрипта (machine-to-machine)
+type APIClient struct {
+	ID                 int
+	ClientID           string
+	Name               string
+	SecretHash         string
+	OwnerUserID        int
+	Scopes             []Scope
+	RateLimitPerMinute int
+	CreatedBy          int
+	CreatedAt          time.Time
+	LastUsedAt         time.Time
+	RevokedAt          time.Time
+}
+
+func (c *APIClient) Revoked() bool {
+	return !c.RevokedAt.IsZero()
+}
+
+// Создание API клиента админом
+type ReqCreateAPIClient struct {
+	Name               string
+	OwnerUserID        int
+	Scopes             []Scope
+	RateLimitPerMinute int // 0 - значение по умолчанию
+}
+
+// Запрос токена по OAuth2 client credentials
+type ReqClientToken struct {
+	GrantType    string
+	ClientID     string
+	ClientSecret string
+	Scope        string // пусто - все scopes клиента
+}
+
+// Ответ token endpoint в формате OAuth2, refresh токен клиентам не выдается
+type ClientTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	Scope       string `json:"scope"`
+}
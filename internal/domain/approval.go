@@ -0,0 +1,50 @@
+package domain
+
+import "time"
+
+type PendingTransferStatus string
+
+const (
+	PendingTransferPending  PendingTransferStatus = "pending"
+	PendingTransferApproved PendingTransferStatus = "approved"
+	PendingTransferRejected PendingTransferStatus = "rejected"
+	PendingTransferExpired  PendingTransferStatus = "expired"
+)
+
+// Крупный перевод, ожидающий одобрения (maker-checker). Средства удержаны на счете отправителя.
+type PendingTransfer struct {
+	ID            int
+	FromAccountID int
+	ToAccountID   int
+	Amount        float64 // сумма к зачислению
+	Fee           float64 // комиссия за превышение лимита, списывается вместе с суммой
+	Currency      string
+	InitiatorID   int
+	Status        PendingTransferStatus
+	DecidedBy     int
+	DecisionNote  string
+	ExpiresAt     time.Time
+	CreatedAt     time.Time
+	DecidedAt     time.Time
+}
+
+// Total - сумма, удержанная на счете отправителя
+func (p *PendingTransfer) Total() float64 {
+	return p.Amount + p.Fee
+}
+
+type TransferStatus string
+
+const (
+	TransferCompleted           TransferStatus = "completed"
+	TransferPendingApproval     TransferStatus = "pending_approval"
+	TransferPendingConfirmation TransferStatus = "pending_confirmation"
+)
+
+// Результат операции перевода
+type TransferResult struct {
+	Status            TransferStatus
+	PendingTransferID int
+	ConfirmationID    int
+	Fee               float64
+}
@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+type ActorType string
+
+const (
+	ActorUser      ActorType = "user" // клиент или сотрудник
+	ActorAPIClient ActorType = "api_client"
+	ActorSystem    ActorType = "system" // фоновые задачи
+	ActorAnonymous ActorType = "anonymous"
+)
+
+type AuditOutcome string
+
+const (
+	AuditSuccess AuditOutcome = "success"
+	AuditFailure AuditOutcome = "failure"
+	AuditDenied  AuditOutcome = "denied"
+)
+
+// RequestMeta - данные HTTP запроса, которые попадают в каждое событие аудита
+type RequestMeta struct {
+	RequestID string
+	IP        string
+	UserAgent string
+	// Аутентифицированный субъект запроса, ActorID = 0 - запрос без токена
+	ActorID     int
+	ActorType   ActorType
+	APIClientID int
+}
+
+// RequestMetaFor - метаданные запроса от имени пользователя (или API клиента, действующего за него)
+func RequestMetaFor(user User) RequestMeta {
+	meta := RequestMeta{ActorID: user.ID, ActorType: ActorUser, APIClientID: user.APIClientID}
+	if user.IsAPIClient() {
+		meta.ActorType = ActorAPIClient
+	}
+	return meta
+}
+
+// AuditEvent - запись журнала audit_events. Before/After - JSON снимки состояния цели до и после действия.
+// Hash считается по всем полям и PrevHash, поэтому записи образуют цепочку.
+type AuditEvent struct {
+	ID          int64
+	OccurredAt  time.Time
+	ActorID     int
+	ActorType   ActorType
+	APIClientID int
+	Action      string
+	Outcome     AuditOutcome
+	TargetType  string
+	TargetID    string
+	Before      json.RawMessage
+	After       json.RawMessage
+	RequestID   string
+	IP          string
+	UserAgent   string
+	PrevHash    string
+	Hash        string
+}
+
+// ComputeHash - sha256 от канонического представления события вместе с PrevHash.
+// Время берется в UTC с точностью до микросекунд - так его хранит PostgreSQL.
+func (e AuditEvent) ComputeHash() string {
+	canonical, _ := json.Marshal([]string{
+		strconv.FormatInt(e.ID, 10),
+		e.OccurredAt.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano),
+		strconv.Itoa(e.ActorID),
+		string(e.ActorType),
+		strconv.Itoa(e.APIClientID),
+		e.Action,
+		string(e.Outcome),
+		e.TargetType,
+		e.TargetID,
+		string(e.Before),
+		string(e.After),
+		e.RequestID,
+		e.IP,
+		e.UserAgent,
+		e.PrevHash,
+	})
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditChainReport - результат проверки цепочки audit_events
+type AuditChainReport struct {
+	Checked  int
+	HeadID   int64
+	HeadHash string // последний hash, его стоит сохранять вне БД, чтобы заметить удаление хвоста
+	BrokenAt int64  // id первой записи, на которой цепочка не сошлась, 0 - цепочка цела
+	Problem  string
+}
+
+func (r AuditChainReport) Valid() bool {
+	return r.BrokenAt == 0
+}
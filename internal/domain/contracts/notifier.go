@@ -0,0 +1,8 @@
+package contracts
+
+import "github.com/MMII0220/MiniBank/internal/domain"
+
+// NotifierI доставляет уведомления пользователям (email, SMS)
+type NotifierI interface {
+	Send(msg domain.Notification) error
+}
@@ -1,29 +1,131 @@
 package contracts
 
 import (
+	"context"
+	"time"
+
 	"github.com/MMII0220/MiniBank/internal/domain"
 )
 
 type RepositoryI interface {
-	SetAccountBlock(accountID int, block bool, reqLogs domain.AdminAuditLog) error
-	GetAuditLogs() ([]domain.AdminAuditLog, error)
-
-	CreateCard(card *domain.Card) error
-
-	GetDailyLimitByUserID(userID int) (domain.Limit, error)
-	GetTodayUsageInTJS(userID int) (float64, error)
-	CreateDailyLimitForUser(userID int, dailyAmount float64) error
-	ResetDailyLimit(userID int) error
-
-	DepositToAccount(accountID int, amount float64) error
-	WithdrawFromAccount(accountID int, amount float64, currency string) error
-	TransferFunds(fromAccountID, toAccountID int, amount float64) error
-	GetAccountByCardNumber(account *domain.Account, cardNumber string, currency string) error
-	GetAccountByPhoneNumber(account *domain.Account, phoneNumber string, currency string) error
-	GetTransactionHistory(idUser int) ([]domain.Transaction, error)
-
-	CreateUser(user *domain.User) error
-	GetUserByEmail(email string) (*domain.User, error)
-	CreateAccount(account *domain.Account) error
-	GetAllAccountsByUserID(userID int) ([]domain.Account, error)
+	CreateAccountRestriction(ctx context.Context, restriction domain.AccountRestriction, reqLogs domain.AdminAuditLog) (domain.AccountRestriction, error)
+	LiftAccountRestrictions(ctx context.Context, accountID int, mode domain.RestrictionMode, liftedBy int, reqLogs domain.AdminAuditLog) (int, error)
+	GetActiveAccountRestrictions(ctx context.Context, accountID int) (domain.AccountRestrictions, error)
+	GetAccountRestrictions(ctx context.Context, accountID int) ([]domain.AccountRestriction, error)
+	MarkDormantAccounts(ctx context.Context, inactiveSince time.Time, reason string) ([]int, error)
+	GetDormantBalances(ctx context.Context) ([]domain.DormantBalance, error)
+
+	GetKYCTier(ctx context.Context, userID int) (domain.KYCTier, error)
+	SetKYCTier(ctx context.Context, userID int, tier domain.KYCTier, dailyAmount float64) error
+	CreateKYCDocument(ctx context.Context, doc *domain.KYCDocument) error
+	GetKYCDocument(ctx context.Context, docID int) (domain.KYCDocument, error)
+	GetKYCDocuments(ctx context.Context, userID int) ([]domain.KYCDocument, error)
+	GetKYCReviewQueue(ctx context.Context, status domain.KYCDocumentStatus) ([]domain.KYCDocument, error)
+	ReviewKYCDocument(ctx context.Context, docID int, status domain.KYCDocumentStatus, adminID int, note string) error
+	GetAuditLogs(ctx context.Context, filter domain.AuditLogFilter) ([]domain.AdminAuditLog, error)
+
+	CreateCard(ctx context.Context, card *domain.Card) error
+
+	GetDailyLimitByUserID(ctx context.Context, userID int) (domain.Limit, error)
+	GetTodayUsageInTJS(ctx context.Context, userID int) (float64, error)
+	CreateDailyLimitForUser(ctx context.Context, userID int, dailyAmount float64) error
+	ResetDailyLimit(ctx context.Context, userID int) error
+
+	DepositToAccount(ctx context.Context, accountID int, amount float64) error
+	WithdrawFromAccount(ctx context.Context, accountID int, amount, fee float64, currency string) error
+	TransferFunds(ctx context.Context, fromAccountID, toAccountID int, amount, fee float64) error
+	GetAccountByCardNumber(ctx context.Context, account *domain.Account, cardNumber string, currency string) error
+	GetAccountByPhoneNumber(ctx context.Context, account *domain.Account, phoneNumber string, currency string) error
+	GetTransactionHistory(ctx context.Context, idUser int) ([]domain.Transaction, error)
+
+	CreateUser(ctx context.Context, user *domain.User) error
+	GetUserByEmail(ctx context.Context, email string) (*domain.User, error)
+	GetUserByID(ctx context.Context, userID int) (*domain.User, error)
+	CreateAccount(ctx context.Context, account *domain.Account) error
+	GetAllAccountsByUserID(ctx context.Context, userID int) ([]domain.Account, error)
+	GetAccountByID(ctx context.Context, accountID int) (domain.Account, error)
+
+	CreateScreeningReview(ctx context.Context, review *domain.ScreeningReview) error
+	GetLatestScreeningReview(ctx context.Context, subjectType domain.ScreeningSubjectType, subjectRef string) (domain.ScreeningReview, error)
+	GetScreeningReviews(ctx context.Context, status domain.ScreeningStatus) ([]domain.ScreeningReview, error)
+	ResolveScreeningReview(ctx context.Context, reviewID int, status domain.ScreeningStatus, adminID int, note string) error
+
+	CreatePendingTransfer(ctx context.Context, pt *domain.PendingTransfer) error
+	GetPendingTransferByID(ctx context.Context, id int) (domain.PendingTransfer, error)
+	GetPendingTransfers(ctx context.Context, status domain.PendingTransferStatus) ([]domain.PendingTransfer, error)
+	GetPendingTransfersForSignatory(ctx context.Context, userID int) ([]domain.PendingTransfer, error)
+	GetExpiredPendingTransfers(ctx context.Context) ([]domain.PendingTransfer, error)
+	ApprovePendingTransfer(ctx context.Context, pt domain.PendingTransfer, approverID int, note string, reqLogs domain.AdminAuditLog) error
+	ReleasePendingTransfer(ctx context.Context, pt domain.PendingTransfer, status domain.PendingTransferStatus, deciderID int, note string, reqLogs domain.AdminAuditLog) error
+	IsAccountSignatory(ctx context.Context, accountID, userID int) (bool, error)
+	AddAccountSignatory(ctx context.Context, accountID, userID int) error
+
+	CreatePendingAction(ctx context.Context, action *domain.PendingAction) error
+	GetPendingActionByID(ctx context.Context, id int) (domain.PendingAction, error)
+	GetPendingActions(ctx context.Context, status domain.PendingActionStatus) ([]domain.PendingAction, error)
+	GetExpiredPendingActions(ctx context.Context) ([]domain.PendingAction, error)
+	ExecutePendingAction(ctx context.Context, action domain.PendingAction, approverID int, note string, reqLogs domain.AdminAuditLog) error
+	ExecutePendingAdjustment(ctx context.Context, action domain.PendingAction, adj domain.BalanceAdjustment, approverID int, note string, reqLogs domain.AdminAuditLog) (domain.BalanceAdjustment, error)
+	ClosePendingAction(ctx context.Context, action domain.PendingAction, status domain.PendingActionStatus, deciderID int, note string, reqLogs domain.AdminAuditLog) error
+
+	CreateSession(ctx context.Context, session *domain.Session, tokenHash string) error
+	GetSession(ctx context.Context, sessionID string) (domain.Session, error)
+	GetRefreshToken(ctx context.Context, tokenHash string) (domain.RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, oldTokenID int, sessionID string, newTokenHash string, expiresAt time.Time) error
+	RevokeSession(ctx context.Context, sessionID string, reason string) error
+	RevokeUserSessions(ctx context.Context, userID int, reason string) ([]string, error)
+	GetActiveSessions(ctx context.Context, userID int) ([]domain.Session, error)
+	GetUserDeviceIDs(ctx context.Context, userID int) ([]string, error)
+	SetSessionStepUpCode(ctx context.Context, sessionID string, codeHash string, expiresAt time.Time) error
+	CompleteSessionStepUp(ctx context.Context, sessionID string) error
+	RecordLoginAttempt(ctx context.Context, attempt domain.LoginAttempt) error
+	GetLoginFailureStats(ctx context.Context, email, ip string, since time.Time) (domain.LoginFailureStats, error)
+	ClearLoginFailures(ctx context.Context, email string) error
+	GetLoginLock(ctx context.Context, email string) (domain.LoginLock, error)
+	GetActiveLoginLocks(ctx context.Context) ([]domain.LoginLock, error)
+	LockLogin(ctx context.Context, lock domain.LoginLock, reqLogs domain.AdminAuditLog) error
+	UnlockLogin(ctx context.Context, email string, reqLogs domain.AdminAuditLog) error
+	MarkSessionMFAVerified(ctx context.Context, sessionID string) error
+	CreateStaffInvite(ctx context.Context, invite *domain.StaffInvite, reqLogs domain.AdminAuditLog) error
+	GetStaffInviteByTokenHash(ctx context.Context, tokenHash string) (domain.StaffInvite, error)
+	ClaimStaffInvite(ctx context.Context, inviteID int) error
+	CompleteStaffInvite(ctx context.Context, inviteID int, userID int) error
+	CountUsersByRole(ctx context.Context, role domain.Role) (int, error)
+	CreatePasswordResetToken(ctx context.Context, token *domain.PasswordResetToken) error
+	GetPasswordResetToken(ctx context.Context, tokenHash string) (domain.PasswordResetToken, error)
+	ResetPassword(ctx context.Context, tokenID int, userID int, passwordHash string) error
+	UpdatePassword(ctx context.Context, userID int, passwordHash string) error
+	CreateContactVerification(ctx context.Context, v *domain.ContactVerification) error
+	GetPendingContactVerification(ctx context.Context, userID int, channel domain.VerificationChannel) (domain.ContactVerification, error)
+	RecordContactVerificationAttempt(ctx context.Context, id int, maxAttempts int) (int, error)
+	CompleteContactVerification(ctx context.Context, v domain.ContactVerification) error
+	GetMFAConfig(ctx context.Context, userID int) (domain.MFAConfig, error)
+	SaveMFASecret(ctx context.Context, userID int, secret string) error
+	EnableMFA(ctx context.Context, userID int, step int64, recoveryCodeHashes []string) error
+	UseMFAStep(ctx context.Context, userID int, step int64) error
+	UseRecoveryCode(ctx context.Context, userID int, codeHash string) error
+	ReplaceRecoveryCodes(ctx context.Context, userID int, recoveryCodeHashes []string) error
+	DisableMFA(ctx context.Context, userID int) error
+	CreateTransferConfirmation(ctx context.Context, c *domain.TransferConfirmation) error
+	GetTransferConfirmation(ctx context.Context, id int) (domain.TransferConfirmation, error)
+	RecordTransferConfirmationAttempt(ctx context.Context, id int, maxAttempts int) (int, error)
+	CloseTransferConfirmation(ctx context.Context, id int, status domain.TransferConfirmationStatus) error
+	IsKnownRecipient(ctx context.Context, userID, accountID int) (bool, error)
+	AddKnownRecipient(ctx context.Context, userID, accountID int) error
+	CreateAPIClient(ctx context.Context, client *domain.APIClient, reqLogs domain.AdminAuditLog) error
+	GetAPIClientByClientID(ctx context.Context, clientID string) (domain.APIClient, error)
+	GetAPIClientByID(ctx context.Context, id int) (domain.APIClient, error)
+	GetAPIClients(ctx context.Context) ([]domain.APIClient, error)
+	RevokeAPIClient(ctx context.Context, id int, reqLogs domain.AdminAuditLog) error
+	TouchAPIClient(ctx context.Context, id int) error
+	CreateAuditLog(ctx context.Context, reqLogs domain.AdminAuditLog) error
+	UpdateUserProfile(ctx context.Context, update domain.ProfileUpdate) error
+	GetProfileChanges(ctx context.Context, userID int) ([]domain.ProfileChange, error)
+	GetCardsByUserID(ctx context.Context, userID int) ([]domain.Card, error)
+	SearchUsers(ctx context.Context, filter domain.UserSearchFilter) ([]domain.User, error)
+	SetUserDisabled(ctx context.Context, userID int, disabled bool, reqLogs domain.AdminAuditLog) error
+	AppendAuditEvent(ctx context.Context, event domain.AuditEvent) (domain.AuditEvent, error)
+	GetAuditEventsAfter(ctx context.Context, afterID int64, limit int) ([]domain.AuditEvent, error)
+	GetAdminStats(ctx context.Context, from, to time.Time, topBreaches int) (domain.AdminStats, error)
+	AdjustBalance(ctx context.Context, adj domain.BalanceAdjustment, ceilingTJS float64, reqLogs domain.AdminAuditLog) (domain.BalanceAdjustment, error)
 }
@@ -0,0 +1,8 @@
+package contracts
+
+import "github.com/MMII0220/MiniBank/internal/domain"
+
+// ScreenerI проверяет имена по санкционным спискам
+type ScreenerI interface {
+	Screen(name string) []domain.ScreeningMatch
+}
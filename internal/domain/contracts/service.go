@@ -1,30 +1,101 @@
 package contracts
 
 import (
+	"context"
+	"io"
 	"time"
 
 	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/jwtkeys"
 )
 
 type ServiceI interface {
-	BlockUnblockAccount(accountID int, block bool, adminID int, reason string) error
-	AuditLogs() ([]domain.AdminAuditLog, error)
+	// WithRequest - сервис в рамках одного HTTP запроса: его метаданные попадают в события аудита
+	WithRequest(meta domain.RequestMeta) ServiceI
+	VerifyAuditChain(ctx context.Context) (domain.AuditChainReport, error)
 
-	Register(req domain.ReqRegister, role domain.Role) (domain.User, error)
-	Login(req domain.ReqLogin) (domain.TokenResponse, error)
-	RefreshToken(req domain.ReqRefreshToken) (domain.TokenResponse, error)
-	ParseToken(tokenStr string) (domain.User, error)
+	RestrictAccount(ctx context.Context, adminID, accountID int, req domain.ReqAdminAccountAction) (domain.AccountRestriction, error)
+	AccountRestrictions(ctx context.Context, accountID int) ([]domain.AccountRestriction, error)
+	MarkDormantAccounts(ctx context.Context) (int, error)
+	ReactivateAccount(ctx context.Context, userID, accountID int, req domain.ReqAccountReactivation) error
+	DormantBalances(ctx context.Context) ([]domain.DormantBalance, error)
+	AdjustBalance(ctx context.Context, adminID, accountID int, req domain.ReqBalanceAdjustment) (domain.BalanceAdjustment, error)
+	SubmitKYCDocument(ctx context.Context, userID int, req domain.ReqKYCDocument) (domain.KYCDocument, error)
+	KYCStatus(ctx context.Context, userID int) (domain.KYCStatus, error)
+	KYCReviewQueue(ctx context.Context, status domain.KYCDocumentStatus) ([]domain.KYCDocument, error)
+	OpenKYCDocument(ctx context.Context, docID int) (domain.KYCDocument, io.ReadCloser, error)
+	ReviewKYCDocument(ctx context.Context, docID, adminID int, approve bool, note string) (domain.KYCTier, error)
+	AuditLogs(ctx context.Context, filter domain.AuditLogFilter) (domain.AuditLogPage, error)
+	ExportAuditLogs(ctx context.Context, filter domain.AuditLogFilter, write func(domain.AdminAuditLog) error) error
+	AccountAuditTimeline(ctx context.Context, accountID int, filter domain.AuditLogFilter) (domain.AuditLogPage, error)
+	AdminStats(ctx context.Context, from, to time.Time) (domain.AdminStats, error)
 
-	CreateCardForAccount(accountID int, holderName string) (*domain.Card, error)
+	Register(ctx context.Context, req domain.ReqRegister, role domain.Role) (domain.User, error)
+	Login(ctx context.Context, req domain.ReqLogin) (domain.TokenResponse, error)
+	RefreshToken(ctx context.Context, req domain.ReqRefreshToken) (domain.TokenResponse, error)
+	ParseToken(ctx context.Context, tokenStr string) (domain.User, error)
+	JWKS() jwtkeys.JWKS
+	Logout(ctx context.Context, userID int, sessionID string) error
+	LogoutAll(ctx context.Context, userID int) error
+	ListSessions(ctx context.Context, userID int) ([]domain.Session, error)
+	TerminateSession(ctx context.Context, userID int, sessionID string) error
+	SendStepUpCode(ctx context.Context, userID int, sessionID string) error
+	CompleteStepUp(ctx context.Context, userID int, sessionID string, code string, ip string) error
+	LoginLocks(ctx context.Context) ([]domain.LoginLock, error)
+	UnlockLogin(ctx context.Context, userID int, adminID int, reason string) error
+	InviteStaff(ctx context.Context, email string, role domain.Role, adminID int) (domain.StaffInvite, error)
+	AcceptInvite(ctx context.Context, req domain.ReqAcceptInvite) (domain.User, error)
+	BootstrapAdmin(ctx context.Context, req domain.ReqRegister) (bool, error)
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, req domain.ReqPasswordReset) error
+	ChangePassword(ctx context.Context, userID int, req domain.ReqPasswordChange) error
+	Profile(ctx context.Context, userID int) (domain.Profile, error)
+	UpdateProfile(ctx context.Context, userID int, req domain.ReqUpdateProfile) (domain.User, error)
+	ProfileChanges(ctx context.Context, userID int) ([]domain.ProfileChange, error)
+	SendVerificationCode(ctx context.Context, userID int, channel domain.VerificationChannel) error
+	VerifyContact(ctx context.Context, userID int, channel domain.VerificationChannel, code string) error
+	VerifyMFA(ctx context.Context, req domain.ReqMFAVerify) (domain.TokenResponse, error)
+	EnrollMFA(ctx context.Context, userID int) (domain.MFAEnrollment, error)
+	ConfirmMFA(ctx context.Context, userID int, sessionID string, code string) ([]string, error)
+	RegenerateRecoveryCodes(ctx context.Context, userID int, code string) ([]string, error)
+	DisableMFA(ctx context.Context, userID int, code string) error
+	ConfirmTransfer(ctx context.Context, userID int, confirmationID int, code string, sessionID string) (domain.TransferResult, error)
+
+	CreateCardForAccount(ctx context.Context, accountID int, holderName string) (*domain.Card, error)
 
 	ConvertToBaseCurrency(amount float64, currency string) (float64, error)
-	CheckLimitAndCalculateFee(userID int, amount float64, currency string) (float64, error)
+	CheckLimitAndCalculateFee(ctx context.Context, userID int, amount float64, currency string) (float64, error)
 	CalculateOverlimitFee(amount float64 /*, currency string*/) float64
 	IsNewDay(lastReset time.Time) bool
 
-	Deposit(currentUserID int, req domain.ReqTransaction) error
-	Withdraw(currentUserID int, req domain.ReqTransaction) error
-	Transfer(currentUserID int, req domain.ReqTransfer) error
-	HistoryLogs(idUser int) ([]domain.Transaction, error)
-	GetAllAccounts(userID int) ([]domain.Account, error)
+	Deposit(ctx context.Context, currentUserID int, req domain.ReqTransaction) error
+	Withdraw(ctx context.Context, currentUserID int, req domain.ReqTransaction) error
+	Transfer(ctx context.Context, currentUserID int, req domain.ReqTransfer) (domain.TransferResult, error)
+	HistoryLogs(ctx context.Context, idUser int) ([]domain.Transaction, error)
+	GetAllAccounts(ctx context.Context, userID int) ([]domain.Account, error)
+
+	ScreeningReviews(ctx context.Context, status domain.ScreeningStatus) ([]domain.ScreeningReview, error)
+	ResolveScreeningReview(ctx context.Context, reviewID int, adminID int, clear bool, note string) error
+
+	PendingTransfers(ctx context.Context, status domain.PendingTransferStatus) ([]domain.PendingTransfer, error)
+	PendingTransfersForApprover(ctx context.Context, userID int) ([]domain.PendingTransfer, error)
+	DecidePendingTransfer(ctx context.Context, pendingID int, approver domain.User, approve bool, note string) error
+	ExpirePendingTransfers(ctx context.Context) (int, error)
+	AddAccountSignatory(ctx context.Context, accountID, userID int) error
+
+	ProposeAdminAction(ctx context.Context, action domain.PendingAction, proposerID int) (domain.PendingAction, error)
+	PendingActions(ctx context.Context, status domain.PendingActionStatus) ([]domain.PendingAction, error)
+	DecidePendingAction(ctx context.Context, actionID int, approverID int, approve bool, note string) error
+	ExpirePendingActions(ctx context.Context) (int, error)
+
+	CreateAPIClient(ctx context.Context, req domain.ReqCreateAPIClient, adminID int) (domain.APIClient, string, error)
+	APIClients(ctx context.Context) ([]domain.APIClient, error)
+	RevokeAPIClient(ctx context.Context, id int, adminID int) error
+	IssueClientToken(ctx context.Context, req domain.ReqClientToken) (domain.ClientTokenResponse, error)
+	RecordClientRequest(ctx context.Context, user domain.User, scope domain.Scope, request string)
+
+	SearchUsers(ctx context.Context, adminID int, search domain.UserSearch) ([]domain.User, error)
+	UserDetail(ctx context.Context, adminID int, userID int) (domain.UserDetail, error)
+	UserHistory(ctx context.Context, adminID int, userID int) ([]domain.Transaction, error)
+	SetUserDisabled(ctx context.Context, adminID int, userID int, disable bool, reason string) error
 }
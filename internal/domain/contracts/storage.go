@@ -0,0 +1,10 @@
+package contracts
+
+import "io"
+
+// DocumentStoreI хранит файлы документов клиентов (локальный диск или S3-совместимое хранилище)
+type DocumentStoreI interface {
+	Put(key string, body io.Reader, contentType string) error
+	Open(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
@@ -1,5 +1,7 @@
 package domain
 
+import "time"
+
 // Business DTOs - описывают бизнес-операции и контракты
 
 // Operation for transaction requests
@@ -17,6 +19,8 @@ type ReqTransfer struct {
 	FromPhoneNumber string
 	Amount          float64
 	Currency        string
+	SessionID       string // сессия, из которой инициирован перевод (проверка step-up)
+	OTPConfirmed    bool   // перевод уже подтвержден кодом из SMS
 }
 
 // For user registration/login requests
@@ -25,12 +29,14 @@ type ReqRegister struct {
 	Phone    string
 	Email    string
 	Password string
-	Role     string // optional, default "user"
 }
 
 type ReqLogin struct {
-	Email    string
-	Password string
+	Email     string
+	Password  string
+	DeviceID  string
+	UserAgent string
+	IP        string
 }
 
 // JWT response with access and refresh tokens
@@ -39,6 +45,11 @@ type TokenResponse struct {
 	RefreshToken string `json:"refresh_token"`
 	ExpiresIn    int64  `json:"expires_in"` // seconds until access token expires
 	TokenType    string `json:"token_type"` // "Bearer"
+	// Включена 2FA: вместо пары токенов выдается challenge токен для /auth/mfa/verify
+	MFARequired bool   `json:"mfa_required,omitempty"`
+	MFAToken    string `json:"mfa_token,omitempty"`
+	// Токен устройства: клиент хранит его и присылает при следующих входах (cookie или X-Device-ID)
+	DeviceToken string `json:"device_token,omitempty"`
 }
 
 // Refresh token request
@@ -47,7 +58,13 @@ type ReqRefreshToken struct {
 }
 
 // For admin account block/unblock requests
+// ReqAdminAccountAction - установка (Block) или снятие ограничения счета.
+// Mode по умолчанию full_block, Amount - только для legal_hold, пустой ExpiresAt - бессрочно.
 type ReqAdminAccountAction struct {
-	Block  bool
-	Reason string
+	Block     bool
+	Mode      RestrictionMode
+	Amount    float64
+	Category  RestrictionCategory
+	Reason    string
+	ExpiresAt time.Time
 }
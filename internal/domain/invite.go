@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// Приглашение сотрудника с ролью выше клиента
+type StaffInvite struct {
+	ID             int
+	Email          string
+	Role           Role
+	TokenHash      string
+	InvitedBy      int
+	ExpiresAt      time.Time
+	AcceptedAt     time.Time
+	AcceptedUserID int
+	CreatedAt      time.Time
+}
+
+// Регистрация сотрудника по приглашению, email и роль берутся из приглашения
+type ReqAcceptInvite struct {
+	Token    string
+	FullName string
+	Phone    string
+	Password string
+}
@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"io"
+	"time"
+)
+
+// KYCTier - уровень проверки личности клиента
+type KYCTier string
+
+const (
+	KYCUnverified KYCTier = "unverified" // сразу после регистрации
+	KYCBasic      KYCTier = "basic"      // проверено удостоверение личности
+	KYCFull       KYCTier = "full"       // удостоверение и подтверждение адреса
+)
+
+// LimitPolicy - лимиты уровня KYC в TJS
+type LimitPolicy struct {
+	DailyAmount float64 // дневной лимит без комиссии, сверх него берется комиссия за превышение
+	MaxDaily    float64 // жесткий потолок списаний за день, 0 - без потолка
+}
+
+var kycPolicies = map[KYCTier]LimitPolicy{
+	KYCUnverified: {DailyAmount: 1000, MaxDaily: 3000},
+	KYCBasic:      {DailyAmount: 5000, MaxDaily: 30000},
+	KYCFull:       {DailyAmount: 20000},
+}
+
+var kycRanks = map[KYCTier]int{KYCUnverified: 0, KYCBasic: 1, KYCFull: 2}
+
+func (t KYCTier) Valid() bool {
+	_, ok := kycRanks[t]
+	return ok
+}
+
+// Policy - лимиты уровня; неизвестный уровень считается unverified
+func (t KYCTier) Policy() LimitPolicy {
+	if policy, ok := kycPolicies[t]; ok {
+		return policy
+	}
+	return kycPolicies[KYCUnverified]
+}
+
+// Above - уровень выше other
+func (t KYCTier) Above(other KYCTier) bool {
+	return kycRanks[t] > kycRanks[other]
+}
+
+// KYCDocumentType - вид документа
+type KYCDocumentType string
+
+const (
+	KYCPassport       KYCDocumentType = "passport"
+	KYCNationalID     KYCDocumentType = "national_id"
+	KYCProofOfAddress KYCDocumentType = "proof_of_address" // счет за коммунальные услуги, выписка банка
+)
+
+func (t KYCDocumentType) Valid() bool {
+	switch t {
+	case KYCPassport, KYCNationalID, KYCProofOfAddress:
+		return true
+	}
+	return false
+}
+
+// KYCDocumentStatus - статус документа в очереди проверки
+type KYCDocumentStatus string
+
+const (
+	KYCDocumentPending  KYCDocumentStatus = "pending"
+	KYCDocumentApproved KYCDocumentStatus = "approved"
+	KYCDocumentRejected KYCDocumentStatus = "rejected"
+)
+
+// KYCDocument - загруженный клиентом документ; файл хранится отдельно под StorageKey
+type KYCDocument struct {
+	ID          int
+	UserID      int
+	Type        KYCDocumentType
+	StorageKey  string
+	FileName    string
+	ContentType string
+	Size        int64
+	SHA256      string
+	Status      KYCDocumentStatus
+	ReviewedBy  int
+	ReviewNote  string
+	ReviewedAt  time.Time
+	CreatedAt   time.Time
+}
+
+// KYCTierFor - уровень, который дают одобренные документы
+func KYCTierFor(docs []KYCDocument) KYCTier {
+	var identity, address bool
+	for _, d := range docs {
+		if d.Status != KYCDocumentApproved {
+			continue
+		}
+		switch d.Type {
+		case KYCPassport, KYCNationalID:
+			identity = true
+		case KYCProofOfAddress:
+			address = true
+		}
+	}
+	switch {
+	case identity && address:
+		return KYCFull
+	case identity:
+		return KYCBasic
+	}
+	return KYCUnverified
+}
+
+// ReqKYCDocument - загрузка документа клиентом
+type ReqKYCDocument struct {
+	Type     KYCDocumentType
+	FileName string
+	Body     io.Reader
+}
+
+// KYCStatus - уровень клиента, его лимиты и загруженные документы
+type KYCStatus struct {
+	Tier      KYCTier
+	Policy    LimitPolicy
+	Documents []KYCDocument
+}
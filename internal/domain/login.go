@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+// Попытка входа (успешная или нет)
+type LoginAttempt struct {
+	ID        int
+	Email     string
+	IP        string
+	UserID    int // 0 - пользователь с таким email не найден
+	Success   bool
+	CreatedAt time.Time
+}
+
+// Неудачные попытки входа за окно наблюдения
+type LoginFailureStats struct {
+	EmailFailures int
+	IPFailures    int
+	LastFailureAt time.Time
+}
+
+// Временная блокировка входа по email
+type LoginLock struct {
+	Email       string
+	UserID      int
+	FailedCount int
+	LockedUntil time.Time
+	CreatedAt   time.Time
+}
+
+// Active - блокировка еще действует
+func (l *LoginLock) Active() bool {
+	return l.LockedUntil.After(time.Now())
+}
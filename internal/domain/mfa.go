@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// Настройки TOTP пользователя
+type MFAConfig struct {
+	UserID       int
+	Secret       string
+	Enabled      bool
+	LastUsedStep int64 // последний принятый 30-секундный интервал, коды не старше него отклоняются
+	CreatedAt    time.Time
+	EnabledAt    time.Time
+}
+
+// Данные для подключения приложения-аутентификатора
+type MFAEnrollment struct {
+	Secret          string
+	ProvisioningURI string
+}
+
+// Второй шаг входа: challenge токен из Login и код из приложения или код восстановления
+type ReqMFAVerify struct {
+	MFAToken  string
+	Code      string
+	DeviceID  string
+	UserAgent string
+	IP        string
+}
@@ -0,0 +1,17 @@
+package domain
+
+type NotificationChannel string
+
+const (
+	ChannelEmail NotificationChannel = "email"
+	ChannelSMS   NotificationChannel = "sms"
+)
+
+// Уведомление пользователю
+type Notification struct {
+	UserID  int
+	Channel NotificationChannel
+	To      string
+	Subject string
+	Body    string
+}
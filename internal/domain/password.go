@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// Токен восстановления пароля, хранится только хеш
+type PasswordResetToken struct {
+	ID        int
+	UserID    int
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    time.Time
+	CreatedAt time.Time
+}
+
+type ReqPasswordReset struct {
+	Token       string
+	NewPassword string
+}
+
+type ReqPasswordChange struct {
+	CurrentPassword string
+	NewPassword     string
+}
@@ -0,0 +1,59 @@
+package domain
+
+import "time"
+
+type PendingActionType string
+
+const (
+	ActionUnblock          PendingActionType = "unblock"
+	ActionLimitRaise       PendingActionType = "limit_raise"
+	ActionManualAdjustment PendingActionType = "manual_adjustment"
+	ActionRoleChange       PendingActionType = "role_change"
+)
+
+// ProposePermission - право, нужное чтобы предложить действие; снятие ограничения счета может предложить поддержка,
+// остальное только админ
+func (t PendingActionType) ProposePermission() Permission {
+	switch t {
+	case ActionUnblock:
+		return PermAdminActionsPropose
+	case ActionRoleChange:
+		return PermUsersInvite
+	default:
+		return PermAccountsManage
+	}
+}
+
+type PendingActionStatus string
+
+const (
+	PendingActionPending  PendingActionStatus = "pending"
+	PendingActionApproved PendingActionStatus = "approved"
+	PendingActionRejected PendingActionStatus = "rejected"
+	PendingActionExpired  PendingActionStatus = "expired"
+)
+
+// Чувствительное админское действие, ожидающее одобрения вторым админом (four-eyes).
+// Какие поля заполнены, зависит от типа:
+//   - unblock: AccountID, RestrictionMode (какое ограничение снять; пустой - full_block)
+//   - limit_raise: UserID, Amount (новый дневной лимит в TJS)
+//   - manual_adjustment: AccountID, Amount (положительная - зачисление, отрицательная - списание), ReasonCode
+//   - role_change: UserID, NewRole
+type PendingAction struct {
+	ID              int
+	Type            PendingActionType
+	AccountID       int
+	UserID          int
+	Amount          float64
+	NewRole         Role
+	ReasonCode      AdjustmentReason
+	RestrictionMode RestrictionMode
+	Reason          string
+	ProposedBy      int
+	Status          PendingActionStatus
+	DecidedBy       int
+	DecisionNote    string
+	ExpiresAt       time.Time
+	CreatedAt       time.Time
+	DecidedAt       time.Time
+}
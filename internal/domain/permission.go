@@ -0,0 +1,76 @@
+package domain
+
+// Permission - право на действие, роли проверяются только через матрицу прав
+type Permission string
+
+const (
+	PermBankingUse          Permission = "banking:use"           // свои счета: пополнение, переводы, история
+	PermAccountsBlock       Permission = "accounts:block"        // блокировка счета
+	PermAccountsManage      Permission = "accounts:manage"       // подписанты бизнес-счетов
+	PermAuditRead           Permission = "audit:read"            // журнал аудита
+	PermScreeningRead       Permission = "screening:read"        // очередь санкционных проверок
+	PermScreeningReview     Permission = "screening:review"      // решение по санкционной проверке
+	PermApprovalsRead       Permission = "approvals:read"        // переводы на одобрении
+	PermApprovalsDecide     Permission = "approvals:decide"      // одобрение крупных переводов
+	PermAdminActionsRead    Permission = "admin_actions:read"    // очередь действий четырех глаз
+	PermAdminActionsPropose Permission = "admin_actions:propose" // предложить разблокировку, лимит, корректировку, смену роли
+	PermAdminActionsDecide  Permission = "admin_actions:decide"  // одобрить действие другого сотрудника
+	PermUsersUnlock         Permission = "users:unlock"          // снять блокировку входа
+	PermUsersInvite         Permission = "users:invite"          // пригласить сотрудника
+	PermAPIClientsManage    Permission = "api_clients:manage"    // API клиенты сервисных скриптов
+	PermUsersRead           Permission = "users:read"            // поиск клиентов и карточка клиента (чтение пишется в аудит)
+	PermUsersDisable        Permission = "users:disable"         // отключить или включить пользователя
+	PermStatsRead           Permission = "stats:read"            // операционная панель: обороты, комиссии, превышения лимита
+	PermKYCRead             Permission = "kyc:read"              // очередь документов KYC и просмотр файлов (пишется в аудит)
+	PermKYCReview           Permission = "kyc:review"            // одобрение и отклонение документов KYC
+)
+
+// rolePermissions - матрица прав. У админа есть все права, включая обычное банковское обслуживание.
+var rolePermissions = map[Role][]Permission{
+	RoleUser: {PermBankingUse},
+	RoleTeller: {
+		PermAccountsBlock, PermUsersRead,
+		PermAdminActionsRead, PermAdminActionsPropose,
+	},
+	RoleSupport: {
+		PermAccountsBlock, PermUsersUnlock, PermUsersRead, PermUsersDisable,
+		PermAdminActionsRead, PermAdminActionsPropose, PermKYCRead, PermKYCReview,
+	},
+	RoleAuditor: {
+		PermAuditRead, PermScreeningRead, PermApprovalsRead, PermAdminActionsRead, PermUsersRead, PermStatsRead,
+		PermKYCRead,
+	},
+	RoleAdmin: {
+		PermBankingUse, PermAccountsBlock, PermAccountsManage, PermAuditRead,
+		PermScreeningRead, PermScreeningReview, PermApprovalsRead, PermApprovalsDecide,
+		PermAdminActionsRead, PermAdminActionsPropose, PermAdminActionsDecide,
+		PermUsersUnlock, PermUsersInvite, PermAPIClientsManage, PermUsersRead, PermUsersDisable, PermStatsRead,
+		PermKYCRead, PermKYCReview,
+	},
+}
+
+// Can - есть ли у роли право
+func (r Role) Can(p Permission) bool {
+	for _, granted := range rolePermissions[r] {
+		if granted == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Valid - роль известна системе
+func (r Role) Valid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// IsStaff - сотрудник банка (любая роль кроме клиента), для них обязательна 2FA
+func (r Role) IsStaff() bool {
+	return r.Valid() && r != RoleUser
+}
+
+// Permissions - права роли
+func (r Role) Permissions() []Permission {
+	return append([]Permission(nil), rolePermissions[r]...)
+}
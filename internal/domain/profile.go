@@ -0,0 +1,51 @@
+package domain
+
+import "time"
+
+// ProfileField - изменяемое поле профиля, пишется в историю изменений
+type ProfileField string
+
+const (
+	ProfileFullName       ProfileField = "full_name"
+	ProfileEmail          ProfileField = "email"
+	ProfilePhone          ProfileField = "phone"
+	ProfileCardHolderName ProfileField = "card_holder_name"
+)
+
+// Запись истории изменений профиля
+type ProfileChange struct {
+	ID        int
+	UserID    int
+	Field     ProfileField
+	OldValue  string
+	NewValue  string
+	ChangedBy int
+	CreatedAt time.Time
+}
+
+// Профиль со сводкой по счетам и картам
+type Profile struct {
+	User     User
+	Accounts []Account
+	Cards    []Card
+}
+
+// Изменение профиля пользователем, nil - поле не меняется.
+// Смена email или телефона требует текущий пароль и повторного подтверждения.
+type ReqUpdateProfile struct {
+	FullName        *string
+	Email           *string
+	Phone           *string
+	CurrentPassword string
+	ReissueCards    bool // перевыпустить карты на новое имя держателя
+}
+
+// ProfileUpdate - итоговые значения профиля для сохранения одной транзакцией
+type ProfileUpdate struct {
+	UserID       int
+	FullName     string
+	Email        string
+	Phone        string
+	ReissueCards bool
+	Changes      []ProfileChange
+}
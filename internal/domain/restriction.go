@@ -0,0 +1,121 @@
+package domain
+
+import "time"
+
+// RestrictionMode - вид ограничения счета
+type RestrictionMode string
+
+const (
+	RestrictionDebitFreeze  RestrictionMode = "debit_freeze"  // запрещены списания
+	RestrictionCreditFreeze RestrictionMode = "credit_freeze" // запрещены зачисления
+	RestrictionFullBlock    RestrictionMode = "full_block"    // запрещены любые операции
+	RestrictionLegalHold    RestrictionMode = "legal_hold"    // Amount недоступна для списания
+	RestrictionDormant      RestrictionMode = "dormant"       // нет активности клиента, списания до реактивации запрещены
+)
+
+func (m RestrictionMode) Valid() bool {
+	switch m {
+	case RestrictionDebitFreeze, RestrictionCreditFreeze, RestrictionFullBlock, RestrictionLegalHold, RestrictionDormant:
+		return true
+	}
+	return false
+}
+
+// RestrictionCategory - категория причины ограничения
+type RestrictionCategory string
+
+const (
+	RestrictionFraud           RestrictionCategory = "fraud"
+	RestrictionAML             RestrictionCategory = "aml"
+	RestrictionCourtOrder      RestrictionCategory = "court_order"
+	RestrictionCustomerRequest RestrictionCategory = "customer_request"
+	RestrictionOperational     RestrictionCategory = "operational"
+	RestrictionDormancy        RestrictionCategory = "dormancy"
+	RestrictionOther           RestrictionCategory = "other"
+)
+
+func (c RestrictionCategory) Valid() bool {
+	switch c {
+	case RestrictionFraud, RestrictionAML, RestrictionCourtOrder, RestrictionCustomerRequest, RestrictionOperational, RestrictionDormancy, RestrictionOther:
+		return true
+	}
+	return false
+}
+
+// AccountRestriction - ограничение счета; действует, пока не снято и не истекло (ExpiresAt пустой - бессрочно)
+type AccountRestriction struct {
+	ID        int
+	AccountID int
+	Mode      RestrictionMode
+	Amount    float64 // только для legal_hold
+	Category  RestrictionCategory
+	Reason    string
+	CreatedBy int
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	LiftedBy  int
+	LiftedAt  time.Time
+}
+
+func (r AccountRestriction) Active(now time.Time) bool {
+	return r.LiftedAt.IsZero() && (r.ExpiresAt.IsZero() || now.Before(r.ExpiresAt))
+}
+
+// AccountRestrictions - действующие ограничения одного счета
+type AccountRestrictions []AccountRestriction
+
+func (rs AccountRestrictions) has(modes ...RestrictionMode) bool {
+	for _, r := range rs {
+		for _, m := range modes {
+			if r.Mode == m {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Has - есть ли действующее ограничение данного вида
+func (rs AccountRestrictions) Has(mode RestrictionMode) bool {
+	return rs.has(mode)
+}
+
+func (rs AccountRestrictions) Blocked() bool {
+	return rs.has(RestrictionFullBlock)
+}
+
+func (rs AccountRestrictions) BlocksDebit() bool {
+	return rs.has(RestrictionFullBlock, RestrictionDebitFreeze, RestrictionDormant)
+}
+
+func (rs AccountRestrictions) Dormant() bool {
+	return rs.has(RestrictionDormant)
+}
+
+func (rs AccountRestrictions) BlocksCredit() bool {
+	return rs.has(RestrictionFullBlock, RestrictionCreditFreeze)
+}
+
+// LegalHold - сумма всех legal_hold, недоступная для списания
+func (rs AccountRestrictions) LegalHold() float64 {
+	var total float64
+	for _, r := range rs {
+		if r.Mode == RestrictionLegalHold {
+			total += r.Amount
+		}
+	}
+	return total
+}
+
+// DormantBalance - остатки на спящих счетах в одной валюте
+type DormantBalance struct {
+	Currency string
+	Accounts int
+	Balance  float64
+}
+
+// ReqAccountReactivation - повторное подтверждение личности клиентом для снятия dormant
+type ReqAccountReactivation struct {
+	Password string
+	MFACode  string // обязателен, если включена 2FA
+}
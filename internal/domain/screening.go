@@ -0,0 +1,53 @@
+package domain
+
+import "time"
+
+// Тип проверяемого субъекта
+type ScreeningSubjectType string
+
+const (
+	ScreeningCustomer          ScreeningSubjectType = "customer"
+	ScreeningTransferRecipient ScreeningSubjectType = "transfer_recipient"
+)
+
+// Статус проверки в очереди комплаенса
+type ScreeningStatus string
+
+const (
+	ScreeningPending   ScreeningStatus = "pending"   // ждет решения админа
+	ScreeningCleared   ScreeningStatus = "cleared"   // ложное совпадение, операция разрешена
+	ScreeningConfirmed ScreeningStatus = "confirmed" // совпадение подтверждено, операция запрещена
+)
+
+// Запись из санкционного списка (OFAC, UN и т.д.)
+type WatchlistEntry struct {
+	ID      string
+	Name    string
+	Aliases []string
+	Source  string
+	Program string
+}
+
+// Результат проверки имени по спискам
+type ScreeningMatch struct {
+	Entry       WatchlistEntry
+	MatchedName string
+	Score       float64
+}
+
+// Чистая доменная модель заявки на ручную проверку
+type ScreeningReview struct {
+	ID          int
+	SubjectType ScreeningSubjectType
+	SubjectRef  string
+	SubjectName string
+	MatchedName string
+	ListSource  string
+	ListEntryID string
+	Score       float64
+	Status      ScreeningStatus
+	ReviewedBy  int
+	ReviewNote  string
+	CreatedAt   time.Time
+	ReviewedAt  time.Time
+}
@@ -0,0 +1,37 @@
+package domain
+
+import "time"
+
+// Серверная сессия пользователя - семейство refresh токенов одного логина
+type Session struct {
+	ID             string
+	UserID         int
+	DeviceID       string // хеш токена устройства, выданного сервером при первом входе с него
+	UserAgent      string
+	IP             string
+	StepUpRequired bool // вход с нового устройства, перед переводами нужен код из SMS или email
+	MFAVerified    bool // вход подтвержден вторым фактором
+	// Код подтверждения входа с нового устройства, хранится только хеш
+	StepUpCodeHash      string
+	StepUpCodeExpiresAt time.Time
+	CreatedAt           time.Time
+	LastUsedAt          time.Time
+	ExpiresAt           time.Time
+	RevokedAt           time.Time
+	RevokeReason        string
+}
+
+// Active - сессия не отозвана и не истекла
+func (s *Session) Active() bool {
+	return s.RevokedAt.IsZero() && time.Now().Before(s.ExpiresAt)
+}
+
+// Одноразовый refresh токен, в БД хранится только хеш
+type RefreshToken struct {
+	ID        int
+	SessionID string
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,36 @@
+package domain
+
+import "time"
+
+// DailyVolume - оборот одного типа операций в одной валюте за день (UTC).
+// Volume - сумма без комиссии, Fees - комиссия за превышение лимита.
+type DailyVolume struct {
+	Day      time.Time
+	Currency string
+	Type     TransactionType
+	Count    int
+	Volume   float64
+	Fees     float64
+}
+
+// LimitBreach - пользователь, чьи операции выходили за дневной лимит (операции с комиссией)
+type LimitBreach struct {
+	UserID       int
+	FullName     string
+	Email        string
+	Breaches     int
+	LastBreachAt time.Time
+}
+
+// AdminStats - сводка для операционной панели за период [From, To)
+type AdminStats struct {
+	From             time.Time
+	To               time.Time
+	NewUsers         int
+	ActiveAccounts   int // счета с операциями за период
+	BlockedAccounts  int // заблокированы сейчас
+	Volumes          []DailyVolume
+	FeeIncome        map[string]float64 // по валютам
+	TopLimitBreaches []LimitBreach
+	GeneratedAt      time.Time
+}
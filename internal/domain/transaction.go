@@ -8,6 +8,7 @@ const (
 	Deposit    TransactionType = "deposit"
 	Withdrawal TransactionType = "withdraw"
 	Transfer   TransactionType = "transfer"
+	Adjustment TransactionType = "adjustment"
 )
 
 // Чистая доменная модель транзакции
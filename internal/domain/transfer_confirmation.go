@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+type TransferConfirmationStatus string
+
+const (
+	TransferConfirmationPending   TransferConfirmationStatus = "pending"
+	TransferConfirmationConfirmed TransferConfirmationStatus = "confirmed"
+	TransferConfirmationExpired   TransferConfirmationStatus = "expired"
+	TransferConfirmationFailed    TransferConfirmationStatus = "failed" // исчерпаны попытки ввода кода
+)
+
+// Перевод, ожидающий подтверждения одноразовым кодом из SMS
+type TransferConfirmation struct {
+	ID          int
+	UserID      int
+	Request     ReqTransfer // исходный запрос, исполняется заново после ввода кода
+	CodeHash    string
+	Attempts    int
+	Status      TransferConfirmationStatus
+	ExpiresAt   time.Time
+	CreatedAt   time.Time
+	ConfirmedAt time.Time
+}
@@ -3,8 +3,11 @@ package domain
 type Role string
 
 const (
-	RoleUser  Role = "user"
-	RoleAdmin Role = "admin"
+	RoleUser    Role = "user"
+	RoleAdmin   Role = "admin"
+	RoleSupport Role = "support"
+	RoleAuditor Role = "auditor"
+	RoleTeller  Role = "teller"
 )
 
 // в domain не должен быть прсистввовать теги, сделать маппинг в каждом слое где они будут использовать
@@ -20,8 +23,28 @@ type User struct {
 	Role      Role
 	CreatedAt string
 	UpdatedAt string
+	// Подтверждены кодом; перевод по номеру телефона возможен только на подтвержденный номер
+	EmailVerified bool
+	PhoneVerified bool
+	// Disabled - пользователь отключен админом, вход и токены не принимаются
+	Disabled  bool
+	SessionID string // sid из access токена, в БД не хранится
+	// MFAVerified - сессия access токена подтверждена вторым фактором, в БД не хранится
+	MFAVerified bool
+	// APIClientID и Scopes - запрос от API клиента, действующего от имени пользователя; в БД не хранятся
+	APIClientID int
+	Scopes      []Scope
 }
 
 func (u *User) IsAdmin() bool {
 	return u.Role == RoleAdmin
 }
+
+func (u *User) Can(p Permission) bool {
+	return u.Role.Can(p)
+}
+
+// IsAPIClient - запрос пришел с токеном API клиента, а не из пользовательской сессии
+func (u *User) IsAPIClient() bool {
+	return u.APIClientID != 0
+}
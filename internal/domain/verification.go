@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+// VerificationChannel - что подтверждается кодом
+type VerificationChannel string
+
+const (
+	VerifyEmail VerificationChannel = "email"
+	VerifyPhone VerificationChannel = "phone"
+)
+
+type VerificationStatus string
+
+const (
+	VerificationPending    VerificationStatus = "pending"
+	VerificationVerified   VerificationStatus = "verified"
+	VerificationSuperseded VerificationStatus = "superseded" // запрошен новый код
+	VerificationFailed     VerificationStatus = "failed"     // исчерпаны попытки
+)
+
+// Код подтверждения email или телефона
+type ContactVerification struct {
+	ID          int
+	UserID      int
+	Channel     VerificationChannel
+	Destination string
+	CodeHash    string
+	Attempts    int
+	Status      VerificationStatus
+	ExpiresAt   time.Time
+	CreatedAt   time.Time
+	VerifiedAt  time.Time
+}
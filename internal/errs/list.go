@@ -28,8 +28,11 @@ var (
 	ErrInvalidCredentials    = errors.New("invalid email or password")
 	ErrUserAlreadyRegistered = errors.New("user already registered")
 	ErrWeakPassword          = errors.New("password is too weak")
+	ErrInvalidResetToken     = errors.New("invalid or expired password reset token")
 	ErrInvalidEmail          = errors.New("invalid email format")
 	ErrInvalidPhone          = errors.New("invalid phone number format")
+	ErrVerificationNotFound  = errors.New("no pending verification code")
+	ErrPhoneNotVerified      = errors.New("phone number is not verified")
 
 	// JWT Token errors
 	ErrInvalidToken       = errors.New("invalid token")
@@ -48,9 +51,21 @@ var (
 	ErrInsufficientPermissions = errors.New("insufficient permissions")
 	ErrSessionExpired          = errors.New("session has expired")
 	ErrAccessDenied            = errors.New("access denied")
+	ErrInviteNotFound          = errors.New("invite not found")
+	ErrInviteExpired           = errors.New("invite has expired")
+	ErrUserDisabled            = errors.New("user is disabled")
+
+	// API client errors
+	ErrInvalidClient        = errors.New("invalid client credentials")
+	ErrInvalidScope         = errors.New("invalid scope")
+	ErrUnsupportedGrantType = errors.New("unsupported grant type")
+	ErrAPIClientNotFound    = errors.New("api client not found")
+	ErrRateLimited          = errors.New("rate limit exceeded")
 
 	// Banking domain errors
 	ErrAccountBlocked     = errors.New("account is blocked")
+	ErrAccountRestricted  = errors.New("operation not allowed by account restriction")
+	ErrAccountDormant     = errors.New("account is dormant")
 	ErrInsufficientFunds  = errors.New("insufficient funds")
 	ErrInvalidAmount      = errors.New("amount must be greater than zero")
 	ErrInvalidCurrency    = errors.New("unsupported currency")
@@ -69,10 +84,39 @@ var (
 	ErrInvalidRecipient     = errors.New("invalid recipient")
 	ErrTransferNotAllowed   = errors.New("transfer not allowed")
 
+	// Transfer confirmation (OTP) errors
+	ErrTransferConfirmationNotFound = errors.New("transfer confirmation not found")
+	ErrOTPExpired                   = errors.New("confirmation code has expired")
+	ErrInvalidOTP                   = errors.New("invalid confirmation code")
+
+	// Maker-checker errors
+	ErrPendingTransferNotFound = errors.New("pending transfer not found")
+	ErrPendingTransferExpired  = errors.New("pending transfer has expired")
+	ErrSelfApproval            = errors.New("initiator cannot approve own operation")
+	ErrPendingActionNotFound   = errors.New("pending action not found")
+	ErrPendingActionExpired    = errors.New("pending action has expired")
+	ErrApprovalRequired        = errors.New("operation requires approval by another admin")
+	ErrAdjustmentCeiling       = errors.New("adjustment ceiling exceeded")
+
 	// Security errors
 	ErrTooManyAttempts    = errors.New("too many failed attempts")
 	ErrAccountLocked      = errors.New("account is temporarily locked")
 	ErrSuspiciousActivity = errors.New("suspicious activity detected")
+	ErrStepUpRequired     = errors.New("additional verification required for this session")
+
+	// Two-factor authentication errors
+	ErrMFARequired    = errors.New("two-factor authentication required")
+	ErrInvalidMFACode = errors.New("invalid two-factor code")
+
+	// Sanctions screening errors
+	ErrScreeningPending        = errors.New("operation is pending compliance review")
+	ErrSanctionsMatch          = errors.New("operation blocked by sanctions screening")
+	ErrScreeningReviewNotFound = errors.New("screening review not found")
+
+	// KYC errors
+	ErrKYCDocumentNotFound = errors.New("kyc document not found")
+	ErrKYCDocumentInvalid  = errors.New("unsupported or too large kyc document")
+	ErrKYCLimitExceeded    = errors.New("daily limit for the kyc tier exceeded")
 
 	// Operation errors
 	ErrOperationNotAllowed = errors.New("operation not allowed")
@@ -0,0 +1,236 @@
+// Package jwtkeys - ключи подписи access токенов (RS256 или EdDSA) с kid в заголовке.
+// Подписывает один ключ, проверяют все загруженные: так старые токены живут до истечения после ротации.
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minRSABits - RSA ключи короче не принимаются
+const minRSABits = 2048
+
+var (
+	ErrNoSigningKey = errors.New("jwt signing key not found")
+	ErrUnknownKey   = errors.New("unknown jwt key id")
+)
+
+// Key - один ключ кольца; Private пустой у ключей, оставленных только для проверки
+type Key struct {
+	ID      string
+	Method  jwt.SigningMethod
+	Private crypto.Signer
+	Public  crypto.PublicKey
+}
+
+type Keyring struct {
+	signing *Key
+	keys    map[string]*Key
+}
+
+// JWK - открытый ключ в формате RFC 7517
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// LoadDir загружает все *.pem из каталога, kid - имя файла без расширения.
+// Файл может содержать приватный ключ (PKCS#8, PKCS#1) или только открытый (PKIX).
+// signingKID выбирает ключ подписи, он обязан быть приватным.
+func LoadDir(dir, signingKID string) (*Keyring, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no *.pem keys in %s", dir)
+	}
+
+	ring := &Keyring{keys: make(map[string]*Key)}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		kid := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		key, err := ParsePEM(kid, data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		ring.keys[kid] = key
+	}
+
+	signing, ok := ring.keys[signingKID]
+	if !ok || signing.Private == nil {
+		return nil, fmt.Errorf("%w: %q", ErrNoSigningKey, signingKID)
+	}
+	ring.signing = signing
+	return ring, nil
+}
+
+// ParsePEM разбирает один RSA или Ed25519 ключ
+func ParsePEM(kid string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	key := &Key{ID: kid}
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := parsed.(crypto.Signer)
+		if !ok {
+			return nil, errors.New("unsupported private key")
+		}
+		key.Private = signer
+		key.Public = signer.Public()
+	case "RSA PRIVATE KEY":
+		parsed, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		key.Private = parsed
+		key.Public = parsed.Public()
+	case "PUBLIC KEY":
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		key.Public = parsed
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+
+	switch pub := key.Public.(type) {
+	case ed25519.PublicKey:
+		key.Method = jwt.SigningMethodEdDSA
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("RSA key must be at least %d bits", minRSABits)
+		}
+		key.Method = jwt.SigningMethodRS256
+	default:
+		return nil, errors.New("only RSA and Ed25519 keys are supported")
+	}
+	return key, nil
+}
+
+// Generate создает кольцо с одним временным Ed25519 ключом: токены перестают действовать после перезапуска
+func Generate() (*Keyring, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(pub)
+	key := &Key{
+		ID:      "ephemeral-" + base64.RawURLEncoding.EncodeToString(sum[:8]),
+		Method:  jwt.SigningMethodEdDSA,
+		Private: priv,
+		Public:  pub,
+	}
+	return &Keyring{signing: key, keys: map[string]*Key{key.ID: key}}, nil
+}
+
+// MustGenerate - Generate, который паникует при ошибке генератора случайных чисел
+func MustGenerate() *Keyring {
+	ring, err := Generate()
+	if err != nil {
+		panic(err)
+	}
+	return ring
+}
+
+// SigningKeyID - kid текущего ключа подписи
+func (r *Keyring) SigningKeyID() string {
+	return r.signing.ID
+}
+
+// Sign подписывает claims текущим ключом и ставит kid в заголовок
+func (r *Keyring) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(r.signing.Method, claims)
+	token.Header["kid"] = r.signing.ID
+	return token.SignedString(r.signing.Private)
+}
+
+// Keyfunc для jwt.Parse: ключ ищется по kid, алгоритм токена обязан совпадать с алгоритмом ключа
+func (r *Keyring) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := r.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %s for key %s", token.Method.Alg(), kid)
+	}
+	return key.Public, nil
+}
+
+// Methods - алгоритмы загруженных ключей для jwt.WithValidMethods
+func (r *Keyring) Methods() []string {
+	seen := make(map[string]bool)
+	var methods []string
+	for _, key := range r.keys {
+		if alg := key.Method.Alg(); !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// JWKS - все открытые ключи кольца для /.well-known/jwks.json
+func (r *Keyring) JWKS() JWKS {
+	kids := make([]string, 0, len(r.keys))
+	for kid := range r.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	set := JWKS{Keys: make([]JWK, 0, len(kids))}
+	for _, kid := range kids {
+		key := r.keys[kid]
+		jwk := JWK{Use: "sig", Alg: key.Method.Alg(), Kid: kid}
+		switch pub := key.Public.(type) {
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
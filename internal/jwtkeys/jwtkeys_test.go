@@ -0,0 +1,102 @@
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) {
+	t.Helper()
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Ротация: старый RSA ключ оставлен только открытым, подписывает новый Ed25519
+func TestLoadDir_RotationAndJWKS(t *testing.T) {
+	dir := t.TempDir()
+
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldRing := &Keyring{keys: map[string]*Key{}}
+	oldRing.signing = &Key{ID: "2025-01", Method: jwt.SigningMethodRS256, Private: oldKey, Public: oldKey.Public()}
+	oldRing.keys["2025-01"] = oldRing.signing
+	oldToken, err := oldRing.Sign(jwt.MapClaims{"sub": "1", "exp": time.Now().Add(time.Minute).Unix()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pubDER, _ := x509.MarshalPKIXPublicKey(oldKey.Public())
+	writePEM(t, dir, "2025-01.pem", "PUBLIC KEY", pubDER)
+	_, newKey, _ := ed25519.GenerateKey(rand.Reader)
+	newDER, _ := x509.MarshalPKCS8PrivateKey(newKey)
+	writePEM(t, dir, "2025-06.pem", "PRIVATE KEY", newDER)
+
+	if _, err := LoadDir(dir, "2025-01"); !errors.Is(err, ErrNoSigningKey) {
+		t.Fatalf("public-only key must not sign, got %v", err)
+	}
+
+	ring, err := LoadDir(dir, "2025-06")
+	if err != nil {
+		t.Fatalf("LoadDir: %v", err)
+	}
+
+	newToken, err := ring.Sign(jwt.MapClaims{"sub": "1", "exp": time.Now().Add(time.Minute).Unix()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, tok := range map[string]string{"old": oldToken, "new": newToken} {
+		if _, err := jwt.Parse(tok, ring.Keyfunc, jwt.WithValidMethods(ring.Methods())); err != nil {
+			t.Fatalf("%s token rejected: %v", name, err)
+		}
+	}
+
+	set := ring.JWKS()
+	if len(set.Keys) != 2 || set.Keys[0].Kid != "2025-01" || set.Keys[0].Kty != "RSA" || set.Keys[0].E != "AQAB" ||
+		set.Keys[1].Kty != "OKP" || set.Keys[1].Crv != "Ed25519" || set.Keys[1].X == "" {
+		t.Fatalf("unexpected jwks: %+v", set)
+	}
+}
+
+func TestKeyfunc_RejectsUnknownKidAndAlgSwap(t *testing.T) {
+	ring := MustGenerate()
+	tok, err := ring.Sign(jwt.MapClaims{"sub": "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := jwt.Parse(tok, MustGenerate().Keyfunc); err == nil {
+		t.Fatalf("token with unknown kid must be rejected")
+	}
+
+	// HS256 токен с kid настоящего ключа (подмена алгоритма) не проходит
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "1"})
+	forged.Header["kid"] = ring.SigningKeyID()
+	forgedStr, _ := forged.SignedString([]byte("secret"))
+	if _, err := jwt.Parse(forgedStr, ring.Keyfunc); err == nil {
+		t.Fatalf("alg swap must be rejected")
+	}
+}
+
+func TestParsePEM_RejectsWeakRSA(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if _, err := ParsePEM("weak", data); err == nil {
+		t.Fatalf("expected error for 1024-bit key")
+	}
+}
@@ -0,0 +1,189 @@
+package migrator
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/MMII0220/MiniBank/internal/pglock"
+	"github.com/MMII0220/MiniBank/migration"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+func testFS() fstest.MapFS {
+	return fstest.MapFS{
+		"002_create_accounts.up.sql":   {Data: []byte("CREATE TABLE accounts (id SERIAL)")},
+		"002_create_accounts.down.sql": {Data: []byte("DROP TABLE accounts")},
+		"001_create_users.up.sql":      {Data: []byte("CREATE TABLE users (id SERIAL)")},
+		"001_create_users.down.sql":    {Data: []byte("DROP TABLE users")},
+		"migration.go":                 {Data: []byte("package migration")},
+	}
+}
+
+func newMockMigrator(t *testing.T) (*Migrator, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	m, err := New(sqlx.NewDb(db, "sqlmock"), testFS())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return m, mock
+}
+
+func expectLock(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_lock($1)")).WithArgs(pglock.Migrations).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func expectVersion(mock sqlmock.Sqlmock, version int64, dirty bool) {
+	rows := sqlmock.NewRows([]string{"version", "dirty"})
+	if version > 0 {
+		rows.AddRow(version, dirty)
+	}
+	mock.ExpectQuery("SELECT version, dirty FROM schema_migrations").WillReturnRows(rows)
+}
+
+func expectApply(mock sqlmock.Sqlmock, script string, version int64) {
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(script)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM schema_migrations").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO schema_migrations").WithArgs(version).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+}
+
+func expectUnlock(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_unlock($1)")).WithArgs(pglock.Migrations).WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func TestLoad_SortedPairs(t *testing.T) {
+	migrations, err := Load(testFS())
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(migrations) != 2 || migrations[0].String() != "001_create_users" || migrations[1].Version != 2 {
+		t.Fatalf("unexpected migrations: %v", migrations)
+	}
+	if migrations[1].Down != "DROP TABLE accounts" {
+		t.Fatalf("unexpected down script: %q", migrations[1].Down)
+	}
+}
+
+func TestLoad_MissingDown(t *testing.T) {
+	fsys := testFS()
+	delete(fsys, "002_create_accounts.down.sql")
+	if _, err := Load(fsys); err == nil || !strings.Contains(err.Error(), "002_create_accounts") {
+		t.Fatalf("expected missing down error, got %v", err)
+	}
+}
+
+func TestLoad_DuplicateVersion(t *testing.T) {
+	fsys := testFS()
+	fsys["002_create_cards.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE cards (id SERIAL)")}
+	if _, err := Load(fsys); err == nil || !strings.Contains(err.Error(), "version 2") {
+		t.Fatalf("expected duplicate version error, got %v", err)
+	}
+}
+
+// Встроенные миграции должны загружаться: у каждой версии есть up и down
+func TestLoad_Embedded(t *testing.T) {
+	migrations, err := Load(migration.FS)
+	if err != nil {
+		t.Fatalf("Load embedded: %v", err)
+	}
+	for i, m := range migrations {
+		if m.Version != uint(i+1) {
+			t.Fatalf("embedded migrations must be numbered without gaps, got %s at position %d", m, i+1)
+		}
+	}
+}
+
+func TestUp_AppliesPendingUnderLock(t *testing.T) {
+	m, mock := newMockMigrator(t)
+	expectLock(mock)
+	expectVersion(mock, 1, false)
+	expectApply(mock, "CREATE TABLE accounts (id SERIAL)", 2)
+	expectUnlock(mock)
+
+	applied, err := m.Up(context.Background())
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if len(applied) != 1 || applied[0].Version != 2 {
+		t.Fatalf("expected only migration 2 applied, got %v", applied)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestUp_FailedMigrationRollsBack(t *testing.T) {
+	m, mock := newMockMigrator(t)
+	expectLock(mock)
+	expectVersion(mock, 0, false)
+	mock.ExpectBegin()
+	mock.ExpectExec("CREATE TABLE users").WillReturnError(errors.New("syntax error"))
+	mock.ExpectRollback()
+	expectUnlock(mock)
+
+	applied, err := m.Up(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "001_create_users up") {
+		t.Fatalf("expected migration error, got %v", err)
+	}
+	if len(applied) != 0 {
+		t.Fatalf("nothing must be applied, got %v", applied)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestDown_RevertsToPreviousVersion(t *testing.T) {
+	m, mock := newMockMigrator(t)
+	expectLock(mock)
+	expectVersion(mock, 2, false)
+	expectApply(mock, "DROP TABLE accounts", 1)
+	expectUnlock(mock)
+
+	reverted, err := m.Down(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if len(reverted) != 1 || reverted[0].Version != 2 {
+		t.Fatalf("expected migration 2 reverted, got %v", reverted)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	cases := map[string]struct {
+		expect func(mock sqlmock.Sqlmock)
+		want   error
+	}{
+		"up to date": {func(mock sqlmock.Sqlmock) { expectVersion(mock, 2, false) }, nil},
+		"behind":     {func(mock sqlmock.Sqlmock) { expectVersion(mock, 1, false) }, ErrSchemaBehind},
+		"dirty":      {func(mock sqlmock.Sqlmock) { expectVersion(mock, 2, true) }, ErrDirty},
+		"fresh database": {func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery("SELECT version, dirty FROM schema_migrations").
+				WillReturnError(&pq.Error{Code: "42P01", Message: `relation "schema_migrations" does not exist`})
+		}, ErrSchemaBehind},
+	}
+	for name, tc := range cases {
+		m, mock := newMockMigrator(t)
+		tc.expect(mock)
+		err := m.Check(context.Background())
+		if !errors.Is(err, tc.want) || (tc.want == nil && err != nil) {
+			t.Fatalf("%s: expected %v, got %v", name, tc.want, err)
+		}
+	}
+}
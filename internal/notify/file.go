@@ -0,0 +1,57 @@
+package notify
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+)
+
+// FileNotifier - локальная замена SMS/email шлюза: каждое уведомление дописывается строкой JSON в файл.
+// Удобно для разработки и тестов - коды подтверждения можно прочитать из файла.
+type FileNotifier struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileNotifier(path string) *FileNotifier {
+	return &FileNotifier{path: path}
+}
+
+type fileRecord struct {
+	SentAt  time.Time `json:"sent_at"`
+	UserID  int       `json:"user_id"`
+	Channel string    `json:"channel"`
+	To      string    `json:"to"`
+	Subject string    `json:"subject"`
+	Body    string    `json:"body"`
+}
+
+func (n *FileNotifier) Send(msg domain.Notification) error {
+	data, err := json.Marshal(fileRecord{
+		SentAt:  time.Now(),
+		UserID:  msg.UserID,
+		Channel: string(msg.Channel),
+		To:      msg.To,
+		Subject: msg.Subject,
+		Body:    msg.Body,
+	})
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
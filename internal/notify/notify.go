@@ -0,0 +1,25 @@
+package notify
+
+import (
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/logger"
+)
+
+// LogNotifier - заглушка, которая только пишет уведомления в лог
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (n *LogNotifier) Send(msg domain.Notification) error {
+	log := logger.GetLogger()
+	log.Info().
+		Int("user_id", msg.UserID).
+		Str("channel", string(msg.Channel)).
+		Str("to", msg.To).
+		Str("subject", msg.Subject).
+		Str("body", msg.Body).
+		Msg("Notification sent")
+	return nil
+}
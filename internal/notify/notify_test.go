@@ -0,0 +1,30 @@
+package notify
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+)
+
+func TestFileNotifier_AppendsJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	n := NewFileNotifier(path)
+
+	for _, body := range []string{"code 123456", "code 654321"} {
+		if err := n.Send(domain.Notification{UserID: 5, Channel: domain.ChannelSMS, To: "+992900000000", Body: body}); err != nil {
+			t.Fatalf("send: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], `"body":"code 654321"`) || !strings.Contains(lines[0], `"channel":"sms"`) {
+		t.Fatalf("unexpected outbox: %s", data)
+	}
+}
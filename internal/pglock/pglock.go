@@ -0,0 +1,34 @@
+// Package pglock - ключи PostgreSQL advisory lock всего приложения.
+//
+// PostgreSQL различает advisory lock только по числу, и ключи действуют на всю базу. Поэтому все ключи
+// объявлены здесь, а не рядом с кодом, который их берет: совпавшие значения молча сериализовали бы
+// несвязанные операции, а снятие чужой session блокировки (pg_advisory_unlock) отпустило бы ее владельца.
+// Новый ключ получает следующее свободное значение из этого списка; TestKeysAreDistinct проверяет, что повторов нет.
+//
+// Значения менять нельзя: при поэтапном обновлении старые и новые инстансы работают одновременно
+// и должны брать одну и ту же блокировку, иначе, например, две версии одновременно допишут цепочку audit_events.
+package pglock
+
+// Ключи одночисловой формы pg_advisory_lock(bigint)
+const (
+	// AuditChain - добавление записи в audit_events (транзакционная блокировка):
+	// цепочка строится строго последовательно, следующая запись ссылается на hash последней
+	AuditChain int64 = 410041
+
+	// Dormancy - фоновое сканирование спящих счетов (транзакционная блокировка):
+	// несколько инстансов не пометят один счет дважды
+	Dormancy int64 = 410046
+
+	// Migrations - прогон миграций схемы (session блокировка на отдельном соединении):
+	// инстансы, стартующие одновременно, применяют миграции по очереди
+	Migrations int64 = 410050
+)
+
+// Классы двухчисловой формы pg_advisory_lock(int, int): второе число - id объекта.
+// В PostgreSQL двухчисловые ключи не пересекаются с одночисловыми, но классы все равно берутся
+// из того же списка, чтобы по значению в pg_locks было однозначно видно, чья это блокировка.
+const (
+	// AdjustmentCeiling - дневной потолок ручных корректировок, второе число - id админа (транзакционная блокировка):
+	// корректировки одного админа проводятся по очереди, иначе параллельные запросы обойдут потолок
+	AdjustmentCeiling int32 = 410044
+)
@@ -0,0 +1,22 @@
+package pglock
+
+import "testing"
+
+func TestKeysAreDistinct(t *testing.T) {
+	keys := []struct {
+		name string
+		key  int64
+	}{
+		{"AuditChain", AuditChain},
+		{"Dormancy", Dormancy},
+		{"Migrations", Migrations},
+		{"AdjustmentCeiling", int64(AdjustmentCeiling)},
+	}
+	seen := make(map[int64]string, len(keys))
+	for _, k := range keys {
+		if other, ok := seen[k.key]; ok {
+			t.Fatalf("%s and %s share advisory lock key %d", k.name, other, k.key)
+		}
+		seen[k.key] = k.name
+	}
+}
@@ -7,70 +7,35 @@ import (
 	"time"
 
 	"log"
-	"os"
 	"strconv"
 
-	"github.com/joho/godotenv"
+	"github.com/MMII0220/MiniBank/config"
 	"github.com/redis/go-redis/v9"
 )
 
 var rdb *redis.Client
-var ctx = context.Background()
 
-// accountsCacheTTL returns TTL for accounts cache from env REDIS_ACCOUNTS_TTL or defaults to 15m
-func accountsCacheTTL() time.Duration {
-	s := os.Getenv("REDIS_ACCOUNTS_TTL")
-	if s == "" {
-		return 15 * time.Minute
-	}
-	d, err := time.ParseDuration(s)
-	if err != nil {
-		return 15 * time.Minute
-	}
-	return d
-}
-
-func InitRedisConnection() error {
-	err := godotenv.Load()
-	if err != nil {
-		log.Printf("Warning: .env file not found, using default Redis settings")
-	}
-
-	// Получаем настройки из переменных окружения или используем дефолтные
-	host := os.Getenv("REDIS_HOST")
-	if host == "" {
-		host = "127.0.0.1"
-	}
-
-	port := os.Getenv("REDIS_PORT")
-	if port == "" {
-		port = "6379"
-	}
-
-	password := os.Getenv("REDIS_PASSWORD")
-
-	dbStr := os.Getenv("REDIS_DB")
-	db := 0
-	if dbStr != "" {
-		if parsedDB, err := strconv.Atoi(dbStr); err == nil {
-			db = parsedDB
-		}
-	}
+// accountsTTL - TTL кеша счетов, задается при подключении (REDIS_ACCOUNTS_TTL)
+var accountsTTL = 15 * time.Minute
 
+func InitRedisConnection(ctx context.Context, cfg config.Redis) error {
 	rdb = redis.NewClient(&redis.Options{
-		Addr:     fmt.Sprintf("%s:%s", host, port),
-		Password: password,
-		DB:       db,
+		Addr:     fmt.Sprintf("%s:%s", cfg.Host, cfg.Port),
+		Password: cfg.Password,
+		DB:       cfg.DB,
 	})
+	accountsTTL = cfg.AccountsTTL
 
 	// Тестируем подключение
-	_, err = rdb.Ping(ctx).Result()
+	pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	_, err := rdb.Ping(pingCtx).Result()
 	if err != nil {
 		log.Printf("Redis connection test failed: %v", err)
 		return err
 	}
 
-	log.Printf("Redis connected successfully to %s:%s", host, port)
+	log.Printf("Redis connected successfully to %s:%s", cfg.Host, cfg.Port)
 	return nil
 }
 
@@ -78,8 +43,16 @@ func GetRedisClient() *redis.Client {
 	return rdb
 }
 
-// SetAccountsCache - кеширует счета пользователя на 15 минут
-func SetAccountsCache(userID int, accounts interface{}) error {
+// Close закрывает соединения с Redis при остановке приложения
+func Close() error {
+	if rdb == nil {
+		return nil
+	}
+	return rdb.Close()
+}
+
+// SetAccountsCache - кеширует счета пользователя на REDIS_ACCOUNTS_TTL (по умолчанию 15 минут)
+func SetAccountsCache(ctx context.Context, userID int, accounts interface{}) error {
 	if rdb == nil {
 		return fmt.Errorf("redis client not initialized")
 	}
@@ -91,11 +64,11 @@ func SetAccountsCache(userID int, accounts interface{}) error {
 		return err
 	}
 
-	return rdb.Set(ctx, key, data, accountsCacheTTL()).Err()
+	return rdb.Set(ctx, key, data, accountsTTL).Err()
 }
 
 // GetAccountsCache - получает кешированные счета пользователя
-func GetAccountsCache(userID int, result interface{}) error {
+func GetAccountsCache(ctx context.Context, userID int, result interface{}) error {
 	if rdb == nil {
 		return fmt.Errorf("redis client not initialized")
 	}
@@ -111,23 +84,26 @@ func GetAccountsCache(userID int, result interface{}) error {
 }
 
 // DeleteAccountsCache - удаляет кеш счетов пользователя
-func DeleteAccountsCache(userID int) error {
+func DeleteAccountsCache(ctx context.Context, userID int) error {
 	if rdb == nil {
 		return nil // Не возвращаем ошибку если Redis не инициализирован
 	}
 
+	// Кеш сбрасывается после коммита: отмена запроса не должна оставить устаревшие балансы
 	key := fmt.Sprintf("user_accounts:%d", userID)
-	return rdb.Del(ctx, key).Err()
+	return rdb.Del(context.WithoutCancel(ctx), key).Err()
 }
 
 // DeleteAccountCacheByAccountID - удаляет кеш по ID аккаунта (нужно получить userID)
-func DeleteAccountCacheByAccountID(accountID int) error {
+func DeleteAccountCacheByAccountID(ctx context.Context, accountID int) error {
 	if rdb == nil {
 		return nil // Не возвращаем ошибку если Redis не инициализирован
 	}
 
 	// Можно добавить дополнительную логику для получения userID по accountID
 	// Для простоты пока удаляем все кеши аккаунтов
+	// Кеш сбрасывается после коммита: отмена запроса не должна оставить устаревшие балансы
+	ctx = context.WithoutCancel(ctx)
 	pattern := "user_accounts:*"
 	keys, err := rdb.Keys(ctx, pattern).Result()
 	if err != nil {
@@ -142,10 +118,164 @@ func DeleteAccountCacheByAccountID(accountID int) error {
 }
 
 // Ping checks Redis connectivity
-func Ping() error {
+func Ping(ctx context.Context) error {
 	if rdb == nil {
 		return fmt.Errorf("redis client not initialized")
 	}
 	_, err := rdb.Ping(ctx).Result()
 	return err
 }
+
+// SetSessionStatus кеширует состояние сессии (активна/отозвана) для проверки access токенов
+func SetSessionStatus(ctx context.Context, sessionID string, active bool, ttl time.Duration) error {
+	if rdb == nil {
+		return fmt.Errorf("redis client not initialized")
+	}
+
+	key := fmt.Sprintf("session:%s", sessionID)
+	return rdb.Set(ctx, key, strconv.FormatBool(active), ttl).Err()
+}
+
+// GetSessionStatus возвращает закешированное состояние сессии, redis.Nil - кеша нет
+func GetSessionStatus(ctx context.Context, sessionID string) (bool, error) {
+	if rdb == nil {
+		return false, fmt.Errorf("redis client not initialized")
+	}
+
+	key := fmt.Sprintf("session:%s", sessionID)
+	data, err := rdb.Get(ctx, key).Result()
+	if err != nil {
+		return false, err
+	}
+	return strconv.ParseBool(data)
+}
+
+// IncrLoginFailures увеличивает счетчик неудачных входов (key - "email:..." или "ip:...").
+// Окно отсчитывается от первой неудачи.
+func IncrLoginFailures(ctx context.Context, key string, window time.Duration) (int64, error) {
+	if rdb == nil {
+		return 0, fmt.Errorf("redis client not initialized")
+	}
+
+	redisKey := fmt.Sprintf("login_fail:%s", key)
+	count, err := rdb.Incr(ctx, redisKey).Result()
+	if err != nil {
+		return 0, err
+	}
+	if count == 1 {
+		if err := rdb.Expire(ctx, redisKey, window).Err(); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// GetLoginFailures возвращает текущее число неудачных входов
+func GetLoginFailures(ctx context.Context, key string) (int64, error) {
+	if rdb == nil {
+		return 0, fmt.Errorf("redis client not initialized")
+	}
+
+	count, err := rdb.Get(ctx, fmt.Sprintf("login_fail:%s", key)).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return count, err
+}
+
+// SetLoginBackoff запрещает следующую попытку входа на время delay
+func SetLoginBackoff(ctx context.Context, key string, delay time.Duration) error {
+	if rdb == nil {
+		return fmt.Errorf("redis client not initialized")
+	}
+	return rdb.Set(ctx, fmt.Sprintf("login_backoff:%s", key), "1", delay).Err()
+}
+
+// GetLoginBackoff - сколько еще ждать до следующей попытки входа, 0 - можно пробовать
+func GetLoginBackoff(ctx context.Context, key string) (time.Duration, error) {
+	if rdb == nil {
+		return 0, fmt.Errorf("redis client not initialized")
+	}
+
+	ttl, err := rdb.PTTL(ctx, fmt.Sprintf("login_backoff:%s", key)).Result()
+	if err != nil {
+		return 0, err
+	}
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+// ClearLoginFailures сбрасывает счетчик и задержку после успешного входа или разблокировки
+func ClearLoginFailures(ctx context.Context, key string) error {
+	if rdb == nil {
+		return nil
+	}
+	return rdb.Del(ctx, fmt.Sprintf("login_fail:%s", key), fmt.Sprintf("login_backoff:%s", key)).Err()
+}
+
+// IncrPasswordResetRequests считает запросы восстановления пароля (key - "email:..." или "ip:...").
+// Счетчики отдельные от неудачных входов. Окно отсчитывается от первого запроса.
+func IncrPasswordResetRequests(ctx context.Context, key string, window time.Duration) (int64, error) {
+	if rdb == nil {
+		return 0, fmt.Errorf("redis client not initialized")
+	}
+
+	redisKey := fmt.Sprintf("reset_rate:%s", key)
+	count, err := rdb.Incr(ctx, redisKey).Result()
+	if err != nil {
+		return 0, err
+	}
+	if count == 1 {
+		if err := rdb.Expire(ctx, redisKey, window).Err(); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// IncrAPIClientRequests считает запросы API клиента в текущем окне (фиксированное окно от первого запроса)
+func IncrAPIClientRequests(ctx context.Context, clientID int, window time.Duration) (int64, error) {
+	if rdb == nil {
+		return 0, fmt.Errorf("redis client not initialized")
+	}
+
+	redisKey := fmt.Sprintf("api_rate:%d", clientID)
+	count, err := rdb.Incr(ctx, redisKey).Result()
+	if err != nil {
+		return 0, err
+	}
+	if count == 1 {
+		if err := rdb.Expire(ctx, redisKey, window).Err(); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// SetStatsCache - кеширует агрегаты операционной панели на короткое время
+func SetStatsCache(ctx context.Context, key string, stats interface{}, ttl time.Duration) error {
+	if rdb == nil {
+		return fmt.Errorf("redis client not initialized")
+	}
+
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return err
+	}
+	return rdb.Set(ctx, fmt.Sprintf("admin_stats:%s", key), data, ttl).Err()
+}
+
+// GetStatsCache - получает закешированные агрегаты
+func GetStatsCache(ctx context.Context, key string, result interface{}) error {
+	if rdb == nil {
+		return fmt.Errorf("redis client not initialized")
+	}
+
+	data, err := rdb.Get(ctx, fmt.Sprintf("admin_stats:%s", key)).Result()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(data), result)
+}
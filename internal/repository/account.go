@@ -1,76 +1,86 @@
 package repository
 
 import (
+	"context"
+	"fmt"
+	"strings"
+
 	"github.com/MMII0220/MiniBank/internal/domain"
 	"github.com/MMII0220/MiniBank/internal/errs"
 	"github.com/MMII0220/MiniBank/internal/logger"
-	"github.com/MMII0220/MiniBank/internal/redis"
 	"github.com/MMII0220/MiniBank/internal/repository/models"
+	"github.com/jmoiron/sqlx"
 )
 
-func (r *Repository) SetAccountBlock(accountID int, block bool, reqLogs domain.AdminAuditLog) error {
-	log := logger.GetLogger()
-	log.Info().
-		Int("account_id", accountID).
-		Bool("block", block).
-		Int("admin_id", reqLogs.AdminID).
-		Str("reason", reqLogs.Reason).
-		Msg("Setting account block status")
-
-	// Начинаем транзакцию
-	tx, err := r.db.Beginx()
+// insertAuditLog пишет запись в account_audit в рамках переданной транзакции
+func (r *Repository) insertAuditLog(ctx context.Context, tx *sqlx.Tx, reqLogs domain.AdminAuditLog) error {
+	logModel := models.AdminAuditLogFromDomain(reqLogs)
+	_, err := tx.ExecContext(ctx, `INSERT INTO account_audit (account_id, admin_id, action, reason, api_client_id) VALUES ($1, $2, $3, $4, NULLIF($5, 0))`,
+		logModel.AccountID, logModel.AdminID, logModel.Action, logModel.Reason, logModel.APIClientID)
 	if err != nil {
-		log.Error().Err(err).Int("account_id", accountID).Msg("Failed to begin transaction")
-		return err
+		return r.translateError(err)
 	}
-	defer tx.Rollback() // откатываем если что-то пойдет не так
+	return nil
+}
 
-	// Обновляем аккаунт
-	result, err := tx.Exec(`UPDATE accounts SET blocked = $1 WHERE id = $2`, block, accountID)
-	if err != nil {
-		return err
+// GetAuditLogs - записи account_audit по фильтру с курсором по id
+func (r *Repository) GetAuditLogs(ctx context.Context, filter domain.AuditLogFilter) ([]domain.AdminAuditLog, error) {
+	log := logger.GetLogger()
+	log.Debug().
+		Int("admin_id", filter.AdminID).
+		Int("account_id", filter.AccountID).
+		Str("action", filter.Action).
+		Int("cursor", filter.Cursor).
+		Int("limit", filter.Limit).
+		Msg("Retrieving audit logs")
+
+	var conditions []string
+	var args []interface{}
+	where := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
 	}
-
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		return errs.ErrAccountNotFound
+	if filter.AdminID != 0 {
+		where("admin_id = $%d", filter.AdminID)
 	}
-
-	// Записываем лог
-	logModel := models.AdminAuditLogFromDomain(reqLogs)
-	_, err = tx.Exec(`INSERT INTO account_audit (account_id, admin_id, action, reason) VALUES ($1, $2, $3, $4)`,
-		logModel.AccountID, logModel.AdminID, logModel.Action, logModel.Reason)
-	if err != nil {
-		return r.translateError(err)
+	if filter.AccountID != 0 {
+		where("account_id = $%d", filter.AccountID)
 	}
-
-	// Коммитим все изменения
-	err = tx.Commit()
-	if err != nil {
-		log.Error().Err(err).Int("account_id", accountID).Msg("Failed to commit transaction")
-		return r.translateError(err)
+	if filter.Action != "" {
+		where("action = $%d", filter.Action)
 	}
-
-	// Удаляем кеш после успешного изменения статуса блокировки
-	if cacheErr := redis.DeleteAccountCacheByAccountID(accountID); cacheErr != nil {
-		log.Warn().Err(cacheErr).Int("account_id", accountID).Msg("Failed to delete account cache after block/unblock")
+	if !filter.From.IsZero() {
+		where("created_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		where("created_at < $%d", filter.To)
+	}
+	order := "DESC"
+	if filter.Ascending {
+		order = "ASC"
+	}
+	if filter.Cursor != 0 {
+		if filter.Ascending {
+			where("id > $%d", filter.Cursor)
+		} else {
+			where("id < $%d", filter.Cursor)
+		}
 	}
 
-	log.Info().
-		Int("account_id", accountID).
-		Bool("block", block).
-		Msg("Account block status updated successfully")
-	return nil
-}
-
-func (r *Repository) GetAuditLogs() ([]domain.AdminAuditLog, error) {
-	log := logger.GetLogger()
-	log.Debug().Msg("Retrieving audit logs")
+	query := `SELECT id, account_id, admin_id, COALESCE(api_client_id, 0) AS api_client_id, action, COALESCE(reason, '') AS reason, created_at
+		FROM account_audit`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	// id растет вместе с created_at, а в отличие от времени уникален - по нему и курсор
+	query += " ORDER BY id " + order
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
 
 	var logModels []models.AdminAuditLogModel
-	query := `SELECT id, account_id, admin_id, action, reason, created_at FROM account_audit ORDER BY created_at DESC`
-	err := r.db.Select(&logModels, query)
-	if err != nil {
+	if err := r.db.SelectContext(ctx, &logModels, query, args...); err != nil {
 		return nil, r.translateError(err)
 	}
 
@@ -82,15 +92,15 @@ func (r *Repository) GetAuditLogs() ([]domain.AdminAuditLog, error) {
 	return logs, nil
 }
 
-func (r *Repository) GetAllAccountsByUserID(userID int) ([]domain.Account, error) {
+func (r *Repository) GetAllAccountsByUserID(ctx context.Context, userID int) ([]domain.Account, error) {
 	log := logger.GetLogger()
 	log.Debug().Int("user_id", userID).Msg("Retrieving all accounts for user")
 
 	var accountModels []models.AccountModel
-	query := `SELECT id, user_id, balance, currency, blocked, created_at, updated_at 
-			  FROM accounts WHERE user_id = $1 ORDER BY created_at DESC`
+	query := `SELECT a.id, a.user_id, a.balance, a.hold_amount, a.currency, ` + accountBlockedColumn + `, a.account_type, a.created_at, a.updated_at
+			  FROM accounts a WHERE a.user_id = $1 ORDER BY a.created_at DESC`
 
-	err := r.db.Select(&accountModels, query, userID)
+	err := r.db.SelectContext(ctx, &accountModels, query, userID)
 	if err != nil {
 		log.Error().Err(err).Int("user_id", userID).Msg("Failed to retrieve accounts")
 		return nil, r.translateError(err)
@@ -105,3 +115,17 @@ func (r *Repository) GetAllAccountsByUserID(userID int) ([]domain.Account, error
 	log.Info().Int("user_id", userID).Int("accounts_count", len(accounts)).Msg("Accounts retrieved successfully")
 	return accounts, nil
 }
+
+func (r *Repository) GetAccountByID(ctx context.Context, accountID int) (domain.Account, error) {
+	var accountModel models.AccountModel
+	query := `SELECT a.id, a.user_id, a.balance, a.hold_amount, a.currency, ` + accountBlockedColumn + `, a.account_type, a.created_at, a.updated_at
+			  FROM accounts a WHERE a.id = $1`
+	err := r.db.GetContext(ctx, &accountModel, query, accountID)
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			return domain.Account{}, errs.ErrAccountNotFound
+		}
+		return domain.Account{}, r.translateError(err)
+	}
+	return accountModel.ToDomain(), nil
+}
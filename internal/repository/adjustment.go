@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/logger"
+	"github.com/MMII0220/MiniBank/internal/pglock"
+	"github.com/jmoiron/sqlx"
+)
+
+// AdjustBalance проводит ручную корректировку в одной транзакции: проверка дневного потолка админа (ceilingTJS),
+// изменение баланса, транзакция adjustment, запись в balance_adjustments и account_audit.
+// Корректировки одного админа сериализуются advisory lock, иначе параллельные запросы обойдут потолок.
+func (r *Repository) AdjustBalance(ctx context.Context, adj domain.BalanceAdjustment, ceilingTJS float64, reqLogs domain.AdminAuditLog) (domain.BalanceAdjustment, error) {
+	log := logger.GetLogger()
+	log.Info().Int("account_id", adj.AccountID).Int("admin_id", adj.AdminID).Str("direction", string(adj.Direction)).
+		Float64("amount", adj.Amount).Msg("Adjusting account balance")
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return adj, r.translateError(err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1, $2)`, pglock.AdjustmentCeiling, adj.AdminID); err != nil {
+		return adj, r.translateError(err)
+	}
+	var usedTJS float64
+	err = tx.GetContext(ctx, &usedTJS, `SELECT COALESCE(SUM(amount_tjs), 0) FROM balance_adjustments
+		WHERE admin_id = $1 AND created_at >= date_trunc('day', NOW())`, adj.AdminID)
+	if err != nil {
+		return adj, r.translateError(err)
+	}
+	if usedTJS+adj.AmountTJS > ceilingTJS {
+		return adj, errs.ErrAdjustmentCeiling
+	}
+
+	if adj, err = r.postAdjustment(ctx, tx, adj); err != nil {
+		return adj, err
+	}
+
+	reqLogs.Reason = fmt.Sprintf("%s; balance %.2f -> %.2f", reqLogs.Reason, adj.BalanceBefore, adj.BalanceAfter)
+	if err = r.insertAuditLog(ctx, tx, reqLogs); err != nil {
+		return adj, err
+	}
+	if err = tx.Commit(); err != nil {
+		return adj, r.translateError(err)
+	}
+
+	r.dropAccountsCache(ctx, adj.AccountID)
+	return adj, nil
+}
+
+// postAdjustment проводит корректировку в транзакции tx: блокирует счет, перечитывает ограничения, проверяет доступный баланс,
+// меняет баланс и пишет транзакцию adjustment и строку balance_adjustments с балансами до и после.
+// Общий путь для AdjustBalance и одобренных manual_adjustment.
+func (r *Repository) postAdjustment(ctx context.Context, tx *sqlx.Tx, adj domain.BalanceAdjustment) (domain.BalanceAdjustment, error) {
+	var account struct {
+		Balance    float64 `db:"balance"`
+		HoldAmount float64 `db:"hold_amount"`
+		Currency   string  `db:"currency"`
+	}
+	err := tx.GetContext(ctx, &account, `SELECT balance, hold_amount, currency FROM accounts WHERE id = $1 FOR UPDATE`, adj.AccountID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return adj, errs.ErrAccountNotFound
+	}
+	if err != nil {
+		return adj, r.translateError(err)
+	}
+	// Корректировка подчиняется ограничениям счета, как и операции клиента
+	var legalHold float64
+	if adj.SignedAmount() > 0 {
+		err = r.checkCreditTx(ctx, tx, adj.AccountID)
+	} else {
+		legalHold, err = r.checkDebitTx(ctx, tx, adj.AccountID)
+	}
+	if err != nil {
+		return adj, err
+	}
+	// Списание не может увести доступный баланс (за вычетом legal_hold) в минус
+	if account.Balance-account.HoldAmount-legalHold+adj.SignedAmount() < 0 {
+		return adj, errs.ErrInsufficientFunds
+	}
+	adj.Currency = account.Currency
+	adj.BalanceBefore = account.Balance
+
+	err = tx.GetContext(ctx, &adj.BalanceAfter, `UPDATE accounts SET balance = balance + $1, updated_at = NOW() WHERE id = $2 RETURNING balance`,
+		adj.SignedAmount(), adj.AccountID)
+	if err != nil {
+		return adj, r.translateError(err)
+	}
+	err = tx.GetContext(ctx, &adj.TransactionID, `INSERT INTO transactions (account_id, amount, currency, type) VALUES ($1, $2, $3, 'adjustment') RETURNING id`,
+		adj.AccountID, adj.SignedAmount(), adj.Currency)
+	if err != nil {
+		return adj, r.translateError(err)
+	}
+	row := tx.QueryRowxContext(ctx, `INSERT INTO balance_adjustments (account_id, transaction_id, admin_id, direction, amount, currency, amount_tjs,
+			reason_code, note, balance_before, balance_after)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id, created_at`,
+		adj.AccountID, adj.TransactionID, adj.AdminID, string(adj.Direction), adj.Amount, adj.Currency, adj.AmountTJS,
+		string(adj.ReasonCode), adj.Note, adj.BalanceBefore, adj.BalanceAfter)
+	if err = row.Scan(&adj.ID, &adj.CreatedAt); err != nil {
+		return adj, r.translateError(err)
+	}
+	return adj, nil
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/logger"
+	"github.com/MMII0220/MiniBank/internal/repository/models"
+)
+
+// SearchUsers ищет пользователей по любому из условий фильтра; без условий возвращает всех постранично
+func (r *Repository) SearchUsers(ctx context.Context, filter domain.UserSearchFilter) ([]domain.User, error) {
+	var userModels []models.UserModel
+	query := `SELECT u.id, u.full_name, u.phone, u.email, u.role, u.created_at, COALESCE(u.updated_at, u.created_at) AS updated_at,
+			u.email_verified_at, u.phone_verified_at, u.disabled_at
+		FROM users u
+		WHERE ($1 = '' AND $2 = '' AND $3 = '' AND $4 = '')
+			OR ($1 <> '' AND LOWER(u.full_name) LIKE $1)
+			OR ($2 <> '' AND u.email LIKE $2)
+			OR ($3 <> '' AND u.phone = $3)
+			OR ($4 <> '' AND EXISTS (SELECT 1 FROM cards c JOIN accounts a ON a.id = c.account_id
+				WHERE a.user_id = u.id AND c.card_number = $4))
+		ORDER BY u.id
+		LIMIT $5 OFFSET $6`
+	err := r.db.SelectContext(ctx, &userModels, query,
+		filter.NamePattern, filter.EmailPattern, filter.Phone, filter.CardNumber, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, r.translateError(err)
+	}
+
+	users := make([]domain.User, len(userModels))
+	for i, userModel := range userModels {
+		users[i] = userModel.ToDomain()
+	}
+	return users, nil
+}
+
+// SetUserDisabled отключает или включает пользователя и пишет событие в аудит одной транзакцией
+func (r *Repository) SetUserDisabled(ctx context.Context, userID int, disabled bool, reqLogs domain.AdminAuditLog) error {
+	log := logger.GetLogger()
+	log.Info().Int("user_id", userID).Bool("disabled", disabled).Int("admin_id", reqLogs.AdminID).Msg("Updating user disabled status")
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return r.translateError(err)
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE users SET disabled_at = NOW(), updated_at = NOW() WHERE id = $1 AND disabled_at IS NULL`
+	if !disabled {
+		query = `UPDATE users SET disabled_at = NULL, updated_at = NOW() WHERE id = $1 AND disabled_at IS NOT NULL`
+	}
+	res, err := tx.ExecContext(ctx, query, userID)
+	if err != nil {
+		return r.translateError(err)
+	}
+	// Уже отключен (или уже включен)
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return errs.ErrInvalidOperation
+	}
+
+	if err = r.insertAuditLog(ctx, tx, reqLogs); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return r.translateError(err)
+	}
+	return nil
+}
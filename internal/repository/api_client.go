@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/logger"
+	"github.com/MMII0220/MiniBank/internal/repository/models"
+)
+
+const apiClientColumns = `id, client_id, name, secret_hash, owner_user_id, scopes, rate_limit_per_minute,
+	created_by, created_at, last_used_at, revoked_at`
+
+// CreateAPIClient сохраняет клиента и пишет событие в аудит одной транзакцией
+func (r *Repository) CreateAPIClient(ctx context.Context, client *domain.APIClient, reqLogs domain.AdminAuditLog) error {
+	log := logger.GetLogger()
+	log.Info().Str("client_id", client.ClientID).Int("owner_user_id", client.OwnerUserID).Int("created_by", client.CreatedBy).Msg("Creating API client")
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return r.translateError(err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `INSERT INTO api_clients (client_id, name, secret_hash, owner_user_id, scopes, rate_limit_per_minute, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at`,
+		client.ClientID, client.Name, client.SecretHash, client.OwnerUserID, domain.FormatScopes(client.Scopes),
+		client.RateLimitPerMinute, client.CreatedBy,
+	).Scan(&client.ID, &client.CreatedAt)
+	if err != nil {
+		return r.translateError(err)
+	}
+
+	reqLogs.APIClientID = client.ID
+	if err = r.insertAuditLog(ctx, tx, reqLogs); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return r.translateError(err)
+	}
+	return nil
+}
+
+func (r *Repository) GetAPIClientByClientID(ctx context.Context, clientID string) (domain.APIClient, error) {
+	return r.getAPIClient(ctx, `SELECT `+apiClientColumns+` FROM api_clients WHERE client_id = $1`, clientID)
+}
+
+func (r *Repository) GetAPIClientByID(ctx context.Context, id int) (domain.APIClient, error) {
+	return r.getAPIClient(ctx, `SELECT `+apiClientColumns+` FROM api_clients WHERE id = $1`, id)
+}
+
+func (r *Repository) getAPIClient(ctx context.Context, query string, arg interface{}) (domain.APIClient, error) {
+	var clientModel models.APIClientModel
+	if err := r.db.GetContext(ctx, &clientModel, query, arg); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.APIClient{}, errs.ErrAPIClientNotFound
+		}
+		return domain.APIClient{}, r.translateError(err)
+	}
+	return clientModel.ToDomain(), nil
+}
+
+func (r *Repository) GetAPIClients(ctx context.Context) ([]domain.APIClient, error) {
+	var clientModels []models.APIClientModel
+	if err := r.db.SelectContext(ctx, &clientModels, `SELECT `+apiClientColumns+` FROM api_clients ORDER BY created_at DESC`); err != nil {
+		return nil, r.translateError(err)
+	}
+
+	clients := make([]domain.APIClient, len(clientModels))
+	for i, clientModel := range clientModels {
+		clients[i] = clientModel.ToDomain()
+	}
+	return clients, nil
+}
+
+// RevokeAPIClient отзывает клиента; его токены перестают приниматься сразу
+func (r *Repository) RevokeAPIClient(ctx context.Context, id int, reqLogs domain.AdminAuditLog) error {
+	log := logger.GetLogger()
+	log.Info().Int("api_client_id", id).Int("admin_id", reqLogs.AdminID).Msg("Revoking API client")
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return r.translateError(err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE api_clients SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`, id)
+	if err != nil {
+		return r.translateError(err)
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return errs.ErrAPIClientNotFound
+	}
+
+	reqLogs.APIClientID = id
+	if err = r.insertAuditLog(ctx, tx, reqLogs); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return r.translateError(err)
+	}
+	return nil
+}
+
+// TouchAPIClient обновляет время последней выдачи токена
+func (r *Repository) TouchAPIClient(ctx context.Context, id int) error {
+	if _, err := r.db.ExecContext(ctx, `UPDATE api_clients SET last_used_at = NOW() WHERE id = $1`, id); err != nil {
+		return r.translateError(err)
+	}
+	return nil
+}
+
+// CreateAuditLog пишет одиночную запись аудита вне бизнес-транзакции (запросы API клиентов)
+func (r *Repository) CreateAuditLog(ctx context.Context, reqLogs domain.AdminAuditLog) error {
+	logModel := models.AdminAuditLogFromDomain(reqLogs)
+	_, err := r.db.ExecContext(ctx, `INSERT INTO account_audit (account_id, admin_id, action, reason, api_client_id) VALUES ($1, $2, $3, $4, NULLIF($5, 0))`,
+		logModel.AccountID, logModel.AdminID, logModel.Action, logModel.Reason, logModel.APIClientID)
+	if err != nil {
+		return r.translateError(err)
+	}
+	return nil
+}
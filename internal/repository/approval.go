@@ -0,0 +1,298 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/logger"
+	"github.com/MMII0220/MiniBank/internal/redis"
+	"github.com/MMII0220/MiniBank/internal/repository/models"
+)
+
+const pendingTransferColumns = `id, from_account_id, to_account_id, amount, fee, currency, initiator_id, status,
+		decided_by, decision_note, expires_at, created_at, decided_at`
+
+// CreatePendingTransfer удерживает средства на счете отправителя и создает перевод на одобрении
+func (r *Repository) CreatePendingTransfer(ctx context.Context, pt *domain.PendingTransfer) error {
+	log := logger.GetLogger()
+	log.Info().
+		Int("from_account_id", pt.FromAccountID).
+		Int("to_account_id", pt.ToAccountID).
+		Float64("amount", pt.Amount).
+		Float64("fee", pt.Fee).
+		Msg("Creating pending transfer")
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return r.translateError(err)
+	}
+	defer tx.Rollback()
+
+	if err = r.lockAccounts(ctx, tx, pt.FromAccountID); err != nil {
+		return err
+	}
+	legalHold, err := r.checkDebitTx(ctx, tx, pt.FromAccountID)
+	if err != nil {
+		return err
+	}
+
+	// Удерживаем сумму с комиссией, если хватает доступного баланса за вычетом legal_hold
+	res, err := tx.ExecContext(ctx, `UPDATE accounts SET hold_amount = hold_amount + $1 WHERE id = $2 AND balance - hold_amount - $3 >= $1`,
+		pt.Total(), pt.FromAccountID, legalHold)
+	if err != nil {
+		return r.translateError(err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return r.translateError(err)
+	}
+	if rowsAffected == 0 {
+		return errs.ErrInsufficientFunds
+	}
+
+	ptModel := models.PendingTransferFromDomain(*pt)
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO pending_transfers (from_account_id, to_account_id, amount, fee, currency, initiator_id, status, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, 'pending', $7)
+		RETURNING id, created_at`,
+		ptModel.FromAccountID, ptModel.ToAccountID, ptModel.Amount, ptModel.Fee, ptModel.Currency, ptModel.InitiatorID, ptModel.ExpiresAt,
+	).Scan(&ptModel.ID, &ptModel.CreatedAt)
+	if err != nil {
+		return r.translateError(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return r.translateError(err)
+	}
+
+	if cacheErr := redis.DeleteAccountCacheByAccountID(ctx, pt.FromAccountID); cacheErr != nil {
+		log.Warn().Err(cacheErr).Int("account_id", pt.FromAccountID).Msg("Failed to delete account cache after hold")
+	}
+
+	pt.ID = ptModel.ID
+	pt.Status = domain.PendingTransferPending
+	pt.CreatedAt = ptModel.CreatedAt
+	return nil
+}
+
+func (r *Repository) GetPendingTransferByID(ctx context.Context, id int) (domain.PendingTransfer, error) {
+	var ptModel models.PendingTransferModel
+	query := `SELECT ` + pendingTransferColumns + ` FROM pending_transfers WHERE id = $1`
+	err := r.db.GetContext(ctx, &ptModel, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.PendingTransfer{}, errs.ErrPendingTransferNotFound
+		}
+		return domain.PendingTransfer{}, r.translateError(err)
+	}
+	return ptModel.ToDomain(), nil
+}
+
+// GetPendingTransfers возвращает переводы по статусу, пустой статус - все
+func (r *Repository) GetPendingTransfers(ctx context.Context, status domain.PendingTransferStatus) ([]domain.PendingTransfer, error) {
+	query := `SELECT ` + pendingTransferColumns + `
+		FROM pending_transfers
+		WHERE ($1 = '' OR status = $1)
+		ORDER BY created_at DESC`
+	return r.selectPendingTransfers(ctx, query, string(status))
+}
+
+// GetPendingTransfersForSignatory - переводы с бизнес-счетов, которые пользователь может одобрить как второй подписант
+func (r *Repository) GetPendingTransfersForSignatory(ctx context.Context, userID int) ([]domain.PendingTransfer, error) {
+	query := `SELECT ` + pendingTransferColumns + `
+		FROM pending_transfers
+		WHERE status = 'pending'
+		AND initiator_id <> $1
+		AND from_account_id IN (
+			SELECT s.account_id FROM account_signatories s
+			JOIN accounts a ON a.id = s.account_id
+			WHERE s.user_id = $1 AND a.account_type = 'business'
+		)
+		ORDER BY created_at DESC`
+	return r.selectPendingTransfers(ctx, query, userID)
+}
+
+// GetExpiredPendingTransfers - переводы на одобрении, срок которых истек
+func (r *Repository) GetExpiredPendingTransfers(ctx context.Context) ([]domain.PendingTransfer, error) {
+	query := `SELECT ` + pendingTransferColumns + `
+		FROM pending_transfers
+		WHERE status = 'pending' AND expires_at <= NOW()
+		ORDER BY expires_at`
+	return r.selectPendingTransfers(ctx, query)
+}
+
+func (r *Repository) selectPendingTransfers(ctx context.Context, query string, args ...interface{}) ([]domain.PendingTransfer, error) {
+	var ptModels []models.PendingTransferModel
+	if err := r.db.SelectContext(ctx, &ptModels, query, args...); err != nil {
+		return nil, r.translateError(err)
+	}
+
+	transfers := make([]domain.PendingTransfer, len(ptModels))
+	for i, m := range ptModels {
+		transfers[i] = m.ToDomain()
+	}
+	return transfers, nil
+}
+
+// ApprovePendingTransfer снимает удержание, проводит перевод и фиксирует решение в аудите - в одной транзакции
+func (r *Repository) ApprovePendingTransfer(ctx context.Context, pt domain.PendingTransfer, approverID int, note string, reqLogs domain.AdminAuditLog) error {
+	log := logger.GetLogger()
+	log.Info().Int("pending_transfer_id", pt.ID).Int("approver_id", approverID).Msg("Approving pending transfer")
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return r.translateError(err)
+	}
+	defer tx.Rollback()
+
+	// Меняем статус первым: защищает от двойного одобрения
+	res, err := tx.ExecContext(ctx, `UPDATE pending_transfers SET status = 'approved', decided_by = $1, decision_note = $2, decided_at = NOW()
+		WHERE id = $3 AND status = 'pending'`, approverID, note, pt.ID)
+	if err != nil {
+		return r.translateError(err)
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return errs.ErrPendingTransferNotFound
+	}
+
+	// Ограничения могли появиться, пока перевод ждал решения: перечитываем их под блокировкой обоих счетов
+	if err = r.lockAccounts(ctx, tx, pt.FromAccountID, pt.ToAccountID); err != nil {
+		return err
+	}
+	legalHold, err := r.checkDebitTx(ctx, tx, pt.FromAccountID)
+	if err != nil {
+		return err
+	}
+	if err = r.checkCreditTx(ctx, tx, pt.ToAccountID); err != nil {
+		return err
+	}
+
+	// Списываем удержанную сумму вместе с комиссией; остаток после списания должен покрывать legal_hold
+	res, err = tx.ExecContext(ctx, `UPDATE accounts SET balance = balance - $1, hold_amount = hold_amount - $1
+		WHERE id = $2 AND hold_amount >= $1 AND balance - hold_amount >= $3`, pt.Total(), pt.FromAccountID, legalHold)
+	if err != nil {
+		return r.translateError(err)
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return errs.ErrInsufficientFunds
+	}
+
+	res, err = tx.ExecContext(ctx, `UPDATE accounts SET balance = balance + $1 WHERE id = $2`, pt.Amount, pt.ToAccountID)
+	if err != nil {
+		return r.translateError(err)
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return errs.ErrAccountNotFound
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO transactions (account_id, amount, currency, type, fee) VALUES ($1, $2, $3, 'transfer', $4)`,
+		pt.FromAccountID, pt.Total(), pt.Currency, pt.Fee)
+	if err != nil {
+		return r.translateError(err)
+	}
+
+	if err = r.insertAuditLog(ctx, tx, reqLogs); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return r.translateError(err)
+	}
+
+	r.dropAccountsCache(ctx, pt.FromAccountID, pt.ToAccountID)
+	return nil
+}
+
+// ReleasePendingTransfer снимает удержание без перевода (отклонение или истечение срока)
+func (r *Repository) ReleasePendingTransfer(ctx context.Context, pt domain.PendingTransfer, status domain.PendingTransferStatus, deciderID int, note string, reqLogs domain.AdminAuditLog) error {
+	log := logger.GetLogger()
+	log.Info().Int("pending_transfer_id", pt.ID).Str("status", string(status)).Msg("Releasing pending transfer hold")
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return r.translateError(err)
+	}
+	defer tx.Rollback()
+
+	decidedBy := sql.NullInt64{Int64: int64(deciderID), Valid: deciderID != 0}
+	res, err := tx.ExecContext(ctx, `UPDATE pending_transfers SET status = $1, decided_by = $2, decision_note = $3, decided_at = NOW()
+		WHERE id = $4 AND status = 'pending'`, string(status), decidedBy, note, pt.ID)
+	if err != nil {
+		return r.translateError(err)
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return errs.ErrPendingTransferNotFound
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE accounts SET hold_amount = GREATEST(hold_amount - $1, 0) WHERE id = $2`, pt.Total(), pt.FromAccountID)
+	if err != nil {
+		return r.translateError(err)
+	}
+
+	if err = r.insertAuditLog(ctx, tx, reqLogs); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return r.translateError(err)
+	}
+
+	r.dropAccountsCache(ctx, pt.FromAccountID)
+	return nil
+}
+
+// IsAccountSignatory проверяет, что пользователь - уполномоченное лицо бизнес-счета
+func (r *Repository) IsAccountSignatory(ctx context.Context, accountID, userID int) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (
+		SELECT 1 FROM account_signatories s
+		JOIN accounts a ON a.id = s.account_id
+		WHERE s.account_id = $1 AND s.user_id = $2 AND a.account_type = 'business'
+	)`
+	if err := r.db.GetContext(ctx, &exists, query, accountID, userID); err != nil {
+		return false, r.translateError(err)
+	}
+	return exists, nil
+}
+
+// AddAccountSignatory переводит счет в бизнес-режим и добавляет уполномоченное лицо
+func (r *Repository) AddAccountSignatory(ctx context.Context, accountID, userID int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return r.translateError(err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE accounts SET account_type = 'business', updated_at = NOW() WHERE id = $1`, accountID)
+	if err != nil {
+		return r.translateError(err)
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return errs.ErrAccountNotFound
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO account_signatories (account_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, accountID, userID)
+	if err != nil {
+		return r.translateError(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return r.translateError(err)
+	}
+
+	r.dropAccountsCache(ctx, accountID)
+	return nil
+}
+
+// dropAccountsCache удаляет кеш счетов после изменения балансов
+func (r *Repository) dropAccountsCache(ctx context.Context, accountIDs ...int) {
+	log := logger.GetLogger()
+	for _, accountID := range accountIDs {
+		if cacheErr := redis.DeleteAccountCacheByAccountID(ctx, accountID); cacheErr != nil {
+			log.Warn().Err(cacheErr).Int("account_id", accountID).Msg("Failed to delete account cache")
+		}
+	}
+}
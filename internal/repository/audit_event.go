@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/logger"
+	"github.com/MMII0220/MiniBank/internal/pglock"
+	"github.com/MMII0220/MiniBank/internal/repository/models"
+)
+
+const auditEventColumns = `id, occurred_at, actor_id, actor_type, api_client_id, action, outcome, target_type, target_id,
+	before_state, after_state, request_id, ip, user_agent, COALESCE(prev_hash, '') AS prev_hash, hash`
+
+// AppendAuditEvent добавляет событие в конец цепочки: id и hash назначаются под блокировкой
+func (r *Repository) AppendAuditEvent(ctx context.Context, event domain.AuditEvent) (domain.AuditEvent, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return event, r.translateError(err)
+	}
+	defer tx.Rollback()
+
+	// Цепочка строится строго последовательно; блокировка снимается вместе с commit/rollback
+	if _, err = tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, pglock.AuditChain); err != nil {
+		return event, r.translateError(err)
+	}
+
+	var prevHash string
+	err = tx.GetContext(ctx, &prevHash, `SELECT hash FROM audit_events ORDER BY id DESC LIMIT 1`)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return event, r.translateError(err)
+	}
+	// id входит в hash, поэтому берем его из последовательности заранее
+	if err = tx.GetContext(ctx, &event.ID, `SELECT nextval('audit_events_id_seq')`); err != nil {
+		return event, r.translateError(err)
+	}
+	event.PrevHash = prevHash
+	event.Hash = event.ComputeHash()
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO audit_events (id, occurred_at, actor_id, actor_type, api_client_id, action, outcome,
+			target_type, target_id, before_state, after_state, request_id, ip, user_agent, prev_hash, hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, NULLIF($15, ''), $16)`,
+		event.ID, event.OccurredAt, event.ActorID, event.ActorType, event.APIClientID, event.Action, event.Outcome,
+		event.TargetType, event.TargetID, string(event.Before), string(event.After), event.RequestID, event.IP, event.UserAgent,
+		event.PrevHash, event.Hash)
+	if err != nil {
+		return event, r.translateError(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return event, r.translateError(err)
+	}
+	return event, nil
+}
+
+// GetAuditEventsAfter - следующая порция цепочки по возрастанию id, для проверки и выгрузки
+func (r *Repository) GetAuditEventsAfter(ctx context.Context, afterID int64, limit int) ([]domain.AuditEvent, error) {
+	log := logger.GetLogger()
+	log.Debug().Int64("after_id", afterID).Int("limit", limit).Msg("Retrieving audit events")
+
+	var eventModels []models.AuditEventModel
+	query := `SELECT ` + auditEventColumns + ` FROM audit_events WHERE id > $1 ORDER BY id LIMIT $2`
+	if err := r.db.SelectContext(ctx, &eventModels, query, afterID, limit); err != nil {
+		return nil, r.translateError(err)
+	}
+
+	events := make([]domain.AuditEvent, len(eventModels))
+	for i, m := range eventModels {
+		events[i] = m.ToDomain()
+	}
+	return events, nil
+}
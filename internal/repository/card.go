@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 
@@ -10,7 +11,7 @@ import (
 
 // Предполагается, что в БД есть unique index на cards.card_number
 // CREATE UNIQUE INDEX IF NOT EXISTS idx_cards_number ON cards(card_number);
-func (r *Repository) CreateCard(card *domain.Card) error {
+func (r *Repository) CreateCard(ctx context.Context, card *domain.Card) error {
 	cardModel := models.CardFromDomain(*card)
 	query := `
 		INSERT INTO cards (account_id, card_number, card_holder_name, expiry_date, cvv, created_at)
@@ -18,7 +19,7 @@ func (r *Repository) CreateCard(card *domain.Card) error {
 		RETURNING id
 	`
 
-	err := r.db.QueryRow(query,
+	err := r.db.QueryRowContext(ctx, query,
 		cardModel.AccountID,
 		cardModel.CardNumber,
 		cardModel.CardHolderName,
@@ -38,3 +39,21 @@ func (r *Repository) CreateCard(card *domain.Card) error {
 	card.ID = cardModel.ID
 	return nil
 }
+
+// GetCardsByUserID - карты всех счетов пользователя
+func (r *Repository) GetCardsByUserID(ctx context.Context, userID int) ([]domain.Card, error) {
+	var cardModels []models.CardModel
+	query := `SELECT c.id, c.account_id, c.card_number, COALESCE(c.card_holder_name, '') AS card_holder_name,
+			c.expiry_date, c.cvv, c.created_at, COALESCE(c.updated_at, c.created_at) AS updated_at
+		FROM cards c JOIN accounts a ON a.id = c.account_id
+		WHERE a.user_id = $1 ORDER BY c.id`
+	if err := r.db.SelectContext(ctx, &cardModels, query, userID); err != nil {
+		return nil, r.translateError(err)
+	}
+
+	cards := make([]domain.Card, len(cardModels))
+	for i, cardModel := range cardModels {
+		cards[i] = cardModel.ToDomain()
+	}
+	return cards, nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/logger"
+	"github.com/MMII0220/MiniBank/internal/pglock"
+	"github.com/MMII0220/MiniBank/internal/repository/models"
+)
+
+// customerActivityTypes - операции, которые инициирует сам клиент; корректировки админа активностью не считаются
+const customerActivityTypes = `('deposit', 'withdraw', 'transfer')`
+
+// MarkDormantAccounts ставит ограничение dormant счетам без активности клиента с inactiveSince.
+// Счет моложе inactiveSince и счет, реактивированный после inactiveSince, не трогаются.
+// Каждая пометка пишется в account_audit от системы (admin_id = 0). Возвращает помеченные счета.
+func (r *Repository) MarkDormantAccounts(ctx context.Context, inactiveSince time.Time, reason string) ([]int, error) {
+	log := logger.GetLogger()
+	log.Info().Time("inactive_since", inactiveSince).Msg("Scanning for dormant accounts")
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, r.translateError(err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, pglock.Dormancy); err != nil {
+		return nil, r.translateError(err)
+	}
+
+	var accountIDs []int
+	err = tx.SelectContext(ctx, &accountIDs, `WITH marked AS (
+			INSERT INTO account_restrictions (account_id, mode, reason_category, reason)
+			SELECT a.id, 'dormant', 'dormancy', $2 FROM accounts a
+			WHERE a.created_at < $1
+				AND NOT EXISTS (SELECT 1 FROM transactions t
+					WHERE t.account_id = a.id AND t.type IN `+customerActivityTypes+` AND t.created_at >= $1)
+				AND NOT EXISTS (SELECT 1 FROM account_restrictions r
+					WHERE r.account_id = a.id AND r.mode = 'dormant' AND (r.lifted_at IS NULL OR r.lifted_at >= $1))
+			RETURNING account_id
+		)
+		INSERT INTO account_audit (account_id, admin_id, action, reason)
+		SELECT account_id, 0, 'dormant', $2 FROM marked
+		RETURNING account_id`, inactiveSince, reason)
+	if err != nil {
+		return nil, r.translateError(err)
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, r.translateError(err)
+	}
+
+	r.dropAccountsCache(ctx, accountIDs...)
+	return accountIDs, nil
+}
+
+// GetDormantBalances - число спящих счетов и сумма остатков по валютам
+func (r *Repository) GetDormantBalances(ctx context.Context) ([]domain.DormantBalance, error) {
+	var balanceModels []models.DormantBalanceModel
+	err := r.db.SelectContext(ctx, &balanceModels, `SELECT a.currency, COUNT(*) AS accounts, COALESCE(SUM(a.balance), 0) AS balance
+		FROM accounts a
+		WHERE EXISTS (SELECT 1 FROM account_restrictions r
+			WHERE r.account_id = a.id AND r.mode = 'dormant' AND `+activeRestriction+`)
+		GROUP BY a.currency ORDER BY a.currency`)
+	if err != nil {
+		return nil, r.translateError(err)
+	}
+
+	balances := make([]domain.DormantBalance, len(balanceModels))
+	for i, m := range balanceModels {
+		balances[i] = m.ToDomain()
+	}
+	return balances, nil
+}
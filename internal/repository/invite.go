@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/logger"
+	"github.com/MMII0220/MiniBank/internal/repository/models"
+)
+
+// CreateStaffInvite сохраняет приглашение и пишет событие в аудит одной транзакцией
+func (r *Repository) CreateStaffInvite(ctx context.Context, invite *domain.StaffInvite, reqLogs domain.AdminAuditLog) error {
+	log := logger.GetLogger()
+	log.Info().Str("email", invite.Email).Str("role", string(invite.Role)).Int("invited_by", invite.InvitedBy).Msg("Creating staff invite")
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return r.translateError(err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `INSERT INTO staff_invites (email, role, token_hash, invited_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`,
+		invite.Email, string(invite.Role), invite.TokenHash, invite.InvitedBy, invite.ExpiresAt,
+	).Scan(&invite.ID, &invite.CreatedAt)
+	if err != nil {
+		return r.translateError(err)
+	}
+
+	if err = r.insertAuditLog(ctx, tx, reqLogs); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return r.translateError(err)
+	}
+	return nil
+}
+
+func (r *Repository) GetStaffInviteByTokenHash(ctx context.Context, tokenHash string) (domain.StaffInvite, error) {
+	var inviteModel models.StaffInviteModel
+	query := `SELECT id, email, role, token_hash, invited_by, expires_at, accepted_at, accepted_user_id, created_at
+		FROM staff_invites WHERE token_hash = $1`
+	if err := r.db.GetContext(ctx, &inviteModel, query, tokenHash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.StaffInvite{}, errs.ErrInviteNotFound
+		}
+		return domain.StaffInvite{}, r.translateError(err)
+	}
+	return inviteModel.ToDomain(), nil
+}
+
+// ClaimStaffInvite помечает приглашение использованным; повторно использовать его нельзя
+func (r *Repository) ClaimStaffInvite(ctx context.Context, inviteID int) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE staff_invites SET accepted_at = NOW() WHERE id = $1 AND accepted_at IS NULL`, inviteID)
+	if err != nil {
+		return r.translateError(err)
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return errs.ErrInvalidOperation
+	}
+	return nil
+}
+
+// CompleteStaffInvite связывает приглашение с созданным пользователем, userID = 0 - регистрация не удалась, приглашение снова доступно
+func (r *Repository) CompleteStaffInvite(ctx context.Context, inviteID int, userID int) error {
+	var err error
+	if userID == 0 {
+		_, err = r.db.ExecContext(ctx, `UPDATE staff_invites SET accepted_at = NULL WHERE id = $1 AND accepted_user_id IS NULL`, inviteID)
+	} else {
+		_, err = r.db.ExecContext(ctx, `UPDATE staff_invites SET accepted_user_id = $1 WHERE id = $2`, userID, inviteID)
+	}
+	if err != nil {
+		return r.translateError(err)
+	}
+	return nil
+}
+
+// CountUsersByRole - сколько пользователей с ролью (для первичной настройки админа)
+func (r *Repository) CountUsersByRole(ctx context.Context, role domain.Role) (int, error) {
+	var count int
+	if err := r.db.GetContext(ctx, &count, `SELECT COUNT(*) FROM users WHERE role = $1`, string(role)); err != nil {
+		return 0, r.translateError(err)
+	}
+	return count, nil
+}
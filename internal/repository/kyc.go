@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/logger"
+	"github.com/MMII0220/MiniBank/internal/repository/models"
+)
+
+const kycDocumentColumns = `id, user_id, doc_type, storage_key, file_name, content_type, size_bytes, sha256,
+	status, reviewed_by, review_note, reviewed_at, created_at`
+
+// GetKYCTier - текущий уровень KYC пользователя
+func (r *Repository) GetKYCTier(ctx context.Context, userID int) (domain.KYCTier, error) {
+	var tier string
+	if err := r.db.GetContext(ctx, &tier, `SELECT kyc_tier FROM users WHERE id = $1`, userID); err != nil {
+		return "", r.translateError(err)
+	}
+	return domain.KYCTier(tier), nil
+}
+
+// SetKYCTier меняет уровень и поднимает дневной лимит без комиссии до лимита уровня.
+// Лимит, повышенный админом выше уровня, не уменьшается.
+func (r *Repository) SetKYCTier(ctx context.Context, userID int, tier domain.KYCTier, dailyAmount float64) error {
+	log := logger.GetLogger()
+	log.Info().Int("user_id", userID).Str("tier", string(tier)).Msg("Setting KYC tier")
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return r.translateError(err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE users SET kyc_tier = $1, updated_at = NOW() WHERE id = $2`, string(tier), userID)
+	if err != nil {
+		return r.translateError(err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errs.ErrUserNotFound
+	}
+	if _, err = tx.ExecContext(ctx, `UPDATE limits SET daily_amount = GREATEST(daily_amount, $1) WHERE user_id = $2`, dailyAmount, userID); err != nil {
+		return r.translateError(err)
+	}
+	if err = tx.Commit(); err != nil {
+		return r.translateError(err)
+	}
+	return nil
+}
+
+// CreateKYCDocument сохраняет метаданные загруженного документа, статус - pending
+func (r *Repository) CreateKYCDocument(ctx context.Context, doc *domain.KYCDocument) error {
+	err := r.db.QueryRowxContext(ctx, `INSERT INTO kyc_documents (user_id, doc_type, storage_key, file_name, content_type, size_bytes, sha256)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, status, created_at`,
+		doc.UserID, string(doc.Type), doc.StorageKey, doc.FileName, doc.ContentType, doc.Size, doc.SHA256).
+		Scan(&doc.ID, &doc.Status, &doc.CreatedAt)
+	if err != nil {
+		return r.translateError(err)
+	}
+	return nil
+}
+
+func (r *Repository) GetKYCDocument(ctx context.Context, docID int) (domain.KYCDocument, error) {
+	var docModel models.KYCDocumentModel
+	err := r.db.GetContext(ctx, &docModel, `SELECT `+kycDocumentColumns+` FROM kyc_documents WHERE id = $1`, docID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.KYCDocument{}, errs.ErrKYCDocumentNotFound
+	}
+	if err != nil {
+		return domain.KYCDocument{}, r.translateError(err)
+	}
+	return docModel.ToDomain(), nil
+}
+
+// GetKYCDocuments - документы пользователя, от новых к старым
+func (r *Repository) GetKYCDocuments(ctx context.Context, userID int) ([]domain.KYCDocument, error) {
+	return r.selectKYCDocuments(ctx, `SELECT `+kycDocumentColumns+` FROM kyc_documents
+		WHERE user_id = $1 ORDER BY id DESC`, userID)
+}
+
+// GetKYCReviewQueue - очередь проверки от старых к новым, пустой статус - все документы
+func (r *Repository) GetKYCReviewQueue(ctx context.Context, status domain.KYCDocumentStatus) ([]domain.KYCDocument, error) {
+	return r.selectKYCDocuments(ctx, `SELECT `+kycDocumentColumns+` FROM kyc_documents
+		WHERE ($1 = '' OR status = $1) ORDER BY created_at, id`, string(status))
+}
+
+func (r *Repository) selectKYCDocuments(ctx context.Context, query string, args ...interface{}) ([]domain.KYCDocument, error) {
+	var docModels []models.KYCDocumentModel
+	if err := r.db.SelectContext(ctx, &docModels, query, args...); err != nil {
+		return nil, r.translateError(err)
+	}
+
+	docs := make([]domain.KYCDocument, len(docModels))
+	for i, m := range docModels {
+		docs[i] = m.ToDomain()
+	}
+	return docs, nil
+}
+
+// ReviewKYCDocument фиксирует решение админа, менять можно только документы в статусе pending
+func (r *Repository) ReviewKYCDocument(ctx context.Context, docID int, status domain.KYCDocumentStatus, adminID int, note string) error {
+	log := logger.GetLogger()
+	log.Info().
+		Int("document_id", docID).
+		Str("status", string(status)).
+		Int("admin_id", adminID).
+		Msg("Reviewing KYC document")
+
+	res, err := r.db.ExecContext(ctx, `UPDATE kyc_documents SET status = $1, reviewed_by = $2, review_note = $3, reviewed_at = NOW()
+		WHERE id = $4 AND status = 'pending'`, string(status), adminID, note, docID)
+	if err != nil {
+		return r.translateError(err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errs.ErrKYCDocumentNotFound
+	}
+	return nil
+}
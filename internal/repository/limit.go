@@ -1,21 +1,23 @@
 package repository
 
 import (
+	"context"
+
 	"github.com/MMII0220/MiniBank/internal/domain"
 	"github.com/MMII0220/MiniBank/internal/repository/models"
 )
 
-func (r *Repository) GetDailyLimitByUserID(userID int) (domain.Limit, error) {
+func (r *Repository) GetDailyLimitByUserID(ctx context.Context, userID int) (domain.Limit, error) {
 	var limitModel models.LimitModel
 	query := `SELECT id, user_id, daily_amount, last_reset FROM limits WHERE user_id = $1`
-	err := r.db.Get(&limitModel, query, userID)
+	err := r.db.GetContext(ctx, &limitModel, query, userID)
 	if err != nil {
 		return domain.Limit{}, r.translateError(err)
 	}
 	return limitModel.ToDomain(), nil
 }
 
-func (r *Repository) GetTodayUsageInTJS(userID int) (float64, error) {
+func (r *Repository) GetTodayUsageInTJS(ctx context.Context, userID int) (float64, error) {
 	// Курсы валют для конвертации в TJS (должны совпадать с service)
 	currencyRates := map[string]float64{
 		"TJS": 1.0,
@@ -33,7 +35,7 @@ func (r *Repository) GetTodayUsageInTJS(userID int) (float64, error) {
 	`
 
 	var transactions []models.TransactionData
-	err := r.db.Select(&transactions, query, userID)
+	err := r.db.SelectContext(ctx, &transactions, query, userID)
 	if err != nil {
 		return 0, r.translateError(err)
 	}
@@ -55,9 +57,9 @@ func (r *Repository) GetTodayUsageInTJS(userID int) (float64, error) {
 }
 
 // CreateDailyLimitForUser создает стандартный лимит для нового пользователя
-func (r *Repository) CreateDailyLimitForUser(userID int, dailyAmount float64) error {
+func (r *Repository) CreateDailyLimitForUser(ctx context.Context, userID int, dailyAmount float64) error {
 	query := `INSERT INTO limits (user_id, daily_amount, last_reset) VALUES ($1, $2, NOW())`
-	_, err := r.db.Exec(query, userID, dailyAmount)
+	_, err := r.db.ExecContext(ctx, query, userID, dailyAmount)
 	if err != nil {
 		return r.translateError(err)
 	}
@@ -65,9 +67,9 @@ func (r *Repository) CreateDailyLimitForUser(userID int, dailyAmount float64) er
 }
 
 // ResetDailyLimit сбрасывает дневной лимит (обновляет last_reset на сегодня)
-func (r *Repository) ResetDailyLimit(userID int) error {
+func (r *Repository) ResetDailyLimit(ctx context.Context, userID int) error {
 	query := `UPDATE limits SET last_reset = NOW() WHERE user_id = $1`
-	_, err := r.db.Exec(query, userID)
+	_, err := r.db.ExecContext(ctx, query, userID)
 	if err != nil {
 		return r.translateError(err)
 	}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/logger"
+	"github.com/MMII0220/MiniBank/internal/repository/models"
+)
+
+// RecordLoginAttempt пишет попытку входа в журнал
+func (r *Repository) RecordLoginAttempt(ctx context.Context, attempt domain.LoginAttempt) error {
+	userID := sql.NullInt64{Int64: int64(attempt.UserID), Valid: attempt.UserID != 0}
+	_, err := r.db.ExecContext(ctx, `INSERT INTO login_attempts (email, ip, user_id, success) VALUES ($1, $2, $3, $4)`,
+		attempt.Email, attempt.IP, userID, attempt.Success)
+	if err != nil {
+		return r.translateError(err)
+	}
+	return nil
+}
+
+// GetLoginFailureStats считает несброшенные неудачные попытки по email и все неудачи с IP начиная с since
+func (r *Repository) GetLoginFailureStats(ctx context.Context, email, ip string, since time.Time) (domain.LoginFailureStats, error) {
+	var statsModel models.LoginFailureStatsModel
+	query := `SELECT
+			COUNT(*) FILTER (WHERE email = $1 AND NOT cleared) AS email_failures,
+			COUNT(*) FILTER (WHERE ip = $2 AND $2 <> '') AS ip_failures,
+			MAX(created_at) FILTER (WHERE email = $1 AND NOT cleared) AS last_failure_at
+		FROM login_attempts
+		WHERE NOT success AND created_at > $3 AND (email = $1 OR ip = $2)`
+	if err := r.db.GetContext(ctx, &statsModel, query, email, ip, since); err != nil {
+		return domain.LoginFailureStats{}, r.translateError(err)
+	}
+	return statsModel.ToDomain(), nil
+}
+
+// ClearLoginFailures сбрасывает счетчик неудачных попыток по email
+func (r *Repository) ClearLoginFailures(ctx context.Context, email string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE login_attempts SET cleared = TRUE WHERE email = $1 AND NOT success AND NOT cleared`, email)
+	if err != nil {
+		return r.translateError(err)
+	}
+	return nil
+}
+
+// GetLoginLock возвращает блокировку входа по email; если блокировки нет - пустую структуру
+func (r *Repository) GetLoginLock(ctx context.Context, email string) (domain.LoginLock, error) {
+	var lockModel models.LoginLockModel
+	query := `SELECT email, user_id, failed_count, locked_until, created_at FROM login_lockouts WHERE email = $1`
+	if err := r.db.GetContext(ctx, &lockModel, query, email); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.LoginLock{}, nil
+		}
+		return domain.LoginLock{}, r.translateError(err)
+	}
+	return lockModel.ToDomain(), nil
+}
+
+// GetActiveLoginLocks - действующие блокировки входа
+func (r *Repository) GetActiveLoginLocks(ctx context.Context) ([]domain.LoginLock, error) {
+	var lockModels []models.LoginLockModel
+	query := `SELECT email, user_id, failed_count, locked_until, created_at
+		FROM login_lockouts
+		WHERE locked_until > NOW()
+		ORDER BY created_at DESC`
+	if err := r.db.SelectContext(ctx, &lockModels, query); err != nil {
+		return nil, r.translateError(err)
+	}
+
+	locks := make([]domain.LoginLock, len(lockModels))
+	for i, m := range lockModels {
+		locks[i] = m.ToDomain()
+	}
+	return locks, nil
+}
+
+// LockLogin блокирует вход по email и пишет событие в аудит в одной транзакции
+func (r *Repository) LockLogin(ctx context.Context, lock domain.LoginLock, reqLogs domain.AdminAuditLog) error {
+	log := logger.GetLogger()
+	log.Warn().Str("email", lock.Email).Time("locked_until", lock.LockedUntil).Msg("Locking login")
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return r.translateError(err)
+	}
+	defer tx.Rollback()
+
+	lockModel := models.LoginLockFromDomain(lock)
+	_, err = tx.ExecContext(ctx, `INSERT INTO login_lockouts (email, user_id, failed_count, locked_until) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (email) DO UPDATE SET user_id = EXCLUDED.user_id, failed_count = EXCLUDED.failed_count,
+			locked_until = EXCLUDED.locked_until, created_at = NOW()`,
+		lockModel.Email, lockModel.UserID, lockModel.FailedCount, lockModel.LockedUntil)
+	if err != nil {
+		return r.translateError(err)
+	}
+
+	// Счетчик начинается заново после окончания блокировки
+	_, err = tx.ExecContext(ctx, `UPDATE login_attempts SET cleared = TRUE WHERE email = $1 AND NOT success AND NOT cleared`, lock.Email)
+	if err != nil {
+		return r.translateError(err)
+	}
+
+	if err = r.insertAuditLog(ctx, tx, reqLogs); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return r.translateError(err)
+	}
+	return nil
+}
+
+// UnlockLogin снимает блокировку входа, сбрасывает неудачные попытки и пишет событие в аудит
+func (r *Repository) UnlockLogin(ctx context.Context, email string, reqLogs domain.AdminAuditLog) error {
+	log := logger.GetLogger()
+	log.Info().Str("email", email).Int("admin_id", reqLogs.AdminID).Msg("Unlocking login")
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return r.translateError(err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM login_lockouts WHERE email = $1`, email); err != nil {
+		return r.translateError(err)
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE login_attempts SET cleared = TRUE WHERE email = $1 AND NOT success AND NOT cleared`, email)
+	if err != nil {
+		return r.translateError(err)
+	}
+
+	if err = r.insertAuditLog(ctx, tx, reqLogs); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return r.translateError(err)
+	}
+	return nil
+}
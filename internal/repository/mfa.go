@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/logger"
+	"github.com/MMII0220/MiniBank/internal/repository/models"
+	"github.com/jmoiron/sqlx"
+)
+
+// GetMFAConfig возвращает настройки TOTP пользователя; если 2FA не подключалась - пустую структуру
+func (r *Repository) GetMFAConfig(ctx context.Context, userID int) (domain.MFAConfig, error) {
+	var configModel models.MFAConfigModel
+	query := `SELECT user_id, secret, enabled, last_used_step, created_at, enabled_at FROM user_mfa WHERE user_id = $1`
+	if err := r.db.GetContext(ctx, &configModel, query, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.MFAConfig{}, nil
+		}
+		return domain.MFAConfig{}, r.translateError(err)
+	}
+	return configModel.ToDomain(), nil
+}
+
+// SaveMFASecret сохраняет новый неподтвержденный секрет. Включенную 2FA перезаписать нельзя.
+func (r *Repository) SaveMFASecret(ctx context.Context, userID int, secret string) error {
+	log := logger.GetLogger()
+	log.Info().Int("user_id", userID).Msg("Saving pending TOTP secret")
+
+	res, err := r.db.ExecContext(ctx, `INSERT INTO user_mfa (user_id, secret) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, last_used_step = 0, created_at = NOW()
+		WHERE user_mfa.enabled = FALSE`, userID, secret)
+	if err != nil {
+		return r.translateError(err)
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return errs.ErrInvalidOperation
+	}
+	return nil
+}
+
+// EnableMFA включает 2FA после первого верного кода и выдает коды восстановления
+func (r *Repository) EnableMFA(ctx context.Context, userID int, step int64, recoveryCodeHashes []string) error {
+	log := logger.GetLogger()
+	log.Info().Int("user_id", userID).Msg("Enabling TOTP")
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return r.translateError(err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE user_mfa SET enabled = TRUE, enabled_at = NOW(), last_used_step = $1
+		WHERE user_id = $2 AND enabled = FALSE`, step, userID)
+	if err != nil {
+		return r.translateError(err)
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return errs.ErrInvalidOperation
+	}
+
+	if err = r.replaceRecoveryCodes(ctx, tx, userID, recoveryCodeHashes); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return r.translateError(err)
+	}
+	return nil
+}
+
+// UseMFAStep фиксирует использованный интервал TOTP; повторный или более старый код отклоняется
+func (r *Repository) UseMFAStep(ctx context.Context, userID int, step int64) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE user_mfa SET last_used_step = $1
+		WHERE user_id = $2 AND enabled = TRUE AND last_used_step < $1`, step, userID)
+	if err != nil {
+		return r.translateError(err)
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return errs.ErrInvalidMFACode
+	}
+	return nil
+}
+
+// UseRecoveryCode гасит неиспользованный код восстановления
+func (r *Repository) UseRecoveryCode(ctx context.Context, userID int, codeHash string) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE mfa_recovery_codes SET used_at = NOW()
+		WHERE id = (SELECT id FROM mfa_recovery_codes WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL LIMIT 1)`,
+		userID, codeHash)
+	if err != nil {
+		return r.translateError(err)
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return errs.ErrInvalidMFACode
+	}
+	return nil
+}
+
+// ReplaceRecoveryCodes заменяет все коды восстановления пользователя новыми
+func (r *Repository) ReplaceRecoveryCodes(ctx context.Context, userID int, recoveryCodeHashes []string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return r.translateError(err)
+	}
+	defer tx.Rollback()
+
+	if err = r.replaceRecoveryCodes(ctx, tx, userID, recoveryCodeHashes); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return r.translateError(err)
+	}
+	return nil
+}
+
+func (r *Repository) replaceRecoveryCodes(ctx context.Context, tx *sqlx.Tx, userID int, recoveryCodeHashes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return r.translateError(err)
+	}
+	for _, hash := range recoveryCodeHashes {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO mfa_recovery_codes (user_id, code_hash) VALUES ($1, $2)`, userID, hash); err != nil {
+			return r.translateError(err)
+		}
+	}
+	return nil
+}
+
+// DisableMFA отключает 2FA и удаляет коды восстановления
+func (r *Repository) DisableMFA(ctx context.Context, userID int) error {
+	log := logger.GetLogger()
+	log.Info().Int("user_id", userID).Msg("Disabling TOTP")
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return r.translateError(err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return r.translateError(err)
+	}
+	if _, err = tx.ExecContext(ctx, `DELETE FROM user_mfa WHERE user_id = $1`, userID); err != nil {
+		return r.translateError(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return r.translateError(err)
+	}
+	return nil
+}
@@ -11,23 +11,27 @@ import (
 
 // AccountModel для работы с аккаунтами в БД
 type AccountModel struct {
-	ID        int          `db:"id"`
-	UserID    int          `db:"user_id"`
-	Balance   float64      `db:"balance"`
-	Currency  string       `db:"currency"`
-	Blocked   bool         `db:"blocked"`
-	CreatedAt time.Time    `db:"created_at"`
-	UpdatedAt sql.NullTime `db:"updated_at"`
+	ID         int          `db:"id"`
+	UserID     int          `db:"user_id"`
+	Balance    float64      `db:"balance"`
+	HoldAmount float64      `db:"hold_amount"`
+	Currency   string       `db:"currency"`
+	Blocked    bool         `db:"blocked"`
+	Type       string       `db:"account_type"`
+	CreatedAt  time.Time    `db:"created_at"`
+	UpdatedAt  sql.NullTime `db:"updated_at"`
 }
 
 func (am *AccountModel) ToDomain() domain.Account {
 	return domain.Account{
-		ID:        am.ID,
-		UserID:    am.UserID,
-		Balance:   fmt.Sprintf("%.2f", am.Balance),
-		Currency:  am.Currency,
-		Blocked:   am.Blocked,
-		CreatedAt: am.CreatedAt,
+		ID:         am.ID,
+		UserID:     am.UserID,
+		Balance:    fmt.Sprintf("%.2f", am.Balance),
+		HoldAmount: fmt.Sprintf("%.2f", am.HoldAmount),
+		Currency:   am.Currency,
+		Blocked:    am.Blocked,
+		Type:       domain.AccountType(am.Type),
+		CreatedAt:  am.CreatedAt,
 		UpdatedAt: func() time.Time {
 			if am.UpdatedAt.Valid {
 				return am.UpdatedAt.Time
@@ -39,14 +43,17 @@ func (am *AccountModel) ToDomain() domain.Account {
 
 func AccountFromDomain(a domain.Account) AccountModel {
 	balance, _ := strconv.ParseFloat(a.Balance, 64)
+	holdAmount, _ := strconv.ParseFloat(a.HoldAmount, 64)
 
 	return AccountModel{
-		ID:        a.ID,
-		UserID:    a.UserID,
-		Balance:   balance,
-		Currency:  a.Currency,
-		Blocked:   a.Blocked,
-		CreatedAt: a.CreatedAt,
-		UpdatedAt: sql.NullTime{Time: a.UpdatedAt, Valid: !a.UpdatedAt.IsZero()},
+		ID:         a.ID,
+		UserID:     a.UserID,
+		Balance:    balance,
+		HoldAmount: holdAmount,
+		Currency:   a.Currency,
+		Blocked:    a.Blocked,
+		Type:       string(a.Type),
+		CreatedAt:  a.CreatedAt,
+		UpdatedAt:  sql.NullTime{Time: a.UpdatedAt, Valid: !a.UpdatedAt.IsZero()},
 	}
 }
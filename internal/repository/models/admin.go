@@ -8,32 +8,35 @@ import (
 
 // AdminAuditLogModel для работы с админскими логами в БД
 type AdminAuditLogModel struct {
-	ID        int       `db:"id"`
-	AccountID int       `db:"account_id"`
-	AdminID   int       `db:"admin_id"`
-	Action    string    `db:"action"`
-	Reason    string    `db:"reason"`
-	CreatedAt time.Time `db:"created_at"`
+	ID          int       `db:"id"`
+	AccountID   int       `db:"account_id"`
+	AdminID     int       `db:"admin_id"`
+	APIClientID int       `db:"api_client_id"`
+	Action      string    `db:"action"`
+	Reason      string    `db:"reason"`
+	CreatedAt   time.Time `db:"created_at"`
 }
 
 func (aal *AdminAuditLogModel) ToDomain() domain.AdminAuditLog {
 	return domain.AdminAuditLog{
-		ID:        aal.ID,
-		AccountID: aal.AccountID,
-		AdminID:   aal.AdminID,
-		Action:    aal.Action,
-		Reason:    aal.Reason,
-		CreatedAt: aal.CreatedAt,
+		ID:          aal.ID,
+		AccountID:   aal.AccountID,
+		AdminID:     aal.AdminID,
+		APIClientID: aal.APIClientID,
+		Action:      aal.Action,
+		Reason:      aal.Reason,
+		CreatedAt:   aal.CreatedAt,
 	}
 }
 
 func AdminAuditLogFromDomain(a domain.AdminAuditLog) AdminAuditLogModel {
 	return AdminAuditLogModel{
-		ID:        a.ID,
-		AccountID: a.AccountID,
-		AdminID:   a.AdminID,
-		Action:    a.Action,
-		Reason:    a.Reason,
-		CreatedAt: a.CreatedAt,
+		ID:          a.ID,
+		AccountID:   a.AccountID,
+		AdminID:     a.AdminID,
+		APIClientID: a.APIClientID,
+		Action:      a.Action,
+		Reason:      a.Reason,
+		CreatedAt:   a.CreatedAt,
 	}
 }
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+)
+
+// APIClientModel для работы с API клиентами в БД
+type APIClientModel struct {
+	ID                 int          `db:"id"`
+	ClientID           string       `db:"client_id"`
+	Name               string       `db:"name"`
+	SecretHash         string       `db:"secret_hash"`
+	OwnerUserID        int          `db:"owner_user_id"`
+	Scopes             string       `db:"scopes"`
+	RateLimitPerMinute int          `db:"rate_limit_per_minute"`
+	CreatedBy          int          `db:"created_by"`
+	CreatedAt          time.Time    `db:"created_at"`
+	LastUsedAt         sql.NullTime `db:"last_used_at"`
+	RevokedAt          sql.NullTime `db:"revoked_at"`
+}
+
+func (m *APIClientModel) ToDomain() domain.APIClient {
+	return domain.APIClient{
+		ID:                 m.ID,
+		ClientID:           m.ClientID,
+		Name:               m.Name,
+		SecretHash:         m.SecretHash,
+		OwnerUserID:        m.OwnerUserID,
+		Scopes:             domain.ParseScopes(m.Scopes),
+		RateLimitPerMinute: m.RateLimitPerMinute,
+		CreatedBy:          m.CreatedBy,
+		CreatedAt:          m.CreatedAt,
+		LastUsedAt:         m.LastUsedAt.Time,
+		RevokedAt:          m.RevokedAt.Time,
+	}
+}
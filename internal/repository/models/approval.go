@@ -0,0 +1,61 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+)
+
+// PendingTransferModel для работы с переводами на одобрении в БД
+type PendingTransferModel struct {
+	ID            int            `db:"id"`
+	FromAccountID int            `db:"from_account_id"`
+	ToAccountID   int            `db:"to_account_id"`
+	Amount        float64        `db:"amount"`
+	Fee           float64        `db:"fee"`
+	Currency      string         `db:"currency"`
+	InitiatorID   int            `db:"initiator_id"`
+	Status        string         `db:"status"`
+	DecidedBy     sql.NullInt64  `db:"decided_by"`
+	DecisionNote  sql.NullString `db:"decision_note"`
+	ExpiresAt     time.Time      `db:"expires_at"`
+	CreatedAt     time.Time      `db:"created_at"`
+	DecidedAt     sql.NullTime   `db:"decided_at"`
+}
+
+func (m *PendingTransferModel) ToDomain() domain.PendingTransfer {
+	return domain.PendingTransfer{
+		ID:            m.ID,
+		FromAccountID: m.FromAccountID,
+		ToAccountID:   m.ToAccountID,
+		Amount:        m.Amount,
+		Fee:           m.Fee,
+		Currency:      m.Currency,
+		InitiatorID:   m.InitiatorID,
+		Status:        domain.PendingTransferStatus(m.Status),
+		DecidedBy:     int(m.DecidedBy.Int64),
+		DecisionNote:  m.DecisionNote.String,
+		ExpiresAt:     m.ExpiresAt,
+		CreatedAt:     m.CreatedAt,
+		DecidedAt:     m.DecidedAt.Time,
+	}
+}
+
+func PendingTransferFromDomain(p domain.PendingTransfer) PendingTransferModel {
+	return PendingTransferModel{
+		ID:            p.ID,
+		FromAccountID: p.FromAccountID,
+		ToAccountID:   p.ToAccountID,
+		Amount:        p.Amount,
+		Fee:           p.Fee,
+		Currency:      p.Currency,
+		InitiatorID:   p.InitiatorID,
+		Status:        string(p.Status),
+		DecidedBy:     sql.NullInt64{Int64: int64(p.DecidedBy), Valid: p.DecidedBy != 0},
+		DecisionNote:  sql.NullString{String: p.DecisionNote, Valid: p.DecisionNote != ""},
+		ExpiresAt:     p.ExpiresAt,
+		CreatedAt:     p.CreatedAt,
+		DecidedAt:     sql.NullTime{Time: p.DecidedAt, Valid: !p.DecidedAt.IsZero()},
+	}
+}
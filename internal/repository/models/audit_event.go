@@ -0,0 +1,57 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+)
+
+// AuditEventModel для работы с журналом audit_events в БД
+type AuditEventModel struct {
+	ID          int64     `db:"id"`
+	OccurredAt  time.Time `db:"occurred_at"`
+	ActorID     int       `db:"actor_id"`
+	ActorType   string    `db:"actor_type"`
+	APIClientID int       `db:"api_client_id"`
+	Action      string    `db:"action"`
+	Outcome     string    `db:"outcome"`
+	TargetType  string    `db:"target_type"`
+	TargetID    string    `db:"target_id"`
+	BeforeState string    `db:"before_state"`
+	AfterState  string    `db:"after_state"`
+	RequestID   string    `db:"request_id"`
+	IP          string    `db:"ip"`
+	UserAgent   string    `db:"user_agent"`
+	PrevHash    string    `db:"prev_hash"`
+	Hash        string    `db:"hash"`
+}
+
+func (m *AuditEventModel) ToDomain() domain.AuditEvent {
+	return domain.AuditEvent{
+		ID:          m.ID,
+		OccurredAt:  m.OccurredAt,
+		ActorID:     m.ActorID,
+		ActorType:   domain.ActorType(m.ActorType),
+		APIClientID: m.APIClientID,
+		Action:      m.Action,
+		Outcome:     domain.AuditOutcome(m.Outcome),
+		TargetType:  m.TargetType,
+		TargetID:    m.TargetID,
+		Before:      rawState(m.BeforeState),
+		After:       rawState(m.AfterState),
+		RequestID:   m.RequestID,
+		IP:          m.IP,
+		UserAgent:   m.UserAgent,
+		PrevHash:    m.PrevHash,
+		Hash:        m.Hash,
+	}
+}
+
+// rawState - пустая строка в БД означает отсутствие снимка
+func rawState(s string) json.RawMessage {
+	if s == "" {
+		return nil
+	}
+	return json.RawMessage(s)
+}
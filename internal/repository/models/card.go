@@ -11,7 +11,7 @@ type CardModel struct {
 	ID             int       `db:"id"`
 	AccountID      int       `db:"account_id"`
 	CardNumber     string    `db:"card_number"`
-	CardHolderName string    `db:"cardholder_name"`
+	CardHolderName string    `db:"card_holder_name"`
 	ExpiryDate     time.Time `db:"expiry_date"`
 	CVV            string    `db:"cvv"`
 	CreatedAt      time.Time `db:"created_at"`
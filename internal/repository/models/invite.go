@@ -0,0 +1,35 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+)
+
+// StaffInviteModel для работы с приглашениями сотрудников в БД
+type StaffInviteModel struct {
+	ID             int           `db:"id"`
+	Email          string        `db:"email"`
+	Role           string        `db:"role"`
+	TokenHash      string        `db:"token_hash"`
+	InvitedBy      int           `db:"invited_by"`
+	ExpiresAt      time.Time     `db:"expires_at"`
+	AcceptedAt     sql.NullTime  `db:"accepted_at"`
+	AcceptedUserID sql.NullInt64 `db:"accepted_user_id"`
+	CreatedAt      time.Time     `db:"created_at"`
+}
+
+func (m *StaffInviteModel) ToDomain() domain.StaffInvite {
+	return domain.StaffInvite{
+		ID:             m.ID,
+		Email:          m.Email,
+		Role:           domain.Role(m.Role),
+		TokenHash:      m.TokenHash,
+		InvitedBy:      m.InvitedBy,
+		ExpiresAt:      m.ExpiresAt,
+		AcceptedAt:     m.AcceptedAt.Time,
+		AcceptedUserID: int(m.AcceptedUserID.Int64),
+		CreatedAt:      m.CreatedAt,
+	}
+}
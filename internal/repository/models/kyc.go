@@ -0,0 +1,43 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+)
+
+// KYCDocumentModel для работы с документами KYC в БД
+type KYCDocumentModel struct {
+	ID          int            `db:"id"`
+	UserID      int            `db:"user_id"`
+	Type        string         `db:"doc_type"`
+	StorageKey  string         `db:"storage_key"`
+	FileName    string         `db:"file_name"`
+	ContentType string         `db:"content_type"`
+	Size        int64          `db:"size_bytes"`
+	SHA256      string         `db:"sha256"`
+	Status      string         `db:"status"`
+	ReviewedBy  sql.NullInt64  `db:"reviewed_by"`
+	ReviewNote  sql.NullString `db:"review_note"`
+	ReviewedAt  sql.NullTime   `db:"reviewed_at"`
+	CreatedAt   time.Time      `db:"created_at"`
+}
+
+func (m *KYCDocumentModel) ToDomain() domain.KYCDocument {
+	return domain.KYCDocument{
+		ID:          m.ID,
+		UserID:      m.UserID,
+		Type:        domain.KYCDocumentType(m.Type),
+		StorageKey:  m.StorageKey,
+		FileName:    m.FileName,
+		ContentType: m.ContentType,
+		Size:        m.Size,
+		SHA256:      m.SHA256,
+		Status:      domain.KYCDocumentStatus(m.Status),
+		ReviewedBy:  int(m.ReviewedBy.Int64),
+		ReviewNote:  m.ReviewNote.String,
+		ReviewedAt:  m.ReviewedAt.Time,
+		CreatedAt:   m.CreatedAt,
+	}
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+)
+
+// LoginLockModel для работы с блокировками входа в БД
+type LoginLockModel struct {
+	Email       string        `db:"email"`
+	UserID      sql.NullInt64 `db:"user_id"`
+	FailedCount int           `db:"failed_count"`
+	LockedUntil time.Time     `db:"locked_until"`
+	CreatedAt   time.Time     `db:"created_at"`
+}
+
+func (m *LoginLockModel) ToDomain() domain.LoginLock {
+	return domain.LoginLock{
+		Email:       m.Email,
+		UserID:      int(m.UserID.Int64),
+		FailedCount: m.FailedCount,
+		LockedUntil: m.LockedUntil,
+		CreatedAt:   m.CreatedAt,
+	}
+}
+
+// LoginFailureStatsModel - агрегаты неудачных попыток входа
+type LoginFailureStatsModel struct {
+	EmailFailures int          `db:"email_failures"`
+	IPFailures    int          `db:"ip_failures"`
+	LastFailureAt sql.NullTime `db:"last_failure_at"`
+}
+
+func (m *LoginFailureStatsModel) ToDomain() domain.LoginFailureStats {
+	return domain.LoginFailureStats{
+		EmailFailures: m.EmailFailures,
+		IPFailures:    m.IPFailures,
+		LastFailureAt: m.LastFailureAt.Time,
+	}
+}
+
+func LoginLockFromDomain(l domain.LoginLock) LoginLockModel {
+	return LoginLockModel{
+		Email:       l.Email,
+		UserID:      sql.NullInt64{Int64: int64(l.UserID), Valid: l.UserID != 0},
+		FailedCount: l.FailedCount,
+		LockedUntil: l.LockedUntil,
+		CreatedAt:   l.CreatedAt,
+	}
+}
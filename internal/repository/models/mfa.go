@@ -0,0 +1,29 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+)
+
+// MFAConfigModel для работы с настройками TOTP в БД
+type MFAConfigModel struct {
+	UserID       int          `db:"user_id"`
+	Secret       string       `db:"secret"`
+	Enabled      bool         `db:"enabled"`
+	LastUsedStep int64        `db:"last_used_step"`
+	CreatedAt    time.Time    `db:"created_at"`
+	EnabledAt    sql.NullTime `db:"enabled_at"`
+}
+
+func (m *MFAConfigModel) ToDomain() domain.MFAConfig {
+	return domain.MFAConfig{
+		UserID:       m.UserID,
+		Secret:       m.Secret,
+		Enabled:      m.Enabled,
+		LastUsedStep: m.LastUsedStep,
+		CreatedAt:    m.CreatedAt,
+		EnabledAt:    m.EnabledAt.Time,
+	}
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+)
+
+// PasswordResetTokenModel для работы с токенами восстановления пароля в БД
+type PasswordResetTokenModel struct {
+	ID        int          `db:"id"`
+	UserID    int          `db:"user_id"`
+	TokenHash string       `db:"token_hash"`
+	ExpiresAt time.Time    `db:"expires_at"`
+	UsedAt    sql.NullTime `db:"used_at"`
+	CreatedAt time.Time    `db:"created_at"`
+}
+
+func (m *PasswordResetTokenModel) ToDomain() domain.PasswordResetToken {
+	return domain.PasswordResetToken{
+		ID:        m.ID,
+		UserID:    m.UserID,
+		TokenHash: m.TokenHash,
+		ExpiresAt: m.ExpiresAt,
+		UsedAt:    m.UsedAt.Time,
+		CreatedAt: m.CreatedAt,
+	}
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+)
+
+// PendingActionModel для работы с админскими действиями на одобрении в БД
+type PendingActionModel struct {
+	ID           int             `db:"id"`
+	Type         string          `db:"action_type"`
+	AccountID    sql.NullInt64   `db:"account_id"`
+	UserID       sql.NullInt64   `db:"user_id"`
+	Amount       sql.NullFloat64 `db:"amount"`
+	NewRole      sql.NullString  `db:"new_role"`
+	ReasonCode   sql.NullString  `db:"reason_code"`
+	Mode         sql.NullString  `db:"restriction_mode"`
+	Reason       string          `db:"reason"`
+	ProposedBy   int             `db:"proposed_by"`
+	Status       string          `db:"status"`
+	DecidedBy    sql.NullInt64   `db:"decided_by"`
+	DecisionNote sql.NullString  `db:"decision_note"`
+	ExpiresAt    time.Time       `db:"expires_at"`
+	CreatedAt    time.Time       `db:"created_at"`
+	DecidedAt    sql.NullTime    `db:"decided_at"`
+}
+
+func (m *PendingActionModel) ToDomain() domain.PendingAction {
+	return domain.PendingAction{
+		ID:              m.ID,
+		Type:            domain.PendingActionType(m.Type),
+		AccountID:       int(m.AccountID.Int64),
+		UserID:          int(m.UserID.Int64),
+		Amount:          m.Amount.Float64,
+		NewRole:         domain.Role(m.NewRole.String),
+		ReasonCode:      domain.AdjustmentReason(m.ReasonCode.String),
+		RestrictionMode: domain.RestrictionMode(m.Mode.String),
+		Reason:          m.Reason,
+		ProposedBy:      m.ProposedBy,
+		Status:          domain.PendingActionStatus(m.Status),
+		DecidedBy:       int(m.DecidedBy.Int64),
+		DecisionNote:    m.DecisionNote.String,
+		ExpiresAt:       m.ExpiresAt,
+		CreatedAt:       m.CreatedAt,
+		DecidedAt:       m.DecidedAt.Time,
+	}
+}
+
+func PendingActionFromDomain(a domain.PendingAction) PendingActionModel {
+	return PendingActionModel{
+		ID:           a.ID,
+		Type:         string(a.Type),
+		AccountID:    sql.NullInt64{Int64: int64(a.AccountID), Valid: a.AccountID != 0},
+		UserID:       sql.NullInt64{Int64: int64(a.UserID), Valid: a.UserID != 0},
+		Amount:       sql.NullFloat64{Float64: a.Amount, Valid: a.Amount != 0},
+		NewRole:      sql.NullString{String: string(a.NewRole), Valid: a.NewRole != ""},
+		ReasonCode:   sql.NullString{String: string(a.ReasonCode), Valid: a.ReasonCode != ""},
+		Mode:         sql.NullString{String: string(a.RestrictionMode), Valid: a.RestrictionMode != ""},
+		Reason:       a.Reason,
+		ProposedBy:   a.ProposedBy,
+		Status:       string(a.Status),
+		DecidedBy:    sql.NullInt64{Int64: int64(a.DecidedBy), Valid: a.DecidedBy != 0},
+		DecisionNote: sql.NullString{String: a.DecisionNote, Valid: a.DecisionNote != ""},
+		ExpiresAt:    a.ExpiresAt,
+		CreatedAt:    a.CreatedAt,
+		DecidedAt:    sql.NullTime{Time: a.DecidedAt, Valid: !a.DecidedAt.IsZero()},
+	}
+}
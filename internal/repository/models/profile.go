@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+)
+
+// ProfileChangeModel для работы с историей изменений профиля в БД
+type ProfileChangeModel struct {
+	ID        int       `db:"id"`
+	UserID    int       `db:"user_id"`
+	Field     string    `db:"field"`
+	OldValue  string    `db:"old_value"`
+	NewValue  string    `db:"new_value"`
+	ChangedBy int       `db:"changed_by"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func (m *ProfileChangeModel) ToDomain() domain.ProfileChange {
+	return domain.ProfileChange{
+		ID:        m.ID,
+		UserID:    m.UserID,
+		Field:     domain.ProfileField(m.Field),
+		OldValue:  m.OldValue,
+		NewValue:  m.NewValue,
+		ChangedBy: m.ChangedBy,
+		CreatedAt: m.CreatedAt,
+	}
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+)
+
+// AccountRestrictionModel для работы с ограничениями счетов в БД
+type AccountRestrictionModel struct {
+	ID        int             `db:"id"`
+	AccountID int             `db:"account_id"`
+	Mode      string          `db:"mode"`
+	Amount    sql.NullFloat64 `db:"amount"`
+	Category  string          `db:"reason_category"`
+	Reason    string          `db:"reason"`
+	CreatedBy sql.NullInt64   `db:"created_by"`
+	ExpiresAt sql.NullTime    `db:"expires_at"`
+	CreatedAt time.Time       `db:"created_at"`
+	LiftedBy  sql.NullInt64   `db:"lifted_by"`
+	LiftedAt  sql.NullTime    `db:"lifted_at"`
+}
+
+func (m *AccountRestrictionModel) ToDomain() domain.AccountRestriction {
+	return domain.AccountRestriction{
+		ID:        m.ID,
+		AccountID: m.AccountID,
+		Mode:      domain.RestrictionMode(m.Mode),
+		Amount:    m.Amount.Float64,
+		Category:  domain.RestrictionCategory(m.Category),
+		Reason:    m.Reason,
+		CreatedBy: int(m.CreatedBy.Int64),
+		ExpiresAt: m.ExpiresAt.Time,
+		CreatedAt: m.CreatedAt,
+		LiftedBy:  int(m.LiftedBy.Int64),
+		LiftedAt:  m.LiftedAt.Time,
+	}
+}
+
+func AccountRestrictionFromDomain(r domain.AccountRestriction) AccountRestrictionModel {
+	return AccountRestrictionModel{
+		ID:        r.ID,
+		AccountID: r.AccountID,
+		Mode:      string(r.Mode),
+		Amount:    sql.NullFloat64{Float64: r.Amount, Valid: r.Mode == domain.RestrictionLegalHold},
+		Category:  string(r.Category),
+		Reason:    r.Reason,
+		CreatedBy: sql.NullInt64{Int64: int64(r.CreatedBy), Valid: r.CreatedBy != 0},
+		ExpiresAt: sql.NullTime{Time: r.ExpiresAt, Valid: !r.ExpiresAt.IsZero()},
+		CreatedAt: r.CreatedAt,
+		LiftedBy:  sql.NullInt64{Int64: int64(r.LiftedBy), Valid: r.LiftedBy != 0},
+		LiftedAt:  sql.NullTime{Time: r.LiftedAt, Valid: !r.LiftedAt.IsZero()},
+	}
+}
+
+// DormantBalanceModel - строка отчета по остаткам на спящих счетах
+type DormantBalanceModel struct {
+	Currency string  `db:"currency"`
+	Accounts int     `db:"accounts"`
+	Balance  float64 `db:"balance"`
+}
+
+func (m *DormantBalanceModel) ToDomain() domain.DormantBalance {
+	return domain.DormantBalance{
+		Currency: m.Currency,
+		Accounts: m.Accounts,
+		Balance:  m.Balance,
+	}
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+)
+
+// ScreeningReviewModel для работы с очередью проверок по санкционным спискам в БД
+type ScreeningReviewModel struct {
+	ID          int            `db:"id"`
+	SubjectType string         `db:"subject_type"`
+	SubjectRef  string         `db:"subject_ref"`
+	SubjectName string         `db:"subject_name"`
+	MatchedName string         `db:"matched_name"`
+	ListSource  string         `db:"list_source"`
+	ListEntryID string         `db:"list_entry_id"`
+	Score       float64        `db:"score"`
+	Status      string         `db:"status"`
+	ReviewedBy  sql.NullInt64  `db:"reviewed_by"`
+	ReviewNote  sql.NullString `db:"review_note"`
+	CreatedAt   time.Time      `db:"created_at"`
+	ReviewedAt  sql.NullTime   `db:"reviewed_at"`
+}
+
+func (m *ScreeningReviewModel) ToDomain() domain.ScreeningReview {
+	return domain.ScreeningReview{
+		ID:          m.ID,
+		SubjectType: domain.ScreeningSubjectType(m.SubjectType),
+		SubjectRef:  m.SubjectRef,
+		SubjectName: m.SubjectName,
+		MatchedName: m.MatchedName,
+		ListSource:  m.ListSource,
+		ListEntryID: m.ListEntryID,
+		Score:       m.Score,
+		Status:      domain.ScreeningStatus(m.Status),
+		ReviewedBy:  int(m.ReviewedBy.Int64),
+		ReviewNote:  m.ReviewNote.String,
+		CreatedAt:   m.CreatedAt,
+		ReviewedAt:  m.ReviewedAt.Time,
+	}
+}
+
+func ScreeningReviewFromDomain(r domain.ScreeningReview) ScreeningReviewModel {
+	return ScreeningReviewModel{
+		ID:          r.ID,
+		SubjectType: string(r.SubjectType),
+		SubjectRef:  r.SubjectRef,
+		SubjectName: r.SubjectName,
+		MatchedName: r.MatchedName,
+		ListSource:  r.ListSource,
+		ListEntryID: r.ListEntryID,
+		Score:       r.Score,
+		Status:      string(r.Status),
+		ReviewedBy:  sql.NullInt64{Int64: int64(r.ReviewedBy), Valid: r.ReviewedBy != 0},
+		ReviewNote:  sql.NullString{String: r.ReviewNote, Valid: r.ReviewNote != ""},
+		CreatedAt:   r.CreatedAt,
+		ReviewedAt:  sql.NullTime{Time: r.ReviewedAt, Valid: !r.ReviewedAt.IsZero()},
+	}
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+)
+
+// SessionModel для работы с серверными сессиями в БД
+type SessionModel struct {
+	ID             string         `db:"id"`
+	UserID         int            `db:"user_id"`
+	DeviceID       string         `db:"device_id"`
+	UserAgent      string         `db:"user_agent"`
+	IP             string         `db:"ip"`
+	StepUpRequired bool           `db:"step_up_required"`
+	MFAVerified    bool           `db:"mfa_verified"`
+	StepUpCodeHash string         `db:"step_up_code_hash"`
+	StepUpCodeExp  sql.NullTime   `db:"step_up_code_expires_at"`
+	CreatedAt      time.Time      `db:"created_at"`
+	LastUsedAt     time.Time      `db:"last_used_at"`
+	ExpiresAt      time.Time      `db:"expires_at"`
+	RevokedAt      sql.NullTime   `db:"revoked_at"`
+	RevokeReason   sql.NullString `db:"revoke_reason"`
+}
+
+func (m *SessionModel) ToDomain() domain.Session {
+	return domain.Session{
+		ID:                  m.ID,
+		UserID:              m.UserID,
+		DeviceID:            m.DeviceID,
+		UserAgent:           m.UserAgent,
+		IP:                  m.IP,
+		StepUpRequired:      m.StepUpRequired,
+		MFAVerified:         m.MFAVerified,
+		StepUpCodeHash:      m.StepUpCodeHash,
+		StepUpCodeExpiresAt: m.StepUpCodeExp.Time,
+		CreatedAt:           m.CreatedAt,
+		LastUsedAt:          m.LastUsedAt,
+		ExpiresAt:           m.ExpiresAt,
+		RevokedAt:           m.RevokedAt.Time,
+		RevokeReason:        m.RevokeReason.String,
+	}
+}
+
+// RefreshTokenModel для работы с refresh токенами в БД
+type RefreshTokenModel struct {
+	ID        int          `db:"id"`
+	SessionID string       `db:"session_id"`
+	TokenHash string       `db:"token_hash"`
+	ExpiresAt time.Time    `db:"expires_at"`
+	UsedAt    sql.NullTime `db:"used_at"`
+	CreatedAt time.Time    `db:"created_at"`
+}
+
+func (m *RefreshTokenModel) ToDomain() domain.RefreshToken {
+	return domain.RefreshToken{
+		ID:        m.ID,
+		SessionID: m.SessionID,
+		TokenHash: m.TokenHash,
+		ExpiresAt: m.ExpiresAt,
+		UsedAt:    m.UsedAt.Time,
+		CreatedAt: m.CreatedAt,
+	}
+}
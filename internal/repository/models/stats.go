@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+)
+
+// DailyVolumeModel - строка агрегата оборотов по дням
+type DailyVolumeModel struct {
+	Day      time.Time `db:"day"`
+	Currency string    `db:"currency"`
+	Type     string    `db:"type"`
+	Count    int       `db:"count"`
+	Volume   float64   `db:"volume"`
+	Fees     float64   `db:"fees"`
+}
+
+func (m *DailyVolumeModel) ToDomain() domain.DailyVolume {
+	return domain.DailyVolume{
+		Day:      m.Day,
+		Currency: m.Currency,
+		Type:     domain.TransactionType(m.Type),
+		Count:    m.Count,
+		Volume:   m.Volume,
+		Fees:     m.Fees,
+	}
+}
+
+// LimitBreachModel - пользователь с операциями сверх лимита
+type LimitBreachModel struct {
+	UserID       int       `db:"user_id"`
+	FullName     string    `db:"full_name"`
+	Email        string    `db:"email"`
+	Breaches     int       `db:"breaches"`
+	LastBreachAt time.Time `db:"last_breach_at"`
+}
+
+func (m *LimitBreachModel) ToDomain() domain.LimitBreach {
+	return domain.LimitBreach{
+		UserID:       m.UserID,
+		FullName:     m.FullName,
+		Email:        m.Email,
+		Breaches:     m.Breaches,
+		LastBreachAt: m.LastBreachAt,
+	}
+}
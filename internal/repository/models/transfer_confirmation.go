@@ -0,0 +1,47 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+)
+
+// TransferConfirmationModel для работы с переводами, ожидающими кода, в БД
+type TransferConfirmationModel struct {
+	ID              int          `db:"id"`
+	UserID          int          `db:"user_id"`
+	FromCardNumber  string       `db:"from_card_number"`
+	FromPhoneNumber string       `db:"from_phone_number"`
+	ToCardNumber    string       `db:"to_card_number"`
+	ToPhoneNumber   string       `db:"to_phone_number"`
+	Amount          float64      `db:"amount"`
+	Currency        string       `db:"currency"`
+	CodeHash        string       `db:"code_hash"`
+	Attempts        int          `db:"attempts"`
+	Status          string       `db:"status"`
+	ExpiresAt       time.Time    `db:"expires_at"`
+	CreatedAt       time.Time    `db:"created_at"`
+	ConfirmedAt     sql.NullTime `db:"confirmed_at"`
+}
+
+func (m *TransferConfirmationModel) ToDomain() domain.TransferConfirmation {
+	return domain.TransferConfirmation{
+		ID:     m.ID,
+		UserID: m.UserID,
+		Request: domain.ReqTransfer{
+			FromCardNumber:  m.FromCardNumber,
+			FromPhoneNumber: m.FromPhoneNumber,
+			ToCardNumber:    m.ToCardNumber,
+			ToPhoneNumber:   m.ToPhoneNumber,
+			Amount:          m.Amount,
+			Currency:        m.Currency,
+		},
+		CodeHash:    m.CodeHash,
+		Attempts:    m.Attempts,
+		Status:      domain.TransferConfirmationStatus(m.Status),
+		ExpiresAt:   m.ExpiresAt,
+		CreatedAt:   m.CreatedAt,
+		ConfirmedAt: m.ConfirmedAt.Time,
+	}
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"database/sql"
 	"time"
 
 	"github.com/MMII0220/MiniBank/internal/domain"
@@ -16,6 +17,10 @@ type UserModel struct {
 	Role      string    `db:"role"`
 	CreatedAt time.Time `db:"created_at"`
 	UpdatedAt time.Time `db:"updated_at"`
+
+	EmailVerifiedAt sql.NullTime `db:"email_verified_at"`
+	PhoneVerifiedAt sql.NullTime `db:"phone_verified_at"`
+	DisabledAt      sql.NullTime `db:"disabled_at"`
 }
 
 func (um *UserModel) ToDomain() domain.User {
@@ -28,6 +33,10 @@ func (um *UserModel) ToDomain() domain.User {
 		Role:      domain.Role(um.Role),
 		CreatedAt: um.CreatedAt.Format(time.RFC3339),
 		UpdatedAt: um.UpdatedAt.Format(time.RFC3339),
+
+		EmailVerified: um.EmailVerifiedAt.Valid,
+		PhoneVerified: um.PhoneVerifiedAt.Valid,
+		Disabled:      um.DisabledAt.Valid,
 	}
 }
 
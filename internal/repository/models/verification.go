@@ -0,0 +1,37 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+)
+
+// ContactVerificationModel для работы с кодами подтверждения в БД
+type ContactVerificationModel struct {
+	ID          int          `db:"id"`
+	UserID      int          `db:"user_id"`
+	Channel     string       `db:"channel"`
+	Destination string       `db:"destination"`
+	CodeHash    string       `db:"code_hash"`
+	Attempts    int          `db:"attempts"`
+	Status      string       `db:"status"`
+	ExpiresAt   time.Time    `db:"expires_at"`
+	CreatedAt   time.Time    `db:"created_at"`
+	VerifiedAt  sql.NullTime `db:"verified_at"`
+}
+
+func (m *ContactVerificationModel) ToDomain() domain.ContactVerification {
+	return domain.ContactVerification{
+		ID:          m.ID,
+		UserID:      m.UserID,
+		Channel:     domain.VerificationChannel(m.Channel),
+		Destination: m.Destination,
+		CodeHash:    m.CodeHash,
+		Attempts:    m.Attempts,
+		Status:      domain.VerificationStatus(m.Status),
+		ExpiresAt:   m.ExpiresAt,
+		CreatedAt:   m.CreatedAt,
+		VerifiedAt:  m.VerifiedAt.Time,
+	}
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/logger"
+	"github.com/MMII0220/MiniBank/internal/repository/models"
+)
+
+// CreatePasswordResetToken сохраняет новый токен, прежние неиспользованные токены пользователя перестают действовать
+func (r *Repository) CreatePasswordResetToken(ctx context.Context, token *domain.PasswordResetToken) error {
+	log := logger.GetLogger()
+	log.Info().Int("user_id", token.UserID).Msg("Creating password reset token")
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return r.translateError(err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `UPDATE password_reset_tokens SET used_at = NOW() WHERE user_id = $1 AND used_at IS NULL`, token.UserID)
+	if err != nil {
+		return r.translateError(err)
+	}
+
+	err = tx.QueryRowContext(ctx, `INSERT INTO password_reset_tokens (user_id, token_hash, expires_at)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at`,
+		token.UserID, token.TokenHash, token.ExpiresAt,
+	).Scan(&token.ID, &token.CreatedAt)
+	if err != nil {
+		return r.translateError(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return r.translateError(err)
+	}
+	return nil
+}
+
+func (r *Repository) GetPasswordResetToken(ctx context.Context, tokenHash string) (domain.PasswordResetToken, error) {
+	var tokenModel models.PasswordResetTokenModel
+	query := `SELECT id, user_id, token_hash, expires_at, used_at, created_at
+		FROM password_reset_tokens WHERE token_hash = $1`
+	if err := r.db.GetContext(ctx, &tokenModel, query, tokenHash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.PasswordResetToken{}, errs.ErrInvalidResetToken
+		}
+		return domain.PasswordResetToken{}, r.translateError(err)
+	}
+	return tokenModel.ToDomain(), nil
+}
+
+// ResetPassword гасит токен и меняет пароль одной транзакцией; токен, использованный параллельно, дает ErrInvalidResetToken
+func (r *Repository) ResetPassword(ctx context.Context, tokenID int, userID int, passwordHash string) error {
+	log := logger.GetLogger()
+	log.Info().Int("user_id", userID).Msg("Resetting password")
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return r.translateError(err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE password_reset_tokens SET used_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND used_at IS NULL AND expires_at > NOW()`, tokenID, userID)
+	if err != nil {
+		return r.translateError(err)
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return errs.ErrInvalidResetToken
+	}
+
+	// Остальные выданные пользователю токены после смены пароля больше не действуют
+	if _, err = tx.ExecContext(ctx, `UPDATE password_reset_tokens SET used_at = NOW()
+		WHERE user_id = $1 AND used_at IS NULL`, userID); err != nil {
+		return r.translateError(err)
+	}
+
+	if _, err = tx.ExecContext(ctx, `UPDATE users SET password = $1, updated_at = NOW() WHERE id = $2`, passwordHash, userID); err != nil {
+		return r.translateError(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return r.translateError(err)
+	}
+	return nil
+}
+
+func (r *Repository) UpdatePassword(ctx context.Context, userID int, passwordHash string) error {
+	log := logger.GetLogger()
+	log.Info().Int("user_id", userID).Msg("Updating password")
+
+	res, err := r.db.ExecContext(ctx, `UPDATE users SET password = $1, updated_at = NOW() WHERE id = $2`, passwordHash, userID)
+	if err != nil {
+		return r.translateError(err)
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return errs.ErrUserNotFound
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/logger"
+	"github.com/MMII0220/MiniBank/internal/repository/models"
+	"github.com/jmoiron/sqlx"
+)
+
+const pendingActionColumns = `id, action_type, account_id, user_id, amount, new_role, reason_code, restriction_mode, reason,
+		proposed_by, status, decided_by, decision_note, expires_at, created_at, decided_at`
+
+func (r *Repository) CreatePendingAction(ctx context.Context, action *domain.PendingAction) error {
+	log := logger.GetLogger()
+	log.Info().
+		Str("action_type", string(action.Type)).
+		Int("proposed_by", action.ProposedBy).
+		Msg("Creating pending admin action")
+
+	actionModel := models.PendingActionFromDomain(*action)
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO pending_actions (action_type, account_id, user_id, amount, new_role, reason_code, restriction_mode, reason,
+			proposed_by, status, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, 'pending', $10)
+		RETURNING id, created_at`,
+		actionModel.Type, actionModel.AccountID, actionModel.UserID, actionModel.Amount, actionModel.NewRole,
+		actionModel.ReasonCode, actionModel.Mode, actionModel.Reason, actionModel.ProposedBy, actionModel.ExpiresAt,
+	).Scan(&actionModel.ID, &actionModel.CreatedAt)
+	if err != nil {
+		return r.translateError(err)
+	}
+
+	action.ID = actionModel.ID
+	action.Status = domain.PendingActionPending
+	action.CreatedAt = actionModel.CreatedAt
+	return nil
+}
+
+func (r *Repository) GetPendingActionByID(ctx context.Context, id int) (domain.PendingAction, error) {
+	var actionModel models.PendingActionModel
+	query := `SELECT ` + pendingActionColumns + ` FROM pending_actions WHERE id = $1`
+	err := r.db.GetContext(ctx, &actionModel, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.PendingAction{}, errs.ErrPendingActionNotFound
+		}
+		return domain.PendingAction{}, r.translateError(err)
+	}
+	return actionModel.ToDomain(), nil
+}
+
+// GetPendingActions возвращает действия по статусу, пустой статус - все
+func (r *Repository) GetPendingActions(ctx context.Context, status domain.PendingActionStatus) ([]domain.PendingAction, error) {
+	query := `SELECT ` + pendingActionColumns + `
+		FROM pending_actions
+		WHERE ($1 = '' OR status = $1)
+		ORDER BY created_at DESC`
+	return r.selectPendingActions(ctx, query, string(status))
+}
+
+// GetExpiredPendingActions - действия на одобрении, срок которых истек
+func (r *Repository) GetExpiredPendingActions(ctx context.Context) ([]domain.PendingAction, error) {
+	query := `SELECT ` + pendingActionColumns + `
+		FROM pending_actions
+		WHERE status = 'pending' AND expires_at <= NOW()
+		ORDER BY expires_at`
+	return r.selectPendingActions(ctx, query)
+}
+
+func (r *Repository) selectPendingActions(ctx context.Context, query string, args ...interface{}) ([]domain.PendingAction, error) {
+	var actionModels []models.PendingActionModel
+	if err := r.db.SelectContext(ctx, &actionModels, query, args...); err != nil {
+		return nil, r.translateError(err)
+	}
+
+	actions := make([]domain.PendingAction, len(actionModels))
+	for i, m := range actionModels {
+		actions[i] = m.ToDomain()
+	}
+	return actions, nil
+}
+
+// ExecutePendingAction фиксирует одобрение, выполняет действие и пишет аудит - в одной транзакции
+func (r *Repository) ExecutePendingAction(ctx context.Context, action domain.PendingAction, approverID int, note string, reqLogs domain.AdminAuditLog) error {
+	log := logger.GetLogger()
+	log.Info().
+		Int("pending_action_id", action.ID).
+		Str("action_type", string(action.Type)).
+		Int("approver_id", approverID).
+		Msg("Executing pending admin action")
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return r.translateError(err)
+	}
+	defer tx.Rollback()
+
+	if err = r.approvePendingAction(ctx, tx, action.ID, approverID, note); err != nil {
+		return err
+	}
+
+	if err = r.applyPendingAction(ctx, tx, action, approverID); err != nil {
+		return err
+	}
+
+	if err = r.insertAuditLog(ctx, tx, reqLogs); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return r.translateError(err)
+	}
+
+	if action.AccountID != 0 {
+		r.dropAccountsCache(ctx, action.AccountID)
+	}
+	return nil
+}
+
+// ExecutePendingAdjustment - одобрение manual_adjustment: фиксирует решение и проводит корректировку adj
+// тем же путем, что и AdjustBalance (транзакция adjustment, balance_adjustments с балансами до и после), в одной транзакции.
+// Дневной потолок не проверяется - сумма сверх него и требует одобрения, но корректировка входит в сумму за день.
+func (r *Repository) ExecutePendingAdjustment(ctx context.Context, action domain.PendingAction, adj domain.BalanceAdjustment, approverID int, note string, reqLogs domain.AdminAuditLog) (domain.BalanceAdjustment, error) {
+	log := logger.GetLogger()
+	log.Info().
+		Int("pending_action_id", action.ID).
+		Int("account_id", adj.AccountID).
+		Int("approver_id", approverID).
+		Msg("Executing pending balance adjustment")
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return adj, r.translateError(err)
+	}
+	defer tx.Rollback()
+
+	if err = r.approvePendingAction(ctx, tx, action.ID, approverID, note); err != nil {
+		return adj, err
+	}
+	if adj, err = r.postAdjustment(ctx, tx, adj); err != nil {
+		return adj, err
+	}
+
+	reqLogs.Reason = fmt.Sprintf("%s; balance %.2f -> %.2f", reqLogs.Reason, adj.BalanceBefore, adj.BalanceAfter)
+	if err = r.insertAuditLog(ctx, tx, reqLogs); err != nil {
+		return adj, err
+	}
+	if err = tx.Commit(); err != nil {
+		return adj, r.translateError(err)
+	}
+
+	r.dropAccountsCache(ctx, adj.AccountID)
+	return adj, nil
+}
+
+// approvePendingAction меняет статус первым: защищает от двойного выполнения
+func (r *Repository) approvePendingAction(ctx context.Context, tx *sqlx.Tx, actionID, approverID int, note string) error {
+	res, err := tx.ExecContext(ctx, `UPDATE pending_actions SET status = 'approved', decided_by = $1, decision_note = $2, decided_at = NOW()
+		WHERE id = $3 AND status = 'pending'`, approverID, note, actionID)
+	if err != nil {
+		return r.translateError(err)
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return errs.ErrPendingActionNotFound
+	}
+	return nil
+}
+
+// applyPendingAction выполняет само действие в рамках транзакции одобрения.
+// manual_adjustment сюда не попадает - он проводится через ExecutePendingAdjustment.
+func (r *Repository) applyPendingAction(ctx context.Context, tx *sqlx.Tx, action domain.PendingAction, approverID int) error {
+	var (
+		res         sql.Result
+		err         error
+		errNotFound error
+	)
+
+	switch action.Type {
+	case domain.ActionUnblock:
+		// Снимаются все действующие ограничения счета предложенного вида
+		res, err = tx.ExecContext(ctx, `UPDATE account_restrictions r SET lifted_at = NOW(), lifted_by = $3
+			WHERE r.account_id = $1 AND r.mode = $2 AND `+activeRestriction, action.AccountID, string(action.RestrictionMode), approverID)
+		errNotFound = errs.ErrInvalidOperation
+	case domain.ActionLimitRaise:
+		res, err = tx.ExecContext(ctx, `UPDATE limits SET daily_amount = $1 WHERE user_id = $2`, action.Amount, action.UserID)
+		errNotFound = errs.ErrLimitNotFound
+	case domain.ActionRoleChange:
+		res, err = tx.ExecContext(ctx, `UPDATE users SET role = $1, updated_at = NOW() WHERE id = $2`, string(action.NewRole), action.UserID)
+		errNotFound = errs.ErrUserNotFound
+	default:
+		return errs.ErrInvalidOperation
+	}
+	if err != nil {
+		return r.translateError(err)
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return errNotFound
+	}
+	return nil
+}
+
+// ClosePendingAction закрывает действие без выполнения (отклонение или истечение срока)
+func (r *Repository) ClosePendingAction(ctx context.Context, action domain.PendingAction, status domain.PendingActionStatus, deciderID int, note string, reqLogs domain.AdminAuditLog) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return r.translateError(err)
+	}
+	defer tx.Rollback()
+
+	decidedBy := sql.NullInt64{Int64: int64(deciderID), Valid: deciderID != 0}
+	res, err := tx.ExecContext(ctx, `UPDATE pending_actions SET status = $1, decided_by = $2, decision_note = $3, decided_at = NOW()
+		WHERE id = $4 AND status = 'pending'`, string(status), decidedBy, note, action.ID)
+	if err != nil {
+		return r.translateError(err)
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return errs.ErrPendingActionNotFound
+	}
+
+	if err = r.insertAuditLog(ctx, tx, reqLogs); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return r.translateError(err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/logger"
+	"github.com/MMII0220/MiniBank/internal/repository/models"
+)
+
+// UpdateUserProfile сохраняет профиль, сбрасывает подтверждение измененных email/телефона,
+// при необходимости перевыпускает карты на новое имя и пишет историю изменений одной транзакцией
+func (r *Repository) UpdateUserProfile(ctx context.Context, update domain.ProfileUpdate) error {
+	log := logger.GetLogger()
+	log.Info().Int("user_id", update.UserID).Int("changes", len(update.Changes)).Msg("Updating user profile")
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return r.translateError(err)
+	}
+	defer tx.Rollback()
+
+	// CASE без ELSE дает NULL: новый email или телефон нужно подтвердить заново
+	res, err := tx.ExecContext(ctx, `UPDATE users SET full_name = $2, email = $3, phone = $4,
+			email_verified_at = CASE WHEN email = $3 THEN email_verified_at END,
+			phone_verified_at = CASE WHEN phone = $4 THEN phone_verified_at END,
+			updated_at = NOW()
+		WHERE id = $1`,
+		update.UserID, update.FullName, update.Email, update.Phone)
+	if err != nil {
+		return r.translateError(err)
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return errs.ErrUserNotFound
+	}
+
+	// Коды, отправленные на прежние адреса, больше не нужны
+	_, err = tx.ExecContext(ctx, `UPDATE contact_verifications SET status = 'superseded'
+		WHERE user_id = $1 AND status = 'pending'
+			AND ((channel = 'email' AND destination <> $2) OR (channel = 'phone' AND destination <> $3))`,
+		update.UserID, update.Email, update.Phone)
+	if err != nil {
+		return r.translateError(err)
+	}
+
+	if update.ReissueCards {
+		_, err = tx.ExecContext(ctx, `UPDATE cards SET card_holder_name = $1, updated_at = NOW()
+			WHERE account_id IN (SELECT id FROM accounts WHERE user_id = $2)`,
+			update.FullName, update.UserID)
+		if err != nil {
+			return r.translateError(err)
+		}
+	}
+
+	for _, change := range update.Changes {
+		_, err = tx.ExecContext(ctx, `INSERT INTO user_profile_changes (user_id, field, old_value, new_value, changed_by)
+			VALUES ($1, $2, $3, $4, $5)`,
+			update.UserID, string(change.Field), change.OldValue, change.NewValue, change.ChangedBy)
+		if err != nil {
+			return r.translateError(err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return r.translateError(err)
+	}
+	return nil
+}
+
+func (r *Repository) GetProfileChanges(ctx context.Context, userID int) ([]domain.ProfileChange, error) {
+	var changeModels []models.ProfileChangeModel
+	query := `SELECT id, user_id, field, old_value, new_value, changed_by, created_at
+		FROM user_profile_changes WHERE user_id = $1 ORDER BY created_at DESC, id DESC`
+	if err := r.db.SelectContext(ctx, &changeModels, query, userID); err != nil {
+		return nil, r.translateError(err)
+	}
+
+	changes := make([]domain.ProfileChange, len(changeModels))
+	for i, changeModel := range changeModels {
+		changes[i] = changeModel.ToDomain()
+	}
+	return changes, nil
+}
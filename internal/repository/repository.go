@@ -58,10 +58,10 @@ func (r *Repository) translateError(err error) error {
 			switch {
 			case strings.Contains(detail, "email"):
 				log.Warn().Str("field", "email").Msg("Unique constraint violation")
-				return fmt.Errorf("user with this email already exists")
+				return fmt.Errorf("%w: user with this email already exists", errs.ErrUserAlreadyExists)
 			case strings.Contains(detail, "phone"):
 				log.Warn().Str("field", "phone").Msg("Unique constraint violation")
-				return fmt.Errorf("user with this phone already exists")
+				return fmt.Errorf("%w: user with this phone already exists", errs.ErrUserAlreadyExists)
 			case strings.Contains(detail, "card_number"):
 				log.Warn().Str("field", "card_number").Msg("Unique constraint violation")
 				return errs.ErrCardAlreadyExists
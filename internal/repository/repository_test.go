@@ -1,15 +1,18 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"regexp"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/MMII0220/MiniBank/internal/domain"
 	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/pglock"
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
 )
@@ -21,10 +24,41 @@ func newMockRepo(t *testing.T) (*Repository, sqlmock.Sqlmock, func()) {
 	}
 	sqlxDB := sqlx.NewDb(db, "sqlmock")
 	r := NewRepository(sqlxDB)
-	cleanup := func() { db.Close() }
+	// Все ожидания, включая Commit/Rollback, должны быть выполнены к концу теста
+	cleanup := func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unmet sqlmock expectations: %v", err)
+		}
+		db.Close()
+	}
 	return r, mock, cleanup
 }
 
+// expectAccountLock - блокировка строки счета в денежной транзакции
+func expectAccountLock(mock sqlmock.Sqlmock, accountID int) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM accounts WHERE id = $1 FOR UPDATE")).
+		WithArgs(accountID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(accountID))
+}
+
+// expectRestrictions - действующие ограничения счета, перечитанные в транзакции; legal_hold идет с суммой
+func expectRestrictions(mock sqlmock.Sqlmock, accountID int, modes map[domain.RestrictionMode]float64) {
+	rows := sqlmock.NewRows([]string{"id", "account_id", "mode", "amount", "reason_category", "reason", "created_by",
+		"expires_at", "created_at", "lifted_by", "lifted_at"})
+	id := 0
+	for mode, amount := range modes {
+		id++
+		var value interface{}
+		if amount > 0 {
+			value = amount
+		}
+		rows.AddRow(id, accountID, string(mode), value, "other", "test", 1, nil, time.Now(), nil, nil)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("FROM account_restrictions r WHERE r.account_id = $1 AND " + activeRestriction)).
+		WithArgs(accountID).
+		WillReturnRows(rows)
+}
+
 func TestTranslateError_NoRows(t *testing.T) {
 	r := &Repository{}
 	err := r.translateError(errors.New("sql: no rows in result set"))
@@ -40,42 +74,81 @@ func TestTranslateError_PgUnique_Email(t *testing.T) {
 	if err == nil || !regexp.MustCompile(`email`).MatchString(err.Error()) {
 		t.Fatalf("expected email unique error, got %v", err)
 	}
+	if !errors.Is(err, errs.ErrUserAlreadyExists) {
+		t.Fatalf("expected ErrUserAlreadyExists, got %v", err)
+	}
 }
 
-func TestSetAccountBlock_Success(t *testing.T) {
+func TestCreateAccountRestriction_Success(t *testing.T) {
 	r, mock, cleanup := newMockRepo(t)
 	defer cleanup()
 
+	expires := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
 	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta("UPDATE accounts SET blocked = $1 WHERE id = $2")).
-		WithArgs(true, 10).
-		WillReturnResult(sqlmock.NewResult(0, 1))
-	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO account_audit (account_id, admin_id, action, reason) VALUES ($1, $2, $3, $4)")).
-		WithArgs(10, 99, sqlmock.AnyArg(), "reason").
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM accounts WHERE id = $1 FOR UPDATE")).
+		WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO account_restrictions (account_id, mode, amount, reason_category, reason, created_by, expires_at)")).
+		WithArgs(10, "legal_hold", sql.NullFloat64{Float64: 250, Valid: true}, "court_order", "case 12/26",
+			sql.NullInt64{Int64: 99, Valid: true}, sql.NullTime{Time: expires, Valid: true}).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(4, time.Now()))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO account_audit (account_id, admin_id, action, reason, api_client_id) VALUES ($1, $2, $3, $4, NULLIF($5, 0))")).
+		WithArgs(10, 99, "legal_hold", "reason", 0).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
-	audit := domain.AdminAuditLog{AccountID: 10, AdminID: 99, Action: "block", Reason: "reason", CreatedAt: time.Now()}
-	if err := r.SetAccountBlock(10, true, audit); err != nil {
-		t.Fatalf("unexpected error: %v", err)
+	audit := domain.AdminAuditLog{AccountID: 10, AdminID: 99, Action: "legal_hold", Reason: "reason", CreatedAt: time.Now()}
+	restriction, err := r.CreateAccountRestriction(context.Background(), domain.AccountRestriction{
+		AccountID: 10, Mode: domain.RestrictionLegalHold, Amount: 250, Category: domain.RestrictionCourtOrder,
+		Reason: "case 12/26", CreatedBy: 99, ExpiresAt: expires,
+	}, audit)
+	if err != nil || restriction.ID != 4 {
+		t.Fatalf("unexpected result %+v, err=%v", restriction, err)
 	}
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Fatalf("unmet expectations: %v", err)
 	}
 }
 
-func TestGetAuditLogs_Success(t *testing.T) {
+func TestLiftAccountRestrictions_NothingActive(t *testing.T) {
 	r, mock, cleanup := newMockRepo(t)
 	defer cleanup()
 
-	rows := sqlmock.NewRows([]string{"id", "account_id", "admin_id", "action", "reason", "created_at"}).
-		AddRow(1, 10, 99, "block", "r", time.Now())
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, account_id, admin_id, action, reason, created_at FROM account_audit ORDER BY created_at DESC")).
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE account_restrictions r SET lifted_at = NOW(), lifted_by = NULLIF($3, 0)")).
+		WithArgs(10, "debit_freeze", 99).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	if _, err := r.LiftAccountRestrictions(context.Background(), 10, domain.RestrictionDebitFreeze, 99, domain.AdminAuditLog{}); !errors.Is(err, errs.ErrInvalidOperation) {
+		t.Fatalf("expected ErrInvalidOperation, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestGetActiveAccountRestrictions_Success(t *testing.T) {
+	r, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	rows := sqlmock.NewRows([]string{"id", "account_id", "mode", "amount", "reason_category", "reason", "created_by",
+		"expires_at", "created_at", "lifted_by", "lifted_at"}).
+		AddRow(1, 10, "debit_freeze", nil, "fraud", "r", 99, nil, time.Now(), nil, nil).
+		AddRow(2, 10, "legal_hold", 250.0, "court_order", "r", 99, time.Now().Add(time.Hour), time.Now(), nil, nil)
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE r.account_id = $1 AND " + activeRestriction)).
+		WithArgs(10).
 		WillReturnRows(rows)
 
-	logs, err := r.GetAuditLogs()
-	if err != nil || len(logs) != 1 {
-		t.Fatalf("expected 1 log, got %v, err=%v", len(logs), err)
+	restrictions, err := r.GetActiveAccountRestrictions(context.Background(), 10)
+	if err != nil || len(restrictions) != 2 {
+		t.Fatalf("unexpected restrictions %+v, err=%v", restrictions, err)
+	}
+	if !restrictions.BlocksDebit() || restrictions.BlocksCredit() || restrictions.LegalHold() != 250 {
+		t.Fatalf("unexpected restriction summary %+v", restrictions)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
 	}
 }
 
@@ -83,13 +156,13 @@ func TestGetAllAccountsByUserID_Success(t *testing.T) {
 	r, mock, cleanup := newMockRepo(t)
 	defer cleanup()
 
-	rows := sqlmock.NewRows([]string{"id", "user_id", "balance", "currency", "blocked", "created_at", "updated_at"}).
-		AddRow(1, 7, 100.50, "TJS", false, time.Now(), sql.NullTime{})
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, balance, currency, blocked, created_at, updated_at \n\t\t\t\t  FROM accounts WHERE user_id = $1 ORDER BY created_at DESC")).
+	rows := sqlmock.NewRows([]string{"id", "user_id", "balance", "hold_amount", "currency", "blocked", "account_type", "created_at", "updated_at"}).
+		AddRow(1, 7, 100.50, 0.0, "TJS", false, "personal", time.Now(), sql.NullTime{})
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT a.id, a.user_id, a.balance, a.hold_amount, a.currency, " + accountBlockedColumn + ", a.account_type, a.created_at, a.updated_at\n\t\t\t  FROM accounts a WHERE a.user_id = $1 ORDER BY a.created_at DESC")).
 		WithArgs(7).
 		WillReturnRows(rows)
 
-	accounts, err := r.GetAllAccountsByUserID(7)
+	accounts, err := r.GetAllAccountsByUserID(context.Background(), 7)
 	if err != nil || len(accounts) != 1 {
 		t.Fatalf("expected 1 account, got %v, err=%v", len(accounts), err)
 	}
@@ -100,6 +173,8 @@ func TestDepositToAccount_Success(t *testing.T) {
 	defer cleanup()
 
 	mock.ExpectBegin()
+	expectAccountLock(mock, 1)
+	expectRestrictions(mock, 1, nil)
 	mock.ExpectExec(regexp.QuoteMeta("UPDATE accounts SET balance = balance + $1 WHERE id = $2")).
 		WithArgs(25.0, 1).
 		WillReturnResult(sqlmock.NewResult(0, 1))
@@ -111,7 +186,7 @@ func TestDepositToAccount_Success(t *testing.T) {
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
-	if err := r.DepositToAccount(1, 25.0); err != nil {
+	if err := r.DepositToAccount(context.Background(), 1, 25.0); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
@@ -121,15 +196,18 @@ func TestWithdrawFromAccount_Success(t *testing.T) {
 	defer cleanup()
 
 	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta("UPDATE accounts SET balance = CAST(balance AS NUMERIC) - $1 WHERE id = $2 AND currency = $3")).
-		WithArgs(10.0, 2, "USD").
+	expectAccountLock(mock, 2)
+	expectRestrictions(mock, 2, map[domain.RestrictionMode]float64{domain.RestrictionLegalHold: 40})
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE accounts SET balance = CAST(balance AS NUMERIC) - $1
+		WHERE id = $2 AND currency = $3 AND balance - hold_amount - $4 >= $1`)).
+		WithArgs(10.0, 2, "USD", 40.0).
 		WillReturnResult(sqlmock.NewResult(0, 1))
-	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO transactions (account_id, amount, currency, type) VALUES ($1, $2, $3, 'withdraw')")).
-		WithArgs(2, 10.0, "USD").
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO transactions (account_id, amount, currency, type, fee) VALUES ($1, $2, $3, 'withdraw', $4)")).
+		WithArgs(2, 10.0, "USD", 0.0).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
-	if err := r.WithdrawFromAccount(2, 10.0, "USD"); err != nil {
+	if err := r.WithdrawFromAccount(context.Background(), 2, 10.0, 0, "USD"); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
@@ -139,49 +217,124 @@ func TestWithdrawFromAccount_NoRow(t *testing.T) {
 	defer cleanup()
 
 	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta("UPDATE accounts SET balance = CAST(balance AS NUMERIC) - $1 WHERE id = $2 AND currency = $3")).
-		WithArgs(10.0, 2, "USD").
-		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM accounts WHERE id = $1 FOR UPDATE")).
+		WithArgs(2).
+		WillReturnError(sql.ErrNoRows)
 	mock.ExpectRollback()
 
-	err := r.WithdrawFromAccount(2, 10.0, "USD")
+	err := r.WithdrawFromAccount(context.Background(), 2, 10.0, 0, "USD")
 	if !errors.Is(err, errs.ErrAccountNotFound) {
 		t.Fatalf("expected ErrAccountNotFound, got %v", err)
 	}
 }
 
+// Ограничение, появившееся после проверки в service, видно под блокировкой счета
+func TestWithdrawFromAccount_RestrictedInTransaction(t *testing.T) {
+	r, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectAccountLock(mock, 2)
+	expectRestrictions(mock, 2, map[domain.RestrictionMode]float64{domain.RestrictionDebitFreeze: 0})
+	mock.ExpectRollback()
+
+	err := r.WithdrawFromAccount(context.Background(), 2, 10.0, 0, "USD")
+	if !errors.Is(err, errs.ErrAccountRestricted) {
+		t.Fatalf("expected ErrAccountRestricted, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+// Сумма legal_hold не может быть списана
+func TestWithdrawFromAccount_LegalHold(t *testing.T) {
+	r, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectAccountLock(mock, 2)
+	expectRestrictions(mock, 2, map[domain.RestrictionMode]float64{domain.RestrictionLegalHold: 95})
+	mock.ExpectExec(regexp.QuoteMeta(`balance - hold_amount - $4 >= $1`)).
+		WithArgs(10.0, 2, "USD", 95.0).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err := r.WithdrawFromAccount(context.Background(), 2, 10.0, 0, "USD")
+	if !errors.Is(err, errs.ErrInsufficientFunds) {
+		t.Fatalf("expected ErrInsufficientFunds, got %v", err)
+	}
+}
+
 func TestTransferFunds_Success(t *testing.T) {
 	r, mock, cleanup := newMockRepo(t)
 	defer cleanup()
 
 	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta("UPDATE accounts SET balance = balance - $1 WHERE id = $2 AND balance >= $1")).
-		WithArgs(5.0, 3).
+	expectAccountLock(mock, 3)
+	expectAccountLock(mock, 4)
+	expectRestrictions(mock, 3, nil)
+	expectRestrictions(mock, 4, nil)
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE accounts SET balance = balance - $1 WHERE id = $2 AND balance - hold_amount - $3 >= $1")).
+		WithArgs(5.0, 3, 0.0).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 	mock.ExpectExec(regexp.QuoteMeta("UPDATE accounts SET balance = balance + $1 WHERE id = $2")).
 		WithArgs(5.0, 4).
 		WillReturnResult(sqlmock.NewResult(0, 1))
-	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO transactions (account_id, amount, type) VALUES ($1, $2, 'transfer')")).
-		WithArgs(3, 5.0).
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO transactions (account_id, amount, currency, type, fee)")).
+		WithArgs(3, 5.0, 0.0).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
-	if err := r.TransferFunds(3, 4, 5.0); err != nil {
+	if err := r.TransferFunds(context.Background(), 3, 4, 5.0, 0); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
 
+// Получателю зачисляется сумма без комиссии, списывается сумма вместе с комиссией
+func TestTransferFunds_RecipientCreditedWithoutFee(t *testing.T) {
+	r, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectAccountLock(mock, 3)
+	expectAccountLock(mock, 4)
+	expectRestrictions(mock, 3, nil)
+	expectRestrictions(mock, 4, nil)
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE accounts SET balance = balance - $1 WHERE id = $2 AND balance - hold_amount - $3 >= $1")).
+		WithArgs(101.5, 3, 0.0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE accounts SET balance = balance + $1 WHERE id = $2")).
+		WithArgs(100.0, 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO transactions (account_id, amount, currency, type, fee)")).
+		WithArgs(3, 101.5, 1.5).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	if err := r.TransferFunds(context.Background(), 3, 4, 101.5, 1.5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
 func TestTransferFunds_Insufficient(t *testing.T) {
 	r, mock, cleanup := newMockRepo(t)
 	defer cleanup()
 
 	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta("UPDATE accounts SET balance = balance - $1 WHERE id = $2 AND balance >= $1")).
-		WithArgs(5.0, 3).
+	expectAccountLock(mock, 3)
+	expectAccountLock(mock, 4)
+	expectRestrictions(mock, 3, nil)
+	expectRestrictions(mock, 4, nil)
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE accounts SET balance = balance - $1 WHERE id = $2 AND balance - hold_amount - $3 >= $1")).
+		WithArgs(5.0, 3, 0.0).
 		WillReturnResult(sqlmock.NewResult(0, 0))
 	mock.ExpectRollback()
 
-	err := r.TransferFunds(3, 4, 5.0)
+	err := r.TransferFunds(context.Background(), 3, 4, 5.0, 0)
 	if !errors.Is(err, errs.ErrInsufficientFunds) {
 		t.Fatalf("expected ErrInsufficientFunds, got %v", err)
 	}
@@ -191,9 +344,9 @@ func TestGetAccountByCardNumber_Success(t *testing.T) {
 	r, mock, cleanup := newMockRepo(t)
 	defer cleanup()
 
-	rows := sqlmock.NewRows([]string{"id", "user_id", "currency", "balance", "blocked"}).
-		AddRow(10, 20, "TJS", 100.0, false)
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT a.id, a.user_id, a.currency, a.balance, a.blocked
+	rows := sqlmock.NewRows([]string{"id", "user_id", "currency", "balance", "hold_amount", "blocked", "account_type"}).
+		AddRow(10, 20, "TJS", 100.0, 0.0, false, "personal")
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT a.id, a.user_id, a.currency, a.balance, a.hold_amount, `+accountBlockedColumn+`, a.account_type
 		FROM accounts a
 		JOIN cards c ON c.account_id = a.id
 		WHERE c.card_number = $1 AND a.currency = $2`)).
@@ -201,7 +354,7 @@ func TestGetAccountByCardNumber_Success(t *testing.T) {
 		WillReturnRows(rows)
 
 	var acc domain.Account
-	if err := r.GetAccountByCardNumber(&acc, "4000", "TJS"); err != nil {
+	if err := r.GetAccountByCardNumber(context.Background(), &acc, "4000", "TJS"); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	if acc.ID != 10 || acc.UserID != 20 {
@@ -213,9 +366,9 @@ func TestGetAccountByPhoneNumber_Success(t *testing.T) {
 	r, mock, cleanup := newMockRepo(t)
 	defer cleanup()
 
-	rows := sqlmock.NewRows([]string{"id", "user_id", "currency", "balance", "blocked"}).
-		AddRow(11, 21, "USD", 55.0, false)
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT a.id, a.user_id, a.currency, a.balance, a.blocked
+	rows := sqlmock.NewRows([]string{"id", "user_id", "currency", "balance", "hold_amount", "blocked", "account_type"}).
+		AddRow(11, 21, "USD", 55.0, 0.0, false, "personal")
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT a.id, a.user_id, a.currency, a.balance, a.hold_amount, `+accountBlockedColumn+`, a.account_type
         FROM accounts a
         JOIN users u ON u.id = a.user_id
         WHERE u.phone = $1 AND a.currency = $2`)).
@@ -223,7 +376,7 @@ func TestGetAccountByPhoneNumber_Success(t *testing.T) {
 		WillReturnRows(rows)
 
 	var acc domain.Account
-	if err := r.GetAccountByPhoneNumber(&acc, "+123", "USD"); err != nil {
+	if err := r.GetAccountByPhoneNumber(context.Background(), &acc, "+123", "USD"); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	if acc.ID != 11 || acc.UserID != 21 {
@@ -246,7 +399,7 @@ func TestGetTransactionHistory_Success(t *testing.T) {
 		WithArgs(77).
 		WillReturnRows(rows)
 
-	trs, err := r.GetTransactionHistory(77)
+	trs, err := r.GetTransactionHistory(context.Background(), 77)
 	if err != nil || len(trs) != 2 {
 		t.Fatalf("expected 2 transactions, got %v, err=%v", len(trs), err)
 	}
@@ -264,7 +417,7 @@ func TestCreateUser_Success(t *testing.T) {
 		WillReturnRows(rows)
 
 	u := &domain.User{FullName: "John Doe", Phone: "+1", Email: "john@example.com", Password: "hash", Role: domain.RoleUser}
-	if err := r.CreateUser(u); err != nil {
+	if err := r.CreateUser(context.Background(), u); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	if u.ID != 123 {
@@ -276,12 +429,12 @@ func TestGetUserByEmail_Success(t *testing.T) {
 	r, mock, cleanup := newMockRepo(t)
 	defer cleanup()
 
-	rows := sqlmock.NewRows([]string{"id", "email", "password", "role"}).AddRow(5, "a@b.c", "hash", string(domain.RoleUser))
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, email, password, role FROM users WHERE email = $1")).
+	rows := sqlmock.NewRows([]string{"id", "email", "password", "role", "disabled_at"}).AddRow(5, "a@b.c", "hash", string(domain.RoleUser), nil)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, email, password, role, disabled_at FROM users WHERE email = $1")).
 		WithArgs("a@b.c").
 		WillReturnRows(rows)
 
-	u, err := r.GetUserByEmail("a@b.c")
+	u, err := r.GetUserByEmail(context.Background(), "a@b.c")
 	if err != nil || u == nil || u.ID != 5 {
 		t.Fatalf("unexpected result user=%v err=%v", u, err)
 	}
@@ -309,11 +462,11 @@ func TestGetUserByEmail_NotFound(t *testing.T) {
 	r, mock, cleanup := newMockRepo(t)
 	defer cleanup()
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, email, password, role FROM users WHERE email = $1")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, email, password, role, disabled_at FROM users WHERE email = $1")).
 		WithArgs("none@example.com").
 		WillReturnError(sql.ErrNoRows)
 
-	u, err := r.GetUserByEmail("none@example.com")
+	u, err := r.GetUserByEmail(context.Background(), "none@example.com")
 	if !errors.Is(err, errs.ErrUserNotFound) || u != nil {
 		t.Fatalf("expected ErrUserNotFound, got %v, user=%v", err, u)
 	}
@@ -324,12 +477,12 @@ func TestCreateAccount_Success(t *testing.T) {
 	defer cleanup()
 
 	rows := sqlmock.NewRows([]string{"id"}).AddRow(777)
-	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO accounts (user_id, currency, balance, blocked, created_at)\n        VALUES ($1, $2, $3, $4, NOW())\n        RETURNING id")).
-		WithArgs(55, "TJS", 0.0, false).
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO accounts (user_id, currency, balance, created_at)\n        VALUES ($1, $2, $3, NOW())\n        RETURNING id")).
+		WithArgs(55, "TJS", 0.0).
 		WillReturnRows(rows)
 
 	a := &domain.Account{UserID: 55, Currency: "TJS", Balance: "0.00", Blocked: false, CreatedAt: time.Now()}
-	if err := r.CreateAccount(a); err != nil {
+	if err := r.CreateAccount(context.Background(), a); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	if a.ID != 777 {
@@ -349,7 +502,7 @@ func TestCreateCard_Success(t *testing.T) {
 		WillReturnRows(rows)
 
 	c := &domain.Card{AccountID: 7, CardNumber: "4000123412341234", CardHolderName: "JOHN DOE", ExpiryDate: time.Now(), CVV: "123"}
-	if err := r.CreateCard(c); err != nil {
+	if err := r.CreateCard(context.Background(), c); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	if c.ID != 42 {
@@ -366,7 +519,7 @@ func TestGetDailyLimitByUserID_Success(t *testing.T) {
 		WithArgs(9).
 		WillReturnRows(rows)
 
-	lim, err := r.GetDailyLimitByUserID(9)
+	lim, err := r.GetDailyLimitByUserID(context.Background(), 9)
 	if err != nil || lim.UserID != 9 || lim.DailyAmount != 1000.0 {
 		t.Fatalf("unexpected limit: %+v err=%v", lim, err)
 	}
@@ -388,7 +541,7 @@ func TestGetTodayUsageInTJS_Success(t *testing.T) {
 		WithArgs(5).
 		WillReturnRows(rows)
 
-	total, err := r.GetTodayUsageInTJS(5)
+	total, err := r.GetTodayUsageInTJS(context.Background(), 5)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -405,7 +558,7 @@ func TestCreateDailyLimitForUser_Success(t *testing.T) {
 		WithArgs(8, 500.0).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	if err := r.CreateDailyLimitForUser(8, 500.0); err != nil {
+	if err := r.CreateDailyLimitForUser(context.Background(), 8, 500.0); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
@@ -418,7 +571,7 @@ func TestResetDailyLimit_Success(t *testing.T) {
 		WithArgs(8).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	if err := r.ResetDailyLimit(8); err != nil {
+	if err := r.ResetDailyLimit(context.Background(), 8); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
@@ -427,10 +580,11 @@ func TestGetAuditLogs_Error(t *testing.T) {
 	r, mock, cleanup := newMockRepo(t)
 	defer cleanup()
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, account_id, admin_id, action, reason, created_at FROM account_audit ORDER BY created_at DESC")).
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, account_id, admin_id, COALESCE(api_client_id, 0) AS api_client_id, action, COALESCE(reason, '') AS reason, created_at
+		FROM account_audit ORDER BY id DESC`)).
 		WillReturnError(errors.New("db down"))
 
-	_, err := r.GetAuditLogs()
+	_, err := r.GetAuditLogs(context.Background(), domain.AuditLogFilter{})
 	if !errors.Is(err, errs.ErrDatabaseError) {
 		t.Fatalf("expected ErrDatabaseError, got %v", err)
 	}
@@ -440,11 +594,11 @@ func TestGetAllAccountsByUserID_Error(t *testing.T) {
 	r, mock, cleanup := newMockRepo(t)
 	defer cleanup()
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, balance, currency, blocked, created_at, updated_at \n\t\t\t\t  FROM accounts WHERE user_id = $1 ORDER BY created_at DESC")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT a.id, a.user_id, a.balance, a.hold_amount, a.currency, " + accountBlockedColumn + ", a.account_type, a.created_at, a.updated_at\n\t\t\t  FROM accounts a WHERE a.user_id = $1 ORDER BY a.created_at DESC")).
 		WithArgs(1).
 		WillReturnError(errors.New("db error"))
 
-	_, err := r.GetAllAccountsByUserID(1)
+	_, err := r.GetAllAccountsByUserID(context.Background(), 1)
 	if !errors.Is(err, errs.ErrDatabaseError) {
 		t.Fatalf("expected ErrDatabaseError, got %v", err)
 	}
@@ -455,13 +609,661 @@ func TestDepositToAccount_NotFound(t *testing.T) {
 	defer cleanup()
 
 	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta("UPDATE accounts SET balance = balance + $1 WHERE id = $2")).
-		WithArgs(1.0, 999).
-		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM accounts WHERE id = $1 FOR UPDATE")).
+		WithArgs(999).
+		WillReturnError(sql.ErrNoRows)
 	mock.ExpectRollback()
 
-	err := r.DepositToAccount(999, 1.0)
+	err := r.DepositToAccount(context.Background(), 999, 1.0)
 	if !errors.Is(err, errs.ErrAccountNotFound) {
 		t.Fatalf("expected ErrAccountNotFound, got %v", err)
 	}
 }
+
+func TestDepositToAccount_CancelledContext(t *testing.T) {
+	r, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	// Клиент отключился до начала операции: транзакция не открывается, баланс не меняется
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := r.DepositToAccount(ctx, 1, 25.0); err == nil {
+		t.Fatal("expected error for cancelled context")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unexpected database calls: %v", err)
+	}
+}
+
+func TestGetLatestScreeningReview_NotFound(t *testing.T) {
+	r, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM screening_reviews")).
+		WithArgs("customer", "a@b.c").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := r.GetLatestScreeningReview(context.Background(), domain.ScreeningCustomer, "a@b.c")
+	if !errors.Is(err, errs.ErrScreeningReviewNotFound) {
+		t.Fatalf("expected ErrScreeningReviewNotFound, got %v", err)
+	}
+}
+
+func TestResolveScreeningReview_AlreadyResolved(t *testing.T) {
+	r, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE screening_reviews")).
+		WithArgs("cleared", 1, "ok", 5).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := r.ResolveScreeningReview(context.Background(), 5, domain.ScreeningCleared, 1, "ok")
+	if !errors.Is(err, errs.ErrScreeningReviewNotFound) {
+		t.Fatalf("expected ErrScreeningReviewNotFound, got %v", err)
+	}
+}
+
+func TestCreatePendingTransfer_InsufficientForHold(t *testing.T) {
+	r, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectAccountLock(mock, 3)
+	expectRestrictions(mock, 3, map[domain.RestrictionMode]float64{domain.RestrictionLegalHold: 500})
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE accounts SET hold_amount = hold_amount + $1 WHERE id = $2 AND balance - hold_amount - $3 >= $1")).
+		WithArgs(20010.0, 3, 500.0).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	pt := domain.PendingTransfer{FromAccountID: 3, ToAccountID: 4, Amount: 20000, Fee: 10, Currency: "TJS", InitiatorID: 5}
+	err := r.CreatePendingTransfer(context.Background(), &pt)
+	if !errors.Is(err, errs.ErrInsufficientFunds) {
+		t.Fatalf("expected ErrInsufficientFunds, got %v", err)
+	}
+}
+
+// Блокировка получателя, поставленная пока перевод ждал решения, видна в транзакции одобрения
+func TestApprovePendingTransfer_RecipientBlockedInTransaction(t *testing.T) {
+	r, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE pending_transfers SET status = 'approved'`)).
+		WithArgs(9, "ok", 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectAccountLock(mock, 3)
+	expectAccountLock(mock, 4)
+	expectRestrictions(mock, 3, nil)
+	expectRestrictions(mock, 4, map[domain.RestrictionMode]float64{domain.RestrictionFullBlock: 0})
+	mock.ExpectRollback()
+
+	pt := domain.PendingTransfer{ID: 7, FromAccountID: 3, ToAccountID: 4, Amount: 20000, Fee: 10, Currency: "TJS"}
+	if err := r.ApprovePendingTransfer(context.Background(), pt, 9, "ok", domain.AdminAuditLog{}); !errors.Is(err, errs.ErrAccountBlocked) {
+		t.Fatalf("expected ErrAccountBlocked, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+// Остаток после списания удержанной суммы должен покрывать legal_hold
+func TestApprovePendingTransfer_LegalHold(t *testing.T) {
+	r, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE pending_transfers SET status = 'approved'`)).
+		WithArgs(9, "ok", 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectAccountLock(mock, 3)
+	expectAccountLock(mock, 4)
+	expectRestrictions(mock, 3, map[domain.RestrictionMode]float64{domain.RestrictionLegalHold: 300})
+	expectRestrictions(mock, 4, nil)
+	mock.ExpectExec(regexp.QuoteMeta(`WHERE id = $2 AND hold_amount >= $1 AND balance - hold_amount >= $3`)).
+		WithArgs(20010.0, 3, 300.0).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	pt := domain.PendingTransfer{ID: 7, FromAccountID: 3, ToAccountID: 4, Amount: 20000, Fee: 10, Currency: "TJS"}
+	if err := r.ApprovePendingTransfer(context.Background(), pt, 9, "ok", domain.AdminAuditLog{}); !errors.Is(err, errs.ErrInsufficientFunds) {
+		t.Fatalf("expected ErrInsufficientFunds, got %v", err)
+	}
+}
+
+func TestExecutePendingAdjustment_PostsBalanceAdjustment(t *testing.T) {
+	r, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	created := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE pending_actions SET status = 'approved', decided_by = $1, decision_note = $2, decided_at = NOW()
+		WHERE id = $3 AND status = 'pending'`)).
+		WithArgs(2, "ok", 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT balance, hold_amount, currency FROM accounts WHERE id = $1 FOR UPDATE`)).WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"balance", "hold_amount", "currency"}).AddRow(700.0, 100.0, "TJS"))
+	expectRestrictions(mock, 3, nil)
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE accounts SET balance = balance + $1`)).WithArgs(-500.0, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(200.0))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO transactions (account_id, amount, currency, type) VALUES ($1, $2, $3, 'adjustment')`)).
+		WithArgs(3, -500.0, "TJS").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(77))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO balance_adjustments`)).
+		WithArgs(3, 77, 1, "debit", 500.0, "TJS", 500.0, "chargeback", "card dispute", 700.0, 200.0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(9, created))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO account_audit`)).
+		WithArgs(3, 2, "manual_adjustment_approved", "approved; balance 700.00 -> 200.00", 0).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	action := domain.PendingAction{ID: 4, Type: domain.ActionManualAdjustment, AccountID: 3, Amount: -500, ProposedBy: 1}
+	adj := domain.BalanceAdjustment{AccountID: 3, AdminID: 1, Direction: domain.AdjustmentDebit, Amount: 500, AmountTJS: 500,
+		ReasonCode: domain.AdjustmentChargeback, Note: "card dispute"}
+	adj, err := r.ExecutePendingAdjustment(context.Background(), action, adj, 2, "ok",
+		domain.AdminAuditLog{AccountID: 3, AdminID: 2, Action: "manual_adjustment_approved", Reason: "approved"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if adj.ID != 9 || adj.BalanceBefore != 700 || adj.BalanceAfter != 200 {
+		t.Fatalf("unexpected adjustment %+v", adj)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestExecutePendingAction_LiftsProposedRestriction(t *testing.T) {
+	r, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE pending_actions SET status = 'approved'`)).
+		WithArgs(2, "ok", 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE account_restrictions r SET lifted_at = NOW(), lifted_by = $3
+			WHERE r.account_id = $1 AND r.mode = $2`)).
+		WithArgs(3, "legal_hold", 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO account_audit`)).
+		WithArgs(3, 2, "unblock_approved", "approved", 0).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	action := domain.PendingAction{ID: 4, Type: domain.ActionUnblock, AccountID: 3, RestrictionMode: domain.RestrictionLegalHold, ProposedBy: 1}
+	if err := r.ExecutePendingAction(context.Background(), action, 2, "ok",
+		domain.AdminAuditLog{AccountID: 3, AdminID: 2, Action: "unblock_approved", Reason: "approved"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestExecutePendingAdjustment_Insufficient(t *testing.T) {
+	r, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE pending_actions SET status = 'approved'`)).
+		WithArgs(2, "ok", 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT balance, hold_amount, currency FROM accounts WHERE id = $1 FOR UPDATE`)).WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"balance", "hold_amount", "currency"}).AddRow(650.0, 100.0, "TJS"))
+	// 650 - 100 покрывает списание 500, но не вместе с legal_hold
+	expectRestrictions(mock, 3, map[domain.RestrictionMode]float64{domain.RestrictionLegalHold: 60})
+	mock.ExpectRollback()
+
+	action := domain.PendingAction{ID: 4, Type: domain.ActionManualAdjustment, AccountID: 3, Amount: -500, ProposedBy: 1}
+	adj := domain.BalanceAdjustment{AccountID: 3, AdminID: 1, Direction: domain.AdjustmentDebit, Amount: 500}
+	if _, err := r.ExecutePendingAdjustment(context.Background(), action, adj, 2, "ok", domain.AdminAuditLog{}); !errors.Is(err, errs.ErrInsufficientFunds) {
+		t.Fatalf("expected ErrInsufficientFunds, got %v", err)
+	}
+}
+
+func TestRotateRefreshToken_AlreadyUsed(t *testing.T) {
+	r, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE refresh_tokens SET used_at = NOW() WHERE id = $1 AND used_at IS NULL`)).
+		WithArgs(9).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err := r.RotateRefreshToken(context.Background(), 9, "sid-1", "hash", time.Now().Add(time.Hour))
+	if !errors.Is(err, errs.ErrRefreshTokenRevoked) {
+		t.Fatalf("expected ErrRefreshTokenRevoked, got %v", err)
+	}
+}
+
+func TestGetUserDeviceIDs(t *testing.T) {
+	r, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT DISTINCT device_id FROM auth_sessions WHERE user_id = $1 AND device_id <> ''`)).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"device_id"}).AddRow("laptop").AddRow("phone"))
+
+	ids, err := r.GetUserDeviceIDs(context.Background(), 5)
+	if err != nil || len(ids) != 2 || ids[1] != "phone" {
+		t.Fatalf("unexpected: %v %v", err, ids)
+	}
+}
+
+func TestGetLoginLock_NoLock(t *testing.T) {
+	r, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT email, user_id, failed_count, locked_until, created_at FROM login_lockouts WHERE email = $1`)).
+		WithArgs("a@b.c").
+		WillReturnRows(sqlmock.NewRows([]string{"email", "user_id", "failed_count", "locked_until", "created_at"}))
+
+	lock, err := r.GetLoginLock(context.Background(), "a@b.c")
+	if err != nil || lock.Active() {
+		t.Fatalf("expected no lock, got %v %+v", err, lock)
+	}
+}
+
+func TestUseMFAStep_ReplayRejected(t *testing.T) {
+	r, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE user_mfa SET last_used_step = $1
+		WHERE user_id = $2 AND enabled = TRUE AND last_used_step < $1`)).
+		WithArgs(int64(100), 5).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := r.UseMFAStep(context.Background(), 5, 100); !errors.Is(err, errs.ErrInvalidMFACode) {
+		t.Fatalf("expected ErrInvalidMFACode, got %v", err)
+	}
+}
+
+func TestRecordTransferConfirmationAttempt_Closed(t *testing.T) {
+	r, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE transfer_confirmations
+		SET attempts = attempts + 1,
+			status = CASE WHEN attempts + 1 >= $2 THEN 'failed' ELSE status END
+		WHERE id = $1 AND status = 'pending'
+		RETURNING attempts`)).
+		WithArgs(9, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"attempts"}))
+
+	if _, err := r.RecordTransferConfirmationAttempt(context.Background(), 9, 3); !errors.Is(err, errs.ErrInvalidOperation) {
+		t.Fatalf("expected ErrInvalidOperation, got %v", err)
+	}
+}
+
+func TestClaimStaffInvite_AlreadyAccepted(t *testing.T) {
+	r, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE staff_invites SET accepted_at = NOW() WHERE id = $1 AND accepted_at IS NULL`)).
+		WithArgs(4).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := r.ClaimStaffInvite(context.Background(), 4); !errors.Is(err, errs.ErrInvalidOperation) {
+		t.Fatalf("expected ErrInvalidOperation, got %v", err)
+	}
+}
+
+func TestResetPassword_TokenAlreadyUsed(t *testing.T) {
+	r, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE password_reset_tokens SET used_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND used_at IS NULL AND expires_at > NOW()`)).
+		WithArgs(8, 5).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	if err := r.ResetPassword(context.Background(), 8, 5, "hash"); !errors.Is(err, errs.ErrInvalidResetToken) {
+		t.Fatalf("expected ErrInvalidResetToken, got %v", err)
+	}
+}
+
+func TestResetPassword_InvalidatesOtherTokens(t *testing.T) {
+	r, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE password_reset_tokens SET used_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND used_at IS NULL AND expires_at > NOW()`)).
+		WithArgs(8, 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE password_reset_tokens SET used_at = NOW()
+		WHERE user_id = $1 AND used_at IS NULL`)).
+		WithArgs(5).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE users SET password = $1, updated_at = NOW() WHERE id = $2`)).
+		WithArgs("hash", 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := r.ResetPassword(context.Background(), 8, 5, "hash"); err != nil {
+		t.Fatalf("unexpected: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestCompleteContactVerification_ContactChanged(t *testing.T) {
+	r, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE contact_verifications SET status = 'verified', verified_at = NOW()
+		WHERE id = $1 AND status = 'pending'`)).
+		WithArgs(3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE users SET phone_verified_at = NOW() WHERE id = $1 AND phone = $2`)).
+		WithArgs(5, "+992900123456").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	v := domain.ContactVerification{ID: 3, UserID: 5, Channel: domain.VerifyPhone, Destination: "+992900123456"}
+	if err := r.CompleteContactVerification(context.Background(), v); !errors.Is(err, errs.ErrVerificationNotFound) {
+		t.Fatalf("expected ErrVerificationNotFound, got %v", err)
+	}
+}
+
+func TestRevokeAPIClient_AlreadyRevoked(t *testing.T) {
+	r, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE api_clients SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`)).
+		WithArgs(4).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	if err := r.RevokeAPIClient(context.Background(), 4, domain.AdminAuditLog{AdminID: 1, Action: "api_client_revoked"}); !errors.Is(err, errs.ErrAPIClientNotFound) {
+		t.Fatalf("expected ErrAPIClientNotFound, got %v", err)
+	}
+}
+
+func TestUpdateUserProfile_ResetsVerificationAndRecordsChanges(t *testing.T) {
+	r, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE users SET full_name = $2, email = $3, phone = $4,`)).
+		WithArgs(5, "Ali", "new@bank.tj", "+992900123456").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE contact_verifications SET status = 'superseded'`)).
+		WithArgs(5, "new@bank.tj", "+992900123456").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO user_profile_changes (user_id, field, old_value, new_value, changed_by)`)).
+		WithArgs(5, "email", "old@bank.tj", "new@bank.tj", 5).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	update := domain.ProfileUpdate{
+		UserID: 5, FullName: "Ali", Email: "new@bank.tj", Phone: "+992900123456",
+		Changes: []domain.ProfileChange{{Field: domain.ProfileEmail, OldValue: "old@bank.tj", NewValue: "new@bank.tj", ChangedBy: 5}},
+	}
+	if err := r.UpdateUserProfile(context.Background(), update); err != nil {
+		t.Fatalf("unexpected: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSetUserDisabled_AlreadyDisabled(t *testing.T) {
+	r, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE users SET disabled_at = NOW(), updated_at = NOW() WHERE id = $1 AND disabled_at IS NULL`)).
+		WithArgs(5).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	if err := r.SetUserDisabled(context.Background(), 5, true, domain.AdminAuditLog{AdminID: 1, Action: "user_disabled"}); !errors.Is(err, errs.ErrInvalidOperation) {
+		t.Fatalf("expected ErrInvalidOperation, got %v", err)
+	}
+}
+
+func TestAppendAuditEvent_ChainsToLastHash(t *testing.T) {
+	r, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	prev := strings.Repeat("a", 64)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_xact_lock($1)`)).
+		WithArgs(pglock.AuditChain).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT hash FROM audit_events ORDER BY id DESC LIMIT 1`)).
+		WillReturnRows(sqlmock.NewRows([]string{"hash"}).AddRow(prev))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT nextval('audit_events_id_seq')`)).
+		WillReturnRows(sqlmock.NewRows([]string{"nextval"}).AddRow(42))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO audit_events`)).
+		WillReturnResult(sqlmock.NewResult(42, 1))
+	mock.ExpectCommit()
+
+	event := domain.AuditEvent{
+		OccurredAt: time.Now().UTC(), ActorID: 1, ActorType: domain.ActorUser,
+		Action: "block", Outcome: domain.AuditSuccess, TargetType: "account", TargetID: "7",
+	}
+	saved, err := r.AppendAuditEvent(context.Background(), event)
+	if err != nil {
+		t.Fatalf("unexpected: %v", err)
+	}
+	if saved.ID != 42 || saved.PrevHash != prev || saved.Hash != saved.ComputeHash() || len(saved.Hash) != 64 {
+		t.Fatalf("unexpected chained event: %+v", saved)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestGetAuditLogs_FilterAndCursor(t *testing.T) {
+	r, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	rows := sqlmock.NewRows([]string{"id", "account_id", "admin_id", "api_client_id", "action", "reason", "created_at"}).
+		AddRow(41, 10, 99, 0, "block", "r", from)
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM account_audit WHERE account_id = $1 AND action = $2 AND created_at >= $3 AND created_at < $4 AND id > $5 ORDER BY id ASC LIMIT $6`)).
+		WithArgs(10, "block", from, to, 40, 51).
+		WillReturnRows(rows)
+
+	logs, err := r.GetAuditLogs(context.Background(), domain.AuditLogFilter{
+		AccountID: 10, Action: "block", From: from, To: to, Cursor: 40, Limit: 51, Ascending: true,
+	})
+	if err != nil || len(logs) != 1 || logs[0].ID != 41 {
+		t.Fatalf("unexpected logs %+v, err=%v", logs, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestGetAdminStats_Success(t *testing.T) {
+	r, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 7)
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM users WHERE role = 'user'`)).WithArgs(from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(DISTINCT account_id) FROM transactions`)).WithArgs(from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(DISTINCT r.account_id) FROM account_restrictions r`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta(`GROUP BY day, currency, type`)).WithArgs(from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"day", "currency", "type", "count", "volume", "fees"}).
+			AddRow(from, "TJS", "transfer", 2, 200.0, 2.0))
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE t.fee > 0`)).WithArgs(from, to, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "full_name", "email", "breaches", "last_breach_at"}).
+			AddRow(7, "Ali", "ali@example.com", 2, from))
+
+	stats, err := r.GetAdminStats(context.Background(), from, to, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.NewUsers != 3 || stats.ActiveAccounts != 5 || stats.BlockedAccounts != 1 {
+		t.Fatalf("unexpected counters %+v", stats)
+	}
+	if len(stats.Volumes) != 1 || stats.Volumes[0].Type != domain.Transfer || stats.Volumes[0].Fees != 2 {
+		t.Fatalf("unexpected volumes %+v", stats.Volumes)
+	}
+	if len(stats.TopLimitBreaches) != 1 || stats.TopLimitBreaches[0].UserID != 7 {
+		t.Fatalf("unexpected breaches %+v", stats.TopLimitBreaches)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestAdjustBalance_Debit(t *testing.T) {
+	r, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	created := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_xact_lock($1, $2)`)).WithArgs(pglock.AdjustmentCeiling, 1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(SUM(amount_tjs), 0) FROM balance_adjustments`)).WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(100.0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT balance, hold_amount, currency FROM accounts WHERE id = $1 FOR UPDATE`)).WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"balance", "hold_amount", "currency"}).AddRow(100.0, 30.0, "TJS"))
+	expectRestrictions(mock, 10, nil)
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE accounts SET balance = balance + $1`)).WithArgs(-20.0, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(80.0))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO transactions (account_id, amount, currency, type) VALUES ($1, $2, $3, 'adjustment')`)).
+		WithArgs(10, -20.0, "TJS").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(77))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO balance_adjustments`)).
+		WithArgs(10, 77, 1, "debit", 20.0, "TJS", 20.0, "error_correction", "duplicate", 100.0, 80.0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(3, created))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO account_audit`)).
+		WithArgs(10, 1, "balance_debit", "error_correction: duplicate; balance 100.00 -> 80.00", 0).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	adj, err := r.AdjustBalance(context.Background(), domain.BalanceAdjustment{
+		AccountID: 10, AdminID: 1, Direction: domain.AdjustmentDebit, Amount: 20, AmountTJS: 20,
+		ReasonCode: domain.AdjustmentErrorCorrection, Note: "duplicate",
+	}, 5000, domain.AdminAuditLog{AccountID: 10, AdminID: 1, Action: "balance_debit", Reason: "error_correction: duplicate"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if adj.ID != 3 || adj.TransactionID != 77 || adj.BalanceBefore != 100 || adj.BalanceAfter != 80 {
+		t.Fatalf("unexpected adjustment %+v", adj)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestAdjustBalance_CeilingAndFunds(t *testing.T) {
+	r, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	adj := domain.BalanceAdjustment{AccountID: 10, AdminID: 1, Direction: domain.AdjustmentDebit, Amount: 80, AmountTJS: 80}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_xact_lock`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM balance_adjustments`)).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(4950.0))
+	mock.ExpectRollback()
+	if _, err := r.AdjustBalance(context.Background(), adj, 5000, domain.AdminAuditLog{}); !errors.Is(err, errs.ErrAdjustmentCeiling) {
+		t.Fatalf("expected ErrAdjustmentCeiling, got %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_xact_lock`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM balance_adjustments`)).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(0.0))
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM accounts WHERE id = $1 FOR UPDATE`)).
+		WillReturnRows(sqlmock.NewRows([]string{"balance", "hold_amount", "currency"}).AddRow(100.0, 30.0, "TJS"))
+	expectRestrictions(mock, 10, nil)
+	mock.ExpectRollback()
+	if _, err := r.AdjustBalance(context.Background(), adj, 5000, domain.AdminAuditLog{}); !errors.Is(err, errs.ErrInsufficientFunds) {
+		t.Fatalf("expected ErrInsufficientFunds, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestMarkDormantAccounts(t *testing.T) {
+	r, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	since := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_xact_lock($1)")).
+		WithArgs(pglock.Dormancy).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("t.type IN ('deposit', 'withdraw', 'transfer') AND t.created_at >= $1")).
+		WithArgs(since, "no customer activity for 12 months").
+		WillReturnRows(sqlmock.NewRows([]string{"account_id"}).AddRow(3).AddRow(7))
+	mock.ExpectCommit()
+
+	ids, err := r.MarkDormantAccounts(context.Background(), since, "no customer activity for 12 months")
+	if err != nil || len(ids) != 2 || ids[0] != 3 || ids[1] != 7 {
+		t.Fatalf("unexpected result %v, err=%v", ids, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestGetDormantBalances(t *testing.T) {
+	r, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	mock.ExpectQuery(regexp.QuoteMeta("r.mode = 'dormant' AND " + activeRestriction)).
+		WillReturnRows(sqlmock.NewRows([]string{"currency", "accounts", "balance"}).
+			AddRow("TJS", 3, 1250.5).AddRow("USD", 1, 40.0))
+
+	balances, err := r.GetDormantBalances(context.Background())
+	if err != nil || len(balances) != 2 || balances[0] != (domain.DormantBalance{Currency: "TJS", Accounts: 3, Balance: 1250.5}) {
+		t.Fatalf("unexpected result %+v, err=%v", balances, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSetKYCTier_RaisesLimit(t *testing.T) {
+	r, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE users SET kyc_tier = $1, updated_at = NOW() WHERE id = $2")).
+		WithArgs("basic", 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE limits SET daily_amount = GREATEST(daily_amount, $1) WHERE user_id = $2")).
+		WithArgs(5000.0, 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := r.SetKYCTier(context.Background(), 5, domain.KYCBasic, 5000); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestReviewKYCDocument_NotPending(t *testing.T) {
+	r, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	mock.ExpectExec(regexp.QuoteMeta("WHERE id = $4 AND status = 'pending'")).
+		WithArgs("approved", 1, "ok", 3).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := r.ReviewKYCDocument(context.Background(), 3, domain.KYCDocumentApproved, 1, "ok"); !errors.Is(err, errs.ErrKYCDocumentNotFound) {
+		t.Fatalf("expected ErrKYCDocumentNotFound, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/logger"
+	"github.com/MMII0220/MiniBank/internal/repository/models"
+	"github.com/jmoiron/sqlx"
+)
+
+// activeRestriction - условие действующего ограничения для таблицы account_restrictions с алиасом r
+const activeRestriction = `r.lifted_at IS NULL AND (r.expires_at IS NULL OR r.expires_at > NOW())`
+
+// accountBlockedColumn - признак blocked счета с алиасом a: действующий full_block
+const accountBlockedColumn = `EXISTS (SELECT 1 FROM account_restrictions r
+			WHERE r.account_id = a.id AND r.mode = 'full_block' AND ` + activeRestriction + `) AS blocked`
+
+const restrictionColumns = `r.id, r.account_id, r.mode, r.amount, r.reason_category, r.reason, r.created_by,
+		r.expires_at, r.created_at, r.lifted_by, r.lifted_at`
+
+// CreateAccountRestriction ставит ограничение и пишет его в account_audit в одной транзакции
+func (r *Repository) CreateAccountRestriction(ctx context.Context, restriction domain.AccountRestriction, reqLogs domain.AdminAuditLog) (domain.AccountRestriction, error) {
+	log := logger.GetLogger()
+	log.Info().
+		Int("account_id", restriction.AccountID).
+		Str("mode", string(restriction.Mode)).
+		Int("admin_id", restriction.CreatedBy).
+		Msg("Creating account restriction")
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return restriction, r.translateError(err)
+	}
+	defer tx.Rollback()
+
+	// Та же блокировка, что в денежных транзакциях: ограничение ставится до или после проводки, но не во время
+	if err = r.lockAccounts(ctx, tx, restriction.AccountID); err != nil {
+		return restriction, err
+	}
+
+	m := models.AccountRestrictionFromDomain(restriction)
+	row := tx.QueryRowxContext(ctx, `INSERT INTO account_restrictions (account_id, mode, amount, reason_category, reason, created_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at`,
+		m.AccountID, m.Mode, m.Amount, m.Category, m.Reason, m.CreatedBy, m.ExpiresAt)
+	if err = row.Scan(&restriction.ID, &restriction.CreatedAt); err != nil {
+		return restriction, r.translateError(err)
+	}
+
+	if err = r.insertAuditLog(ctx, tx, reqLogs); err != nil {
+		return restriction, err
+	}
+	if err = tx.Commit(); err != nil {
+		return restriction, r.translateError(err)
+	}
+
+	r.dropAccountsCache(ctx, restriction.AccountID)
+	return restriction, nil
+}
+
+// LiftAccountRestrictions снимает все действующие ограничения счета данного вида, возвращает число снятых
+func (r *Repository) LiftAccountRestrictions(ctx context.Context, accountID int, mode domain.RestrictionMode, liftedBy int, reqLogs domain.AdminAuditLog) (int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, r.translateError(err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE account_restrictions r SET lifted_at = NOW(), lifted_by = NULLIF($3, 0)
+		WHERE r.account_id = $1 AND r.mode = $2 AND `+activeRestriction, accountID, string(mode), liftedBy)
+	if err != nil {
+		return 0, r.translateError(err)
+	}
+	lifted, _ := res.RowsAffected()
+	if lifted == 0 {
+		return 0, errs.ErrInvalidOperation
+	}
+
+	if err = r.insertAuditLog(ctx, tx, reqLogs); err != nil {
+		return 0, err
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, r.translateError(err)
+	}
+
+	r.dropAccountsCache(ctx, accountID)
+	return int(lifted), nil
+}
+
+// GetActiveAccountRestrictions - действующие ограничения счета; истекшие не возвращаются
+func (r *Repository) GetActiveAccountRestrictions(ctx context.Context, accountID int) (domain.AccountRestrictions, error) {
+	return r.activeRestrictions(ctx, r.db, accountID)
+}
+
+func (r *Repository) activeRestrictions(ctx context.Context, q sqlx.QueryerContext, accountID int) (domain.AccountRestrictions, error) {
+	var restrictionModels []models.AccountRestrictionModel
+	err := sqlx.SelectContext(ctx, q, &restrictionModels, `SELECT `+restrictionColumns+` FROM account_restrictions r
+		WHERE r.account_id = $1 AND `+activeRestriction+` ORDER BY r.id`, accountID)
+	if err != nil {
+		return nil, r.translateError(err)
+	}
+
+	restrictions := make(domain.AccountRestrictions, len(restrictionModels))
+	for i, m := range restrictionModels {
+		restrictions[i] = m.ToDomain()
+	}
+	return restrictions, nil
+}
+
+// GetAccountRestrictions - все ограничения счета, включая снятые и истекшие, от новых к старым
+func (r *Repository) GetAccountRestrictions(ctx context.Context, accountID int) ([]domain.AccountRestriction, error) {
+	var restrictionModels []models.AccountRestrictionModel
+	err := r.db.SelectContext(ctx, &restrictionModels, `SELECT `+restrictionColumns+` FROM account_restrictions r
+		WHERE r.account_id = $1 ORDER BY r.id DESC`, accountID)
+	if err != nil {
+		return nil, r.translateError(err)
+	}
+
+	restrictions := make([]domain.AccountRestriction, len(restrictionModels))
+	for i, m := range restrictionModels {
+		restrictions[i] = m.ToDomain()
+	}
+	return restrictions, nil
+}
+
+// lockAccounts блокирует строки счетов до конца транзакции, по возрастанию id, чтобы встречные переводы не ждали друг друга.
+// CreateAccountRestriction берет ту же блокировку, поэтому ограничение не может появиться между проверкой в транзакции и проводкой.
+func (r *Repository) lockAccounts(ctx context.Context, tx *sqlx.Tx, accountIDs ...int) error {
+	ids := append([]int(nil), accountIDs...)
+	sort.Ints(ids)
+	for i, id := range ids {
+		if i > 0 && id == ids[i-1] {
+			continue
+		}
+		var locked int
+		err := tx.GetContext(ctx, &locked, `SELECT id FROM accounts WHERE id = $1 FOR UPDATE`, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return errs.ErrAccountNotFound
+		}
+		if err != nil {
+			return r.translateError(err)
+		}
+	}
+	return nil
+}
+
+// checkDebitTx - ограничения списания, перечитанные в денежной транзакции после lockAccounts.
+// Возвращает сумму legal_hold, которую нельзя списать.
+func (r *Repository) checkDebitTx(ctx context.Context, tx *sqlx.Tx, accountID int) (float64, error) {
+	restrictions, err := r.activeRestrictions(ctx, tx, accountID)
+	if err != nil {
+		return 0, err
+	}
+	if restrictions.Blocked() {
+		return 0, errs.ErrAccountBlocked
+	}
+	if restrictions.Dormant() {
+		return 0, errs.ErrAccountDormant
+	}
+	if restrictions.BlocksDebit() {
+		return 0, errs.ErrAccountRestricted
+	}
+	return restrictions.LegalHold(), nil
+}
+
+// checkCreditTx - ограничения зачисления, перечитанные в денежной транзакции после lockAccounts
+func (r *Repository) checkCreditTx(ctx context.Context, tx *sqlx.Tx, accountID int) error {
+	restrictions, err := r.activeRestrictions(ctx, tx, accountID)
+	if err != nil {
+		return err
+	}
+	if restrictions.Blocked() {
+		return errs.ErrAccountBlocked
+	}
+	if restrictions.BlocksCredit() {
+		return errs.ErrAccountRestricted
+	}
+	return nil
+}
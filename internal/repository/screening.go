@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/logger"
+	"github.com/MMII0220/MiniBank/internal/repository/models"
+)
+
+const screeningReviewColumns = `id, subject_type, subject_ref, subject_name, matched_name, list_source, list_entry_id,
+		score, status, reviewed_by, review_note, created_at, reviewed_at`
+
+// CreateScreeningReview ставит потенциальное совпадение в очередь на ручную проверку
+func (r *Repository) CreateScreeningReview(ctx context.Context, review *domain.ScreeningReview) error {
+	log := logger.GetLogger()
+	log.Warn().
+		Str("subject_type", string(review.SubjectType)).
+		Str("subject_ref", review.SubjectRef).
+		Str("matched_name", review.MatchedName).
+		Float64("score", review.Score).
+		Msg("Creating sanctions screening review")
+
+	reviewModel := models.ScreeningReviewFromDomain(*review)
+	query := `
+		INSERT INTO screening_reviews (subject_type, subject_ref, subject_name, matched_name, list_source, list_entry_id, score, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, 'pending')
+		RETURNING id, created_at
+	`
+	err := r.db.QueryRowContext(ctx, query,
+		reviewModel.SubjectType, reviewModel.SubjectRef, reviewModel.SubjectName, reviewModel.MatchedName,
+		reviewModel.ListSource, reviewModel.ListEntryID, reviewModel.Score,
+	).Scan(&reviewModel.ID, &reviewModel.CreatedAt)
+	if err != nil {
+		log.Error().Err(err).Str("subject_ref", review.SubjectRef).Msg("Failed to create screening review")
+		return r.translateError(err)
+	}
+
+	review.ID = reviewModel.ID
+	review.Status = domain.ScreeningPending
+	review.CreatedAt = reviewModel.CreatedAt
+	return nil
+}
+
+// GetLatestScreeningReview возвращает последнюю проверку по субъекту
+func (r *Repository) GetLatestScreeningReview(ctx context.Context, subjectType domain.ScreeningSubjectType, subjectRef string) (domain.ScreeningReview, error) {
+	var reviewModel models.ScreeningReviewModel
+	query := `SELECT ` + screeningReviewColumns + `
+		FROM screening_reviews
+		WHERE subject_type = $1 AND subject_ref = $2
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1`
+	err := r.db.GetContext(ctx, &reviewModel, query, string(subjectType), subjectRef)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ScreeningReview{}, errs.ErrScreeningReviewNotFound
+		}
+		return domain.ScreeningReview{}, r.translateError(err)
+	}
+	return reviewModel.ToDomain(), nil
+}
+
+// GetScreeningReviews возвращает очередь проверок, пустой статус - все записи
+func (r *Repository) GetScreeningReviews(ctx context.Context, status domain.ScreeningStatus) ([]domain.ScreeningReview, error) {
+	var reviewModels []models.ScreeningReviewModel
+	query := `SELECT ` + screeningReviewColumns + `
+		FROM screening_reviews
+		WHERE ($1 = '' OR status = $1)
+		ORDER BY created_at DESC`
+	err := r.db.SelectContext(ctx, &reviewModels, query, string(status))
+	if err != nil {
+		return nil, r.translateError(err)
+	}
+
+	reviews := make([]domain.ScreeningReview, len(reviewModels))
+	for i, m := range reviewModels {
+		reviews[i] = m.ToDomain()
+	}
+	return reviews, nil
+}
+
+// ResolveScreeningReview фиксирует решение админа, менять можно только заявки в статусе pending
+func (r *Repository) ResolveScreeningReview(ctx context.Context, reviewID int, status domain.ScreeningStatus, adminID int, note string) error {
+	log := logger.GetLogger()
+	log.Info().
+		Int("review_id", reviewID).
+		Str("status", string(status)).
+		Int("admin_id", adminID).
+		Msg("Resolving screening review")
+
+	query := `
+		UPDATE screening_reviews
+		SET status = $1, reviewed_by = $2, review_note = $3, reviewed_at = NOW()
+		WHERE id = $4 AND status = 'pending'
+	`
+	result, err := r.db.ExecContext(ctx, query, string(status), adminID, note, reviewID)
+	if err != nil {
+		return r.translateError(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return r.translateError(err)
+	}
+	if rowsAffected == 0 {
+		return errs.ErrScreeningReviewNotFound
+	}
+	return nil
+}
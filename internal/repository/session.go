@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/logger"
+	"github.com/MMII0220/MiniBank/internal/repository/models"
+)
+
+const sessionColumns = `id, user_id, device_id, user_agent, ip, step_up_required, mfa_verified,
+		step_up_code_hash, step_up_code_expires_at, created_at, last_used_at, expires_at, revoked_at, revoke_reason`
+
+// CreateSession создает сессию и первый refresh токен семейства
+func (r *Repository) CreateSession(ctx context.Context, session *domain.Session, tokenHash string) error {
+	log := logger.GetLogger()
+	log.Info().Int("user_id", session.UserID).Str("session_id", session.ID).Msg("Creating auth session")
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return r.translateError(err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `INSERT INTO auth_sessions (id, user_id, device_id, user_agent, ip, step_up_required, mfa_verified, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at, last_used_at`,
+		session.ID, session.UserID, session.DeviceID, session.UserAgent, session.IP, session.StepUpRequired, session.MFAVerified, session.ExpiresAt,
+	).Scan(&session.CreatedAt, &session.LastUsedAt)
+	if err != nil {
+		return r.translateError(err)
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO refresh_tokens (session_id, token_hash, expires_at) VALUES ($1, $2, $3)`,
+		session.ID, tokenHash, session.ExpiresAt)
+	if err != nil {
+		return r.translateError(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return r.translateError(err)
+	}
+	return nil
+}
+
+func (r *Repository) GetSession(ctx context.Context, sessionID string) (domain.Session, error) {
+	var sessionModel models.SessionModel
+	query := `SELECT ` + sessionColumns + ` FROM auth_sessions WHERE id = $1`
+	err := r.db.GetContext(ctx, &sessionModel, query, sessionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Session{}, errs.ErrSessionExpired
+		}
+		return domain.Session{}, r.translateError(err)
+	}
+	return sessionModel.ToDomain(), nil
+}
+
+// GetRefreshToken ищет refresh токен по хешу
+func (r *Repository) GetRefreshToken(ctx context.Context, tokenHash string) (domain.RefreshToken, error) {
+	var tokenModel models.RefreshTokenModel
+	query := `SELECT id, session_id, token_hash, expires_at, used_at, created_at FROM refresh_tokens WHERE token_hash = $1`
+	err := r.db.GetContext(ctx, &tokenModel, query, tokenHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.RefreshToken{}, errs.ErrInvalidRefreshToken
+		}
+		return domain.RefreshToken{}, r.translateError(err)
+	}
+	return tokenModel.ToDomain(), nil
+}
+
+// RotateRefreshToken гасит использованный токен и выдает следующий в той же сессии.
+// Если токен уже погашен параллельным запросом - это повторное использование.
+func (r *Repository) RotateRefreshToken(ctx context.Context, oldTokenID int, sessionID string, newTokenHash string, expiresAt time.Time) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return r.translateError(err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE refresh_tokens SET used_at = NOW() WHERE id = $1 AND used_at IS NULL`, oldTokenID)
+	if err != nil {
+		return r.translateError(err)
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return errs.ErrRefreshTokenRevoked
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO refresh_tokens (session_id, token_hash, expires_at) VALUES ($1, $2, $3)`,
+		sessionID, newTokenHash, expiresAt)
+	if err != nil {
+		return r.translateError(err)
+	}
+
+	res, err = tx.ExecContext(ctx, `UPDATE auth_sessions SET last_used_at = NOW(), expires_at = $1
+		WHERE id = $2 AND revoked_at IS NULL`, expiresAt, sessionID)
+	if err != nil {
+		return r.translateError(err)
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return errs.ErrRefreshTokenRevoked
+	}
+
+	if err = tx.Commit(); err != nil {
+		return r.translateError(err)
+	}
+	return nil
+}
+
+// RevokeSession отзывает сессию вместе со всеми ее refresh токенами
+func (r *Repository) RevokeSession(ctx context.Context, sessionID string, reason string) error {
+	log := logger.GetLogger()
+	log.Info().Str("session_id", sessionID).Str("reason", reason).Msg("Revoking auth session")
+
+	_, err := r.db.ExecContext(ctx, `UPDATE auth_sessions SET revoked_at = NOW(), revoke_reason = $1
+		WHERE id = $2 AND revoked_at IS NULL`, reason, sessionID)
+	if err != nil {
+		return r.translateError(err)
+	}
+	return nil
+}
+
+// RevokeUserSessions отзывает все активные сессии пользователя и возвращает их id
+func (r *Repository) RevokeUserSessions(ctx context.Context, userID int, reason string) ([]string, error) {
+	log := logger.GetLogger()
+	log.Info().Int("user_id", userID).Str("reason", reason).Msg("Revoking all user sessions")
+
+	var sessionIDs []string
+	err := r.db.SelectContext(ctx, &sessionIDs, `UPDATE auth_sessions SET revoked_at = NOW(), revoke_reason = $1
+		WHERE user_id = $2 AND revoked_at IS NULL
+		RETURNING id`, reason, userID)
+	if err != nil {
+		return nil, r.translateError(err)
+	}
+	return sessionIDs, nil
+}
+
+// GetActiveSessions - неотозванные и неистекшие сессии пользователя, последние использованные сверху
+func (r *Repository) GetActiveSessions(ctx context.Context, userID int) ([]domain.Session, error) {
+	var sessionModels []models.SessionModel
+	query := `SELECT ` + sessionColumns + `
+		FROM auth_sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY last_used_at DESC`
+	if err := r.db.SelectContext(ctx, &sessionModels, query, userID); err != nil {
+		return nil, r.translateError(err)
+	}
+
+	sessions := make([]domain.Session, len(sessionModels))
+	for i, m := range sessionModels {
+		sessions[i] = m.ToDomain()
+	}
+	return sessions, nil
+}
+
+// GetUserDeviceIDs - устройства, с которых пользователь когда-либо входил
+func (r *Repository) GetUserDeviceIDs(ctx context.Context, userID int) ([]string, error) {
+	var deviceIDs []string
+	query := `SELECT DISTINCT device_id FROM auth_sessions WHERE user_id = $1 AND device_id <> ''`
+	if err := r.db.SelectContext(ctx, &deviceIDs, query, userID); err != nil {
+		return nil, r.translateError(err)
+	}
+	return deviceIDs, nil
+}
+
+// SetSessionStepUpCode сохраняет хеш нового кода подтверждения сессии, прежний код перестает действовать
+func (r *Repository) SetSessionStepUpCode(ctx context.Context, sessionID string, codeHash string, expiresAt time.Time) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE auth_sessions SET step_up_code_hash = $1, step_up_code_expires_at = $2
+		WHERE id = $3 AND step_up_required AND revoked_at IS NULL`, codeHash, expiresAt, sessionID)
+	if err != nil {
+		return r.translateError(err)
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return errs.ErrInvalidOperation
+	}
+	return nil
+}
+
+// CompleteSessionStepUp снимает требование повторного подтверждения с сессии и гасит код
+func (r *Repository) CompleteSessionStepUp(ctx context.Context, sessionID string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE auth_sessions SET step_up_required = FALSE, step_up_code_hash = '', step_up_code_expires_at = NULL
+		WHERE id = $1`, sessionID)
+	if err != nil {
+		return r.translateError(err)
+	}
+	return nil
+}
+
+// MarkSessionMFAVerified - сессия подтверждена вторым фактором (после подключения 2FA в ней)
+func (r *Repository) MarkSessionMFAVerified(ctx context.Context, sessionID string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE auth_sessions SET mfa_verified = TRUE WHERE id = $1`, sessionID)
+	if err != nil {
+		return r.translateError(err)
+	}
+	return nil
+}
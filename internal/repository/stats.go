@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/logger"
+	"github.com/MMII0220/MiniBank/internal/repository/models"
+)
+
+// GetAdminStats считает сводку за период [from, to). Запросы только читают и идут вне транзакции:
+// для панели небольшая рассинхронизация между счетчиками допустима.
+func (r *Repository) GetAdminStats(ctx context.Context, from, to time.Time, topBreaches int) (domain.AdminStats, error) {
+	log := logger.GetLogger()
+	log.Debug().Time("from", from).Time("to", to).Msg("Calculating admin stats")
+
+	stats := domain.AdminStats{From: from, To: to}
+
+	err := r.db.GetContext(ctx, &stats.NewUsers, `SELECT COUNT(*) FROM users WHERE role = 'user' AND created_at >= $1 AND created_at < $2`, from, to)
+	if err != nil {
+		return stats, r.translateError(err)
+	}
+	err = r.db.GetContext(ctx, &stats.ActiveAccounts, `SELECT COUNT(DISTINCT account_id) FROM transactions WHERE created_at >= $1 AND created_at < $2`, from, to)
+	if err != nil {
+		return stats, r.translateError(err)
+	}
+	if err = r.db.GetContext(ctx, &stats.BlockedAccounts, `SELECT COUNT(DISTINCT r.account_id) FROM account_restrictions r
+		WHERE r.mode = 'full_block' AND `+activeRestriction); err != nil {
+		return stats, r.translateError(err)
+	}
+
+	var volumeModels []models.DailyVolumeModel
+	err = r.db.SelectContext(ctx, &volumeModels, `SELECT (created_at AT TIME ZONE 'UTC')::date AS day, currency, type,
+			COUNT(*) AS count, COALESCE(SUM(amount - fee), 0) AS volume, COALESCE(SUM(fee), 0) AS fees
+		FROM transactions
+		WHERE created_at >= $1 AND created_at < $2 AND type IN ('deposit', 'withdraw', 'transfer')
+		GROUP BY day, currency, type
+		ORDER BY day, currency, type`, from, to)
+	if err != nil {
+		return stats, r.translateError(err)
+	}
+	stats.Volumes = make([]domain.DailyVolume, len(volumeModels))
+	for i, m := range volumeModels {
+		stats.Volumes[i] = m.ToDomain()
+	}
+
+	var breachModels []models.LimitBreachModel
+	err = r.db.SelectContext(ctx, &breachModels, `SELECT u.id AS user_id, u.full_name, u.email,
+			COUNT(*) AS breaches, MAX(t.created_at) AS last_breach_at
+		FROM transactions t
+		JOIN accounts a ON a.id = t.account_id
+		JOIN users u ON u.id = a.user_id
+		WHERE t.fee > 0 AND t.created_at >= $1 AND t.created_at < $2
+		GROUP BY u.id, u.full_name, u.email
+		ORDER BY breaches DESC, last_breach_at DESC
+		LIMIT $3`, from, to, topBreaches)
+	if err != nil {
+		return stats, r.translateError(err)
+	}
+	stats.TopLimitBreaches = make([]domain.LimitBreach, len(breachModels))
+	for i, m := range breachModels {
+		stats.TopLimitBreaches[i] = m.ToDomain()
+	}
+
+	return stats, nil
+}
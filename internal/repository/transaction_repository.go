@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"log"
 
 	"github.com/MMII0220/MiniBank/internal/domain"
@@ -10,22 +11,30 @@ import (
 	"github.com/MMII0220/MiniBank/internal/repository/models"
 )
 
-func (r *Repository) DepositToAccount(accountID int, amount float64) error {
+func (r *Repository) DepositToAccount(ctx context.Context, accountID int, amount float64) error {
 	log := logger.GetLogger()
 	log.Info().
 		Int("account_id", accountID).
 		Float64("amount", amount).
 		Msg("Starting deposit transaction")
 
-	tx, err := r.db.Beginx()
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
 		log.Error().Err(err).Int("account_id", accountID).Msg("Failed to begin deposit transaction")
 		return r.translateError(err)
 	}
 	defer tx.Rollback()
 
+	// Ограничения перечитываются под блокировкой счета: проверка в service могла устареть
+	if err = r.lockAccounts(ctx, tx, accountID); err != nil {
+		return err
+	}
+	if err = r.checkCreditTx(ctx, tx, accountID); err != nil {
+		return err
+	}
+
 	// Обновляем баланс счета
-	result, err := tx.Exec(`UPDATE accounts SET balance = balance + $1 WHERE id = $2`, amount, accountID)
+	result, err := tx.ExecContext(ctx, `UPDATE accounts SET balance = balance + $1 WHERE id = $2`, amount, accountID)
 	if err != nil {
 		return r.translateError(err)
 	}
@@ -41,13 +50,13 @@ func (r *Repository) DepositToAccount(accountID int, amount float64) error {
 
 	// Получаем валюту счета для транзакции
 	var currency string
-	err = tx.Get(&currency, `SELECT currency FROM accounts WHERE id = $1`, accountID)
+	err = tx.GetContext(ctx, &currency, `SELECT currency FROM accounts WHERE id = $1`, accountID)
 	if err != nil {
 		return r.translateError(err)
 	}
 
 	// Создаем запись транзакции
-	_, err = tx.Exec(`INSERT INTO transactions (account_id, amount, currency, type) VALUES ($1, $2, $3, 'deposit')`, accountID, amount, currency)
+	_, err = tx.ExecContext(ctx, `INSERT INTO transactions (account_id, amount, currency, type) VALUES ($1, $2, $3, 'deposit')`, accountID, amount, currency)
 	if err != nil {
 		return r.translateError(err)
 	}
@@ -59,7 +68,7 @@ func (r *Repository) DepositToAccount(accountID int, amount float64) error {
 	}
 
 	// Удаляем кеш после успешного депозита
-	if cacheErr := redis.DeleteAccountCacheByAccountID(accountID); cacheErr != nil {
+	if cacheErr := redis.DeleteAccountCacheByAccountID(ctx, accountID); cacheErr != nil {
 		log.Warn().Err(cacheErr).Int("account_id", accountID).Msg("Failed to delete account cache after deposit")
 	}
 
@@ -67,15 +76,26 @@ func (r *Repository) DepositToAccount(accountID int, amount float64) error {
 	return nil
 }
 
-func (r *Repository) WithdrawFromAccount(accountID int, amount float64, currency string) error {
-	tx, err := r.db.Beginx()
+// WithdrawFromAccount списывает amount (вместе с комиссией fee за превышение лимита).
+// Ограничения и legal_hold перечитываются под блокировкой счета.
+func (r *Repository) WithdrawFromAccount(ctx context.Context, accountID int, amount, fee float64, currency string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return r.translateError(err)
 	}
 	defer tx.Rollback()
 
-	// Обновляем баланс (убираем проверку balance >= $1 из UPDATE)
-	result, err := tx.Exec(`UPDATE accounts SET balance = CAST(balance AS NUMERIC) - $1 WHERE id = $2 AND currency = $3`, amount, accountID, currency)
+	if err = r.lockAccounts(ctx, tx, accountID); err != nil {
+		return err
+	}
+	legalHold, err := r.checkDebitTx(ctx, tx, accountID)
+	if err != nil {
+		return err
+	}
+
+	// Списываем, если хватает баланса за вычетом удержаний под переводы и legal_hold
+	result, err := tx.ExecContext(ctx, `UPDATE accounts SET balance = CAST(balance AS NUMERIC) - $1
+		WHERE id = $2 AND currency = $3 AND balance - hold_amount - $4 >= $1`, amount, accountID, currency, legalHold)
 	if err != nil {
 		log.Printf("ERROR: Failed to update balance: %v", err)
 		return r.translateError(err)
@@ -87,10 +107,10 @@ func (r *Repository) WithdrawFromAccount(accountID int, amount float64, currency
 		return r.translateError(err)
 	}
 	if rowsAffected == 0 {
-		return errs.ErrAccountNotFound
+		return errs.ErrInsufficientFunds
 	}
 
-	_, err = tx.Exec(`INSERT INTO transactions (account_id, amount, currency, type) VALUES ($1, $2, $3, 'withdraw')`, accountID, amount, currency)
+	_, err = tx.ExecContext(ctx, `INSERT INTO transactions (account_id, amount, currency, type, fee) VALUES ($1, $2, $3, 'withdraw', $4)`, accountID, amount, currency, fee)
 	if err != nil {
 		log.Printf("ERROR: Failed to insert transaction: %v", err)
 		return r.translateError(err)
@@ -103,28 +123,39 @@ func (r *Repository) WithdrawFromAccount(accountID int, amount float64, currency
 	}
 
 	// Удаляем кеш после успешного снятия
-	if cacheErr := redis.DeleteAccountCacheByAccountID(accountID); cacheErr != nil {
+	if cacheErr := redis.DeleteAccountCacheByAccountID(ctx, accountID); cacheErr != nil {
 		log.Printf("WARNING: Failed to delete account cache after withdrawal: %v", cacheErr)
 	}
 
 	return nil
 }
 
-func (r *Repository) TransferFunds(fromAccountID, toAccountID int, amount float64) error {
-	tx, err := r.db.Beginx()
+// TransferFunds списывает с отправителя amount (сумма перевода вместе с комиссией fee),
+// получателю зачисляется amount - fee: комиссия остается у банка, как и при выполнении отложенного перевода
+func (r *Repository) TransferFunds(ctx context.Context, fromAccountID, toAccountID int, amount, fee float64) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return r.translateError(err)
 	}
 
-	// Гарантированный откат, если дальше что-то сломается
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
+	// Откат на любом раннем выходе, в том числе при нехватке средств: иначе блокировки строк висят до отмены ctx
+	defer tx.Rollback()
+
+	// Ограничения обоих счетов перечитываются под блокировкой: проверка в service могла устареть
+	if err = r.lockAccounts(ctx, tx, fromAccountID, toAccountID); err != nil {
+		return err
+	}
+	legalHold, err := r.checkDebitTx(ctx, tx, fromAccountID)
+	if err != nil {
+		return err
+	}
+	if err = r.checkCreditTx(ctx, tx, toAccountID); err != nil {
+		return err
+	}
 
-	// Списываем деньги, но только если хватает баланса
-	res, err := tx.Exec(`UPDATE accounts SET balance = balance - $1 WHERE id = $2 AND balance >= $1`, amount, fromAccountID)
+	// Списываем деньги, но только если хватает доступного баланса (без удержаний под переводы и legal_hold)
+	res, err := tx.ExecContext(ctx, `UPDATE accounts SET balance = balance - $1 WHERE id = $2 AND balance - hold_amount - $3 >= $1`,
+		amount, fromAccountID, legalHold)
 	if err != nil {
 		return r.translateError(err)
 	}
@@ -134,14 +165,15 @@ func (r *Repository) TransferFunds(fromAccountID, toAccountID int, amount float6
 		return errs.ErrInsufficientFunds
 	}
 
-	// Зачисляем получателю
-	_, err = tx.Exec(`UPDATE accounts SET balance = balance + $1 WHERE id = $2`, amount, toAccountID)
+	// Зачисляем получателю сумму перевода без комиссии
+	_, err = tx.ExecContext(ctx, `UPDATE accounts SET balance = balance + $1 WHERE id = $2`, amount-fee, toAccountID)
 	if err != nil {
 		return r.translateError(err)
 	}
 
 	// Логируем операцию
-	_, err = tx.Exec(`INSERT INTO transactions (account_id, amount, type) VALUES ($1, $2, 'transfer')`, fromAccountID, amount)
+	_, err = tx.ExecContext(ctx, `INSERT INTO transactions (account_id, amount, currency, type, fee)
+		VALUES ($1, $2, (SELECT currency FROM accounts WHERE id = $1), 'transfer', $3)`, fromAccountID, amount, fee)
 	if err != nil {
 		return r.translateError(err)
 	}
@@ -153,26 +185,26 @@ func (r *Repository) TransferFunds(fromAccountID, toAccountID int, amount float6
 	}
 
 	// Удаляем кеш для обоих аккаунтов после успешного перевода
-	if cacheErr := redis.DeleteAccountCacheByAccountID(fromAccountID); cacheErr != nil {
+	if cacheErr := redis.DeleteAccountCacheByAccountID(ctx, fromAccountID); cacheErr != nil {
 		log.Printf("WARNING: Failed to delete cache for sender account: %v", cacheErr)
 	}
-	if cacheErr := redis.DeleteAccountCacheByAccountID(toAccountID); cacheErr != nil {
+	if cacheErr := redis.DeleteAccountCacheByAccountID(ctx, toAccountID); cacheErr != nil {
 		log.Printf("WARNING: Failed to delete cache for recipient account: %v", cacheErr)
 	}
 
 	return nil
 }
 
-func (r *Repository) GetAccountByCardNumber(account *domain.Account, cardNumber string, currency string) error {
+func (r *Repository) GetAccountByCardNumber(ctx context.Context, account *domain.Account, cardNumber string, currency string) error {
 	var accountModel models.AccountModel
 	query := `
-		SELECT a.id, a.user_id, a.currency, a.balance, a.blocked
+		SELECT a.id, a.user_id, a.currency, a.balance, a.hold_amount, ` + accountBlockedColumn + `, a.account_type
 		FROM accounts a
 		JOIN cards c ON c.account_id = a.id
 		WHERE c.card_number = $1 AND a.currency = $2
 	`
 
-	err := r.db.Get(&accountModel, query, cardNumber, currency)
+	err := r.db.GetContext(ctx, &accountModel, query, cardNumber, currency)
 	if err != nil {
 		return r.translateError(err)
 	}
@@ -181,16 +213,16 @@ func (r *Repository) GetAccountByCardNumber(account *domain.Account, cardNumber
 	return nil
 }
 
-func (r *Repository) GetAccountByPhoneNumber(account *domain.Account, phoneNumber string, currency string) error {
+func (r *Repository) GetAccountByPhoneNumber(ctx context.Context, account *domain.Account, phoneNumber string, currency string) error {
 	var accountModel models.AccountModel
 	query := `
-        SELECT a.id, a.user_id, a.currency, a.balance, a.blocked
+        SELECT a.id, a.user_id, a.currency, a.balance, a.hold_amount, ` + accountBlockedColumn + `, a.account_type
         FROM accounts a
         JOIN users u ON u.id = a.user_id
         WHERE u.phone = $1 AND a.currency = $2
     `
 
-	err := r.db.Get(&accountModel, query, phoneNumber, currency)
+	err := r.db.GetContext(ctx, &accountModel, query, phoneNumber, currency)
 	if err != nil {
 		log.Printf("ERROR: Аккаунт не найден: %v", err)
 		return r.translateError(err)
@@ -201,7 +233,7 @@ func (r *Repository) GetAccountByPhoneNumber(account *domain.Account, phoneNumbe
 }
 
 // GetTransactionHistory возвращает список транзакций пользователя
-func (r *Repository) GetTransactionHistory(idUser int) ([]domain.Transaction, error) {
+func (r *Repository) GetTransactionHistory(ctx context.Context, idUser int) ([]domain.Transaction, error) {
 	query := `
 		SELECT t.id, t.amount, t.currency, t.type, t.created_at
 		FROM transactions t
@@ -211,7 +243,7 @@ func (r *Repository) GetTransactionHistory(idUser int) ([]domain.Transaction, er
 	`
 
 	var transactionModels []models.TransactionModel
-	err := r.db.Select(&transactionModels, query, idUser)
+	err := r.db.SelectContext(ctx, &transactionModels, query, idUser)
 	if err != nil {
 		return nil, r.translateError(err)
 	}
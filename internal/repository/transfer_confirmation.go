@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/logger"
+	"github.com/MMII0220/MiniBank/internal/repository/models"
+)
+
+// CreateTransferConfirmation сохраняет перевод, ожидающий ввода кода
+func (r *Repository) CreateTransferConfirmation(ctx context.Context, c *domain.TransferConfirmation) error {
+	log := logger.GetLogger()
+	log.Info().Int("user_id", c.UserID).Float64("amount", c.Request.Amount).Msg("Creating transfer confirmation")
+
+	err := r.db.QueryRowContext(ctx, `INSERT INTO transfer_confirmations
+			(user_id, from_card_number, from_phone_number, to_card_number, to_phone_number, amount, currency, code_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, status, created_at`,
+		c.UserID, c.Request.FromCardNumber, c.Request.FromPhoneNumber, c.Request.ToCardNumber, c.Request.ToPhoneNumber,
+		c.Request.Amount, c.Request.Currency, c.CodeHash, c.ExpiresAt,
+	).Scan(&c.ID, &c.Status, &c.CreatedAt)
+	if err != nil {
+		return r.translateError(err)
+	}
+	return nil
+}
+
+func (r *Repository) GetTransferConfirmation(ctx context.Context, id int) (domain.TransferConfirmation, error) {
+	var confirmationModel models.TransferConfirmationModel
+	query := `SELECT id, user_id, from_card_number, from_phone_number, to_card_number, to_phone_number,
+			amount, currency, code_hash, attempts, status, expires_at, created_at, confirmed_at
+		FROM transfer_confirmations WHERE id = $1`
+	if err := r.db.GetContext(ctx, &confirmationModel, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.TransferConfirmation{}, errs.ErrTransferConfirmationNotFound
+		}
+		return domain.TransferConfirmation{}, r.translateError(err)
+	}
+	return confirmationModel.ToDomain(), nil
+}
+
+// RecordTransferConfirmationAttempt учитывает неверный код; на maxAttempts-й попытке подтверждение закрывается.
+// Возвращает число сделанных попыток.
+func (r *Repository) RecordTransferConfirmationAttempt(ctx context.Context, id int, maxAttempts int) (int, error) {
+	var attempts int
+	err := r.db.QueryRowContext(ctx, `UPDATE transfer_confirmations
+		SET attempts = attempts + 1,
+			status = CASE WHEN attempts + 1 >= $2 THEN 'failed' ELSE status END
+		WHERE id = $1 AND status = 'pending'
+		RETURNING attempts`, id, maxAttempts).Scan(&attempts)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, errs.ErrInvalidOperation
+		}
+		return 0, r.translateError(err)
+	}
+	return attempts, nil
+}
+
+// CloseTransferConfirmation переводит ожидающее подтверждение в конечный статус.
+// Если подтверждение уже закрыто параллельным запросом - ErrInvalidOperation.
+func (r *Repository) CloseTransferConfirmation(ctx context.Context, id int, status domain.TransferConfirmationStatus) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE transfer_confirmations
+		SET status = $1, confirmed_at = CASE WHEN $1 = 'confirmed' THEN NOW() ELSE NULL END
+		WHERE id = $2 AND status = 'pending'`, string(status), id)
+	if err != nil {
+		return r.translateError(err)
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return errs.ErrInvalidOperation
+	}
+	return nil
+}
+
+// IsKnownRecipient - пользователь уже переводил на этот счет
+func (r *Repository) IsKnownRecipient(ctx context.Context, userID, accountID int) (bool, error) {
+	var known bool
+	err := r.db.GetContext(ctx, &known, `SELECT EXISTS(SELECT 1 FROM known_recipients WHERE user_id = $1 AND account_id = $2)`, userID, accountID)
+	if err != nil {
+		return false, r.translateError(err)
+	}
+	return known, nil
+}
+
+func (r *Repository) AddKnownRecipient(ctx context.Context, userID, accountID int) error {
+	_, err := r.db.ExecContext(ctx, `INSERT INTO known_recipients (user_id, account_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, userID, accountID)
+	if err != nil {
+		return r.translateError(err)
+	}
+	return nil
+}
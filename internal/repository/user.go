@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"context"
+
 	"github.com/MMII0220/MiniBank/internal/domain"
 	"github.com/MMII0220/MiniBank/internal/errs"
 	"github.com/MMII0220/MiniBank/internal/logger"
@@ -8,7 +10,7 @@ import (
 	"github.com/MMII0220/MiniBank/internal/repository/models"
 )
 
-func (r *Repository) CreateUser(user *domain.User) error {
+func (r *Repository) CreateUser(ctx context.Context, user *domain.User) error {
 	log := logger.GetLogger()
 	log.Info().Str("email", user.Email).Msg("Creating new user")
 
@@ -18,7 +20,7 @@ func (r *Repository) CreateUser(user *domain.User) error {
 		VALUES ($1, $2, $3, $4, $5)
 		RETURNING id
 	`
-	err := r.db.Get(&userModel.ID, query,
+	err := r.db.GetContext(ctx, &userModel.ID, query,
 		userModel.FullName, userModel.Phone, userModel.Email, userModel.Password, userModel.Role)
 	if err != nil {
 		log.Error().Err(err).Str("email", user.Email).Msg("Failed to create user")
@@ -30,13 +32,13 @@ func (r *Repository) CreateUser(user *domain.User) error {
 	return nil
 }
 
-func (r *Repository) GetUserByEmail(email string) (*domain.User, error) {
+func (r *Repository) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
 	log := logger.GetLogger()
 	log.Debug().Str("email", email).Msg("Searching user by email")
 
 	var userModel models.UserModel
-	query := `SELECT id, email, password, role FROM users WHERE email = $1`
-	err := r.db.Get(&userModel, query, email)
+	query := `SELECT id, email, password, role, disabled_at FROM users WHERE email = $1`
+	err := r.db.GetContext(ctx, &userModel, query, email)
 	if err != nil {
 		// Если пользователь не найден, возвращаем специфичную ошибку
 		if err.Error() == "sql: no rows in result set" {
@@ -52,7 +54,29 @@ func (r *Repository) GetUserByEmail(email string) (*domain.User, error) {
 	return &domainUser, nil
 }
 
-func (r *Repository) CreateAccount(account *domain.Account) error {
+func (r *Repository) GetUserByID(ctx context.Context, userID int) (*domain.User, error) {
+	log := logger.GetLogger()
+	log.Debug().Int("user_id", userID).Msg("Searching user by id")
+
+	var userModel models.UserModel
+	query := `SELECT id, full_name, phone, email, password, role, created_at, COALESCE(updated_at, created_at) AS updated_at,
+			email_verified_at, phone_verified_at, disabled_at
+		FROM users WHERE id = $1`
+	err := r.db.GetContext(ctx, &userModel, query, userID)
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			log.Debug().Int("user_id", userID).Msg("User not found")
+			return nil, errs.ErrUserNotFound
+		}
+		log.Error().Err(err).Int("user_id", userID).Msg("Failed to get user by id")
+		return nil, r.translateError(err)
+	}
+
+	domainUser := userModel.ToDomain()
+	return &domainUser, nil
+}
+
+func (r *Repository) CreateAccount(ctx context.Context, account *domain.Account) error {
 	log := logger.GetLogger()
 	log.Info().
 		Int("user_id", account.UserID).
@@ -62,11 +86,11 @@ func (r *Repository) CreateAccount(account *domain.Account) error {
 
 	accountModel := models.AccountFromDomain(*account)
 	query := `
-        INSERT INTO accounts (user_id, currency, balance, blocked, created_at)
-        VALUES ($1, $2, $3, $4, NOW())
+        INSERT INTO accounts (user_id, currency, balance, created_at)
+        VALUES ($1, $2, $3, NOW())
         RETURNING id
     `
-	err := r.db.QueryRow(query, accountModel.UserID, accountModel.Currency, accountModel.Balance, accountModel.Blocked).Scan(&accountModel.ID)
+	err := r.db.QueryRowContext(ctx, query, accountModel.UserID, accountModel.Currency, accountModel.Balance).Scan(&accountModel.ID)
 	if err != nil {
 		log.Error().Err(err).Int("user_id", account.UserID).Msg("Failed to create account")
 		return r.translateError(err)
@@ -75,7 +99,7 @@ func (r *Repository) CreateAccount(account *domain.Account) error {
 	account.ID = accountModel.ID
 
 	// Удаляем кеш аккаунтов пользователя после создания нового аккаунта
-	if cacheErr := redis.DeleteAccountsCache(account.UserID); cacheErr != nil {
+	if cacheErr := redis.DeleteAccountsCache(ctx, account.UserID); cacheErr != nil {
 		log.Warn().Err(cacheErr).Int("user_id", account.UserID).Msg("Failed to delete account cache after creating account")
 	}
 
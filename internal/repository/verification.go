@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/logger"
+	"github.com/MMII0220/MiniBank/internal/repository/models"
+)
+
+// CreateContactVerification сохраняет новый код, прежние ожидающие коды того же канала перестают действовать
+func (r *Repository) CreateContactVerification(ctx context.Context, v *domain.ContactVerification) error {
+	log := logger.GetLogger()
+	log.Info().Int("user_id", v.UserID).Str("channel", string(v.Channel)).Msg("Creating contact verification")
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return r.translateError(err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `UPDATE contact_verifications SET status = 'superseded'
+		WHERE user_id = $1 AND channel = $2 AND status = 'pending'`, v.UserID, string(v.Channel))
+	if err != nil {
+		return r.translateError(err)
+	}
+
+	err = tx.QueryRowContext(ctx, `INSERT INTO contact_verifications (user_id, channel, destination, code_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, status, created_at`,
+		v.UserID, string(v.Channel), v.Destination, v.CodeHash, v.ExpiresAt,
+	).Scan(&v.ID, &v.Status, &v.CreatedAt)
+	if err != nil {
+		return r.translateError(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return r.translateError(err)
+	}
+	return nil
+}
+
+// GetPendingContactVerification - последний ожидающий код канала
+func (r *Repository) GetPendingContactVerification(ctx context.Context, userID int, channel domain.VerificationChannel) (domain.ContactVerification, error) {
+	var verificationModel models.ContactVerificationModel
+	query := `SELECT id, user_id, channel, destination, code_hash, attempts, status, expires_at, created_at, verified_at
+		FROM contact_verifications
+		WHERE user_id = $1 AND channel = $2 AND status = 'pending'
+		ORDER BY created_at DESC LIMIT 1`
+	if err := r.db.GetContext(ctx, &verificationModel, query, userID, string(channel)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ContactVerification{}, errs.ErrVerificationNotFound
+		}
+		return domain.ContactVerification{}, r.translateError(err)
+	}
+	return verificationModel.ToDomain(), nil
+}
+
+// RecordContactVerificationAttempt учитывает неверный код; на maxAttempts-й попытке код закрывается.
+// Возвращает число сделанных попыток.
+func (r *Repository) RecordContactVerificationAttempt(ctx context.Context, id int, maxAttempts int) (int, error) {
+	var attempts int
+	err := r.db.QueryRowContext(ctx, `UPDATE contact_verifications
+		SET attempts = attempts + 1,
+			status = CASE WHEN attempts + 1 >= $2 THEN 'failed' ELSE status END
+		WHERE id = $1 AND status = 'pending'
+		RETURNING attempts`, id, maxAttempts).Scan(&attempts)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, errs.ErrVerificationNotFound
+		}
+		return 0, r.translateError(err)
+	}
+	return attempts, nil
+}
+
+// CompleteContactVerification закрывает код и отмечает email или телефон подтвержденным.
+// Если адрес пользователя уже сменился, подтверждение не засчитывается.
+func (r *Repository) CompleteContactVerification(ctx context.Context, v domain.ContactVerification) error {
+	log := logger.GetLogger()
+	log.Info().Int("user_id", v.UserID).Str("channel", string(v.Channel)).Msg("Completing contact verification")
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return r.translateError(err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE contact_verifications SET status = 'verified', verified_at = NOW()
+		WHERE id = $1 AND status = 'pending'`, v.ID)
+	if err != nil {
+		return r.translateError(err)
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return errs.ErrVerificationNotFound
+	}
+
+	query := `UPDATE users SET email_verified_at = NOW() WHERE id = $1 AND email = $2`
+	if v.Channel == domain.VerifyPhone {
+		query = `UPDATE users SET phone_verified_at = NOW() WHERE id = $1 AND phone = $2`
+	}
+	res, err = tx.ExecContext(ctx, query, v.UserID, v.Destination)
+	if err != nil {
+		return r.translateError(err)
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return errs.ErrVerificationNotFound
+	}
+
+	if err = tx.Commit(); err != nil {
+		return r.translateError(err)
+	}
+	return nil
+}
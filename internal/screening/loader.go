@@ -0,0 +1,278 @@
+package screening
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+)
+
+// LoadFile загружает список из локального файла, формат определяется по расширению
+func LoadFile(path string) ([]domain.WatchlistEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open watchlist %s: %w", path, err)
+	}
+	defer f.Close()
+
+	source := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return LoadCSV(f, source)
+	case ".xml":
+		return LoadXML(f, source)
+	default:
+		return nil, fmt.Errorf("unsupported watchlist format: %s", path)
+	}
+}
+
+// LoadCSV читает CSV двух видов:
+//   - с заголовком, где есть колонка "name" (опционально id, aliases через ";", program, source);
+//   - OFAC sdn.csv без заголовка: ent_num, SDN_Name, SDN_Type, Program, ...
+func LoadCSV(r io.Reader, source string) ([]domain.WatchlistEntry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("parse watchlist csv: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	columns := make(map[string]int)
+	for i, h := range rows[0] {
+		columns[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+
+	var entries []domain.WatchlistEntry
+	if nameCol, ok := columns["name"]; ok {
+		for i, row := range rows[1:] {
+			name := column(row, nameCol)
+			if name == "" {
+				continue
+			}
+			entry := domain.WatchlistEntry{
+				ID:      fmt.Sprintf("%d", i+1),
+				Name:    name,
+				Source:  source,
+				Program: columnByName(row, columns, "program"),
+			}
+			if id := columnByName(row, columns, "id"); id != "" {
+				entry.ID = id
+			}
+			if src := columnByName(row, columns, "source"); src != "" {
+				entry.Source = src
+			}
+			for _, alias := range strings.Split(columnByName(row, columns, "aliases"), ";") {
+				if alias = strings.TrimSpace(alias); alias != "" {
+					entry.Aliases = append(entry.Aliases, alias)
+				}
+			}
+			entries = append(entries, entry)
+		}
+		return entries, nil
+	}
+
+	// OFAC sdn.csv: пустые значения там обозначены как "-0-"
+	for _, row := range rows {
+		name := column(row, 1)
+		if name == "" || name == "-0-" {
+			continue
+		}
+		program := column(row, 3)
+		if program == "-0-" {
+			program = ""
+		}
+		entries = append(entries, domain.WatchlistEntry{
+			ID:      column(row, 0),
+			Name:    ofacName(name),
+			Source:  source,
+			Program: program,
+		})
+	}
+	return entries, nil
+}
+
+// LoadXML читает OFAC SDN XML (<sdnList>) или консолидированный список ООН (<CONSOLIDATED_LIST>)
+func LoadXML(r io.Reader, source string) ([]domain.WatchlistEntry, error) {
+	decoder := xml.NewDecoder(r)
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, errors.New("watchlist xml has no root element")
+			}
+			return nil, fmt.Errorf("parse watchlist xml: %w", err)
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "sdnList":
+			var list ofacList
+			if err := decoder.DecodeElement(&list, &start); err != nil {
+				return nil, fmt.Errorf("parse ofac xml: %w", err)
+			}
+			return list.toDomain(source), nil
+		case "CONSOLIDATED_LIST":
+			var list unList
+			if err := decoder.DecodeElement(&list, &start); err != nil {
+				return nil, fmt.Errorf("parse un xml: %w", err)
+			}
+			return list.toDomain(source), nil
+		default:
+			return nil, fmt.Errorf("unsupported watchlist xml root: %s", start.Name.Local)
+		}
+	}
+}
+
+// OFAC SDN XML
+type ofacList struct {
+	Entries []ofacEntry `xml:"sdnEntry"`
+}
+
+type ofacEntry struct {
+	UID       string    `xml:"uid"`
+	FirstName string    `xml:"firstName"`
+	LastName  string    `xml:"lastName"`
+	Programs  []string  `xml:"programList>program"`
+	Akas      []ofacAka `xml:"akaList>aka"`
+}
+
+type ofacAka struct {
+	FirstName string `xml:"firstName"`
+	LastName  string `xml:"lastName"`
+}
+
+func (l ofacList) toDomain(source string) []domain.WatchlistEntry {
+	entries := make([]domain.WatchlistEntry, 0, len(l.Entries))
+	for _, e := range l.Entries {
+		entry := domain.WatchlistEntry{
+			ID:      e.UID,
+			Name:    joinName(e.FirstName, e.LastName),
+			Source:  source,
+			Program: strings.Join(e.Programs, ","),
+		}
+		for _, aka := range e.Akas {
+			if alias := joinName(aka.FirstName, aka.LastName); alias != "" {
+				entry.Aliases = append(entry.Aliases, alias)
+			}
+		}
+		if entry.Name != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// Консолидированный санкционный список ООН
+type unList struct {
+	Individuals []unIndividual `xml:"INDIVIDUALS>INDIVIDUAL"`
+	Entities    []unEntity     `xml:"ENTITIES>ENTITY"`
+}
+
+type unIndividual struct {
+	DataID     string    `xml:"DATAID"`
+	FirstName  string    `xml:"FIRST_NAME"`
+	SecondName string    `xml:"SECOND_NAME"`
+	ThirdName  string    `xml:"THIRD_NAME"`
+	FourthName string    `xml:"FOURTH_NAME"`
+	ListType   string    `xml:"UN_LIST_TYPE"`
+	Aliases    []unAlias `xml:"INDIVIDUAL_ALIAS"`
+}
+
+type unEntity struct {
+	DataID   string    `xml:"DATAID"`
+	Name     string    `xml:"FIRST_NAME"`
+	ListType string    `xml:"UN_LIST_TYPE"`
+	Aliases  []unAlias `xml:"ENTITY_ALIAS"`
+}
+
+type unAlias struct {
+	Name string `xml:"ALIAS_NAME"`
+}
+
+func (l unList) toDomain(source string) []domain.WatchlistEntry {
+	entries := make([]domain.WatchlistEntry, 0, len(l.Individuals)+len(l.Entities))
+	for _, ind := range l.Individuals {
+		entry := domain.WatchlistEntry{
+			ID:      ind.DataID,
+			Name:    joinName(ind.FirstName, ind.SecondName, ind.ThirdName, ind.FourthName),
+			Source:  source,
+			Program: ind.ListType,
+		}
+		entry.Aliases = unAliases(ind.Aliases)
+		if entry.Name != "" {
+			entries = append(entries, entry)
+		}
+	}
+	for _, ent := range l.Entities {
+		entry := domain.WatchlistEntry{
+			ID:      ent.DataID,
+			Name:    strings.TrimSpace(ent.Name),
+			Source:  source,
+			Program: ent.ListType,
+		}
+		entry.Aliases = unAliases(ent.Aliases)
+		if entry.Name != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func unAliases(aliases []unAlias) []string {
+	var result []string
+	for _, a := range aliases {
+		if name := strings.TrimSpace(a.Name); name != "" {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+// ofacName переводит "LAST, First Middle" в "First Middle LAST"
+func ofacName(name string) string {
+	parts := strings.SplitN(name, ",", 2)
+	if len(parts) != 2 {
+		return strings.TrimSpace(name)
+	}
+	return joinName(parts[1], parts[0])
+}
+
+func joinName(parts ...string) string {
+	var nonEmpty []string
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+	return strings.Join(nonEmpty, " ")
+}
+
+func column(row []string, idx int) string {
+	if idx < 0 || idx >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[idx])
+}
+
+func columnByName(row []string, columns map[string]int, name string) string {
+	idx, ok := columns[name]
+	if !ok {
+		return ""
+	}
+	return column(row, idx)
+}
@@ -0,0 +1,117 @@
+package screening
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Таблица транслитерации кириллицы (русский + таджикский алфавит) в латиницу
+var cyrillicToLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya",
+	// таджикские буквы
+	'ғ': "gh", 'ӣ': "i", 'қ': "q", 'ӯ': "u", 'ҳ': "h", 'ҷ': "j",
+}
+
+// Фонетические упрощения, чтобы разные латинские написания сводились к одному
+// (Mohammad/Muhammad, Yusuf/Iusuf, Dzhamshed/Jamshed и т.п.)
+var latinFolding = strings.NewReplacer(
+	"shch", "sh",
+	"dzh", "j",
+	"zh", "j",
+	"kh", "h",
+	"gh", "g",
+	"ph", "f",
+	"ck", "k",
+	"ks", "x",
+	"ts", "c",
+	"yu", "u",
+	"iu", "u",
+	"ya", "a",
+	"ia", "a",
+	"ye", "e",
+	"ou", "u",
+	"oo", "u",
+	"ee", "i",
+	"w", "v",
+	"q", "k",
+	"y", "i",
+	"o", "u",
+)
+
+// Transliterate переводит кириллицу в латиницу, остальные символы приводит к нижнему регистру
+func Transliterate(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if lat, ok := cyrillicToLatin[r]; ok {
+			b.WriteString(lat)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// NormalizeName приводит имя к виду для сравнения: латиница, без знаков, одиночные пробелы
+func NormalizeName(name string) string {
+	translit := Transliterate(name)
+
+	var b strings.Builder
+	for _, r := range translit {
+		switch {
+		case r >= 'a' && r <= 'z':
+			b.WriteRune(r)
+		case unicode.IsLetter(r):
+			// латиница с диакритикой и прочие буквы - оставляем только базовые
+			if base := stripDiacritic(r); base != 0 {
+				b.WriteRune(base)
+			}
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// nameTokens возвращает нормализованные и фонетически свернутые токены имени
+func nameTokens(name string) []string {
+	fields := strings.Fields(NormalizeName(name))
+	tokens := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if len(f) < 2 {
+			continue // инициалы не участвуют в сравнении
+		}
+		tokens = append(tokens, latinFolding.Replace(f))
+	}
+	return tokens
+}
+
+// stripDiacritic - минимальная замена для часто встречающихся в списках символов
+func stripDiacritic(r rune) rune {
+	switch r {
+	case 'á', 'à', 'â', 'ä', 'ã', 'å', 'ā':
+		return 'a'
+	case 'é', 'è', 'ê', 'ë', 'ē':
+		return 'e'
+	case 'í', 'ì', 'î', 'ï', 'ī':
+		return 'i'
+	case 'ó', 'ò', 'ô', 'ö', 'õ', 'ō':
+		return 'o'
+	case 'ú', 'ù', 'û', 'ü', 'ū':
+		return 'u'
+	case 'ç':
+		return 'c'
+	case 'ñ':
+		return 'n'
+	case 'ş', 'š':
+		return 's'
+	case 'ž':
+		return 'z'
+	case 'ğ':
+		return 'g'
+	}
+	return 0
+}
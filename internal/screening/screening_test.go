@@ -0,0 +1,109 @@
+package screening
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+)
+
+func TestNormalizeName_CyrillicAndLatin(t *testing.T) {
+	cases := map[string]string{
+		"Джамшед Рахимов":    "dzhamshed rakhimov",
+		"  O'Brien,  JOHN ":  "o brien john",
+		"Ҷамшед Қодиров":     "jamshed qodirov",
+		"José Müller-Schmid": "jose muller schmid",
+	}
+	for in, want := range cases {
+		if got := NormalizeName(in); got != want {
+			t.Fatalf("NormalizeName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestStore_Screen_FuzzyAndTransliterated(t *testing.T) {
+	s := NewStore(DefaultThreshold)
+	s.Add(
+		domain.WatchlistEntry{ID: "1", Name: "Dzhamshed Rakhimov", Source: "UN"},
+		domain.WatchlistEntry{ID: "2", Name: "Ivan Petrov", Aliases: []string{"Ivan Petroff"}, Source: "OFAC"},
+	)
+
+	// кириллица против латиницы
+	matches := s.Screen("Джамшед Рахимов")
+	if len(matches) != 1 || matches[0].Entry.ID != "1" {
+		t.Fatalf("expected match on entry 1, got %+v", matches)
+	}
+
+	// другой порядок слов и опечатка
+	matches = s.Screen("Petrow Ivan")
+	if len(matches) != 1 || matches[0].Entry.ID != "2" {
+		t.Fatalf("expected match on entry 2, got %+v", matches)
+	}
+
+	// непохожее имя
+	if matches := s.Screen("Anna Karimova"); len(matches) != 0 {
+		t.Fatalf("expected no matches, got %+v", matches)
+	}
+}
+
+func TestLoadCSV_HeaderAndOFAC(t *testing.T) {
+	withHeader := "id,name,aliases,program\n10,Ivan Petrov,Ivan Petroff;I. Petrov,SDGT\n"
+	entries, err := LoadCSV(strings.NewReader(withHeader), "local")
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("unexpected: %v %+v", err, entries)
+	}
+	if entries[0].ID != "10" || len(entries[0].Aliases) != 2 || entries[0].Program != "SDGT" {
+		t.Fatalf("unexpected entry: %+v", entries[0])
+	}
+
+	ofac := "36,\"AEROCARIBBEAN AIRLINES\",-0-,\"CUBA\",-0-\n173,\"ALI, Hassan\",\"individual\",\"SDGT\",-0-\n"
+	entries, err = LoadCSV(strings.NewReader(ofac), "sdn")
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("unexpected: %v %+v", err, entries)
+	}
+	if entries[1].Name != "Hassan ALI" {
+		t.Fatalf("expected reordered name, got %q", entries[1].Name)
+	}
+}
+
+func TestLoadXML_OFACAndUN(t *testing.T) {
+	ofac := `<?xml version="1.0"?>
+<sdnList xmlns="http://tempuri.org/sdnList.xsd">
+  <sdnEntry>
+    <uid>306</uid>
+    <firstName>Hassan</firstName>
+    <lastName>ALI</lastName>
+    <programList><program>SDGT</program></programList>
+    <akaList><aka><firstName>Hasan</firstName><lastName>ALLI</lastName></aka></akaList>
+  </sdnEntry>
+</sdnList>`
+	entries, err := LoadXML(strings.NewReader(ofac), "sdn")
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("unexpected: %v %+v", err, entries)
+	}
+	if entries[0].Name != "Hassan ALI" || len(entries[0].Aliases) != 1 {
+		t.Fatalf("unexpected entry: %+v", entries[0])
+	}
+
+	un := `<CONSOLIDATED_LIST>
+  <INDIVIDUALS>
+    <INDIVIDUAL>
+      <DATAID>6908555</DATAID>
+      <FIRST_NAME>ABDUL</FIRST_NAME>
+      <SECOND_NAME>RAHMAN</SECOND_NAME>
+      <UN_LIST_TYPE>Al-Qaida</UN_LIST_TYPE>
+      <INDIVIDUAL_ALIAS><QUALITY>Good</QUALITY><ALIAS_NAME>Abdurakhmon</ALIAS_NAME></INDIVIDUAL_ALIAS>
+    </INDIVIDUAL>
+  </INDIVIDUALS>
+  <ENTITIES>
+    <ENTITY><DATAID>1</DATAID><FIRST_NAME>SOME FOUNDATION</FIRST_NAME></ENTITY>
+  </ENTITIES>
+</CONSOLIDATED_LIST>`
+	entries, err = LoadXML(strings.NewReader(un), "un")
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("unexpected: %v %+v", err, entries)
+	}
+	if entries[0].Name != "ABDUL RAHMAN" || entries[0].Aliases[0] != "Abdurakhmon" {
+		t.Fatalf("unexpected entry: %+v", entries[0])
+	}
+}
@@ -0,0 +1,223 @@
+package screening
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+)
+
+// DefaultThreshold - минимальная схожесть имени, при которой совпадение уходит на проверку
+const DefaultThreshold = 0.88
+
+// indexed - одно имя (основное или alias) записи списка в нормализованном виде
+type indexed struct {
+	entry  int
+	name   string
+	tokens []string
+}
+
+// Store - индексированное хранилище санкционных списков в памяти
+type Store struct {
+	mu        sync.RWMutex
+	entries   []domain.WatchlistEntry
+	names     []indexed
+	index     map[string][]int // префикс токена -> индексы в names
+	threshold float64
+}
+
+func NewStore(threshold float64) *Store {
+	if threshold <= 0 || threshold > 1 {
+		threshold = DefaultThreshold
+	}
+	return &Store{
+		index:     make(map[string][]int),
+		threshold: threshold,
+	}
+}
+
+// Add добавляет записи в хранилище и индексирует основное имя и все alias
+func (s *Store) Add(entries ...domain.WatchlistEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range entries {
+		s.entries = append(s.entries, e)
+		entryIdx := len(s.entries) - 1
+
+		for _, name := range append([]string{e.Name}, e.Aliases...) {
+			tokens := nameTokens(name)
+			if len(tokens) == 0 {
+				continue
+			}
+			s.names = append(s.names, indexed{entry: entryIdx, name: name, tokens: tokens})
+			nameIdx := len(s.names) - 1
+			for _, key := range indexKeys(tokens) {
+				s.index[key] = append(s.index[key], nameIdx)
+			}
+		}
+	}
+}
+
+// Len возвращает количество загруженных записей
+func (s *Store) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.entries)
+}
+
+// Screen проверяет имя по всем спискам и возвращает совпадения, отсортированные по убыванию схожести
+func (s *Store) Screen(name string) []domain.ScreeningMatch {
+	tokens := nameTokens(name)
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// Кандидаты - имена, у которых есть токен с тем же префиксом
+	candidates := make(map[int]struct{})
+	for _, key := range indexKeys(tokens) {
+		for _, nameIdx := range s.index[key] {
+			candidates[nameIdx] = struct{}{}
+		}
+	}
+
+	// Для каждой записи списка оставляем лучшее совпадение среди ее имен
+	best := make(map[int]domain.ScreeningMatch)
+	for nameIdx := range candidates {
+		n := s.names[nameIdx]
+		score := nameSimilarity(tokens, n.tokens)
+		if score < s.threshold {
+			continue
+		}
+		if prev, ok := best[n.entry]; ok && prev.Score >= score {
+			continue
+		}
+		best[n.entry] = domain.ScreeningMatch{
+			Entry:       s.entries[n.entry],
+			MatchedName: n.name,
+			Score:       score,
+		}
+	}
+
+	matches := make([]domain.ScreeningMatch, 0, len(best))
+	for _, m := range best {
+		matches = append(matches, m)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score == matches[j].Score {
+			return matches[i].Entry.ID < matches[j].Entry.ID
+		}
+		return matches[i].Score > matches[j].Score
+	})
+	return matches
+}
+
+// indexKeys - ключи индекса: первые две буквы каждого токена.
+// Опечатки в начале имени встречаются редко, поэтому этого достаточно для отбора кандидатов.
+func indexKeys(tokens []string) []string {
+	keys := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		if len(t) >= 2 {
+			keys = append(keys, t[:2])
+		}
+	}
+	return keys
+}
+
+// nameSimilarity сравнивает два имени без учета порядка слов.
+// Каждому токену короткого имени подбирается лучший токен длинного имени,
+// лишние токены длинного имени немного снижают итоговый балл.
+func nameSimilarity(a, b []string) float64 {
+	short, long := a, b
+	if len(short) > len(long) {
+		short, long = long, short
+	}
+
+	used := make([]bool, len(long))
+	var total float64
+	for _, t := range short {
+		bestScore, bestIdx := 0.0, -1
+		for i, u := range long {
+			if used[i] {
+				continue
+			}
+			if sc := jaroWinkler(t, u); sc > bestScore {
+				bestScore, bestIdx = sc, i
+			}
+		}
+		if bestIdx >= 0 {
+			used[bestIdx] = true
+		}
+		total += bestScore
+	}
+
+	score := total / float64(len(short))
+	// Штраф за непокрытые токены: "Ali" не должен полностью совпадать с "Ali Hassan Mahmoud"
+	missing := len(long) - len(short)
+	return score * (1 - 0.05*float64(missing))
+}
+
+// jaroWinkler - классическая метрика Джаро-Винклера для коротких строк
+func jaroWinkler(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	la, lb := len(a), len(b)
+	if la == 0 || lb == 0 {
+		return 0
+	}
+
+	window := max(la, lb)/2 - 1
+	if window < 0 {
+		window = 0
+	}
+
+	matchedA := make([]bool, la)
+	matchedB := make([]bool, lb)
+	matches := 0
+	for i := 0; i < la; i++ {
+		start := max(0, i-window)
+		end := min(lb, i+window+1)
+		for j := start; j < end; j++ {
+			if matchedB[j] || a[i] != b[j] {
+				continue
+			}
+			matchedA[i], matchedB[j] = true, true
+			matches++
+			break
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions := 0
+	k := 0
+	for i := 0; i < la; i++ {
+		if !matchedA[i] {
+			continue
+		}
+		for !matchedB[k] {
+			k++
+		}
+		if a[i] != b[k] {
+			transpositions++
+		}
+		k++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(la) + m/float64(lb) + (m-float64(transpositions)/2)/m) / 3
+
+	prefix := 0
+	for i := 0; i < min(4, la, lb); i++ {
+		if a[i] != b[i] {
+			break
+		}
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
+)
+
+// AdjustBalance - зачисление или списание админом с обязательным кодом причины и комментарием.
+// Проводится транзакцией типа adjustment; балансы до и после пишутся в balance_adjustments, account_audit и audit_events.
+// Без второго админа проходят только мелкие корректировки: не больше ADJUSTMENT_NO_APPROVAL_LIMIT TJS за раз
+// и не больше ADJUSTMENT_DAILY_CEILING TJS за день. Остальное предлагается как manual_adjustment (four-eyes).
+func (s *Service) AdjustBalance(ctx context.Context, adminID, accountID int, req domain.ReqBalanceAdjustment) (domain.BalanceAdjustment, error) {
+	note := strings.TrimSpace(req.Note)
+	if !req.Direction.Valid() || !req.ReasonCode.Valid() || note == "" {
+		return domain.BalanceAdjustment{}, errs.ErrInvalidData
+	}
+	amount := math.Round(req.Amount*100) / 100
+	if amount <= 0 {
+		return domain.BalanceAdjustment{}, errs.ErrInvalidAmount
+	}
+
+	account, err := s.repo.GetAccountByID(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, errs.ErrAccountNotFound) {
+			return domain.BalanceAdjustment{}, errs.ErrAccountNotFound
+		}
+		return domain.BalanceAdjustment{}, s.translateError(err)
+	}
+	amountTJS, err := s.ConvertToBaseCurrency(amount, account.Currency)
+	if err != nil {
+		return domain.BalanceAdjustment{}, errs.ErrInvalidData
+	}
+	if amountTJS > s.cfg.AdjustmentNoApprovalLimit {
+		s.recordAdjustmentDenied(ctx, accountID, req, amount, account.Currency)
+		return domain.BalanceAdjustment{}, errs.ErrApprovalRequired
+	}
+	if err := s.checkAdjustment(ctx, account, domain.BalanceAdjustment{Direction: req.Direction, Amount: amount}.SignedAmount()); err != nil {
+		return domain.BalanceAdjustment{}, err
+	}
+
+	adj := domain.BalanceAdjustment{
+		AccountID:  accountID,
+		AdminID:    adminID,
+		Direction:  req.Direction,
+		Amount:     amount,
+		Currency:   account.Currency,
+		AmountTJS:  math.Round(amountTJS*100) / 100,
+		ReasonCode: req.ReasonCode,
+		Note:       note,
+	}
+	auditLog := domain.AdminAuditLog{
+		AccountID: accountID,
+		AdminID:   adminID,
+		Action:    "balance_" + string(req.Direction),
+		Reason:    fmt.Sprintf("%s: %s; amount %.2f %s", req.ReasonCode, note, adj.SignedAmount(), account.Currency),
+		CreatedAt: time.Now(),
+	}
+
+	adj, err = s.repo.AdjustBalance(ctx, adj, s.cfg.AdjustmentDailyCeiling, auditLog)
+	if err != nil {
+		if errors.Is(err, errs.ErrAccountNotFound) {
+			return domain.BalanceAdjustment{}, errs.ErrAccountNotFound
+		}
+		if errors.Is(err, errs.ErrAdjustmentCeiling) {
+			s.recordAdjustmentDenied(ctx, accountID, req, amount, account.Currency)
+		}
+		return domain.BalanceAdjustment{}, s.translateError(err)
+	}
+
+	s.recordEvent(ctx, domain.AuditEvent{
+		Action: auditLog.Action, TargetType: "account", TargetID: strconv.Itoa(accountID),
+		Before: auditState(map[string]float64{"balance": adj.BalanceBefore}),
+		After: auditState(map[string]interface{}{
+			"balance": adj.BalanceAfter, "amount": adj.SignedAmount(), "currency": adj.Currency,
+			"reason_code": adj.ReasonCode, "note": adj.Note, "transaction_id": adj.TransactionID, "adjustment_id": adj.ID,
+		}),
+	})
+	return adj, nil
+}
+
+// recordAdjustmentDenied - попытка прямой корректировки, которой нужен второй админ
+func (s *Service) recordAdjustmentDenied(ctx context.Context, accountID int, req domain.ReqBalanceAdjustment, amount float64, currency string) {
+	s.recordEvent(ctx, domain.AuditEvent{
+		Action: "balance_adjustment_denied", TargetType: "account", TargetID: strconv.Itoa(accountID),
+		Outcome: domain.AuditDenied,
+		After: auditState(map[string]interface{}{
+			"direction": req.Direction, "amount": amount, "currency": currency, "reason_code": req.ReasonCode,
+		}),
+	})
+}
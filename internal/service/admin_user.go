@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/logger"
+)
+
+// Админская консоль клиентов. Любое чтение данных клиента сотрудником сначала пишется в аудит:
+// не удалось записать - данные не отдаются.
+
+const (
+	defaultUserSearchLimit = 20
+	maxUserSearchLimit     = 100
+	// recentTransactionsLimit - сколько последних операций показывать в карточке клиента
+	recentTransactionsLimit = 20
+)
+
+// likePattern - подстрока для LIKE, спецсимволы шаблона экранируются
+func likePattern(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+	return "%" + s + "%"
+}
+
+// userSearchFilter - строка поиска проверяется как имя, email, телефон и номер карты одновременно
+func (s *Service) userSearchFilter(search domain.UserSearch) (domain.UserSearchFilter, error) {
+	filter := domain.UserSearchFilter{Limit: search.Limit, Offset: search.Offset}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultUserSearchLimit
+	}
+	if filter.Limit > maxUserSearchLimit || filter.Offset < 0 {
+		return filter, errs.ErrInvalidData
+	}
+
+	query := strings.ToLower(strings.TrimSpace(search.Query))
+	if query == "" {
+		return filter, nil
+	}
+	filter.NamePattern = likePattern(query)
+	filter.EmailPattern = likePattern(query)
+	if phone, err := s.normalizePhone(query); err == nil {
+		filter.Phone = phone
+	}
+	digits := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		if r == ' ' || r == '-' {
+			return -1
+		}
+		return 'x'
+	}, query)
+	if len(digits) >= 12 && !strings.Contains(digits, "x") {
+		filter.CardNumber = digits
+	}
+	return filter, nil
+}
+
+// logCustomerDataAccess - запись в аудит о просмотре данных клиента сотрудником.
+// targetID пустой у поиска, тогда в After сохраняется строка поиска.
+func (s *Service) logCustomerDataAccess(ctx context.Context, adminID int, action, targetID string, after interface{}) error {
+	event := domain.AuditEvent{
+		ActorID: adminID, ActorType: domain.ActorUser, APIClientID: s.meta.APIClientID,
+		Action: action, TargetType: "user", TargetID: targetID, After: auditState(after),
+	}
+	if s.meta.ActorType == domain.ActorAPIClient {
+		event.ActorType = domain.ActorAPIClient
+	}
+	return s.recordEventStrict(ctx, event)
+}
+
+// adminGetUser - пользователь для админки; в отличие от входа, отсутствие пользователя не маскируется
+func (s *Service) adminGetUser(ctx context.Context, userID int) (*domain.User, error) {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, errs.ErrUserNotFound) {
+			return nil, errs.ErrUserNotFound
+		}
+		return nil, s.translateError(err)
+	}
+	return user, nil
+}
+
+// SearchUsers - поиск клиентов по имени, email, телефону или номеру карты
+func (s *Service) SearchUsers(ctx context.Context, adminID int, search domain.UserSearch) ([]domain.User, error) {
+	filter, err := s.userSearchFilter(search)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.logCustomerDataAccess(ctx, adminID, "customer_search", "", map[string]interface{}{
+		"query": search.Query, "limit": filter.Limit, "offset": filter.Offset,
+	}); err != nil {
+		return nil, err
+	}
+
+	users, err := s.repo.SearchUsers(ctx, filter)
+	if err != nil {
+		return nil, s.translateError(err)
+	}
+	return users, nil
+}
+
+// UserDetail - карточка клиента: счета, карты, лимит, последние операции и сессии
+func (s *Service) UserDetail(ctx context.Context, adminID int, userID int) (domain.UserDetail, error) {
+	var detail domain.UserDetail
+
+	user, err := s.adminGetUser(ctx, userID)
+	if err != nil {
+		return detail, err
+	}
+	if err := s.logCustomerDataAccess(ctx, adminID, "customer_viewed", strconv.Itoa(userID), nil); err != nil {
+		return detail, err
+	}
+	detail.User = *user
+
+	if detail.Accounts, err = s.repo.GetAllAccountsByUserID(ctx, userID); err != nil {
+		return detail, s.translateError(err)
+	}
+	if detail.Cards, err = s.repo.GetCardsByUserID(ctx, userID); err != nil {
+		return detail, s.translateError(err)
+	}
+	// У сотрудников нет счетов и лимита (репозиторий отвечает на пустую выборку ErrUserNotFound)
+	detail.Limit, err = s.repo.GetDailyLimitByUserID(ctx, userID)
+	if err != nil && !errors.Is(err, errs.ErrLimitNotFound) && !errors.Is(err, errs.ErrUserNotFound) {
+		return detail, s.translateError(err)
+	}
+	if detail.TodayUsage, err = s.repo.GetTodayUsageInTJS(ctx, userID); err != nil {
+		return detail, s.translateError(err)
+	}
+	transactions, err := s.repo.GetTransactionHistory(ctx, userID)
+	if err != nil {
+		return detail, s.translateError(err)
+	}
+	if len(transactions) > recentTransactionsLimit {
+		transactions = transactions[:recentTransactionsLimit]
+	}
+	detail.RecentTransactions = transactions
+	if detail.Sessions, err = s.repo.GetActiveSessions(ctx, userID); err != nil {
+		return detail, s.translateError(err)
+	}
+	return detail, nil
+}
+
+// UserHistory - полная история операций клиента для сотрудника
+func (s *Service) UserHistory(ctx context.Context, adminID int, userID int) ([]domain.Transaction, error) {
+	if _, err := s.adminGetUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	if err := s.logCustomerDataAccess(ctx, adminID, "customer_history_viewed", strconv.Itoa(userID), nil); err != nil {
+		return nil, err
+	}
+	return s.HistoryLogs(ctx, userID)
+}
+
+// SetUserDisabled отключает или включает пользователя. При отключении все его сессии отзываются сразу.
+func (s *Service) SetUserDisabled(ctx context.Context, adminID int, userID int, disable bool, reason string) error {
+	log := logger.GetLogger()
+
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return errs.ErrInvalidData
+	}
+	if adminID == userID {
+		return errs.ErrOperationNotAllowed
+	}
+	user, err := s.adminGetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	action := "user_enabled"
+	if disable {
+		action = "user_disabled"
+	}
+	audit := domain.AdminAuditLog{
+		AdminID: adminID,
+		Action:  action,
+		Reason:  fmt.Sprintf("user %d: %s", userID, reason),
+	}
+	if err := s.repo.SetUserDisabled(ctx, userID, disable, audit); err != nil {
+		return s.translateError(err)
+	}
+
+	if disable {
+		sessionIDs, err := s.repo.RevokeUserSessions(ctx, userID, "user_disabled")
+		if err != nil {
+			return s.translateError(err)
+		}
+		s.markSessionsRevoked(ctx, sessionIDs...)
+	}
+	s.recordEvent(ctx, domain.AuditEvent{
+		Action: action, TargetType: "user", TargetID: strconv.Itoa(userID),
+		Before: auditState(map[string]bool{"disabled": user.Disabled}),
+		After:  auditState(map[string]interface{}{"disabled": disable, "reason": reason}),
+	})
+
+	log.Info().Int("user_id", userID).Int("admin_id", adminID).Str("action", action).Msg("User status changed")
+	return nil
+}
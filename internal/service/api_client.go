@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/logger"
+	"github.com/MMII0220/MiniBank/internal/redis"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// API клиенты - сервисные скрипты, которые получают токен по client_id/client_secret (OAuth2 client credentials)
+// и действуют от имени владельца, но только в пределах своих scopes.
+
+const clientCredentialsGrant = "client_credentials"
+
+// apiClientRateWindow - окно подсчета запросов клиента, лимит задается в запросах в минуту
+const apiClientRateWindow = time.Minute
+
+// CreateAPIClient создает клиента; секрет возвращается один раз, в БД хранится только его хеш
+func (s *Service) CreateAPIClient(ctx context.Context, req domain.ReqCreateAPIClient, adminID int) (domain.APIClient, string, error) {
+	log := logger.GetLogger()
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" || req.RateLimitPerMinute < 0 {
+		return domain.APIClient{}, "", errs.ErrInvalidData
+	}
+	rateLimit := req.RateLimitPerMinute
+	if rateLimit == 0 {
+		rateLimit = s.cfg.APIClientRateLimit
+	}
+
+	owner, err := s.repo.GetUserByID(ctx, req.OwnerUserID)
+	if err != nil {
+		if errors.Is(err, errs.ErrUserNotFound) {
+			return domain.APIClient{}, "", errs.ErrUserNotFound
+		}
+		return domain.APIClient{}, "", s.translateError(err)
+	}
+
+	// Клиент не может получить больше, чем разрешено его владельцу
+	if len(req.Scopes) == 0 {
+		return domain.APIClient{}, "", errs.ErrInvalidScope
+	}
+	scopes := make([]domain.Scope, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		if !scope.Valid() || !owner.Can(scope.Permission()) {
+			return domain.APIClient{}, "", errs.ErrInvalidScope
+		}
+		if !domain.HasScope(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	idPart, err := s.generateRefreshToken()
+	if err != nil {
+		return domain.APIClient{}, "", err
+	}
+	secret, err := s.generateRefreshToken()
+	if err != nil {
+		return domain.APIClient{}, "", err
+	}
+
+	client := domain.APIClient{
+		ClientID:           "mbc_" + idPart[:24],
+		Name:               name,
+		SecretHash:         hashToken(secret),
+		OwnerUserID:        owner.ID,
+		Scopes:             scopes,
+		RateLimitPerMinute: rateLimit,
+		CreatedBy:          adminID,
+	}
+	audit := domain.AdminAuditLog{
+		AdminID: adminID,
+		Action:  "api_client_created",
+		Reason:  fmt.Sprintf("%s: owner %d, scopes %s", name, owner.ID, domain.FormatScopes(scopes)),
+	}
+	if err := s.repo.CreateAPIClient(ctx, &client, audit); err != nil {
+		return domain.APIClient{}, "", s.translateError(err)
+	}
+	s.recordEvent(ctx, domain.AuditEvent{
+		Action: audit.Action, TargetType: "api_client", TargetID: strconv.Itoa(client.ID),
+		After: auditState(map[string]interface{}{
+			"client_id": client.ClientID, "name": client.Name, "owner_user_id": client.OwnerUserID,
+			"scopes": domain.FormatScopes(client.Scopes), "rate_limit_per_minute": client.RateLimitPerMinute,
+		}),
+	})
+
+	log.Info().Int("api_client_id", client.ID).Str("client_id", client.ClientID).Int("admin_id", adminID).Msg("API client created")
+	return client, secret, nil
+}
+
+func (s *Service) APIClients(ctx context.Context) ([]domain.APIClient, error) {
+	clients, err := s.repo.GetAPIClients(ctx)
+	if err != nil {
+		return nil, s.translateError(err)
+	}
+	return clients, nil
+}
+
+// RevokeAPIClient отзывает клиента, выданные ему токены перестают действовать сразу
+func (s *Service) RevokeAPIClient(ctx context.Context, id int, adminID int) error {
+	audit := domain.AdminAuditLog{AdminID: adminID, Action: "api_client_revoked"}
+	if err := s.repo.RevokeAPIClient(ctx, id, audit); err != nil {
+		if errors.Is(err, errs.ErrAPIClientNotFound) {
+			return errs.ErrAPIClientNotFound
+		}
+		return s.translateError(err)
+	}
+	s.recordEvent(ctx, domain.AuditEvent{
+		Action: audit.Action, TargetType: "api_client", TargetID: strconv.Itoa(id),
+		After: auditState(map[string]bool{"revoked": true}),
+	})
+	return nil
+}
+
+// IssueClientToken - token endpoint OAuth2 для grant_type=client_credentials
+func (s *Service) IssueClientToken(ctx context.Context, req domain.ReqClientToken) (domain.ClientTokenResponse, error) {
+	log := logger.GetLogger()
+	var response domain.ClientTokenResponse
+
+	if req.GrantType != clientCredentialsGrant {
+		return response, errs.ErrUnsupportedGrantType
+	}
+
+	client, err := s.repo.GetAPIClientByClientID(ctx, req.ClientID)
+	if err != nil {
+		if errors.Is(err, errs.ErrAPIClientNotFound) {
+			return response, errs.ErrInvalidClient
+		}
+		return response, s.translateError(err)
+	}
+	if client.Revoked() || subtle.ConstantTimeCompare([]byte(hashToken(req.ClientSecret)), []byte(client.SecretHash)) != 1 {
+		log.Warn().Str("client_id", req.ClientID).Msg("Invalid API client credentials")
+		return response, errs.ErrInvalidClient
+	}
+
+	// Можно запросить часть своих scopes, но не больше
+	scopes := client.Scopes
+	if req.Scope != "" {
+		scopes = domain.ParseScopes(req.Scope)
+		for _, scope := range scopes {
+			if !domain.HasScope(client.Scopes, scope) {
+				return response, errs.ErrInvalidScope
+			}
+		}
+	}
+
+	token, err := s.signToken(client.OwnerUserID, s.cfg.APIClientTokenTTL, jwt.MapClaims{
+		"cid":   client.ID,
+		"scope": domain.FormatScopes(scopes),
+		"type":  "client",
+	})
+	if err != nil {
+		return response, s.translateError(err)
+	}
+	if err := s.repo.TouchAPIClient(ctx, client.ID); err != nil {
+		log.Warn().Err(err).Int("api_client_id", client.ID).Msg("Failed to update API client last use")
+	}
+
+	log.Info().Int("api_client_id", client.ID).Str("scope", domain.FormatScopes(scopes)).Msg("API client token issued")
+	return domain.ClientTokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(s.cfg.APIClientTokenTTL.Seconds()),
+		Scope:       domain.FormatScopes(scopes),
+	}, nil
+}
+
+// parseClientToken - токен API клиента: клиент должен быть не отозван, scopes сужаются до текущих у клиента
+func (s *Service) parseClientToken(ctx context.Context, claims jwt.MapClaims, ownerID int) (domain.User, error) {
+	cid, ok := claims["cid"].(float64)
+	if !ok || cid <= 0 {
+		return domain.User{}, errs.ErrInvalidTokenClaims
+	}
+	client, err := s.repo.GetAPIClientByID(ctx, int(cid))
+	if err != nil {
+		if errors.Is(err, errs.ErrAPIClientNotFound) {
+			return domain.User{}, errs.ErrInvalidToken
+		}
+		return domain.User{}, s.translateError(err)
+	}
+	if client.Revoked() || client.OwnerUserID != ownerID {
+		return domain.User{}, errs.ErrInvalidToken
+	}
+
+	// Роль владельца берем из БД - права клиента не переживают понижение владельца
+	owner, err := s.repo.GetUserByID(ctx, ownerID)
+	if err != nil {
+		return domain.User{}, s.translateError(err)
+	}
+	if owner.Disabled {
+		return domain.User{}, errs.ErrInvalidToken
+	}
+
+	tokenScope, _ := claims["scope"].(string)
+	var scopes []domain.Scope
+	for _, scope := range domain.ParseScopes(tokenScope) {
+		if domain.HasScope(client.Scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	if err := s.allowClientRequest(ctx, client); err != nil {
+		return domain.User{}, err
+	}
+
+	return domain.User{
+		ID:          owner.ID,
+		FullName:    owner.FullName,
+		Role:        owner.Role,
+		APIClientID: client.ID,
+		Scopes:      scopes,
+	}, nil
+}
+
+// allowClientRequest - лимит запросов клиента в минуту; без Redis считаем в памяти процесса
+func (s *Service) allowClientRequest(ctx context.Context, client domain.APIClient) error {
+	count, err := redis.IncrAPIClientRequests(ctx, client.ID, apiClientRateWindow)
+	if err != nil {
+		count = s.limiter.incr("client:"+strconv.Itoa(client.ID), apiClientRateWindow)
+	}
+	if count > int64(client.RateLimitPerMinute) {
+		log := logger.GetLogger()
+		log.Warn().Int("api_client_id", client.ID).Int64("requests", count).Msg("API client rate limit exceeded")
+		return errs.ErrRateLimited
+	}
+	return nil
+}
+
+// RecordClientRequest пишет запрос API клиента в аудит от имени владельца с id клиента
+func (s *Service) RecordClientRequest(ctx context.Context, user domain.User, scope domain.Scope, request string) {
+	audit := domain.AdminAuditLog{
+		AdminID:     user.ID,
+		APIClientID: user.APIClientID,
+		Action:      "api:" + string(scope),
+		Reason:      request,
+	}
+	if err := s.repo.CreateAuditLog(ctx, audit); err != nil {
+		log := logger.GetLogger()
+		log.Error().Err(err).Int("api_client_id", user.APIClientID).Str("request", request).Msg("Failed to write API client audit log")
+	}
+}
+
+// rateLimiter - запасной счетчик запросов в памяти процесса на случай недоступного Redis.
+// Ключи с префиксом по назначению, как в Redis: "client:ID", "reset:email:..."
+//
+// Ключи приходят из запросов (email, IP), поэтому память ограничена: истекшие окна удаляются,
+// когда ключей становится больше maxKeys, а если и после этого места нет, новые ключи делят
+// один общий счетчик и быстрее упираются в лимит, а не растят карту.
+type rateLimiter struct {
+	mu      sync.Mutex
+	windows map[string]rateWindow
+	maxKeys int
+}
+
+type rateWindow struct {
+	start  time.Time
+	window time.Duration
+	count  int64
+}
+
+// rateLimiterMaxKeys - число ключей, после которого удаляются истекшие окна
+const rateLimiterMaxKeys = 10000
+
+// rateLimiterOverflowKey - общий счетчик для новых ключей, когда карта заполнена
+const rateLimiterOverflowKey = "overflow"
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{windows: make(map[string]rateWindow), maxKeys: rateLimiterMaxKeys}
+}
+
+func (l *rateLimiter) incr(key string, window time.Duration) int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	w, ok := l.windows[key]
+	if !ok && len(l.windows) >= l.maxKeys {
+		l.sweep(now)
+		if len(l.windows) >= l.maxKeys {
+			key = rateLimiterOverflowKey
+			w = l.windows[key]
+		}
+	}
+	if now.Sub(w.start) >= window {
+		w = rateWindow{start: now, window: window}
+	}
+	w.count++
+	l.windows[key] = w
+	return w.count
+}
+
+// sweep удаляет окна, которые уже закончились
+func (l *rateLimiter) sweep(now time.Time) {
+	for key, w := range l.windows {
+		if now.Sub(w.start) >= w.window {
+			delete(l.windows, key)
+		}
+	}
+}
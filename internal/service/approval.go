@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/logger"
+)
+
+// createPendingTransfer удерживает средства и ставит перевод в очередь на одобрение
+func (s *Service) createPendingTransfer(ctx context.Context, initiatorID int, from, to domain.Account, amount, fee float64, currency string) (domain.TransferResult, error) {
+	pt := domain.PendingTransfer{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        amount,
+		Fee:           fee,
+		Currency:      currency,
+		InitiatorID:   initiatorID,
+		ExpiresAt:     time.Now().Add(s.cfg.TransferApprovalTTL),
+	}
+	if err := s.repo.CreatePendingTransfer(ctx, &pt); err != nil {
+		return domain.TransferResult{}, s.translateError(err)
+	}
+
+	s.notifyUser(ctx, initiatorID, "Transfer is waiting for approval",
+		fmt.Sprintf("Your transfer #%d of %.2f %s requires approval and will expire at %s. The funds are on hold.",
+			pt.ID, pt.Amount, pt.Currency, pt.ExpiresAt.Format(time.RFC3339)))
+
+	return domain.TransferResult{
+		Status:            domain.TransferPendingApproval,
+		PendingTransferID: pt.ID,
+		Fee:               fee,
+	}, nil
+}
+
+// PendingTransfers - очередь переводов для админа, просроченные предварительно закрываются
+func (s *Service) PendingTransfers(ctx context.Context, status domain.PendingTransferStatus) ([]domain.PendingTransfer, error) {
+	if _, err := s.ExpirePendingTransfers(ctx); err != nil {
+		return nil, err
+	}
+
+	transfers, err := s.repo.GetPendingTransfers(ctx, status)
+	if err != nil {
+		return nil, s.translateError(err)
+	}
+	return transfers, nil
+}
+
+// PendingTransfersForApprover - переводы с бизнес-счетов, где пользователь второй подписант
+func (s *Service) PendingTransfersForApprover(ctx context.Context, userID int) ([]domain.PendingTransfer, error) {
+	if _, err := s.ExpirePendingTransfers(ctx); err != nil {
+		return nil, err
+	}
+
+	transfers, err := s.repo.GetPendingTransfersForSignatory(ctx, userID)
+	if err != nil {
+		return nil, s.translateError(err)
+	}
+	return transfers, nil
+}
+
+// DecidePendingTransfer - одобрение или отклонение перевода.
+// Решать может админ или второй подписант бизнес-счета, но не сам инициатор.
+func (s *Service) DecidePendingTransfer(ctx context.Context, pendingID int, approver domain.User, approve bool, note string) error {
+	log := logger.GetLogger()
+
+	// причина отказа сообщается инициатору, поэтому обязательна
+	if !approve && note == "" {
+		return errs.ErrInvalidData
+	}
+
+	pt, err := s.repo.GetPendingTransferByID(ctx, pendingID)
+	if err != nil {
+		return s.translateError(err)
+	}
+
+	if pt.Status != domain.PendingTransferPending {
+		return errs.ErrInvalidOperation
+	}
+
+	if pt.InitiatorID == approver.ID {
+		return errs.ErrSelfApproval
+	}
+
+	// Без права одобрения решать может только второй подписант бизнес-счета
+	if !approver.Can(domain.PermApprovalsDecide) {
+		ok, err := s.repo.IsAccountSignatory(ctx, pt.FromAccountID, approver.ID)
+		if err != nil {
+			return s.translateError(err)
+		}
+		if !ok {
+			return errs.ErrAccessDenied
+		}
+	}
+
+	if time.Now().After(pt.ExpiresAt) {
+		if err := s.expirePendingTransfer(ctx, pt); err != nil {
+			return err
+		}
+		return errs.ErrPendingTransferExpired
+	}
+
+	if approve {
+		// Ограничения, появившиеся пока перевод ждал решения, и legal_hold проверяются в транзакции одобрения
+		auditLog := pendingTransferAuditLog(pt, approver.ID, "transfer_approved", note)
+		if err := s.repo.ApprovePendingTransfer(ctx, pt, approver.ID, note, auditLog); err != nil {
+			return s.translateError(err)
+		}
+		s.recordEvent(ctx, pendingTransferAuditEvent(pt, auditLog.Action, domain.PendingTransferApproved, note))
+		log.Info().Int("pending_transfer_id", pt.ID).Int("approver_id", approver.ID).Msg("Pending transfer approved")
+		s.notifyUser(ctx, pt.InitiatorID, "Transfer approved",
+			fmt.Sprintf("Your transfer #%d of %.2f %s has been approved and executed.", pt.ID, pt.Amount, pt.Currency))
+		return nil
+	}
+
+	auditLog := pendingTransferAuditLog(pt, approver.ID, "transfer_rejected", note)
+	if err := s.repo.ReleasePendingTransfer(ctx, pt, domain.PendingTransferRejected, approver.ID, note, auditLog); err != nil {
+		return s.translateError(err)
+	}
+	s.recordEvent(ctx, pendingTransferAuditEvent(pt, auditLog.Action, domain.PendingTransferRejected, note))
+	log.Info().Int("pending_transfer_id", pt.ID).Int("approver_id", approver.ID).Msg("Pending transfer rejected")
+	s.notifyUser(ctx, pt.InitiatorID, "Transfer rejected",
+		fmt.Sprintf("Your transfer #%d of %.2f %s has been rejected: %s. The hold has been released.", pt.ID, pt.Amount, pt.Currency, note))
+	return nil
+}
+
+// ExpirePendingTransfers закрывает просроченные переводы и снимает удержания
+func (s *Service) ExpirePendingTransfers(ctx context.Context) (int, error) {
+	expired, err := s.repo.GetExpiredPendingTransfers(ctx)
+	if err != nil {
+		return 0, s.translateError(err)
+	}
+
+	count := 0
+	for _, pt := range expired {
+		if err := s.expirePendingTransfer(ctx, pt); err != nil {
+			// перевод мог быть одобрен параллельно - это не ошибка
+			if errors.Is(err, errs.ErrPendingTransferNotFound) {
+				continue
+			}
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+func (s *Service) expirePendingTransfer(ctx context.Context, pt domain.PendingTransfer) error {
+	auditLog := pendingTransferAuditLog(pt, 0, "transfer_expired", "approval window elapsed")
+	if err := s.repo.ReleasePendingTransfer(ctx, pt, domain.PendingTransferExpired, 0, "approval window elapsed", auditLog); err != nil {
+		return s.translateError(err)
+	}
+	s.recordEvent(ctx, pendingTransferAuditEvent(pt, auditLog.Action, domain.PendingTransferExpired, "approval window elapsed"))
+	s.notifyUser(ctx, pt.InitiatorID, "Transfer expired",
+		fmt.Sprintf("Your transfer #%d of %.2f %s was not approved in time. The hold has been released.", pt.ID, pt.Amount, pt.Currency))
+	return nil
+}
+
+// AddAccountSignatory делает счет бизнес-счетом и добавляет уполномоченное лицо
+func (s *Service) AddAccountSignatory(ctx context.Context, accountID, userID int) error {
+	if _, err := s.repo.GetUserByID(ctx, userID); err != nil {
+		return s.translateError(err)
+	}
+	return s.translateError(s.repo.AddAccountSignatory(ctx, accountID, userID))
+}
+
+// pendingTransferAuditLog - запись решения в account_audit, adminID = 0 для системных действий
+func pendingTransferAuditLog(pt domain.PendingTransfer, adminID int, action, note string) domain.AdminAuditLog {
+	return domain.AdminAuditLog{
+		AccountID: pt.FromAccountID,
+		AdminID:   adminID,
+		Action:    action,
+		Reason:    fmt.Sprintf("pending transfer #%d (%.2f %s): %s", pt.ID, pt.Amount, pt.Currency, note),
+		CreatedAt: time.Now(),
+	}
+}
+
+// pendingTransferAuditEvent - решение по переводу; отказ и истечение срока возвращают холд отправителю
+func pendingTransferAuditEvent(pt domain.PendingTransfer, action string, status domain.PendingTransferStatus, note string) domain.AuditEvent {
+	return domain.AuditEvent{
+		Action: action, TargetType: "pending_transfer", TargetID: strconv.Itoa(pt.ID),
+		Before: auditState(map[string]interface{}{
+			"status": pt.Status, "from_account_id": pt.FromAccountID, "to_account_id": pt.ToAccountID,
+			"amount": pt.Amount, "currency": pt.Currency,
+		}),
+		After: auditState(map[string]interface{}{"status": status, "note": note}),
+	}
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/domain/contracts"
+	"github.com/MMII0220/MiniBank/internal/logger"
+)
+
+// Журнал audit_events: кто (actor), что (action, outcome), над чем (target), состояние до и после,
+// и из какого запроса. Записи связаны hash цепочкой, проверка - VerifyAuditChain.
+
+// auditVerifyBatch - сколько записей читается за раз при проверке цепочки
+const auditVerifyBatch = 1000
+
+// WithRequest возвращает копию сервиса, которая подписывает события аудита метаданными запроса.
+// Копия делит с исходным сервисом репозиторий, ключи и лимитер.
+func (s *Service) WithRequest(meta domain.RequestMeta) contracts.ServiceI {
+	scoped := *s
+	scoped.meta = meta
+	return &scoped
+}
+
+// auditState - JSON снимок для Before/After, nil - снимка нет
+func auditState(v interface{}) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// newAuditEvent дополняет событие временем и метаданными запроса. Если субъект не указан явно,
+// это субъект запроса, а без запроса - система.
+func (s *Service) newAuditEvent(event domain.AuditEvent) domain.AuditEvent {
+	event.OccurredAt = time.Now().UTC().Truncate(time.Microsecond)
+	if event.Outcome == "" {
+		event.Outcome = domain.AuditSuccess
+	}
+	if event.ActorType == "" {
+		event.ActorID = s.meta.ActorID
+		event.ActorType = s.meta.ActorType
+		event.APIClientID = s.meta.APIClientID
+	}
+	if event.ActorType == "" {
+		event.ActorType = domain.ActorSystem
+		if s.meta.RequestID != "" {
+			event.ActorType = domain.ActorAnonymous
+		}
+	}
+	event.RequestID = s.meta.RequestID
+	event.IP = s.meta.IP
+	event.UserAgent = s.meta.UserAgent
+	return event
+}
+
+// recordEvent пишет событие аудита; ошибка записи не отменяет уже выполненное действие и только логируется.
+// Действие уже выполнено, поэтому событие пишется и после отмены запроса клиентом.
+func (s *Service) recordEvent(ctx context.Context, event domain.AuditEvent) {
+	_ = s.recordEventStrict(context.WithoutCancel(ctx), event)
+}
+
+// recordEventStrict - для действий, которые нельзя выполнять без следа в аудите (например, чтение данных клиента)
+func (s *Service) recordEventStrict(ctx context.Context, event domain.AuditEvent) error {
+	event = s.newAuditEvent(event)
+	if _, err := s.repo.AppendAuditEvent(ctx, event); err != nil {
+		log := logger.GetLogger()
+		log.Error().Err(err).
+			Str("action", event.Action).
+			Str("target_type", event.TargetType).
+			Str("target_id", event.TargetID).
+			Msg("Failed to record audit event")
+		return s.translateError(err)
+	}
+	return nil
+}
+
+// VerifyAuditChain проходит audit_events по порядку и пересчитывает hash каждой записи.
+// Правка строки дает несовпадение hash, удаление - разрыв prev_hash.
+func (s *Service) VerifyAuditChain(ctx context.Context) (domain.AuditChainReport, error) {
+	var report domain.AuditChainReport
+
+	for {
+		events, err := s.repo.GetAuditEventsAfter(ctx, report.HeadID, auditVerifyBatch)
+		if err != nil {
+			return report, s.translateError(err)
+		}
+		for _, event := range events {
+			switch {
+			case event.PrevHash != report.HeadHash:
+				report.BrokenAt = event.ID
+				report.Problem = "prev_hash does not match the previous event: an event was deleted or reordered"
+				return report, nil
+			case event.ComputeHash() != event.Hash:
+				report.BrokenAt = event.ID
+				report.Problem = "hash does not match the event contents: the event was modified"
+				return report, nil
+			}
+			report.Checked++
+			report.HeadID = event.ID
+			report.HeadHash = event.Hash
+		}
+		if len(events) < auditVerifyBatch {
+			return report, nil
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/logger"
+)
+
+// Журнал account_audit: постраничный просмотр, выгрузка для регулятора и хронология счета
+
+const (
+	defaultAuditPageSize = 50
+	maxAuditPageSize     = 500
+	// auditExportBatch - размер порции при выгрузке, весь журнал в память не читается
+	auditExportBatch = 1000
+)
+
+func validateAuditLogFilter(filter domain.AuditLogFilter) error {
+	if filter.AdminID < 0 || filter.AccountID < 0 || filter.Cursor < 0 || filter.Limit < 0 || filter.Limit > maxAuditPageSize {
+		return errs.ErrInvalidData
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return errs.ErrInvalidData
+	}
+	return nil
+}
+
+// AuditLogs - страница журнала; NextCursor передается в следующий запрос как cursor
+func (s *Service) AuditLogs(ctx context.Context, filter domain.AuditLogFilter) (domain.AuditLogPage, error) {
+	var page domain.AuditLogPage
+	if err := validateAuditLogFilter(filter); err != nil {
+		return page, err
+	}
+	limit := filter.Limit
+	if limit == 0 {
+		limit = defaultAuditPageSize
+	}
+
+	// Запрашиваем на одну запись больше, чтобы понять, есть ли следующая страница
+	filter.Limit = limit + 1
+	logs, err := s.repo.GetAuditLogs(ctx, filter)
+	if err != nil {
+		return page, s.translateError(err)
+	}
+	if len(logs) > limit {
+		logs = logs[:limit]
+		page.NextCursor = logs[limit-1].ID
+	}
+	page.Logs = logs
+	return page, nil
+}
+
+// ExportAuditLogs передает в write все записи по фильтру порциями. Сама выгрузка фиксируется в audit_events
+// до чтения журнала: без записи в аудит выгрузка не выполняется.
+func (s *Service) ExportAuditLogs(ctx context.Context, filter domain.AuditLogFilter, write func(domain.AdminAuditLog) error) error {
+	log := logger.GetLogger()
+
+	if err := validateAuditLogFilter(filter); err != nil {
+		return err
+	}
+	err := s.recordEventStrict(ctx, domain.AuditEvent{
+		Action: "audit_log_exported", TargetType: "account_audit",
+		After: auditState(map[string]interface{}{
+			"admin_id": filter.AdminID, "account_id": filter.AccountID, "action": filter.Action,
+			"from": filter.From, "to": filter.To,
+		}),
+	})
+	if err != nil {
+		return err
+	}
+
+	filter.Limit = auditExportBatch
+	exported := 0
+	for {
+		logs, err := s.repo.GetAuditLogs(ctx, filter)
+		if err != nil {
+			return s.translateError(err)
+		}
+		for _, l := range logs {
+			if err := write(l); err != nil {
+				return err
+			}
+		}
+		exported += len(logs)
+		if len(logs) < auditExportBatch {
+			break
+		}
+		filter.Cursor = logs[len(logs)-1].ID
+	}
+
+	log.Info().Int("exported", exported).Int("actor_id", s.meta.ActorID).Msg("Audit log exported")
+	return nil
+}
+
+// AccountAuditTimeline - записи журнала по счету от старых к новым
+func (s *Service) AccountAuditTimeline(ctx context.Context, accountID int, filter domain.AuditLogFilter) (domain.AuditLogPage, error) {
+	if _, err := s.repo.GetAccountByID(ctx, accountID); err != nil {
+		// Сотруднику с правом audit:read можно сообщить, что счета нет
+		if errors.Is(err, errs.ErrAccountNotFound) {
+			return domain.AuditLogPage{}, errs.ErrAccountNotFound
+		}
+		return domain.AuditLogPage{}, s.translateError(err)
+	}
+
+	filter.AccountID = accountID
+	filter.Ascending = true
+	return s.AuditLogs(ctx, filter)
+}
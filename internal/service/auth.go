@@ -1,20 +1,82 @@
 package service
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"errors"
 	"fmt"
-	"os"
+	"slices"
+	"strconv"
 	"time"
 
 	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/jwtkeys"
 	"github.com/MMII0220/MiniBank/internal/logger"
+	"github.com/MMII0220/MiniBank/internal/utils"
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
-var jwtSecret = []byte(os.Getenv("JWT_SECRET_KEY"))
+const accessTokenTTL = 15 * time.Minute // Короткий срок жизни - 15 минут
+
+// signToken добавляет стандартные claims (iss, aud, sub, iat, exp, jti) и подписывает текущим ключом
+func (s *Service) signToken(userID int, ttl time.Duration, claims jwt.MapClaims) (string, error) {
+	jti, err := s.generateRefreshToken()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	claims["iss"] = s.cfg.JWTIssuer
+	claims["aud"] = s.cfg.JWTAudience
+	claims["sub"] = strconv.Itoa(userID)
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(ttl).Unix()
+	claims["jti"] = jti[:32]
+	return s.keys.Sign(claims)
+}
+
+// parseSignedToken проверяет подпись по kid, алгоритм и стандартные claims, возвращает claims и id пользователя из sub.
+// tokenTypes - допустимые значения claim type
+func (s *Service) parseSignedToken(tokenStr string, tokenTypes ...string) (jwt.MapClaims, int, error) {
+	token, err := jwt.Parse(tokenStr, s.keys.Keyfunc,
+		jwt.WithValidMethods(s.keys.Methods()),
+		jwt.WithIssuer(s.cfg.JWTIssuer),
+		jwt.WithAudience(s.cfg.JWTAudience),
+		jwt.WithIssuedAt(),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, 0, errs.ErrTokenExpired
+		}
+		return nil, 0, errs.ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, 0, errs.ErrInvalidTokenClaims
+	}
+	tokenType, _ := claims["type"].(string)
+	if !slices.Contains(tokenTypes, tokenType) {
+		return nil, 0, errs.ErrInvalidTokenType
+	}
+	if jti, _ := claims["jti"].(string); jti == "" {
+		return nil, 0, errs.ErrInvalidTokenClaims
+	}
+	sub, _ := claims.GetSubject()
+	userID, err := strconv.Atoi(sub)
+	if err != nil || userID <= 0 {
+		return nil, 0, errs.ErrInvalidTokenClaims
+	}
+	return claims, userID, nil
+}
+
+// JWKS - открытые ключи для проверки токенов другими сервисами
+func (s *Service) JWKS() jwtkeys.JWKS {
+	return s.keys.JWKS()
+}
 
 func (s *Service) generateRefreshToken() (string, error) {
 	bytes := make([]byte, 32)
@@ -25,33 +87,39 @@ func (s *Service) generateRefreshToken() (string, error) {
 	return hex.EncodeToString(bytes), nil
 }
 
-func (s *Service) createAccessToken(userID int, role domain.Role) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": userID,
-		"role":    role,
-		"type":    "access",
-		"exp":     time.Now().Add(time.Minute * 15).Unix(), // Короткий срок жизни - 15 минут
-	})
-	return token.SignedString(jwtSecret)
-}
-
-func (s *Service) createRefreshToken(userID int, role domain.Role) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": userID,
-		"role":    role,
-		"type":    "refresh",
-		"exp":     time.Now().Add(time.Hour * 24 * 7).Unix(), // 7 дней
+func (s *Service) createAccessToken(userID int, role domain.Role, sessionID string, mfaVerified bool) (string, error) {
+	return s.signToken(userID, accessTokenTTL, jwt.MapClaims{
+		"role": role,
+		"sid":  sessionID, // по sid middleware проверяет, что сессия не отозвана
+		"mfa":  mfaVerified,
+		"type": "access",
 	})
-	return token.SignedString(jwtSecret)
 }
 
-func (s *Service) Register(req domain.ReqRegister, role domain.Role) (domain.User, error) {
+func (s *Service) Register(ctx context.Context, req domain.ReqRegister, role domain.Role) (domain.User, error) {
 	log := logger.GetLogger()
 	log.Info().
 		Str("email", req.Email).
 		Str("role", string(role)).
 		Msg("Starting user registration")
 
+	// Email и телефон хранятся нормализованными, иначе один человек оказывается двумя (+992 900... и 992900...)
+	email, err := utils.NormalizeEmail(req.Email)
+	if err != nil {
+		return domain.User{}, err
+	}
+	phone, err := s.normalizePhone(req.Phone)
+	if err != nil {
+		return domain.User{}, err
+	}
+	req.Email, req.Phone = email, phone
+
+	// Проверяем клиента по санкционным спискам до создания записи
+	if err := s.screenSubject(ctx, domain.ScreeningCustomer, req.Email, req.FullName); err != nil {
+		log.Warn().Err(err).Str("email", req.Email).Msg("Registration blocked by sanctions screening")
+		return domain.User{}, err
+	}
+
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
 		log.Error().Err(err).Str("email", req.Email).Msg("Failed to hash password")
@@ -66,7 +134,7 @@ func (s *Service) Register(req domain.ReqRegister, role domain.Role) (domain.Use
 		Role:     role,
 	}
 
-	err = s.repo.CreateUser(&user)
+	err = s.repo.CreateUser(ctx, &user)
 	if err != nil {
 		return domain.User{}, s.translateError(err)
 	}
@@ -80,13 +148,13 @@ func (s *Service) Register(req domain.ReqRegister, role domain.Role) (domain.Use
 			Blocked:  false,
 		}
 
-		err := s.repo.CreateAccount(&account)
+		err := s.repo.CreateAccount(ctx, &account)
 		if err != nil {
 			return user, s.translateError(err)
 		}
 
 		// после создания account создаём карту
-		card, err := s.CreateCardForAccount(account.ID, user.FullName)
+		card, err := s.CreateCardForAccount(ctx, account.ID, user.FullName)
 		if err != nil {
 			return domain.User{}, s.translateError(err)
 		}
@@ -96,7 +164,7 @@ func (s *Service) Register(req domain.ReqRegister, role domain.Role) (domain.Use
 
 	// var reqLimit domain.Limit
 	// Создаем стандартный лимит для нового пользователя
-	err = s.repo.CreateDailyLimitForUser(user.ID, 1000.0) // 1000 TJS дневной лимит
+	err = s.repo.CreateDailyLimitForUser(ctx, user.ID, domain.KYCUnverified.Policy().DailyAmount) // лимит уровня unverified
 	if err != nil {
 		return user, s.translateError(err)
 	}
@@ -104,82 +172,111 @@ func (s *Service) Register(req domain.ReqRegister, role domain.Role) (domain.Use
 	return user, nil
 }
 
-func (s *Service) Login(req domain.ReqLogin) (domain.TokenResponse, error) {
+func (s *Service) Login(ctx context.Context, req domain.ReqLogin) (domain.TokenResponse, error) {
 	var response domain.TokenResponse
 
-	user, err := s.repo.GetUserByEmail(req.Email)
-	if err != nil {
-		return response, s.translateError(err) // Теперь вернет ErrInvalidCredentials
+	// Блокировка, задержка после неудач и лимит по IP проверяются до пароля
+	email := normalizeLoginEmail(req.Email)
+	if err := s.checkLoginAllowed(ctx, email, req.IP); err != nil {
+		if errors.Is(err, errs.ErrAccountLocked) || errors.Is(err, errs.ErrTooManyAttempts) {
+			s.recordEvent(ctx, loginAuditEvent("login_throttled", domain.AuditDenied, email, 0))
+		}
+		return response, err
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
+	user, err := s.repo.GetUserByEmail(ctx, email)
 	if err != nil {
+		if errors.Is(err, errs.ErrUserNotFound) {
+			return response, s.registerLoginFailure(ctx, email, req.IP, 0)
+		}
 		return response, s.translateError(err)
 	}
 
-	// Создаем access токен (15 минут)
-	accessToken, err := s.createAccessToken(user.ID, user.Role)
+	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
 	if err != nil {
-		return response, s.translateError(err)
+		return response, s.registerLoginFailure(ctx, email, req.IP, user.ID)
+	}
+	// Отключенному пользователю сообщаем об этом только после верного пароля
+	if user.Disabled {
+		s.recordEvent(ctx, loginAuditEvent("login_failed", domain.AuditDenied, email, user.ID))
+		return response, errs.ErrUserDisabled
 	}
 
-	// Создаем refresh токен (7 дней)
-	refreshToken, err := s.createRefreshToken(user.ID, user.Role)
+	// С включенной 2FA вход завершается в VerifyMFA
+	mfaConfig, err := s.repo.GetMFAConfig(ctx, user.ID)
 	if err != nil {
 		return response, s.translateError(err)
 	}
-
-	response = domain.TokenResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		ExpiresIn:    15 * 60, // 15 минут в секундах
-		TokenType:    "Bearer",
+	if mfaConfig.Enabled {
+		return s.mfaChallenge(user.ID)
 	}
+	s.registerLoginSuccess(ctx, email, req.IP, user.ID)
 
-	return response, nil
+	// Новая серверная сессия: access токен (15 минут) + одноразовый refresh токен
+	return s.startSession(ctx, *user, req, false)
 }
 
-// Обновление токенов через refresh токен
-func (s *Service) RefreshToken(req domain.ReqRefreshToken) (domain.TokenResponse, error) {
+// Обновление токенов через refresh токен.
+// Refresh токен одноразовый: при каждом обновлении выдается новый, а повторное
+// предъявление уже использованного токена считается кражей и отзывает всю сессию.
+func (s *Service) RefreshToken(ctx context.Context, req domain.ReqRefreshToken) (domain.TokenResponse, error) {
+	log := logger.GetLogger()
 	var response domain.TokenResponse
 
-	// Парсим refresh токен
-	token, err := jwt.Parse(req.RefreshToken, func(token *jwt.Token) (interface{}, error) {
-		return jwtSecret, nil
-	})
-
-	if err != nil || !token.Valid {
-		return response, errors.New("invalid refresh token")
+	stored, err := s.repo.GetRefreshToken(ctx, hashToken(req.RefreshToken))
+	if err != nil {
+		return response, s.translateError(err)
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return response, errors.New("invalid refresh token claims")
+	if !stored.UsedAt.IsZero() {
+		log.Warn().Str("session_id", stored.SessionID).Msg("Refresh token reuse detected, revoking session")
+		s.recordEvent(ctx, domain.AuditEvent{
+			Action: "refresh_token_reused", Outcome: domain.AuditDenied, TargetType: "session", TargetID: stored.SessionID,
+		})
+		if err := s.revokeSession(ctx, stored.SessionID, "refresh_token_reuse"); err != nil {
+			return response, err
+		}
+		return response, errs.ErrRefreshTokenRevoked
 	}
 
-	// Проверяем что это именно refresh токен
-	tokenType, ok := claims["type"].(string)
-	if !ok || tokenType != "refresh" {
-		return response, errors.New("invalid token type")
+	if time.Now().After(stored.ExpiresAt) {
+		return response, errs.ErrRefreshTokenExpired
 	}
 
-	// Получаем данные пользователя
-	userID := int(claims["user_id"].(float64))
-	role := domain.Role(claims["role"].(string))
+	session, err := s.repo.GetSession(ctx, stored.SessionID)
+	if err != nil {
+		return response, s.translateError(err)
+	}
+	if !session.RevokedAt.IsZero() {
+		return response, errs.ErrRefreshTokenRevoked
+	}
 
-	// Проверяем срок действия
-	exp := int64(claims["exp"].(float64))
-	if time.Now().Unix() > exp {
-		return response, errors.New("refresh token expired")
+	// Роль берем из БД, а не из старого токена - она могла измениться
+	user, err := s.repo.GetUserByID(ctx, session.UserID)
+	if err != nil {
+		return response, s.translateError(err)
+	}
+	if user.Disabled {
+		return response, errs.ErrUserDisabled
 	}
 
-	// Создаем новые токены
-	newAccessToken, err := s.createAccessToken(userID, role)
+	newRefreshToken, err := s.generateRefreshToken()
 	if err != nil {
+		return response, err
+	}
+	expiresAt := time.Now().Add(s.cfg.RefreshTokenTTL)
+	if err := s.repo.RotateRefreshToken(ctx, stored.ID, session.ID, hashToken(newRefreshToken), expiresAt); err != nil {
+		// токен погасили параллельно - тот же сценарий повторного использования
+		if errors.Is(err, errs.ErrRefreshTokenRevoked) {
+			log.Warn().Str("session_id", session.ID).Msg("Concurrent refresh token reuse detected, revoking session")
+			if revokeErr := s.revokeSession(ctx, session.ID, "refresh_token_reuse"); revokeErr != nil {
+				return response, revokeErr
+			}
+		}
 		return response, s.translateError(err)
 	}
 
-	newRefreshToken, err := s.createRefreshToken(userID, role)
+	newAccessToken, err := s.createAccessToken(user.ID, user.Role, session.ID, session.MFAVerified)
 	if err != nil {
 		return response, s.translateError(err)
 	}
@@ -190,35 +287,44 @@ func (s *Service) RefreshToken(req domain.ReqRefreshToken) (domain.TokenResponse
 		ExpiresIn:    15 * 60, // 15 минут в секундах
 		TokenType:    "Bearer",
 	}
+	s.recordEvent(ctx, domain.AuditEvent{
+		ActorID: user.ID, ActorType: domain.ActorUser,
+		Action: "token_refreshed", TargetType: "session", TargetID: session.ID,
+	})
 
 	return response, nil
 }
 
 // Проверка access токена
-func (s *Service) ParseToken(tokenStr string) (domain.User, error) {
+func (s *Service) ParseToken(ctx context.Context, tokenStr string) (domain.User, error) {
 	var user domain.User
 
-	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-		return jwtSecret, nil
-	})
-
-	if err != nil || !token.Valid {
-		return user, errors.New("invalid token")
+	// Access токен пользовательской сессии или токен API клиента
+	claims, userID, err := s.parseSignedToken(tokenStr, "access", "client")
+	if err != nil {
+		return user, err
+	}
+	if claims["type"] == "client" {
+		return s.parseClientToken(ctx, claims, userID)
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
+	role, ok := claims["role"].(string)
 	if !ok {
-		return user, errors.New("invalid token claims")
+		return user, errs.ErrInvalidTokenClaims
 	}
+	user.ID = userID
+	user.Role = domain.Role(role)
 
-	// Проверяем что это access токен
-	tokenType, ok := claims["type"].(string)
-	if !ok || tokenType != "access" {
-		return user, errors.New("invalid token type, access token required")
+	// Отозванная сессия (logout, повторное использование refresh токена) действует сразу
+	sessionID, ok := claims["sid"].(string)
+	if !ok || sessionID == "" {
+		return user, errs.ErrInvalidTokenClaims
 	}
-
-	user.ID = int(claims["user_id"].(float64))
-	user.Role = domain.Role(claims["role"].(string))
+	if !s.sessionActive(ctx, sessionID, user.ID) {
+		return user, errs.ErrSessionExpired
+	}
+	user.SessionID = sessionID
+	user.MFAVerified, _ = claims["mfa"].(bool)
 
 	return user, nil
 }
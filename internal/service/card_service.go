@@ -2,6 +2,7 @@
 package service
 
 import (
+	"context"
 	"time"
 
 	"github.com/MMII0220/MiniBank/internal/domain"
@@ -9,7 +10,7 @@ import (
 )
 
 // CreateCardForAccount - простое создание карты
-func (s *Service) CreateCardForAccount(accountID int, holderName string) (*domain.Card, error) {
+func (s *Service) CreateCardForAccount(ctx context.Context, accountID int, holderName string) (*domain.Card, error) {
 	// Генерируем данные
 	cardNumber, _ := utils.GenerateCardNumber()
 	cvv, _ := utils.GenerateCVV()
@@ -26,7 +27,7 @@ func (s *Service) CreateCardForAccount(accountID int, holderName string) (*domai
 	}
 
 	// Сохраняем в БД
-	err := s.repo.CreateCard(card)
+	err := s.repo.CreateCard(ctx, card)
 	if err != nil {
 		return nil, s.translateError(err)
 	}
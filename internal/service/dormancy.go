@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/logger"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MarkDormantAccounts помечает спящими счета без операций клиента (пополнение, снятие, перевод) за DORMANCY_MONTHS.
+// Запускается по расписанию; списания со спящего счета запрещены до реактивации.
+func (s *Service) MarkDormantAccounts(ctx context.Context) (int, error) {
+	log := logger.GetLogger()
+
+	months := s.cfg.DormancyMonths
+	inactiveSince := time.Now().AddDate(0, -months, 0)
+	reason := fmt.Sprintf("no customer activity for %d months", months)
+
+	accountIDs, err := s.repo.MarkDormantAccounts(ctx, inactiveSince, reason)
+	if err != nil {
+		return 0, s.translateError(err)
+	}
+	for _, accountID := range accountIDs {
+		s.recordEvent(ctx, domain.AuditEvent{
+			Action: "dormant", TargetType: "account", TargetID: strconv.Itoa(accountID),
+			After: auditState(map[string]interface{}{"mode": domain.RestrictionDormant, "inactive_since": inactiveSince}),
+		})
+	}
+	if len(accountIDs) > 0 {
+		log.Info().Int("count", len(accountIDs)).Int("months", months).Msg("Accounts marked dormant")
+	}
+	return len(accountIDs), nil
+}
+
+// ReactivateAccount снимает dormant по запросу владельца счета после повторного подтверждения личности:
+// пароль и, если включена 2FA, код TOTP или код восстановления.
+// Админ снимает dormant как любое ограничение - через unblock и одобрение вторым админом.
+func (s *Service) ReactivateAccount(ctx context.Context, userID, accountID int, req domain.ReqAccountReactivation) error {
+	log := logger.GetLogger()
+
+	account, err := s.repo.GetAccountByID(ctx, accountID)
+	if err != nil {
+		return s.translateError(err)
+	}
+	if account.UserID != userID {
+		return errs.ErrAccessDenied
+	}
+
+	if err := s.confirmIdentity(ctx, userID, req); err != nil {
+		s.recordEvent(ctx, domain.AuditEvent{
+			Action: "account_reactivated", Outcome: domain.AuditDenied, TargetType: "account", TargetID: strconv.Itoa(accountID),
+			After: auditState(map[string]interface{}{"error": err.Error()}),
+		})
+		return err
+	}
+
+	auditLog := domain.AdminAuditLog{
+		AccountID: accountID,
+		Action:    "reactivated",
+		Reason:    fmt.Sprintf("reactivated by account owner (user %d) after identity re-confirmation", userID),
+		CreatedAt: time.Now(),
+	}
+	if _, err := s.repo.LiftAccountRestrictions(ctx, accountID, domain.RestrictionDormant, userID, auditLog); err != nil {
+		return s.translateError(err)
+	}
+	s.recordEvent(ctx, domain.AuditEvent{
+		Action: "account_reactivated", TargetType: "account", TargetID: strconv.Itoa(accountID),
+		Before: auditState(map[string]interface{}{"mode": domain.RestrictionDormant}),
+	})
+	log.Info().Int("account_id", accountID).Int("user_id", userID).Msg("Dormant account reactivated")
+	return nil
+}
+
+// confirmIdentity - повторный ввод пароля и второго фактора, если он включен
+func (s *Service) confirmIdentity(ctx context.Context, userID int, req domain.ReqAccountReactivation) error {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return s.translateError(err)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		return errs.ErrInvalidCredentials
+	}
+
+	config, err := s.repo.GetMFAConfig(ctx, userID)
+	if err != nil {
+		return s.translateError(err)
+	}
+	if !config.Enabled {
+		return nil
+	}
+	if strings.TrimSpace(req.MFACode) == "" {
+		return errs.ErrMFARequired
+	}
+	return s.checkMFACode(ctx, userID, req.MFACode)
+}
+
+// DormantBalances - отчет по остаткам на спящих счетах в разрезе валют
+func (s *Service) DormantBalances(ctx context.Context) ([]domain.DormantBalance, error) {
+	balances, err := s.repo.GetDormantBalances(ctx)
+	if err != nil {
+		return nil, s.translateError(err)
+	}
+	return balances, nil
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/logger"
+)
+
+// InviteStaff создает приглашение сотрудника с ролью выше клиента и отправляет токен на email.
+// Сам токен не хранится и возвращается только в письме.
+func (s *Service) InviteStaff(ctx context.Context, email string, role domain.Role, adminID int) (domain.StaffInvite, error) {
+	log := logger.GetLogger()
+
+	if !role.IsStaff() {
+		return domain.StaffInvite{}, errs.ErrInvalidData
+	}
+	email = normalizeLoginEmail(email)
+	if email == "" {
+		return domain.StaffInvite{}, errs.ErrInvalidData
+	}
+
+	token, err := s.generateRefreshToken()
+	if err != nil {
+		return domain.StaffInvite{}, s.translateError(err)
+	}
+
+	invite := domain.StaffInvite{
+		Email:     email,
+		Role:      role,
+		TokenHash: hashToken(token),
+		InvitedBy: adminID,
+		ExpiresAt: time.Now().Add(s.cfg.StaffInviteTTL),
+	}
+	auditLog := domain.AdminAuditLog{
+		AdminID: adminID,
+		Action:  "staff_invited",
+		Reason:  fmt.Sprintf("invited %s as %s", email, role),
+	}
+	if err := s.repo.CreateStaffInvite(ctx, &invite, auditLog); err != nil {
+		return domain.StaffInvite{}, s.translateError(err)
+	}
+	s.recordEvent(ctx, domain.AuditEvent{
+		Action: auditLog.Action, TargetType: "staff_invite", TargetID: strconv.Itoa(invite.ID),
+		After: auditState(map[string]interface{}{"email": email, "role": role, "expires_at": invite.ExpiresAt}),
+	})
+
+	msg := domain.Notification{
+		Channel: domain.ChannelEmail,
+		To:      email,
+		Subject: "MiniBank staff invitation",
+		Body: fmt.Sprintf("You have been invited to MiniBank as %s. Use this token to create your account before %s: %s",
+			role, invite.ExpiresAt.Format(time.RFC3339), token),
+	}
+	if err := s.notifier.Send(msg); err != nil {
+		log.Error().Err(err).Int("invite_id", invite.ID).Msg("Failed to send staff invite")
+		return domain.StaffInvite{}, errs.ErrTransactionFailed
+	}
+
+	log.Info().Int("invite_id", invite.ID).Str("role", string(role)).Int("invited_by", adminID).Msg("Staff invite sent")
+	return invite, nil
+}
+
+// AcceptInvite регистрирует сотрудника по приглашению; email и роль берутся из приглашения
+func (s *Service) AcceptInvite(ctx context.Context, req domain.ReqAcceptInvite) (domain.User, error) {
+	log := logger.GetLogger()
+
+	invite, err := s.repo.GetStaffInviteByTokenHash(ctx, hashToken(req.Token))
+	if err != nil {
+		if errors.Is(err, errs.ErrInviteNotFound) {
+			return domain.User{}, errs.ErrInviteNotFound
+		}
+		return domain.User{}, s.translateError(err)
+	}
+	if !invite.AcceptedAt.IsZero() {
+		return domain.User{}, errs.ErrInviteNotFound
+	}
+	if time.Now().After(invite.ExpiresAt) {
+		return domain.User{}, errs.ErrInviteExpired
+	}
+
+	// Приглашение занимается до регистрации, чтобы один токен не создал двух сотрудников
+	if err := s.repo.ClaimStaffInvite(ctx, invite.ID); err != nil {
+		if errors.Is(err, errs.ErrInvalidOperation) {
+			return domain.User{}, errs.ErrInviteNotFound
+		}
+		return domain.User{}, s.translateError(err)
+	}
+
+	user, err := s.Register(ctx, domain.ReqRegister{
+		FullName: req.FullName,
+		Phone:    req.Phone,
+		Email:    invite.Email,
+		Password: req.Password,
+	}, invite.Role)
+	if completeErr := s.repo.CompleteStaffInvite(ctx, invite.ID, user.ID); completeErr != nil {
+		log.Error().Err(completeErr).Int("invite_id", invite.ID).Msg("Failed to complete staff invite")
+	}
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	log.Info().Int("invite_id", invite.ID).Int("user_id", user.ID).Str("role", string(user.Role)).Msg("Staff invite accepted")
+	return user, nil
+}
+
+// BootstrapAdmin создает первого админа при пустой системе; если админ уже есть, ничего не делает
+func (s *Service) BootstrapAdmin(ctx context.Context, req domain.ReqRegister) (bool, error) {
+	log := logger.GetLogger()
+
+	count, err := s.repo.CountUsersByRole(ctx, domain.RoleAdmin)
+	if err != nil {
+		return false, s.translateError(err)
+	}
+	if count > 0 {
+		return false, nil
+	}
+
+	req.Email = normalizeLoginEmail(req.Email)
+	if req.Email == "" || len(req.Password) < 6 {
+		return false, errs.ErrInvalidData
+	}
+
+	user, err := s.Register(ctx, req, domain.RoleAdmin)
+	if err != nil {
+		return false, err
+	}
+
+	log.Info().Int("user_id", user.ID).Msg("Bootstrap admin created")
+	return true, nil
+}
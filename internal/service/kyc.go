@@ -0,0 +1,214 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/logger"
+)
+
+// kycContentTypes - допустимые форматы документов (определяются по содержимому, а не по имени файла)
+var kycContentTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"application/pdf": true,
+}
+
+// kycMaxDocumentSize - максимальный размер документа, KYC_MAX_DOCUMENT_MB (по умолчанию 10 МБ)
+func (s *Service) kycMaxDocumentSize() int64 {
+	return int64(s.cfg.KYCMaxDocumentMB) << 20
+}
+
+// SubmitKYCDocument сохраняет документ клиента в хранилище и ставит его в очередь проверки
+func (s *Service) SubmitKYCDocument(ctx context.Context, userID int, req domain.ReqKYCDocument) (domain.KYCDocument, error) {
+	log := logger.GetLogger()
+
+	if s.documents == nil {
+		return domain.KYCDocument{}, errors.New("document storage is not configured")
+	}
+	if !req.Type.Valid() {
+		return domain.KYCDocument{}, errs.ErrInvalidData
+	}
+
+	maxSize := s.kycMaxDocumentSize()
+	data, err := io.ReadAll(io.LimitReader(req.Body, maxSize+1))
+	if err != nil {
+		return domain.KYCDocument{}, errs.ErrInvalidData
+	}
+	if len(data) == 0 || int64(len(data)) > maxSize {
+		return domain.KYCDocument{}, errs.ErrKYCDocumentInvalid
+	}
+	contentType := http.DetectContentType(data)
+	if !kycContentTypes[contentType] {
+		return domain.KYCDocument{}, errs.ErrKYCDocumentInvalid
+	}
+
+	suffix := make([]byte, 16)
+	if _, err := rand.Read(suffix); err != nil {
+		return domain.KYCDocument{}, s.translateError(err)
+	}
+	sum := sha256.Sum256(data)
+	doc := domain.KYCDocument{
+		UserID:      userID,
+		Type:        req.Type,
+		StorageKey:  fmt.Sprintf("kyc/%d/%s", userID, hex.EncodeToString(suffix)),
+		FileName:    kycFileName(req.FileName),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		SHA256:      hex.EncodeToString(sum[:]),
+	}
+
+	if err := s.documents.Put(doc.StorageKey, bytes.NewReader(data), contentType); err != nil {
+		log.Error().Err(err).Int("user_id", userID).Msg("Failed to store KYC document")
+		return domain.KYCDocument{}, errs.ErrDatabaseError
+	}
+	if err := s.repo.CreateKYCDocument(ctx, &doc); err != nil {
+		// Файл без записи в БД никто не увидит - удаляем
+		if delErr := s.documents.Delete(doc.StorageKey); delErr != nil {
+			log.Warn().Err(delErr).Str("storage_key", doc.StorageKey).Msg("Failed to delete orphaned KYC document")
+		}
+		return domain.KYCDocument{}, s.translateError(err)
+	}
+
+	s.recordEvent(ctx, domain.AuditEvent{
+		Action: "kyc_document_submitted", TargetType: "kyc_document", TargetID: strconv.Itoa(doc.ID),
+		After: auditState(map[string]interface{}{
+			"user_id": userID, "doc_type": doc.Type, "content_type": doc.ContentType, "size": doc.Size, "sha256": doc.SHA256,
+		}),
+	})
+	log.Info().Int("user_id", userID).Int("document_id", doc.ID).Str("doc_type", string(doc.Type)).Msg("KYC document submitted")
+	return doc, nil
+}
+
+// kycFileName - только имя файла без пути, для показа проверяющему
+func kycFileName(name string) string {
+	name = strings.TrimSpace(filepath.Base(strings.ReplaceAll(name, "\\", "/")))
+	if name == "" || name == "." || name == "/" {
+		return "document"
+	}
+	if len(name) > 255 {
+		name = name[:255]
+	}
+	return name
+}
+
+// KYCStatus - уровень клиента, лимиты уровня и загруженные документы
+func (s *Service) KYCStatus(ctx context.Context, userID int) (domain.KYCStatus, error) {
+	tier, err := s.repo.GetKYCTier(ctx, userID)
+	if err != nil {
+		return domain.KYCStatus{}, s.translateError(err)
+	}
+	docs, err := s.repo.GetKYCDocuments(ctx, userID)
+	if err != nil {
+		return domain.KYCStatus{}, s.translateError(err)
+	}
+	return domain.KYCStatus{Tier: tier, Policy: tier.Policy(), Documents: docs}, nil
+}
+
+// KYCReviewQueue - документы на проверке, по умолчанию только pending
+func (s *Service) KYCReviewQueue(ctx context.Context, status domain.KYCDocumentStatus) ([]domain.KYCDocument, error) {
+	docs, err := s.repo.GetKYCReviewQueue(ctx, status)
+	if err != nil {
+		return nil, s.translateError(err)
+	}
+	return docs, nil
+}
+
+// OpenKYCDocument отдает файл документа проверяющему; просмотр пишется в аудит до выдачи файла
+func (s *Service) OpenKYCDocument(ctx context.Context, docID int) (domain.KYCDocument, io.ReadCloser, error) {
+	if s.documents == nil {
+		return domain.KYCDocument{}, nil, errors.New("document storage is not configured")
+	}
+	doc, err := s.repo.GetKYCDocument(ctx, docID)
+	if err != nil {
+		return domain.KYCDocument{}, nil, s.translateError(err)
+	}
+	if err := s.recordEventStrict(ctx, domain.AuditEvent{
+		Action: "kyc_document_viewed", TargetType: "kyc_document", TargetID: strconv.Itoa(docID),
+		After: auditState(map[string]interface{}{"user_id": doc.UserID, "doc_type": doc.Type}),
+	}); err != nil {
+		return domain.KYCDocument{}, nil, err
+	}
+
+	body, err := s.documents.Open(doc.StorageKey)
+	if err != nil {
+		log := logger.GetLogger()
+		log.Error().Err(err).Int("document_id", docID).Str("storage_key", doc.StorageKey).Msg("Failed to open KYC document")
+		return domain.KYCDocument{}, nil, errs.ErrDatabaseError
+	}
+	return doc, body, nil
+}
+
+// ReviewKYCDocument - решение админа по документу. После одобрения уровень клиента пересчитывается
+// по всем одобренным документам и только повышается; возвращает уровень после решения.
+func (s *Service) ReviewKYCDocument(ctx context.Context, docID, adminID int, approve bool, note string) (domain.KYCTier, error) {
+	log := logger.GetLogger()
+
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return "", errors.New("note is required for kyc review decision")
+	}
+	doc, err := s.repo.GetKYCDocument(ctx, docID)
+	if err != nil {
+		return "", s.translateError(err)
+	}
+	if doc.UserID == adminID {
+		return "", errs.ErrSelfApproval
+	}
+
+	status, action := domain.KYCDocumentRejected, "kyc_document_rejected"
+	if approve {
+		status, action = domain.KYCDocumentApproved, "kyc_document_approved"
+	}
+	if err := s.repo.ReviewKYCDocument(ctx, docID, status, adminID, note); err != nil {
+		return "", s.translateError(err)
+	}
+	s.recordEvent(ctx, domain.AuditEvent{
+		Action: action, TargetType: "kyc_document", TargetID: strconv.Itoa(docID),
+		Before: auditState(map[string]domain.KYCDocumentStatus{"status": domain.KYCDocumentPending}),
+		After:  auditState(map[string]interface{}{"status": status, "note": note, "user_id": doc.UserID}),
+	})
+
+	current, err := s.repo.GetKYCTier(ctx, doc.UserID)
+	if err != nil {
+		return "", s.translateError(err)
+	}
+	if !approve {
+		s.notifyUser(ctx, doc.UserID, "Document rejected",
+			fmt.Sprintf("Your %s was not accepted: %s. Please upload a new one.", doc.Type, note))
+		return current, nil
+	}
+
+	docs, err := s.repo.GetKYCDocuments(ctx, doc.UserID)
+	if err != nil {
+		return "", s.translateError(err)
+	}
+	tier := domain.KYCTierFor(docs)
+	if !tier.Above(current) {
+		return current, nil
+	}
+	if err := s.repo.SetKYCTier(ctx, doc.UserID, tier, tier.Policy().DailyAmount); err != nil {
+		return "", s.translateError(err)
+	}
+	s.recordEvent(ctx, domain.AuditEvent{
+		Action: "kyc_tier_changed", TargetType: "user", TargetID: strconv.Itoa(doc.UserID),
+		Before: auditState(map[string]domain.KYCTier{"kyc_tier": current}),
+		After:  auditState(map[string]interface{}{"kyc_tier": tier, "document_id": docID}),
+	})
+	s.notifyUser(ctx, doc.UserID, "Verification level raised",
+		fmt.Sprintf("Your verification level is now %s, daily limit %.2f TJS.", tier, tier.Policy().DailyAmount))
+	log.Info().Int("user_id", doc.UserID).Str("from", string(current)).Str("to", string(tier)).Msg("KYC tier raised")
+	return tier, nil
+}
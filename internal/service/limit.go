@@ -1,8 +1,12 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"time"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
 )
 
 // Курсы валют к TJS (базовая валюта)
@@ -23,29 +27,38 @@ func (s *Service) ConvertToBaseCurrency(amount float64, currency string) (float6
 	return amount * rate, nil
 }
 
-// Проверяем лимит и рассчитываем комиссию
-func (s *Service) CheckLimitAndCalculateFee(userID int, amount float64, currency string) (float64, error) {
+// Проверяем лимит и рассчитываем комиссию.
+// Лимиты берутся по уровню KYC: сверх жесткого потолка уровня операция запрещена,
+// сверх дневного лимита без комиссии берется комиссия с превышающей части.
+func (s *Service) CheckLimitAndCalculateFee(ctx context.Context, userID int, amount float64, currency string) (float64, error) {
 	// Конвертируем сумму операции в TJS
 	amountInTJS, err := s.ConvertToBaseCurrency(amount, currency)
 	if err != nil {
 		return 0, s.translateError(err)
 	}
 
-	// Получаем лимит пользователя (в TJS)
-	limit, err := s.repo.GetDailyLimitByUserID(userID)
+	tier, err := s.repo.GetKYCTier(ctx, userID)
 	if err != nil {
-		// Если лимита нет - создаем стандартный лимит 1000 TJS
-		if err.Error() == "sql: no rows in result set" {
-			// Возвращаем 0 комиссии если лимитов нет
-			return 0, nil
-		}
 		return 0, s.translateError(err)
 	}
+	policy := tier.Policy()
+
+	// Получаем лимит пользователя (в TJS): лимит уровня KYC или повышенный админом
+	limit, err := s.repo.GetDailyLimitByUserID(ctx, userID)
+	hasLimit := true
+	if err != nil {
+		if !errors.Is(err, errs.ErrUserNotFound) {
+			return 0, s.translateError(err)
+		}
+		// Нет записи лимита - действует лимит уровня
+		limit = domain.Limit{UserID: userID, DailyAmount: policy.DailyAmount, LastReset: time.Now()}
+		hasLimit = false
+	}
 
 	// Проверяем нужно ли сбросить лимит (если прошел день)
 	var usedTodayInTJS float64
-	if s.IsNewDay(limit.LastReset) {
-		err = s.repo.ResetDailyLimit(userID)
+	if hasLimit && s.IsNewDay(limit.LastReset) {
+		err = s.repo.ResetDailyLimit(ctx, userID)
 		if err != nil {
 			return 0, s.translateError(err)
 		}
@@ -53,14 +66,19 @@ func (s *Service) CheckLimitAndCalculateFee(userID int, amount float64, currency
 		usedTodayInTJS = 0
 	} else {
 		// Получаем уже потраченную сумму сегодня (в TJS)
-		usedTodayInTJS, err = s.repo.GetTodayUsageInTJS(userID)
+		usedTodayInTJS, err = s.repo.GetTodayUsageInTJS(ctx, userID)
 		if err != nil {
 			return 0, s.translateError(err)
 		}
 	}
 
-	// Проверяем превышение лимита
+	// Жесткий потолок уровня KYC не снимается ни комиссией, ни повышением лимита
 	totalUsageInTJS := usedTodayInTJS + amountInTJS
+	if policy.MaxDaily > 0 && totalUsageInTJS > policy.MaxDaily {
+		return 0, errs.ErrKYCLimitExceeded
+	}
+
+	// Проверяем превышение лимита
 	if totalUsageInTJS > limit.DailyAmount {
 		// Рассчитываем комиссию только с превышающей части
 		overlimitAmountInTJS := totalUsageInTJS - limit.DailyAmount
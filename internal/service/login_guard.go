@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/logger"
+	"github.com/MMII0220/MiniBank/internal/redis"
+)
+
+// Защита входа от подбора пароля.
+// Неудачные попытки считаются по email и по IP в Redis, журнал попыток хранится в login_attempts
+// и служит запасным источником счетчиков, если Redis недоступен.
+// После нескольких неудач включается нарастающая задержка, после LOGIN_MAX_FAILED_ATTEMPTS - временная блокировка.
+
+// loginFreeAttempts - сколько неудач подряд допускается без задержки
+const loginFreeAttempts = 2
+
+// loginBackoff - задержка перед следующей попыткой: удваивается с каждой неудачей сверх бесплатных
+func (s *Service) loginBackoff(failures int) time.Duration {
+	if failures <= loginFreeAttempts {
+		return 0
+	}
+	shift := failures - loginFreeAttempts - 1
+	if shift > 16 {
+		shift = 16
+	}
+	d := s.cfg.LoginBackoffBase << shift
+	if ceiling := s.cfg.LoginLockoutDuration; d > ceiling {
+		return ceiling
+	}
+	return d
+}
+
+func normalizeLoginEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// checkLoginAllowed отклоняет попытку входа, если email заблокирован, не истекла задержка или IP превысил лимит
+func (s *Service) checkLoginAllowed(ctx context.Context, email, ip string) error {
+	lock, err := s.repo.GetLoginLock(ctx, email)
+	if err != nil {
+		return s.translateError(err)
+	}
+	if lock.Active() {
+		return errs.ErrAccountLocked
+	}
+
+	wait, backoffErr := redis.GetLoginBackoff(ctx, "email:"+email)
+	var ipFailures int64
+	var ipErr error
+	if ip != "" {
+		ipFailures, ipErr = redis.GetLoginFailures(ctx, "ip:"+ip)
+	}
+	if backoffErr == nil && ipErr == nil {
+		if wait > 0 || (ip != "" && ipFailures >= int64(s.cfg.LoginIPMaxFailedAttempts)) {
+			return errs.ErrTooManyAttempts
+		}
+		return nil
+	}
+
+	// Redis недоступен - считаем по журналу попыток
+	stats, err := s.repo.GetLoginFailureStats(ctx, email, ip, time.Now().Add(-s.cfg.LoginFailureWindow))
+	if err != nil {
+		return s.translateError(err)
+	}
+	if ip != "" && stats.IPFailures >= s.cfg.LoginIPMaxFailedAttempts {
+		return errs.ErrTooManyAttempts
+	}
+	if !stats.LastFailureAt.IsZero() && time.Since(stats.LastFailureAt) < s.loginBackoff(stats.EmailFailures) {
+		return errs.ErrTooManyAttempts
+	}
+	return nil
+}
+
+// registerLoginFailure учитывает неудачную попытку и при превышении порога блокирует вход.
+// Возвращает ошибку, которую получит клиент.
+func (s *Service) registerLoginFailure(ctx context.Context, email, ip string, userID int) error {
+	log := logger.GetLogger()
+
+	if err := s.repo.RecordLoginAttempt(ctx, domain.LoginAttempt{Email: email, IP: ip, UserID: userID}); err != nil {
+		log.Warn().Err(err).Str("email", email).Msg("Failed to record login attempt")
+	}
+	s.recordEvent(ctx, loginAuditEvent("login_failed", domain.AuditFailure, email, userID))
+
+	if ip != "" {
+		if _, err := redis.IncrLoginFailures(ctx, "ip:"+ip, s.cfg.LoginFailureWindow); err != nil {
+			log.Warn().Err(err).Str("ip", ip).Msg("Failed to count login failure by IP")
+		}
+	}
+
+	count, err := redis.IncrLoginFailures(ctx, "email:"+email, s.cfg.LoginFailureWindow)
+	failures := int(count)
+	if err != nil {
+		stats, statsErr := s.repo.GetLoginFailureStats(ctx, email, ip, time.Now().Add(-s.cfg.LoginFailureWindow))
+		if statsErr != nil {
+			log.Error().Err(statsErr).Str("email", email).Msg("Failed to count login failures")
+			return errs.ErrInvalidCredentials
+		}
+		failures = stats.EmailFailures
+	}
+
+	if failures >= s.cfg.LoginMaxFailedAttempts {
+		if err := s.lockLogin(ctx, email, userID, failures); err != nil {
+			log.Error().Err(err).Str("email", email).Msg("Failed to lock login")
+			return errs.ErrInvalidCredentials
+		}
+		return errs.ErrAccountLocked
+	}
+
+	if delay := s.loginBackoff(failures); delay > 0 {
+		if err := redis.SetLoginBackoff(ctx, "email:"+email, delay); err != nil {
+			log.Debug().Err(err).Str("email", email).Msg("Failed to cache login backoff")
+		}
+	}
+	return errs.ErrInvalidCredentials
+}
+
+// registerLoginSuccess сбрасывает счетчик неудач по email
+func (s *Service) registerLoginSuccess(ctx context.Context, email, ip string, userID int) {
+	log := logger.GetLogger()
+
+	if err := s.repo.RecordLoginAttempt(ctx, domain.LoginAttempt{Email: email, IP: ip, UserID: userID, Success: true}); err != nil {
+		log.Warn().Err(err).Str("email", email).Msg("Failed to record login attempt")
+	}
+	event := loginAuditEvent("login_succeeded", domain.AuditSuccess, email, userID)
+	event.ActorID, event.ActorType = userID, domain.ActorUser
+	s.recordEvent(ctx, event)
+	if err := s.repo.ClearLoginFailures(ctx, email); err != nil {
+		log.Warn().Err(err).Str("email", email).Msg("Failed to clear login failures")
+	}
+	if err := redis.ClearLoginFailures(ctx, "email:"+email); err != nil {
+		log.Debug().Err(err).Str("email", email).Msg("Failed to clear cached login failures")
+	}
+}
+
+func (s *Service) lockLogin(ctx context.Context, email string, userID int, failures int) error {
+	log := logger.GetLogger()
+
+	lock := domain.LoginLock{
+		Email:       email,
+		UserID:      userID,
+		FailedCount: failures,
+		LockedUntil: time.Now().Add(s.cfg.LoginLockoutDuration),
+	}
+	auditLog := domain.AdminAuditLog{
+		AccountID: 0,
+		AdminID:   0,
+		Action:    "login_locked",
+		Reason: fmt.Sprintf("login %s (user %d) locked after %d failed attempts until %s",
+			email, userID, failures, lock.LockedUntil.Format(time.RFC3339)),
+	}
+	if err := s.repo.LockLogin(ctx, lock, auditLog); err != nil {
+		return s.translateError(err)
+	}
+	event := loginAuditEvent("login_locked", domain.AuditSuccess, email, userID)
+	event.ActorType = domain.ActorSystem
+	event.After = auditState(map[string]interface{}{"failed_count": failures, "locked_until": lock.LockedUntil})
+	s.recordEvent(ctx, event)
+	if err := redis.ClearLoginFailures(ctx, "email:"+email); err != nil {
+		log.Debug().Err(err).Str("email", email).Msg("Failed to clear cached login failures")
+	}
+
+	log.Warn().Str("email", email).Int("user_id", userID).Int("failures", failures).Msg("Login locked after repeated failures")
+	if userID != 0 {
+		s.notifyUser(ctx, userID, "Sign-in temporarily locked",
+			fmt.Sprintf("We locked sign-in to your account until %s after %d failed password attempts. If this was not you, consider changing your password.",
+				lock.LockedUntil.Format(time.RFC3339), failures))
+	}
+	return nil
+}
+
+// LoginLocks - действующие блокировки входа
+func (s *Service) LoginLocks(ctx context.Context) ([]domain.LoginLock, error) {
+	locks, err := s.repo.GetActiveLoginLocks(ctx)
+	if err != nil {
+		return nil, s.translateError(err)
+	}
+	return locks, nil
+}
+
+// UnlockLogin - админ досрочно снимает блокировку входа пользователя
+func (s *Service) UnlockLogin(ctx context.Context, userID int, adminID int, reason string) error {
+	log := logger.GetLogger()
+
+	if reason == "" {
+		return errs.ErrInvalidData
+	}
+
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, errs.ErrUserNotFound) {
+			return errs.ErrUserNotFound
+		}
+		return s.translateError(err)
+	}
+
+	email := normalizeLoginEmail(user.Email)
+	auditLog := domain.AdminAuditLog{
+		AccountID: 0,
+		AdminID:   adminID,
+		Action:    "login_unlocked",
+		Reason:    fmt.Sprintf("login %s (user %d) unlocked: %s", email, userID, reason),
+	}
+	if err := s.repo.UnlockLogin(ctx, email, auditLog); err != nil {
+		return s.translateError(err)
+	}
+	event := loginAuditEvent("login_unlocked", domain.AuditSuccess, email, userID)
+	event.After = auditState(map[string]string{"reason": reason})
+	s.recordEvent(ctx, event)
+	if err := redis.ClearLoginFailures(ctx, "email:"+email); err != nil {
+		log.Debug().Err(err).Str("email", email).Msg("Failed to clear cached login failures")
+	}
+
+	log.Info().Int("user_id", userID).Int("admin_id", adminID).Msg("Login unlocked by admin")
+	return nil
+}
+
+// loginAuditEvent - событие входа; целью служит пользователь, а если email неизвестен - сам email
+func loginAuditEvent(action string, outcome domain.AuditOutcome, email string, userID int) domain.AuditEvent {
+	event := domain.AuditEvent{Action: action, Outcome: outcome, TargetType: "user", TargetID: strconv.Itoa(userID)}
+	if userID == 0 {
+		event.TargetType, event.TargetID = "login", email
+	}
+	return event
+}
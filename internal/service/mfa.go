@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/logger"
+	"github.com/MMII0220/MiniBank/internal/totp"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// mfaChallengeTTL - сколько живет challenge токен между вводом пароля и кода
+	mfaChallengeTTL = 5 * time.Minute
+	// mfaSkew - допустимое расхождение часов в 30-секундных интервалах
+	mfaSkew = 1
+	// recoveryCodeCount - сколько кодов восстановления выдается за раз
+	recoveryCodeCount = 10
+)
+
+func (s *Service) createMFAChallengeToken(userID int) (string, error) {
+	return s.signToken(userID, mfaChallengeTTL, jwt.MapClaims{
+		"type": "mfa_challenge",
+	})
+}
+
+func (s *Service) parseMFAChallengeToken(tokenStr string) (int, error) {
+	_, userID, err := s.parseSignedToken(tokenStr, "mfa_challenge")
+	return userID, err
+}
+
+// mfaChallenge - ответ Login, когда у пользователя включена 2FA
+func (s *Service) mfaChallenge(userID int) (domain.TokenResponse, error) {
+	challenge, err := s.createMFAChallengeToken(userID)
+	if err != nil {
+		return domain.TokenResponse{}, s.translateError(err)
+	}
+	return domain.TokenResponse{
+		MFARequired: true,
+		MFAToken:    challenge,
+		ExpiresIn:   int64(mfaChallengeTTL.Seconds()),
+	}, nil
+}
+
+// VerifyMFA - второй шаг входа: обменивает challenge токен и код на обычную пару токенов.
+// Неверные коды учитываются той же защитой от подбора, что и пароль.
+func (s *Service) VerifyMFA(ctx context.Context, req domain.ReqMFAVerify) (domain.TokenResponse, error) {
+	var response domain.TokenResponse
+
+	userID, err := s.parseMFAChallengeToken(req.MFAToken)
+	if err != nil {
+		return response, err
+	}
+
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return response, s.translateError(err)
+	}
+
+	email := normalizeLoginEmail(user.Email)
+	if err := s.checkLoginAllowed(ctx, email, req.IP); err != nil {
+		return response, err
+	}
+
+	if err := s.checkMFACode(ctx, user.ID, req.Code); err != nil {
+		if errors.Is(err, errs.ErrInvalidMFACode) {
+			if failErr := s.registerLoginFailure(ctx, email, req.IP, user.ID); errors.Is(failErr, errs.ErrAccountLocked) {
+				return response, failErr
+			}
+		}
+		return response, err
+	}
+	s.registerLoginSuccess(ctx, email, req.IP, user.ID)
+
+	return s.startSession(ctx, *user, domain.ReqLogin{
+		Email:     user.Email,
+		DeviceID:  req.DeviceID,
+		UserAgent: req.UserAgent,
+		IP:        req.IP,
+	}, true)
+}
+
+// checkMFACode принимает код TOTP или неиспользованный код восстановления
+func (s *Service) checkMFACode(ctx context.Context, userID int, code string) error {
+	config, err := s.repo.GetMFAConfig(ctx, userID)
+	if err != nil {
+		return s.translateError(err)
+	}
+	if !config.Enabled {
+		return errs.ErrInvalidOperation
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(config.Secret, code, time.Now(), mfaSkew)
+		if !ok {
+			return errs.ErrInvalidMFACode
+		}
+		return s.translateError(s.repo.UseMFAStep(ctx, userID, step))
+	}
+
+	return s.translateError(s.repo.UseRecoveryCode(ctx, userID, hashToken(normalizeRecoveryCode(code))))
+}
+
+// EnrollMFA создает новый секрет TOTP. 2FA включится только после ConfirmMFA.
+func (s *Service) EnrollMFA(ctx context.Context, userID int) (domain.MFAEnrollment, error) {
+	log := logger.GetLogger()
+
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return domain.MFAEnrollment{}, s.translateError(err)
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return domain.MFAEnrollment{}, s.translateError(err)
+	}
+	if err := s.repo.SaveMFASecret(ctx, userID, secret); err != nil {
+		return domain.MFAEnrollment{}, s.translateError(err)
+	}
+
+	log.Info().Int("user_id", userID).Msg("TOTP enrollment started")
+	return domain.MFAEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(secret, s.cfg.MFAIssuer, user.Email),
+	}, nil
+}
+
+// ConfirmMFA включает 2FA по первому коду из приложения и возвращает коды восстановления.
+// Текущая сессия считается подтвержденной вторым фактором.
+func (s *Service) ConfirmMFA(ctx context.Context, userID int, sessionID string, code string) ([]string, error) {
+	log := logger.GetLogger()
+
+	config, err := s.repo.GetMFAConfig(ctx, userID)
+	if err != nil {
+		return nil, s.translateError(err)
+	}
+	if config.Secret == "" || config.Enabled {
+		return nil, errs.ErrInvalidOperation
+	}
+
+	step, ok := totp.Validate(config.Secret, code, time.Now(), mfaSkew)
+	if !ok {
+		return nil, errs.ErrInvalidMFACode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, s.translateError(err)
+	}
+	if err := s.repo.EnableMFA(ctx, userID, step, hashes); err != nil {
+		return nil, s.translateError(err)
+	}
+
+	if sessionID != "" {
+		if err := s.repo.MarkSessionMFAVerified(ctx, sessionID); err != nil {
+			log.Warn().Err(err).Str("session_id", sessionID).Msg("Failed to mark session as MFA verified")
+		}
+	}
+
+	log.Info().Int("user_id", userID).Msg("TOTP enabled")
+	s.recordEvent(ctx, domain.AuditEvent{Action: "mfa_enabled", TargetType: "user", TargetID: strconv.Itoa(userID)})
+	s.notifyUser(ctx, userID, "Two-factor authentication enabled",
+		"Two-factor authentication is now enabled for your account. Keep your recovery codes in a safe place.")
+	return codes, nil
+}
+
+// RegenerateRecoveryCodes выдает новый набор кодов восстановления, старые перестают действовать
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, userID int, code string) ([]string, error) {
+	if err := s.checkMFACode(ctx, userID, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, s.translateError(err)
+	}
+	if err := s.repo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, s.translateError(err)
+	}
+	return codes, nil
+}
+
+// DisableMFA отключает 2FA. Сотрудникам банка отключать нельзя.
+func (s *Service) DisableMFA(ctx context.Context, userID int, code string) error {
+	log := logger.GetLogger()
+
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return s.translateError(err)
+	}
+	if user.Role.IsStaff() {
+		return errs.ErrMFARequired
+	}
+
+	if err := s.checkMFACode(ctx, userID, code); err != nil {
+		return err
+	}
+	if err := s.repo.DisableMFA(ctx, userID); err != nil {
+		return s.translateError(err)
+	}
+
+	log.Info().Int("user_id", userID).Msg("TOTP disabled")
+	s.recordEvent(ctx, domain.AuditEvent{Action: "mfa_disabled", TargetType: "user", TargetID: strconv.Itoa(userID)})
+	s.notifyUser(ctx, userID, "Two-factor authentication disabled",
+		"Two-factor authentication was disabled for your account. If this was not you, contact support immediately.")
+	return nil
+}
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// generateRecoveryCodes - коды вида "abcde-fghij" и их хеши для БД
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		buf := make([]byte, 7)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(recoveryCodeEncoding.EncodeToString(buf))[:10]
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+		hashes = append(hashes, hashToken(raw))
+	}
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/logger"
+)
+
+// notifyUser отправляет пользователю email-уведомление.
+// Ошибки доставки не должны ломать бизнес-операцию, поэтому только логируются.
+func (s *Service) notifyUser(ctx context.Context, userID int, subject, body string) {
+	log := logger.GetLogger()
+
+	// Уведомление о выполненной операции отправляется, даже если клиент уже отключился
+	ctx = context.WithoutCancel(ctx)
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		log.Warn().Err(err).Int("user_id", userID).Msg("Failed to load user for notification")
+		return
+	}
+
+	msg := domain.Notification{
+		UserID:  userID,
+		Channel: domain.ChannelEmail,
+		To:      user.Email,
+		Subject: subject,
+		Body:    body,
+	}
+	if err := s.notifier.Send(msg); err != nil {
+		log.Warn().Err(err).Int("user_id", userID).Str("subject", subject).Msg("Failed to send notification")
+	}
+}
+
+// codeNotification - одноразовый код уходит по SMS на users.phone, без телефона - на email
+func codeNotification(user domain.User, subject, body string) domain.Notification {
+	msg := domain.Notification{
+		UserID:  user.ID,
+		Channel: domain.ChannelSMS,
+		To:      user.Phone,
+		Subject: subject,
+		Body:    body,
+	}
+	if user.Phone == "" {
+		msg.Channel = domain.ChannelEmail
+		msg.To = user.Email
+	}
+	return msg
+}
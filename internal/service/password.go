@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/logger"
+	"github.com/MMII0220/MiniBank/internal/redis"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// bcrypt учитывает только первые 72 байта пароля
+const passwordMaxBytes = 72
+
+// commonPasswords - самые частые пароли из утечек, проверяются без учета регистра
+var commonPasswords = map[string]bool{
+	"password1": true, "password12": true, "password123": true, "passw0rd": true,
+	"12345678a": true, "qwerty123": true, "qwertyuiop1": true, "1q2w3e4r": true,
+	"1qaz2wsx": true, "abc12345": true, "admin123": true, "welcome1": true,
+	"iloveyou1": true, "letmein1": true, "minibank1": true, "minibank123": true,
+}
+
+// validatePasswordStrength - политика паролей: длина, буквы и цифры, не из списка частых, не содержит email
+func (s *Service) validatePasswordStrength(password, email string) error {
+	if len([]rune(password)) < s.cfg.PasswordMinLength || len(password) > passwordMaxBytes {
+		return errs.ErrWeakPassword
+	}
+
+	var hasLetter, hasDigit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	if !hasLetter || !hasDigit {
+		return errs.ErrWeakPassword
+	}
+
+	lower := strings.ToLower(password)
+	if commonPasswords[lower] {
+		return errs.ErrWeakPassword
+	}
+	if local, _, ok := strings.Cut(normalizeLoginEmail(email), "@"); ok && len(local) >= 4 && strings.Contains(lower, local) {
+		return errs.ErrWeakPassword
+	}
+	return nil
+}
+
+// ForgotPassword отправляет одноразовый токен восстановления на email.
+// Ответ не зависит от того, зарегистрирован ли email: для неизвестного тоже nil, токен создается
+// и отправляется в фоне, ошибки доставки только логируются. Запросы ограничены по email и по IP.
+func (s *Service) ForgotPassword(ctx context.Context, email string) error {
+	log := logger.GetLogger()
+
+	email = normalizeLoginEmail(email)
+	if err := s.allowPasswordReset(ctx, email, s.meta.IP); err != nil {
+		return err
+	}
+
+	user, err := s.repo.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, errs.ErrUserNotFound) {
+			log.Info().Msg("Password reset requested for unknown email")
+			return nil
+		}
+		return s.translateError(err)
+	}
+
+	// Письмо отправляется, даже если клиент уже отключился
+	ctx = context.WithoutCancel(ctx)
+	s.async(func() { s.sendPasswordReset(ctx, *user) })
+	return nil
+}
+
+// allowPasswordReset - лимит запросов восстановления, свой, а не общий с защитой входа:
+// PASSWORD_RESET_MAX_PER_EMAIL на email и PASSWORD_RESET_MAX_PER_IP на IP за PASSWORD_RESET_WINDOW
+func (s *Service) allowPasswordReset(ctx context.Context, email, ip string) error {
+	limits := map[string]int{"email:" + email: s.cfg.PasswordResetMaxPerEmail}
+	if ip != "" {
+		limits["ip:"+ip] = s.cfg.PasswordResetMaxPerIP
+	}
+
+	allowed := true
+	for key, limit := range limits {
+		count, err := redis.IncrPasswordResetRequests(ctx, key, s.cfg.PasswordResetWindow)
+		if err != nil {
+			count = s.limiter.incr("reset:"+key, s.cfg.PasswordResetWindow)
+		}
+		if count > int64(limit) {
+			allowed = false
+		}
+	}
+	if !allowed {
+		log := logger.GetLogger()
+		log.Warn().Str("ip", ip).Msg("Password reset requests rate limited")
+		return errs.ErrTooManyAttempts
+	}
+	return nil
+}
+
+// sendPasswordReset создает токен восстановления и отправляет его пользователю
+func (s *Service) sendPasswordReset(ctx context.Context, user domain.User) {
+	log := logger.GetLogger()
+
+	token, err := s.generateRefreshToken()
+	if err != nil {
+		log.Error().Err(err).Int("user_id", user.ID).Msg("Failed to generate password reset token")
+		return
+	}
+	reset := domain.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(s.cfg.PasswordResetTTL),
+	}
+	if err := s.repo.CreatePasswordResetToken(ctx, &reset); err != nil {
+		log.Error().Err(err).Int("user_id", user.ID).Msg("Failed to create password reset token")
+		return
+	}
+
+	msg := domain.Notification{
+		UserID:  user.ID,
+		Channel: domain.ChannelEmail,
+		To:      user.Email,
+		Subject: "MiniBank password reset",
+		Body: fmt.Sprintf("Use this token to set a new password before %s. If you did not request a reset, ignore this message: %s",
+			reset.ExpiresAt.Format(time.RFC3339), token),
+	}
+	if err := s.notifier.Send(msg); err != nil {
+		log.Error().Err(err).Int("user_id", user.ID).Msg("Failed to send password reset token")
+		return
+	}
+	log.Info().Int("user_id", user.ID).Msg("Password reset token sent")
+}
+
+// ResetPassword задает новый пароль по токену из письма и завершает все сессии пользователя
+func (s *Service) ResetPassword(ctx context.Context, req domain.ReqPasswordReset) error {
+	log := logger.GetLogger()
+
+	reset, err := s.repo.GetPasswordResetToken(ctx, hashToken(req.Token))
+	if err != nil {
+		return s.translateError(err)
+	}
+	if !reset.UsedAt.IsZero() || time.Now().After(reset.ExpiresAt) {
+		return errs.ErrInvalidResetToken
+	}
+
+	user, err := s.repo.GetUserByID(ctx, reset.UserID)
+	if err != nil {
+		return s.translateError(err)
+	}
+	if err := s.validatePasswordStrength(req.NewPassword, user.Email); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return s.translateError(err)
+	}
+	if err := s.repo.ResetPassword(ctx, reset.ID, user.ID, string(hashedPassword)); err != nil {
+		return s.translateError(err)
+	}
+
+	if err := s.afterPasswordChange(ctx, user.ID, "password_reset"); err != nil {
+		return err
+	}
+	log.Info().Int("user_id", user.ID).Msg("Password reset completed")
+	return nil
+}
+
+// ChangePassword - смена пароля с подтверждением текущего, после нее нужно войти заново на всех устройствах
+func (s *Service) ChangePassword(ctx context.Context, userID int, req domain.ReqPasswordChange) error {
+	log := logger.GetLogger()
+
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return s.translateError(err)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+		return errs.ErrInvalidCredentials
+	}
+	if req.NewPassword == req.CurrentPassword {
+		return errs.ErrWeakPassword
+	}
+	if err := s.validatePasswordStrength(req.NewPassword, user.Email); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return s.translateError(err)
+	}
+	if err := s.repo.UpdatePassword(ctx, userID, string(hashedPassword)); err != nil {
+		return s.translateError(err)
+	}
+
+	if err := s.afterPasswordChange(ctx, userID, "password_change"); err != nil {
+		return err
+	}
+	log.Info().Int("user_id", userID).Msg("Password changed")
+	return nil
+}
+
+// afterPasswordChange отзывает все сессии вместе с refresh токенами и уведомляет пользователя
+func (s *Service) afterPasswordChange(ctx context.Context, userID int, reason string) error {
+	sessionIDs, err := s.repo.RevokeUserSessions(ctx, userID, reason)
+	if err != nil {
+		return s.translateError(err)
+	}
+	s.markSessionsRevoked(ctx, sessionIDs...)
+	s.recordEvent(ctx, domain.AuditEvent{
+		ActorID: userID, ActorType: domain.ActorUser,
+		Action: reason, TargetType: "user", TargetID: strconv.Itoa(userID),
+		After: auditState(map[string]int{"sessions_revoked": len(sessionIDs)}),
+	})
+
+	s.notifyUser(ctx, userID, "Your MiniBank password was changed",
+		"The password for your account was changed and all devices were signed out. If this was not you, contact support immediately.")
+	return nil
+}
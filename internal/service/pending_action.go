@@ -0,0 +1,328 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/logger"
+)
+
+// ProposeAdminAction ставит чувствительное действие в очередь на одобрение другим админом
+func (s *Service) ProposeAdminAction(ctx context.Context, action domain.PendingAction, proposerID int) (domain.PendingAction, error) {
+	log := logger.GetLogger()
+
+	if action.Reason == "" {
+		return domain.PendingAction{}, errs.ErrInvalidData
+	}
+	if action.Type == domain.ActionUnblock && action.RestrictionMode == "" {
+		action.RestrictionMode = domain.RestrictionFullBlock
+	}
+	if err := s.validateAdminAction(ctx, action); err != nil {
+		return domain.PendingAction{}, err
+	}
+
+	action.ProposedBy = proposerID
+	action.ExpiresAt = time.Now().Add(s.cfg.AdminActionTTL)
+	if err := s.repo.CreatePendingAction(ctx, &action); err != nil {
+		return domain.PendingAction{}, s.translateError(err)
+	}
+	s.recordEvent(ctx, domain.AuditEvent{
+		Action: "admin_action_proposed", TargetType: "pending_action", TargetID: strconv.Itoa(action.ID),
+		After: auditState(action),
+	})
+
+	log.Info().
+		Int("pending_action_id", action.ID).
+		Str("action_type", string(action.Type)).
+		Int("proposed_by", proposerID).
+		Msg("Admin action proposed")
+	return action, nil
+}
+
+// validateAdminAction проверяет параметры действия до постановки в очередь
+func (s *Service) validateAdminAction(ctx context.Context, action domain.PendingAction) error {
+	switch action.Type {
+	case domain.ActionUnblock:
+		if !action.RestrictionMode.Valid() {
+			return errs.ErrInvalidData
+		}
+		if _, err := s.repo.GetAccountByID(ctx, action.AccountID); err != nil {
+			return s.translateError(err)
+		}
+		// снимать нечего - ограничения этого вида на счете нет
+		restrictions, err := s.repo.GetActiveAccountRestrictions(ctx, action.AccountID)
+		if err != nil {
+			return s.translateError(err)
+		}
+		if !restrictions.Has(action.RestrictionMode) {
+			return errs.ErrInvalidOperation
+		}
+	case domain.ActionLimitRaise:
+		if action.Amount <= 0 {
+			return errs.ErrInvalidLimit
+		}
+		limit, err := s.repo.GetDailyLimitByUserID(ctx, action.UserID)
+		if err != nil {
+			if errors.Is(err, errs.ErrUserNotFound) {
+				return errs.ErrLimitNotFound
+			}
+			return s.translateError(err)
+		}
+		// снижение лимита не требует второго админа, здесь только повышение
+		if action.Amount <= limit.DailyAmount {
+			return errs.ErrInvalidLimit
+		}
+	case domain.ActionManualAdjustment:
+		if math.Round(action.Amount*100) == 0 {
+			return errs.ErrInvalidAmount
+		}
+		if !action.ReasonCode.Valid() {
+			return errs.ErrInvalidData
+		}
+		if _, err := s.repo.GetAccountByID(ctx, action.AccountID); err != nil {
+			return s.translateError(err)
+		}
+	case domain.ActionRoleChange:
+		if !action.NewRole.Valid() {
+			return errs.ErrInvalidData
+		}
+		user, err := s.repo.GetUserByID(ctx, action.UserID)
+		if err != nil {
+			if errors.Is(err, errs.ErrUserNotFound) {
+				return errs.ErrUserNotFound
+			}
+			return s.translateError(err)
+		}
+		if user.Role == action.NewRole {
+			return errs.ErrInvalidOperation
+		}
+	default:
+		return errs.ErrInvalidOperation
+	}
+	return nil
+}
+
+// PendingActions - очередь админских действий, просроченные предварительно закрываются
+func (s *Service) PendingActions(ctx context.Context, status domain.PendingActionStatus) ([]domain.PendingAction, error) {
+	if _, err := s.ExpirePendingActions(ctx); err != nil {
+		return nil, err
+	}
+
+	actions, err := s.repo.GetPendingActions(ctx, status)
+	if err != nil {
+		return nil, s.translateError(err)
+	}
+	return actions, nil
+}
+
+// DecidePendingAction - одобрение или отклонение действия вторым админом.
+// Автор предложения не может одобрить его сам, действие выполняется только при одобрении.
+func (s *Service) DecidePendingAction(ctx context.Context, actionID int, approverID int, approve bool, note string) error {
+	log := logger.GetLogger()
+
+	if !approve && note == "" {
+		return errs.ErrInvalidData
+	}
+
+	action, err := s.repo.GetPendingActionByID(ctx, actionID)
+	if err != nil {
+		return s.translateError(err)
+	}
+
+	if action.Status != domain.PendingActionPending {
+		return errs.ErrInvalidOperation
+	}
+
+	if action.ProposedBy == approverID {
+		return errs.ErrSelfApproval
+	}
+
+	if time.Now().After(action.ExpiresAt) {
+		if err := s.expirePendingAction(ctx, action); err != nil {
+			return err
+		}
+		return errs.ErrPendingActionExpired
+	}
+
+	if !approve {
+		auditLog := pendingActionAuditLog(action, approverID, "rejected", note)
+		if err := s.repo.ClosePendingAction(ctx, action, domain.PendingActionRejected, approverID, note, auditLog); err != nil {
+			return s.translateError(err)
+		}
+		s.recordEvent(ctx, pendingActionClosedEvent(action, auditLog.Action, domain.PendingActionRejected, note))
+		log.Info().Int("pending_action_id", action.ID).Int("approver_id", approverID).Msg("Admin action rejected")
+		return nil
+	}
+
+	if action.Type == domain.ActionManualAdjustment {
+		return s.executePendingAdjustment(ctx, action, approverID, note)
+	}
+
+	auditLog := pendingActionAuditLog(action, approverID, "approved", note)
+	targetType, targetID, before := s.pendingActionTargetState(ctx, action)
+	if err := s.repo.ExecutePendingAction(ctx, action, approverID, note, auditLog); err != nil {
+		return s.translateError(err)
+	}
+	// Для повышения лимита, корректировки, смены роли и разблокировки в аудит идет состояние цели до и после
+	_, _, after := s.pendingActionTargetState(ctx, action)
+	s.recordEvent(ctx, domain.AuditEvent{
+		Action: auditLog.Action, TargetType: targetType, TargetID: targetID,
+		Before: auditState(before), After: auditState(after),
+	})
+	log.Info().
+		Int("pending_action_id", action.ID).
+		Str("action_type", string(action.Type)).
+		Int("approver_id", approverID).
+		Msg("Admin action approved and executed")
+	return nil
+}
+
+// executePendingAdjustment проводит одобренную manual_adjustment тем же путем, что и AdjustBalance:
+// транзакция adjustment и запись в balance_adjustments с кодом причины и балансами до и после.
+// Корректировка записывается на автора предложения и входит в его дневной потолок.
+func (s *Service) executePendingAdjustment(ctx context.Context, action domain.PendingAction, approverID int, note string) error {
+	log := logger.GetLogger()
+
+	// Ограничения счета и доступный баланс проверяются в транзакции проводки
+	account, err := s.repo.GetAccountByID(ctx, action.AccountID)
+	if err != nil {
+		return s.translateError(err)
+	}
+
+	adj := domain.BalanceAdjustment{
+		AccountID:  action.AccountID,
+		AdminID:    action.ProposedBy,
+		Direction:  domain.AdjustmentCredit,
+		Amount:     math.Round(math.Abs(action.Amount)*100) / 100,
+		Currency:   account.Currency,
+		ReasonCode: action.ReasonCode,
+		Note:       action.Reason,
+	}
+	if action.Amount < 0 {
+		adj.Direction = domain.AdjustmentDebit
+	}
+	amountTJS, err := s.ConvertToBaseCurrency(adj.Amount, account.Currency)
+	if err != nil {
+		return errs.ErrInvalidData
+	}
+	adj.AmountTJS = math.Round(amountTJS*100) / 100
+
+	auditLog := pendingActionAuditLog(action, approverID, "approved", note)
+	adj, err = s.repo.ExecutePendingAdjustment(ctx, action, adj, approverID, note, auditLog)
+	if err != nil {
+		return s.translateError(err)
+	}
+	s.recordEvent(ctx, domain.AuditEvent{
+		Action: auditLog.Action, TargetType: "account", TargetID: strconv.Itoa(action.AccountID),
+		Before: auditState(map[string]float64{"balance": adj.BalanceBefore}),
+		After: auditState(map[string]interface{}{
+			"balance": adj.BalanceAfter, "amount": adj.SignedAmount(), "currency": adj.Currency,
+			"reason_code": adj.ReasonCode, "note": adj.Note, "transaction_id": adj.TransactionID, "adjustment_id": adj.ID,
+			"pending_action_id": action.ID,
+		}),
+	})
+	log.Info().
+		Int("pending_action_id", action.ID).
+		Int("adjustment_id", adj.ID).
+		Int("approver_id", approverID).
+		Msg("Balance adjustment approved and posted")
+	return nil
+}
+
+// ExpirePendingActions закрывает действия, которые не дождались второго админа
+func (s *Service) ExpirePendingActions(ctx context.Context) (int, error) {
+	expired, err := s.repo.GetExpiredPendingActions(ctx)
+	if err != nil {
+		return 0, s.translateError(err)
+	}
+
+	count := 0
+	for _, action := range expired {
+		if err := s.expirePendingAction(ctx, action); err != nil {
+			// действие могли решить параллельно - это не ошибка
+			if errors.Is(err, errs.ErrPendingActionNotFound) {
+				continue
+			}
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+func (s *Service) expirePendingAction(ctx context.Context, action domain.PendingAction) error {
+	auditLog := pendingActionAuditLog(action, 0, "expired", "approval window elapsed")
+	if err := s.repo.ClosePendingAction(ctx, action, domain.PendingActionExpired, 0, "approval window elapsed", auditLog); err != nil {
+		return s.translateError(err)
+	}
+	s.recordEvent(ctx, pendingActionClosedEvent(action, auditLog.Action, domain.PendingActionExpired, auditLog.Reason))
+	return nil
+}
+
+// pendingActionAuditLog - запись решения в account_audit, например "unblock_approved".
+// Для действий над пользователем account_id = 0, пользователь указан в reason.
+func pendingActionAuditLog(action domain.PendingAction, adminID int, outcome, note string) domain.AdminAuditLog {
+	reason := fmt.Sprintf("pending action #%d proposed by admin %d: %s", action.ID, action.ProposedBy, action.Reason)
+	switch action.Type {
+	case domain.ActionUnblock:
+		reason = fmt.Sprintf("%s; lift %s", reason, action.RestrictionMode)
+	case domain.ActionLimitRaise:
+		reason = fmt.Sprintf("%s; user %d daily limit -> %.2f", reason, action.UserID, action.Amount)
+	case domain.ActionRoleChange:
+		reason = fmt.Sprintf("%s; user %d role -> %s", reason, action.UserID, action.NewRole)
+	case domain.ActionManualAdjustment:
+		reason = fmt.Sprintf("%s; %s amount %.2f", reason, action.ReasonCode, action.Amount)
+	}
+	if note != "" {
+		reason = fmt.Sprintf("%s; decision: %s", reason, note)
+	}
+
+	return domain.AdminAuditLog{
+		AccountID: action.AccountID,
+		AdminID:   adminID,
+		Action:    fmt.Sprintf("%s_%s", action.Type, outcome),
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	}
+}
+
+func pendingActionClosedEvent(action domain.PendingAction, auditAction string, status domain.PendingActionStatus, note string) domain.AuditEvent {
+	return domain.AuditEvent{
+		Action: auditAction, TargetType: "pending_action", TargetID: strconv.Itoa(action.ID),
+		Before: auditState(map[string]domain.PendingActionStatus{"status": action.Status}),
+		After:  auditState(map[string]interface{}{"status": status, "note": note}),
+	}
+}
+
+// pendingActionTargetState - цель действия и ее текущее состояние для снимков аудита.
+// Ошибка чтения не мешает выполнению действия, снимок просто остается пустым.
+func (s *Service) pendingActionTargetState(ctx context.Context, action domain.PendingAction) (string, string, interface{}) {
+	switch action.Type {
+	case domain.ActionLimitRaise:
+		limit, err := s.repo.GetDailyLimitByUserID(ctx, action.UserID)
+		if err != nil {
+			return "user", strconv.Itoa(action.UserID), nil
+		}
+		return "user", strconv.Itoa(action.UserID), map[string]float64{"daily_limit": limit.DailyAmount}
+	case domain.ActionRoleChange:
+		user, err := s.repo.GetUserByID(ctx, action.UserID)
+		if err != nil {
+			return "user", strconv.Itoa(action.UserID), nil
+		}
+		return "user", strconv.Itoa(action.UserID), map[string]domain.Role{"role": user.Role}
+	default:
+		account, err := s.repo.GetAccountByID(ctx, action.AccountID)
+		if err != nil {
+			return "account", strconv.Itoa(action.AccountID), nil
+		}
+		return "account", strconv.Itoa(action.AccountID), map[string]interface{}{
+			"blocked": account.Blocked, "balance": account.Balance, "currency": account.Currency,
+		}
+	}
+}
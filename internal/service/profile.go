@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/logger"
+	"github.com/MMII0220/MiniBank/internal/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Profile - данные пользователя со сводкой по счетам и картам
+func (s *Service) Profile(ctx context.Context, userID int) (domain.Profile, error) {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return domain.Profile{}, s.translateError(err)
+	}
+	accounts, err := s.GetAllAccounts(ctx, userID)
+	if err != nil {
+		return domain.Profile{}, err
+	}
+	cards, err := s.repo.GetCardsByUserID(ctx, userID)
+	if err != nil {
+		return domain.Profile{}, s.translateError(err)
+	}
+	return domain.Profile{User: *user, Accounts: accounts, Cards: cards}, nil
+}
+
+// UpdateProfile меняет имя, email и телефон. Новый email или телефон нужно подтвердить заново,
+// а прежний email получает уведомление - так владелец узнает об изменении с украденной сессии.
+func (s *Service) UpdateProfile(ctx context.Context, userID int, req domain.ReqUpdateProfile) (domain.User, error) {
+	log := logger.GetLogger()
+
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return domain.User{}, s.translateError(err)
+	}
+
+	update := domain.ProfileUpdate{UserID: userID, FullName: user.FullName, Email: user.Email, Phone: user.Phone}
+	if req.FullName != nil {
+		if update.FullName = strings.TrimSpace(*req.FullName); update.FullName == "" {
+			return domain.User{}, errs.ErrInvalidData
+		}
+	}
+	if req.Email != nil {
+		if update.Email, err = utils.NormalizeEmail(*req.Email); err != nil {
+			return domain.User{}, err
+		}
+	}
+	if req.Phone != nil {
+		if update.Phone, err = s.normalizePhone(*req.Phone); err != nil {
+			return domain.User{}, err
+		}
+	}
+
+	changed := func(field domain.ProfileField, oldValue, newValue string) {
+		if oldValue != newValue {
+			update.Changes = append(update.Changes, domain.ProfileChange{Field: field, OldValue: oldValue, NewValue: newValue, ChangedBy: userID})
+		}
+	}
+	changed(domain.ProfileFullName, user.FullName, update.FullName)
+	changed(domain.ProfileEmail, user.Email, update.Email)
+	changed(domain.ProfilePhone, user.Phone, update.Phone)
+	contactChanged := update.Email != user.Email || update.Phone != user.Phone
+
+	// Адреса для входа и кодов подтверждения меняются только с текущим паролем
+	if contactChanged {
+		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+			return domain.User{}, errs.ErrInvalidCredentials
+		}
+	}
+	if update.FullName != user.FullName {
+		if err := s.screenSubject(ctx, domain.ScreeningCustomer, update.Email, update.FullName); err != nil {
+			log.Warn().Err(err).Int("user_id", userID).Msg("Profile update blocked by sanctions screening")
+			return domain.User{}, err
+		}
+	}
+
+	// Карты перевыпускаются на текущее имя: номер и срок действия сохраняются, меняется имя держателя
+	if req.ReissueCards {
+		cards, err := s.repo.GetCardsByUserID(ctx, userID)
+		if err != nil {
+			return domain.User{}, s.translateError(err)
+		}
+		seen := map[string]bool{}
+		for _, card := range cards {
+			if card.CardHolderName != update.FullName && !seen[card.CardHolderName] {
+				seen[card.CardHolderName] = true
+				changed(domain.ProfileCardHolderName, card.CardHolderName, update.FullName)
+			}
+		}
+		update.ReissueCards = len(seen) > 0
+	}
+
+	if len(update.Changes) == 0 {
+		return *user, nil
+	}
+	if err := s.repo.UpdateUserProfile(ctx, update); err != nil {
+		return domain.User{}, s.translateError(err)
+	}
+	before, after := map[string]string{}, map[string]string{}
+	for _, ch := range update.Changes {
+		before[string(ch.Field)], after[string(ch.Field)] = ch.OldValue, ch.NewValue
+	}
+	s.recordEvent(ctx, domain.AuditEvent{
+		Action: "profile_updated", TargetType: "user", TargetID: strconv.Itoa(userID),
+		Before: auditState(before), After: auditState(after),
+	})
+
+	if contactChanged {
+		msg := domain.Notification{
+			UserID:  userID,
+			Channel: domain.ChannelEmail,
+			To:      user.Email,
+			Subject: "MiniBank profile changed",
+			Body:    "The email or phone number of your MiniBank profile was changed. If it was not you, contact support.",
+		}
+		if err := s.notifier.Send(msg); err != nil {
+			log.Warn().Err(err).Int("user_id", userID).Msg("Failed to notify previous email about profile change")
+		}
+	}
+	log.Info().Int("user_id", userID).Int("changes", len(update.Changes)).Msg("Profile updated")
+
+	updated, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return domain.User{}, s.translateError(err)
+	}
+	return *updated, nil
+}
+
+// ProfileChanges - история изменений профиля для самого пользователя
+func (s *Service) ProfileChanges(ctx context.Context, userID int) ([]domain.ProfileChange, error) {
+	changes, err := s.repo.GetProfileChanges(ctx, userID)
+	if err != nil {
+		return nil, s.translateError(err)
+	}
+	return changes, nil
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/logger"
+)
+
+// restrictionAuditAction - действие в account_audit; полная блокировка пишется как прежде, "block"
+func restrictionAuditAction(mode domain.RestrictionMode) string {
+	if mode == domain.RestrictionFullBlock {
+		return "block"
+	}
+	return string(mode)
+}
+
+// RestrictAccount ставит ограничение счета: debit_freeze, credit_freeze, full_block или legal_hold на сумму.
+// Пустой ExpiresAt - бессрочно, иначе ограничение перестает действовать само.
+func (s *Service) RestrictAccount(ctx context.Context, adminID, accountID int, req domain.ReqAdminAccountAction) (domain.AccountRestriction, error) {
+	log := logger.GetLogger()
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return domain.AccountRestriction{}, errors.New("reason is required for account blocking/unblocking")
+	}
+	if !req.Mode.Valid() || !req.Category.Valid() {
+		return domain.AccountRestriction{}, errs.ErrInvalidData
+	}
+	// Сумма задается только для legal_hold и для него обязательна
+	if (req.Mode == domain.RestrictionLegalHold) != (req.Amount > 0) {
+		return domain.AccountRestriction{}, errs.ErrInvalidAmount
+	}
+	if !req.ExpiresAt.IsZero() && !req.ExpiresAt.After(time.Now()) {
+		return domain.AccountRestriction{}, errs.ErrInvalidData
+	}
+
+	restriction := domain.AccountRestriction{
+		AccountID: accountID,
+		Mode:      req.Mode,
+		Amount:    req.Amount,
+		Category:  req.Category,
+		Reason:    reason,
+		CreatedBy: adminID,
+		ExpiresAt: req.ExpiresAt,
+	}
+	auditReason := fmt.Sprintf("[%s] %s", req.Category, reason)
+	if req.Mode == domain.RestrictionLegalHold {
+		auditReason = fmt.Sprintf("%s; amount %.2f", auditReason, req.Amount)
+	}
+	if !req.ExpiresAt.IsZero() {
+		auditReason = fmt.Sprintf("%s; until %s", auditReason, req.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+	auditLog := domain.AdminAuditLog{
+		AccountID: accountID,
+		AdminID:   adminID,
+		Action:    restrictionAuditAction(req.Mode),
+		Reason:    auditReason,
+		CreatedAt: time.Now(),
+	}
+
+	before, _ := s.repo.GetActiveAccountRestrictions(ctx, accountID)
+	restriction, err := s.repo.CreateAccountRestriction(ctx, restriction, auditLog)
+	if err != nil {
+		if errors.Is(err, errs.ErrAccountNotFound) {
+			return domain.AccountRestriction{}, errs.ErrAccountNotFound
+		}
+		return domain.AccountRestriction{}, s.translateError(err)
+	}
+	s.recordEvent(ctx, domain.AuditEvent{
+		Action: auditLog.Action, TargetType: "account", TargetID: strconv.Itoa(accountID),
+		Before: auditState(restrictionModes(before)),
+		After: auditState(map[string]interface{}{
+			"restriction_id": restriction.ID, "mode": restriction.Mode, "amount": restriction.Amount,
+			"category": restriction.Category, "reason": reason, "expires_at": restriction.ExpiresAt,
+		}),
+	})
+	log.Info().Int("account_id", accountID).Int("admin_id", adminID).Str("mode", string(req.Mode)).
+		Int("restriction_id", restriction.ID).Msg("Account restriction created")
+	return restriction, nil
+}
+
+// AccountRestrictions - история ограничений счета, включая снятые и истекшие
+func (s *Service) AccountRestrictions(ctx context.Context, accountID int) ([]domain.AccountRestriction, error) {
+	if _, err := s.repo.GetAccountByID(ctx, accountID); err != nil {
+		if errors.Is(err, errs.ErrAccountNotFound) {
+			return nil, errs.ErrAccountNotFound
+		}
+		return nil, s.translateError(err)
+	}
+	restrictions, err := s.repo.GetAccountRestrictions(ctx, accountID)
+	if err != nil {
+		return nil, s.translateError(err)
+	}
+	return restrictions, nil
+}
+
+// restrictionModes - краткий снимок действующих ограничений для аудита
+func restrictionModes(rs domain.AccountRestrictions) []string {
+	modes := make([]string, 0, len(rs))
+	for _, r := range rs {
+		modes = append(modes, string(r.Mode))
+	}
+	return modes
+}
+
+// checkDebit - можно ли списать со счета; возвращает ограничения, чтобы учесть legal_hold в доступном балансе.
+// Все операции со списанием проходят через эту проверку.
+func (s *Service) checkDebit(ctx context.Context, accountID int) (domain.AccountRestrictions, error) {
+	restrictions, err := s.repo.GetActiveAccountRestrictions(ctx, accountID)
+	if err != nil {
+		return nil, s.translateError(err)
+	}
+	if restrictions.Blocked() {
+		return nil, errs.ErrAccountBlocked
+	}
+	if restrictions.Dormant() {
+		return nil, errs.ErrAccountDormant
+	}
+	if restrictions.BlocksDebit() {
+		return nil, errs.ErrAccountRestricted
+	}
+	return restrictions, nil
+}
+
+// checkCredit - можно ли зачислить на счет; все операции с зачислением проходят через эту проверку
+func (s *Service) checkCredit(ctx context.Context, accountID int) error {
+	restrictions, err := s.repo.GetActiveAccountRestrictions(ctx, accountID)
+	if err != nil {
+		return s.translateError(err)
+	}
+	if restrictions.Blocked() {
+		return errs.ErrAccountBlocked
+	}
+	if restrictions.BlocksCredit() {
+		return errs.ErrAccountRestricted
+	}
+	return nil
+}
+
+// spendableBalance - доступный баланс без удержаний под переводы и сумм legal_hold
+func spendableBalance(account domain.Account, restrictions domain.AccountRestrictions) (float64, error) {
+	balance, err := availableBalance(account)
+	if err != nil {
+		return 0, err
+	}
+	return balance - restrictions.LegalHold(), nil
+}
+
+// checkAdjustment - ручная корректировка подчиняется тем же ограничениям, что и операции клиента:
+// списание учитывает заморозку и legal_hold, зачисление - заморозку зачислений
+func (s *Service) checkAdjustment(ctx context.Context, account domain.Account, signedAmount float64) error {
+	if signedAmount > 0 {
+		return s.checkCredit(ctx, account.ID)
+	}
+	restrictions, err := s.checkDebit(ctx, account.ID)
+	if err != nil {
+		return err
+	}
+	balance, err := spendableBalance(account, restrictions)
+	if err != nil {
+		return errs.ErrInvalidData
+	}
+	if balance+signedAmount < 0 {
+		return errs.ErrInsufficientFunds
+	}
+	return nil
+}
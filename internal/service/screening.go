@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/logger"
+)
+
+// screenSubject проверяет имя по санкционным спискам.
+// При потенциальном совпадении операция блокируется, пока админ не снимет подозрение.
+func (s *Service) screenSubject(ctx context.Context, subjectType domain.ScreeningSubjectType, subjectRef, name string) error {
+	if s.screener == nil {
+		return nil
+	}
+
+	matches := s.screener.Screen(name)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	log := logger.GetLogger()
+
+	// Смотрим, не принималось ли уже решение по этому субъекту
+	review, err := s.repo.GetLatestScreeningReview(ctx, subjectType, subjectRef)
+	switch {
+	case err == nil && review.SubjectName == name:
+		switch review.Status {
+		case domain.ScreeningCleared:
+			return nil
+		case domain.ScreeningConfirmed:
+			return errs.ErrSanctionsMatch
+		default:
+			return errs.ErrScreeningPending
+		}
+	case err != nil && !errors.Is(err, errs.ErrScreeningReviewNotFound):
+		return s.translateError(err)
+	}
+
+	// Ставим лучшее совпадение в очередь на проверку
+	best := matches[0]
+	newReview := domain.ScreeningReview{
+		SubjectType: subjectType,
+		SubjectRef:  subjectRef,
+		SubjectName: name,
+		MatchedName: best.MatchedName,
+		ListSource:  best.Entry.Source,
+		ListEntryID: best.Entry.ID,
+		Score:       best.Score,
+	}
+	if err := s.repo.CreateScreeningReview(ctx, &newReview); err != nil {
+		return s.translateError(err)
+	}
+
+	log.Warn().
+		Int("review_id", newReview.ID).
+		Str("subject_type", string(subjectType)).
+		Str("subject_ref", subjectRef).
+		Str("matched_name", best.MatchedName).
+		Str("list_source", best.Entry.Source).
+		Float64("score", best.Score).
+		Msg("Potential sanctions match, operation blocked until review")
+
+	return errs.ErrScreeningPending
+}
+
+// recipientScreeningRef - ключ субъекта для получателя перевода
+func recipientScreeningRef(accountID int) string {
+	return fmt.Sprintf("account:%d", accountID)
+}
+
+func (s *Service) ScreeningReviews(ctx context.Context, status domain.ScreeningStatus) ([]domain.ScreeningReview, error) {
+	reviews, err := s.repo.GetScreeningReviews(ctx, status)
+	if err != nil {
+		return nil, s.translateError(err)
+	}
+	return reviews, nil
+}
+
+// ResolveScreeningReview - решение админа: clear снимает блокировку, иначе совпадение подтверждается
+func (s *Service) ResolveScreeningReview(ctx context.Context, reviewID int, adminID int, clear bool, note string) error {
+	if note == "" {
+		return errors.New("note is required for screening review decision")
+	}
+
+	status := domain.ScreeningConfirmed
+	if clear {
+		status = domain.ScreeningCleared
+	}
+
+	if err := s.repo.ResolveScreeningReview(ctx, reviewID, status, adminID, note); err != nil {
+		return s.translateError(err)
+	}
+	s.recordEvent(ctx, domain.AuditEvent{
+		Action: "screening_resolved", TargetType: "screening_review", TargetID: strconv.Itoa(reviewID),
+		Before: auditState(map[string]domain.ScreeningStatus{"status": domain.ScreeningPending}),
+		After:  auditState(map[string]interface{}{"status": status, "note": note}),
+	})
+	return nil
+}
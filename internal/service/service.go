@@ -3,20 +3,67 @@ package service
 import (
 	"errors"
 
+	"github.com/MMII0220/MiniBank/config"
+	"github.com/MMII0220/MiniBank/internal/domain"
 	"github.com/MMII0220/MiniBank/internal/domain/contracts"
 	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/jwtkeys"
+	"github.com/MMII0220/MiniBank/internal/notify"
 )
 
 type Service struct {
-	repo contracts.RepositoryI
+	cfg      config.Service
+	repo     contracts.RepositoryI
+	screener contracts.ScreenerI // nil - проверка по санкционным спискам выключена
+	notifier contracts.NotifierI
+	keys     *jwtkeys.Keyring // ключи подписи access токенов
+	// documents - хранилище файлов KYC, nil - загрузка документов недоступна
+	documents contracts.DocumentStoreI
+	// limiter - счетчики лимитов запросов, если Redis недоступен
+	limiter *rateLimiter
+	// async запускает работу, результат которой не нужен в ответе (в тестах - синхронно)
+	async func(task func())
+	// meta - метаданные текущего HTTP запроса (см. WithRequest), пусто у фоновых задач
+	meta domain.RequestMeta
 }
 
 func NewService(repo contracts.RepositoryI) *Service {
 	return &Service{
-		repo: repo,
+		cfg:      config.DefaultService(),
+		repo:     repo,
+		notifier: notify.NewLogNotifier(),
+		keys:     jwtkeys.MustGenerate(),
+
+		limiter: newRateLimiter(),
+		async:   func(task func()) { go task() },
 	}
 }
 
+// SetConfig задает настройки бизнес-логики (по умолчанию - config.DefaultService())
+func (s *Service) SetConfig(cfg config.Service) {
+	s.cfg = cfg
+}
+
+// SetKeyring подключает ключи подписи токенов из файлов (по умолчанию - временный ключ до перезапуска)
+func (s *Service) SetKeyring(keys *jwtkeys.Keyring) {
+	s.keys = keys
+}
+
+// SetNotifier заменяет канал доставки уведомлений (по умолчанию - запись в лог)
+func (s *Service) SetNotifier(notifier contracts.NotifierI) {
+	s.notifier = notifier
+}
+
+// SetDocumentStore подключает хранилище документов KYC (локальный диск или S3-совместимое)
+func (s *Service) SetDocumentStore(store contracts.DocumentStoreI) {
+	s.documents = store
+}
+
+// SetScreener подключает проверку клиентов и получателей по санкционным спискам
+func (s *Service) SetScreener(screener contracts.ScreenerI) {
+	s.screener = screener
+}
+
 // translateError - функция service слоя для перевода repository ошибок в business логику
 func (s *Service) translateError(err error) error {
 	if err == nil {
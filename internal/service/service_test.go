@@ -1,400 +1,3319 @@
 package service
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"io"
 	"math"
 	"reflect"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/MMII0220/MiniBank/config"
 	"github.com/MMII0220/MiniBank/internal/domain"
 	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/totp"
+	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type mockRepo struct {
-	setAccountBlockFn         func(accountID int, block bool, reqLogs domain.AdminAuditLog) error
-	getAuditLogsFn            func() ([]domain.AdminAuditLog, error)
-	getAllAccountsByUserIDFn  func(userID int) ([]domain.Account, error)
-	getTransactionHistoryFn   func(idUser int) ([]domain.Transaction, error)
-	createUserFn              func(user *domain.User) error
-	createAccountFn           func(account *domain.Account) error
-	createCardFn              func(card *domain.Card) error
-	createDailyLimitFn        func(userID int, dailyAmount float64) error
-	getUserByEmailFn          func(email string) (*domain.User, error)
-	getAccountByCardNumberFn  func(account *domain.Account, cardNumber string, currency string) error
-	getAccountByPhoneNumberFn func(account *domain.Account, phoneNumber string, currency string) error
-	depositToAccountFn        func(accountID int, amount float64) error
-	withdrawFromAccountFn     func(accountID int, amount float64, currency string) error
-	transferFundsFn           func(fromAccountID, toAccountID int, amount float64) error
-	getDailyLimitByUserIDFn   func(userID int) (domain.Limit, error)
-	getTodayUsageInTJSFn      func(userID int) (float64, error)
-	resetDailyLimitFn         func(userID int) error
-}
-
-func (m *mockRepo) SetAccountBlock(accountID int, block bool, reqLogs domain.AdminAuditLog) error {
-	if m.setAccountBlockFn != nil {
-		return m.setAccountBlockFn(accountID, block, reqLogs)
-	}
-	return nil
-}
-func (m *mockRepo) GetAuditLogs() ([]domain.AdminAuditLog, error) {
+	createRestrictionFn         func(restriction domain.AccountRestriction, reqLogs domain.AdminAuditLog) (domain.AccountRestriction, error)
+	liftRestrictionsFn          func(accountID int, mode domain.RestrictionMode, liftedBy int, reqLogs domain.AdminAuditLog) (int, error)
+	activeRestrictionsFn        func(accountID int) (domain.AccountRestrictions, error)
+	markDormantFn               func(inactiveSince time.Time, reason string) ([]int, error)
+	getKYCTierFn                func(userID int) (domain.KYCTier, error)
+	setKYCTierFn                func(userID int, tier domain.KYCTier, dailyAmount float64) error
+	createKYCDocumentFn         func(doc *domain.KYCDocument) error
+	getKYCDocumentFn            func(docID int) (domain.KYCDocument, error)
+	getKYCDocumentsFn           func(userID int) ([]domain.KYCDocument, error)
+	reviewKYCDocumentFn         func(docID int, status domain.KYCDocumentStatus, adminID int, note string) error
+	getAuditLogsFn              func(filter domain.AuditLogFilter) ([]domain.AdminAuditLog, error)
+	getAdminStatsFn             func(from, to time.Time, topBreaches int) (domain.AdminStats, error)
+	adjustBalanceFn             func(adj domain.BalanceAdjustment, ceilingTJS float64, reqLogs domain.AdminAuditLog) (domain.BalanceAdjustment, error)
+	getAllAccountsByUserIDFn    func(userID int) ([]domain.Account, error)
+	getTransactionHistoryFn     func(idUser int) ([]domain.Transaction, error)
+	createUserFn                func(user *domain.User) error
+	createAccountFn             func(account *domain.Account) error
+	createCardFn                func(card *domain.Card) error
+	createDailyLimitFn          func(userID int, dailyAmount float64) error
+	getUserByEmailFn            func(email string) (*domain.User, error)
+	getAccountByCardNumberFn    func(account *domain.Account, cardNumber string, currency string) error
+	getAccountByPhoneNumberFn   func(account *domain.Account, phoneNumber string, currency string) error
+	depositToAccountFn          func(accountID int, amount float64) error
+	withdrawFromAccountFn       func(accountID int, amount float64, currency string) error
+	transferFundsFn             func(fromAccountID, toAccountID int, amount float64) error
+	getDailyLimitByUserIDFn     func(userID int) (domain.Limit, error)
+	getTodayUsageInTJSFn        func(userID int) (float64, error)
+	resetDailyLimitFn           func(userID int) error
+	getUserByIDFn               func(userID int) (*domain.User, error)
+	createScreeningReviewFn     func(review *domain.ScreeningReview) error
+	getLatestScreeningFn        func(subjectType domain.ScreeningSubjectType, subjectRef string) (domain.ScreeningReview, error)
+	resolveScreeningReviewFn    func(reviewID int, status domain.ScreeningStatus, adminID int, note string) error
+	createPendingTransferFn     func(pt *domain.PendingTransfer) error
+	getPendingTransferByIDFn    func(id int) (domain.PendingTransfer, error)
+	approvePendingTransferFn    func(pt domain.PendingTransfer, approverID int, note string, reqLogs domain.AdminAuditLog) error
+	releasePendingTransferFn    func(pt domain.PendingTransfer, status domain.PendingTransferStatus, deciderID int, note string, reqLogs domain.AdminAuditLog) error
+	isAccountSignatoryFn        func(accountID, userID int) (bool, error)
+	getAccountByIDFn            func(accountID int) (domain.Account, error)
+	createPendingActionFn       func(action *domain.PendingAction) error
+	getPendingActionByIDFn      func(id int) (domain.PendingAction, error)
+	executePendingActionFn      func(action domain.PendingAction, approverID int, note string, reqLogs domain.AdminAuditLog) error
+	executePendingAdjustmentFn  func(action domain.PendingAction, adj domain.BalanceAdjustment, approverID int, reqLogs domain.AdminAuditLog) (domain.BalanceAdjustment, error)
+	createSessionFn             func(session *domain.Session, tokenHash string) error
+	getSessionFn                func(sessionID string) (domain.Session, error)
+	getRefreshTokenFn           func(tokenHash string) (domain.RefreshToken, error)
+	rotateRefreshTokenFn        func(oldTokenID int, sessionID string, newTokenHash string, expiresAt time.Time) error
+	revokeSessionFn             func(sessionID string, reason string) error
+	getUserDeviceIDsFn          func(userID int) ([]string, error)
+	setSessionStepUpCodeFn      func(sessionID string, codeHash string, expiresAt time.Time) error
+	completeSessionStepUpFn     func(sessionID string) error
+	getLoginLockFn              func(email string) (domain.LoginLock, error)
+	recordLoginAttemptFn        func(attempt domain.LoginAttempt) error
+	getLoginFailureStatsFn      func(email, ip string, since time.Time) (domain.LoginFailureStats, error)
+	lockLoginFn                 func(lock domain.LoginLock, reqLogs domain.AdminAuditLog) error
+	unlockLoginFn               func(email string, reqLogs domain.AdminAuditLog) error
+	getMFAConfigFn              func(userID int) (domain.MFAConfig, error)
+	enableMFAFn                 func(userID int, step int64, recoveryCodeHashes []string) error
+	useMFAStepFn                func(userID int, step int64) error
+	useRecoveryCodeFn           func(userID int, codeHash string) error
+	createConfirmationFn        func(c *domain.TransferConfirmation) error
+	getConfirmationFn           func(id int) (domain.TransferConfirmation, error)
+	recordConfirmAttemptFn      func(id int, maxAttempts int) (int, error)
+	closeConfirmationFn         func(id int, status domain.TransferConfirmationStatus) error
+	isKnownRecipientFn          func(userID, accountID int) (bool, error)
+	createStaffInviteFn         func(invite *domain.StaffInvite, reqLogs domain.AdminAuditLog) error
+	getStaffInviteFn            func(tokenHash string) (domain.StaffInvite, error)
+	claimStaffInviteFn          func(inviteID int) error
+	completeStaffInviteFn       func(inviteID int, userID int) error
+	countUsersByRoleFn          func(role domain.Role) (int, error)
+	revokeUserSessionsFn        func(userID int, reason string) ([]string, error)
+	createResetTokenFn          func(token *domain.PasswordResetToken) error
+	getResetTokenFn             func(tokenHash string) (domain.PasswordResetToken, error)
+	resetPasswordFn             func(tokenID int, userID int, passwordHash string) error
+	updatePasswordFn            func(userID int, passwordHash string) error
+	createVerificationFn        func(v *domain.ContactVerification) error
+	getVerificationFn           func(userID int, channel domain.VerificationChannel) (domain.ContactVerification, error)
+	recordVerificationAttemptFn func(id int, maxAttempts int) (int, error)
+	completeVerificationFn      func(v domain.ContactVerification) error
+	createAPIClientFn           func(client *domain.APIClient, reqLogs domain.AdminAuditLog) error
+	getAPIClientFn              func(clientID string) (domain.APIClient, error)
+	getAPIClientByIDFn          func(id int) (domain.APIClient, error)
+	createAuditLogFn            func(reqLogs domain.AdminAuditLog) error
+	updateUserProfileFn         func(update domain.ProfileUpdate) error
+	getCardsByUserIDFn          func(userID int) ([]domain.Card, error)
+	searchUsersFn               func(filter domain.UserSearchFilter) ([]domain.User, error)
+	setUserDisabledFn           func(userID int, disabled bool, reqLogs domain.AdminAuditLog) error
+	appendAuditEventFn          func(event domain.AuditEvent) (domain.AuditEvent, error)
+	getAuditEventsAfterFn       func(afterID int64, limit int) ([]domain.AuditEvent, error)
+}
+
+func (m *mockRepo) CreateAccountRestriction(ctx context.Context, restriction domain.AccountRestriction, reqLogs domain.AdminAuditLog) (domain.AccountRestriction, error) {
+	if m.createRestrictionFn != nil {
+		return m.createRestrictionFn(restriction, reqLogs)
+	}
+	return restriction, nil
+}
+func (m *mockRepo) LiftAccountRestrictions(ctx context.Context, accountID int, mode domain.RestrictionMode, liftedBy int, reqLogs domain.AdminAuditLog) (int, error) {
+	if m.liftRestrictionsFn != nil {
+		return m.liftRestrictionsFn(accountID, mode, liftedBy, reqLogs)
+	}
+	return 1, nil
+}
+func (m *mockRepo) GetActiveAccountRestrictions(ctx context.Context, accountID int) (domain.AccountRestrictions, error) {
+	if m.activeRestrictionsFn != nil {
+		return m.activeRestrictionsFn(accountID)
+	}
+	return nil, nil
+}
+func (m *mockRepo) GetAccountRestrictions(ctx context.Context, accountID int) ([]domain.AccountRestriction, error) {
+	return nil, nil
+}
+func (m *mockRepo) MarkDormantAccounts(ctx context.Context, inactiveSince time.Time, reason string) ([]int, error) {
+	if m.markDormantFn != nil {
+		return m.markDormantFn(inactiveSince, reason)
+	}
+	return nil, nil
+}
+func (m *mockRepo) GetDormantBalances(ctx context.Context) ([]domain.DormantBalance, error) {
+	return nil, nil
+}
+func (m *mockRepo) GetKYCTier(ctx context.Context, userID int) (domain.KYCTier, error) {
+	if m.getKYCTierFn != nil {
+		return m.getKYCTierFn(userID)
+	}
+	// по умолчанию без жесткого потолка уровня, чтобы тесты лимитов проверяли только комиссию
+	return domain.KYCFull, nil
+}
+func (m *mockRepo) SetKYCTier(ctx context.Context, userID int, tier domain.KYCTier, dailyAmount float64) error {
+	if m.setKYCTierFn != nil {
+		return m.setKYCTierFn(userID, tier, dailyAmount)
+	}
+	return nil
+}
+func (m *mockRepo) CreateKYCDocument(ctx context.Context, doc *domain.KYCDocument) error {
+	if m.createKYCDocumentFn != nil {
+		return m.createKYCDocumentFn(doc)
+	}
+	return nil
+}
+func (m *mockRepo) GetKYCDocument(ctx context.Context, docID int) (domain.KYCDocument, error) {
+	if m.getKYCDocumentFn != nil {
+		return m.getKYCDocumentFn(docID)
+	}
+	return domain.KYCDocument{}, errs.ErrKYCDocumentNotFound
+}
+func (m *mockRepo) GetKYCDocuments(ctx context.Context, userID int) ([]domain.KYCDocument, error) {
+	if m.getKYCDocumentsFn != nil {
+		return m.getKYCDocumentsFn(userID)
+	}
+	return nil, nil
+}
+func (m *mockRepo) GetKYCReviewQueue(ctx context.Context, status domain.KYCDocumentStatus) ([]domain.KYCDocument, error) {
+	return nil, nil
+}
+func (m *mockRepo) ReviewKYCDocument(ctx context.Context, docID int, status domain.KYCDocumentStatus, adminID int, note string) error {
+	if m.reviewKYCDocumentFn != nil {
+		return m.reviewKYCDocumentFn(docID, status, adminID, note)
+	}
+	return nil
+}
+func (m *mockRepo) GetAuditLogs(ctx context.Context, filter domain.AuditLogFilter) ([]domain.AdminAuditLog, error) {
 	if m.getAuditLogsFn != nil {
-		return m.getAuditLogsFn()
+		return m.getAuditLogsFn(filter)
 	}
 	return []domain.AdminAuditLog{}, nil
 }
-func (m *mockRepo) GetDailyLimitByUserID(userID int) (domain.Limit, error) {
+func (m *mockRepo) GetAdminStats(ctx context.Context, from, to time.Time, topBreaches int) (domain.AdminStats, error) {
+	if m.getAdminStatsFn != nil {
+		return m.getAdminStatsFn(from, to, topBreaches)
+	}
+	return domain.AdminStats{}, nil
+}
+func (m *mockRepo) AdjustBalance(ctx context.Context, adj domain.BalanceAdjustment, ceilingTJS float64, reqLogs domain.AdminAuditLog) (domain.BalanceAdjustment, error) {
+	if m.adjustBalanceFn != nil {
+		return m.adjustBalanceFn(adj, ceilingTJS, reqLogs)
+	}
+	return adj, nil
+}
+func (m *mockRepo) GetDailyLimitByUserID(ctx context.Context, userID int) (domain.Limit, error) {
 	if m.getDailyLimitByUserIDFn != nil {
 		return m.getDailyLimitByUserIDFn(userID)
 	}
 	return domain.Limit{}, nil
 }
-func (m *mockRepo) GetTodayUsageInTJS(userID int) (float64, error) {
+func (m *mockRepo) GetTodayUsageInTJS(ctx context.Context, userID int) (float64, error) {
 	if m.getTodayUsageInTJSFn != nil {
 		return m.getTodayUsageInTJSFn(userID)
 	}
 	return 0, nil
 }
-func (m *mockRepo) CreateDailyLimitForUser(userID int, dailyAmount float64) error {
+func (m *mockRepo) CreateDailyLimitForUser(ctx context.Context, userID int, dailyAmount float64) error {
 	if m.createDailyLimitFn != nil {
 		return m.createDailyLimitFn(userID, dailyAmount)
 	}
 	return nil
 }
-func (m *mockRepo) ResetDailyLimit(userID int) error {
+func (m *mockRepo) ResetDailyLimit(ctx context.Context, userID int) error {
 	if m.resetDailyLimitFn != nil {
 		return m.resetDailyLimitFn(userID)
 	}
 	return nil
 }
-func (m *mockRepo) DepositToAccount(accountID int, amount float64) error {
+func (m *mockRepo) DepositToAccount(ctx context.Context, accountID int, amount float64) error {
 	if m.depositToAccountFn != nil {
 		return m.depositToAccountFn(accountID, amount)
 	}
 	return nil
 }
-func (m *mockRepo) WithdrawFromAccount(accountID int, amount float64, currency string) error {
+func (m *mockRepo) WithdrawFromAccount(ctx context.Context, accountID int, amount, fee float64, currency string) error {
 	if m.withdrawFromAccountFn != nil {
 		return m.withdrawFromAccountFn(accountID, amount, currency)
 	}
 	return nil
 }
-func (m *mockRepo) TransferFunds(fromAccountID, toAccountID int, amount float64) error {
+func (m *mockRepo) TransferFunds(ctx context.Context, fromAccountID, toAccountID int, amount, fee float64) error {
 	if m.transferFundsFn != nil {
 		return m.transferFundsFn(fromAccountID, toAccountID, amount)
 	}
 	return nil
 }
-func (m *mockRepo) GetAccountByCardNumber(account *domain.Account, cardNumber string, currency string) error {
+func (m *mockRepo) GetAccountByCardNumber(ctx context.Context, account *domain.Account, cardNumber string, currency string) error {
 	if m.getAccountByCardNumberFn != nil {
 		return m.getAccountByCardNumberFn(account, cardNumber, currency)
 	}
 	return nil
 }
-func (m *mockRepo) GetAccountByPhoneNumber(account *domain.Account, phoneNumber string, currency string) error {
+func (m *mockRepo) GetAccountByPhoneNumber(ctx context.Context, account *domain.Account, phoneNumber string, currency string) error {
 	if m.getAccountByPhoneNumberFn != nil {
 		return m.getAccountByPhoneNumberFn(account, phoneNumber, currency)
 	}
 	return nil
 }
-func (m *mockRepo) GetTransactionHistory(idUser int) ([]domain.Transaction, error) {
+func (m *mockRepo) GetTransactionHistory(ctx context.Context, idUser int) ([]domain.Transaction, error) {
 	if m.getTransactionHistoryFn != nil {
 		return m.getTransactionHistoryFn(idUser)
 	}
 	return nil, nil
 }
-func (m *mockRepo) CreateUser(user *domain.User) error {
+func (m *mockRepo) CreateUser(ctx context.Context, user *domain.User) error {
 	if m.createUserFn != nil {
 		return m.createUserFn(user)
 	}
 	return nil
 }
-func (m *mockRepo) GetUserByEmail(email string) (*domain.User, error) {
+func (m *mockRepo) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
 	if m.getUserByEmailFn != nil {
 		return m.getUserByEmailFn(email)
 	}
 	return nil, nil
 }
-func (m *mockRepo) CreateAccount(account *domain.Account) error {
+func (m *mockRepo) CreateAccount(ctx context.Context, account *domain.Account) error {
 	if m.createAccountFn != nil {
 		return m.createAccountFn(account)
 	}
 	return nil
 }
-func (m *mockRepo) CreateCard(card *domain.Card) error {
+func (m *mockRepo) CreateCard(ctx context.Context, card *domain.Card) error {
 	if m.createCardFn != nil {
 		return m.createCardFn(card)
 	}
 	return nil
 }
-func (m *mockRepo) GetAllAccountsByUserID(userID int) ([]domain.Account, error) {
+func (m *mockRepo) GetAllAccountsByUserID(ctx context.Context, userID int) ([]domain.Account, error) {
 	if m.getAllAccountsByUserIDFn != nil {
 		return m.getAllAccountsByUserIDFn(userID)
 	}
 	return []domain.Account{}, nil
 }
 
-func TestService_BlockUnblockAccount_RequiresReason(t *testing.T) {
-	s := NewService(&mockRepo{})
-	if err := s.BlockUnblockAccount(1, true, 99, ""); err == nil {
-		t.Fatalf("expected error for empty reason")
+func (m *mockRepo) GetUserByID(ctx context.Context, userID int) (*domain.User, error) {
+	if m.getUserByIDFn != nil {
+		return m.getUserByIDFn(userID)
 	}
+	return &domain.User{ID: userID}, nil
 }
-
-func TestService_BlockUnblockAccount_Success(t *testing.T) {
-	called := false
-	s := NewService(&mockRepo{setAccountBlockFn: func(accountID int, block bool, reqLogs domain.AdminAuditLog) error {
-		called = true
-		if accountID != 10 || !block || reqLogs.AdminID != 7 {
-			t.Fatalf("wrong args: %+v", reqLogs)
-		}
-		return nil
-	}})
-	if err := s.BlockUnblockAccount(10, true, 7, "fraud"); err != nil {
-		t.Fatalf("unexpected error: %v", err)
+func (m *mockRepo) CreateScreeningReview(ctx context.Context, review *domain.ScreeningReview) error {
+	if m.createScreeningReviewFn != nil {
+		return m.createScreeningReviewFn(review)
 	}
-	if !called {
-		t.Fatalf("expected repo.SetAccountBlock called")
+	return nil
+}
+func (m *mockRepo) GetLatestScreeningReview(ctx context.Context, subjectType domain.ScreeningSubjectType, subjectRef string) (domain.ScreeningReview, error) {
+	if m.getLatestScreeningFn != nil {
+		return m.getLatestScreeningFn(subjectType, subjectRef)
 	}
+	return domain.ScreeningReview{}, errs.ErrScreeningReviewNotFound
 }
-
-func TestService_AuditLogs_DBError(t *testing.T) {
-	s := NewService(&mockRepo{getAuditLogsFn: func() ([]domain.AdminAuditLog, error) {
-		return nil, errs.ErrDatabaseError
-	}})
-	_, err := s.AuditLogs()
-	if !errors.Is(err, errs.ErrDatabaseError) {
-		t.Fatalf("expected ErrDatabaseError, got %v", err)
+func (m *mockRepo) GetScreeningReviews(ctx context.Context, status domain.ScreeningStatus) ([]domain.ScreeningReview, error) {
+	return []domain.ScreeningReview{}, nil
+}
+func (m *mockRepo) ResolveScreeningReview(ctx context.Context, reviewID int, status domain.ScreeningStatus, adminID int, note string) error {
+	if m.resolveScreeningReviewFn != nil {
+		return m.resolveScreeningReviewFn(reviewID, status, adminID, note)
 	}
+	return nil
 }
-
-func TestService_GetAllAccounts_DBSuccess(t *testing.T) {
-	accounts := []domain.Account{{ID: 1, UserID: 5}, {ID: 2, UserID: 5}}
-	s := NewService(&mockRepo{getAllAccountsByUserIDFn: func(userID int) ([]domain.Account, error) {
-		if userID != 5 {
-			t.Fatalf("expected userID 5, got %d", userID)
-		}
-		return accounts, nil
-	}})
-	got, err := s.GetAllAccounts(5)
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
+func (m *mockRepo) GetAccountByID(ctx context.Context, accountID int) (domain.Account, error) {
+	if m.getAccountByIDFn != nil {
+		return m.getAccountByIDFn(accountID)
 	}
-	if !reflect.DeepEqual(got, accounts) {
-		t.Fatalf("accounts mismatch: %+v", got)
+	return domain.Account{ID: accountID}, nil
+}
+func (m *mockRepo) CreatePendingTransfer(ctx context.Context, pt *domain.PendingTransfer) error {
+	if m.createPendingTransferFn != nil {
+		return m.createPendingTransferFn(pt)
 	}
+	return nil
 }
-
-func TestService_GetAllAccounts_DBError(t *testing.T) {
-	s := NewService(&mockRepo{getAllAccountsByUserIDFn: func(userID int) ([]domain.Account, error) {
-		return nil, errs.ErrDatabaseError
-	}})
-	_, err := s.GetAllAccounts(5)
-	if !errors.Is(err, errs.ErrDatabaseError) {
-		t.Fatalf("expected ErrDatabaseError, got %v", err)
+func (m *mockRepo) GetPendingTransferByID(ctx context.Context, id int) (domain.PendingTransfer, error) {
+	if m.getPendingTransferByIDFn != nil {
+		return m.getPendingTransferByIDFn(id)
 	}
+	return domain.PendingTransfer{}, errs.ErrPendingTransferNotFound
 }
-
-func TestService_translateError_Mapping(t *testing.T) {
-	s := NewService(&mockRepo{})
-	cases := []struct {
-		in  error
-		out error
-	}{
-		{errs.ErrUserNotFound, errs.ErrInvalidCredentials},
-		{errs.ErrAccountNotFound, errs.ErrAccessDenied},
-		{errs.ErrUserAlreadyExists, errs.ErrUserAlreadyRegistered},
-		{errs.ErrCardAlreadyExists, errs.ErrInvalidOperation},
-		{errs.ErrDatabaseError, errs.ErrDatabaseError},
+func (m *mockRepo) GetPendingTransfers(ctx context.Context, status domain.PendingTransferStatus) ([]domain.PendingTransfer, error) {
+	return []domain.PendingTransfer{}, nil
+}
+func (m *mockRepo) GetPendingTransfersForSignatory(ctx context.Context, userID int) ([]domain.PendingTransfer, error) {
+	return []domain.PendingTransfer{}, nil
+}
+func (m *mockRepo) GetExpiredPendingTransfers(ctx context.Context) ([]domain.PendingTransfer, error) {
+	return []domain.PendingTransfer{}, nil
+}
+func (m *mockRepo) ApprovePendingTransfer(ctx context.Context, pt domain.PendingTransfer, approverID int, note string, reqLogs domain.AdminAuditLog) error {
+	if m.approvePendingTransferFn != nil {
+		return m.approvePendingTransferFn(pt, approverID, note, reqLogs)
 	}
-	for _, tc := range cases {
-		got := s.translateError(tc.in)
-		if !errors.Is(got, tc.out) {
-			t.Fatalf("expected %v, got %v", tc.out, got)
-		}
+	return nil
+}
+func (m *mockRepo) ReleasePendingTransfer(ctx context.Context, pt domain.PendingTransfer, status domain.PendingTransferStatus, deciderID int, note string, reqLogs domain.AdminAuditLog) error {
+	if m.releasePendingTransferFn != nil {
+		return m.releasePendingTransferFn(pt, status, deciderID, note, reqLogs)
 	}
+	return nil
 }
-
-func TestService_LimitHelpers(t *testing.T) {
-	s := NewService(&mockRepo{})
-	// ConvertToBaseCurrency success
-	v, err := s.ConvertToBaseCurrency(2, "USD")
-	if err != nil || v <= 0 {
-		t.Fatalf("unexpected convert err=%v v=%f", err, v)
+func (m *mockRepo) IsAccountSignatory(ctx context.Context, accountID, userID int) (bool, error) {
+	if m.isAccountSignatoryFn != nil {
+		return m.isAccountSignatoryFn(accountID, userID)
 	}
-	// unsupported currency
-	if _, err := s.ConvertToBaseCurrency(1, "ABC"); err == nil {
-		t.Fatalf("expected error for unsupported currency")
+	return false, nil
+}
+func (m *mockRepo) AddAccountSignatory(ctx context.Context, accountID, userID int) error {
+	return nil
+}
+func (m *mockRepo) CreatePendingAction(ctx context.Context, action *domain.PendingAction) error {
+	if m.createPendingActionFn != nil {
+		return m.createPendingActionFn(action)
 	}
-	// CalculateOverlimitFee
-	fee := s.CalculateOverlimitFee(100)
-	if math.Abs(fee-2) > 1e-9 {
-		t.Fatalf("expected fee 2 got %f", fee)
+	return nil
+}
+func (m *mockRepo) GetPendingActionByID(ctx context.Context, id int) (domain.PendingAction, error) {
+	if m.getPendingActionByIDFn != nil {
+		return m.getPendingActionByIDFn(id)
 	}
-	// IsNewDay
-	if !s.IsNewDay(time.Now().Add(-24 * time.Hour)) {
-		t.Fatalf("expected new day")
+	return domain.PendingAction{}, errs.ErrPendingActionNotFound
+}
+func (m *mockRepo) GetPendingActions(ctx context.Context, status domain.PendingActionStatus) ([]domain.PendingAction, error) {
+	return []domain.PendingAction{}, nil
+}
+func (m *mockRepo) GetExpiredPendingActions(ctx context.Context) ([]domain.PendingAction, error) {
+	return []domain.PendingAction{}, nil
+}
+func (m *mockRepo) ExecutePendingAction(ctx context.Context, action domain.PendingAction, approverID int, note string, reqLogs domain.AdminAuditLog) error {
+	if m.executePendingActionFn != nil {
+		return m.executePendingActionFn(action, approverID, note, reqLogs)
 	}
+	return nil
 }
-
-func TestService_HistoryLogs(t *testing.T) {
-	s := NewService(&mockRepo{getTransactionHistoryFn: func(idUser int) ([]domain.Transaction, error) {
-		if idUser != 5 {
-			t.Fatalf("want 5")
-		}
-		return []domain.Transaction{{ID: 1}}, nil
-	}})
-	got, err := s.HistoryLogs(5)
-	if err != nil || len(got) != 1 {
-		t.Fatalf("unexpected: %v %+v", err, got)
+func (m *mockRepo) ExecutePendingAdjustment(ctx context.Context, action domain.PendingAction, adj domain.BalanceAdjustment, approverID int, note string, reqLogs domain.AdminAuditLog) (domain.BalanceAdjustment, error) {
+	if m.executePendingAdjustmentFn != nil {
+		return m.executePendingAdjustmentFn(action, adj, approverID, reqLogs)
 	}
+	return adj, nil
 }
-
-// extend mockRepo to allow history injection
-
-func TestService_Register_Success(t *testing.T) {
-	s := NewService(&mockRepo{createUserFn: func(user *domain.User) error {
-		user.ID = 99
-		return nil
-	}})
-	u, err := s.Register(domain.ReqRegister{FullName: "John Doe", Phone: "123", Email: "a@b.c", Password: "password123"}, domain.RoleUser)
-	if err != nil {
-		t.Fatalf("unexpected: %v", err)
+func (m *mockRepo) ClosePendingAction(ctx context.Context, action domain.PendingAction, status domain.PendingActionStatus, deciderID int, note string, reqLogs domain.AdminAuditLog) error {
+	return nil
+}
+func (m *mockRepo) CreateSession(ctx context.Context, session *domain.Session, tokenHash string) error {
+	if m.createSessionFn != nil {
+		return m.createSessionFn(session, tokenHash)
 	}
-	if u.ID == 0 {
-		t.Fatalf("expected non-zero user id")
+	return nil
+}
+func (m *mockRepo) GetSession(ctx context.Context, sessionID string) (domain.Session, error) {
+	if m.getSessionFn != nil {
+		return m.getSessionFn(sessionID)
 	}
+	return domain.Session{ID: sessionID, UserID: 7, ExpiresAt: time.Now().Add(time.Hour)}, nil
 }
-
-func TestService_Login_SuccessAndInvalid(t *testing.T) {
-	// create hashed password
-	pw := "password123"
-	hashed, _ := bcrypt.GenerateFromPassword([]byte(pw), bcrypt.DefaultCost)
-	s := NewService(&mockRepo{getUserByEmailFn: func(email string) (*domain.User, error) {
-		return &domain.User{ID: 5, Email: email, Password: string(hashed), Role: domain.RoleUser}, nil
-	}})
-	// success
-	tok, err := s.Login(domain.ReqLogin{Email: "a@b.c", Password: pw})
-	if err != nil || tok.AccessToken == "" {
-		t.Fatalf("unexpected: %v tok=%+v", err, tok)
+func (m *mockRepo) GetRefreshToken(ctx context.Context, tokenHash string) (domain.RefreshToken, error) {
+	if m.getRefreshTokenFn != nil {
+		return m.getRefreshTokenFn(tokenHash)
 	}
-	// invalid password
-	if _, err := s.Login(domain.ReqLogin{Email: "a@b.c", Password: "wrong"}); err == nil {
-		t.Fatalf("expected error on bad password")
+	return domain.RefreshToken{}, errs.ErrInvalidRefreshToken
+}
+func (m *mockRepo) RotateRefreshToken(ctx context.Context, oldTokenID int, sessionID string, newTokenHash string, expiresAt time.Time) error {
+	if m.rotateRefreshTokenFn != nil {
+		return m.rotateRefreshTokenFn(oldTokenID, sessionID, newTokenHash, expiresAt)
 	}
+	return nil
 }
-
-func TestService_Tokens_ParseAndRefresh(t *testing.T) {
-	s := NewService(&mockRepo{})
-	// create access token and parse
-	at, err := s.createAccessToken(7, domain.RoleAdmin)
-	if err != nil {
-		t.Fatalf("createAccessToken: %v", err)
+func (m *mockRepo) RevokeSession(ctx context.Context, sessionID string, reason string) error {
+	if m.revokeSessionFn != nil {
+		return m.revokeSessionFn(sessionID, reason)
 	}
-	user, err := s.ParseToken(at)
-	if err != nil || user.ID != 7 || user.Role != domain.RoleAdmin {
-		t.Fatalf("parse fail: %v user=%+v", err, user)
+	return nil
+}
+func (m *mockRepo) RevokeUserSessions(ctx context.Context, userID int, reason string) ([]string, error) {
+	if m.revokeUserSessionsFn != nil {
+		return m.revokeUserSessionsFn(userID, reason)
 	}
-	// create refresh and refresh
-	rt, err := s.createRefreshToken(7, domain.RoleAdmin)
-	if err != nil {
-		t.Fatalf("createRefreshToken: %v", err)
+	return []string{}, nil
+}
+func (m *mockRepo) GetActiveSessions(ctx context.Context, userID int) ([]domain.Session, error) {
+	return []domain.Session{}, nil
+}
+func (m *mockRepo) GetUserDeviceIDs(ctx context.Context, userID int) ([]string, error) {
+	if m.getUserDeviceIDsFn != nil {
+		return m.getUserDeviceIDsFn(userID)
 	}
-	tr, err := s.RefreshToken(domain.ReqRefreshToken{RefreshToken: rt})
-	if err != nil || tr.AccessToken == "" {
-		t.Fatalf("refresh failed: %v %+v", err, tr)
+	return []string{}, nil
+}
+func (m *mockRepo) SetSessionStepUpCode(ctx context.Context, sessionID string, codeHash string, expiresAt time.Time) error {
+	if m.setSessionStepUpCodeFn != nil {
+		return m.setSessionStepUpCodeFn(sessionID, codeHash, expiresAt)
 	}
+	return nil
 }
-
-func TestService_Deposit_And_Withdraw_Success(t *testing.T) {
-	s := NewService(&mockRepo{
-		getAccountByCardNumberFn: func(acc *domain.Account, card string, currency string) error {
-			*acc = domain.Account{ID: 1, UserID: 5, Balance: "100.00", Currency: currency}
-			return nil
-		},
-		depositToAccountFn: func(accountID int, amount float64) error {
-			if accountID != 1 || amount != 10 {
-				t.Fatalf("bad deposit args")
-			}
-			return nil
-		},
-		resetDailyLimitFn: func(userID int) error { return nil },
-		withdrawFromAccountFn: func(accountID int, amount float64, currency string) error {
-			if accountID != 1 || amount <= 0 {
-				t.Fatalf("bad withdraw args")
-			}
-			return nil
-		},
-	})
-	// deposit
-	if err := s.Deposit(5, domain.ReqTransaction{CardNumber: "4000", Amount: 10, Currency: "TJS"}); err != nil {
-		t.Fatalf("deposit err: %v", err)
+func (m *mockRepo) CompleteSessionStepUp(ctx context.Context, sessionID string) error {
+	if m.completeSessionStepUpFn != nil {
+		return m.completeSessionStepUpFn(sessionID)
 	}
-	// withdraw
-	if err := s.Withdraw(5, domain.ReqTransaction{CardNumber: "4000", Amount: 10, Currency: "TJS"}); err != nil {
-		t.Fatalf("withdraw err: %v", err)
+	return nil
+}
+func (m *mockRepo) RecordLoginAttempt(ctx context.Context, attempt domain.LoginAttempt) error {
+	if m.recordLoginAttemptFn != nil {
+		return m.recordLoginAttemptFn(attempt)
 	}
+	return nil
 }
-
-func TestService_CreateCardForAccount_Success(t *testing.T) {
-	called := false
-	s := NewService(&mockRepo{createCardFn: func(card *domain.Card) error {
-		called = true
-		card.ID = 77
-		return nil
-	}})
-	card, err := s.CreateCardForAccount(10, "John Doe")
-	if err != nil || card == nil || card.ID != 77 {
-		t.Fatalf("unexpected: %v %+v", err, card)
+func (m *mockRepo) GetLoginFailureStats(ctx context.Context, email, ip string, since time.Time) (domain.LoginFailureStats, error) {
+	if m.getLoginFailureStatsFn != nil {
+		return m.getLoginFailureStatsFn(email, ip, since)
 	}
-	if !called {
-		t.Fatalf("expected repo.CreateCard called")
+	return domain.LoginFailureStats{}, nil
+}
+func (m *mockRepo) ClearLoginFailures(ctx context.Context, email string) error {
+	return nil
+}
+func (m *mockRepo) GetLoginLock(ctx context.Context, email string) (domain.LoginLock, error) {
+	if m.getLoginLockFn != nil {
+		return m.getLoginLockFn(email)
 	}
+	return domain.LoginLock{}, nil
 }
-
-func TestService_Withdraw_InsufficientIncludingFee(t *testing.T) {
-	s := NewService(&mockRepo{
-		getAccountByPhoneNumberFn: func(acc *domain.Account, phone string, currency string) error {
-			*acc = domain.Account{ID: 1, UserID: 5, Balance: "10.00", Currency: currency}
-			return nil
-		},
-		getDailyLimitByUserIDFn: func(userID int) (domain.Limit, error) {
-			return domain.Limit{UserID: userID, DailyAmount: 5.0, LastReset: time.Now()}, nil
-		},
-		getTodayUsageInTJSFn: func(userID int) (float64, error) { return 5.0, nil },
-	})
-	// amount 10 > balance 10 after fee > 0, expect error
-	err := s.Withdraw(5, domain.ReqTransaction{PhoneNumber: "992", Amount: 10, Currency: "TJS"})
-	if err == nil || err.Error() != "insufficient funds including overlimit fee" {
-		t.Fatalf("expected overlimit insufficient, got %v", err)
+func (m *mockRepo) GetActiveLoginLocks(ctx context.Context) ([]domain.LoginLock, error) {
+	return []domain.LoginLock{}, nil
+}
+func (m *mockRepo) LockLogin(ctx context.Context, lock domain.LoginLock, reqLogs domain.AdminAuditLog) error {
+	if m.lockLoginFn != nil {
+		return m.lockLoginFn(lock, reqLogs)
 	}
+	return nil
 }
-
-func TestService_Transfer_Success(t *testing.T) {
-	called := false
-	s := NewService(&mockRepo{
-		getAccountByCardNumberFn: func(acc *domain.Account, card string, currency string) error {
-			if card == "4000" {
-				*acc = domain.Account{ID: 1, UserID: 5, Balance: "100.00", Currency: currency}
-			}
-			if card == "5000" {
-				*acc = domain.Account{ID: 2, UserID: 6, Balance: "20.00", Currency: currency}
+func (m *mockRepo) UnlockLogin(ctx context.Context, email string, reqLogs domain.AdminAuditLog) error {
+	if m.unlockLoginFn != nil {
+		return m.unlockLoginFn(email, reqLogs)
+	}
+	return nil
+}
+func (m *mockRepo) MarkSessionMFAVerified(ctx context.Context, sessionID string) error {
+	return nil
+}
+func (m *mockRepo) GetMFAConfig(ctx context.Context, userID int) (domain.MFAConfig, error) {
+	if m.getMFAConfigFn != nil {
+		return m.getMFAConfigFn(userID)
+	}
+	return domain.MFAConfig{}, nil
+}
+func (m *mockRepo) SaveMFASecret(ctx context.Context, userID int, secret string) error {
+	return nil
+}
+func (m *mockRepo) EnableMFA(ctx context.Context, userID int, step int64, recoveryCodeHashes []string) error {
+	if m.enableMFAFn != nil {
+		return m.enableMFAFn(userID, step, recoveryCodeHashes)
+	}
+	return nil
+}
+func (m *mockRepo) UseMFAStep(ctx context.Context, userID int, step int64) error {
+	if m.useMFAStepFn != nil {
+		return m.useMFAStepFn(userID, step)
+	}
+	return nil
+}
+func (m *mockRepo) UseRecoveryCode(ctx context.Context, userID int, codeHash string) error {
+	if m.useRecoveryCodeFn != nil {
+		return m.useRecoveryCodeFn(userID, codeHash)
+	}
+	return errs.ErrInvalidMFACode
+}
+func (m *mockRepo) ReplaceRecoveryCodes(ctx context.Context, userID int, recoveryCodeHashes []string) error {
+	return nil
+}
+func (m *mockRepo) DisableMFA(ctx context.Context, userID int) error {
+	return nil
+}
+func (m *mockRepo) CreateTransferConfirmation(ctx context.Context, c *domain.TransferConfirmation) error {
+	if m.createConfirmationFn != nil {
+		return m.createConfirmationFn(c)
+	}
+	return nil
+}
+func (m *mockRepo) GetTransferConfirmation(ctx context.Context, id int) (domain.TransferConfirmation, error) {
+	if m.getConfirmationFn != nil {
+		return m.getConfirmationFn(id)
+	}
+	return domain.TransferConfirmation{}, errs.ErrTransferConfirmationNotFound
+}
+func (m *mockRepo) RecordTransferConfirmationAttempt(ctx context.Context, id int, maxAttempts int) (int, error) {
+	if m.recordConfirmAttemptFn != nil {
+		return m.recordConfirmAttemptFn(id, maxAttempts)
+	}
+	return 1, nil
+}
+func (m *mockRepo) CloseTransferConfirmation(ctx context.Context, id int, status domain.TransferConfirmationStatus) error {
+	if m.closeConfirmationFn != nil {
+		return m.closeConfirmationFn(id, status)
+	}
+	return nil
+}
+func (m *mockRepo) IsKnownRecipient(ctx context.Context, userID, accountID int) (bool, error) {
+	if m.isKnownRecipientFn != nil {
+		return m.isKnownRecipientFn(userID, accountID)
+	}
+	return true, nil
+}
+func (m *mockRepo) AddKnownRecipient(ctx context.Context, userID, accountID int) error {
+	return nil
+}
+func (m *mockRepo) CreateStaffInvite(ctx context.Context, invite *domain.StaffInvite, reqLogs domain.AdminAuditLog) error {
+	if m.createStaffInviteFn != nil {
+		return m.createStaffInviteFn(invite, reqLogs)
+	}
+	invite.ID = 1
+	return nil
+}
+func (m *mockRepo) GetStaffInviteByTokenHash(ctx context.Context, tokenHash string) (domain.StaffInvite, error) {
+	if m.getStaffInviteFn != nil {
+		return m.getStaffInviteFn(tokenHash)
+	}
+	return domain.StaffInvite{}, errs.ErrInviteNotFound
+}
+func (m *mockRepo) ClaimStaffInvite(ctx context.Context, inviteID int) error {
+	if m.claimStaffInviteFn != nil {
+		return m.claimStaffInviteFn(inviteID)
+	}
+	return nil
+}
+func (m *mockRepo) CompleteStaffInvite(ctx context.Context, inviteID int, userID int) error {
+	if m.completeStaffInviteFn != nil {
+		return m.completeStaffInviteFn(inviteID, userID)
+	}
+	return nil
+}
+func (m *mockRepo) CountUsersByRole(ctx context.Context, role domain.Role) (int, error) {
+	if m.countUsersByRoleFn != nil {
+		return m.countUsersByRoleFn(role)
+	}
+	return 0, nil
+}
+func (m *mockRepo) CreatePasswordResetToken(ctx context.Context, token *domain.PasswordResetToken) error {
+	if m.createResetTokenFn != nil {
+		return m.createResetTokenFn(token)
+	}
+	return nil
+}
+func (m *mockRepo) GetPasswordResetToken(ctx context.Context, tokenHash string) (domain.PasswordResetToken, error) {
+	if m.getResetTokenFn != nil {
+		return m.getResetTokenFn(tokenHash)
+	}
+	return domain.PasswordResetToken{}, errs.ErrInvalidResetToken
+}
+func (m *mockRepo) ResetPassword(ctx context.Context, tokenID int, userID int, passwordHash string) error {
+	if m.resetPasswordFn != nil {
+		return m.resetPasswordFn(tokenID, userID, passwordHash)
+	}
+	return nil
+}
+func (m *mockRepo) UpdatePassword(ctx context.Context, userID int, passwordHash string) error {
+	if m.updatePasswordFn != nil {
+		return m.updatePasswordFn(userID, passwordHash)
+	}
+	return nil
+}
+func (m *mockRepo) CreateAPIClient(ctx context.Context, client *domain.APIClient, reqLogs domain.AdminAuditLog) error {
+	if m.createAPIClientFn != nil {
+		return m.createAPIClientFn(client, reqLogs)
+	}
+	return nil
+}
+func (m *mockRepo) GetAPIClientByClientID(ctx context.Context, clientID string) (domain.APIClient, error) {
+	if m.getAPIClientFn != nil {
+		return m.getAPIClientFn(clientID)
+	}
+	return domain.APIClient{}, errs.ErrAPIClientNotFound
+}
+func (m *mockRepo) GetAPIClientByID(ctx context.Context, id int) (domain.APIClient, error) {
+	if m.getAPIClientByIDFn != nil {
+		return m.getAPIClientByIDFn(id)
+	}
+	return domain.APIClient{}, errs.ErrAPIClientNotFound
+}
+func (m *mockRepo) GetAPIClients(ctx context.Context) ([]domain.APIClient, error) { return nil, nil }
+func (m *mockRepo) RevokeAPIClient(ctx context.Context, id int, reqLogs domain.AdminAuditLog) error {
+	return nil
+}
+func (m *mockRepo) TouchAPIClient(ctx context.Context, id int) error { return nil }
+func (m *mockRepo) CreateAuditLog(ctx context.Context, reqLogs domain.AdminAuditLog) error {
+	if m.createAuditLogFn != nil {
+		return m.createAuditLogFn(reqLogs)
+	}
+	return nil
+}
+func (m *mockRepo) UpdateUserProfile(ctx context.Context, update domain.ProfileUpdate) error {
+	if m.updateUserProfileFn != nil {
+		return m.updateUserProfileFn(update)
+	}
+	return nil
+}
+func (m *mockRepo) GetProfileChanges(ctx context.Context, userID int) ([]domain.ProfileChange, error) {
+	return nil, nil
+}
+func (m *mockRepo) GetCardsByUserID(ctx context.Context, userID int) ([]domain.Card, error) {
+	if m.getCardsByUserIDFn != nil {
+		return m.getCardsByUserIDFn(userID)
+	}
+	return nil, nil
+}
+func (m *mockRepo) AppendAuditEvent(ctx context.Context, event domain.AuditEvent) (domain.AuditEvent, error) {
+	if m.appendAuditEventFn != nil {
+		return m.appendAuditEventFn(event)
+	}
+	return event, nil
+}
+func (m *mockRepo) GetAuditEventsAfter(ctx context.Context, afterID int64, limit int) ([]domain.AuditEvent, error) {
+	if m.getAuditEventsAfterFn != nil {
+		return m.getAuditEventsAfterFn(afterID, limit)
+	}
+	return nil, nil
+}
+func (m *mockRepo) SearchUsers(ctx context.Context, filter domain.UserSearchFilter) ([]domain.User, error) {
+	if m.searchUsersFn != nil {
+		return m.searchUsersFn(filter)
+	}
+	return nil, nil
+}
+func (m *mockRepo) SetUserDisabled(ctx context.Context, userID int, disabled bool, reqLogs domain.AdminAuditLog) error {
+	if m.setUserDisabledFn != nil {
+		return m.setUserDisabledFn(userID, disabled, reqLogs)
+	}
+	return nil
+}
+func (m *mockRepo) CreateContactVerification(ctx context.Context, v *domain.ContactVerification) error {
+	if m.createVerificationFn != nil {
+		return m.createVerificationFn(v)
+	}
+	return nil
+}
+func (m *mockRepo) GetPendingContactVerification(ctx context.Context, userID int, channel domain.VerificationChannel) (domain.ContactVerification, error) {
+	if m.getVerificationFn != nil {
+		return m.getVerificationFn(userID, channel)
+	}
+	return domain.ContactVerification{}, errs.ErrVerificationNotFound
+}
+func (m *mockRepo) RecordContactVerificationAttempt(ctx context.Context, id int, maxAttempts int) (int, error) {
+	if m.recordVerificationAttemptFn != nil {
+		return m.recordVerificationAttemptFn(id, maxAttempts)
+	}
+	return 1, nil
+}
+func (m *mockRepo) CompleteContactVerification(ctx context.Context, v domain.ContactVerification) error {
+	if m.completeVerificationFn != nil {
+		return m.completeVerificationFn(v)
+	}
+	return nil
+}
+
+func TestService_RestrictAccount_RequiresReason(t *testing.T) {
+	s := NewService(&mockRepo{})
+	if _, err := s.RestrictAccount(context.Background(), 99, 1, domain.ReqAdminAccountAction{Block: true, Mode: domain.RestrictionFullBlock, Category: domain.RestrictionOther}); err == nil {
+		t.Fatalf("expected error for empty reason")
+	}
+}
+
+func TestService_ProposeAdminAction_LiftRestriction(t *testing.T) {
+	var created domain.PendingAction
+	s := NewService(&mockRepo{
+		getAccountByIDFn: func(accountID int) (domain.Account, error) {
+			return domain.Account{ID: accountID}, nil
+		},
+		activeRestrictionsFn: func(accountID int) (domain.AccountRestrictions, error) {
+			return domain.AccountRestrictions{{AccountID: accountID, Mode: domain.RestrictionLegalHold, Amount: 500}}, nil
+		},
+		liftRestrictionsFn: func(accountID int, mode domain.RestrictionMode, liftedBy int, reqLogs domain.AdminAuditLog) (int, error) {
+			t.Fatalf("restriction must not be lifted before approval")
+			return 0, nil
+		},
+		createPendingActionFn: func(action *domain.PendingAction) error {
+			action.ID = 6
+			created = *action
+			return nil
+		},
+	})
+
+	// legal_hold снимается только после одобрения вторым админом
+	action, err := s.ProposeAdminAction(context.Background(), domain.PendingAction{Type: domain.ActionUnblock, AccountID: 10,
+		RestrictionMode: domain.RestrictionLegalHold, Reason: "court order lifted"}, 7)
+	if err != nil || action.ID != 6 || created.RestrictionMode != domain.RestrictionLegalHold {
+		t.Fatalf("unexpected: %v %+v", err, created)
+	}
+	// debit_freeze на счете нет - снимать нечего
+	if _, err := s.ProposeAdminAction(context.Background(), domain.PendingAction{Type: domain.ActionUnblock, AccountID: 10,
+		RestrictionMode: domain.RestrictionDebitFreeze, Reason: "r"}, 7); !errors.Is(err, errs.ErrInvalidOperation) {
+		t.Fatalf("expected ErrInvalidOperation, got %v", err)
+	}
+	// без вида снимается полная блокировка, ее на счете тоже нет
+	if _, err := s.ProposeAdminAction(context.Background(), domain.PendingAction{Type: domain.ActionUnblock, AccountID: 10, Reason: "r"}, 7); !errors.Is(err, errs.ErrInvalidOperation) {
+		t.Fatalf("expected ErrInvalidOperation, got %v", err)
+	}
+}
+
+func TestService_RestrictAccount_Success(t *testing.T) {
+	called := false
+	s := NewService(&mockRepo{createRestrictionFn: func(restriction domain.AccountRestriction, reqLogs domain.AdminAuditLog) (domain.AccountRestriction, error) {
+		called = true
+		if restriction.AccountID != 10 || restriction.Mode != domain.RestrictionFullBlock || reqLogs.AdminID != 7 || reqLogs.Action != "block" {
+			t.Fatalf("wrong args: %+v %+v", restriction, reqLogs)
+		}
+		restriction.ID = 1
+		return restriction, nil
+	}})
+	req := domain.ReqAdminAccountAction{Block: true, Mode: domain.RestrictionFullBlock, Category: domain.RestrictionFraud, Reason: "fraud"}
+	if _, err := s.RestrictAccount(context.Background(), 7, 10, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !called {
+		t.Fatalf("expected repo.CreateAccountRestriction called")
+	}
+}
+
+func TestService_RestrictAccount_Validation(t *testing.T) {
+	s := NewService(&mockRepo{createRestrictionFn: func(restriction domain.AccountRestriction, reqLogs domain.AdminAuditLog) (domain.AccountRestriction, error) {
+		t.Fatal("repository must not be called for invalid restriction")
+		return restriction, nil
+	}})
+	for _, tc := range []struct {
+		req  domain.ReqAdminAccountAction
+		want error
+	}{
+		{domain.ReqAdminAccountAction{Mode: domain.RestrictionLegalHold, Category: domain.RestrictionCourtOrder, Reason: "r"}, errs.ErrInvalidAmount},
+		{domain.ReqAdminAccountAction{Mode: domain.RestrictionDebitFreeze, Amount: 10, Category: domain.RestrictionFraud, Reason: "r"}, errs.ErrInvalidAmount},
+		{domain.ReqAdminAccountAction{Mode: "soft", Category: domain.RestrictionFraud, Reason: "r"}, errs.ErrInvalidData},
+		{domain.ReqAdminAccountAction{Mode: domain.RestrictionDebitFreeze, Category: domain.RestrictionFraud, Reason: "r", ExpiresAt: time.Now().Add(-time.Hour)}, errs.ErrInvalidData},
+	} {
+		if _, err := s.RestrictAccount(context.Background(), 7, 10, tc.req); !errors.Is(err, tc.want) {
+			t.Fatalf("%+v: expected %v, got %v", tc.req, tc.want, err)
+		}
+	}
+}
+
+func TestService_Restrictions_EnforcedForEveryOperation(t *testing.T) {
+	restrictions := map[int]domain.AccountRestrictions{
+		1: {{Mode: domain.RestrictionDebitFreeze}},
+		2: {{Mode: domain.RestrictionCreditFreeze}},
+		3: {{Mode: domain.RestrictionFullBlock}},
+		4: {{Mode: domain.RestrictionLegalHold, Amount: 80}},
+	}
+	accounts := map[string]domain.Account{
+		"1111": {ID: 1, UserID: 5, Balance: "100.00", Currency: "TJS"},
+		"2222": {ID: 2, UserID: 5, Balance: "100.00", Currency: "TJS"},
+		"3333": {ID: 3, UserID: 5, Balance: "100.00", Currency: "TJS"},
+		"4444": {ID: 4, UserID: 5, Balance: "100.00", Currency: "TJS"},
+		"5555": {ID: 5, UserID: 6, Balance: "100.00", Currency: "TJS"},
+	}
+	moved := 0
+	s := NewService(&mockRepo{
+		getAccountByCardNumberFn: func(account *domain.Account, cardNumber string, currency string) error {
+			*account = accounts[cardNumber]
+			return nil
+		},
+		activeRestrictionsFn: func(accountID int) (domain.AccountRestrictions, error) {
+			return restrictions[accountID], nil
+		},
+		depositToAccountFn: func(accountID int, amount float64) error {
+			moved++
+			return nil
+		},
+		withdrawFromAccountFn: func(accountID int, amount float64, currency string) error {
+			moved++
+			return nil
+		},
+		transferFundsFn: func(fromAccountID, toAccountID int, amount float64) error {
+			moved++
+			return nil
+		},
+	})
+
+	deposit := func(card string) error {
+		return s.Deposit(context.Background(), 5, domain.ReqTransaction{Amount: 10, CardNumber: card})
+	}
+	withdraw := func(card string, amount float64) error {
+		return s.Withdraw(context.Background(), 5, domain.ReqTransaction{Amount: amount, CardNumber: card})
+	}
+	transfer := func(from, to string) error {
+		_, err := s.Transfer(context.Background(), 5, domain.ReqTransfer{Amount: 10, FromCardNumber: from, ToCardNumber: to, OTPConfirmed: true})
+		return err
+	}
+
+	for _, tc := range []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"deposit to credit-frozen", deposit("2222"), errs.ErrAccountRestricted},
+		{"deposit to blocked", deposit("3333"), errs.ErrAccountBlocked},
+		{"withdraw from debit-frozen", withdraw("1111", 10), errs.ErrAccountRestricted},
+		{"withdraw from blocked", withdraw("3333", 10), errs.ErrAccountBlocked},
+		{"transfer from debit-frozen", transfer("1111", "5555"), errs.ErrAccountRestricted},
+		{"transfer to credit-frozen", transfer("4444", "2222"), errs.ErrAccountRestricted},
+		{"transfer to blocked", transfer("4444", "3333"), errs.ErrAccountBlocked},
+	} {
+		if !errors.Is(tc.err, tc.want) {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.want, tc.err)
+		}
+	}
+
+	// legal_hold 80 из 100: доступно 20
+	if err := withdraw("4444", 30); err == nil || moved != 0 {
+		t.Fatalf("withdrawal into legal hold must fail, err=%v", err)
+	}
+	if err := transfer("4444", "5555"); err != nil {
+		t.Fatalf("transfer within available funds: unexpected error %v", err)
+	}
+	if moved != 1 {
+		t.Fatalf("expected only the legal hold transfer to move funds, moved=%d", moved)
+	}
+
+	// Ограничение, появившееся пока перевод ждал одобрения, находит транзакция одобрения; решение не фиксируется
+	var events []domain.AuditEvent
+	s = NewService(&mockRepo{
+		getPendingTransferByIDFn: func(id int) (domain.PendingTransfer, error) {
+			return domain.PendingTransfer{ID: id, FromAccountID: 4, ToAccountID: 2, InitiatorID: 5,
+				Status: domain.PendingTransferPending, ExpiresAt: time.Now().Add(time.Hour)}, nil
+		},
+		approvePendingTransferFn: func(pt domain.PendingTransfer, approverID int, note string, reqLogs domain.AdminAuditLog) error {
+			return errs.ErrAccountRestricted
+		},
+		appendAuditEventFn: func(event domain.AuditEvent) (domain.AuditEvent, error) {
+			events = append(events, event)
+			return event, nil
+		},
+	})
+	if err := s.DecidePendingTransfer(context.Background(), 3, domain.User{ID: 9, Role: domain.RoleAdmin}, true, ""); !errors.Is(err, errs.ErrAccountRestricted) {
+		t.Fatalf("expected ErrAccountRestricted, got %v", err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("failed approval must not be recorded as approved: %+v", events)
+	}
+}
+
+func TestService_AuditLogs_DBError(t *testing.T) {
+	s := NewService(&mockRepo{getAuditLogsFn: func(filter domain.AuditLogFilter) ([]domain.AdminAuditLog, error) {
+		return nil, errs.ErrDatabaseError
+	}})
+	_, err := s.AuditLogs(context.Background(), domain.AuditLogFilter{})
+	if !errors.Is(err, errs.ErrDatabaseError) {
+		t.Fatalf("expected ErrDatabaseError, got %v", err)
+	}
+}
+
+func TestService_GetAllAccounts_DBSuccess(t *testing.T) {
+	accounts := []domain.Account{{ID: 1, UserID: 5}, {ID: 2, UserID: 5}}
+	s := NewService(&mockRepo{getAllAccountsByUserIDFn: func(userID int) ([]domain.Account, error) {
+		if userID != 5 {
+			t.Fatalf("expected userID 5, got %d", userID)
+		}
+		return accounts, nil
+	}})
+	got, err := s.GetAllAccounts(context.Background(), 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, accounts) {
+		t.Fatalf("accounts mismatch: %+v", got)
+	}
+}
+
+func TestService_GetAllAccounts_DBError(t *testing.T) {
+	s := NewService(&mockRepo{getAllAccountsByUserIDFn: func(userID int) ([]domain.Account, error) {
+		return nil, errs.ErrDatabaseError
+	}})
+	_, err := s.GetAllAccounts(context.Background(), 5)
+	if !errors.Is(err, errs.ErrDatabaseError) {
+		t.Fatalf("expected ErrDatabaseError, got %v", err)
+	}
+}
+
+func TestService_translateError_Mapping(t *testing.T) {
+	s := NewService(&mockRepo{})
+	cases := []struct {
+		in  error
+		out error
+	}{
+		{errs.ErrUserNotFound, errs.ErrInvalidCredentials},
+		{errs.ErrAccountNotFound, errs.ErrAccessDenied},
+		{errs.ErrUserAlreadyExists, errs.ErrUserAlreadyRegistered},
+		{errs.ErrCardAlreadyExists, errs.ErrInvalidOperation},
+		{errs.ErrDatabaseError, errs.ErrDatabaseError},
+	}
+	for _, tc := range cases {
+		got := s.translateError(tc.in)
+		if !errors.Is(got, tc.out) {
+			t.Fatalf("expected %v, got %v", tc.out, got)
+		}
+	}
+}
+
+func TestService_LimitHelpers(t *testing.T) {
+	s := NewService(&mockRepo{})
+	// ConvertToBaseCurrency success
+	v, err := s.ConvertToBaseCurrency(2, "USD")
+	if err != nil || v <= 0 {
+		t.Fatalf("unexpected convert err=%v v=%f", err, v)
+	}
+	// unsupported currency
+	if _, err := s.ConvertToBaseCurrency(1, "ABC"); err == nil {
+		t.Fatalf("expected error for unsupported currency")
+	}
+	// CalculateOverlimitFee
+	fee := s.CalculateOverlimitFee(100)
+	if math.Abs(fee-2) > 1e-9 {
+		t.Fatalf("expected fee 2 got %f", fee)
+	}
+	// IsNewDay
+	if !s.IsNewDay(time.Now().Add(-24 * time.Hour)) {
+		t.Fatalf("expected new day")
+	}
+}
+
+func TestService_HistoryLogs(t *testing.T) {
+	s := NewService(&mockRepo{getTransactionHistoryFn: func(idUser int) ([]domain.Transaction, error) {
+		if idUser != 5 {
+			t.Fatalf("want 5")
+		}
+		return []domain.Transaction{{ID: 1}}, nil
+	}})
+	got, err := s.HistoryLogs(context.Background(), 5)
+	if err != nil || len(got) != 1 {
+		t.Fatalf("unexpected: %v %+v", err, got)
+	}
+}
+
+// extend mockRepo to allow history injection
+
+func TestService_Register_Success(t *testing.T) {
+	s := NewService(&mockRepo{createUserFn: func(user *domain.User) error {
+		user.ID = 99
+		return nil
+	}})
+	u, err := s.Register(context.Background(), domain.ReqRegister{FullName: "John Doe", Phone: "+992 900 12 34 56", Email: "a@b.c", Password: "password123"}, domain.RoleUser)
+	if err != nil {
+		t.Fatalf("unexpected: %v", err)
+	}
+	if u.ID == 0 {
+		t.Fatalf("expected non-zero user id")
+	}
+}
+
+func TestService_Login_SuccessAndInvalid(t *testing.T) {
+	// create hashed password
+	pw := "password123"
+	hashed, _ := bcrypt.GenerateFromPassword([]byte(pw), bcrypt.DefaultCost)
+	s := NewService(&mockRepo{getUserByEmailFn: func(email string) (*domain.User, error) {
+		return &domain.User{ID: 5, Email: email, Password: string(hashed), Role: domain.RoleUser}, nil
+	}})
+	// success
+	tok, err := s.Login(context.Background(), domain.ReqLogin{Email: "a@b.c", Password: pw})
+	if err != nil || tok.AccessToken == "" {
+		t.Fatalf("unexpected: %v tok=%+v", err, tok)
+	}
+	// invalid password
+	if _, err := s.Login(context.Background(), domain.ReqLogin{Email: "a@b.c", Password: "wrong"}); err == nil {
+		t.Fatalf("expected error on bad password")
+	}
+}
+
+type stubNotifier struct {
+	sent []domain.Notification
+	err  error
+}
+
+func (n *stubNotifier) Send(msg domain.Notification) error {
+	n.sent = append(n.sent, msg)
+	return n.err
+}
+
+func TestService_Login_NewDeviceNotifiesAndRequiresStepUp(t *testing.T) {
+	pw := "password123"
+	hashed, _ := bcrypt.GenerateFromPassword([]byte(pw), bcrypt.DefaultCost)
+	var created domain.Session
+	var codeHash string
+	notifier := &stubNotifier{}
+	s := NewService(&mockRepo{
+		getUserByEmailFn: func(email string) (*domain.User, error) {
+			return &domain.User{ID: 5, Email: email, Phone: "+992900123456", Password: string(hashed), Role: domain.RoleUser}, nil
+		},
+		getUserByIDFn: func(userID int) (*domain.User, error) {
+			return &domain.User{ID: userID, Email: "a@b.c"}, nil
+		},
+		getUserDeviceIDsFn: func(userID int) ([]string, error) {
+			return []string{deviceKey("laptop-token")}, nil
+		},
+		createSessionFn: func(session *domain.Session, tokenHash string) error {
+			created = *session
+			return nil
+		},
+		setSessionStepUpCodeFn: func(sessionID string, hash string, expiresAt time.Time) error {
+			codeHash = hash
+			return nil
+		},
+	})
+	s.SetNotifier(notifier)
+	cfg := config.DefaultService()
+	cfg.NewDeviceStepUp = true
+	s.SetConfig(cfg)
+
+	// Без токена устройства User-Agent не помогает: выдается новый токен, устройство новое
+	resp, err := s.Login(context.Background(), domain.ReqLogin{Email: "a@b.c", Password: pw, UserAgent: "curl/8", IP: "10.0.0.1"})
+	if err != nil {
+		t.Fatalf("unexpected: %v", err)
+	}
+	if resp.DeviceToken == "" || created.DeviceID != deviceKey(resp.DeviceToken) {
+		t.Fatalf("expected server-issued device token, got %q for %q", resp.DeviceToken, created.DeviceID)
+	}
+	if !created.StepUpRequired || created.IP != "10.0.0.1" || codeHash == "" {
+		t.Fatalf("unexpected session: %+v", created)
+	}
+	// Уведомление о входе и код подтверждения по SMS
+	if len(notifier.sent) != 2 || notifier.sent[1].Channel != domain.ChannelSMS || notifier.sent[1].To != "+992900123456" {
+		t.Fatalf("expected sign-in notice and SMS code, got %+v", notifier.sent)
+	}
+
+	// известное устройство - без уведомления и подтверждения
+	notifier.sent = nil
+	resp, err = s.Login(context.Background(), domain.ReqLogin{Email: "a@b.c", Password: pw, DeviceID: "laptop-token"})
+	if err != nil {
+		t.Fatalf("unexpected: %v", err)
+	}
+	if created.StepUpRequired || len(notifier.sent) != 0 || resp.DeviceToken != "laptop-token" {
+		t.Fatalf("known device should not trigger checks: %+v %+v", created, notifier.sent)
+	}
+}
+
+func TestService_Transfer_BlockedUntilStepUp(t *testing.T) {
+	s := NewService(&mockRepo{
+		getSessionFn: func(sessionID string) (domain.Session, error) {
+			return domain.Session{ID: sessionID, UserID: 5, StepUpRequired: true, ExpiresAt: time.Now().Add(time.Hour)}, nil
+		},
+		getAccountByCardNumberFn: func(acc *domain.Account, card string, currency string) error {
+			*acc = domain.Account{ID: 1, UserID: 5, Balance: "100.00", Currency: currency}
+			return nil
+		},
+	})
+	_, err := s.Transfer(context.Background(), 5, domain.ReqTransfer{FromCardNumber: "4000", ToCardNumber: "5000", Amount: 10, Currency: "TJS", SessionID: "sid-1"})
+	if !errors.Is(err, errs.ErrStepUpRequired) {
+		t.Fatalf("expected ErrStepUpRequired, got %v", err)
+	}
+}
+
+func TestService_CompleteStepUp_ChecksCode(t *testing.T) {
+	session := domain.Session{
+		ID: "sid-1", UserID: 5, StepUpRequired: true, ExpiresAt: time.Now().Add(time.Hour),
+		StepUpCodeHash: hashToken("123456"), StepUpCodeExpiresAt: time.Now().Add(5 * time.Minute),
+	}
+	cleared := ""
+	var attempts []domain.LoginAttempt
+	repo := &mockRepo{
+		getUserByIDFn: func(userID int) (*domain.User, error) {
+			return &domain.User{ID: userID, Email: "A@b.c"}, nil
+		},
+		getSessionFn: func(sessionID string) (domain.Session, error) {
+			return session, nil
+		},
+		recordLoginAttemptFn: func(attempt domain.LoginAttempt) error {
+			attempts = append(attempts, attempt)
+			return nil
+		},
+		completeSessionStepUpFn: func(sessionID string) error {
+			cleared = sessionID
+			return nil
+		},
+	}
+	s := NewService(repo)
+
+	// Пароль больше не подходит, неверный код учитывается защитой входа
+	if err := s.CompleteStepUp(context.Background(), 5, "sid-1", "password123", "10.0.0.1"); !errors.Is(err, errs.ErrInvalidOTP) || cleared != "" {
+		t.Fatalf("expected ErrInvalidOTP, got %v", err)
+	}
+	if len(attempts) != 1 || attempts[0].Email != "a@b.c" || attempts[0].IP != "10.0.0.1" || attempts[0].Success {
+		t.Fatalf("expected failed attempt to be recorded, got %+v", attempts)
+	}
+	if err := s.CompleteStepUp(context.Background(), 6, "sid-1", "123456", ""); !errors.Is(err, errs.ErrAccessDenied) || cleared != "" {
+		t.Fatalf("expected ErrAccessDenied for another user's session, got %v", err)
+	}
+	if err := s.CompleteStepUp(context.Background(), 5, "sid-1", "123456", "10.0.0.1"); err != nil || cleared != "sid-1" {
+		t.Fatalf("unexpected: %v cleared=%q", err, cleared)
+	}
+
+	session.StepUpCodeExpiresAt = time.Now().Add(-time.Second)
+	if err := s.CompleteStepUp(context.Background(), 5, "sid-1", "123456", ""); !errors.Is(err, errs.ErrOTPExpired) {
+		t.Fatalf("expected ErrOTPExpired, got %v", err)
+	}
+}
+
+func TestService_CompleteStepUp_ThrottledLogin(t *testing.T) {
+	checked := false
+	s := NewService(&mockRepo{
+		getUserByIDFn: func(userID int) (*domain.User, error) {
+			return &domain.User{ID: userID, Email: "a@b.c"}, nil
+		},
+		getLoginLockFn: func(email string) (domain.LoginLock, error) {
+			return domain.LoginLock{Email: email, LockedUntil: time.Now().Add(time.Minute)}, nil
+		},
+		getSessionFn: func(sessionID string) (domain.Session, error) {
+			checked = true
+			return domain.Session{}, nil
+		},
+	})
+	if err := s.CompleteStepUp(context.Background(), 5, "sid-1", "123456", ""); !errors.Is(err, errs.ErrAccountLocked) || checked {
+		t.Fatalf("expected ErrAccountLocked before the code is checked, got %v", err)
+	}
+}
+
+func TestService_SendStepUpCode_Throttled(t *testing.T) {
+	session := domain.Session{ID: "sid-1", UserID: 5, StepUpRequired: true, StepUpCodeExpiresAt: time.Now().Add(9*time.Minute + 30*time.Second)}
+	sent := false
+	s := NewService(&mockRepo{
+		getSessionFn: func(sessionID string) (domain.Session, error) {
+			return session, nil
+		},
+		setSessionStepUpCodeFn: func(sessionID string, hash string, expiresAt time.Time) error {
+			sent = true
+			return nil
+		},
+	})
+	if err := s.SendStepUpCode(context.Background(), 5, "sid-1"); !errors.Is(err, errs.ErrTooManyAttempts) || sent {
+		t.Fatalf("expected ErrTooManyAttempts right after the previous code, got %v", err)
+	}
+	session.StepUpCodeExpiresAt = time.Now().Add(2 * time.Minute)
+	if err := s.SendStepUpCode(context.Background(), 5, "sid-1"); err != nil || !sent {
+		t.Fatalf("unexpected: %v sent=%v", err, sent)
+	}
+}
+
+func TestService_Login_LockedEmailRejectedBeforePassword(t *testing.T) {
+	s := NewService(&mockRepo{
+		getLoginLockFn: func(email string) (domain.LoginLock, error) {
+			if email != "a@b.c" {
+				t.Fatalf("email not normalized: %q", email)
+			}
+			return domain.LoginLock{Email: email, LockedUntil: time.Now().Add(time.Minute)}, nil
+		},
+		getUserByEmailFn: func(email string) (*domain.User, error) {
+			t.Fatalf("password must not be checked while locked")
+			return nil, nil
+		},
+	})
+	if _, err := s.Login(context.Background(), domain.ReqLogin{Email: " A@B.c", Password: "x"}); !errors.Is(err, errs.ErrAccountLocked) {
+		t.Fatalf("expected ErrAccountLocked, got %v", err)
+	}
+}
+
+func TestService_Login_LocksAfterMaxFailures(t *testing.T) {
+	// Redis в тестах не поднят - счетчики берутся из журнала попыток
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	var locked domain.LoginLock
+	var audit domain.AdminAuditLog
+	s := NewService(&mockRepo{
+		getUserByEmailFn: func(email string) (*domain.User, error) {
+			return &domain.User{ID: 5, Email: email, Password: string(hashed)}, nil
+		},
+		getLoginFailureStatsFn: func(email, ip string, since time.Time) (domain.LoginFailureStats, error) {
+			return domain.LoginFailureStats{EmailFailures: 5, LastFailureAt: time.Now().Add(-time.Hour)}, nil
+		},
+		lockLoginFn: func(lock domain.LoginLock, reqLogs domain.AdminAuditLog) error {
+			locked, audit = lock, reqLogs
+			return nil
+		},
+	})
+	_, err := s.Login(context.Background(), domain.ReqLogin{Email: "a@b.c", Password: "wrong", IP: "10.0.0.1"})
+	if !errors.Is(err, errs.ErrAccountLocked) {
+		t.Fatalf("expected ErrAccountLocked, got %v", err)
+	}
+	if locked.UserID != 5 || !locked.Active() || audit.Action != "login_locked" {
+		t.Fatalf("unexpected lock: %+v %+v", locked, audit)
+	}
+}
+
+func TestService_Login_ProgressiveDelay(t *testing.T) {
+	s := NewService(&mockRepo{getLoginFailureStatsFn: func(email, ip string, since time.Time) (domain.LoginFailureStats, error) {
+		return domain.LoginFailureStats{EmailFailures: 4, LastFailureAt: time.Now()}, nil
+	}})
+	if _, err := s.Login(context.Background(), domain.ReqLogin{Email: "a@b.c", Password: "x"}); !errors.Is(err, errs.ErrTooManyAttempts) {
+		t.Fatalf("expected ErrTooManyAttempts, got %v", err)
+	}
+	if s.loginBackoff(2) != 0 || s.loginBackoff(3) != time.Second || s.loginBackoff(5) != 4*time.Second {
+		t.Fatalf("unexpected backoff: %v %v %v", s.loginBackoff(2), s.loginBackoff(3), s.loginBackoff(5))
+	}
+}
+
+func TestService_UnlockLogin_Audited(t *testing.T) {
+	var audit domain.AdminAuditLog
+	s := NewService(&mockRepo{
+		getUserByIDFn: func(userID int) (*domain.User, error) {
+			return &domain.User{ID: userID, Email: "A@b.c"}, nil
+		},
+		unlockLoginFn: func(email string, reqLogs domain.AdminAuditLog) error {
+			if email != "a@b.c" {
+				t.Fatalf("unexpected email %q", email)
+			}
+			audit = reqLogs
+			return nil
+		},
+	})
+	if err := s.UnlockLogin(context.Background(), 5, 1, ""); !errors.Is(err, errs.ErrInvalidData) {
+		t.Fatalf("expected ErrInvalidData, got %v", err)
+	}
+	if err := s.UnlockLogin(context.Background(), 5, 1, "verified by phone"); err != nil {
+		t.Fatalf("unexpected: %v", err)
+	}
+	if audit.Action != "login_unlocked" || audit.AdminID != 1 {
+		t.Fatalf("unexpected audit: %+v", audit)
+	}
+}
+
+func TestService_Login_MFAChallengeAndVerify(t *testing.T) {
+	pw := "password123"
+	hashed, _ := bcrypt.GenerateFromPassword([]byte(pw), bcrypt.DefaultCost)
+	secret, _ := totp.GenerateSecret()
+	var created domain.Session
+	var usedStep int64
+	s := NewService(&mockRepo{
+		getUserByEmailFn: func(email string) (*domain.User, error) {
+			return &domain.User{ID: 5, Email: email, Password: string(hashed), Role: domain.RoleAdmin}, nil
+		},
+		getUserByIDFn: func(userID int) (*domain.User, error) {
+			return &domain.User{ID: userID, Email: "a@b.c", Role: domain.RoleAdmin}, nil
+		},
+		getMFAConfigFn: func(userID int) (domain.MFAConfig, error) {
+			return domain.MFAConfig{UserID: userID, Secret: secret, Enabled: true}, nil
+		},
+		useMFAStepFn: func(userID int, step int64) error {
+			usedStep = step
+			return nil
+		},
+		createSessionFn: func(session *domain.Session, tokenHash string) error {
+			created = *session
+			return nil
+		},
+	})
+
+	resp, err := s.Login(context.Background(), domain.ReqLogin{Email: "a@b.c", Password: pw})
+	if err != nil || !resp.MFARequired || resp.MFAToken == "" || resp.AccessToken != "" {
+		t.Fatalf("expected MFA challenge, got %v %+v", err, resp)
+	}
+	// challenge токен не годится как access токен
+	if _, err := s.ParseToken(context.Background(), resp.MFAToken); err == nil {
+		t.Fatalf("challenge token must not be accepted as access token")
+	}
+
+	if _, err := s.VerifyMFA(context.Background(), domain.ReqMFAVerify{MFAToken: resp.MFAToken, Code: "wrong-code"}); !errors.Is(err, errs.ErrInvalidMFACode) {
+		t.Fatalf("expected ErrInvalidMFACode, got %v", err)
+	}
+
+	code, _ := totp.CodeAt(secret, totp.Step(time.Now()))
+	tok, err := s.VerifyMFA(context.Background(), domain.ReqMFAVerify{MFAToken: resp.MFAToken, Code: code})
+	if err != nil || tok.AccessToken == "" || !created.MFAVerified || usedStep == 0 {
+		t.Fatalf("unexpected: %v %+v session=%+v", err, tok, created)
+	}
+}
+
+func TestService_ConfirmMFA_IssuesUsableRecoveryCodes(t *testing.T) {
+	secret, _ := totp.GenerateSecret()
+	var stored []string
+	s := NewService(&mockRepo{
+		getMFAConfigFn: func(userID int) (domain.MFAConfig, error) {
+			return domain.MFAConfig{UserID: userID, Secret: secret, Enabled: stored != nil}, nil
+		},
+		enableMFAFn: func(userID int, step int64, recoveryCodeHashes []string) error {
+			stored = recoveryCodeHashes
+			return nil
+		},
+		useRecoveryCodeFn: func(userID int, codeHash string) error {
+			if slices.Contains(stored, codeHash) {
+				return nil
+			}
+			return errs.ErrInvalidMFACode
+		},
+	})
+
+	if _, err := s.ConfirmMFA(context.Background(), 5, "sid-1", "12345"); !errors.Is(err, errs.ErrInvalidMFACode) {
+		t.Fatalf("expected ErrInvalidMFACode, got %v", err)
+	}
+	code, _ := totp.CodeAt(secret, totp.Step(time.Now()))
+	codes, err := s.ConfirmMFA(context.Background(), 5, "sid-1", code)
+	if err != nil || len(codes) != recoveryCodeCount || len(stored) != recoveryCodeCount {
+		t.Fatalf("unexpected: %v %v", err, codes)
+	}
+	if err := s.checkMFACode(context.Background(), 5, strings.ToUpper(codes[3])); err != nil {
+		t.Fatalf("recovery code rejected: %v", err)
+	}
+}
+
+func TestService_DisableMFA_AdminForbidden(t *testing.T) {
+	s := NewService(&mockRepo{getUserByIDFn: func(userID int) (*domain.User, error) {
+		return &domain.User{ID: userID, Role: domain.RoleAdmin}, nil
+	}})
+	if err := s.DisableMFA(context.Background(), 1, "123456"); !errors.Is(err, errs.ErrMFARequired) {
+		t.Fatalf("expected ErrMFARequired, got %v", err)
+	}
+}
+
+func TestService_Tokens_ParseAndRefresh(t *testing.T) {
+	var refreshHash string
+	s := NewService(&mockRepo{
+		getRefreshTokenFn: func(tokenHash string) (domain.RefreshToken, error) {
+			if tokenHash != refreshHash {
+				return domain.RefreshToken{}, errs.ErrInvalidRefreshToken
+			}
+			return domain.RefreshToken{ID: 1, SessionID: "sid-1", TokenHash: tokenHash, ExpiresAt: time.Now().Add(time.Hour)}, nil
+		},
+		getUserByIDFn: func(userID int) (*domain.User, error) {
+			return &domain.User{ID: userID, Role: domain.RoleAdmin}, nil
+		},
+		rotateRefreshTokenFn: func(oldTokenID int, sessionID string, newTokenHash string, expiresAt time.Time) error {
+			if oldTokenID != 1 || sessionID != "sid-1" || newTokenHash == refreshHash {
+				t.Fatalf("bad rotation args: %d %s", oldTokenID, sessionID)
+			}
+			return nil
+		},
+	})
+	// create access token and parse
+	at, err := s.createAccessToken(7, domain.RoleAdmin, "sid-1", true)
+	if err != nil {
+		t.Fatalf("createAccessToken: %v", err)
+	}
+	user, err := s.ParseToken(context.Background(), at)
+	if err != nil || user.ID != 7 || user.Role != domain.RoleAdmin || user.SessionID != "sid-1" || !user.MFAVerified {
+		t.Fatalf("parse fail: %v user=%+v", err, user)
+	}
+	// create refresh and refresh
+	rt, err := s.generateRefreshToken()
+	if err != nil {
+		t.Fatalf("generateRefreshToken: %v", err)
+	}
+	refreshHash = hashToken(rt)
+	tr, err := s.RefreshToken(context.Background(), domain.ReqRefreshToken{RefreshToken: rt})
+	if err != nil || tr.AccessToken == "" || tr.RefreshToken == rt {
+		t.Fatalf("refresh failed: %v %+v", err, tr)
+	}
+}
+
+func TestService_RefreshToken_ReuseRevokesSession(t *testing.T) {
+	revoked := ""
+	s := NewService(&mockRepo{
+		getRefreshTokenFn: func(tokenHash string) (domain.RefreshToken, error) {
+			return domain.RefreshToken{ID: 1, SessionID: "sid-1", ExpiresAt: time.Now().Add(time.Hour), UsedAt: time.Now().Add(-time.Minute)}, nil
+		},
+		rotateRefreshTokenFn: func(oldTokenID int, sessionID string, newTokenHash string, expiresAt time.Time) error {
+			t.Fatalf("used token must not be rotated")
+			return nil
+		},
+		revokeSessionFn: func(sessionID string, reason string) error {
+			revoked = sessionID
+			return nil
+		},
+	})
+
+	_, err := s.RefreshToken(context.Background(), domain.ReqRefreshToken{RefreshToken: "stolen"})
+	if !errors.Is(err, errs.ErrRefreshTokenRevoked) || revoked != "sid-1" {
+		t.Fatalf("expected revoked session, got %v revoked=%q", err, revoked)
+	}
+}
+
+func TestService_ParseToken_RevokedSession(t *testing.T) {
+	s := NewService(&mockRepo{getSessionFn: func(sessionID string) (domain.Session, error) {
+		return domain.Session{ID: sessionID, UserID: 7, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: time.Now()}, nil
+	}})
+	at, _ := s.createAccessToken(7, domain.RoleUser, "sid-2", false)
+	if _, err := s.ParseToken(context.Background(), at); !errors.Is(err, errs.ErrSessionExpired) {
+		t.Fatalf("expected ErrSessionExpired, got %v", err)
+	}
+}
+
+func TestService_ParseToken_StandardClaims(t *testing.T) {
+	s := NewService(&mockRepo{})
+	at, err := s.createAccessToken(7, domain.RoleUser, "sid-1", false)
+	if err != nil {
+		t.Fatalf("createAccessToken: %v", err)
+	}
+
+	token, _, err := jwt.NewParser().ParseUnverified(at, jwt.MapClaims{})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	claims := token.Claims.(jwt.MapClaims)
+	if token.Header["kid"] != s.keys.SigningKeyID() || token.Method.Alg() != "EdDSA" {
+		t.Fatalf("unexpected header: %v", token.Header)
+	}
+	for _, name := range []string{"iss", "aud", "sub", "iat", "exp", "jti"} {
+		if _, ok := claims[name]; !ok {
+			t.Fatalf("missing %s claim in %v", name, claims)
+		}
+	}
+
+	// Токен другого кольца ключей (чужой сервис, старый удаленный ключ) не принимается
+	other := NewService(&mockRepo{})
+	if _, err := other.ParseToken(context.Background(), at); !errors.Is(err, errs.ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken for foreign key, got %v", err)
+	}
+
+	// Токен для другой аудитории не принимается
+	cfg := config.DefaultService()
+	cfg.JWTAudience = "reporting"
+	s.SetConfig(cfg)
+	if _, err := s.ParseToken(context.Background(), at); !errors.Is(err, errs.ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken for wrong audience, got %v", err)
+	}
+}
+
+func TestService_Deposit_And_Withdraw_Success(t *testing.T) {
+	s := NewService(&mockRepo{
+		getAccountByCardNumberFn: func(acc *domain.Account, card string, currency string) error {
+			*acc = domain.Account{ID: 1, UserID: 5, Balance: "100.00", Currency: currency}
+			return nil
+		},
+		depositToAccountFn: func(accountID int, amount float64) error {
+			if accountID != 1 || amount != 10 {
+				t.Fatalf("bad deposit args")
+			}
+			return nil
+		},
+		resetDailyLimitFn: func(userID int) error { return nil },
+		withdrawFromAccountFn: func(accountID int, amount float64, currency string) error {
+			if accountID != 1 || amount <= 0 {
+				t.Fatalf("bad withdraw args")
+			}
+			return nil
+		},
+	})
+	// deposit
+	if err := s.Deposit(context.Background(), 5, domain.ReqTransaction{CardNumber: "4000", Amount: 10, Currency: "TJS"}); err != nil {
+		t.Fatalf("deposit err: %v", err)
+	}
+	// withdraw
+	if err := s.Withdraw(context.Background(), 5, domain.ReqTransaction{CardNumber: "4000", Amount: 10, Currency: "TJS"}); err != nil {
+		t.Fatalf("withdraw err: %v", err)
+	}
+}
+
+func TestService_CreateCardForAccount_Success(t *testing.T) {
+	called := false
+	s := NewService(&mockRepo{createCardFn: func(card *domain.Card) error {
+		called = true
+		card.ID = 77
+		return nil
+	}})
+	card, err := s.CreateCardForAccount(context.Background(), 10, "John Doe")
+	if err != nil || card == nil || card.ID != 77 {
+		t.Fatalf("unexpected: %v %+v", err, card)
+	}
+	if !called {
+		t.Fatalf("expected repo.CreateCard called")
+	}
+}
+
+func TestService_Withdraw_InsufficientIncludingFee(t *testing.T) {
+	s := NewService(&mockRepo{
+		getAccountByPhoneNumberFn: func(acc *domain.Account, phone string, currency string) error {
+			*acc = domain.Account{ID: 1, UserID: 5, Balance: "10.00", Currency: currency}
+			return nil
+		},
+		getDailyLimitByUserIDFn: func(userID int) (domain.Limit, error) {
+			return domain.Limit{UserID: userID, DailyAmount: 5.0, LastReset: time.Now()}, nil
+		},
+		getTodayUsageInTJSFn: func(userID int) (float64, error) { return 5.0, nil },
+	})
+	// amount 10 > balance 10 after fee > 0, expect error
+	err := s.Withdraw(context.Background(), 5, domain.ReqTransaction{PhoneNumber: "900123456", Amount: 10, Currency: "TJS"})
+	if err == nil || err.Error() != "insufficient funds including overlimit fee" {
+		t.Fatalf("expected overlimit insufficient, got %v", err)
+	}
+}
+
+func TestService_Transfer_Success(t *testing.T) {
+	called := false
+	s := NewService(&mockRepo{
+		getAccountByCardNumberFn: func(acc *domain.Account, card string, currency string) error {
+			if card == "4000" {
+				*acc = domain.Account{ID: 1, UserID: 5, Balance: "100.00", Currency: currency}
+			}
+			if card == "5000" {
+				*acc = domain.Account{ID: 2, UserID: 6, Balance: "20.00", Currency: currency}
+			}
+			return nil
+		},
+		transferFundsFn: func(fromID, toID int, amount float64) error {
+			called = true
+			if fromID != 1 || toID != 2 || amount <= 10 {
+				t.Fatalf("bad transfer args")
+			}
+			return nil
+		},
+	})
+	res, err := s.Transfer(context.Background(), 5, domain.ReqTransfer{FromCardNumber: "4000", ToCardNumber: "5000", Amount: 10, Currency: "TJS"})
+	if err != nil || res.Status != domain.TransferCompleted {
+		t.Fatalf("transfer err: %v %+v", err, res)
+	}
+	if !called {
+		t.Fatalf("expected transfer called")
+	}
+}
+
+func TestService_Transfer_ForeignSenderAccountDenied(t *testing.T) {
+	signatory := false
+	s := NewService(&mockRepo{
+		getAccountByCardNumberFn: func(acc *domain.Account, card string, currency string) error {
+			if card == "4000" {
+				*acc = domain.Account{ID: 1, UserID: 6, Balance: "50000.00", HoldAmount: "0.00", Currency: currency}
+			} else {
+				*acc = domain.Account{ID: 2, UserID: 5, Balance: "0.00", Currency: currency}
+			}
+			return nil
+		},
+		isAccountSignatoryFn: func(accountID, userID int) (bool, error) {
+			return signatory && accountID == 1 && userID == 5, nil
+		},
+		getDailyLimitByUserIDFn: func(userID int) (domain.Limit, error) {
+			return domain.Limit{DailyAmount: 100000}, nil
+		},
+		createPendingTransferFn: func(pt *domain.PendingTransfer) error {
+			if !signatory {
+				t.Fatalf("hold must not be placed on someone else's account")
+			}
+			pt.ID = 42
+			return nil
+		},
+		transferFundsFn: func(fromID, toID int, amount float64) error {
+			t.Fatalf("someone else's account must not be debited")
+			return nil
+		},
+	})
+
+	// карта чужого счета в from - ни холда, ни кода подтверждения
+	req := domain.ReqTransfer{FromCardNumber: "4000", ToCardNumber: "5000", Amount: 20000, Currency: "TJS", OTPConfirmed: true}
+	if _, err := s.Transfer(context.Background(), 5, req); !errors.Is(err, errs.ErrAccessDenied) {
+		t.Fatalf("expected ErrAccessDenied, got %v", err)
+	}
+	req.OTPConfirmed = false
+	if _, err := s.Transfer(context.Background(), 5, req); !errors.Is(err, errs.ErrAccessDenied) {
+		t.Fatalf("expected ErrAccessDenied, got %v", err)
+	}
+
+	// подписант бизнес-счета переводить может
+	signatory = true
+	req.OTPConfirmed = true
+	res, err := s.Transfer(context.Background(), 5, req)
+	if err != nil || res.Status != domain.TransferPendingApproval {
+		t.Fatalf("signatory transfer: %v %+v", err, res)
+	}
+}
+
+type stubScreener struct {
+	matches map[string][]domain.ScreeningMatch
+}
+
+func (s *stubScreener) Screen(name string) []domain.ScreeningMatch {
+	return s.matches[name]
+}
+
+func TestService_Register_BlockedByScreening(t *testing.T) {
+	created := false
+	var review domain.ScreeningReview
+	s := NewService(&mockRepo{
+		createUserFn: func(user *domain.User) error {
+			created = true
+			return nil
+		},
+		createScreeningReviewFn: func(r *domain.ScreeningReview) error {
+			review = *r
+			return nil
+		},
+	})
+	s.SetScreener(&stubScreener{matches: map[string][]domain.ScreeningMatch{
+		"Ivan Petrov": {{Entry: domain.WatchlistEntry{ID: "7", Source: "OFAC"}, MatchedName: "Ivan Petroff", Score: 0.95}},
+	}})
+
+	_, err := s.Register(context.Background(), domain.ReqRegister{FullName: "Ivan Petrov", Phone: "900123457", Email: "ivan@b.c", Password: "password123"}, domain.RoleUser)
+	if !errors.Is(err, errs.ErrScreeningPending) {
+		t.Fatalf("expected ErrScreeningPending, got %v", err)
+	}
+	if created {
+		t.Fatalf("user must not be created while review is pending")
+	}
+	if review.SubjectType != domain.ScreeningCustomer || review.SubjectRef != "ivan@b.c" || review.ListEntryID != "7" {
+		t.Fatalf("unexpected review: %+v", review)
+	}
+}
+
+func TestService_Register_ClearedReviewAllows(t *testing.T) {
+	s := NewService(&mockRepo{
+		createUserFn: func(user *domain.User) error {
+			user.ID = 10
+			return nil
+		},
+		getLatestScreeningFn: func(subjectType domain.ScreeningSubjectType, subjectRef string) (domain.ScreeningReview, error) {
+			return domain.ScreeningReview{SubjectName: "Ivan Petrov", Status: domain.ScreeningCleared}, nil
+		},
+		createScreeningReviewFn: func(r *domain.ScreeningReview) error {
+			t.Fatalf("review must not be created again")
+			return nil
+		},
+	})
+	s.SetScreener(&stubScreener{matches: map[string][]domain.ScreeningMatch{
+		"Ivan Petrov": {{MatchedName: "Ivan Petroff", Score: 0.95}},
+	}})
+
+	u, err := s.Register(context.Background(), domain.ReqRegister{FullName: "Ivan Petrov", Phone: "900123457", Email: "ivan@b.c", Password: "password123"}, domain.RoleUser)
+	if err != nil || u.ID != 10 {
+		t.Fatalf("unexpected: %v %+v", err, u)
+	}
+}
+
+func TestService_Transfer_RecipientConfirmedMatch(t *testing.T) {
+	s := NewService(&mockRepo{
+		getAccountByCardNumberFn: func(acc *domain.Account, card string, currency string) error {
+			if card == "4000" {
+				*acc = domain.Account{ID: 1, UserID: 5, Balance: "100.00", Currency: currency}
+			} else {
+				*acc = domain.Account{ID: 2, UserID: 6, Balance: "0.00", Currency: currency}
+			}
+			return nil
+		},
+		getUserByIDFn: func(userID int) (*domain.User, error) {
+			return &domain.User{ID: userID, FullName: "Hassan Ali"}, nil
+		},
+		getLatestScreeningFn: func(subjectType domain.ScreeningSubjectType, subjectRef string) (domain.ScreeningReview, error) {
+			if subjectType != domain.ScreeningTransferRecipient || subjectRef != "account:2" {
+				t.Fatalf("unexpected subject %s %s", subjectType, subjectRef)
+			}
+			return domain.ScreeningReview{SubjectName: "Hassan Ali", Status: domain.ScreeningConfirmed}, nil
+		},
+		transferFundsFn: func(fromID, toID int, amount float64) error {
+			t.Fatalf("transfer must be blocked")
+			return nil
+		},
+	})
+	s.SetScreener(&stubScreener{matches: map[string][]domain.ScreeningMatch{
+		"Hassan Ali": {{MatchedName: "Hassan ALI", Score: 1}},
+	}})
+
+	_, err := s.Transfer(context.Background(), 5, domain.ReqTransfer{FromCardNumber: "4000", ToCardNumber: "5000", Amount: 10, Currency: "TJS"})
+	if !errors.Is(err, errs.ErrSanctionsMatch) {
+		t.Fatalf("expected ErrSanctionsMatch, got %v", err)
+	}
+}
+
+func TestService_Transfer_LargeAmountNeedsApproval(t *testing.T) {
+	var pending domain.PendingTransfer
+	s := NewService(&mockRepo{
+		getAccountByCardNumberFn: func(acc *domain.Account, card string, currency string) error {
+			if card == "4000" {
+				*acc = domain.Account{ID: 1, UserID: 5, Balance: "50000.00", HoldAmount: "0.00", Currency: currency}
+			} else {
+				*acc = domain.Account{ID: 2, UserID: 6, Balance: "0.00", Currency: currency}
+			}
+			return nil
+		},
+		getDailyLimitByUserIDFn: func(userID int) (domain.Limit, error) {
+			return domain.Limit{DailyAmount: 100000}, nil
+		},
+		createPendingTransferFn: func(pt *domain.PendingTransfer) error {
+			pt.ID = 42
+			pending = *pt
+			return nil
+		},
+		transferFundsFn: func(fromID, toID int, amount float64) error {
+			t.Fatalf("large transfer must not execute immediately")
+			return nil
+		},
+	})
+
+	// код из SMS уже введен - дальше перевод уходит на одобрение
+	res, err := s.Transfer(context.Background(), 5, domain.ReqTransfer{FromCardNumber: "4000", ToCardNumber: "5000", Amount: 20000, Currency: "TJS", OTPConfirmed: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Status != domain.TransferPendingApproval || res.PendingTransferID != 42 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if pending.FromAccountID != 1 || pending.ToAccountID != 2 || pending.InitiatorID != 5 || pending.Amount != 20000 {
+		t.Fatalf("unexpected pending transfer: %+v", pending)
+	}
+	if !pending.ExpiresAt.After(time.Now()) {
+		t.Fatalf("expected expiry in the future, got %v", pending.ExpiresAt)
+	}
+}
+
+func TestService_Transfer_NewRecipientNeedsOTP(t *testing.T) {
+	notifier := &stubNotifier{}
+	var saved domain.TransferConfirmation
+	s := NewService(&mockRepo{
+		getAccountByCardNumberFn: func(acc *domain.Account, card string, currency string) error {
+			if card == "4000" {
+				*acc = domain.Account{ID: 1, UserID: 5, Balance: "100.00", Currency: currency}
+			} else {
+				*acc = domain.Account{ID: 2, UserID: 6, Balance: "0.00", Currency: currency}
+			}
+			return nil
+		},
+		getUserByIDFn: func(userID int) (*domain.User, error) {
+			return &domain.User{ID: userID, Phone: "+992900000000", Email: "a@b.c"}, nil
+		},
+		isKnownRecipientFn: func(userID, accountID int) (bool, error) {
+			return false, nil
+		},
+		createConfirmationFn: func(c *domain.TransferConfirmation) error {
+			c.ID = 9
+			saved = *c
+			return nil
+		},
+		transferFundsFn: func(fromID, toID int, amount float64) error {
+			t.Fatalf("transfer must wait for the code")
+			return nil
+		},
+	})
+	s.SetNotifier(notifier)
+
+	res, err := s.Transfer(context.Background(), 5, domain.ReqTransfer{FromCardNumber: "4000", ToCardNumber: "5000", Amount: 10, Currency: "TJS"})
+	if err != nil || res.Status != domain.TransferPendingConfirmation || res.ConfirmationID != 9 {
+		t.Fatalf("unexpected: %v %+v", err, res)
+	}
+	if len(notifier.sent) != 1 || notifier.sent[0].Channel != domain.ChannelSMS || notifier.sent[0].To != "+992900000000" {
+		t.Fatalf("expected SMS to user's phone, got %+v", notifier.sent)
+	}
+	if saved.Request.ToCardNumber != "5000" || saved.CodeHash == "" || !saved.ExpiresAt.After(time.Now()) {
+		t.Fatalf("unexpected confirmation: %+v", saved)
+	}
+}
+
+func TestService_ConfirmTransfer_CodeAttemptsAndExecution(t *testing.T) {
+	transferred := false
+	closed := domain.TransferConfirmationStatus("")
+	confirmation := domain.TransferConfirmation{
+		ID:        9,
+		UserID:    5,
+		Request:   domain.ReqTransfer{FromCardNumber: "4000", ToCardNumber: "5000", Amount: 10, Currency: "TJS"},
+		CodeHash:  hashToken("123456"),
+		Status:    domain.TransferConfirmationPending,
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+	s := NewService(&mockRepo{
+		getConfirmationFn: func(id int) (domain.TransferConfirmation, error) {
+			return confirmation, nil
+		},
+		recordConfirmAttemptFn: func(id int, maxAttempts int) (int, error) {
+			return maxAttempts, nil
+		},
+		closeConfirmationFn: func(id int, status domain.TransferConfirmationStatus) error {
+			closed = status
+			return nil
+		},
+		getAccountByCardNumberFn: func(acc *domain.Account, card string, currency string) error {
+			if card == "4000" {
+				*acc = domain.Account{ID: 1, UserID: 5, Balance: "100.00", Currency: currency}
+			} else {
+				*acc = domain.Account{ID: 2, UserID: 6, Balance: "0.00", Currency: currency}
+			}
+			return nil
+		},
+		// получатель все еще новый, но код уже введен
+		isKnownRecipientFn: func(userID, accountID int) (bool, error) {
+			return false, nil
+		},
+		transferFundsFn: func(fromID, toID int, amount float64) error {
+			transferred = true
+			return nil
+		},
+	})
+
+	if _, err := s.ConfirmTransfer(context.Background(), 6, 9, "123456", ""); !errors.Is(err, errs.ErrTransferConfirmationNotFound) {
+		t.Fatalf("expected not found for another user, got %v", err)
+	}
+	if _, err := s.ConfirmTransfer(context.Background(), 5, 9, "000000", ""); !errors.Is(err, errs.ErrTooManyAttempts) {
+		t.Fatalf("expected ErrTooManyAttempts on last attempt, got %v", err)
+	}
+
+	res, err := s.ConfirmTransfer(context.Background(), 5, 9, "123456", "")
+	if err != nil || res.Status != domain.TransferCompleted || !transferred || closed != domain.TransferConfirmationConfirmed {
+		t.Fatalf("unexpected: %v %+v transferred=%v closed=%s", err, res, transferred, closed)
+	}
+
+	confirmation.ExpiresAt = time.Now().Add(-time.Second)
+	if _, err := s.ConfirmTransfer(context.Background(), 5, 9, "123456", ""); !errors.Is(err, errs.ErrOTPExpired) || closed != domain.TransferConfirmationExpired {
+		t.Fatalf("expected ErrOTPExpired, got %v", err)
+	}
+}
+
+func TestService_DecidePendingTransfer_Rules(t *testing.T) {
+	pt := domain.PendingTransfer{ID: 3, FromAccountID: 1, ToAccountID: 2, Amount: 20000, InitiatorID: 5,
+		Status: domain.PendingTransferPending, ExpiresAt: time.Now().Add(time.Hour)}
+	approved := false
+	s := NewService(&mockRepo{
+		getPendingTransferByIDFn: func(id int) (domain.PendingTransfer, error) {
+			return pt, nil
+		},
+		approvePendingTransferFn: func(p domain.PendingTransfer, approverID int, note string, reqLogs domain.AdminAuditLog) error {
+			approved = true
+			if approverID != 9 || reqLogs.Action != "transfer_approved" || reqLogs.AccountID != 1 {
+				t.Fatalf("unexpected approval args: %d %+v", approverID, reqLogs)
+			}
+			return nil
+		},
+	})
+
+	// инициатор не может одобрить свой перевод, даже если он админ
+	if err := s.DecidePendingTransfer(context.Background(), 3, domain.User{ID: 5, Role: domain.RoleAdmin}, true, ""); !errors.Is(err, errs.ErrSelfApproval) {
+		t.Fatalf("expected ErrSelfApproval, got %v", err)
+	}
+	// обычный пользователь без подписи на счете
+	if err := s.DecidePendingTransfer(context.Background(), 3, domain.User{ID: 8, Role: domain.RoleUser}, true, ""); !errors.Is(err, errs.ErrAccessDenied) {
+		t.Fatalf("expected ErrAccessDenied, got %v", err)
+	}
+	// отказ без причины
+	if err := s.DecidePendingTransfer(context.Background(), 3, domain.User{ID: 9, Role: domain.RoleAdmin}, false, ""); !errors.Is(err, errs.ErrInvalidData) {
+		t.Fatalf("expected ErrInvalidData, got %v", err)
+	}
+	if err := s.DecidePendingTransfer(context.Background(), 3, domain.User{ID: 9, Role: domain.RoleAdmin}, true, "ok"); err != nil || !approved {
+		t.Fatalf("expected approval, got %v", err)
+	}
+}
+
+func TestService_DecidePendingTransfer_Expired(t *testing.T) {
+	var released domain.PendingTransferStatus
+	s := NewService(&mockRepo{
+		getPendingTransferByIDFn: func(id int) (domain.PendingTransfer, error) {
+			return domain.PendingTransfer{ID: id, FromAccountID: 1, InitiatorID: 5,
+				Status: domain.PendingTransferPending, ExpiresAt: time.Now().Add(-time.Minute)}, nil
+		},
+		releasePendingTransferFn: func(pt domain.PendingTransfer, status domain.PendingTransferStatus, deciderID int, note string, reqLogs domain.AdminAuditLog) error {
+			released = status
+			return nil
+		},
+	})
+
+	err := s.DecidePendingTransfer(context.Background(), 3, domain.User{ID: 9, Role: domain.RoleAdmin}, true, "")
+	if !errors.Is(err, errs.ErrPendingTransferExpired) || released != domain.PendingTransferExpired {
+		t.Fatalf("expected expiry, got %v (%s)", err, released)
+	}
+}
+
+func TestService_ProposeAdminAction_Validation(t *testing.T) {
+	var created domain.PendingAction
+	s := NewService(&mockRepo{
+		getAccountByIDFn: func(accountID int) (domain.Account, error) {
+			return domain.Account{ID: accountID, Blocked: accountID == 10}, nil
+		},
+		activeRestrictionsFn: func(accountID int) (domain.AccountRestrictions, error) {
+			if accountID != 10 {
+				return nil, nil
+			}
+			return domain.AccountRestrictions{{AccountID: accountID, Mode: domain.RestrictionFullBlock}}, nil
+		},
+		getDailyLimitByUserIDFn: func(userID int) (domain.Limit, error) {
+			return domain.Limit{UserID: userID, DailyAmount: 1000}, nil
+		},
+		createPendingActionFn: func(action *domain.PendingAction) error {
+			action.ID = 4
+			created = *action
+			return nil
+		},
+	})
+
+	// счет не заблокирован - разблокировать нечего
+	if _, err := s.ProposeAdminAction(context.Background(), domain.PendingAction{Type: domain.ActionUnblock, AccountID: 11, Reason: "r"}, 1); !errors.Is(err, errs.ErrInvalidOperation) {
+		t.Fatalf("expected ErrInvalidOperation, got %v", err)
+	}
+	// понижение лимита через four-eyes не проводится
+	if _, err := s.ProposeAdminAction(context.Background(), domain.PendingAction{Type: domain.ActionLimitRaise, UserID: 5, Amount: 500, Reason: "r"}, 1); !errors.Is(err, errs.ErrInvalidLimit) {
+		t.Fatalf("expected ErrInvalidLimit, got %v", err)
+	}
+
+	// корректировка без кода причины не попадет в balance_adjustments
+	if _, err := s.ProposeAdminAction(context.Background(), domain.PendingAction{Type: domain.ActionManualAdjustment, AccountID: 10, Amount: 9000, Reason: "r"}, 1); !errors.Is(err, errs.ErrInvalidData) {
+		t.Fatalf("expected ErrInvalidData, got %v", err)
+	}
+
+	action, err := s.ProposeAdminAction(context.Background(), domain.PendingAction{Type: domain.ActionUnblock, AccountID: 10, Reason: "verified"}, 1)
+	if err != nil || action.ID != 4 {
+		t.Fatalf("unexpected: %v %+v", err, action)
+	}
+	if created.ProposedBy != 1 || created.RestrictionMode != domain.RestrictionFullBlock || !created.ExpiresAt.After(time.Now()) {
+		t.Fatalf("unexpected created action: %+v", created)
+	}
+}
+
+func TestService_DecidePendingAction_FourEyes(t *testing.T) {
+	executed := false
+	s := NewService(&mockRepo{
+		getPendingActionByIDFn: func(id int) (domain.PendingAction, error) {
+			return domain.PendingAction{ID: id, Type: domain.ActionRoleChange, UserID: 3, NewRole: domain.RoleAdmin,
+				Reason: "promotion", ProposedBy: 1, Status: domain.PendingActionPending, ExpiresAt: time.Now().Add(time.Hour)}, nil
+		},
+		executePendingActionFn: func(action domain.PendingAction, approverID int, note string, reqLogs domain.AdminAuditLog) error {
+			executed = true
+			if approverID != 2 || reqLogs.Action != "role_change_approved" {
+				t.Fatalf("unexpected execution args: %d %+v", approverID, reqLogs)
+			}
+			return nil
+		},
+	})
+
+	if err := s.DecidePendingAction(context.Background(), 4, 1, true, ""); !errors.Is(err, errs.ErrSelfApproval) {
+		t.Fatalf("expected ErrSelfApproval, got %v", err)
+	}
+	if executed {
+		t.Fatalf("action must not execute on self-approval")
+	}
+	if err := s.DecidePendingAction(context.Background(), 4, 2, true, "ok"); err != nil || !executed {
+		t.Fatalf("expected execution, got %v", err)
+	}
+}
+
+// Одобренная корректировка проводится как AdjustBalance: код причины, автор предложения, сумма в TJS
+func TestService_DecidePendingAction_ManualAdjustmentPostsAdjustment(t *testing.T) {
+	var events []domain.AuditEvent
+	s := NewService(&mockRepo{
+		getPendingActionByIDFn: func(id int) (domain.PendingAction, error) {
+			return domain.PendingAction{ID: id, Type: domain.ActionManualAdjustment, AccountID: 10, Amount: -20,
+				ReasonCode: domain.AdjustmentChargeback, Reason: "card dispute", ProposedBy: 1,
+				Status: domain.PendingActionPending, ExpiresAt: time.Now().Add(time.Hour)}, nil
+		},
+		getAccountByIDFn: func(accountID int) (domain.Account, error) {
+			return domain.Account{ID: accountID, Currency: "USD", Balance: "100.00"}, nil
+		},
+		executePendingActionFn: func(action domain.PendingAction, approverID int, note string, reqLogs domain.AdminAuditLog) error {
+			t.Fatal("manual adjustment must be posted through ExecutePendingAdjustment")
+			return nil
+		},
+		executePendingAdjustmentFn: func(action domain.PendingAction, adj domain.BalanceAdjustment, approverID int, reqLogs domain.AdminAuditLog) (domain.BalanceAdjustment, error) {
+			if adj.AdminID != 1 || adj.Direction != domain.AdjustmentDebit || adj.Amount != 20 || adj.AmountTJS != 184.2 ||
+				adj.ReasonCode != domain.AdjustmentChargeback || adj.Note != "card dispute" || approverID != 2 {
+				t.Fatalf("unexpected adjustment %+v approver %d", adj, approverID)
+			}
+			if reqLogs.Action != "manual_adjustment_approved" || !strings.Contains(reqLogs.Reason, "chargeback amount -20.00") {
+				t.Fatalf("unexpected audit log %+v", reqLogs)
+			}
+			adj.ID, adj.TransactionID, adj.BalanceBefore, adj.BalanceAfter = 3, 77, 100, 80
+			return adj, nil
+		},
+		appendAuditEventFn: func(event domain.AuditEvent) (domain.AuditEvent, error) {
+			events = append(events, event)
+			return event, nil
+		},
+	})
+
+	if err := s.DecidePendingAction(context.Background(), 4, 2, true, "ok"); err != nil {
+		t.Fatalf("unexpected: %v", err)
+	}
+	if len(events) != 1 || !strings.Contains(string(events[0].Before), `"balance":100`) || !strings.Contains(string(events[0].After), `"adjustment_id":3`) {
+		t.Fatalf("unexpected audit events %+v", events)
+	}
+}
+
+func TestRole_PermissionMatrix(t *testing.T) {
+	if !domain.RoleAdmin.Can(domain.PermBankingUse) {
+		t.Fatalf("admin must keep access to /api")
+	}
+	if domain.RoleUser.Can(domain.PermAccountsBlock) || domain.RoleUser.IsStaff() {
+		t.Fatalf("user must not have staff permissions")
+	}
+	if domain.RoleAuditor.Can(domain.PermAccountsBlock) || !domain.RoleAuditor.Can(domain.PermAuditRead) {
+		t.Fatalf("auditor is read-only")
+	}
+	if !domain.RoleSupport.Can(domain.PermUsersUnlock) || domain.RoleSupport.Can(domain.PermAdminActionsDecide) {
+		t.Fatalf("support can unlock logins but not approve actions")
+	}
+	if domain.Role("root").Valid() || domain.Role("root").Can(domain.PermBankingUse) {
+		t.Fatalf("unknown role must have no permissions")
+	}
+}
+
+func TestService_InviteStaff_SendsTokenAndStoresHash(t *testing.T) {
+	var stored domain.StaffInvite
+	var audit domain.AdminAuditLog
+	notifier := &stubNotifier{}
+	s := NewService(&mockRepo{createStaffInviteFn: func(invite *domain.StaffInvite, reqLogs domain.AdminAuditLog) error {
+		invite.ID = 3
+		stored = *invite
+		audit = reqLogs
+		return nil
+	}})
+	s.SetNotifier(notifier)
+
+	if _, err := s.InviteStaff(context.Background(), "ops@bank.tj", domain.RoleUser, 1); !errors.Is(err, errs.ErrInvalidData) {
+		t.Fatalf("expected ErrInvalidData for user role, got %v", err)
+	}
+
+	invite, err := s.InviteStaff(context.Background(), " Ops@Bank.tj ", domain.RoleSupport, 1)
+	if err != nil {
+		t.Fatalf("unexpected: %v", err)
+	}
+	if invite.ID != 3 || stored.Email != "ops@bank.tj" || stored.Role != domain.RoleSupport || audit.Action != "staff_invited" {
+		t.Fatalf("unexpected invite: %+v audit: %+v", stored, audit)
+	}
+	if len(notifier.sent) != 1 || notifier.sent[0].To != "ops@bank.tj" {
+		t.Fatalf("expected invite email, got %+v", notifier.sent)
+	}
+	body := notifier.sent[0].Body
+	token := body[strings.LastIndex(body, " ")+1:]
+	if hashToken(token) != stored.TokenHash {
+		t.Fatalf("stored hash does not match emailed token")
+	}
+}
+
+func TestService_AcceptInvite_UsesInviteRoleAndEmail(t *testing.T) {
+	var created domain.User
+	completed := 0
+	s := NewService(&mockRepo{
+		getStaffInviteFn: func(tokenHash string) (domain.StaffInvite, error) {
+			if tokenHash != hashToken("tok") {
+				return domain.StaffInvite{}, errs.ErrInviteNotFound
+			}
+			return domain.StaffInvite{ID: 4, Email: "aud@bank.tj", Role: domain.RoleAuditor, ExpiresAt: time.Now().Add(time.Hour)}, nil
+		},
+		createUserFn: func(user *domain.User) error {
+			user.ID = 21
+			created = *user
+			return nil
+		},
+		completeStaffInviteFn: func(inviteID int, userID int) error {
+			completed = userID
+			return nil
+		},
+	})
+
+	if _, err := s.AcceptInvite(context.Background(), domain.ReqAcceptInvite{Token: "other"}); !errors.Is(err, errs.ErrInviteNotFound) {
+		t.Fatalf("expected ErrInviteNotFound, got %v", err)
+	}
+
+	user, err := s.AcceptInvite(context.Background(), domain.ReqAcceptInvite{Token: "tok", FullName: "Audit", Phone: "900000001", Password: "password123"})
+	if err != nil {
+		t.Fatalf("unexpected: %v", err)
+	}
+	if user.ID != 21 || created.Role != domain.RoleAuditor || created.Email != "aud@bank.tj" || completed != 21 {
+		t.Fatalf("unexpected user %+v, completed=%d", created, completed)
+	}
+}
+
+func TestService_AcceptInvite_ExpiredOrUsed(t *testing.T) {
+	invite := domain.StaffInvite{ID: 4, Role: domain.RoleTeller, ExpiresAt: time.Now().Add(-time.Minute)}
+	s := NewService(&mockRepo{
+		getStaffInviteFn: func(tokenHash string) (domain.StaffInvite, error) { return invite, nil },
+		claimStaffInviteFn: func(inviteID int) error {
+			t.Fatalf("invite must not be claimed")
+			return nil
+		},
+	})
+
+	if _, err := s.AcceptInvite(context.Background(), domain.ReqAcceptInvite{Token: "tok"}); !errors.Is(err, errs.ErrInviteExpired) {
+		t.Fatalf("expected ErrInviteExpired, got %v", err)
+	}
+	invite.ExpiresAt = time.Now().Add(time.Hour)
+	invite.AcceptedAt = time.Now()
+	if _, err := s.AcceptInvite(context.Background(), domain.ReqAcceptInvite{Token: "tok"}); !errors.Is(err, errs.ErrInviteNotFound) {
+		t.Fatalf("expected ErrInviteNotFound for used invite, got %v", err)
+	}
+}
+
+func TestService_BootstrapAdmin_OnlyWhenNoAdmin(t *testing.T) {
+	admins := 1
+	var created domain.User
+	s := NewService(&mockRepo{
+		countUsersByRoleFn: func(role domain.Role) (int, error) { return admins, nil },
+		createUserFn: func(user *domain.User) error {
+			user.ID = 1
+			created = *user
+			return nil
+		},
+	})
+	req := domain.ReqRegister{FullName: "Admin", Email: "root@bank.tj", Phone: "+992900000000", Password: "password123"}
+
+	if ok, err := s.BootstrapAdmin(context.Background(), req); ok || err != nil {
+		t.Fatalf("expected no-op when admin exists, got %v %v", ok, err)
+	}
+	admins = 0
+	if ok, err := s.BootstrapAdmin(context.Background(), req); !ok || err != nil || created.Role != domain.RoleAdmin {
+		t.Fatalf("expected admin to be created, got %v %v %+v", ok, err, created)
+	}
+}
+
+func TestValidatePasswordStrength(t *testing.T) {
+	s := NewService(&mockRepo{})
+	cases := map[string]bool{
+		"Tr0ub4dor&3":            true,
+		"short1":                 false, // короче 8
+		"onlyletterslong":        false, // нет цифр
+		"1234567890":             false, // нет букв
+		"Password123":            false, // частый пароль
+		"johnsmith2024":          false, // содержит email
+		strings.Repeat("a1", 40): false, // длиннее 72 байт
+	}
+	for password, ok := range cases {
+		err := s.validatePasswordStrength(password, "JohnSmith@example.com")
+		if ok && err != nil || !ok && !errors.Is(err, errs.ErrWeakPassword) {
+			t.Fatalf("%q: got %v, want ok=%v", password, err, ok)
+		}
+	}
+}
+
+func TestService_ForgotPassword_UnknownEmailIsSilent(t *testing.T) {
+	notifier := &stubNotifier{}
+	s := NewService(&mockRepo{
+		getUserByEmailFn: func(email string) (*domain.User, error) { return nil, errs.ErrUserNotFound },
+		createResetTokenFn: func(token *domain.PasswordResetToken) error {
+			t.Fatalf("no token for unknown email")
+			return nil
+		},
+	})
+	s.SetNotifier(notifier)
+
+	if err := s.ForgotPassword(context.Background(), "ghost@example.com"); err != nil || len(notifier.sent) != 0 {
+		t.Fatalf("expected silent success, got %v %+v", err, notifier.sent)
+	}
+}
+
+// Сбой доставки не должен отличать известный email от неизвестного
+func TestService_ForgotPassword_NotifierErrorIsSilent(t *testing.T) {
+	notifier := &stubNotifier{err: errors.New("smtp down")}
+	s := NewService(&mockRepo{
+		getUserByEmailFn:   func(email string) (*domain.User, error) { return &domain.User{ID: 5, Email: email}, nil },
+		createResetTokenFn: func(token *domain.PasswordResetToken) error { return nil },
+	})
+	s.SetNotifier(notifier)
+	s.async = func(task func()) { task() }
+
+	if err := s.ForgotPassword(context.Background(), "a@b.c"); err != nil || len(notifier.sent) != 1 {
+		t.Fatalf("expected silent success after a send attempt, got %v %+v", err, notifier.sent)
+	}
+}
+
+func TestService_ForgotPassword_Throttled(t *testing.T) {
+	lookups := 0
+	s := NewService(&mockRepo{
+		getUserByEmailFn: func(email string) (*domain.User, error) {
+			lookups++
+			return nil, errs.ErrUserNotFound
+		},
+	})
+	cfg := config.DefaultService()
+	cfg.PasswordResetMaxPerEmail = 2
+	cfg.PasswordResetMaxPerIP = 3
+	// лимиты входа не влияют на восстановление пароля
+	cfg.LoginMaxFailedAttempts = 1
+	cfg.LoginIPMaxFailedAttempts = 1
+	s.SetConfig(cfg)
+	scoped := s.WithRequest(domain.RequestMeta{IP: "10.0.0.1"})
+
+	for i := 0; i < 2; i++ {
+		if err := scoped.ForgotPassword(context.Background(), "ghost@example.com"); err != nil {
+			t.Fatalf("request %d: unexpected %v", i+1, err)
+		}
+	}
+	if err := scoped.ForgotPassword(context.Background(), " Ghost@example.com"); !errors.Is(err, errs.ErrTooManyAttempts) {
+		t.Fatalf("expected per-email limit, got %v", err)
+	}
+	// Другой email с того же IP упирается в лимит IP
+	if err := scoped.ForgotPassword(context.Background(), "other@example.com"); !errors.Is(err, errs.ErrTooManyAttempts) {
+		t.Fatalf("expected per-IP limit, got %v", err)
+	}
+	if lookups != 2 {
+		t.Fatalf("throttled requests must not reach the repository, got %d lookups", lookups)
+	}
+}
+
+func TestRateLimiter_BoundedKeys(t *testing.T) {
+	l := newRateLimiter()
+	l.maxKeys = 3
+
+	// окно первого ключа уже закончилось и освобождает место
+	l.incr("reset:email:old@example.com", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	l.incr("reset:email:a@example.com", time.Hour)
+	l.incr("reset:email:b@example.com", time.Hour)
+	l.incr("reset:email:c@example.com", time.Hour)
+	if _, ok := l.windows["reset:email:old@example.com"]; ok || len(l.windows) != 3 {
+		t.Fatalf("expired window must be swept: %v", l.windows)
+	}
+
+	// карта заполнена живыми окнами - новые ключи делят общий счетчик
+	if got := l.incr("reset:email:d@example.com", time.Hour); got != 1 {
+		t.Fatalf("unexpected count %d", got)
+	}
+	if got := l.incr("reset:email:e@example.com", time.Hour); got != 2 {
+		t.Fatalf("new keys must share the overflow counter, got %d", got)
+	}
+	if len(l.windows) > 4 {
+		t.Fatalf("limiter must not grow past its cap: %d keys", len(l.windows))
+	}
+	// существующие ключи считаются как прежде
+	if got := l.incr("reset:email:a@example.com", time.Hour); got != 2 {
+		t.Fatalf("unexpected count for existing key %d", got)
+	}
+}
+
+func TestService_ForgotAndResetPassword(t *testing.T) {
+	var stored domain.PasswordResetToken
+	var newHash string
+	revoked := ""
+	notifier := &stubNotifier{}
+	s := NewService(&mockRepo{
+		getUserByEmailFn: func(email string) (*domain.User, error) {
+			if email != "a@b.c" {
+				t.Fatalf("email must be normalized, got %q", email)
+			}
+			return &domain.User{ID: 5, Email: email}, nil
+		},
+		getUserByIDFn: func(userID int) (*domain.User, error) { return &domain.User{ID: userID, Email: "a@b.c"}, nil },
+		createResetTokenFn: func(token *domain.PasswordResetToken) error {
+			token.ID = 8
+			stored = *token
+			return nil
+		},
+		getResetTokenFn: func(tokenHash string) (domain.PasswordResetToken, error) {
+			if tokenHash != stored.TokenHash {
+				return domain.PasswordResetToken{}, errs.ErrInvalidResetToken
+			}
+			return stored, nil
+		},
+		resetPasswordFn: func(tokenID int, userID int, passwordHash string) error {
+			if tokenID != 8 || userID != 5 {
+				t.Fatalf("unexpected reset args: %d %d", tokenID, userID)
+			}
+			newHash = passwordHash
+			return nil
+		},
+		revokeUserSessionsFn: func(userID int, reason string) ([]string, error) {
+			revoked = reason
+			return []string{"sid-1"}, nil
+		},
+	})
+	s.SetNotifier(notifier)
+	s.async = func(task func()) { task() }
+
+	if err := s.ForgotPassword(context.Background(), " A@B.c "); err != nil || len(notifier.sent) != 1 {
+		t.Fatalf("expected reset email, got %v %+v", err, notifier.sent)
+	}
+	body := notifier.sent[0].Body
+	token := body[strings.LastIndex(body, " ")+1:]
+
+	if err := s.ResetPassword(context.Background(), domain.ReqPasswordReset{Token: "guess", NewPassword: "N3w-secret-pass"}); !errors.Is(err, errs.ErrInvalidResetToken) {
+		t.Fatalf("expected ErrInvalidResetToken, got %v", err)
+	}
+	if err := s.ResetPassword(context.Background(), domain.ReqPasswordReset{Token: token, NewPassword: "weak"}); !errors.Is(err, errs.ErrWeakPassword) {
+		t.Fatalf("expected ErrWeakPassword, got %v", err)
+	}
+	if err := s.ResetPassword(context.Background(), domain.ReqPasswordReset{Token: token, NewPassword: "N3w-secret-pass"}); err != nil {
+		t.Fatalf("unexpected: %v", err)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(newHash), []byte("N3w-secret-pass")) != nil || revoked != "password_reset" {
+		t.Fatalf("password not stored or sessions not revoked: revoked=%q", revoked)
+	}
+
+	stored.UsedAt = time.Now()
+	if err := s.ResetPassword(context.Background(), domain.ReqPasswordReset{Token: token, NewPassword: "An0ther-secret"}); !errors.Is(err, errs.ErrInvalidResetToken) {
+		t.Fatalf("expected used token to be rejected, got %v", err)
+	}
+}
+
+func TestService_ChangePassword(t *testing.T) {
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("Old-pass-123"), bcrypt.MinCost)
+	updated := false
+	revoked := ""
+	s := NewService(&mockRepo{
+		getUserByIDFn: func(userID int) (*domain.User, error) {
+			return &domain.User{ID: userID, Email: "a@b.c", Password: string(hashed)}, nil
+		},
+		updatePasswordFn: func(userID int, passwordHash string) error {
+			updated = true
+			return nil
+		},
+		revokeUserSessionsFn: func(userID int, reason string) ([]string, error) {
+			revoked = reason
+			return nil, nil
+		},
+	})
+
+	if err := s.ChangePassword(context.Background(), 5, domain.ReqPasswordChange{CurrentPassword: "wrong", NewPassword: "N3w-secret-pass"}); !errors.Is(err, errs.ErrInvalidCredentials) {
+		t.Fatalf("expected ErrInvalidCredentials, got %v", err)
+	}
+	if err := s.ChangePassword(context.Background(), 5, domain.ReqPasswordChange{CurrentPassword: "Old-pass-123", NewPassword: "Old-pass-123"}); !errors.Is(err, errs.ErrWeakPassword) {
+		t.Fatalf("expected ErrWeakPassword for reused password, got %v", err)
+	}
+	if updated {
+		t.Fatalf("password must not be updated on failure")
+	}
+	if err := s.ChangePassword(context.Background(), 5, domain.ReqPasswordChange{CurrentPassword: "Old-pass-123", NewPassword: "N3w-secret-pass"}); err != nil {
+		t.Fatalf("unexpected: %v", err)
+	}
+	if !updated || revoked != "password_change" {
+		t.Fatalf("expected update and revocation, got updated=%v revoked=%q", updated, revoked)
+	}
+}
+
+func TestService_Register_NormalizesContacts(t *testing.T) {
+	var created domain.User
+	s := NewService(&mockRepo{createUserFn: func(user *domain.User) error {
+		created = *user
+		return nil
+	}})
+
+	if _, err := s.Register(context.Background(), domain.ReqRegister{FullName: "Ali", Phone: "12", Email: "ali@bank.tj", Password: "password123"}, domain.RoleUser); !errors.Is(err, errs.ErrInvalidPhone) {
+		t.Fatalf("expected ErrInvalidPhone, got %v", err)
+	}
+	if _, err := s.Register(context.Background(), domain.ReqRegister{FullName: "Ali", Phone: "900123456", Email: "ali@", Password: "password123"}, domain.RoleUser); !errors.Is(err, errs.ErrInvalidEmail) {
+		t.Fatalf("expected ErrInvalidEmail, got %v", err)
+	}
+
+	if _, err := s.Register(context.Background(), domain.ReqRegister{FullName: "Ali", Phone: "(90) 012-34-56", Email: " Ali@Bank.TJ ", Password: "password123"}, domain.RoleUser); err != nil {
+		t.Fatalf("unexpected: %v", err)
+	}
+	if created.Phone != "+992900123456" || created.Email != "ali@bank.tj" {
+		t.Fatalf("contacts not normalized: %+v", created)
+	}
+}
+
+func TestService_ContactVerification_SendAndConfirm(t *testing.T) {
+	var stored domain.ContactVerification
+	var completed domain.ContactVerification
+	notifier := &stubNotifier{}
+	s := NewService(&mockRepo{
+		getUserByIDFn: func(userID int) (*domain.User, error) {
+			return &domain.User{ID: userID, Email: "ali@bank.tj", Phone: "+992900123456", EmailVerified: true}, nil
+		},
+		createVerificationFn: func(v *domain.ContactVerification) error {
+			v.ID = 8
+			stored = *v
+			return nil
+		},
+		getVerificationFn: func(userID int, channel domain.VerificationChannel) (domain.ContactVerification, error) {
+			if stored.ID == 0 || channel != stored.Channel {
+				return domain.ContactVerification{}, errs.ErrVerificationNotFound
+			}
+			return stored, nil
+		},
+		completeVerificationFn: func(v domain.ContactVerification) error {
+			completed = v
+			return nil
+		},
+	})
+	s.SetNotifier(notifier)
+
+	if err := s.SendVerificationCode(context.Background(), 5, domain.VerifyEmail); !errors.Is(err, errs.ErrInvalidOperation) {
+		t.Fatalf("expected ErrInvalidOperation for verified email, got %v", err)
+	}
+	if err := s.SendVerificationCode(context.Background(), 5, domain.VerifyPhone); err != nil {
+		t.Fatalf("unexpected: %v", err)
+	}
+	if len(notifier.sent) != 1 || notifier.sent[0].Channel != domain.ChannelSMS || notifier.sent[0].To != "+992900123456" {
+		t.Fatalf("expected sms to phone, got %+v", notifier.sent)
+	}
+	if stored.Destination != "+992900123456" || stored.Channel != domain.VerifyPhone {
+		t.Fatalf("unexpected verification: %+v", stored)
+	}
+
+	body := notifier.sent[0].Body
+	code := strings.TrimSuffix(strings.Fields(body[strings.Index(body, "is ")+3:])[0], ".")
+	if hashToken(code) != stored.CodeHash {
+		t.Fatalf("stored hash does not match sent code")
+	}
+	if err := s.VerifyContact(context.Background(), 5, domain.VerifyEmail, code); !errors.Is(err, errs.ErrVerificationNotFound) {
+		t.Fatalf("expected ErrVerificationNotFound, got %v", err)
+	}
+	if err := s.VerifyContact(context.Background(), 5, domain.VerifyPhone, code); err != nil {
+		t.Fatalf("unexpected: %v", err)
+	}
+	if completed.ID != 8 {
+		t.Fatalf("expected verification completed, got %+v", completed)
+	}
+}
+
+func TestService_VerifyContact_WrongCodeCountsAttempts(t *testing.T) {
+	attempts := 0
+	s := NewService(&mockRepo{
+		getVerificationFn: func(userID int, channel domain.VerificationChannel) (domain.ContactVerification, error) {
+			return domain.ContactVerification{ID: 2, UserID: userID, Channel: channel, CodeHash: hashToken("123456"), ExpiresAt: time.Now().Add(time.Minute)}, nil
+		},
+		recordVerificationAttemptFn: func(id int, maxAttempts int) (int, error) {
+			attempts++
+			return attempts, nil
+		},
+		completeVerificationFn: func(v domain.ContactVerification) error {
+			t.Fatalf("must not complete with wrong code")
+			return nil
+		},
+	})
+
+	if err := s.VerifyContact(context.Background(), 5, domain.VerifyPhone, "000000"); !errors.Is(err, errs.ErrInvalidOTP) {
+		t.Fatalf("expected ErrInvalidOTP, got %v", err)
+	}
+	attempts = 4
+	if err := s.VerifyContact(context.Background(), 5, domain.VerifyPhone, "000000"); !errors.Is(err, errs.ErrTooManyAttempts) {
+		t.Fatalf("expected ErrTooManyAttempts, got %v", err)
+	}
+}
+
+func TestService_Transfer_ToUnverifiedPhone(t *testing.T) {
+	var lookedUp string
+	verified := false
+	s := NewService(&mockRepo{
+		getAccountByCardNumberFn: func(acc *domain.Account, card string, currency string) error {
+			*acc = domain.Account{ID: 1, UserID: 5, Balance: "100.00", Currency: currency}
+			return nil
+		},
+		getAccountByPhoneNumberFn: func(acc *domain.Account, phone string, currency string) error {
+			lookedUp = phone
+			*acc = domain.Account{ID: 2, UserID: 6, Balance: "0.00", Currency: currency}
+			return nil
+		},
+		getUserByIDFn: func(userID int) (*domain.User, error) {
+			return &domain.User{ID: userID, PhoneVerified: verified}, nil
+		},
+	})
+
+	_, err := s.Transfer(context.Background(), 5, domain.ReqTransfer{FromCardNumber: "4000", ToPhoneNumber: "90 012 34 56", Amount: 10, Currency: "TJS"})
+	if !errors.Is(err, errs.ErrPhoneNotVerified) {
+		t.Fatalf("expected ErrPhoneNotVerified, got %v", err)
+	}
+	if lookedUp != "+992900123456" {
+		t.Fatalf("expected normalized phone lookup, got %q", lookedUp)
+	}
+
+	verified = true
+	if res, err := s.Transfer(context.Background(), 5, domain.ReqTransfer{FromCardNumber: "4000", ToPhoneNumber: "900123456", Amount: 10, Currency: "TJS"}); err != nil || res.Status != domain.TransferCompleted {
+		t.Fatalf("transfer err: %v %+v", err, res)
+	}
+}
+
+func TestService_CreateAPIClient_ScopesLimitedByOwner(t *testing.T) {
+	var stored domain.APIClient
+	var audit domain.AdminAuditLog
+	s := NewService(&mockRepo{
+		getUserByIDFn: func(userID int) (*domain.User, error) {
+			return &domain.User{ID: userID, Role: domain.RoleUser}, nil
+		},
+		createAPIClientFn: func(client *domain.APIClient, reqLogs domain.AdminAuditLog) error {
+			client.ID = 3
+			stored = *client
+			audit = reqLogs
+			return nil
+		},
+	})
+
+	req := domain.ReqCreateAPIClient{Name: "reports", OwnerUserID: 7, Scopes: []domain.Scope{domain.ScopeAuditRead}}
+	if _, _, err := s.CreateAPIClient(context.Background(), req, 1); !errors.Is(err, errs.ErrInvalidScope) {
+		t.Fatalf("expected ErrInvalidScope for audit:read of a client, got %v", err)
+	}
+
+	req.Scopes = []domain.Scope{domain.ScopeAccountsRead, domain.ScopeTransfersInitiate, domain.ScopeAccountsRead}
+	client, secret, err := s.CreateAPIClient(context.Background(), req, 1)
+	if err != nil {
+		t.Fatalf("unexpected: %v", err)
+	}
+	if client.ID != 3 || !strings.HasPrefix(client.ClientID, "mbc_") || len(secret) != 64 {
+		t.Fatalf("unexpected client: %+v secret %q", client, secret)
+	}
+	if stored.SecretHash != hashToken(secret) || len(stored.Scopes) != 2 || stored.RateLimitPerMinute != 60 {
+		t.Fatalf("unexpected stored client: %+v", stored)
+	}
+	if audit.Action != "api_client_created" || audit.AdminID != 1 {
+		t.Fatalf("unexpected audit: %+v", audit)
+	}
+}
+
+func TestService_ClientCredentialsToken(t *testing.T) {
+	client := domain.APIClient{
+		ID: 3, ClientID: "mbc_1", SecretHash: hashToken("s3cret"), OwnerUserID: 7,
+		Scopes: []domain.Scope{domain.ScopeAccountsRead, domain.ScopeTransfersInitiate}, RateLimitPerMinute: 2,
+	}
+	s := NewService(&mockRepo{
+		getAPIClientFn: func(clientID string) (domain.APIClient, error) {
+			if clientID != client.ClientID {
+				return domain.APIClient{}, errs.ErrAPIClientNotFound
 			}
+			return client, nil
+		},
+		getAPIClientByIDFn: func(id int) (domain.APIClient, error) { return client, nil },
+		getUserByIDFn: func(userID int) (*domain.User, error) {
+			return &domain.User{ID: userID, Role: domain.RoleUser}, nil
+		},
+	})
+
+	if _, err := s.IssueClientToken(context.Background(), domain.ReqClientToken{GrantType: "password"}); !errors.Is(err, errs.ErrUnsupportedGrantType) {
+		t.Fatalf("expected ErrUnsupportedGrantType, got %v", err)
+	}
+	if _, err := s.IssueClientToken(context.Background(), domain.ReqClientToken{GrantType: "client_credentials", ClientID: "mbc_1", ClientSecret: "bad"}); !errors.Is(err, errs.ErrInvalidClient) {
+		t.Fatalf("expected ErrInvalidClient, got %v", err)
+	}
+	if _, err := s.IssueClientToken(context.Background(), domain.ReqClientToken{GrantType: "client_credentials", ClientID: "mbc_1", ClientSecret: "s3cret", Scope: "audit:read"}); !errors.Is(err, errs.ErrInvalidScope) {
+		t.Fatalf("expected ErrInvalidScope, got %v", err)
+	}
+
+	res, err := s.IssueClientToken(context.Background(), domain.ReqClientToken{GrantType: "client_credentials", ClientID: "mbc_1", ClientSecret: "s3cret", Scope: "accounts:read"})
+	if err != nil || res.Scope != "accounts:read" || res.TokenType != "Bearer" {
+		t.Fatalf("unexpected token: %+v %v", res, err)
+	}
+
+	user, err := s.ParseToken(context.Background(), res.AccessToken)
+	if err != nil {
+		t.Fatalf("unexpected: %v", err)
+	}
+	if user.ID != 7 || user.APIClientID != 3 || len(user.Scopes) != 1 || user.Scopes[0] != domain.ScopeAccountsRead {
+		t.Fatalf("unexpected client principal: %+v", user)
+	}
+
+	// Лимит 2 запроса в минуту: третий отклоняется (без Redis - счетчик в памяти)
+	if _, err := s.ParseToken(context.Background(), res.AccessToken); err != nil {
+		t.Fatalf("unexpected: %v", err)
+	}
+	if _, err := s.ParseToken(context.Background(), res.AccessToken); !errors.Is(err, errs.ErrRateLimited) {
+		t.Fatalf("expected ErrRateLimited, got %v", err)
+	}
+
+	client.RevokedAt = time.Now()
+	if _, err := s.ParseToken(context.Background(), res.AccessToken); !errors.Is(err, errs.ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken for revoked client, got %v", err)
+	}
+}
+
+func TestService_RecordClientRequest_AttributesClient(t *testing.T) {
+	var audit domain.AdminAuditLog
+	s := NewService(&mockRepo{createAuditLogFn: func(reqLogs domain.AdminAuditLog) error {
+		audit = reqLogs
+		return nil
+	}})
+
+	s.RecordClientRequest(context.Background(), domain.User{ID: 7, APIClientID: 3}, domain.ScopeTransfersInitiate, "POST /api/transfer -> 200")
+	if audit.AdminID != 7 || audit.APIClientID != 3 || audit.Action != "api:transfers:initiate" {
+		t.Fatalf("unexpected audit: %+v", audit)
+	}
+}
+
+func TestService_UpdateProfile_ContactChangeNeedsPasswordAndReverification(t *testing.T) {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	var saved domain.ProfileUpdate
+	notifier := &stubNotifier{}
+	s := NewService(&mockRepo{
+		getUserByIDFn: func(userID int) (*domain.User, error) {
+			return &domain.User{ID: userID, FullName: "Ali Valiev", Email: "ali@bank.tj", Phone: "+992900123456", Password: string(hash), EmailVerified: true}, nil
+		},
+		getCardsByUserIDFn: func(userID int) ([]domain.Card, error) {
+			return []domain.Card{{ID: 1, CardHolderName: "Ali Valiev"}, {ID: 2, CardHolderName: "Ali Valiev"}}, nil
+		},
+		updateUserProfileFn: func(update domain.ProfileUpdate) error {
+			saved = update
+			return nil
+		},
+	})
+	s.SetNotifier(notifier)
+
+	email, name := " Ali.New@Bank.TJ ", "Ali Karimov"
+	req := domain.ReqUpdateProfile{FullName: &name, Email: &email, ReissueCards: true}
+	if _, err := s.UpdateProfile(context.Background(), 5, req); !errors.Is(err, errs.ErrInvalidCredentials) {
+		t.Fatalf("expected ErrInvalidCredentials without password, got %v", err)
+	}
+
+	req.CurrentPassword = "password123"
+	if _, err := s.UpdateProfile(context.Background(), 5, req); err != nil {
+		t.Fatalf("unexpected: %v", err)
+	}
+	if saved.Email != "ali.new@bank.tj" || saved.FullName != "Ali Karimov" || saved.Phone != "+992900123456" || !saved.ReissueCards {
+		t.Fatalf("unexpected update: %+v", saved)
+	}
+	fields := map[domain.ProfileField]string{}
+	for _, ch := range saved.Changes {
+		fields[ch.Field] = ch.OldValue
+	}
+	if len(saved.Changes) != 3 || fields[domain.ProfileEmail] != "ali@bank.tj" || fields[domain.ProfileCardHolderName] != "Ali Valiev" {
+		t.Fatalf("unexpected changes: %+v", saved.Changes)
+	}
+	if len(notifier.sent) != 1 || notifier.sent[0].To != "ali@bank.tj" {
+		t.Fatalf("expected notice to previous email, got %+v", notifier.sent)
+	}
+}
+
+func TestService_UpdateProfile_NoChanges(t *testing.T) {
+	s := NewService(&mockRepo{
+		getUserByIDFn: func(userID int) (*domain.User, error) {
+			return &domain.User{ID: userID, FullName: "Ali", Email: "ali@bank.tj", Phone: "+992900123456"}, nil
+		},
+		updateUserProfileFn: func(update domain.ProfileUpdate) error {
+			t.Fatalf("must not save unchanged profile")
+			return nil
+		},
+	})
+
+	phone, empty := "900123456", " "
+	if _, err := s.UpdateProfile(context.Background(), 5, domain.ReqUpdateProfile{Phone: &phone}); err != nil {
+		t.Fatalf("unexpected: %v", err)
+	}
+	if _, err := s.UpdateProfile(context.Background(), 5, domain.ReqUpdateProfile{FullName: &empty}); !errors.Is(err, errs.ErrInvalidData) {
+		t.Fatalf("expected ErrInvalidData for empty name, got %v", err)
+	}
+}
+
+func TestService_SearchUsers_BuildsFilterAndLogsAccess(t *testing.T) {
+	var filter domain.UserSearchFilter
+	var audit domain.AuditEvent
+	s := NewService(&mockRepo{
+		appendAuditEventFn: func(event domain.AuditEvent) (domain.AuditEvent, error) {
+			audit = event
+			return event, nil
+		},
+		searchUsersFn: func(f domain.UserSearchFilter) ([]domain.User, error) {
+			filter = f
+			return []domain.User{{ID: 5}}, nil
+		},
+	})
+
+	users, err := s.SearchUsers(context.Background(), 1, domain.UserSearch{Query: "4242 4242 4242 4242"})
+	if err != nil || len(users) != 1 {
+		t.Fatalf("unexpected: %v %+v", err, users)
+	}
+	if filter.CardNumber != "4242424242424242" || filter.Limit != defaultUserSearchLimit || filter.Phone != "" {
+		t.Fatalf("unexpected filter: %+v", filter)
+	}
+	if audit.ActorID != 1 || audit.Action != "customer_search" || audit.TargetType != "user" {
+		t.Fatalf("unexpected audit: %+v", audit)
+	}
+
+	if _, err := s.SearchUsers(context.Background(), 1, domain.UserSearch{Query: "100%_"}); err != nil {
+		t.Fatalf("unexpected: %v", err)
+	}
+	if filter.NamePattern != `%100\%\_%` || filter.CardNumber != "" {
+		t.Fatalf("unexpected escaped filter: %+v", filter)
+	}
+	if _, err := s.SearchUsers(context.Background(), 1, domain.UserSearch{Limit: 500}); !errors.Is(err, errs.ErrInvalidData) {
+		t.Fatalf("expected ErrInvalidData for big limit, got %v", err)
+	}
+}
+
+func TestService_UserDetail_FailsClosedWithoutAudit(t *testing.T) {
+	s := NewService(&mockRepo{
+		getUserByIDFn: func(userID int) (*domain.User, error) {
+			return &domain.User{ID: userID}, nil
+		},
+		appendAuditEventFn: func(event domain.AuditEvent) (domain.AuditEvent, error) {
+			return event, errors.New("db down")
+		},
+		getAllAccountsByUserIDFn: func(userID int) ([]domain.Account, error) {
+			t.Fatalf("customer data must not be read without audit")
+			return nil, nil
+		},
+	})
+
+	if _, err := s.UserDetail(context.Background(), 1, 5); err == nil {
+		t.Fatalf("expected error when audit write fails")
+	}
+}
+
+func TestService_SetUserDisabled_RevokesSessions(t *testing.T) {
+	var revoked int
+	var audit domain.AdminAuditLog
+	s := NewService(&mockRepo{
+		getUserByIDFn: func(userID int) (*domain.User, error) {
+			return &domain.User{ID: userID}, nil
+		},
+		setUserDisabledFn: func(userID int, disabled bool, reqLogs domain.AdminAuditLog) error {
+			audit = reqLogs
 			return nil
 		},
-		transferFundsFn: func(fromID, toID int, amount float64) error {
-			called = true
-			if fromID != 1 || toID != 2 || amount <= 10 {
-				t.Fatalf("bad transfer args")
+		revokeUserSessionsFn: func(userID int, reason string) ([]string, error) {
+			revoked = userID
+			return []string{"s1"}, nil
+		},
+	})
+
+	if err := s.SetUserDisabled(context.Background(), 1, 1, true, "fraud"); !errors.Is(err, errs.ErrOperationNotAllowed) {
+		t.Fatalf("expected ErrOperationNotAllowed for self-disable, got %v", err)
+	}
+	if err := s.SetUserDisabled(context.Background(), 1, 5, true, " "); !errors.Is(err, errs.ErrInvalidData) {
+		t.Fatalf("expected ErrInvalidData without reason, got %v", err)
+	}
+	if err := s.SetUserDisabled(context.Background(), 1, 5, true, "fraud"); err != nil {
+		t.Fatalf("unexpected: %v", err)
+	}
+	if revoked != 5 || audit.Action != "user_disabled" || audit.AdminID != 1 {
+		t.Fatalf("unexpected revoke=%d audit=%+v", revoked, audit)
+	}
+}
+
+func TestService_Login_DisabledUser(t *testing.T) {
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	s := NewService(&mockRepo{getUserByEmailFn: func(email string) (*domain.User, error) {
+		return &domain.User{ID: 5, Email: email, Password: string(hashed), Role: domain.RoleUser, Disabled: true}, nil
+	}})
+
+	if _, err := s.Login(context.Background(), domain.ReqLogin{Email: "a@b.c", Password: "password123"}); !errors.Is(err, errs.ErrUserDisabled) {
+		t.Fatalf("expected ErrUserDisabled, got %v", err)
+	}
+}
+
+func TestService_VerifyAuditChain_DetectsTampering(t *testing.T) {
+	var chain []domain.AuditEvent
+	prev := ""
+	for i, action := range []string{"login_succeeded", "profile_updated", "block"} {
+		e := domain.AuditEvent{
+			ID: int64(i + 1), OccurredAt: time.Date(2026, 1, 2, 3, 4, 5, 123456000, time.UTC),
+			ActorID: 5, ActorType: domain.ActorUser, Action: action, Outcome: domain.AuditSuccess,
+			After: json.RawMessage(`{"b":1,"a":2}`), PrevHash: prev,
+		}
+		e.Hash = e.ComputeHash()
+		prev = e.Hash
+		chain = append(chain, e)
+	}
+	verify := func(events []domain.AuditEvent) domain.AuditChainReport {
+		s := NewService(&mockRepo{getAuditEventsAfterFn: func(afterID int64, limit int) ([]domain.AuditEvent, error) {
+			var page []domain.AuditEvent
+			for _, e := range events {
+				if e.ID > afterID && len(page) < limit {
+					page = append(page, e)
+				}
+			}
+			return page, nil
+		}})
+		report, err := s.VerifyAuditChain(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected: %v", err)
+		}
+		return report
+	}
+
+	if report := verify(chain); !report.Valid() || report.Checked != 3 || report.HeadHash != chain[2].Hash {
+		t.Fatalf("expected intact chain, got %+v", report)
+	}
+
+	edited := append([]domain.AuditEvent(nil), chain...)
+	edited[1].Action = "profile_viewed"
+	if report := verify(edited); report.BrokenAt != 2 {
+		t.Fatalf("expected edit detected at #2, got %+v", report)
+	}
+
+	deleted := []domain.AuditEvent{chain[0], chain[2]}
+	if report := verify(deleted); report.BrokenAt != 3 {
+		t.Fatalf("expected deletion detected at #3, got %+v", report)
+	}
+}
+
+func TestService_Login_RecordsAuditEventsWithRequestMeta(t *testing.T) {
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	var events []domain.AuditEvent
+	base := NewService(&mockRepo{
+		getUserByEmailFn: func(email string) (*domain.User, error) {
+			return &domain.User{ID: 5, Email: email, Password: string(hashed), Role: domain.RoleUser}, nil
+		},
+		appendAuditEventFn: func(event domain.AuditEvent) (domain.AuditEvent, error) {
+			events = append(events, event)
+			return event, nil
+		},
+	})
+	s := base.WithRequest(domain.RequestMeta{RequestID: "req-1", IP: "10.0.0.1", UserAgent: "curl/8"})
+
+	if _, err := s.Login(context.Background(), domain.ReqLogin{Email: "a@b.c", Password: "wrong"}); err == nil {
+		t.Fatalf("expected error on bad password")
+	}
+	if _, err := s.Login(context.Background(), domain.ReqLogin{Email: "a@b.c", Password: "password123"}); err != nil {
+		t.Fatalf("unexpected: %v", err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("expected 2 audit events, got %+v", events)
+	}
+	failed, succeeded := events[0], events[1]
+	if failed.Action != "login_failed" || failed.Outcome != domain.AuditFailure || failed.ActorType != domain.ActorAnonymous ||
+		failed.TargetID != "5" || failed.RequestID != "req-1" || failed.IP != "10.0.0.1" || failed.UserAgent != "curl/8" {
+		t.Fatalf("unexpected failure event: %+v", failed)
+	}
+	if succeeded.Action != "login_succeeded" || succeeded.ActorID != 5 || succeeded.ActorType != domain.ActorUser {
+		t.Fatalf("unexpected success event: %+v", succeeded)
+	}
+}
+
+func TestService_AuditLogs_CursorPagination(t *testing.T) {
+	var got domain.AuditLogFilter
+	s := NewService(&mockRepo{getAuditLogsFn: func(filter domain.AuditLogFilter) ([]domain.AdminAuditLog, error) {
+		got = filter
+		logs := make([]domain.AdminAuditLog, 0, filter.Limit)
+		for id := 100; id > 100-filter.Limit; id-- {
+			logs = append(logs, domain.AdminAuditLog{ID: id})
+		}
+		return logs, nil
+	}})
+
+	page, err := s.AuditLogs(context.Background(), domain.AuditLogFilter{AdminID: 7, Limit: 3})
+	if err != nil {
+		t.Fatalf("unexpected: %v", err)
+	}
+	if got.Limit != 4 || got.AdminID != 7 || len(page.Logs) != 3 || page.NextCursor != 98 {
+		t.Fatalf("unexpected page %+v for filter %+v", page, got)
+	}
+
+	from := time.Now()
+	if _, err := s.AuditLogs(context.Background(), domain.AuditLogFilter{From: from, To: from.Add(-time.Hour)}); !errors.Is(err, errs.ErrInvalidData) {
+		t.Fatalf("expected ErrInvalidData for inverted range, got %v", err)
+	}
+	if _, err := s.AuditLogs(context.Background(), domain.AuditLogFilter{Limit: maxAuditPageSize + 1}); !errors.Is(err, errs.ErrInvalidData) {
+		t.Fatalf("expected ErrInvalidData for big page, got %v", err)
+	}
+}
+
+func TestService_ExportAuditLogs_BatchesAndRecordsExport(t *testing.T) {
+	var cursors []int
+	var events []domain.AuditEvent
+	repo := &mockRepo{
+		appendAuditEventFn: func(event domain.AuditEvent) (domain.AuditEvent, error) {
+			events = append(events, event)
+			return event, nil
+		},
+		getAuditLogsFn: func(filter domain.AuditLogFilter) ([]domain.AdminAuditLog, error) {
+			cursors = append(cursors, filter.Cursor)
+			// полная порция, затем неполная
+			n := auditExportBatch
+			if filter.Cursor != 0 {
+				n = 2
+			}
+			logs := make([]domain.AdminAuditLog, n)
+			for i := range logs {
+				logs[i] = domain.AdminAuditLog{ID: 5000 - filter.Cursor/10 - i}
+			}
+			return logs, nil
+		},
+	}
+	s := NewService(repo)
+
+	exported := 0
+	err := s.ExportAuditLogs(context.Background(), domain.AuditLogFilter{Action: "block"}, func(domain.AdminAuditLog) error {
+		exported++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected: %v", err)
+	}
+	if exported != auditExportBatch+2 || len(cursors) != 2 || cursors[1] != 5000-auditExportBatch+1 {
+		t.Fatalf("unexpected export: rows=%d cursors=%v", exported, cursors)
+	}
+	if len(events) != 1 || events[0].Action != "audit_log_exported" {
+		t.Fatalf("expected export recorded in audit_events, got %+v", events)
+	}
+
+	repo.appendAuditEventFn = func(event domain.AuditEvent) (domain.AuditEvent, error) {
+		return event, errors.New("db down")
+	}
+	cursors = nil
+	if err := s.ExportAuditLogs(context.Background(), domain.AuditLogFilter{}, func(domain.AdminAuditLog) error { return nil }); err == nil || len(cursors) != 0 {
+		t.Fatalf("expected export refused without audit record, err=%v cursors=%v", err, cursors)
+	}
+}
+
+func TestService_AdminStats_RangeAndFeeIncome(t *testing.T) {
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	var gotFrom, gotTo time.Time
+	s := NewService(&mockRepo{getAdminStatsFn: func(from, to time.Time, topBreaches int) (domain.AdminStats, error) {
+		gotFrom, gotTo = from, to
+		if topBreaches != topLimitBreaches {
+			t.Fatalf("unexpected top breaches %d", topBreaches)
+		}
+		return domain.AdminStats{From: from, To: to, Volumes: []domain.DailyVolume{
+			{Day: day, Currency: "TJS", Type: domain.Transfer, Count: 2, Volume: 200, Fees: 2},
+			{Day: day, Currency: "TJS", Type: domain.Withdrawal, Count: 1, Volume: 50, Fees: 0.5},
+			{Day: day, Currency: "USD", Type: domain.Deposit, Count: 1, Volume: 10},
+		}}, nil
+	}})
+
+	stats, err := s.AdminStats(context.Background(), day, day.AddDate(0, 0, 7))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !gotFrom.Equal(day) || !gotTo.Equal(day.AddDate(0, 0, 7)) {
+		t.Fatalf("unexpected period %v - %v", gotFrom, gotTo)
+	}
+	if len(stats.FeeIncome) != 1 || stats.FeeIncome["TJS"] != 2.5 {
+		t.Fatalf("unexpected fee income: %v", stats.FeeIncome)
+	}
+	if stats.GeneratedAt.IsZero() {
+		t.Fatal("expected generated_at to be set")
+	}
+
+	// по умолчанию - последние 30 дней, включая сегодня
+	if _, err := s.AdminStats(context.Background(), time.Time{}, time.Time{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotTo.Sub(gotFrom) != defaultStatsPeriod || !gotTo.After(time.Now()) {
+		t.Fatalf("unexpected default period %v - %v", gotFrom, gotTo)
+	}
+
+	for _, period := range [][2]time.Time{
+		{day, day},
+		{day.AddDate(0, 0, 1), day},
+		{day, day.AddDate(2, 0, 0)},
+	} {
+		if _, err := s.AdminStats(context.Background(), period[0], period[1]); !errors.Is(err, errs.ErrInvalidData) {
+			t.Fatalf("%v - %v: expected ErrInvalidData, got %v", period[0], period[1], err)
+		}
+	}
+}
+
+func TestService_AdjustBalance_Validation(t *testing.T) {
+	s := NewService(&mockRepo{adjustBalanceFn: func(adj domain.BalanceAdjustment, ceilingTJS float64, reqLogs domain.AdminAuditLog) (domain.BalanceAdjustment, error) {
+		t.Fatal("repository must not be called for invalid adjustment")
+		return adj, nil
+	}})
+	for _, tc := range []struct {
+		req  domain.ReqBalanceAdjustment
+		want error
+	}{
+		{domain.ReqBalanceAdjustment{Direction: "refund", Amount: 10, ReasonCode: domain.AdjustmentGoodwill, Note: "n"}, errs.ErrInvalidData},
+		{domain.ReqBalanceAdjustment{Direction: domain.AdjustmentCredit, Amount: 10, ReasonCode: "because", Note: "n"}, errs.ErrInvalidData},
+		{domain.ReqBalanceAdjustment{Direction: domain.AdjustmentCredit, Amount: 10, ReasonCode: domain.AdjustmentGoodwill, Note: "  "}, errs.ErrInvalidData},
+		{domain.ReqBalanceAdjustment{Direction: domain.AdjustmentDebit, Amount: 0.001, ReasonCode: domain.AdjustmentOther, Note: "n"}, errs.ErrInvalidAmount},
+	} {
+		if _, err := s.AdjustBalance(context.Background(), 1, 10, tc.req); !errors.Is(err, tc.want) {
+			t.Fatalf("%+v: expected %v, got %v", tc.req, tc.want, err)
+		}
+	}
+}
+
+func TestService_AdjustBalance_Debit(t *testing.T) {
+	var events []domain.AuditEvent
+	s := NewService(&mockRepo{
+		getAccountByIDFn: func(accountID int) (domain.Account, error) {
+			return domain.Account{ID: accountID, Currency: "USD", Balance: "100.00"}, nil
+		},
+		adjustBalanceFn: func(adj domain.BalanceAdjustment, ceilingTJS float64, reqLogs domain.AdminAuditLog) (domain.BalanceAdjustment, error) {
+			if adj.SignedAmount() != -20 || adj.AmountTJS != 184.2 || ceilingTJS != 5000 {
+				t.Fatalf("unexpected adjustment %+v, ceiling %v", adj, ceilingTJS)
+			}
+			if reqLogs.Action != "balance_debit" || reqLogs.Reason != "error_correction: duplicate deposit; amount -20.00 USD" {
+				t.Fatalf("unexpected audit log %+v", reqLogs)
+			}
+			adj.ID, adj.TransactionID, adj.BalanceBefore, adj.BalanceAfter = 3, 77, 100, 80
+			return adj, nil
+		},
+		appendAuditEventFn: func(event domain.AuditEvent) (domain.AuditEvent, error) {
+			events = append(events, event)
+			return event, nil
+		},
+	})
+
+	adj, err := s.AdjustBalance(context.Background(), 1, 10, domain.ReqBalanceAdjustment{
+		Direction: domain.AdjustmentDebit, Amount: 20, ReasonCode: domain.AdjustmentErrorCorrection, Note: " duplicate deposit ",
+	})
+	if err != nil || adj.TransactionID != 77 || adj.BalanceAfter != 80 {
+		t.Fatalf("unexpected result %+v, err=%v", adj, err)
+	}
+	if len(events) != 1 || events[0].Action != "balance_debit" || !strings.Contains(string(events[0].Before), `"balance":100`) ||
+		!strings.Contains(string(events[0].After), `"balance":80`) {
+		t.Fatalf("unexpected audit events %+v", events)
+	}
+}
+
+func TestService_AdjustBalance_CeilingExceeded(t *testing.T) {
+	var events []domain.AuditEvent
+	s := NewService(&mockRepo{
+		getAccountByIDFn: func(accountID int) (domain.Account, error) {
+			return domain.Account{ID: accountID, Currency: "TJS"}, nil
+		},
+		adjustBalanceFn: func(adj domain.BalanceAdjustment, ceilingTJS float64, reqLogs domain.AdminAuditLog) (domain.BalanceAdjustment, error) {
+			return adj, errs.ErrAdjustmentCeiling
+		},
+		appendAuditEventFn: func(event domain.AuditEvent) (domain.AuditEvent, error) {
+			events = append(events, event)
+			return event, nil
+		},
+	})
+
+	// мелкая корректировка, но дневной потолок админа уже выбран
+	_, err := s.AdjustBalance(context.Background(), 1, 10, domain.ReqBalanceAdjustment{
+		Direction: domain.AdjustmentCredit, Amount: 400, ReasonCode: domain.AdjustmentGoodwill, Note: "outage",
+	})
+	if !errors.Is(err, errs.ErrAdjustmentCeiling) {
+		t.Fatalf("expected ErrAdjustmentCeiling, got %v", err)
+	}
+	if len(events) != 1 || events[0].Outcome != domain.AuditDenied {
+		t.Fatalf("expected denied audit event, got %+v", events)
+	}
+}
+
+func TestService_AdjustBalance_LargeNeedsApproval(t *testing.T) {
+	var events []domain.AuditEvent
+	s := NewService(&mockRepo{
+		getAccountByIDFn: func(accountID int) (domain.Account, error) {
+			return domain.Account{ID: accountID, Currency: "USD", Balance: "1000.00"}, nil
+		},
+		adjustBalanceFn: func(adj domain.BalanceAdjustment, ceilingTJS float64, reqLogs domain.AdminAuditLog) (domain.BalanceAdjustment, error) {
+			t.Fatal("adjustment above the no-approval limit must not be posted directly")
+			return adj, nil
+		},
+		appendAuditEventFn: func(event domain.AuditEvent) (domain.AuditEvent, error) {
+			events = append(events, event)
+			return event, nil
+		},
+	})
+
+	// 60 USD - больше 500 TJS, даже под дневным потолком нужен второй админ
+	_, err := s.AdjustBalance(context.Background(), 1, 10, domain.ReqBalanceAdjustment{
+		Direction: domain.AdjustmentCredit, Amount: 60, ReasonCode: domain.AdjustmentGoodwill, Note: "outage",
+	})
+	if !errors.Is(err, errs.ErrApprovalRequired) {
+		t.Fatalf("expected ErrApprovalRequired, got %v", err)
+	}
+	if len(events) != 1 || events[0].Action != "balance_adjustment_denied" || events[0].Outcome != domain.AuditDenied {
+		t.Fatalf("expected denied audit event, got %+v", events)
+	}
+}
+
+func TestService_MarkDormantAccounts(t *testing.T) {
+	events := 0
+	s := NewService(&mockRepo{
+		markDormantFn: func(inactiveSince time.Time, reason string) ([]int, error) {
+			want := time.Now().AddDate(0, -6, 0)
+			if inactiveSince.Sub(want).Abs() > time.Minute || reason != "no customer activity for 6 months" {
+				t.Fatalf("unexpected scan args: %v %q", inactiveSince, reason)
 			}
+			return []int{3, 7}, nil
+		},
+		appendAuditEventFn: func(event domain.AuditEvent) (domain.AuditEvent, error) {
+			if event.Action != "dormant" || event.TargetType != "account" {
+				t.Fatalf("unexpected audit event: %+v", event)
+			}
+			events++
+			return event, nil
+		},
+	})
+	cfg := config.DefaultService()
+	cfg.DormancyMonths = 6
+	s.SetConfig(cfg)
+
+	count, err := s.MarkDormantAccounts(context.Background())
+	if err != nil || count != 2 || events != 2 {
+		t.Fatalf("expected 2 accounts and 2 events, got %d %d %v", count, events, err)
+	}
+}
+
+func TestService_Dormant_BlocksDebitsOnly(t *testing.T) {
+	moved := 0
+	s := NewService(&mockRepo{
+		getAccountByCardNumberFn: func(account *domain.Account, cardNumber string, currency string) error {
+			*account = domain.Account{ID: 1, UserID: 5, Balance: "100.00", Currency: "TJS"}
+			return nil
+		},
+		activeRestrictionsFn: func(accountID int) (domain.AccountRestrictions, error) {
+			return domain.AccountRestrictions{{Mode: domain.RestrictionDormant}}, nil
+		},
+		depositToAccountFn: func(accountID int, amount float64) error {
+			moved++
 			return nil
 		},
 	})
-	if err := s.Transfer(5, domain.ReqTransfer{FromCardNumber: "4000", ToCardNumber: "5000", Amount: 10, Currency: "TJS"}); err != nil {
-		t.Fatalf("transfer err: %v", err)
+
+	if err := s.Withdraw(context.Background(), 5, domain.ReqTransaction{Amount: 10, CardNumber: "1111"}); !errors.Is(err, errs.ErrAccountDormant) {
+		t.Fatalf("expected ErrAccountDormant, got %v", err)
 	}
-	if !called {
-		t.Fatalf("expected transfer called")
+	if err := s.Deposit(context.Background(), 5, domain.ReqTransaction{Amount: 10, CardNumber: "1111"}); err != nil || moved != 1 {
+		t.Fatalf("deposit to a dormant account must succeed, err=%v moved=%d", err, moved)
+	}
+}
+
+func TestService_ReactivateAccount(t *testing.T) {
+	pw := "password123"
+	hashed, _ := bcrypt.GenerateFromPassword([]byte(pw), bcrypt.MinCost)
+	secret, _ := totp.GenerateSecret()
+	mfaEnabled := false
+	var lifted domain.RestrictionMode
+	var liftedBy int
+	var audit domain.AdminAuditLog
+	s := NewService(&mockRepo{
+		getAccountByIDFn: func(accountID int) (domain.Account, error) {
+			return domain.Account{ID: accountID, UserID: 5}, nil
+		},
+		getUserByIDFn: func(userID int) (*domain.User, error) {
+			return &domain.User{ID: userID, Password: string(hashed)}, nil
+		},
+		getMFAConfigFn: func(userID int) (domain.MFAConfig, error) {
+			return domain.MFAConfig{UserID: userID, Secret: secret, Enabled: mfaEnabled}, nil
+		},
+		liftRestrictionsFn: func(accountID int, mode domain.RestrictionMode, by int, reqLogs domain.AdminAuditLog) (int, error) {
+			lifted, liftedBy, audit = mode, by, reqLogs
+			return 1, nil
+		},
+	})
+
+	if err := s.ReactivateAccount(context.Background(), 6, 10, domain.ReqAccountReactivation{Password: pw}); !errors.Is(err, errs.ErrAccessDenied) {
+		t.Fatalf("expected ErrAccessDenied for another user's account, got %v", err)
+	}
+	if err := s.ReactivateAccount(context.Background(), 5, 10, domain.ReqAccountReactivation{Password: "wrong"}); !errors.Is(err, errs.ErrInvalidCredentials) {
+		t.Fatalf("expected ErrInvalidCredentials, got %v", err)
+	}
+
+	mfaEnabled = true
+	if err := s.ReactivateAccount(context.Background(), 5, 10, domain.ReqAccountReactivation{Password: pw}); !errors.Is(err, errs.ErrMFARequired) {
+		t.Fatalf("expected ErrMFARequired, got %v", err)
+	}
+	if lifted != "" {
+		t.Fatalf("restriction must not be lifted without identity confirmation")
+	}
+
+	code, _ := totp.CodeAt(secret, totp.Step(time.Now()))
+	if err := s.ReactivateAccount(context.Background(), 5, 10, domain.ReqAccountReactivation{Password: pw, MFACode: code}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if lifted != domain.RestrictionDormant || liftedBy != 5 || audit.Action != "reactivated" || audit.AccountID != 10 {
+		t.Fatalf("unexpected lift: mode=%s by=%d audit=%+v", lifted, liftedBy, audit)
+	}
+}
+
+// memStore - хранилище документов в памяти для тестов KYC
+type memStore map[string][]byte
+
+func (m memStore) Put(key string, body io.Reader, contentType string) error {
+	data, err := io.ReadAll(body)
+	m[key] = data
+	return err
+}
+func (m memStore) Open(key string) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(m[key])), nil
+}
+func (m memStore) Delete(key string) error {
+	delete(m, key)
+	return nil
+}
+
+func TestService_SubmitKYCDocument(t *testing.T) {
+	store := memStore{}
+	var created domain.KYCDocument
+	failInsert := false
+	s := NewService(&mockRepo{createKYCDocumentFn: func(doc *domain.KYCDocument) error {
+		if failInsert {
+			return errs.ErrDatabaseError
+		}
+		doc.ID = 9
+		doc.Status = domain.KYCDocumentPending
+		created = *doc
+		return nil
+	}})
+	s.SetDocumentStore(store)
+
+	png := append([]byte("\x89PNG\r\n\x1a\n"), []byte("scan")...)
+	doc, err := s.SubmitKYCDocument(context.Background(), 5, domain.ReqKYCDocument{Type: domain.KYCPassport, FileName: `C:\\scans\\passport.png`, Body: bytes.NewReader(png)})
+	if err != nil || doc.ID != 9 {
+		t.Fatalf("unexpected result %+v, err=%v", doc, err)
+	}
+	if created.ContentType != "image/png" || created.FileName != "passport.png" || created.Size != int64(len(png)) ||
+		!strings.HasPrefix(created.StorageKey, "kyc/5/") || len(created.SHA256) != 64 {
+		t.Fatalf("unexpected document metadata: %+v", created)
+	}
+	if !bytes.Equal(store[created.StorageKey], png) {
+		t.Fatalf("file was not stored under %s", created.StorageKey)
+	}
+
+	if _, err := s.SubmitKYCDocument(context.Background(), 5, domain.ReqKYCDocument{Type: domain.KYCPassport, Body: strings.NewReader("plain text")}); !errors.Is(err, errs.ErrKYCDocumentInvalid) {
+		t.Fatalf("expected ErrKYCDocumentInvalid for text, got %v", err)
+	}
+	cfg := config.DefaultService()
+	cfg.KYCMaxDocumentMB = 1
+	s.SetConfig(cfg)
+	big := append(append([]byte{}, png...), make([]byte, 1<<20)...)
+	if _, err := s.SubmitKYCDocument(context.Background(), 5, domain.ReqKYCDocument{Type: domain.KYCPassport, Body: bytes.NewReader(big)}); !errors.Is(err, errs.ErrKYCDocumentInvalid) {
+		t.Fatalf("expected ErrKYCDocumentInvalid for oversized file, got %v", err)
+	}
+
+	// Без записи в БД файл не остается в хранилище
+	failInsert = true
+	if _, err := s.SubmitKYCDocument(context.Background(), 5, domain.ReqKYCDocument{Type: domain.KYCNationalID, Body: bytes.NewReader(png)}); err == nil {
+		t.Fatalf("expected error when the document row cannot be saved")
+	}
+	if len(store) != 1 {
+		t.Fatalf("orphaned file left in storage: %d objects", len(store))
+	}
+}
+
+func TestService_ReviewKYCDocument_RaisesTier(t *testing.T) {
+	docs := []domain.KYCDocument{
+		{ID: 1, UserID: 5, Type: domain.KYCPassport, Status: domain.KYCDocumentApproved},
+		{ID: 2, UserID: 5, Type: domain.KYCProofOfAddress, Status: domain.KYCDocumentPending},
+	}
+	var setTier domain.KYCTier
+	var setLimit float64
+	s := NewService(&mockRepo{
+		getKYCDocumentFn: func(docID int) (domain.KYCDocument, error) {
+			return docs[docID-1], nil
+		},
+		getKYCTierFn: func(userID int) (domain.KYCTier, error) {
+			return domain.KYCBasic, nil
+		},
+		reviewKYCDocumentFn: func(docID int, status domain.KYCDocumentStatus, adminID int, note string) error {
+			docs[docID-1].Status = status
+			return nil
+		},
+		getKYCDocumentsFn: func(userID int) ([]domain.KYCDocument, error) {
+			return docs, nil
+		},
+		setKYCTierFn: func(userID int, tier domain.KYCTier, dailyAmount float64) error {
+			setTier, setLimit = tier, dailyAmount
+			return nil
+		},
+	})
+
+	if _, err := s.ReviewKYCDocument(context.Background(), 2, 5, true, "own document"); !errors.Is(err, errs.ErrSelfApproval) {
+		t.Fatalf("expected ErrSelfApproval, got %v", err)
+	}
+	if _, err := s.ReviewKYCDocument(context.Background(), 2, 1, true, " "); err == nil {
+		t.Fatalf("expected error without note")
+	}
+
+	tier, err := s.ReviewKYCDocument(context.Background(), 2, 1, true, "utility bill matches")
+	if err != nil || tier != domain.KYCFull {
+		t.Fatalf("expected full tier, got %s err=%v", tier, err)
+	}
+	if setTier != domain.KYCFull || setLimit != domain.KYCFull.Policy().DailyAmount {
+		t.Fatalf("unexpected tier update: %s %.2f", setTier, setLimit)
+	}
+}
+
+func TestService_ReviewKYCDocument_RejectKeepsTier(t *testing.T) {
+	setCalled := false
+	s := NewService(&mockRepo{
+		getKYCDocumentFn: func(docID int) (domain.KYCDocument, error) {
+			return domain.KYCDocument{ID: docID, UserID: 5, Type: domain.KYCPassport, Status: domain.KYCDocumentPending}, nil
+		},
+		getKYCTierFn: func(userID int) (domain.KYCTier, error) {
+			return domain.KYCUnverified, nil
+		},
+		setKYCTierFn: func(userID int, tier domain.KYCTier, dailyAmount float64) error {
+			setCalled = true
+			return nil
+		},
+	})
+
+	tier, err := s.ReviewKYCDocument(context.Background(), 1, 2, false, "photo is blurred")
+	if err != nil || tier != domain.KYCUnverified || setCalled {
+		t.Fatalf("rejection must not change the tier: tier=%s err=%v set=%v", tier, err, setCalled)
+	}
+}
+
+func TestService_CheckLimitAndCalculateFee_KYCTier(t *testing.T) {
+	tier := domain.KYCUnverified
+	hasLimit := true
+	usage := 2500.0
+	s := NewService(&mockRepo{
+		getKYCTierFn: func(userID int) (domain.KYCTier, error) { return tier, nil },
+		getDailyLimitByUserIDFn: func(userID int) (domain.Limit, error) {
+			if !hasLimit {
+				return domain.Limit{}, errs.ErrUserNotFound
+			}
+			return domain.Limit{UserID: userID, DailyAmount: 1000, LastReset: time.Now()}, nil
+		},
+		getTodayUsageInTJSFn: func(userID int) (float64, error) { return usage, nil },
+	})
+
+	// unverified: потолок 3000 за день
+	if _, err := s.CheckLimitAndCalculateFee(context.Background(), 5, 600, "TJS"); !errors.Is(err, errs.ErrKYCLimitExceeded) {
+		t.Fatalf("expected ErrKYCLimitExceeded, got %v", err)
+	}
+	fee, err := s.CheckLimitAndCalculateFee(context.Background(), 5, 400, "TJS")
+	if err != nil || math.Abs(fee-38) > 1e-9 {
+		t.Fatalf("expected fee 38 under the cap, got %v err=%v", fee, err)
+	}
+
+	// Без записи лимита действует лимит уровня: basic - 5000 без комиссии
+	tier, hasLimit, usage = domain.KYCBasic, false, 0
+	fee, err = s.CheckLimitAndCalculateFee(context.Background(), 5, 6000, "TJS")
+	if err != nil || math.Abs(fee-20) > 1e-9 {
+		t.Fatalf("expected fee 20 over the basic limit, got %v err=%v", fee, err)
 	}
 }
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/logger"
+	"github.com/MMII0220/MiniBank/internal/redis"
+)
+
+// sessionStatusCacheTTL - сколько живет в Redis признак активности сессии
+const sessionStatusCacheTTL = 5 * time.Minute
+
+// stepUpResendInterval - повторный код подтверждения сессии можно запросить не чаще
+const stepUpResendInterval = time.Minute
+
+// deviceKey - идентификатор устройства в auth_sessions: хеш токена устройства.
+// Токен выдает сервер при первом входе, поэтому его нельзя угадать, как User-Agent или придуманный клиентом id.
+func deviceKey(deviceToken string) string {
+	return "dev:" + hashToken(deviceToken)
+}
+
+// hashToken - в БД хранится только sha256 от refresh токена
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// startSession создает серверную сессию и выдает первую пару токенов
+func (s *Service) startSession(ctx context.Context, user domain.User, req domain.ReqLogin, mfaVerified bool) (domain.TokenResponse, error) {
+	log := logger.GetLogger()
+	var response domain.TokenResponse
+
+	// Без токена устройства (первый вход, очищенные cookie) выдаем новый - такое устройство считается новым
+	deviceToken := req.DeviceID
+	if deviceToken == "" {
+		token, err := s.generateRefreshToken()
+		if err != nil {
+			return response, err
+		}
+		deviceToken = token
+	}
+	deviceID := deviceKey(deviceToken)
+	newDevice, err := s.isNewDevice(ctx, user.ID, deviceID)
+	if err != nil {
+		return response, err
+	}
+
+	sessionID, err := s.generateRefreshToken()
+	if err != nil {
+		return response, err
+	}
+	refreshToken, err := s.generateRefreshToken()
+	if err != nil {
+		return response, err
+	}
+
+	session := domain.Session{
+		ID:             sessionID,
+		UserID:         user.ID,
+		DeviceID:       deviceID,
+		UserAgent:      req.UserAgent,
+		IP:             req.IP,
+		StepUpRequired: newDevice && s.cfg.NewDeviceStepUp && !mfaVerified, // вход с кодом 2FA уже подтвержден вторым фактором
+		MFAVerified:    mfaVerified,
+		ExpiresAt:      time.Now().Add(s.cfg.RefreshTokenTTL),
+	}
+	if err := s.repo.CreateSession(ctx, &session, hashToken(refreshToken)); err != nil {
+		return response, s.translateError(err)
+	}
+
+	if newDevice {
+		log.Info().Int("user_id", user.ID).Str("device_id", deviceID).Str("ip", req.IP).Msg("Login from a new device")
+		s.notifyUser(ctx, user.ID, "New sign-in to your account",
+			fmt.Sprintf("Your account was accessed from a new device (%s, IP %s) at %s. If this was not you, sign out of all sessions and change your password.",
+				req.UserAgent, req.IP, session.CreatedAt.Format(time.RFC3339)))
+	}
+	if session.StepUpRequired {
+		// Код можно запросить повторно через SendStepUpCode, поэтому сбой отправки не ломает вход
+		if err := s.sendStepUpCode(ctx, user, session.ID); err != nil {
+			log.Error().Err(err).Int("user_id", user.ID).Str("session_id", session.ID).Msg("Failed to send step-up code")
+		}
+	}
+
+	accessToken, err := s.createAccessToken(user.ID, user.Role, session.ID, mfaVerified)
+	if err != nil {
+		return response, s.translateError(err)
+	}
+
+	response = domain.TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    15 * 60, // 15 минут в секундах
+		TokenType:    "Bearer",
+		DeviceToken:  deviceToken,
+	}
+	return response, nil
+}
+
+// sessionActive проверяет сессию access токена: сначала Redis, затем БД
+func (s *Service) sessionActive(ctx context.Context, sessionID string, userID int) bool {
+	log := logger.GetLogger()
+
+	if active, err := redis.GetSessionStatus(ctx, sessionID); err == nil {
+		return active
+	}
+
+	session, err := s.repo.GetSession(ctx, sessionID)
+	if err != nil {
+		log.Debug().Err(err).Str("session_id", sessionID).Msg("Session lookup failed")
+		return false
+	}
+	active := session.Active() && session.UserID == userID
+
+	if cacheErr := redis.SetSessionStatus(ctx, sessionID, active, sessionStatusCacheTTL); cacheErr != nil {
+		log.Debug().Err(cacheErr).Str("session_id", sessionID).Msg("Failed to cache session status (ignored)")
+	}
+	return active
+}
+
+// revokeSession отзывает сессию в БД и сразу помечает ее отозванной в кеше
+func (s *Service) revokeSession(ctx context.Context, sessionID string, reason string) error {
+	if err := s.repo.RevokeSession(ctx, sessionID, reason); err != nil {
+		return s.translateError(err)
+	}
+	s.markSessionsRevoked(ctx, sessionID)
+	return nil
+}
+
+func (s *Service) markSessionsRevoked(ctx context.Context, sessionIDs ...string) {
+	log := logger.GetLogger()
+	for _, sessionID := range sessionIDs {
+		if cacheErr := redis.SetSessionStatus(ctx, sessionID, false, sessionStatusCacheTTL); cacheErr != nil {
+			log.Debug().Err(cacheErr).Str("session_id", sessionID).Msg("Failed to cache revoked session (ignored)")
+		}
+	}
+}
+
+// isNewDevice - устройство не встречалось среди прошлых входов.
+// Самый первый вход пользователя новым устройством не считается.
+func (s *Service) isNewDevice(ctx context.Context, userID int, deviceID string) (bool, error) {
+	known, err := s.repo.GetUserDeviceIDs(ctx, userID)
+	if err != nil {
+		return false, s.translateError(err)
+	}
+	return len(known) > 0 && !slices.Contains(known, deviceID), nil
+}
+
+// Logout завершает текущую сессию пользователя
+func (s *Service) Logout(ctx context.Context, userID int, sessionID string) error {
+	return s.endUserSession(ctx, userID, sessionID, "logout")
+}
+
+// TerminateSession - пользователь завершает одну из своих сессий (например, на потерянном устройстве)
+func (s *Service) TerminateSession(ctx context.Context, userID int, sessionID string) error {
+	return s.endUserSession(ctx, userID, sessionID, "terminated_by_user")
+}
+
+func (s *Service) endUserSession(ctx context.Context, userID int, sessionID string, reason string) error {
+	session, err := s.repo.GetSession(ctx, sessionID)
+	if err != nil {
+		return s.translateError(err)
+	}
+	if session.UserID != userID {
+		return errs.ErrAccessDenied
+	}
+	return s.revokeSession(ctx, sessionID, reason)
+}
+
+// ListSessions - активные сессии пользователя с данными устройств
+func (s *Service) ListSessions(ctx context.Context, userID int) ([]domain.Session, error) {
+	sessions, err := s.repo.GetActiveSessions(ctx, userID)
+	if err != nil {
+		return nil, s.translateError(err)
+	}
+	return sessions, nil
+}
+
+// sendStepUpCode отправляет новый код подтверждения сессии по SMS или email
+func (s *Service) sendStepUpCode(ctx context.Context, user domain.User, sessionID string) error {
+	log := logger.GetLogger()
+
+	code, err := generateOTP()
+	if err != nil {
+		return s.translateError(err)
+	}
+	if err := s.repo.SetSessionStepUpCode(ctx, sessionID, hashToken(code), time.Now().Add(s.cfg.StepUpCodeTTL)); err != nil {
+		return s.translateError(err)
+	}
+
+	msg := codeNotification(user, "Sign-in confirmation code",
+		fmt.Sprintf("MiniBank: code %s confirms sign-in from a new device. Valid for %d min. Never share this code.",
+			code, int(s.cfg.StepUpCodeTTL.Minutes())))
+	if err := s.notifier.Send(msg); err != nil {
+		log.Error().Err(err).Int("user_id", user.ID).Str("session_id", sessionID).Msg("Failed to send step-up code")
+		return errs.ErrTransactionFailed
+	}
+	return nil
+}
+
+// SendStepUpCode повторно отправляет код подтверждения текущей сессии, не чаще раза в stepUpResendInterval
+func (s *Service) SendStepUpCode(ctx context.Context, userID int, sessionID string) error {
+	session, err := s.repo.GetSession(ctx, sessionID)
+	if err != nil {
+		return s.translateError(err)
+	}
+	if session.UserID != userID || !session.StepUpRequired {
+		return errs.ErrInvalidOperation
+	}
+	if !session.StepUpCodeExpiresAt.IsZero() && time.Until(session.StepUpCodeExpiresAt) > s.cfg.StepUpCodeTTL-stepUpResendInterval {
+		return errs.ErrTooManyAttempts
+	}
+
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return s.translateError(err)
+	}
+	return s.sendStepUpCode(ctx, *user, sessionID)
+}
+
+// CompleteStepUp подтверждает сессию с нового устройства кодом, отправленным на телефон или email.
+// Неверные коды учитываются той же защитой от подбора, что и пароль; после блокировки входа сессия отзывается.
+func (s *Service) CompleteStepUp(ctx context.Context, userID int, sessionID string, code string, ip string) error {
+	log := logger.GetLogger()
+
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return s.translateError(err)
+	}
+	email := normalizeLoginEmail(user.Email)
+	if err := s.checkLoginAllowed(ctx, email, ip); err != nil {
+		return err
+	}
+
+	session, err := s.repo.GetSession(ctx, sessionID)
+	if err != nil {
+		return s.translateError(err)
+	}
+	if session.UserID != userID {
+		return errs.ErrAccessDenied
+	}
+	if !session.StepUpRequired {
+		return nil
+	}
+	if session.StepUpCodeHash == "" || time.Now().After(session.StepUpCodeExpiresAt) {
+		return errs.ErrOTPExpired
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashToken(strings.TrimSpace(code))), []byte(session.StepUpCodeHash)) != 1 {
+		s.recordEvent(ctx, domain.AuditEvent{
+			Action: "step_up_failed", Outcome: domain.AuditFailure, TargetType: "session", TargetID: sessionID,
+		})
+		if failErr := s.registerLoginFailure(ctx, email, ip, userID); errors.Is(failErr, errs.ErrAccountLocked) {
+			log.Warn().Int("user_id", userID).Str("session_id", sessionID).Msg("Step-up attempts exhausted, revoking session")
+			if err := s.revokeSession(ctx, sessionID, "step_up_failed"); err != nil {
+				log.Error().Err(err).Str("session_id", sessionID).Msg("Failed to revoke session after step-up lockout")
+			}
+			return failErr
+		}
+		return errs.ErrInvalidOTP
+	}
+
+	if err := s.repo.CompleteSessionStepUp(ctx, sessionID); err != nil {
+		return s.translateError(err)
+	}
+	s.recordEvent(ctx, domain.AuditEvent{Action: "step_up_completed", TargetType: "session", TargetID: sessionID})
+	return nil
+}
+
+// checkStepUp запрещает переводы из сессии, которая ждет подтверждения после входа с нового устройства
+func (s *Service) checkStepUp(ctx context.Context, sessionID string) error {
+	if sessionID == "" {
+		return nil
+	}
+	session, err := s.repo.GetSession(ctx, sessionID)
+	if err != nil {
+		return s.translateError(err)
+	}
+	if session.StepUpRequired {
+		return errs.ErrStepUpRequired
+	}
+	return nil
+}
+
+// LogoutAll завершает все сессии пользователя на всех устройствах
+func (s *Service) LogoutAll(ctx context.Context, userID int) error {
+	sessionIDs, err := s.repo.RevokeUserSessions(ctx, userID, "logout_all")
+	if err != nil {
+		return s.translateError(err)
+	}
+	s.markSessionsRevoked(ctx, sessionIDs...)
+	return nil
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/logger"
+	"github.com/MMII0220/MiniBank/internal/redis"
+)
+
+const (
+	defaultStatsPeriod = 30 * 24 * time.Hour
+	maxStatsPeriod     = 366 * 24 * time.Hour
+	topLimitBreaches   = 10
+)
+
+// statsRange - период отчета [from, to) в UTC; по умолчанию последние 30 дней, включая сегодня
+func statsRange(from, to time.Time) (time.Time, time.Time, error) {
+	if to.IsZero() {
+		to = time.Now().UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+	}
+	if from.IsZero() {
+		from = to.Add(-defaultStatsPeriod)
+	}
+	from, to = from.UTC(), to.UTC()
+	if !from.Before(to) || to.Sub(from) > maxStatsPeriod {
+		return from, to, errs.ErrInvalidData
+	}
+	return from, to, nil
+}
+
+// AdminStats - сводка для операционной панели. Агрегаты считаются в БД и кешируются в Redis на STATS_CACHE_TTL;
+// без Redis каждый запрос считается заново.
+func (s *Service) AdminStats(ctx context.Context, from, to time.Time) (domain.AdminStats, error) {
+	log := logger.GetLogger()
+
+	from, to, err := statsRange(from, to)
+	if err != nil {
+		return domain.AdminStats{}, err
+	}
+	s.recordEvent(ctx, domain.AuditEvent{
+		Action: "stats_viewed", TargetType: "stats",
+		After: auditState(map[string]time.Time{"from": from, "to": to}),
+	})
+
+	cacheKey := from.Format(time.RFC3339) + "_" + to.Format(time.RFC3339)
+	var stats domain.AdminStats
+	if err := redis.GetStatsCache(ctx, cacheKey, &stats); err == nil {
+		return stats, nil
+	}
+
+	stats, err = s.repo.GetAdminStats(ctx, from, to, topLimitBreaches)
+	if err != nil {
+		return domain.AdminStats{}, s.translateError(err)
+	}
+	stats.FeeIncome = map[string]float64{}
+	for _, v := range stats.Volumes {
+		if v.Fees != 0 {
+			stats.FeeIncome[v.Currency] += v.Fees
+		}
+	}
+	stats.GeneratedAt = time.Now().UTC()
+
+	if err := redis.SetStatsCache(ctx, cacheKey, stats, s.cfg.StatsCacheTTL); err != nil {
+		log.Debug().Err(err).Msg("Failed to cache admin stats")
+	}
+	return stats, nil
+}
@@ -1,14 +1,16 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strconv"
 
 	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
 )
 
-func (s *Service) Deposit(currentUserID int, req domain.ReqTransaction) error {
+func (s *Service) Deposit(ctx context.Context, currentUserID int, req domain.ReqTransaction) error {
 	var account domain.Account
 	var err error
 
@@ -20,10 +22,14 @@ func (s *Service) Deposit(currentUserID int, req domain.ReqTransaction) error {
 		return errors.New("amount must be greater than zero")
 	}
 
+	if req.PhoneNumber, err = s.lookupPhone(req.PhoneNumber); err != nil {
+		return err
+	}
+
 	if req.CardNumber != "" {
-		err = s.repo.GetAccountByCardNumber(&account, req.CardNumber, req.Currency)
+		err = s.repo.GetAccountByCardNumber(ctx, &account, req.CardNumber, req.Currency)
 	} else if req.PhoneNumber != "" {
-		err = s.repo.GetAccountByPhoneNumber(&account, req.PhoneNumber, req.Currency)
+		err = s.repo.GetAccountByPhoneNumber(ctx, &account, req.PhoneNumber, req.Currency)
 	} else {
 		return errors.New("either card_number or phone_number must be provided")
 	}
@@ -32,19 +38,19 @@ func (s *Service) Deposit(currentUserID int, req domain.ReqTransaction) error {
 		return s.translateError(err)
 	}
 
-	if account.Blocked {
-		return errors.New("account is blocked")
-	}
-
 	// account.UserID = currentUserID
 	if account.UserID != currentUserID {
 		return errors.New("access denied")
 	}
 
-	return s.translateError(s.repo.DepositToAccount(account.ID, req.Amount))
+	if err := s.checkCredit(ctx, account.ID); err != nil {
+		return err
+	}
+
+	return s.translateError(s.repo.DepositToAccount(ctx, account.ID, req.Amount))
 }
 
-func (s *Service) Withdraw(currentUserID int, req domain.ReqTransaction) error {
+func (s *Service) Withdraw(ctx context.Context, currentUserID int, req domain.ReqTransaction) error {
 	var account domain.Account
 	var err error
 
@@ -56,10 +62,14 @@ func (s *Service) Withdraw(currentUserID int, req domain.ReqTransaction) error {
 		return errors.New("amount must be greater than zero")
 	}
 
+	if req.PhoneNumber, err = s.lookupPhone(req.PhoneNumber); err != nil {
+		return err
+	}
+
 	if req.CardNumber != "" {
-		err = s.repo.GetAccountByCardNumber(&account, req.CardNumber, req.Currency)
+		err = s.repo.GetAccountByCardNumber(ctx, &account, req.CardNumber, req.Currency)
 	} else if req.PhoneNumber != "" {
-		err = s.repo.GetAccountByPhoneNumber(&account, req.PhoneNumber, req.Currency)
+		err = s.repo.GetAccountByPhoneNumber(ctx, &account, req.PhoneNumber, req.Currency)
 	} else {
 		return errors.New("either card_number or phone_number must be provided")
 	}
@@ -71,15 +81,16 @@ func (s *Service) Withdraw(currentUserID int, req domain.ReqTransaction) error {
 		return s.translateError(err)
 	}
 
-	if account.Blocked {
-		return errors.New("account is blocked")
-	}
-
 	// account.UserID = currentUserID
 	if account.UserID != currentUserID {
 		return errors.New("access denied")
 	}
-	balance, err := strconv.ParseFloat(account.Balance, 64)
+
+	restrictions, err := s.checkDebit(ctx, account.ID)
+	if err != nil {
+		return err
+	}
+	balance, err := spendableBalance(account, restrictions)
 	if err != nil {
 		return errors.New("invalid balance format")
 	}
@@ -89,7 +100,7 @@ func (s *Service) Withdraw(currentUserID int, req domain.ReqTransaction) error {
 	}
 
 	// Проверяем лимит и получаем комиссию (НЕ перезаписываем req.Amount!)
-	fee, err := s.CheckLimitAndCalculateFee(account.UserID, req.Amount, req.Currency)
+	fee, err := s.CheckLimitAndCalculateFee(ctx, account.UserID, req.Amount, req.Currency)
 	if err != nil {
 		return s.translateError(err)
 	}
@@ -103,10 +114,11 @@ func (s *Service) Withdraw(currentUserID int, req domain.ReqTransaction) error {
 	// Обновляем req.Amount для списания основной суммы + комиссии
 	req.Amount = totalAmount
 
-	return s.translateError(s.repo.WithdrawFromAccount(account.ID, req.Amount, req.Currency))
+	return s.translateError(s.repo.WithdrawFromAccount(ctx, account.ID, req.Amount, fee, req.Currency))
 }
 
-func (s *Service) Transfer(currentUserID int, req domain.ReqTransfer) error {
+func (s *Service) Transfer(ctx context.Context, currentUserID int, req domain.ReqTransfer) (domain.TransferResult, error) {
+	var result domain.TransferResult
 	var fromAccount, toAccount domain.Account
 	var err error
 
@@ -115,42 +127,86 @@ func (s *Service) Transfer(currentUserID int, req domain.ReqTransfer) error {
 		req.Currency = "TJS"
 	}
 
+	if req.FromPhoneNumber, err = s.lookupPhone(req.FromPhoneNumber); err != nil {
+		return result, err
+	}
+	if req.ToPhoneNumber, err = s.lookupPhone(req.ToPhoneNumber); err != nil {
+		return result, err
+	}
+
 	if req.FromCardNumber != "" {
-		err = s.repo.GetAccountByCardNumber(&fromAccount, req.FromCardNumber, req.Currency)
+		err = s.repo.GetAccountByCardNumber(ctx, &fromAccount, req.FromCardNumber, req.Currency)
 	} else if req.FromPhoneNumber != "" {
-		err = s.repo.GetAccountByPhoneNumber(&fromAccount, req.FromPhoneNumber, req.Currency)
+		err = s.repo.GetAccountByPhoneNumber(ctx, &fromAccount, req.FromPhoneNumber, req.Currency)
+	}
+	if err != nil {
+		return result, s.translateError(err)
+	}
+
+	// Счет отправителя задан номером карты или телефона - списывать можно только со своего счета
+	// или со счета, где пользователь подписант. До этой проверки не ставится ни холд, ни код подтверждения.
+	if err := s.checkSenderAccess(ctx, fromAccount, currentUserID); err != nil {
+		return result, err
 	}
 
 	if req.ToCardNumber != "" {
-		err = s.repo.GetAccountByCardNumber(&toAccount, req.ToCardNumber, req.Currency)
+		err = s.repo.GetAccountByCardNumber(ctx, &toAccount, req.ToCardNumber, req.Currency)
 	} else if req.ToPhoneNumber != "" {
-		err = s.repo.GetAccountByPhoneNumber(&toAccount, req.ToPhoneNumber, req.Currency)
+		err = s.repo.GetAccountByPhoneNumber(ctx, &toAccount, req.ToPhoneNumber, req.Currency)
 	}
 
 	if err != nil {
-		return s.translateError(err)
+		return result, s.translateError(err)
+	}
+
+	// Получатель по номеру телефона - только подтвержденный номер
+	if req.ToCardNumber == "" && req.ToPhoneNumber != "" {
+		if err := s.checkRecipientPhoneVerified(ctx, toAccount); err != nil {
+			return result, err
+		}
 	}
 
-	if fromAccount.Blocked || toAccount.Blocked {
-		return errors.New("one of the accounts is blocked")
+	// Ограничения проверяются для обеих сторон: списание с отправителя и зачисление получателю
+	restrictions, err := s.checkDebit(ctx, fromAccount.ID)
+	if err != nil {
+		return result, err
+	}
+	if err := s.checkCredit(ctx, toAccount.ID); err != nil {
+		return result, err
 	}
 
 	if req.Amount <= 0 {
-		return errors.New("amount must be greater than zero")
+		return result, errors.New("amount must be greater than zero")
+	}
+
+	// Сессия после входа с нового устройства должна быть подтверждена
+	if err := s.checkStepUp(ctx, req.SessionID); err != nil {
+		return result, err
+	}
+
+	// Проверяем получателя по санкционным спискам
+	if s.screener != nil {
+		recipient, err := s.repo.GetUserByID(ctx, toAccount.UserID)
+		if err != nil {
+			return result, s.translateError(err)
+		}
+		if err := s.screenSubject(ctx, domain.ScreeningTransferRecipient, recipientScreeningRef(toAccount.ID), recipient.FullName); err != nil {
+			return result, err
+		}
 	}
 
-	balance, err := strconv.ParseFloat(fromAccount.Balance, 64)
+	balance, err := spendableBalance(fromAccount, restrictions)
 	if err != nil {
-		return errors.New("invalid balance format")
+		return result, errors.New("invalid balance format")
 	}
 	if req.Amount > balance {
-		return errors.New("insufficient funds")
+		return result, errors.New("insufficient funds")
 	}
 
 	// Проверяем лимит и получаем комиссию для переводов (НЕ перезаписываем req.Amount!)
-	fee, err := s.CheckLimitAndCalculateFee(fromAccount.UserID, req.Amount, req.Currency)
+	fee, err := s.CheckLimitAndCalculateFee(ctx, fromAccount.UserID, req.Amount, req.Currency)
 	if err != nil {
-		return s.translateError(err)
+		return result, s.translateError(err)
 	}
 
 	fmt.Printf("DEBUG: Transfer fee = %f\n", fee)
@@ -158,23 +214,83 @@ func (s *Service) Transfer(currentUserID int, req domain.ReqTransfer) error {
 	// Если есть комиссия - добавляем к основной сумме
 	totalAmount := req.Amount + fee
 	if totalAmount > balance {
-		return errors.New("insufficient funds including overlimit fee")
+		return result, errors.New("insufficient funds including overlimit fee")
+	}
+
+	amountInTJS, err := s.ConvertToBaseCurrency(req.Amount, req.Currency)
+	if err != nil {
+		return result, s.translateError(err)
+	}
+
+	// Крупная сумма или новый получатель - сначала код из SMS
+	if !req.OTPConfirmed {
+		needsOTP, err := s.transferNeedsOTP(ctx, currentUserID, toAccount.ID, amountInTJS)
+		if err != nil {
+			return result, err
+		}
+		if needsOTP {
+			return s.createTransferConfirmation(ctx, currentUserID, req)
+		}
+	}
+
+	// Крупные переводы исполняются только после одобрения (maker-checker)
+	if amountInTJS >= s.cfg.TransferApprovalThreshold {
+		return s.createPendingTransfer(ctx, currentUserID, fromAccount, toAccount, req.Amount, fee, req.Currency)
 	}
 
 	// Обновляем req.Amount для списания основной суммы + комиссии
 	req.Amount = totalAmount
 
-	fromAccount.UserID = currentUserID
-
 	// Атомарная операция через репозиторий
-	return s.translateError(s.repo.TransferFunds(fromAccount.ID, toAccount.ID, req.Amount))
+	if err := s.repo.TransferFunds(ctx, fromAccount.ID, toAccount.ID, req.Amount, fee); err != nil {
+		return result, s.translateError(err)
+	}
+	s.rememberRecipient(ctx, currentUserID, toAccount.ID)
+
+	result.Status = domain.TransferCompleted
+	result.Fee = fee
+	return result, nil
+}
+
+// checkSenderAccess - владелец счета или его подписант
+func (s *Service) checkSenderAccess(ctx context.Context, account domain.Account, userID int) error {
+	if account.ID == 0 {
+		return errs.ErrAccessDenied
+	}
+	if account.UserID == userID {
+		return nil
+	}
+	ok, err := s.repo.IsAccountSignatory(ctx, account.ID, userID)
+	if err != nil {
+		return s.translateError(err)
+	}
+	if !ok {
+		return errs.ErrAccessDenied
+	}
+	return nil
 }
 
 // HistoryLogs возвращает историю операций пользователя
-func (s *Service) HistoryLogs(idUser int) ([]domain.Transaction, error) {
-	transactions, err := s.repo.GetTransactionHistory(idUser)
+func (s *Service) HistoryLogs(ctx context.Context, idUser int) ([]domain.Transaction, error) {
+	transactions, err := s.repo.GetTransactionHistory(ctx, idUser)
 	if err != nil {
 		return nil, s.translateError(err)
 	}
 	return transactions, nil
 }
+
+// availableBalance - баланс счета за вычетом средств, удержанных под переводы на одобрении
+func availableBalance(account domain.Account) (float64, error) {
+	balance, err := strconv.ParseFloat(account.Balance, 64)
+	if err != nil {
+		return 0, err
+	}
+	if account.HoldAmount == "" {
+		return balance, nil
+	}
+	hold, err := strconv.ParseFloat(account.HoldAmount, 64)
+	if err != nil {
+		return 0, err
+	}
+	return balance - hold, nil
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/logger"
+)
+
+// generateOTP - случайный 6-значный код
+func generateOTP() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// transferNeedsOTP - крупная сумма или получатель, которому пользователь еще не переводил
+func (s *Service) transferNeedsOTP(ctx context.Context, userID int, toAccountID int, amountInTJS float64) (bool, error) {
+	if amountInTJS >= s.cfg.TransferOTPThreshold {
+		return true, nil
+	}
+	known, err := s.repo.IsKnownRecipient(ctx, userID, toAccountID)
+	if err != nil {
+		return false, s.translateError(err)
+	}
+	return !known, nil
+}
+
+// createTransferConfirmation откладывает перевод до ввода кода и отправляет код на телефон пользователя
+func (s *Service) createTransferConfirmation(ctx context.Context, userID int, req domain.ReqTransfer) (domain.TransferResult, error) {
+	log := logger.GetLogger()
+
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return domain.TransferResult{}, s.translateError(err)
+	}
+
+	code, err := generateOTP()
+	if err != nil {
+		return domain.TransferResult{}, s.translateError(err)
+	}
+
+	confirmation := domain.TransferConfirmation{
+		UserID:    userID,
+		Request:   req,
+		CodeHash:  hashToken(code),
+		ExpiresAt: time.Now().Add(s.cfg.TransferOTPTTL),
+	}
+	if err := s.repo.CreateTransferConfirmation(ctx, &confirmation); err != nil {
+		return domain.TransferResult{}, s.translateError(err)
+	}
+
+	msg := codeNotification(*user, "Transfer confirmation code",
+		fmt.Sprintf("MiniBank: code %s confirms transfer of %.2f %s. Valid for %d min. Never share this code.",
+			code, req.Amount, req.Currency, int(s.cfg.TransferOTPTTL.Minutes())))
+	if err := s.notifier.Send(msg); err != nil {
+		log.Error().Err(err).Int("user_id", userID).Int("confirmation_id", confirmation.ID).Msg("Failed to send transfer confirmation code")
+		return domain.TransferResult{}, errs.ErrTransactionFailed
+	}
+
+	log.Info().Int("user_id", userID).Int("confirmation_id", confirmation.ID).Msg("Transfer is waiting for OTP confirmation")
+	return domain.TransferResult{
+		Status:         domain.TransferPendingConfirmation,
+		ConfirmationID: confirmation.ID,
+	}, nil
+}
+
+// ConfirmTransfer проверяет код и исполняет отложенный перевод.
+// Все проверки перевода (баланс, лимиты, блокировки, одобрение крупных сумм) выполняются заново.
+func (s *Service) ConfirmTransfer(ctx context.Context, userID int, confirmationID int, code string, sessionID string) (domain.TransferResult, error) {
+	log := logger.GetLogger()
+	var result domain.TransferResult
+
+	confirmation, err := s.repo.GetTransferConfirmation(ctx, confirmationID)
+	if err != nil {
+		return result, s.translateError(err)
+	}
+	// Чужое подтверждение не раскрываем
+	if confirmation.UserID != userID {
+		return result, errs.ErrTransferConfirmationNotFound
+	}
+
+	switch confirmation.Status {
+	case domain.TransferConfirmationPending:
+	case domain.TransferConfirmationFailed:
+		return result, errs.ErrTooManyAttempts
+	case domain.TransferConfirmationExpired:
+		return result, errs.ErrOTPExpired
+	default:
+		return result, errs.ErrInvalidOperation
+	}
+
+	if time.Now().After(confirmation.ExpiresAt) {
+		if err := s.repo.CloseTransferConfirmation(ctx, confirmation.ID, domain.TransferConfirmationExpired); err != nil && !errors.Is(err, errs.ErrInvalidOperation) {
+			return result, s.translateError(err)
+		}
+		return result, errs.ErrOTPExpired
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashToken(code)), []byte(confirmation.CodeHash)) != 1 {
+		attempts, err := s.repo.RecordTransferConfirmationAttempt(ctx, confirmation.ID, s.cfg.TransferOTPMaxAttempts)
+		if err != nil {
+			return result, s.translateError(err)
+		}
+		log.Warn().Int("confirmation_id", confirmation.ID).Int("attempts", attempts).Msg("Invalid transfer confirmation code")
+		if attempts >= s.cfg.TransferOTPMaxAttempts {
+			return result, errs.ErrTooManyAttempts
+		}
+		return result, errs.ErrInvalidOTP
+	}
+
+	// Параллельное подтверждение того же кода не исполнит перевод дважды
+	if err := s.repo.CloseTransferConfirmation(ctx, confirmation.ID, domain.TransferConfirmationConfirmed); err != nil {
+		return result, s.translateError(err)
+	}
+
+	req := confirmation.Request
+	req.SessionID = sessionID
+	req.OTPConfirmed = true
+	return s.Transfer(ctx, userID, req)
+}
+
+// rememberRecipient - следующие переводы этому получателю не потребуют кода (кроме крупных)
+func (s *Service) rememberRecipient(ctx context.Context, userID, accountID int) {
+	log := logger.GetLogger()
+	if err := s.repo.AddKnownRecipient(ctx, userID, accountID); err != nil {
+		log.Warn().Err(err).Int("user_id", userID).Int("account_id", accountID).Msg("Failed to remember transfer recipient")
+	}
+}
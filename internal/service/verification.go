@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MMII0220/MiniBank/internal/domain"
+	"github.com/MMII0220/MiniBank/internal/errs"
+	"github.com/MMII0220/MiniBank/internal/logger"
+	"github.com/MMII0220/MiniBank/internal/utils"
+)
+
+// normalizePhone - номер в E.164 с кодом страны по умолчанию
+func (s *Service) normalizePhone(phone string) (string, error) {
+	return utils.NormalizePhone(phone, s.cfg.PhoneDefaultCountryCode, s.cfg.PhoneNationalLength)
+}
+
+// lookupPhone нормализует номер из запроса перед поиском счета; пустой номер остается пустым
+func (s *Service) lookupPhone(phone string) (string, error) {
+	if phone == "" {
+		return "", nil
+	}
+	return s.normalizePhone(phone)
+}
+
+// SendVerificationCode отправляет код подтверждения на текущий email или телефон пользователя
+func (s *Service) SendVerificationCode(ctx context.Context, userID int, channel domain.VerificationChannel) error {
+	log := logger.GetLogger()
+
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return s.translateError(err)
+	}
+
+	msg := domain.Notification{UserID: userID}
+	var verified bool
+	switch channel {
+	case domain.VerifyEmail:
+		msg.Channel, msg.To, verified = domain.ChannelEmail, user.Email, user.EmailVerified
+	case domain.VerifyPhone:
+		msg.Channel, msg.To, verified = domain.ChannelSMS, user.Phone, user.PhoneVerified
+	default:
+		return errs.ErrInvalidData
+	}
+	if verified {
+		return errs.ErrInvalidOperation
+	}
+	if msg.To == "" {
+		return errs.ErrInvalidData
+	}
+
+	code, err := generateOTP()
+	if err != nil {
+		return s.translateError(err)
+	}
+	verification := domain.ContactVerification{
+		UserID:      userID,
+		Channel:     channel,
+		Destination: msg.To,
+		CodeHash:    hashToken(code),
+		ExpiresAt:   time.Now().Add(s.cfg.ContactVerificationTTL),
+	}
+	if err := s.repo.CreateContactVerification(ctx, &verification); err != nil {
+		return s.translateError(err)
+	}
+
+	msg.Subject = "MiniBank verification code"
+	msg.Body = fmt.Sprintf("MiniBank: your verification code is %s. Valid for %d min.", code, int(s.cfg.ContactVerificationTTL.Minutes()))
+	if err := s.notifier.Send(msg); err != nil {
+		log.Error().Err(err).Int("user_id", userID).Str("channel", string(channel)).Msg("Failed to send verification code")
+		return errs.ErrTransactionFailed
+	}
+
+	log.Info().Int("user_id", userID).Str("channel", string(channel)).Int("verification_id", verification.ID).Msg("Verification code sent")
+	return nil
+}
+
+// VerifyContact проверяет код и отмечает email или телефон подтвержденным
+func (s *Service) VerifyContact(ctx context.Context, userID int, channel domain.VerificationChannel, code string) error {
+	log := logger.GetLogger()
+
+	verification, err := s.repo.GetPendingContactVerification(ctx, userID, channel)
+	if err != nil {
+		if errors.Is(err, errs.ErrVerificationNotFound) {
+			return errs.ErrVerificationNotFound
+		}
+		return s.translateError(err)
+	}
+	if time.Now().After(verification.ExpiresAt) {
+		return errs.ErrOTPExpired
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashToken(code)), []byte(verification.CodeHash)) != 1 {
+		attempts, err := s.repo.RecordContactVerificationAttempt(ctx, verification.ID, s.cfg.ContactVerificationMaxAttempts)
+		if err != nil {
+			return s.translateError(err)
+		}
+		log.Warn().Int("verification_id", verification.ID).Int("attempts", attempts).Msg("Invalid verification code")
+		if attempts >= s.cfg.ContactVerificationMaxAttempts {
+			return errs.ErrTooManyAttempts
+		}
+		return errs.ErrInvalidOTP
+	}
+
+	if err := s.repo.CompleteContactVerification(ctx, verification); err != nil {
+		if errors.Is(err, errs.ErrVerificationNotFound) {
+			return errs.ErrVerificationNotFound
+		}
+		return s.translateError(err)
+	}
+
+	log.Info().Int("user_id", userID).Str("channel", string(channel)).Msg("Contact verified")
+	return nil
+}
+
+// checkRecipientPhoneVerified - по номеру телефона можно переводить только на подтвержденный номер
+func (s *Service) checkRecipientPhoneVerified(ctx context.Context, account domain.Account) error {
+	recipient, err := s.repo.GetUserByID(ctx, account.UserID)
+	if err != nil {
+		return s.translateError(err)
+	}
+	if !recipient.PhoneVerified {
+		return errs.ErrPhoneNotVerified
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"io"
+	"path/filepath"
+	"sync"
+)
+
+// S3Client - подмножество API S3-совместимого хранилища (AWS S3, MinIO, Ceph), которое нужно приложению.
+// Реальный клиент подключается адаптером к этому интерфейсу, для разработки есть LocalS3.
+type S3Client interface {
+	PutObject(bucket, key string, body io.Reader, contentType string) error
+	GetObject(bucket, key string) (io.ReadCloser, error)
+	DeleteObject(bucket, key string) error
+}
+
+// BucketStore - хранилище документов в одном бакете S3-совместимого хранилища
+type BucketStore struct {
+	client S3Client
+	bucket string
+}
+
+func NewBucketStore(client S3Client, bucket string) *BucketStore {
+	return &BucketStore{client: client, bucket: bucket}
+}
+
+func (s *BucketStore) Put(key string, body io.Reader, contentType string) error {
+	return s.client.PutObject(s.bucket, key, body, contentType)
+}
+
+func (s *BucketStore) Open(key string) (io.ReadCloser, error) {
+	return s.client.GetObject(s.bucket, key)
+}
+
+func (s *BucketStore) Delete(key string) error {
+	return s.client.DeleteObject(s.bucket, key)
+}
+
+// LocalS3 - локальная замена S3-совместимого хранилища: каждый бакет - каталог внутри root
+type LocalS3 struct {
+	root    string
+	mu      sync.Mutex
+	buckets map[string]*DiskStore
+}
+
+func NewLocalS3(root string) *LocalS3 {
+	return &LocalS3{root: root, buckets: map[string]*DiskStore{}}
+}
+
+func (c *LocalS3) bucket(name string) (*DiskStore, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if store, ok := c.buckets[name]; ok {
+		return store, nil
+	}
+	store, err := NewDiskStore(filepath.Join(c.root, filepath.Base(name)))
+	if err != nil {
+		return nil, err
+	}
+	c.buckets[name] = store
+	return store, nil
+}
+
+func (c *LocalS3) PutObject(bucket, key string, body io.Reader, contentType string) error {
+	store, err := c.bucket(bucket)
+	if err != nil {
+		return err
+	}
+	return store.Put(key, body, contentType)
+}
+
+func (c *LocalS3) GetObject(bucket, key string) (io.ReadCloser, error) {
+	store, err := c.bucket(bucket)
+	if err != nil {
+		return nil, err
+	}
+	return store.Open(key)
+}
+
+func (c *LocalS3) DeleteObject(bucket, key string) error {
+	store, err := c.bucket(bucket)
+	if err != nil {
+		return err
+	}
+	return store.Delete(key)
+}
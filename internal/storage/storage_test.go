@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDiskStore_PutOpenDelete(t *testing.T) {
+	store, err := NewDiskStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Put("kyc/5/abc", strings.NewReader("scan"), "image/png"); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	f, err := store.Open("kyc/5/abc")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	data, _ := io.ReadAll(f)
+	f.Close()
+	if string(data) != "scan" {
+		t.Fatalf("unexpected content %q", data)
+	}
+
+	if err := store.Delete("kyc/5/abc"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := store.Open("kyc/5/abc"); !errors.Is(err, ErrObjectNotFound) {
+		t.Fatalf("expected ErrObjectNotFound after delete, got %v", err)
+	}
+}
+
+func TestDiskStore_RejectsKeysOutsideDir(t *testing.T) {
+	store, err := NewDiskStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"", "/", "../etc/passwd", "kyc/../../x"} {
+		if err := store.Put(key, strings.NewReader("x"), "text/plain"); err == nil {
+			t.Fatalf("expected error for key %q", key)
+		}
+	}
+}
+
+func TestBucketStore_LocalS3(t *testing.T) {
+	store := NewBucketStore(NewLocalS3(t.TempDir()), "kyc-docs")
+
+	if err := store.Put("kyc/5/abc", strings.NewReader("scan"), "application/pdf"); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	f, err := store.Open("kyc/5/abc")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	data, _ := io.ReadAll(f)
+	f.Close()
+	if string(data) != "scan" {
+		t.Fatalf("unexpected content %q", data)
+	}
+	if _, err := NewBucketStore(NewLocalS3(t.TempDir()), "other").Open("kyc/5/abc"); !errors.Is(err, ErrObjectNotFound) {
+		t.Fatalf("objects must not leak between stand-in roots, got %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Тестовые векторы RFC 6238 (SHA1), последние 6 цифр
+func TestCodeAt_RFC6238Vectors(t *testing.T) {
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	cases := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+	for ts, want := range cases {
+		got, err := CodeAt(secret, Step(time.Unix(ts, 0)))
+		if err != nil || got != want {
+			t.Fatalf("CodeAt(%d) = %q, %v; want %q", ts, got, err, want)
+		}
+	}
+}
+
+func TestValidate_SkewAndReplayStep(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	prev, _ := CodeAt(secret, Step(now)-1)
+
+	step, ok := Validate(secret, prev, now, 1)
+	if !ok || step != Step(now)-1 {
+		t.Fatalf("expected previous step accepted, got %d %v", step, ok)
+	}
+	if _, ok := Validate(secret, prev, now, 0); ok {
+		t.Fatalf("expected previous step rejected without skew")
+	}
+	if _, ok := Validate(secret, "12345", now, 1); ok {
+		t.Fatalf("expected short code rejected")
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI("JBSWY3DPEHPK3PXP", "MiniBank", "john@example.com")
+	if !strings.HasPrefix(uri, "otpauth://totp/MiniBank:john@example.com?") ||
+		!strings.Contains(uri, "secret=JBSWY3DPEHPK3PXP") || !strings.Contains(uri, "issuer=MiniBank") {
+		t.Fatalf("unexpected uri: %s", uri)
+	}
+}
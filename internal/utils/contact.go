@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"net/mail"
+	"strings"
+
+	"github.com/MMII0220/MiniBank/internal/errs"
+)
+
+// NormalizeEmail - email в нижнем регистре без пробелов; адрес с именем ("John <a@b.c>") не принимается
+func NormalizeEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return "", errs.ErrInvalidEmail
+	}
+	_, domainPart, _ := strings.Cut(email, "@")
+	if !strings.Contains(domainPart, ".") {
+		return "", errs.ErrInvalidEmail
+	}
+	return email, nil
+}
+
+// NormalizePhone приводит номер к E.164 (+992900123456).
+// Пробелы, дефисы, точки и скобки отбрасываются, префикс 00 равен +.
+// Номер без кода страны длиной nationalLength получает defaultCountryCode.
+func NormalizePhone(phone, defaultCountryCode string, nationalLength int) (string, error) {
+	phone = strings.TrimSpace(phone)
+	international := false
+	switch {
+	case strings.HasPrefix(phone, "+"):
+		international = true
+		phone = phone[1:]
+	case strings.HasPrefix(phone, "00"):
+		international = true
+		phone = phone[2:]
+	}
+
+	var digits strings.Builder
+	for _, r := range phone {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", errs.ErrInvalidPhone
+		}
+	}
+	number := digits.String()
+
+	if !international {
+		switch {
+		case len(number) == nationalLength:
+			number = defaultCountryCode + number
+		case len(number) == len(defaultCountryCode)+nationalLength && strings.HasPrefix(number, defaultCountryCode):
+			// код страны без +, например 992900123456
+		default:
+			return "", errs.ErrInvalidPhone
+		}
+	}
+
+	// E.164: до 15 цифр, код страны не начинается с 0
+	if len(number) < 8 || len(number) > 15 || number[0] == '0' {
+		return "", errs.ErrInvalidPhone
+	}
+	return "+" + number, nil
+}
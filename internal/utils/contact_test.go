@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/MMII0220/MiniBank/internal/errs"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	cases := map[string]string{
+		"+992 900 12 34 56":  "+992900123456",
+		"992900123456":       "+992900123456",
+		"900-12-34-56":       "+992900123456",
+		"00992 (900) 123456": "+992900123456",
+		"+7 912 345 67 89":   "+79123456789",
+	}
+	for in, want := range cases {
+		got, err := NormalizePhone(in, "992", 9)
+		if err != nil || got != want {
+			t.Fatalf("NormalizePhone(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+
+	for _, in := range []string{"", "12345", "79123456789", "+0123456789", "+992 900 12 34 56 78 90 12", "900abc456"} {
+		if _, err := NormalizePhone(in, "992", 9); !errors.Is(err, errs.ErrInvalidPhone) {
+			t.Fatalf("NormalizePhone(%q): expected ErrInvalidPhone, got %v", in, err)
+		}
+	}
+}
+
+func TestNormalizeEmail(t *testing.T) {
+	got, err := NormalizeEmail("  John.Doe@Example.COM ")
+	if err != nil || got != "john.doe@example.com" {
+		t.Fatalf("unexpected: %q %v", got, err)
+	}
+	for _, in := range []string{"", "john", "john@localhost", "John <john@example.com>", "a@@b.c"} {
+		if _, err := NormalizeEmail(in); !errors.Is(err, errs.ErrInvalidEmail) {
+			t.Fatalf("NormalizeEmail(%q): expected ErrInvalidEmail, got %v", in, err)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS screening_reviews (
+    id            SERIAL PRIMARY KEY,
+    subject_type  VARCHAR(32)  NOT NULL CHECK (subject_type IN ('customer','transfer_recipient')),
+    subject_ref   VARCHAR(255) NOT NULL, -- email клиента или "account:<id>" получателя
+    subject_name  VARCHAR(255) NOT NULL,
+    matched_name  VARCHAR(255) NOT NULL,
+    list_source   VARCHAR(64)  NOT NULL,
+    list_entry_id VARCHAR(64)  NOT NULL,
+    score         NUMERIC(5,4) NOT NULL,
+    status        VARCHAR(16)  NOT NULL DEFAULT 'pending' CHECK (status IN ('pending','cleared','confirmed')),
+    reviewed_by   INT          NULL REFERENCES users(id),
+    review_note   TEXT         NULL,
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    reviewed_at   TIMESTAMPTZ  NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_screening_reviews_subject ON screening_reviews(subject_type, subject_ref);
+CREATE INDEX IF NOT EXISTS idx_screening_reviews_status ON screening_reviews(status);
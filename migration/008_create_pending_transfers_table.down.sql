@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS pending_transfers;
+DROP TABLE IF EXISTS account_signatories;
+ALTER TABLE accounts DROP COLUMN IF EXISTS account_type;
+ALTER TABLE accounts DROP COLUMN IF EXISTS hold_amount;
@@ -0,0 +1,30 @@
+-- Удержание средств под переводы, ожидающие одобрения
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS hold_amount NUMERIC(20,2) NOT NULL DEFAULT 0;
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS account_type VARCHAR(16) NOT NULL DEFAULT 'personal'
+    CHECK (account_type IN ('personal','business'));
+
+-- Уполномоченные лица бизнес-счетов (второй подписант для крупных переводов)
+CREATE TABLE IF NOT EXISTS account_signatories (
+    account_id  INT         NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    user_id     INT         NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (account_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS pending_transfers (
+    id              SERIAL PRIMARY KEY,
+    from_account_id INT           NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    to_account_id   INT           NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    amount          NUMERIC(20,2) NOT NULL, -- сумма к зачислению получателю
+    fee             NUMERIC(20,2) NOT NULL DEFAULT 0,
+    currency        VARCHAR(26)   NOT NULL DEFAULT 'TJS',
+    initiator_id    INT           NOT NULL REFERENCES users(id),
+    status          VARCHAR(16)   NOT NULL DEFAULT 'pending' CHECK (status IN ('pending','approved','rejected','expired')),
+    decided_by      INT           NULL REFERENCES users(id),
+    decision_note   TEXT          NULL,
+    expires_at      TIMESTAMPTZ   NOT NULL,
+    created_at      TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    decided_at      TIMESTAMPTZ   NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_pending_transfers_status ON pending_transfers(status, expires_at);
@@ -0,0 +1,5 @@
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_type_check
+    CHECK (type IN ('deposit','withdraw','transfer'));
+
+DROP TABLE IF EXISTS pending_actions;
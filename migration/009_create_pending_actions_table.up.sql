@@ -0,0 +1,25 @@
+-- Чувствительные админские действия по принципу четырех глаз:
+-- один админ предлагает, другой одобряет, выполняется только после одобрения
+CREATE TABLE IF NOT EXISTS pending_actions (
+    id             SERIAL PRIMARY KEY,
+    action_type    VARCHAR(32)   NOT NULL CHECK (action_type IN ('unblock','limit_raise','manual_adjustment','role_change')),
+    account_id     INT           NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    user_id        INT           NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount         NUMERIC(20,2) NULL, -- новый лимит или сумма корректировки (со знаком)
+    new_role       VARCHAR(16)   NULL,
+    reason         TEXT          NOT NULL,
+    proposed_by    INT           NOT NULL REFERENCES users(id),
+    status         VARCHAR(16)   NOT NULL DEFAULT 'pending' CHECK (status IN ('pending','approved','rejected','expired')),
+    decided_by     INT           NULL REFERENCES users(id),
+    decision_note  TEXT          NULL,
+    expires_at     TIMESTAMPTZ   NOT NULL,
+    created_at     TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    decided_at     TIMESTAMPTZ   NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_pending_actions_status ON pending_actions(status, expires_at);
+
+-- Ручная корректировка баланса проводится отдельным типом транзакции
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_type_check
+    CHECK (type IN ('deposit','withdraw','transfer','adjustment'));
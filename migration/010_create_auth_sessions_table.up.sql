@@ -0,0 +1,24 @@
+-- Серверные сессии: одна сессия = одно семейство refresh токенов (логин на устройстве)
+CREATE TABLE IF NOT EXISTS auth_sessions (
+    id             VARCHAR(64)  PRIMARY KEY, -- sid в access токене
+    user_id        INT          NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at     TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    last_used_at   TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    expires_at     TIMESTAMPTZ  NOT NULL,
+    revoked_at     TIMESTAMPTZ  NULL,
+    revoke_reason  VARCHAR(64)  NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_sessions_user ON auth_sessions(user_id);
+
+-- Refresh токены одноразовые: при обновлении старый помечается used_at, повторное использование отзывает всю сессию
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id          SERIAL       PRIMARY KEY,
+    session_id  VARCHAR(64)  NOT NULL REFERENCES auth_sessions(id) ON DELETE CASCADE,
+    token_hash  CHAR(64)     NOT NULL UNIQUE, -- sha256, сам токен не хранится
+    expires_at  TIMESTAMPTZ  NOT NULL,
+    used_at     TIMESTAMPTZ  NULL,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_session ON refresh_tokens(session_id);
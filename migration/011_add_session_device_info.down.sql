@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_auth_sessions_user_device;
+ALTER TABLE auth_sessions DROP COLUMN IF EXISTS step_up_required;
+ALTER TABLE auth_sessions DROP COLUMN IF EXISTS ip;
+ALTER TABLE auth_sessions DROP COLUMN IF EXISTS user_agent;
+ALTER TABLE auth_sessions DROP COLUMN IF EXISTS device_id;
@@ -0,0 +1,8 @@
+-- Данные устройства, с которого выполнен вход
+ALTER TABLE auth_sessions ADD COLUMN IF NOT EXISTS device_id  VARCHAR(128) NOT NULL DEFAULT '';
+ALTER TABLE auth_sessions ADD COLUMN IF NOT EXISTS user_agent TEXT         NOT NULL DEFAULT '';
+ALTER TABLE auth_sessions ADD COLUMN IF NOT EXISTS ip         VARCHAR(64)  NOT NULL DEFAULT '';
+-- Вход с нового устройства: до подтверждения личности переводы из этой сессии запрещены
+ALTER TABLE auth_sessions ADD COLUMN IF NOT EXISTS step_up_required BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_auth_sessions_user_device ON auth_sessions(user_id, device_id);
@@ -0,0 +1,22 @@
+-- Попытки входа: постоянный журнал и запасной источник счетчиков, если Redis недоступен
+CREATE TABLE IF NOT EXISTS login_attempts (
+    id          SERIAL       PRIMARY KEY,
+    email       VARCHAR(255) NOT NULL,
+    ip          VARCHAR(64)  NOT NULL DEFAULT '',
+    user_id     INT          NULL REFERENCES users(id) ON DELETE SET NULL,
+    success     BOOLEAN      NOT NULL,
+    cleared     BOOLEAN      NOT NULL DEFAULT FALSE, -- сброшено успешным входом или разблокировкой
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_attempts_email ON login_attempts(email, created_at);
+CREATE INDEX IF NOT EXISTS idx_login_attempts_ip ON login_attempts(ip, created_at);
+
+-- Временные блокировки входа после серии неудачных попыток
+CREATE TABLE IF NOT EXISTS login_lockouts (
+    email         VARCHAR(255) PRIMARY KEY,
+    user_id       INT          NULL REFERENCES users(id) ON DELETE CASCADE,
+    failed_count  INT          NOT NULL,
+    locked_until  TIMESTAMPTZ  NOT NULL,
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,3 @@
+ALTER TABLE auth_sessions DROP COLUMN IF EXISTS mfa_verified;
+DROP TABLE IF EXISTS mfa_recovery_codes;
+DROP TABLE IF EXISTS user_mfa;
@@ -0,0 +1,23 @@
+-- TOTP (RFC 6238): один секрет на пользователя, включается после подтверждения первым кодом
+CREATE TABLE IF NOT EXISTS user_mfa (
+    user_id         INT          PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret          VARCHAR(64)  NOT NULL, -- base32
+    enabled         BOOLEAN      NOT NULL DEFAULT FALSE,
+    last_used_step  BIGINT       NOT NULL DEFAULT 0, -- защита от повторного использования кода
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    enabled_at      TIMESTAMPTZ  NULL
+);
+
+-- Одноразовые коды восстановления на случай потери устройства
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    id          SERIAL       PRIMARY KEY,
+    user_id     INT          NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash   CHAR(64)     NOT NULL, -- sha256, сам код не хранится
+    used_at     TIMESTAMPTZ  NULL,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_mfa_recovery_codes_user ON mfa_recovery_codes(user_id);
+
+-- Сессия, вход в которую подтвержден вторым фактором
+ALTER TABLE auth_sessions ADD COLUMN IF NOT EXISTS mfa_verified BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS known_recipients;
+DROP TABLE IF EXISTS transfer_confirmations;
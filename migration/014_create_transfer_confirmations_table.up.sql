@@ -0,0 +1,29 @@
+-- Переводы, ожидающие подтверждения одноразовым кодом (крупная сумма или новый получатель)
+CREATE TABLE IF NOT EXISTS transfer_confirmations (
+    id                 SERIAL        PRIMARY KEY,
+    user_id            INT           NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    from_card_number   VARCHAR(32)   NOT NULL DEFAULT '',
+    from_phone_number  VARCHAR(32)   NOT NULL DEFAULT '',
+    to_card_number     VARCHAR(32)   NOT NULL DEFAULT '',
+    to_phone_number    VARCHAR(32)   NOT NULL DEFAULT '',
+    amount             NUMERIC(20,2) NOT NULL CHECK (amount > 0),
+    currency           VARCHAR(3)    NOT NULL,
+    code_hash          CHAR(64)      NOT NULL, -- sha256, сам код не хранится
+    attempts           INT           NOT NULL DEFAULT 0,
+    status             VARCHAR(16)   NOT NULL DEFAULT 'pending'
+                       CHECK (status IN ('pending','confirmed','expired','failed')),
+    expires_at         TIMESTAMPTZ   NOT NULL,
+    created_at         TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    confirmed_at       TIMESTAMPTZ   NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_transfer_confirmations_user ON transfer_confirmations(user_id, status);
+
+-- Получатели, которым пользователь уже переводил: перевод новому получателю требует подтверждения кодом.
+-- В transactions нет контрагента, поэтому таблица заполняется с момента внедрения.
+CREATE TABLE IF NOT EXISTS known_recipients (
+    user_id     INT          NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    account_id  INT          NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, account_id)
+);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS staff_invites;
+
+UPDATE users SET role = 'user' WHERE role IN ('support','auditor','teller');
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('user','admin'));
@@ -0,0 +1,16 @@
+-- Роли сотрудников: поддержка, аудитор, операционист
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('user','admin','support','auditor','teller'));
+
+-- Приглашения сотрудников: роль выше клиента выдается только через приглашение админа
+CREATE TABLE IF NOT EXISTS staff_invites (
+    id                SERIAL       PRIMARY KEY,
+    email             VARCHAR(255) NOT NULL,
+    role              VARCHAR(16)  NOT NULL CHECK (role IN ('admin','support','auditor','teller')),
+    token_hash        CHAR(64)     NOT NULL UNIQUE, -- sha256, сам токен не хранится
+    invited_by        INT          NOT NULL REFERENCES users(id),
+    expires_at        TIMESTAMPTZ  NOT NULL,
+    accepted_at       TIMESTAMPTZ  NULL,
+    accepted_user_id  INT          NULL REFERENCES users(id) ON DELETE SET NULL,
+    created_at        TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,11 @@
+-- Одноразовые токены восстановления пароля, в БД только sha256 от токена
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    id          SERIAL       PRIMARY KEY,
+    user_id     INT          NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash  CHAR(64)     NOT NULL UNIQUE,
+    expires_at  TIMESTAMPTZ  NOT NULL,
+    used_at     TIMESTAMPTZ  NULL, -- использован или заменен более новым токеном
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user ON password_reset_tokens(user_id) WHERE used_at IS NULL;
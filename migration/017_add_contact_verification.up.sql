@@ -0,0 +1,42 @@
+-- Подтверждение email и телефона
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMPTZ NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone_verified_at TIMESTAMPTZ NULL;
+
+-- Приводим существующие email к нижнему регистру и телефоны к E.164 (+992 по умолчанию).
+-- Строки, которые после нормализации совпали бы с другим пользователем, остаются как есть и разбираются вручную.
+UPDATE users u SET email = LOWER(TRIM(u.email))
+WHERE u.email <> LOWER(TRIM(u.email))
+  AND NOT EXISTS (SELECT 1 FROM users o WHERE o.id <> u.id AND o.email = LOWER(TRIM(u.email)));
+
+WITH normalized AS (
+    SELECT id,
+           CASE
+               WHEN phone ~ '^\s*(\+|00)' THEN '+' || regexp_replace(regexp_replace(phone, '^\s*(\+|00)', ''), '\D', '', 'g')
+               WHEN length(regexp_replace(phone, '\D', '', 'g')) = 9 THEN '+992' || regexp_replace(phone, '\D', '', 'g')
+               WHEN regexp_replace(phone, '\D', '', 'g') ~ '^992\d{9}$' THEN '+' || regexp_replace(phone, '\D', '', 'g')
+           END AS phone
+    FROM users
+)
+UPDATE users u SET phone = n.phone
+FROM normalized n
+WHERE u.id = n.id
+  AND n.phone IS NOT NULL
+  AND n.phone <> u.phone
+  AND NOT EXISTS (SELECT 1 FROM users o WHERE o.id <> u.id AND o.phone = n.phone);
+
+-- Коды подтверждения, в БД только sha256 от кода
+CREATE TABLE IF NOT EXISTS contact_verifications (
+    id           SERIAL        PRIMARY KEY,
+    user_id      INT           NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    channel      VARCHAR(8)    NOT NULL CHECK (channel IN ('email','phone')),
+    destination  VARCHAR(255)  NOT NULL, -- адрес на момент отправки: смена email/телефона делает код недействительным
+    code_hash    CHAR(64)      NOT NULL,
+    attempts     INT           NOT NULL DEFAULT 0,
+    status       VARCHAR(16)   NOT NULL DEFAULT 'pending'
+                 CHECK (status IN ('pending','verified','superseded','failed')),
+    expires_at   TIMESTAMPTZ   NOT NULL,
+    created_at   TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    verified_at  TIMESTAMPTZ   NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_contact_verifications_user ON contact_verifications(user_id, channel, status);
@@ -0,0 +1,2 @@
+ALTER TABLE account_audit DROP COLUMN IF EXISTS api_client_id;
+DROP TABLE IF EXISTS api_clients;
@@ -0,0 +1,19 @@
+-- API клиенты для сервисных скриптов: вход по client_id/client_secret (OAuth2 client credentials)
+CREATE TABLE IF NOT EXISTS api_clients (
+    id                     SERIAL       PRIMARY KEY,
+    client_id              VARCHAR(64)  NOT NULL UNIQUE,
+    name                   VARCHAR(255) NOT NULL,
+    secret_hash            CHAR(64)     NOT NULL, -- sha256, сам секрет показывается один раз при создании
+    owner_user_id          INT          NOT NULL REFERENCES users(id) ON DELETE CASCADE, -- от чьего имени действует клиент
+    scopes                 TEXT         NOT NULL, -- через пробел, как scope в OAuth2
+    rate_limit_per_minute  INT          NOT NULL CHECK (rate_limit_per_minute > 0),
+    created_by             INT          NOT NULL REFERENCES users(id),
+    created_at             TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    last_used_at           TIMESTAMPTZ  NULL,
+    revoked_at             TIMESTAMPTZ  NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_clients_owner ON api_clients(owner_user_id);
+
+-- Действия API клиента попадают в аудит с его id
+ALTER TABLE account_audit ADD COLUMN IF NOT EXISTS api_client_id INT NULL REFERENCES api_clients(id) ON DELETE SET NULL;
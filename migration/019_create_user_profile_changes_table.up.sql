@@ -0,0 +1,12 @@
+-- История изменений профиля (имя, email, телефон), видна самому пользователю
+CREATE TABLE IF NOT EXISTS user_profile_changes (
+    id          SERIAL       PRIMARY KEY,
+    user_id     INT          NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    field       VARCHAR(32)  NOT NULL CHECK (field IN ('full_name','email','phone','card_holder_name')),
+    old_value   TEXT         NOT NULL,
+    new_value   TEXT         NOT NULL,
+    changed_by  INT          NOT NULL REFERENCES users(id),
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_profile_changes_user ON user_profile_changes(user_id, created_at DESC);
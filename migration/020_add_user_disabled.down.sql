@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_users_lower_full_name;
+ALTER TABLE users DROP COLUMN IF EXISTS disabled_at;
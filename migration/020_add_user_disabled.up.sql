@@ -0,0 +1,5 @@
+-- Отключение пользователя админом: вход, refresh токены и API клиенты владельца перестают работать
+ALTER TABLE users ADD COLUMN IF NOT EXISTS disabled_at TIMESTAMPTZ NULL;
+
+-- Поиск клиентов в админке по имени и email
+CREATE INDEX IF NOT EXISTS idx_users_lower_full_name ON users (LOWER(full_name));
@@ -0,0 +1,37 @@
+-- Общий журнал чувствительных действий. Каждая запись хранит hash предыдущей (prev_hash),
+-- поэтому удаление или правка строки обнаруживается проверкой цепочки (minibank audit-verify).
+-- Внешних ключей нет намеренно: запись аудита должна пережить удаление пользователя или счета.
+CREATE TABLE IF NOT EXISTS audit_events (
+    id             BIGSERIAL    PRIMARY KEY,
+    occurred_at    TIMESTAMPTZ  NOT NULL,
+    actor_id       INT          NOT NULL DEFAULT 0, -- 0 - система или аноним
+    actor_type     VARCHAR(16)  NOT NULL CHECK (actor_type IN ('user', 'api_client', 'system', 'anonymous')),
+    api_client_id  INT          NOT NULL DEFAULT 0,
+    action         VARCHAR(64)  NOT NULL,
+    outcome        VARCHAR(16)  NOT NULL CHECK (outcome IN ('success', 'failure', 'denied')),
+    target_type    VARCHAR(32)  NOT NULL DEFAULT '',
+    target_id      VARCHAR(255) NOT NULL DEFAULT '',
+    -- before/after хранятся как TEXT, а не JSONB: JSONB переставляет ключи, и hash бы не сошелся
+    before_state   TEXT         NOT NULL DEFAULT '',
+    after_state    TEXT         NOT NULL DEFAULT '',
+    request_id     VARCHAR(64)  NOT NULL DEFAULT '',
+    ip             VARCHAR(64)  NOT NULL DEFAULT '',
+    user_agent     TEXT         NOT NULL DEFAULT '',
+    prev_hash      CHAR(64)     NULL, -- NULL только у первой записи
+    hash           CHAR(64)     NOT NULL UNIQUE
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor ON audit_events(actor_type, actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_target ON audit_events(target_type, target_id);
+
+-- Журнал только дополняется
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_audit_events_append_only ON audit_events;
+CREATE TRIGGER trg_audit_events_append_only
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_account_audit_created_at;
+DROP INDEX IF EXISTS idx_account_audit_action;
+DROP INDEX IF EXISTS idx_account_audit_admin_id;
+DROP INDEX IF EXISTS idx_account_audit_account_id;
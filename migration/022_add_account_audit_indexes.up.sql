@@ -0,0 +1,5 @@
+-- Фильтры журнала account_audit (курсор идет по id)
+CREATE INDEX IF NOT EXISTS idx_account_audit_account_id ON account_audit(account_id, id);
+CREATE INDEX IF NOT EXISTS idx_account_audit_admin_id ON account_audit(admin_id, id);
+CREATE INDEX IF NOT EXISTS idx_account_audit_action ON account_audit(action, id);
+CREATE INDEX IF NOT EXISTS idx_account_audit_created_at ON account_audit(created_at);
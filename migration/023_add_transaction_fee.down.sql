@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_users_created_at;
+DROP INDEX IF EXISTS idx_transactions_created_at;
+ALTER TABLE transactions DROP COLUMN IF EXISTS fee;
@@ -0,0 +1,7 @@
+-- Комиссия за превышение дневного лимита, входящая в amount операции.
+-- Нужна для отчета о комиссионном доходе и превышениях лимита; у старых операций остается 0.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS fee NUMERIC(20,2) NOT NULL DEFAULT 0;
+
+-- Отчеты /admin/stats идут по диапазону дат
+CREATE INDEX IF NOT EXISTS idx_transactions_created_at ON transactions(created_at);
+CREATE INDEX IF NOT EXISTS idx_users_created_at ON users(created_at);
@@ -0,0 +1,20 @@
+-- Ручные корректировки баланса админом (/admin/accounts/:id/adjust).
+-- Каждая корректировка - транзакция типа adjustment плюс запись здесь с кодом причины и балансом до и после.
+CREATE TABLE IF NOT EXISTS balance_adjustments (
+    id              SERIAL PRIMARY KEY,
+    account_id      INT           NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    transaction_id  INT           NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    admin_id        INT           NOT NULL REFERENCES users(id),
+    direction       VARCHAR(8)    NOT NULL CHECK (direction IN ('credit','debit')),
+    amount          NUMERIC(20,2) NOT NULL CHECK (amount > 0),
+    currency        VARCHAR(3)    NOT NULL,
+    amount_tjs      NUMERIC(20,2) NOT NULL, -- для дневного потолка админа
+    reason_code     VARCHAR(32)   NOT NULL CHECK (reason_code IN ('goodwill','error_correction','fee_refund','chargeback','other')),
+    note            TEXT          NOT NULL,
+    balance_before  NUMERIC(20,2) NOT NULL,
+    balance_after   NUMERIC(20,2) NOT NULL,
+    created_at      TIMESTAMPTZ   NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_balance_adjustments_admin ON balance_adjustments(admin_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_balance_adjustments_account ON balance_adjustments(account_id, created_at);
@@ -0,0 +1,7 @@
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS blocked BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE accounts SET blocked = TRUE
+WHERE id IN (SELECT account_id FROM account_restrictions
+             WHERE mode = 'full_block' AND lifted_at IS NULL AND (expires_at IS NULL OR expires_at > NOW()));
+
+DROP TABLE IF EXISTS account_restrictions;
@@ -0,0 +1,28 @@
+-- Ограничения счета вместо флага accounts.blocked:
+--   debit_freeze  - запрещены списания
+--   credit_freeze - запрещены зачисления
+--   full_block    - запрещены любые операции
+--   legal_hold    - сумма amount недоступна для списания
+-- Ограничение действует, пока не снято (lifted_at) и не истекло (expires_at).
+CREATE TABLE IF NOT EXISTS account_restrictions (
+    id               SERIAL PRIMARY KEY,
+    account_id       INT           NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    mode             VARCHAR(16)   NOT NULL CHECK (mode IN ('debit_freeze','credit_freeze','full_block','legal_hold')),
+    amount           NUMERIC(20,2) NULL CHECK (amount > 0),
+    reason_category  VARCHAR(32)   NOT NULL CHECK (reason_category IN ('fraud','aml','court_order','customer_request','operational','other')),
+    reason           TEXT          NOT NULL,
+    created_by       INT           NULL REFERENCES users(id),
+    expires_at       TIMESTAMPTZ   NULL,
+    created_at       TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    lifted_by        INT           NULL REFERENCES users(id),
+    lifted_at        TIMESTAMPTZ   NULL,
+    CHECK ((mode = 'legal_hold') = (amount IS NOT NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_restrictions_active ON account_restrictions(account_id) WHERE lifted_at IS NULL;
+
+-- Действующие блокировки переносятся как бессрочный full_block
+INSERT INTO account_restrictions (account_id, mode, reason_category, reason)
+SELECT id, 'full_block', 'other', 'migrated from accounts.blocked' FROM accounts WHERE blocked = TRUE;
+
+ALTER TABLE accounts DROP COLUMN IF EXISTS blocked;
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_transactions_account_type_created_at;
+
+DELETE FROM account_restrictions WHERE mode = 'dormant' OR reason_category = 'dormancy';
+
+ALTER TABLE account_restrictions DROP CONSTRAINT IF EXISTS account_restrictions_reason_category_check;
+ALTER TABLE account_restrictions ADD CONSTRAINT account_restrictions_reason_category_check
+    CHECK (reason_category IN ('fraud','aml','court_order','customer_request','operational','other'));
+
+ALTER TABLE account_restrictions DROP CONSTRAINT IF EXISTS account_restrictions_mode_check;
+ALTER TABLE account_restrictions ADD CONSTRAINT account_restrictions_mode_check
+    CHECK (mode IN ('debit_freeze','credit_freeze','full_block','legal_hold'));
@@ -0,0 +1,12 @@
+-- dormant - нет активности клиента дольше DORMANCY_MONTHS, запрещены списания до реактивации.
+-- Ставится фоновым сканированием, снимается клиентом (пароль и 2FA) или админом.
+ALTER TABLE account_restrictions DROP CONSTRAINT IF EXISTS account_restrictions_mode_check;
+ALTER TABLE account_restrictions ADD CONSTRAINT account_restrictions_mode_check
+    CHECK (mode IN ('debit_freeze','credit_freeze','full_block','legal_hold','dormant'));
+
+ALTER TABLE account_restrictions DROP CONSTRAINT IF EXISTS account_restrictions_reason_category_check;
+ALTER TABLE account_restrictions ADD CONSTRAINT account_restrictions_reason_category_check
+    CHECK (reason_category IN ('fraud','aml','court_order','customer_request','operational','dormancy','other'));
+
+-- Последняя активность клиента для сканирования
+CREATE INDEX IF NOT EXISTS idx_transactions_account_type_created_at ON transactions(account_id, type, created_at);
@@ -0,0 +1,24 @@
+-- Уровень KYC клиента: unverified после регистрации, basic после проверки удостоверения личности,
+-- full - удостоверение и подтверждение адреса. От уровня зависят дневные лимиты.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS kyc_tier VARCHAR(16) NOT NULL DEFAULT 'unverified'
+    CHECK (kyc_tier IN ('unverified','basic','full'));
+
+-- Документы клиентов; сам файл лежит в хранилище (диск или S3-совместимое) под storage_key
+CREATE TABLE IF NOT EXISTS kyc_documents (
+    id            SERIAL PRIMARY KEY,
+    user_id       INT          NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    doc_type      VARCHAR(32)  NOT NULL CHECK (doc_type IN ('passport','national_id','proof_of_address')),
+    storage_key   TEXT         NOT NULL UNIQUE,
+    file_name     VARCHAR(255) NOT NULL,
+    content_type  VARCHAR(64)  NOT NULL,
+    size_bytes    BIGINT       NOT NULL,
+    sha256        CHAR(64)     NOT NULL,
+    status        VARCHAR(16)  NOT NULL DEFAULT 'pending' CHECK (status IN ('pending','approved','rejected')),
+    reviewed_by   INT          NULL REFERENCES users(id),
+    review_note   TEXT         NULL,
+    reviewed_at   TIMESTAMPTZ  NULL,
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_kyc_documents_user_id ON kyc_documents(user_id);
+CREATE INDEX IF NOT EXISTS idx_kyc_documents_status ON kyc_documents(status, created_at);
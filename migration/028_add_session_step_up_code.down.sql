@@ -0,0 +1,2 @@
+ALTER TABLE auth_sessions DROP COLUMN IF EXISTS step_up_code_expires_at;
+ALTER TABLE auth_sessions DROP COLUMN IF EXISTS step_up_code_hash;
@@ -0,0 +1,3 @@
+-- Код подтверждения входа с нового устройства: отправляется на телефон или email, хранится только хеш
+ALTER TABLE auth_sessions ADD COLUMN IF NOT EXISTS step_up_code_hash       VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE auth_sessions ADD COLUMN IF NOT EXISTS step_up_code_expires_at TIMESTAMP;
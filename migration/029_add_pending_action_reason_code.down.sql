@@ -0,0 +1,2 @@
+ALTER TABLE pending_actions DROP CONSTRAINT IF EXISTS pending_actions_adjustment_reason_code_check;
+ALTER TABLE pending_actions DROP COLUMN IF EXISTS reason_code;
@@ -0,0 +1,10 @@
+-- Код причины у manual_adjustment: одобренная корректировка проводится как /admin/accounts/:id/adjust
+-- и попадает в balance_adjustments. Предложения, созданные до этой миграции, получают код other.
+ALTER TABLE pending_actions ADD COLUMN IF NOT EXISTS reason_code VARCHAR(32)
+    CHECK (reason_code IN ('goodwill','error_correction','fee_refund','chargeback','other'));
+
+UPDATE pending_actions SET reason_code = 'other' WHERE action_type = 'manual_adjustment' AND reason_code IS NULL;
+
+ALTER TABLE pending_actions DROP CONSTRAINT IF EXISTS pending_actions_adjustment_reason_code_check;
+ALTER TABLE pending_actions ADD CONSTRAINT pending_actions_adjustment_reason_code_check
+    CHECK (action_type <> 'manual_adjustment' OR reason_code IS NOT NULL);
@@ -0,0 +1,2 @@
+ALTER TABLE pending_actions DROP CONSTRAINT IF EXISTS pending_actions_unblock_restriction_mode_check;
+ALTER TABLE pending_actions DROP COLUMN IF EXISTS restriction_mode;
@@ -0,0 +1,10 @@
+-- Вид снимаемого ограничения у unblock: любое ограничение счета снимается только после одобрения вторым админом.
+-- Предложения, созданные до этой миграции, снимали полную блокировку.
+ALTER TABLE pending_actions ADD COLUMN IF NOT EXISTS restriction_mode VARCHAR(16)
+    CHECK (restriction_mode IN ('debit_freeze','credit_freeze','full_block','legal_hold','dormant'));
+
+UPDATE pending_actions SET restriction_mode = 'full_block' WHERE action_type = 'unblock' AND restriction_mode IS NULL;
+
+ALTER TABLE pending_actions DROP CONSTRAINT IF EXISTS pending_actions_unblock_restriction_mode_check;
+ALTER TABLE pending_actions ADD CONSTRAINT pending_actions_unblock_restriction_mode_check
+    CHECK (action_type <> 'unblock' OR restriction_mode IS NOT NULL);
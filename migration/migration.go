@@ -0,0 +1,9 @@
+// Package migration - SQL миграции схемы, встроенные в бинарник (см. internal/migrator и `minibank migrate`)
+package migration
+
+import "embed"
+
+// FS - файлы NNN_name.up.sql и NNN_name.down.sql
+//
+//go:embed *.sql
+var FS embed.FS